@@ -17,6 +17,10 @@ const configPath = "config/config.yaml"
 // @securityDefinitions.apikey  ApiKeyAuth
 // @in                          header
 // @name                        Authorization
+
+// @securityDefinitions.apikey  APIKeyHeader
+// @in                          header
+// @name                        X-API-Key
 func main() {
 	app.Run(configPath)
 }
@@ -11,11 +11,20 @@ import (
 
 type (
 	Config struct {
-		App  `yaml:"app"`
-		HTTP `yaml:"http"`
-		Log  `yaml:"log"`
-		PG   `yaml:"postgres"`
-		JWT  `yaml:"jwt"`
+		App           `yaml:"app"`
+		HTTP          `yaml:"http"`
+		Log           `yaml:"log"`
+		PG            `yaml:"postgres"`
+		JWT           `yaml:"jwt"`
+		Password      `yaml:"password"`
+		TwoFactor     `yaml:"two_factor"`
+		OIDC          `yaml:"oidc"`
+		LoginThrottle `yaml:"login_throttle"`
+		Idempotency   `yaml:"idempotency"`
+		Reservation   `yaml:"reservation"`
+		Warehouse     `yaml:"warehouse"`
+		StockAlert    `yaml:"stock_alert"`
+		Mail          `yaml:"mail"`
 	}
 
 	App struct {
@@ -25,6 +34,9 @@ type (
 
 	HTTP struct {
 		Port string `env-required:"true" yaml:"port" env:"HTTP_PORT"`
+		// TrustedProxies - прокси, чьим X-Forwarded-For можно верить при
+		// определении IP клиента. По умолчанию заголовок игнорируется.
+		TrustedProxies []string `yaml:"trusted_proxies" env:"HTTP_TRUSTED_PROXIES" env-separator:","`
 	}
 
 	Log struct {
@@ -37,8 +49,102 @@ type (
 	}
 
 	JWT struct {
-		SignKey  string        `env-required:"true"  env:"JWT_SIGN_KEY"`
-		TokenTTL time.Duration `env-required:"true" yaml:"token_ttl" env:"JWT_TOKEN_TTL"`
+		SignKey         string        `env:"JWT_SIGN_KEY"`
+		KeysDir         string        `yaml:"keys_dir" env:"JWT_KEYS_DIR"`
+		ActiveKeyID     string        `yaml:"active_key_id" env:"JWT_ACTIVE_KEY_ID"`
+		TokenTTL        time.Duration `env-required:"true" yaml:"token_ttl" env:"JWT_TOKEN_TTL"`
+		RefreshTokenTTL time.Duration `env-required:"true" yaml:"refresh_token_ttl" env:"JWT_REFRESH_TOKEN_TTL"`
+	}
+
+	Password struct {
+		Algorithm         string `yaml:"algorithm" env:"PASSWORD_ALGORITHM" env-default:"argon2id"`
+		Argon2Memory      uint32 `yaml:"argon2_memory" env:"PASSWORD_ARGON2_MEMORY" env-default:"65536"`
+		Argon2Iterations  uint32 `yaml:"argon2_iterations" env:"PASSWORD_ARGON2_ITERATIONS" env-default:"3"`
+		Argon2Parallelism uint8  `yaml:"argon2_parallelism" env:"PASSWORD_ARGON2_PARALLELISM" env-default:"2"`
+
+		MinLength     int  `yaml:"min_length" env:"PASSWORD_MIN_LENGTH" env-default:"8"`
+		MaxBytes      int  `yaml:"max_bytes" env:"PASSWORD_MAX_BYTES" env-default:"72"`
+		RequireUpper  bool `yaml:"require_upper" env:"PASSWORD_REQUIRE_UPPER" env-default:"true"`
+		RequireLower  bool `yaml:"require_lower" env:"PASSWORD_REQUIRE_LOWER" env-default:"true"`
+		RequireDigit  bool `yaml:"require_digit" env:"PASSWORD_REQUIRE_DIGIT" env-default:"true"`
+		RequireSymbol bool `yaml:"require_symbol" env:"PASSWORD_REQUIRE_SYMBOL" env-default:"false"`
+		// DenyCommon - запрещать пароли из встроенного списка распространенных.
+		DenyCommon bool `yaml:"deny_common" env:"PASSWORD_DENY_COMMON" env-default:"true"`
+	}
+
+	TwoFactor struct {
+		// Issuer - название сервиса в приложении-аутентификаторе.
+		Issuer       string        `yaml:"issuer" env:"TWO_FACTOR_ISSUER" env-default:"GoMarket"`
+		ChallengeTTL time.Duration `yaml:"challenge_ttl" env:"TWO_FACTOR_CHALLENGE_TTL" env-default:"5m"`
+	}
+
+	OIDC struct {
+		Enabled bool `yaml:"enabled" env:"OIDC_ENABLED" env-default:"false"`
+		// Provider - имя провайдера, под которым хранятся связанные учетные записи.
+		Provider     string   `yaml:"provider" env:"OIDC_PROVIDER" env-default:"company"`
+		Issuer       string   `yaml:"issuer" env:"OIDC_ISSUER"`
+		ClientID     string   `yaml:"client_id" env:"OIDC_CLIENT_ID"`
+		ClientSecret string   `env:"OIDC_CLIENT_SECRET"`
+		RedirectURL  string   `yaml:"redirect_url" env:"OIDC_REDIRECT_URL" env-default:"http://localhost:8080/auth/oidc/callback"`
+		Scopes       []string `yaml:"scopes" env:"OIDC_SCOPES" env-separator:"," env-default:"openid,email,profile"`
+		// LinkByEmail - связывать вход с существующим пользователем по почте,
+		// подтвержденной и у провайдера, и у нас.
+		LinkByEmail bool          `yaml:"link_by_email" env:"OIDC_LINK_BY_EMAIL" env-default:"true"`
+		StateTTL    time.Duration `yaml:"state_ttl" env:"OIDC_STATE_TTL" env-default:"10m"`
+	}
+
+	LoginThrottle struct {
+		// Store - где хранить счетчики неудачных входов: postgres или memory.
+		Store string `yaml:"store" env:"LOGIN_THROTTLE_STORE" env-default:"postgres"`
+	}
+
+	Idempotency struct {
+		// KeyTTL - сколько хранится ответ на запрос с Idempotency-Key. После
+		// этого ключ можно использовать заново.
+		KeyTTL time.Duration `yaml:"key_ttl" env:"IDEMPOTENCY_KEY_TTL" env-default:"24h"`
+	}
+
+	Reservation struct {
+		// TTL - сколько держится бронь товаров, пока покупатель оплачивает заказ.
+		TTL time.Duration `yaml:"ttl" env:"RESERVATION_TTL" env-default:"15m"`
+		// SweepInterval - как часто снимаются истекшие брони.
+		SweepInterval time.Duration `yaml:"sweep_interval" env:"RESERVATION_SWEEP_INTERVAL" env-default:"1m"`
+	}
+
+	Warehouse struct {
+		// Allocation - стратегия выбора складов для отгрузки заказа:
+		// priority, most_stock или nearest.
+		Allocation string `yaml:"allocation" env:"WAREHOUSE_ALLOCATION" env-default:"priority"`
+	}
+
+	StockAlert struct {
+		// Notifiers - способы доставки предупреждений о низком остатке:
+		// log, webhook и email, через запятую.
+		Notifiers []string `yaml:"notifiers" env:"STOCK_ALERT_NOTIFIERS" env-separator:"," env-default:"log"`
+		// WebhookURL и WebhookSecret нужны для способа webhook. Если секрет
+		// задан, тело запроса подписывается HMAC-SHA256.
+		WebhookURL     string        `yaml:"webhook_url" env:"STOCK_ALERT_WEBHOOK_URL"`
+		WebhookSecret  string        `env:"STOCK_ALERT_WEBHOOK_SECRET"`
+		WebhookTimeout time.Duration `yaml:"webhook_timeout" env:"STOCK_ALERT_WEBHOOK_TIMEOUT" env-default:"5s"`
+		// DispatchInterval - как часто отправляются новые предупреждения.
+		DispatchInterval time.Duration `yaml:"dispatch_interval" env:"STOCK_ALERT_DISPATCH_INTERVAL" env-default:"10s"`
+	}
+
+	Mail struct {
+		// Sender - способ отправки писем: smtp или file (письма складываются в FileDir).
+		Sender       string `yaml:"sender" env:"MAIL_SENDER" env-default:"file"`
+		From         string `yaml:"from" env:"MAIL_FROM" env-default:"GoMarket <no-reply@go-market.local>"`
+		FileDir      string `yaml:"file_dir" env:"MAIL_FILE_DIR" env-default:"./mail"`
+		SMTPHost     string `yaml:"smtp_host" env:"MAIL_SMTP_HOST"`
+		SMTPPort     string `yaml:"smtp_port" env:"MAIL_SMTP_PORT" env-default:"587"`
+		SMTPUsername string `env:"MAIL_SMTP_USERNAME"`
+		SMTPPassword string `env:"MAIL_SMTP_PASSWORD"`
+
+		TokenSignKey     string        `env-required:"true" env:"MAIL_TOKEN_SIGN_KEY"`
+		VerifyEmailURL   string        `yaml:"verify_email_url" env:"MAIL_VERIFY_EMAIL_URL" env-default:"http://localhost:8080/auth/verify-email"`
+		ResetPasswordURL string        `yaml:"reset_password_url" env:"MAIL_RESET_PASSWORD_URL" env-default:"http://localhost:8080/reset-password"`
+		VerifyEmailTTL   time.Duration `yaml:"verify_email_ttl" env:"MAIL_VERIFY_EMAIL_TTL" env-default:"24h"`
+		ResetPasswordTTL time.Duration `yaml:"reset_password_ttl" env:"MAIL_RESET_PASSWORD_TTL" env-default:"1h"`
 	}
 )
 
@@ -18,44 +18,71 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/v1/products/add-product": {
-            "post": {
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "Public keys for verifying access tokens, selected by the kid header. Retired keys stay published until their tokens expire",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "JSON Web Key Set",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/jwtkeys.JWKSet"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/{id}/roles": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Add a new product with name, description, price, and quantity",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Retrieve the roles assigned to a user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "admin"
                 ],
-                "summary": "Add a new product",
+                "summary": "Get user roles",
                 "parameters": [
                     {
-                        "description": "Product input",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/v1.addProductInput"
-                        }
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/v1.productRoutes"
+                            "$ref": "#/definitions/v1.userRolesResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body or validation error",
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResonse"
                         }
@@ -67,30 +94,40 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/products/delete-product/{id}": {
-            "delete": {
+            },
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Delete a product by its ID",
+                "description": "Grant a role (buyer, seller or admin) to a user. The user's sessions are revoked, so the new role takes effect on the next sign-in",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "admin"
                 ],
-                "summary": "Delete product by ID",
+                "summary": "Grant role",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Product ID",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Role to grant",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.grantRoleInput"
+                        }
                     }
                 ],
                 "responses": {
@@ -102,7 +139,19 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid product ID",
+                        "description": "Invalid request body or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResonse"
                         }
@@ -116,39 +165,59 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/products/get-product/{id}": {
-            "get": {
+        "/api/v1/admin/users/{id}/roles/{role}": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Retrieve a product by its ID",
+                "description": "Revoke a role from a user. The user's sessions are revoked, so tokens issued with the old role stop working immediately",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "admin"
                 ],
-                "summary": "Get product by ID",
+                "summary": "Revoke role",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Product ID",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Role to revoke",
+                        "name": "role",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Success message",
                         "schema": {
-                            "$ref": "#/definitions/v1.productRoutes"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Invalid product ID",
+                        "description": "Invalid user ID or role",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "Role is not assigned",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResonse"
                         }
@@ -162,26 +231,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/products/get-products": {
+        "/api/v1/cart": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
                     }
                 ],
-                "description": "Retrieve a list of all available products",
+                "description": "Get the cart of the authenticated user with current prices. Items with less stock than requested carry a warning: out_of_stock or not_enough_stock",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "cart"
                 ],
-                "summary": "Get all products",
+                "summary": "Get cart",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/v1.productRoutes"
+                            "$ref": "#/definitions/v1.cartResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
                         }
                     },
                     "500": {
@@ -191,44 +269,24 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/products/update-product/{id}": {
-            "put": {
+            },
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
                     }
                 ],
-                "description": "Update product details by ID with new name, description, price, and quantity",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Remove all products from the cart of the authenticated user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
-                ],
-                "summary": "Update product by ID",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Product ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Product update input",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/v1.updateProductInput"
-                        }
-                    }
+                    "cart"
                 ],
+                "summary": "Clear cart",
                 "responses": {
                     "200": {
                         "description": "Success message",
@@ -237,8 +295,8 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "Invalid request body or validation error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResonse"
                         }
@@ -252,14 +310,17 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/purchase/get-product-purchase/{id}": {
-            "get": {
+        "/api/v1/cart/checkout": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
                     }
                 ],
-                "description": "Retrieve a list of purchases for a specific product by product ID",
+                "description": "Buy everything in the cart of the authenticated user as one order at current prices. If any item cannot be bought, nothing is bought and the cart is left as is. On success the cart is emptied. The order is shipped from warehouses chosen by the configured allocation strategy; with ship_to the nearest strategy prefers warehouses closest to the buyer. A request retried with the same Idempotency-Key gets the original response instead of buying again",
                 "consumes": [
                     "application/json"
                 ],
@@ -267,27 +328,64 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "purchases"
+                    "cart"
                 ],
-                "summary": "Get product purchases",
+                "summary": "Checkout cart",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Product ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "Unique key of the checkout attempt, e.g. a UUID",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Delivery destination",
+                        "name": "input",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/v1.checkoutInput"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/v1.purchaseRoutes"
+                            "$ref": "#/definitions/v1.checkoutResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body or validation error",
+                        "description": "Invalid request body, validation error or cart is empty",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "402": {
+                        "description": "Not enough balance",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "403": {
+                        "description": "Buyer email is not verified",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product or buyer not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "409": {
+                        "description": "Not enough stock or request with this idempotency key is still in progress",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResonse"
                         }
@@ -301,14 +399,17 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/purchase/get-user-purchase/{id}": {
-            "get": {
+        "/api/v1/cart/items": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
                     }
                 ],
-                "description": "Retrieve a list of purchases made by a user specified by user ID",
+                "description": "Put a product into the cart of the authenticated user. If the product is already in the cart, the quantity is added to it",
                 "consumes": [
                     "application/json"
                 ],
@@ -316,23 +417,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "purchases"
+                    "cart"
                 ],
-                "summary": "Get user purchases",
+                "summary": "Add item to cart",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Product and quantity",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.addCartItemInput"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Success message",
                         "schema": {
-                            "$ref": "#/definitions/v1.purchaseRoutes"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
@@ -341,6 +445,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/v1.ErrorResonse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -350,14 +466,17 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/purchase/make-purchase": {
-            "post": {
+        "/api/v1/cart/items/{id}": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
                     }
                 ],
-                "description": "Allows a user to purchase a product by specifying user ID, product ID, and quantity",
+                "description": "Set the quantity of a product in the cart of the authenticated user. Quantity 0 removes the product from the cart",
                 "consumes": [
                     "application/json"
                 ],
@@ -365,29 +484,49 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "purchases"
+                    "cart"
                 ],
-                "summary": "Make a purchase",
+                "summary": "Update cart item",
                 "parameters": [
                     {
-                        "description": "Purchase input data",
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New quantity",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/v1.makePurcahseInput"
+                            "$ref": "#/definitions/v1.updateCartItemInput"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "Success message",
                         "schema": {
-                            "$ref": "#/definitions/v1.purchaseRoutes"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Invalid request body or validation error",
+                        "description": "Invalid ID, request body or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product is not in the cart",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResonse"
                         }
@@ -399,11 +538,177 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/auth/sign-in": {
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Remove a product from the cart of the authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cart"
+                ],
+                "summary": "Remove cart item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product is not in the cart",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/ledger/reconcile": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Recompute account balances from ledger entries and report drift against stored wallet balances",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ledger"
+                ],
+                "summary": "Reconcile ledger",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ledger.Report"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/orders/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Get an order with its status history and refunds. The order is visible to the buyer, sellers of its lines and admins",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Get order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.orderDetailsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid order ID",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/orders/{id}/advance": {
             "post": {
-                "description": "Authenticate a user and return a JWT token",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Move an order to the next fulfilment status: paid to shipped, shipped to delivered. Allowed for admins and the seller of all order lines",
                 "consumes": [
                     "application/json"
                 ],
@@ -411,29 +716,59 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "orders"
                 ],
-                "summary": "User login",
+                "summary": "Advance order",
                 "parameters": [
                     {
-                        "description": "User login input",
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason",
                         "name": "input",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/v1.signInInput"
+                            "$ref": "#/definitions/v1.orderStatusInput"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/v1.authRoutes"
+                            "$ref": "#/definitions/v1.orderResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid credentials or bad request",
+                        "description": "Invalid order ID or request body",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to change this order",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "409": {
+                        "description": "Order status cannot be advanced or has changed",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResonse"
                         }
@@ -447,9 +782,17 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/sign-up": {
+        "/api/v1/orders/{id}/cancel": {
             "post": {
-                "description": "Register a new user with username, password, and email",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Cancel an order that has not been shipped yet. Stock is returned to the products and a paid order is refunded to the buyer. Allowed for the buyer, admins and the seller of all order lines",
                 "consumes": [
                     "application/json"
                 ],
@@ -457,29 +800,59 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "orders"
                 ],
-                "summary": "User registration",
+                "summary": "Cancel order",
                 "parameters": [
                     {
-                        "description": "User registration input",
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason",
                         "name": "input",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/v1.signUpInput"
+                            "$ref": "#/definitions/v1.orderStatusInput"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/v1.authRoutes"
+                            "$ref": "#/definitions/v1.orderResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body or validation error",
+                        "description": "Invalid order ID or request body",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to change this order",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "409": {
+                        "description": "Order status does not allow cancellation or has changed",
                         "schema": {
                             "$ref": "#/definitions/v1.ErrorResonse"
                         }
@@ -492,44 +865,3770 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "v1.ErrorResonse": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string"
+        },
+        "/api/v1/orders/{id}/refunds": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Return units of a paid or delivered order: they go back to stock and their purchase price is credited to the buyer. Without items everything not yet refunded is returned, and the order becomes refunded once nothing is left. Allowed for admins and the seller of all refunded lines",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Refund order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason and returned units",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.refundInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/v1.refundResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid order ID, request body or product not in the order",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to change this order",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "409": {
+                        "description": "Refund exceeds purchased quantity or order status does not allow refunds",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/add-product": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Add a new product with name, description, price, and quantity owned by the authenticated seller",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Add a new product",
+                "parameters": [
+                    {
+                        "description": "Product input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.addProductInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/v1.productRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "403": {
+                        "description": "Seller or admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/adjust-stock/{id}": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Change the stock of a product in a warehouse and record the movement with the actor and reason. Kind restock adds received units (positive delta), adjustment corrects the stock in either direction. Without warehouse_id the default (highest priority) warehouse is used. The warehouse stock cannot go below zero and reserved units cannot be written off. Only the owning seller or an admin may adjust stock",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Adjust product stock",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Stock change",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.adjustStockInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/v1.stockMovementResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID, request body or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "403": {
+                        "description": "Seller or admin role required, or product belongs to another seller",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product or warehouse not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "409": {
+                        "description": "Stock would go below zero in the warehouse or below the reserved quantity",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/delete-product/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Delete a product by its ID. Only the owning seller or an admin may delete a product",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Delete product by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid product ID",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "403": {
+                        "description": "Seller or admin role required, or product belongs to another seller",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/get-product/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Retrieve a product by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Get product by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.productRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid product ID",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/get-products": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Retrieve a list of all available products",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Get all products",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.productRoutes"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/get-stock/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Reconstruct the stock of a product at the given moment from its movement history. Without at the current stock is returned. Only the owning seller or an admin may see it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Get product stock at a point in time",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Moment, RFC 3339",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.stockResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or moment",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "403": {
+                        "description": "Seller or admin role required, or product belongs to another seller",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/reorder-threshold/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Set the stock level at or below which the seller is alerted that the product needs restocking. The alert fires once per crossing: it is sent when the stock drops to the threshold and re-armed when the stock rises above it again. A null threshold disables alerts. Only the owning seller or an admin may set it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Set product reorder threshold",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reorder threshold",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.reorderThresholdInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID, request body or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "403": {
+                        "description": "Seller or admin role required, or product belongs to another seller",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/stock-alerts/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Get the low stock alerts of a product, newest first. An alert without resolved_at is still open: the stock has not risen above the threshold since. An alert without notified_at has not been delivered yet; failed deliveries are retried with backoff and counted in attempts, and only through channels not yet listed in delivered_channels. Only the owning seller or an admin may see them",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Get product stock alerts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/v1.stockAlertResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "403": {
+                        "description": "Seller or admin role required, or product belongs to another seller",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/stock-movements/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Get restocks, sales, refunds and manual adjustments of a product in the period [from, to), oldest first. Without to the period ends now. Only the owning seller or an admin may see the history",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Get product stock movements",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Period start, RFC 3339",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Period end, RFC 3339",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/v1.stockMovementResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or period",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "403": {
+                        "description": "Seller or admin role required, or product belongs to another seller",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/update-product/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Update product details by ID with new name, description and price. Stock is changed via stock adjustments. Only the owning seller or an admin may update a product",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Update product by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Product update input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.updateProductInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "403": {
+                        "description": "Seller or admin role required, or product belongs to another seller",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/warehouse-stock/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Get the stock of a product in every warehouse that has ever held it, in warehouse priority order. The product quantity is the sum of these stocks. Only the owning seller or an admin may see it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Get product stock by warehouse",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/v1.warehouseStockResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "403": {
+                        "description": "Seller or admin role required, or product belongs to another seller",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/purchase/get-product-purchase/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Retrieve the orders containing a specific product by product ID. Only the lines of this product are returned",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "purchases"
+                ],
+                "summary": "Get product purchases",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ordersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/purchase/get-user-purchase/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Retrieve the orders of a user specified by user ID. Only admins may read orders of another user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "purchases"
+                ],
+                "summary": "Get user purchases",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ordersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "403": {
+                        "description": "Reading purchases of another user is forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/purchase/make-purchase": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Allows the authenticated user to purchase a product by specifying product ID and quantity, or several products at once via items. All lines are bought as one order: if any line fails, nothing is bought. Prices are fixed at purchase time. The order is shipped from warehouses chosen by the configured allocation strategy; with ship_to the nearest strategy prefers warehouses closest to the buyer. Only admins may purchase on behalf of another user ID. A request retried with the same Idempotency-Key and body gets the original response instead of buying again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "purchases"
+                ],
+                "summary": "Make a purchase",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Unique key of the purchase attempt, e.g. a UUID",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Purchase input data",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.makePurcahseInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/v1.purchaseRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body, validation error or empty order",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "402": {
+                        "description": "Not enough balance",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "403": {
+                        "description": "Purchase on behalf of another user is forbidden or buyer email is not verified",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product or buyer not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "409": {
+                        "description": "Not enough stock or request with this idempotency key is still in progress",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency key was used with a different request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/purchase/me": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Retrieve the orders of the authenticated user with prices fixed at purchase time",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "purchases"
+                ],
+                "summary": "Get my purchases",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ordersResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/reservations": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Hold products for the authenticated user while they pay. Reserved units are not available to other buyers until the reservation is confirmed, released or expires. All lines are reserved at once: if any line fails, nothing is reserved",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Reserve products",
+                "parameters": [
+                    {
+                        "description": "Products and quantities",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.reserveInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/v1.reservationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "403": {
+                        "description": "Buyer email is not verified",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product or buyer not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "409": {
+                        "description": "Not enough stock",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/reservations/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Get a reservation of the authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Get reservation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.reservationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Cancel an active reservation of the authenticated user and return the products to sale",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Release reservation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "409": {
+                        "description": "Reservation is no longer active",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/reservations/{id}/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Pay for an active reservation at current prices. The reserved units are sold and the reservation is converted into an order. If payment fails, the reservation stays active until it expires. The order is shipped from warehouses chosen by the configured allocation strategy; with ship_to the nearest strategy prefers warehouses closest to the buyer. A request retried with the same Idempotency-Key gets the original response instead of paying again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reservations"
+                ],
+                "summary": "Confirm reservation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key of the payment attempt, e.g. a UUID",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Delivery destination",
+                        "name": "input",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/v1.checkoutInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/v1.checkoutResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID, invalid request body or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "402": {
+                        "description": "Not enough balance",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation or product not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "409": {
+                        "description": "Reservation is no longer active or has expired",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency key was used with a different request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/seller/products": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Retrieve the products owned by the authenticated seller",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "seller"
+                ],
+                "summary": "Get seller products",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.sellerRoutes"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "403": {
+                        "description": "Seller or admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/seller/sales": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Retrieve units sold and revenue per product and in total for the authenticated seller",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "seller"
+                ],
+                "summary": "Get seller sales",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.SellerSalesReport"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "403": {
+                        "description": "Seller or admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/me": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieve the profile of the authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get profile",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.userProfileResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete the account of the authenticated user after confirming the password. Personal data is erased, purchase history is kept",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete account",
+                "parameters": [
+                    {
+                        "description": "Current password",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.deleteAccountInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body, validation error or wrong password",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Change the username and/or email of the authenticated user. A new email must be verified again, a verification link is sent to it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update profile",
+                "parameters": [
+                    {
+                        "description": "Profile fields to change",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.updateProfileInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.userProfileResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "409": {
+                        "description": "Username or email is already taken",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/me/2fa/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Enable two-factor authentication with a code from the authenticator app. Returns recovery codes, which are shown only once",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Confirm two-factor authentication",
+                "parameters": [
+                    {
+                        "description": "Code from the authenticator app",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.twoFactorCodeInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.recoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body, validation error, invalid code or enrollment not started",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "409": {
+                        "description": "Two-factor authentication is already enabled",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/me/2fa/disable": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Disable two-factor authentication with a code from the authenticator app or a recovery code",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Disable two-factor authentication",
+                "parameters": [
+                    {
+                        "description": "Code from the authenticator app or a recovery code",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.twoFactorCodeInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body, validation error, invalid code or two-factor authentication not enabled",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/me/2fa/enroll": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Generate a TOTP secret and an otpauth URI for the authenticator app. Two-factor authentication is enabled only after /confirm",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Enroll two-factor authentication",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.twoFactorEnrollmentResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "409": {
+                        "description": "Two-factor authentication is already enabled",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/me/api-keys": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the non-revoked API keys of the current user. Secrets are never returned",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List API keys",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.apiKeysResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Issue an API key for machine-to-machine access with the given scopes and optional expiry. The key is returned only once and is sent in the X-API-Key header",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Create API key",
+                "parameters": [
+                    {
+                        "description": "Key name, scopes and expiry",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.createAPIKeyInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/v1.createdAPIKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body, validation error, unknown scope or expiry in the past",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/me/api-keys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Revoke an API key of the current user. Requests with it are rejected immediately",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Revoke API key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "API key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "API key not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/me/password": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Change the password of the authenticated user. The current password is required. All sessions are revoked, so the user has to sign in again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Change password",
+                "parameters": [
+                    {
+                        "description": "Current and new password",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.changePasswordInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body, validation error or wrong current password",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "422": {
+                        "description": "Password does not meet the policy",
+                        "schema": {
+                            "$ref": "#/definitions/v1.PasswordPolicyResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallet/balance": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Retrieve the wallet balance of the authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Get wallet balance",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.walletBalanceResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallet/deposit": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Top up the wallet of the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Deposit to wallet",
+                "parameters": [
+                    {
+                        "description": "Deposit amount",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.walletAmountInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.walletBalanceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/wallet/withdraw": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Withdraw funds from the wallet of the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Withdraw from wallet",
+                "parameters": [
+                    {
+                        "description": "Withdraw amount",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.walletAmountInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.walletBalanceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "402": {
+                        "description": "Not enough balance",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/warehouses": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "APIKeyHeader": []
+                    }
+                ],
+                "description": "Get all warehouses, highest priority first. The first warehouse receives stock for which no warehouse is given",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouses"
+                ],
+                "summary": "List warehouses",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/v1.warehouseResponse"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Seller or admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Add a warehouse with a unique name, allocation priority and optional location used by the nearest allocation strategy",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouses"
+                ],
+                "summary": "Create warehouse",
+                "parameters": [
+                    {
+                        "description": "Warehouse",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.warehouseInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/v1.warehouseRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "409": {
+                        "description": "Warehouse with this name already exists",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/warehouses/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replace the name, priority and location of a warehouse. Stock in the warehouse is not affected",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouses"
+                ],
+                "summary": "Update warehouse",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Warehouse ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Warehouse",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.warehouseInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID, request body or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "404": {
+                        "description": "Warehouse not found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "409": {
+                        "description": "Warehouse with this name already exists",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/2fa/verify": {
+            "post": {
+                "description": "Exchange the challenge token from /auth/sign-in and a code from the authenticator app (or a recovery code) for a JWT access token and a refresh token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Verify second factor",
+                "parameters": [
+                    {
+                        "description": "Challenge token and code",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.verifyTwoFactorInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/v1.tokensResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired challenge token, or invalid code",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed attempts, retry after the Retry-After header",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/forgot-password": {
+            "post": {
+                "description": "Send a password reset link to every account with this email. The response does not reveal whether such accounts exist",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Forgot password",
+                "parameters": [
+                    {
+                        "description": "Email",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.emailInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "description": "Revoke the session of the refresh token. Access tokens issued for the session stop being accepted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Logout",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.refreshTokenInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid refresh token",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/callback": {
+            "get": {
+                "description": "Finish the OpenID Connect login: the provider redirects here with a code, which is exchanged for the JWT access token and refresh token. On the first login the external account is linked to the user with the same verified email or a new user is created. If the user has two-factor authentication enabled, a challenge token is returned instead and has to be exchanged via /auth/2fa/verify",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Identity provider callback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "State from the login redirect",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.tokensResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Second factor required",
+                        "schema": {
+                            "$ref": "#/definitions/v1.twoFactorChallengeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing parameters or invalid login state",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "401": {
+                        "description": "Login denied by the provider or invalid ID token",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/login": {
+            "get": {
+                "description": "Start the OpenID Connect authorization code flow with PKCE and redirect to the identity provider",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Sign in with the company identity provider",
+                "responses": {
+                    "302": {
+                        "description": "Redirect to the identity provider"
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "description": "Exchange a refresh token for a new access token and a rotated refresh token. Reusing an already exchanged refresh token revokes the whole session",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Refresh tokens",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.refreshTokenInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/v1.tokensResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid, expired, reused or revoked refresh token",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/resend-verification": {
+            "post": {
+                "description": "Send a new verification link to every unverified account with this email. The response does not reveal whether such accounts exist",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Resend verification email",
+                "parameters": [
+                    {
+                        "description": "Email",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.emailInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/reset-password": {
+            "post": {
+                "description": "Set a new password using the single-use token from the reset email. All sessions of the user are revoked",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Reset password",
+                "parameters": [
+                    {
+                        "description": "Reset token and new password",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.resetPasswordInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body, validation error or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "422": {
+                        "description": "Password does not meet the policy",
+                        "schema": {
+                            "$ref": "#/definitions/v1.PasswordPolicyResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sign-in": {
+            "post": {
+                "description": "Authenticate a user and return a JWT access token and a refresh token. If two-factor authentication is enabled, a challenge token is returned instead and has to be exchanged via /auth/2fa/verify",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "User login",
+                "parameters": [
+                    {
+                        "description": "User login input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.signInInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/v1.tokensResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Second factor required",
+                        "schema": {
+                            "$ref": "#/definitions/v1.twoFactorChallengeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid credentials or bad request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed attempts, retry after the Retry-After header",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sign-up": {
+            "post": {
+                "description": "Register a new user with username, password, and email. A verification link is sent to the email; purchases are blocked until it is confirmed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "User registration",
+                "parameters": [
+                    {
+                        "description": "User registration input",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.signUpInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/v1.authRoutes"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "422": {
+                        "description": "Password does not meet the policy",
+                        "schema": {
+                            "$ref": "#/definitions/v1.PasswordPolicyResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/verify-email": {
+            "get": {
+                "description": "Confirm the email address using the single-use token from the verification email",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Verify email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Verification token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid, used or expired token",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ErrorResonse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "entity.APIKeyScope": {
+            "type": "string",
+            "enum": [
+                "products:read",
+                "products:write",
+                "purchases:read",
+                "purchases:write",
+                "wallet:read",
+                "wallet:write",
+                "seller:read"
+            ],
+            "x-enum-varnames": [
+                "ScopeProductsRead",
+                "ScopeProductsWrite",
+                "ScopePurchasesRead",
+                "ScopePurchasesWrite",
+                "ScopeWalletRead",
+                "ScopeWalletWrite",
+                "ScopeSellerRead"
+            ]
+        },
+        "entity.InventoryMovementKind": {
+            "type": "string",
+            "enum": [
+                "restock",
+                "sale",
+                "refund",
+                "adjustment"
+            ],
+            "x-enum-varnames": [
+                "InventoryMovementRestock",
+                "InventoryMovementSale",
+                "InventoryMovementRefund",
+                "InventoryMovementAdjustment"
+            ]
+        },
+        "entity.OrderStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "paid",
+                "shipped",
+                "delivered",
+                "cancelled",
+                "refunded"
+            ],
+            "x-enum-varnames": [
+                "OrderStatusPending",
+                "OrderStatusPaid",
+                "OrderStatusShipped",
+                "OrderStatusDelivered",
+                "OrderStatusCancelled",
+                "OrderStatusRefunded"
+            ]
+        },
+        "entity.ProductSales": {
+            "type": "object",
+            "properties": {
+                "productID": {
+                    "type": "integer"
+                },
+                "productName": {
+                    "type": "string"
+                },
+                "revenue": {
+                    "type": "number"
+                },
+                "unitsSold": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.ReservationStatus": {
+            "type": "string",
+            "enum": [
+                "active",
+                "confirmed",
+                "released",
+                "expired"
+            ],
+            "x-enum-varnames": [
+                "ReservationStatusActive",
+                "ReservationStatusConfirmed",
+                "ReservationStatusReleased",
+                "ReservationStatusExpired"
+            ]
+        },
+        "entity.Role": {
+            "type": "string",
+            "enum": [
+                "buyer",
+                "seller",
+                "admin"
+            ],
+            "x-enum-varnames": [
+                "RoleBuyer",
+                "RoleSeller",
+                "RoleAdmin"
+            ]
+        },
+        "entity.SellerSalesReport": {
+            "type": "object",
+            "properties": {
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.ProductSales"
+                    }
+                },
+                "revenue": {
+                    "type": "number"
+                },
+                "unitsSold": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.StockWarning": {
+            "type": "string",
+            "enum": [
+                "out_of_stock",
+                "not_enough_stock"
+            ],
+            "x-enum-varnames": [
+                "StockWarningOutOfStock",
+                "StockWarningNotEnoughStock"
+            ]
+        },
+        "jwtkeys.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                }
+            }
+        },
+        "jwtkeys.JWKSet": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/jwtkeys.JWK"
+                    }
+                }
+            }
+        },
+        "ledger.Account": {
+            "type": "object",
+            "properties": {
+                "owner_id": {
+                    "type": "integer"
+                },
+                "type": {
+                    "$ref": "#/definitions/ledger.AccountType"
+                }
+            }
+        },
+        "ledger.AccountBalance": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "$ref": "#/definitions/ledger.Account"
+                },
+                "balance": {
+                    "type": "integer"
+                }
+            }
+        },
+        "ledger.AccountType": {
+            "type": "string",
+            "enum": [
+                "user_wallet",
+                "marketplace_revenue",
+                "seller_payout",
+                "external"
+            ],
+            "x-enum-varnames": [
+                "AccountUserWallet",
+                "AccountMarketplaceRevenue",
+                "AccountSellerPayout",
+                "AccountExternal"
+            ]
+        },
+        "ledger.Drift": {
+            "type": "object",
+            "properties": {
+                "computed": {
+                    "type": "number"
+                },
+                "difference": {
+                    "type": "number"
+                },
+                "stored": {
+                    "type": "number"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "ledger.Report": {
+            "type": "object",
+            "properties": {
+                "accounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ledger.AccountBalance"
+                    }
+                },
+                "balanced": {
+                    "type": "boolean"
+                },
+                "drifts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ledger.Drift"
+                    }
+                },
+                "trial_balance": {
+                    "type": "integer"
+                }
+            }
+        },
+        "v1.ErrorResonse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.PasswordPolicyResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "violations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.passwordViolation"
+                    }
+                }
+            }
+        },
+        "v1.addCartItemInput": {
+            "type": "object",
+            "required": [
+                "product_id",
+                "quantity"
+            ],
+            "properties": {
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "v1.addProductInput": {
+            "type": "object",
+            "required": [
+                "description",
+                "name",
+                "price",
+                "quantity"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number",
+                    "minimum": 0.01
+                },
+                "quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "v1.adjustStockInput": {
+            "type": "object",
+            "required": [
+                "delta",
+                "kind",
+                "reason"
+            ],
+            "properties": {
+                "delta": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "enum": [
+                        "restock",
+                        "adjustment"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.InventoryMovementKind"
+                        }
+                    ]
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "warehouse_id": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "v1.apiKeyResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.APIKeyScope"
+                    }
+                }
+            }
+        },
+        "v1.apiKeysResponse": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.apiKeyResponse"
+                    }
+                }
+            }
+        },
+        "v1.authRoutes": {
+            "type": "object"
+        },
+        "v1.cartItemResponse": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "subtotal": {
+                    "type": "number"
+                },
+                "warning": {
+                    "$ref": "#/definitions/entity.StockWarning"
+                }
+            }
+        },
+        "v1.cartResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.cartItemResponse"
+                    }
+                },
+                "total": {
+                    "type": "number"
+                }
+            }
+        },
+        "v1.changePasswordInput": {
+            "type": "object",
+            "required": [
+                "new_password",
+                "old_password"
+            ],
+            "properties": {
+                "new_password": {
+                    "type": "string"
+                },
+                "old_password": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.checkoutInput": {
+            "type": "object",
+            "properties": {
+                "ship_to": {
+                    "$ref": "#/definitions/v1.geoPointInput"
+                }
+            }
+        },
+        "v1.checkoutResponse": {
+            "type": "object",
+            "properties": {
+                "order_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "v1.createAPIKeyInput": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/entity.APIKeyScope"
+                    }
+                }
+            }
+        },
+        "v1.createdAPIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.APIKeyScope"
+                    }
+                }
+            }
+        },
+        "v1.deleteAccountInput": {
+            "type": "object",
+            "required": [
+                "password"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.emailInput": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.geoPointInput": {
+            "type": "object",
+            "required": [
+                "latitude",
+                "longitude"
+            ],
+            "properties": {
+                "latitude": {
+                    "type": "number",
+                    "maximum": 90,
+                    "minimum": -90
+                },
+                "longitude": {
+                    "type": "number",
+                    "maximum": 180,
+                    "minimum": -180
+                }
+            }
+        },
+        "v1.geoPointResponse": {
+            "type": "object",
+            "properties": {
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                }
+            }
+        },
+        "v1.grantRoleInput": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "buyer",
+                        "seller",
+                        "admin"
+                    ]
+                }
+            }
+        },
+        "v1.makePurcahseInput": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "$ref": "#/definitions/v1.makePurchaseItemInput"
+                    }
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "ship_to": {
+                    "$ref": "#/definitions/v1.geoPointInput"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "v1.makePurchaseItemInput": {
+            "type": "object",
+            "required": [
+                "product_id",
+                "quantity"
+            ],
+            "properties": {
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "v1.orderDetailsResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.orderStatusChangeResponse"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.orderItemResponse"
+                    }
+                },
+                "refunds": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.refundResponse"
+                    }
+                },
+                "status": {
+                    "$ref": "#/definitions/entity.OrderStatus"
+                },
+                "total": {
+                    "type": "number"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "v1.orderItemResponse": {
+            "type": "object",
+            "properties": {
+                "product_id": {
+                    "type": "integer"
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "seller_id": {
+                    "type": "integer"
+                },
+                "unit_price": {
+                    "type": "number"
+                }
+            }
+        },
+        "v1.orderResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.orderItemResponse"
+                    }
+                },
+                "status": {
+                    "$ref": "#/definitions/entity.OrderStatus"
+                },
+                "total": {
+                    "type": "number"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "v1.orderStatusChangeResponse": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "from": {
+                    "$ref": "#/definitions/entity.OrderStatus"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "to": {
+                    "$ref": "#/definitions/entity.OrderStatus"
+                }
+            }
+        },
+        "v1.orderStatusInput": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500
+                }
+            }
+        },
+        "v1.ordersResponse": {
+            "type": "object",
+            "properties": {
+                "orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.orderResponse"
+                    }
+                }
+            }
+        },
+        "v1.passwordViolation": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.productRoutes": {
+            "type": "object"
+        },
+        "v1.purchaseRoutes": {
+            "type": "object"
+        },
+        "v1.recoveryCodesResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "v1.refreshTokenInput": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.refundInput": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "$ref": "#/definitions/v1.refundItemInput"
+                    }
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500
+                }
+            }
+        },
+        "v1.refundItemInput": {
+            "type": "object",
+            "required": [
+                "product_id",
+                "quantity"
+            ],
+            "properties": {
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "v1.refundItemResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
                 }
             }
         },
-        "v1.addProductInput": {
+        "v1.refundResponse": {
             "type": "object",
-            "required": [
-                "description",
-                "name",
-                "price",
-                "quantity"
-            ],
             "properties": {
-                "description": {
-                    "type": "string"
+                "actor_id": {
+                    "type": "integer"
                 },
-                "name": {
+                "amount": {
+                    "type": "number"
+                },
+                "created_at": {
                     "type": "string"
                 },
-                "price": {
-                    "type": "number"
+                "id": {
+                    "type": "integer"
                 },
-                "quantity": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.refundItemResponse"
+                    }
+                },
+                "order_id": {
                     "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
                 }
             }
         },
-        "v1.authRoutes": {
-            "type": "object"
+        "v1.reorderThresholdInput": {
+            "type": "object",
+            "properties": {
+                "threshold": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
         },
-        "v1.makePurcahseInput": {
+        "v1.reservationItemResponse": {
             "type": "object",
             "properties": {
                 "product_id": {
@@ -537,16 +4636,67 @@ const docTemplate = `{
                 },
                 "quantity": {
                     "type": "integer"
+                }
+            }
+        },
+        "v1.reservationResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
                 },
-                "user_id": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.reservationItemResponse"
+                    }
+                },
+                "order_id": {
                     "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/entity.ReservationStatus"
                 }
             }
         },
-        "v1.productRoutes": {
-            "type": "object"
+        "v1.reserveInput": {
+            "type": "object",
+            "required": [
+                "items"
+            ],
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/v1.makePurchaseItemInput"
+                    }
+                }
+            }
         },
-        "v1.purchaseRoutes": {
+        "v1.resetPasswordInput": {
+            "type": "object",
+            "required": [
+                "password",
+                "token"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.sellerRoutes": {
             "type": "object"
         },
         "v1.signInInput": {
@@ -583,13 +4733,149 @@ const docTemplate = `{
                 }
             }
         },
+        "v1.stockAlertResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_channels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "notified_at": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "resolved_at": {
+                    "type": "string"
+                },
+                "threshold": {
+                    "type": "integer"
+                }
+            }
+        },
+        "v1.stockMovementResponse": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delta": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "$ref": "#/definitions/entity.InventoryMovementKind"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "quantity_after": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "warehouse_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "v1.stockResponse": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "v1.tokensResponse": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.twoFactorChallengeResponse": {
+            "type": "object",
+            "properties": {
+                "challenge_token": {
+                    "type": "string"
+                },
+                "two_factor_required": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "v1.twoFactorCodeInput": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.twoFactorEnrollmentResponse": {
+            "type": "object",
+            "properties": {
+                "otpauth_uri": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.updateCartItemInput": {
+            "type": "object",
+            "required": [
+                "quantity"
+            ],
+            "properties": {
+                "quantity": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
         "v1.updateProductInput": {
             "type": "object",
             "required": [
                 "description",
                 "name",
-                "price",
-                "quantity"
+                "price"
             ],
             "properties": {
                 "description": {
@@ -599,15 +4885,156 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "price": {
+                    "type": "number",
+                    "minimum": 0.01
+                }
+            }
+        },
+        "v1.updateProfileInput": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string",
+                    "minLength": 1
+                }
+            }
+        },
+        "v1.userProfileResponse": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "number"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "email_verified": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Role"
+                    }
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.userRolesResponse": {
+            "type": "object",
+            "properties": {
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.Role"
+                    }
+                }
+            }
+        },
+        "v1.verifyTwoFactorInput": {
+            "type": "object",
+            "required": [
+                "challenge_token",
+                "code"
+            ],
+            "properties": {
+                "challenge_token": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.walletAmountInput": {
+            "type": "object",
+            "required": [
+                "amount"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number"
+                }
+            }
+        },
+        "v1.walletBalanceResponse": {
+            "type": "object",
+            "properties": {
+                "balance": {
                     "type": "number"
+                }
+            }
+        },
+        "v1.warehouseInput": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "location": {
+                    "$ref": "#/definitions/v1.geoPointInput"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "priority": {
+                    "type": "integer"
+                }
+            }
+        },
+        "v1.warehouseResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "location": {
+                    "$ref": "#/definitions/v1.geoPointResponse"
+                },
+                "name": {
+                    "type": "string"
                 },
+                "priority": {
+                    "type": "integer"
+                }
+            }
+        },
+        "v1.warehouseRoutes": {
+            "type": "object"
+        },
+        "v1.warehouseStockResponse": {
+            "type": "object",
+            "properties": {
                 "quantity": {
                     "type": "integer"
+                },
+                "warehouse_id": {
+                    "type": "integer"
+                },
+                "warehouse_name": {
+                    "type": "string"
                 }
             }
         }
     },
     "securityDefinitions": {
+        "APIKeyHeader": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
         "ApiKeyAuth": {
             "type": "apiKey",
             "name": "Authorization",
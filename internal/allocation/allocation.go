@@ -0,0 +1,158 @@
+// Package allocation выбирает склады, с которых отгружается строка заказа.
+// Порядок складов задает стратегия: ближайший к покупателю, с наибольшим
+// остатком или с наибольшим приоритетом.
+package allocation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+)
+
+var ErrNotEnoughStock = errors.New("not enough stock in warehouses")
+
+// Candidate - склад, на котором есть товар.
+type Candidate struct {
+	WarehouseID int
+	Quantity    int
+	Priority    int
+	Location    *entity.GeoPoint
+}
+
+// Allocation - сколько единиц строки отгружается со склада.
+type Allocation struct {
+	WarehouseID int
+	Quantity    int
+}
+
+// Strategy упорядочивает склады от самого подходящего к наименее
+// подходящему. destination может быть nil. Rank не меняет candidates.
+type Strategy interface {
+	Rank(candidates []Candidate, destination *entity.GeoPoint) []Candidate
+}
+
+// New возвращает стратегию по имени из конфигурации.
+func New(name string) (Strategy, error) {
+	switch name {
+	case "priority":
+		return Priority{}, nil
+	case "most_stock":
+		return MostStock{}, nil
+	case "nearest":
+		return Nearest{}, nil
+	}
+
+	return nil, fmt.Errorf("unknown allocation strategy %q", name)
+}
+
+// Allocate распределяет quantity единиц по складам. Если какой-то склад может
+// отгрузить все сам, выбирается первый такой склад в порядке стратегии, чтобы
+// не дробить отправку. Иначе единицы набираются со складов по порядку.
+func Allocate(strategy Strategy, candidates []Candidate, destination *entity.GeoPoint, quantity int) ([]Allocation, error) {
+	ranked := strategy.Rank(candidates, destination)
+
+	for _, candidate := range ranked {
+		if candidate.Quantity >= quantity {
+			return []Allocation{{WarehouseID: candidate.WarehouseID, Quantity: quantity}}, nil
+		}
+	}
+
+	var allocations []Allocation
+	for _, candidate := range ranked {
+		if quantity == 0 {
+			break
+		}
+		if candidate.Quantity <= 0 {
+			continue
+		}
+
+		take := min(candidate.Quantity, quantity)
+		allocations = append(allocations, Allocation{WarehouseID: candidate.WarehouseID, Quantity: take})
+		quantity -= take
+	}
+	if quantity > 0 {
+		return nil, ErrNotEnoughStock
+	}
+
+	return allocations, nil
+}
+
+// Priority выбирает склады по убыванию приоритета.
+type Priority struct{}
+
+func (Priority) Rank(candidates []Candidate, _ *entity.GeoPoint) []Candidate {
+	ranked := append([]Candidate(nil), candidates...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return byPriority(ranked[i], ranked[j])
+	})
+	return ranked
+}
+
+// MostStock выбирает склады по убыванию остатка, при равном остатке - по
+// приоритету.
+type MostStock struct{}
+
+func (MostStock) Rank(candidates []Candidate, _ *entity.GeoPoint) []Candidate {
+	ranked := append([]Candidate(nil), candidates...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Quantity != ranked[j].Quantity {
+			return ranked[i].Quantity > ranked[j].Quantity
+		}
+		return byPriority(ranked[i], ranked[j])
+	})
+	return ranked
+}
+
+// Nearest выбирает склады по расстоянию до покупателя. Склады без координат
+// идут после остальных, а без адреса доставки стратегия работает как Priority.
+type Nearest struct{}
+
+func (Nearest) Rank(candidates []Candidate, destination *entity.GeoPoint) []Candidate {
+	if destination == nil {
+		return Priority{}.Rank(candidates, nil)
+	}
+
+	ranked := append([]Candidate(nil), candidates...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i].Location, ranked[j].Location
+		switch {
+		case a == nil && b == nil:
+			return byPriority(ranked[i], ranked[j])
+		case a == nil || b == nil:
+			return b == nil
+		}
+
+		da, db := Distance(*a, *destination), Distance(*b, *destination)
+		if da != db {
+			return da < db
+		}
+		return byPriority(ranked[i], ranked[j])
+	})
+	return ranked
+}
+
+// earthRadiusKm - средний радиус Земли.
+const earthRadiusKm = 6371.0
+
+// Distance возвращает расстояние между точками по большому кругу в
+// километрах.
+func Distance(a, b entity.GeoPoint) float64 {
+	lat1, lat2 := a.Latitude*math.Pi/180, b.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// byPriority сравнивает склады по убыванию приоритета, при равном приоритете -
+// по возрастанию id, чтобы порядок был детерминированным.
+func byPriority(a, b Candidate) bool {
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	return a.WarehouseID < b.WarehouseID
+}
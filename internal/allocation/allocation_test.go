@@ -0,0 +1,137 @@
+package allocation
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+)
+
+var (
+	moscow = &entity.GeoPoint{Latitude: 55.7558, Longitude: 37.6173}
+	kazan  = &entity.GeoPoint{Latitude: 55.7963, Longitude: 49.1088}
+	spb    = &entity.GeoPoint{Latitude: 59.9343, Longitude: 30.3351}
+)
+
+func TestAllocate(t *testing.T) {
+	candidates := []Candidate{
+		{WarehouseID: 1, Quantity: 3, Priority: 10, Location: moscow},
+		{WarehouseID: 2, Quantity: 8, Priority: 5, Location: spb},
+		{WarehouseID: 3, Quantity: 5, Priority: 5, Location: kazan},
+		{WarehouseID: 4, Quantity: 20, Priority: 0},
+	}
+
+	testCases := []struct {
+		name        string
+		strategy    Strategy
+		destination *entity.GeoPoint
+		quantity    int
+		want        []Allocation
+		wantErr     error
+	}{
+		{
+			name:     "Priority",
+			strategy: Priority{},
+			quantity: 2,
+			want:     []Allocation{{WarehouseID: 1, Quantity: 2}},
+		},
+		{
+			name:     "Priority skips a warehouse that cannot cover the line",
+			strategy: Priority{},
+			quantity: 4,
+			want:     []Allocation{{WarehouseID: 2, Quantity: 4}},
+		},
+		{
+			name:     "Most stock",
+			strategy: MostStock{},
+			quantity: 2,
+			want:     []Allocation{{WarehouseID: 4, Quantity: 2}},
+		},
+		{
+			name:        "Nearest",
+			strategy:    Nearest{},
+			destination: &entity.GeoPoint{Latitude: 55.79, Longitude: 49.12},
+			quantity:    2,
+			want:        []Allocation{{WarehouseID: 3, Quantity: 2}},
+		},
+		{
+			name:     "Nearest without destination falls back to priority",
+			strategy: Nearest{},
+			quantity: 2,
+			want:     []Allocation{{WarehouseID: 1, Quantity: 2}},
+		},
+		{
+			name:     "Split in strategy order",
+			strategy: Priority{},
+			quantity: 30,
+			want: []Allocation{
+				{WarehouseID: 1, Quantity: 3},
+				{WarehouseID: 2, Quantity: 8},
+				{WarehouseID: 3, Quantity: 5},
+				{WarehouseID: 4, Quantity: 14},
+			},
+		},
+		{
+			name:     "Not enough stock",
+			strategy: MostStock{},
+			quantity: 37,
+			wantErr:  ErrNotEnoughStock,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Allocate(tc.strategy, candidates, tc.destination, tc.quantity)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Allocate() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Allocate() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNearest_Rank(t *testing.T) {
+	candidates := []Candidate{
+		{WarehouseID: 1, Priority: 10},
+		{WarehouseID: 2, Location: spb},
+		{WarehouseID: 3, Location: kazan},
+		{WarehouseID: 4, Priority: 20},
+	}
+
+	var got []int
+	for _, candidate := range (Nearest{}).Rank(candidates, moscow) {
+		got = append(got, candidate.WarehouseID)
+	}
+
+	// Склады без координат идут последними, между собой - по приоритету.
+	want := []int{2, 3, 4, 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Rank() = %v, want %v", got, want)
+	}
+	if candidates[0].WarehouseID != 1 {
+		t.Errorf("Rank() reordered its input: %+v", candidates)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	got := Distance(*moscow, *spb)
+	if math.Abs(got-634) > 5 {
+		t.Errorf("Distance(Moscow, Saint Petersburg) = %.0f km, want about 634 km", got)
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, name := range []string{"priority", "most_stock", "nearest"} {
+		if _, err := New(name); err != nil {
+			t.Errorf("New(%q) error = %v", name, err)
+		}
+	}
+	if _, err := New("random"); err == nil {
+		t.Error("New(\"random\") error = nil, want an error")
+	}
+}
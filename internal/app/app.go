@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
@@ -12,12 +13,15 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/cripplemymind9/go-market/config"
+	"github.com/cripplemymind9/go-market/internal/allocation"
 	v1 "github.com/cripplemymind9/go-market/internal/controller/http/v1"
 	"github.com/cripplemymind9/go-market/internal/repository"
+	"github.com/cripplemymind9/go-market/internal/repository/memory"
 	"github.com/cripplemymind9/go-market/internal/service"
-	"github.com/cripplemymind9/go-market/pkg/hasher"
+	"github.com/cripplemymind9/go-market/internal/service/impl"
 	"github.com/cripplemymind9/go-market/pkg/httpserver"
 	"github.com/cripplemymind9/go-market/pkg/postgres"
+	"github.com/cripplemymind9/go-market/pkg/signedtoken"
 )
 
 func Run(configPath string) {
@@ -44,24 +48,117 @@ func Run(configPath string) {
 		log.WithError(err).Fatal("Failed to initialize migrations")
 	}
 
+	// Warehouse allocation
+	allocationStrategy, err := allocation.New(cfg.Warehouse.Allocation)
+	if err != nil {
+		log.WithError(fmt.Errorf("app - Run - allocation.New: %w", err)).Fatal("Failed to initialize warehouse allocation")
+	}
+
 	// Repositories
 	log.Info("Initializing repositories...")
-	repositories := repository.NewRepositories(pg)
+	repositories := repository.NewRepositories(pg, allocationStrategy)
+	switch cfg.LoginThrottle.Store {
+	case "postgres":
+	case "memory":
+		repositories.LoginAttempt = memory.NewLoginAttemptRepo()
+	default:
+		log.Fatalf("Unknown login throttle store %q", cfg.LoginThrottle.Store)
+	}
+
+	// JWT keys
+	jwtKeys, err := NewJWTKeySet(cfg.JWT)
+	if err != nil {
+		log.WithError(fmt.Errorf("app - Run - NewJWTKeySet: %w", err)).Fatal("Failed to load JWT keys")
+	}
+
+	// Password hasher
+	passwordHasher, err := NewPasswordHasher(cfg.Password)
+	if err != nil {
+		log.WithError(fmt.Errorf("app - Run - NewPasswordHasher: %w", err)).Fatal("Failed to initialize password hasher")
+	}
+
+	// Password policy
+	passwordPolicy, err := NewPasswordPolicy(cfg.Password)
+	if err != nil {
+		log.WithError(fmt.Errorf("app - Run - NewPasswordPolicy: %w", err)).Fatal("Failed to initialize password policy")
+	}
+
+	// Mailer
+	mailSender, err := NewMailer(cfg.Mail)
+	if err != nil {
+		log.WithError(fmt.Errorf("app - Run - NewMailer: %w", err)).Fatal("Failed to initialize mailer")
+	}
+
+	// Stock alert notifier
+	stockAlertNotifier, err := NewStockAlertNotifier(cfg.StockAlert, mailSender)
+	if err != nil {
+		log.WithError(fmt.Errorf("app - Run - NewStockAlertNotifier: %w", err)).Fatal("Failed to initialize stock alert notifier")
+	}
+
+	// OIDC
+	oidcClient, err := NewOIDCClient(cfg.OIDC)
+	if err != nil {
+		log.WithError(fmt.Errorf("app - Run - NewOIDCClient: %w", err)).Fatal("Failed to initialize OIDC client")
+	}
 
 	// Services dependencies
 	deps := service.ServiceDependencies{
-		Repos:    *repositories,
-		Hasher:   hasher.NewBcryptHasher(),
-		SignKey:  cfg.JWT.SignKey,
-		TokenTTL: cfg.JWT.TokenTTL,
+		Repos:            *repositories,
+		Hasher:           passwordHasher,
+		PasswordPolicy:   passwordPolicy,
+		Mailer:           mailSender,
+		EmailTokenSigner: signedtoken.NewSigner([]byte(cfg.Mail.TokenSignKey)),
+		Account: impl.AccountConfig{
+			VerifyEmailURL:   cfg.Mail.VerifyEmailURL,
+			ResetPasswordURL: cfg.Mail.ResetPasswordURL,
+			VerifyEmailTTL:   cfg.Mail.VerifyEmailTTL,
+			ResetPasswordTTL: cfg.Mail.ResetPasswordTTL,
+		},
+		JWTKeys:         jwtKeys,
+		TokenTTL:        cfg.JWT.TokenTTL,
+		RefreshTokenTTL: cfg.JWT.RefreshTokenTTL,
+
+		TwoFactorIssuer:       cfg.TwoFactor.Issuer,
+		TwoFactorChallengeTTL: cfg.TwoFactor.ChallengeTTL,
+
+		OIDCClient: oidcClient,
+		OIDC: impl.OIDCConfig{
+			Provider:    cfg.OIDC.Provider,
+			LinkByEmail: cfg.OIDC.LinkByEmail,
+			StateTTL:    cfg.OIDC.StateTTL,
+		},
+
+		IdempotencyKeyTTL: cfg.Idempotency.KeyTTL,
+		ReservationTTL:    cfg.Reservation.TTL,
+
+		StockAlertNotifier: stockAlertNotifier,
 	}
 	services := service.NewServices(deps)
 
+	// Reservation sweeper
+	sweeperCtx, stopSweeper := context.WithCancel(context.Background())
+	sweeperDone := make(chan struct{})
+	go func() {
+		defer close(sweeperDone)
+		RunReservationSweeper(sweeperCtx, services.Reservation, cfg.Reservation.SweepInterval)
+	}()
+
+	// Stock alert dispatcher
+	dispatcherCtx, stopDispatcher := context.WithCancel(context.Background())
+	dispatcherDone := make(chan struct{})
+	go func() {
+		defer close(dispatcherDone)
+		RunStockAlertDispatcher(dispatcherCtx, services.StockAlert, cfg.StockAlert.DispatchInterval)
+	}()
+
 	// Validator
 	validator := validator.New()
 
 	// Gin router
 	router := gin.Default()
+	if err = router.SetTrustedProxies(cfg.HTTP.TrustedProxies); err != nil {
+		log.WithError(fmt.Errorf("app - Run - router.SetTrustedProxies: %w", err)).Fatal("Failed to configure trusted proxies")
+	}
 	router.Use(func(c *gin.Context) {
 		c.Set("validator", validator)
 		c.Next()
@@ -89,4 +186,12 @@ func Run(configPath string) {
 	if err := httpServer.Shutdown(); err != nil {
 		log.WithError(fmt.Errorf("app - Run - httpServer.Shutdown: %w", err)).Error("Failed to shutdown HTTP server")
 	}
+
+	log.Info("Stopping reservation sweeper...")
+	stopSweeper()
+	<-sweeperDone
+
+	log.Info("Stopping stock alert dispatcher...")
+	stopDispatcher()
+	<-dispatcherDone
 }
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/cripplemymind9/go-market/config"
+	"github.com/cripplemymind9/go-market/pkg/hasher"
+	"github.com/cripplemymind9/go-market/pkg/passwordpolicy"
+)
+
+// NewPasswordHasher возвращает хэшер, который хэширует пароли выбранным в
+// конфигурации алгоритмом и при этом проверяет хэши всех поддерживаемых.
+func NewPasswordHasher(cfg config.Password) (hasher.PasswordHasher, error) {
+	argon := hasher.NewArgon2Hasher(hasher.Argon2Params{
+		Memory:      cfg.Argon2Memory,
+		Iterations:  cfg.Argon2Iterations,
+		Parallelism: cfg.Argon2Parallelism,
+		SaltLength:  hasher.DefaultArgon2Params.SaltLength,
+		KeyLength:   hasher.DefaultArgon2Params.KeyLength,
+	})
+	bcrypt := hasher.NewBcryptHasher()
+
+	switch cfg.Algorithm {
+	case "argon2id":
+		return hasher.NewMultiHasher(argon, bcrypt), nil
+	case "bcrypt":
+		return hasher.NewMultiHasher(bcrypt, argon), nil
+	}
+
+	return nil, fmt.Errorf("unknown password hashing algorithm %q", cfg.Algorithm)
+}
+
+// NewPasswordPolicy собирает политику паролей из конфигурации. Пароли длиннее
+// 72 байт bcrypt не различает, поэтому больший предел не допускается.
+func NewPasswordPolicy(cfg config.Password) (passwordpolicy.Policy, error) {
+	if cfg.MaxBytes <= 0 || cfg.MaxBytes > passwordpolicy.BcryptMaxBytes {
+		return passwordpolicy.Policy{}, fmt.Errorf("password max bytes must be between 1 and %d, got %d", passwordpolicy.BcryptMaxBytes, cfg.MaxBytes)
+	}
+	if cfg.MinLength > cfg.MaxBytes {
+		return passwordpolicy.Policy{}, fmt.Errorf("password min length %d exceeds max bytes %d", cfg.MinLength, cfg.MaxBytes)
+	}
+
+	policy := passwordpolicy.Policy{
+		MinLength:     cfg.MinLength,
+		MaxBytes:      cfg.MaxBytes,
+		RequireUpper:  cfg.RequireUpper,
+		RequireLower:  cfg.RequireLower,
+		RequireDigit:  cfg.RequireDigit,
+		RequireSymbol: cfg.RequireSymbol,
+	}
+	if cfg.DenyCommon {
+		policy.DenyList = passwordpolicy.CommonPasswords()
+	}
+
+	return policy, nil
+}
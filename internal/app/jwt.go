@@ -0,0 +1,30 @@
+package app
+
+import (
+	"errors"
+
+	"github.com/cripplemymind9/go-market/config"
+	"github.com/cripplemymind9/go-market/pkg/jwtkeys"
+)
+
+// NewJWTKeySet собирает ключи подписи токенов. Если задан каталог ключей,
+// токены подписываются асимметричным ключом ActiveKeyID, а SignKey (если есть)
+// остается лишь для проверки токенов, выданных до перехода. Без каталога
+// токены по-прежнему подписываются HS256 ключом SignKey.
+func NewJWTKeySet(cfg config.JWT) (*jwtkeys.KeySet, error) {
+	var legacy []jwtkeys.Key
+	if cfg.SignKey != "" {
+		// Старые токены выпускались без kid, поэтому HMAC-ключ идет с пустым ID.
+		legacy = append(legacy, jwtkeys.NewHMACKey("", []byte(cfg.SignKey)))
+	}
+
+	if cfg.KeysDir != "" {
+		return jwtkeys.LoadDir(cfg.KeysDir, cfg.ActiveKeyID, legacy...)
+	}
+
+	if legacy == nil {
+		return nil, errors.New("either JWT_KEYS_DIR or JWT_SIGN_KEY must be set")
+	}
+
+	return jwtkeys.NewKeySet(legacy[0])
+}
@@ -0,0 +1,23 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cripplemymind9/go-market/config"
+	"github.com/cripplemymind9/go-market/pkg/mailer"
+)
+
+func NewMailer(cfg config.Mail) (mailer.Mailer, error) {
+	switch cfg.Sender {
+	case "smtp":
+		if cfg.SMTPHost == "" {
+			return nil, errors.New("MAIL_SMTP_HOST must be set for the smtp sender")
+		}
+		return mailer.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From), nil
+	case "file":
+		return mailer.NewFileMailer(cfg.FileDir, cfg.From), nil
+	}
+
+	return nil, fmt.Errorf("unknown mail sender %q", cfg.Sender)
+}
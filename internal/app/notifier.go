@@ -0,0 +1,33 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cripplemymind9/go-market/config"
+	"github.com/cripplemymind9/go-market/internal/notifier"
+	"github.com/cripplemymind9/go-market/pkg/mailer"
+)
+
+func NewStockAlertNotifier(cfg config.StockAlert, mailSender mailer.Mailer) (notifier.Multi, error) {
+	var notifiers notifier.Multi
+	for _, name := range cfg.Notifiers {
+		var n notifier.Notifier
+		switch name {
+		case "log":
+			n = notifier.NewLogNotifier()
+		case "webhook":
+			if cfg.WebhookURL == "" {
+				return nil, errors.New("STOCK_ALERT_WEBHOOK_URL must be set for the webhook notifier")
+			}
+			n = notifier.NewWebhookNotifier(cfg.WebhookURL, cfg.WebhookSecret, cfg.WebhookTimeout)
+		case "email":
+			n = notifier.NewEmailNotifier(mailSender)
+		default:
+			return nil, fmt.Errorf("unknown stock alert notifier %q", name)
+		}
+		notifiers = append(notifiers, notifier.Channel{Name: name, Notifier: n})
+	}
+
+	return notifiers, nil
+}
@@ -0,0 +1,28 @@
+package app
+
+import (
+	"errors"
+
+	"github.com/cripplemymind9/go-market/config"
+	"github.com/cripplemymind9/go-market/pkg/oidc"
+)
+
+// NewOIDCClient создает клиент внешнего провайдера входа. Если вход через
+// провайдера выключен, возвращает nil.
+func NewOIDCClient(cfg config.OIDC) (*oidc.Client, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	if cfg.Issuer == "" || cfg.ClientID == "" || cfg.RedirectURL == "" {
+		return nil, errors.New("OIDC_ISSUER, OIDC_CLIENT_ID and OIDC_REDIRECT_URL must be set when OIDC is enabled")
+	}
+
+	return oidc.NewClient(oidc.Config{
+		Issuer:       cfg.Issuer,
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
+		Scopes:       cfg.Scopes,
+	}), nil
+}
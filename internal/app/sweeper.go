@@ -0,0 +1,54 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cripplemymind9/go-market/internal/service"
+)
+
+// RunReservationSweeper каждые interval снимает истекшие брони, возвращая
+// товары в продажу. Работает, пока не отменен ctx.
+func RunReservationSweeper(ctx context.Context, reservations service.Reservation, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			released, err := reservations.ReleaseExpired(ctx)
+			if err != nil {
+				log.Errorf("app - RunReservationSweeper - reservations.ReleaseExpired: %v", err)
+			}
+			if released > 0 {
+				log.Infof("Released %d expired reservations", released)
+			}
+		}
+	}
+}
+
+// RunStockAlertDispatcher каждые interval отправляет продавцам новые
+// предупреждения о низком остатке. Работает, пока не отменен ctx.
+func RunStockAlertDispatcher(ctx context.Context, alerts service.StockAlert, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sent, err := alerts.DispatchAlerts(ctx)
+			if err != nil {
+				log.Errorf("app - RunStockAlertDispatcher - alerts.DispatchAlerts: %v", err)
+			}
+			if sent > 0 {
+				log.Infof("Dispatched %d stock alerts", sent)
+			}
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/cripplemymind9/go-market/internal/service"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+type accountRoutes struct {
+	accountService service.Account
+	validator      *validator.Validate
+}
+
+func newAccountRoutes(g *gin.RouterGroup, accountService service.Account, validator *validator.Validate) {
+	r := &accountRoutes{
+		accountService: accountService,
+		validator:      validator,
+	}
+
+	g.GET("/verify-email", r.verifyEmail)
+	g.POST("/resend-verification", r.resendVerification)
+	g.POST("/forgot-password", r.forgotPassword)
+	g.POST("/reset-password", r.resetPassword)
+}
+
+// verifyEmail подтверждает адрес почты по ссылке из письма
+// @Summary Verify email
+// @Description Confirm the email address using the single-use token from the verification email
+// @Tags auth
+// @Produce json
+// @Param token query string true "Verification token"
+// @Success 200 {object} map[string]interface{} "Success message"
+// @Failure 400 {object} ErrorResonse "Invalid, used or expired token"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Router /auth/verify-email [get]
+func (r *accountRoutes) verifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		newErrorResponse(c, http.StatusBadRequest, "token is required")
+		return
+	}
+
+	if err := r.accountService.VerifyEmail(c.Request.Context(), token); err != nil {
+		if err == serviceerrs.ErrInvalidEmailToken {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "succes",
+	})
+}
+
+// emailInput представляет собой модель данных для запросов, адресованных по почте.
+type emailInput struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// resendVerification повторно отправляет письмо с подтверждением почты
+// @Summary Resend verification email
+// @Description Send a new verification link to every unverified account with this email. The response does not reveal whether such accounts exist
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body emailInput true "Email"
+// @Success 202 {object} map[string]interface{} "Success message"
+// @Failure 400 {object} ErrorResonse "Invalid request body or validation error"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Router /auth/resend-verification [post]
+func (r *accountRoutes) resendVerification(c *gin.Context) {
+	var input emailInput
+
+	if err := c.ShouldBindBodyWithJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := r.validator.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := r.accountService.ResendVerificationEmail(c.Request.Context(), input.Email); err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, map[string]interface{}{
+		"message": "succes",
+	})
+}
+
+// forgotPassword отправляет ссылку для сброса пароля
+// @Summary Forgot password
+// @Description Send a password reset link to every account with this email. The response does not reveal whether such accounts exist
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body emailInput true "Email"
+// @Success 202 {object} map[string]interface{} "Success message"
+// @Failure 400 {object} ErrorResonse "Invalid request body or validation error"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Router /auth/forgot-password [post]
+func (r *accountRoutes) forgotPassword(c *gin.Context) {
+	var input emailInput
+
+	if err := c.ShouldBindBodyWithJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := r.validator.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := r.accountService.ForgotPassword(c.Request.Context(), input.Email); err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, map[string]interface{}{
+		"message": "succes",
+	})
+}
+
+// resetPasswordInput представляет собой модель данных для сброса пароля.
+type resetPasswordInput struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// resetPassword задает новый пароль по ссылке из письма
+// @Summary Reset password
+// @Description Set a new password using the single-use token from the reset email. All sessions of the user are revoked
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body resetPasswordInput true "Reset token and new password"
+// @Success 200 {object} map[string]interface{} "Success message"
+// @Failure 400 {object} ErrorResonse "Invalid request body, validation error or invalid token"
+// @Failure 422 {object} PasswordPolicyResponse "Password does not meet the policy"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Router /auth/reset-password [post]
+func (r *accountRoutes) resetPassword(c *gin.Context) {
+	var input resetPasswordInput
+
+	if err := c.ShouldBindBodyWithJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := r.validator.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err := r.accountService.ResetPassword(c.Request.Context(), types.AccountResetPasswordInput{
+		Token:    input.Token,
+		Password: input.Password,
+	})
+	if err != nil {
+		if newPasswordPolicyResponse(c, err) {
+			return
+		}
+		if err == serviceerrs.ErrInvalidEmailToken {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "succes",
+	})
+}
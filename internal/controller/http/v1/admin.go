@@ -0,0 +1,165 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/service"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+type adminRoutes struct {
+	roleService service.Role
+	validator   *validator.Validate
+}
+
+func newAdminRoutes(g *gin.RouterGroup, roleService service.Role, validator *validator.Validate) {
+	r := &adminRoutes{
+		roleService: roleService,
+		validator:   validator,
+	}
+
+	g.GET("/users/:id/roles", r.getUserRoles)
+	g.POST("/users/:id/roles", r.grantRole)
+	g.DELETE("/users/:id/roles/:role", r.revokeRole)
+}
+
+type userRolesResponse struct {
+	Roles []entity.Role `json:"roles"`
+}
+
+// getUserRoles возвращает роли пользователя
+// @Summary Get user roles
+// @Description Retrieve the roles assigned to a user
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} userRolesResponse
+// @Failure 400 {object} ErrorResonse "Invalid user ID"
+// @Failure 403 {object} ErrorResonse "Admin role required"
+// @Failure 404 {object} ErrorResonse "User not found"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /api/v1/admin/users/{id}/roles [get]
+func (r *adminRoutes) getUserRoles(c *gin.Context) {
+	param := c.Param("id")
+	id, err := strconv.Atoi(param)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, string(err.Error()))
+		return
+	}
+
+	roles, err := r.roleService.GetUserRoles(c.Request.Context(), id)
+	if err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, userRolesResponse{
+		Roles: roles,
+	})
+}
+
+// grantRoleInput представляет собой модель данных для назначения роли.
+type grantRoleInput struct {
+	Role string `json:"role" validate:"required,oneof=buyer seller admin"`
+}
+
+// grantRole назначает пользователю роль
+// @Summary Grant role
+// @Description Grant a role (buyer, seller or admin) to a user. The user's sessions are revoked, so the new role takes effect on the next sign-in
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param input body grantRoleInput true "Role to grant"
+// @Success 200 {object} map[string]interface{} "Success message"
+// @Failure 400 {object} ErrorResonse "Invalid request body or validation error"
+// @Failure 403 {object} ErrorResonse "Admin role required"
+// @Failure 404 {object} ErrorResonse "User not found"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /api/v1/admin/users/{id}/roles [post]
+func (r *adminRoutes) grantRole(c *gin.Context) {
+	param := c.Param("id")
+	id, err := strconv.Atoi(param)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, string(err.Error()))
+		return
+	}
+
+	var input grantRoleInput
+
+	if err := c.ShouldBindBodyWithJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := r.validator.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := r.roleService.GrantRole(c.Request.Context(), types.RoleGrantRoleInput{
+		UserID: id,
+		Role:   entity.Role(input.Role),
+	}); err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "succes",
+	})
+}
+
+// revokeRole отзывает у пользователя роль
+// @Summary Revoke role
+// @Description Revoke a role from a user. The user's sessions are revoked, so tokens issued with the old role stop working immediately
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Param role path string true "Role to revoke"
+// @Success 200 {object} map[string]interface{} "Success message"
+// @Failure 400 {object} ErrorResonse "Invalid user ID or role"
+// @Failure 403 {object} ErrorResonse "Admin role required"
+// @Failure 404 {object} ErrorResonse "Role is not assigned"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /api/v1/admin/users/{id}/roles/{role} [delete]
+func (r *adminRoutes) revokeRole(c *gin.Context) {
+	param := c.Param("id")
+	id, err := strconv.Atoi(param)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, string(err.Error()))
+		return
+	}
+
+	if err := r.roleService.RevokeRole(c.Request.Context(), types.RoleRevokeRoleInput{
+		UserID: id,
+		Role:   entity.Role(c.Param("role")),
+	}); err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "succes",
+	})
+}
+
+func (r *adminRoutes) handleError(c *gin.Context, err error) {
+	switch err {
+	case serviceerrs.ErrInvalidRole:
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+	case serviceerrs.ErrUserNotFound, serviceerrs.ErrRoleNotAssigned:
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+	default:
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+	}
+}
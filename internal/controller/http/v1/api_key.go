@@ -0,0 +1,196 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/service"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+type apiKeyRoutes struct {
+	apiKeyService service.APIKey
+	validator     *validator.Validate
+}
+
+func newAPIKeyRoutes(g *gin.RouterGroup, apiKeyService service.APIKey, validator *validator.Validate) {
+	r := &apiKeyRoutes{
+		apiKeyService: apiKeyService,
+		validator:     validator,
+	}
+
+	g.POST("", r.create)
+	g.GET("", r.list)
+	g.DELETE("/:id", r.revoke)
+}
+
+// createAPIKeyInput представляет собой модель данных для выпуска API-ключа.
+type createAPIKeyInput struct {
+	Name      string               `json:"name" validate:"required,max=100"`
+	Scopes    []entity.APIKeyScope `json:"scopes" validate:"required,min=1"`
+	ExpiresAt *time.Time           `json:"expires_at"`
+}
+
+type apiKeyResponse struct {
+	ID         int                  `json:"id"`
+	Name       string               `json:"name"`
+	Prefix     string               `json:"prefix"`
+	Scopes     []entity.APIKeyScope `json:"scopes"`
+	ExpiresAt  *time.Time           `json:"expires_at"`
+	LastUsedAt *time.Time           `json:"last_used_at"`
+	CreatedAt  time.Time            `json:"created_at"`
+}
+
+type createdAPIKeyResponse struct {
+	apiKeyResponse
+	Key string `json:"key"`
+}
+
+func newAPIKeyResponse(key entity.APIKey) apiKeyResponse {
+	return apiKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+// create выпускает API-ключ
+// @Summary Create API key
+// @Description Issue an API key for machine-to-machine access with the given scopes and optional expiry. The key is returned only once and is sent in the X-API-Key header
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param input body createAPIKeyInput true "Key name, scopes and expiry"
+// @Success 201 {object} createdAPIKeyResponse
+// @Failure 400 {object} ErrorResonse "Invalid request body, validation error, unknown scope or expiry in the past"
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /api/v1/users/me/api-keys [post]
+func (r *apiKeyRoutes) create(c *gin.Context) {
+	userId, ok := getUserId(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input createAPIKeyInput
+
+	if err := c.ShouldBindBodyWithJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := r.validator.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	created, err := r.apiKeyService.Create(c.Request.Context(), types.APIKeyCreateInput{
+		UserID:    userId,
+		Name:      input.Name,
+		Scopes:    input.Scopes,
+		ExpiresAt: input.ExpiresAt,
+	})
+	if err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, createdAPIKeyResponse{
+		apiKeyResponse: newAPIKeyResponse(created.Key),
+		Key:            created.Token,
+	})
+}
+
+type apiKeysResponse struct {
+	Keys []apiKeyResponse `json:"keys"`
+}
+
+// list возвращает действующие API-ключи пользователя
+// @Summary List API keys
+// @Description Get the non-revoked API keys of the current user. Secrets are never returned
+// @Tags users
+// @Produce json
+// @Success 200 {object} apiKeysResponse
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /api/v1/users/me/api-keys [get]
+func (r *apiKeyRoutes) list(c *gin.Context) {
+	userId, ok := getUserId(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	keys, err := r.apiKeyService.List(c.Request.Context(), userId)
+	if err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	response := apiKeysResponse{Keys: make([]apiKeyResponse, 0, len(keys))}
+	for _, key := range keys {
+		response.Keys = append(response.Keys, newAPIKeyResponse(key))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// revoke отзывает API-ключ
+// @Summary Revoke API key
+// @Description Revoke an API key of the current user. Requests with it are rejected immediately
+// @Tags users
+// @Produce json
+// @Param id path int true "API key ID"
+// @Success 200 {object} map[string]interface{} "Success message"
+// @Failure 400 {object} ErrorResonse "Invalid ID"
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 404 {object} ErrorResonse "API key not found"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /api/v1/users/me/api-keys/{id} [delete]
+func (r *apiKeyRoutes) revoke(c *gin.Context) {
+	userId, ok := getUserId(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	if err = r.apiKeyService.Revoke(c.Request.Context(), userId, id); err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "succes",
+	})
+}
+
+func (r *apiKeyRoutes) handleError(c *gin.Context, err error) {
+	switch err {
+	case serviceerrs.ErrInvalidAPIKeyScope, serviceerrs.ErrInvalidAPIKeyExpiry:
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+	case serviceerrs.ErrAPIKeyNotFound:
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+	default:
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+	}
+}
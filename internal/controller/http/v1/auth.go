@@ -1,7 +1,9 @@
 package v1
 
 import (
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -12,18 +14,31 @@ import (
 )
 
 type authRoutes struct {
-	authService service.Auth
-	validator   *validator.Validate
+	authService     service.Auth
+	accountService  service.Account
+	throttleService service.LoginThrottle
+	validator       *validator.Validate
 }
 
-func newAuthRoutes(g *gin.RouterGroup, authService service.Auth, validator *validator.Validate) {
+func newAuthRoutes(
+	g *gin.RouterGroup,
+	authService service.Auth,
+	accountService service.Account,
+	throttleService service.LoginThrottle,
+	validator *validator.Validate,
+) {
 	r := &authRoutes{
-		authService: authService,
-		validator:   validator,
+		authService:     authService,
+		accountService:  accountService,
+		throttleService: throttleService,
+		validator:       validator,
 	}
 
 	g.POST("/sign-up", r.signUp)
 	g.POST("/sign-in", r.signIn)
+	g.POST("/2fa/verify", r.verifyTwoFactor)
+	g.POST("/refresh", r.refresh)
+	g.POST("/logout", r.logout)
 }
 
 // signUpInput представляет собой модель данных для запроса на регистрацию.
@@ -35,13 +50,14 @@ type signUpInput struct {
 
 // signUp регистрирует нового пользователя
 // @Summary User registration
-// @Description Register a new user with username, password, and email
+// @Description Register a new user with username, password, and email. A verification link is sent to the email; purchases are blocked until it is confirmed
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param input body signUpInput true "User registration input"
 // @Success 201 {object} v1.authRoutes.signUp.response
 // @Failure 400 {object} ErrorResonse "Invalid request body or validation error"
+// @Failure 422 {object} PasswordPolicyResponse "Password does not meet the policy"
 // @Failure 500 {object} ErrorResonse "Internal server error"
 // @Router /auth/sign-up [post]
 func (r *authRoutes) signUp(c *gin.Context) {
@@ -63,6 +79,9 @@ func (r *authRoutes) signUp(c *gin.Context) {
 		Email:    input.Email,
 	})
 	if err != nil {
+		if newPasswordPolicyResponse(c, err) {
+			return
+		}
 		if err == serviceerrs.ErrUserAlreadyExists {
 			newErrorResponse(c, http.StatusBadRequest, err.Error())
 			return
@@ -71,6 +90,10 @@ func (r *authRoutes) signUp(c *gin.Context) {
 		return
 	}
 
+	// Регистрация не откатывается, если письмо не ушло: его можно запросить
+	// повторно через /auth/resend-verification.
+	_ = r.accountService.SendVerificationEmail(c.Request.Context(), id)
+
 	type response struct {
 		ID int `json:"id"`
 	}
@@ -88,13 +111,15 @@ type signInInput struct {
 
 // signIn выполняет аутентификацию пользователя
 // @Summary User login
-// @Description Authenticate a user and return a JWT token
+// @Description Authenticate a user and return a JWT access token and a refresh token. If two-factor authentication is enabled, a challenge token is returned instead and has to be exchanged via /auth/2fa/verify
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param input body signInInput true "User login input"
-// @Success 201 {object} v1.authRoutes.signIn.response
+// @Success 201 {object} tokensResponse
+// @Success 202 {object} twoFactorChallengeResponse "Second factor required"
 // @Failure 400 {object} ErrorResonse "Invalid credentials or bad request"
+// @Failure 429 {object} ErrorResonse "Too many failed attempts, retry after the Retry-After header"
 // @Failure 500 {object} ErrorResonse "Internal server error"
 // @Router /auth/sign-in [post]
 func (r *authRoutes) signIn(c *gin.Context) {
@@ -110,7 +135,23 @@ func (r *authRoutes) signIn(c *gin.Context) {
 		return
 	}
 
-	token, err := r.authService.GenerateToken(c.Request.Context(), types.AuthGenerateTokenInput{
+	throttle := types.LoginThrottleInput{
+		Username: input.Username,
+		IP:       c.ClientIP(),
+	}
+
+	retryAfter, err := r.throttleService.Reserve(c.Request.Context(), throttle)
+	if err != nil {
+		if err == serviceerrs.ErrTooManyLoginAttempts {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			newErrorResponse(c, http.StatusTooManyRequests, "Too many login attempts")
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	tokens, err := r.authService.GenerateToken(c.Request.Context(), types.AuthGenerateTokenInput{
 		Username: input.Username,
 		Password: input.Password,
 	})
@@ -130,11 +171,197 @@ func (r *authRoutes) signIn(c *gin.Context) {
 		return
 	}
 
-	type response struct {
-		Token string `json:"token"`
+	_ = r.throttleService.RecordSuccess(c.Request.Context(), throttle)
+
+	if tokens.ChallengeToken != "" {
+		c.JSON(http.StatusAccepted, twoFactorChallengeResponse{
+			ChallengeToken:    tokens.ChallengeToken,
+			TwoFactorRequired: true,
+		})
+		return
 	}
 
-	c.JSON(http.StatusCreated, response{
-		Token: token,
+	c.JSON(http.StatusCreated, newTokensResponse(tokens))
+}
+
+// twoFactorChallengeResponse - ответ на вход по паролю, если нужен второй фактор.
+type twoFactorChallengeResponse struct {
+	ChallengeToken    string `json:"challenge_token"`
+	TwoFactorRequired bool   `json:"two_factor_required"`
+}
+
+// verifyTwoFactorInput представляет собой модель данных для второго шага входа.
+type verifyTwoFactorInput struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+}
+
+// verifyTwoFactor завершает вход с вторым фактором
+// @Summary Verify second factor
+// @Description Exchange the challenge token from /auth/sign-in and a code from the authenticator app (or a recovery code) for a JWT access token and a refresh token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body verifyTwoFactorInput true "Challenge token and code"
+// @Success 201 {object} tokensResponse
+// @Failure 400 {object} ErrorResonse "Invalid request body or validation error"
+// @Failure 401 {object} ErrorResonse "Invalid or expired challenge token, or invalid code"
+// @Failure 429 {object} ErrorResonse "Too many failed attempts, retry after the Retry-After header"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Router /auth/2fa/verify [post]
+func (r *authRoutes) verifyTwoFactor(c *gin.Context) {
+	var input verifyTwoFactorInput
+
+	if err := c.ShouldBindBodyWithJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := r.validator.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	challenge, err := r.authService.ParseChallengeToken(c.Request.Context(), input.ChallengeToken)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	// Пароль уже подобран, поэтому попытки угадать код ограничиваются
+	// отдельно от входа и не сбрасываются успешным вводом пароля.
+	throttle := types.LoginThrottleInput{
+		Scope:    "2fa",
+		Username: challenge.Username,
+		IP:       c.ClientIP(),
+	}
+
+	retryAfter, err := r.throttleService.Reserve(c.Request.Context(), throttle)
+	if err != nil {
+		if err == serviceerrs.ErrTooManyLoginAttempts {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			newErrorResponse(c, http.StatusTooManyRequests, "Too many login attempts")
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	tokens, err := r.authService.VerifyTwoFactor(c.Request.Context(), types.AuthVerifyTwoFactorInput{
+		ChallengeToken: input.ChallengeToken,
+		Code:           input.Code,
+	})
+	if err != nil {
+		switch err {
+		case serviceerrs.ErrInvalidTwoFactorCode:
+			newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		case serviceerrs.ErrInvalidChallengeToken:
+			newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		default:
+			newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	_ = r.throttleService.RecordSuccess(c.Request.Context(), throttle)
+
+	c.JSON(http.StatusCreated, newTokensResponse(tokens))
+}
+
+// tokensResponse - пара токенов, выдаваемая при входе и обновлении.
+type tokensResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+func newTokensResponse(tokens types.AuthTokens) tokensResponse {
+	return tokensResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+	}
+}
+
+// refreshTokenInput представляет собой модель данных для запросов с refresh-токеном.
+type refreshTokenInput struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// refresh обменивает refresh-токен на новую пару токенов
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new access token and a rotated refresh token. Reusing an already exchanged refresh token revokes the whole session
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body refreshTokenInput true "Refresh token"
+// @Success 201 {object} tokensResponse
+// @Failure 400 {object} ErrorResonse "Invalid request body or validation error"
+// @Failure 401 {object} ErrorResonse "Invalid, expired, reused or revoked refresh token"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Router /auth/refresh [post]
+func (r *authRoutes) refresh(c *gin.Context) {
+	var input refreshTokenInput
+
+	if err := c.ShouldBindBodyWithJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := r.validator.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tokens, err := r.authService.RefreshToken(c.Request.Context(), input.RefreshToken)
+	if err != nil {
+		switch err {
+		case serviceerrs.ErrInvalidRefreshToken, serviceerrs.ErrRefreshTokenReused,
+			serviceerrs.ErrSessionRevoked, serviceerrs.ErrUserNotFound:
+			newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		default:
+			newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, newTokensResponse(tokens))
+}
+
+// logout завершает сессию
+// @Summary Logout
+// @Description Revoke the session of the refresh token. Access tokens issued for the session stop being accepted
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body refreshTokenInput true "Refresh token"
+// @Success 200 {object} map[string]interface{} "Success message"
+// @Failure 400 {object} ErrorResonse "Invalid request body or validation error"
+// @Failure 401 {object} ErrorResonse "Invalid refresh token"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Router /auth/logout [post]
+func (r *authRoutes) logout(c *gin.Context) {
+	var input refreshTokenInput
+
+	if err := c.ShouldBindBodyWithJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := r.validator.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err := r.authService.Logout(c.Request.Context(), input.RefreshToken)
+	if err != nil {
+		if err == serviceerrs.ErrInvalidRefreshToken {
+			newErrorResponse(c, http.StatusUnauthorized, err.Error())
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "succes",
 	})
 }
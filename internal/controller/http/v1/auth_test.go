@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -17,6 +18,7 @@ import (
 	"github.com/cripplemymind9/go-market/internal/service"
 	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
 	"github.com/cripplemymind9/go-market/internal/service/types"
+	"github.com/cripplemymind9/go-market/pkg/passwordpolicy"
 )
 
 func TestAuthRoutes_SignUp(t *testing.T) {
@@ -25,7 +27,7 @@ func TestAuthRoutes_SignUp(t *testing.T) {
 		input types.AuthRegisterUserInput
 	}
 
-	type MockBehaviour func(m *servicemocks.MockAuth, args args)
+	type MockBehaviour func(m *servicemocks.MockAuth, am *servicemocks.MockAccount, args args)
 
 	testCases := []struct {
 		name            string
@@ -46,17 +48,60 @@ func TestAuthRoutes_SignUp(t *testing.T) {
 				},
 			},
 			inputBody: `{"username":"test","password":"Qwerty!1","email":"test@example.com"}`,
-			mockBehaviour: func(m *servicemocks.MockAuth, args args) {
+			mockBehaviour: func(m *servicemocks.MockAuth, am *servicemocks.MockAccount, args args) {
 				m.EXPECT().RegisterUser(args.ctx, args.input).Return(1, nil)
+				am.EXPECT().SendVerificationEmail(args.ctx, 1).Return(nil)
 			},
 			wantStatusCode:  201,
 			wantRequestBody: `{"id":1}` + "\n",
 		},
+		{
+			name: "Verification email not sent",
+			args: args{
+				ctx: context.Background(),
+				input: types.AuthRegisterUserInput{
+					Username: "test",
+					Password: "Qwerty!1",
+					Email:    "test@example.com",
+				},
+			},
+			inputBody: `{"username":"test","password":"Qwerty!1","email":"test@example.com"}`,
+			mockBehaviour: func(m *servicemocks.MockAuth, am *servicemocks.MockAccount, args args) {
+				m.EXPECT().RegisterUser(args.ctx, args.input).Return(1, nil)
+				am.EXPECT().SendVerificationEmail(args.ctx, 1).Return(serviceerrs.ErrCannotSendEmail)
+			},
+			wantStatusCode:  201,
+			wantRequestBody: `{"id":1}` + "\n",
+		},
+		{
+			name: "Weak password",
+			args: args{
+				ctx: context.Background(),
+				input: types.AuthRegisterUserInput{
+					Username: "test",
+					Password: "qwerty",
+					Email:    "test@example.com",
+				},
+			},
+			inputBody: `{"username":"test","password":"qwerty","email":"test@example.com"}`,
+			mockBehaviour: func(m *servicemocks.MockAuth, am *servicemocks.MockAccount, args args) {
+				m.EXPECT().RegisterUser(args.ctx, args.input).Return(0, &serviceerrs.PasswordPolicyError{
+					Violations: []passwordpolicy.Violation{
+						{Rule: passwordpolicy.RuleMinLength, Message: "password must be at least 8 characters long"},
+						{Rule: passwordpolicy.RuleCommon, Message: "password is too common"},
+					},
+				})
+			},
+			wantStatusCode: 422,
+			wantRequestBody: `{"error":"password does not meet the policy","violations":[` +
+				`{"rule":"min_length","message":"password must be at least 8 characters long"},` +
+				`{"rule":"common","message":"password is too common"}]}` + "\n",
+		},
 		{
 			name:            "Invalid password: not provided",
 			args:            args{},
 			inputBody:       `{"username":"test","email":"test@example.com"}`,
-			mockBehaviour:   func(m *servicemocks.MockAuth, args args) {},
+			mockBehaviour:   func(m *servicemocks.MockAuth, am *servicemocks.MockAccount, args args) {},
 			wantStatusCode:  400,
 			wantRequestBody: `{"error":"Key: 'signUpInput.Password' Error:Field validation for 'Password' failed on the 'required' tag"}` + "\n",
 		},
@@ -64,7 +109,7 @@ func TestAuthRoutes_SignUp(t *testing.T) {
 			name:            "Invalid username: not provided",
 			args:            args{},
 			inputBody:       `{"password":"Qwerty!1","email":"test@example.com"}`,
-			mockBehaviour:   func(m *servicemocks.MockAuth, args args) {},
+			mockBehaviour:   func(m *servicemocks.MockAuth, am *servicemocks.MockAccount, args args) {},
 			wantStatusCode:  400,
 			wantRequestBody: `{"error":"Key: 'signUpInput.Username' Error:Field validation for 'Username' failed on the 'required' tag"}` + "\n",
 		},
@@ -72,7 +117,7 @@ func TestAuthRoutes_SignUp(t *testing.T) {
 			name:            "Invalid email: not provided",
 			args:            args{},
 			inputBody:       `{"username":"test","password":"Qwerty!1"}`,
-			mockBehaviour:   func(m *servicemocks.MockAuth, args args) {},
+			mockBehaviour:   func(m *servicemocks.MockAuth, am *servicemocks.MockAccount, args args) {},
 			wantStatusCode:  400,
 			wantRequestBody: `{"error":"Key: 'signUpInput.Email' Error:Field validation for 'Email' failed on the 'required' tag"}` + "\n",
 		},
@@ -80,7 +125,7 @@ func TestAuthRoutes_SignUp(t *testing.T) {
 			name:            "Invalid request body",
 			args:            args{},
 			inputBody:       `{"username" test","password":"Qwerty!1"`,
-			mockBehaviour:   func(m *servicemocks.MockAuth, args args) {},
+			mockBehaviour:   func(m *servicemocks.MockAuth, am *servicemocks.MockAccount, args args) {},
 			wantStatusCode:  400,
 			wantRequestBody: `{"error":"invalid request body"}` + "\n",
 		},
@@ -95,7 +140,7 @@ func TestAuthRoutes_SignUp(t *testing.T) {
 				},
 			},
 			inputBody: `{"username":"test","password":"Qwerty!1","email":"test@example.com"}`,
-			mockBehaviour: func(m *servicemocks.MockAuth, args args) {
+			mockBehaviour: func(m *servicemocks.MockAuth, am *servicemocks.MockAccount, args args) {
 				m.EXPECT().RegisterUser(args.ctx, args.input).Return(0, serviceerrs.ErrUserAlreadyExists)
 			},
 			wantStatusCode:  400,
@@ -111,14 +156,16 @@ func TestAuthRoutes_SignUp(t *testing.T) {
 
 			// Init service mock
 			auth := servicemocks.NewMockAuth(ctrl)
-			tc.mockBehaviour(auth, tc.args)
-			services := &service.Services{Auth: auth}
+			account := servicemocks.NewMockAccount(ctrl)
+			tc.mockBehaviour(auth, account, tc.args)
+			services := &service.Services{Auth: auth, Account: account}
 
 			// Create router
 			router := gin.Default()
 			authRoutes := &authRoutes{
-				authService: services.Auth,
-				validator:   validator.New(),
+				authService:    services.Auth,
+				accountService: services.Account,
+				validator:      validator.New(),
 			}
 			router.POST("/auth/sign-up", authRoutes.signUp)
 
@@ -143,7 +190,7 @@ func TestAuthRoutes_SignIn(t *testing.T) {
 		input types.AuthGenerateTokenInput
 	}
 
-	type mockBehaviour func(m *servicemocks.MockAuth, args args)
+	type mockBehaviour func(m *servicemocks.MockAuth, th *servicemocks.MockLoginThrottle, args args)
 
 	testCases := []struct {
 		name            string
@@ -152,6 +199,7 @@ func TestAuthRoutes_SignIn(t *testing.T) {
 		mockBehaviour   mockBehaviour
 		wantStatusCode  int
 		wantRequestBody string
+		wantRetryAfter  string
 	}{
 		{
 			name: "OK",
@@ -163,17 +211,44 @@ func TestAuthRoutes_SignIn(t *testing.T) {
 				},
 			},
 			inputBody: `{"username":"test","password":"Qwerty1!"}`,
-			mockBehaviour: func(m *servicemocks.MockAuth, args args) {
-				m.EXPECT().GenerateToken(args.ctx, args.input).Return("token", nil)
+			mockBehaviour: func(m *servicemocks.MockAuth, th *servicemocks.MockLoginThrottle, args args) {
+				throttle := types.LoginThrottleInput{Username: "test", IP: "192.0.2.1"}
+				th.EXPECT().Reserve(args.ctx, throttle).Return(time.Duration(0), nil)
+				m.EXPECT().GenerateToken(args.ctx, args.input).Return(types.AuthTokens{
+					AccessToken:  "token",
+					RefreshToken: "sid.secret",
+				}, nil)
+				th.EXPECT().RecordSuccess(args.ctx, throttle).Return(nil)
 			},
 			wantStatusCode:  201,
-			wantRequestBody: `{"token":"token"}` + "\n",
+			wantRequestBody: `{"token":"token","refresh_token":"sid.secret"}` + "\n",
+		},
+		{
+			name: "Second factor required",
+			args: args{
+				ctx: context.Background(),
+				input: types.AuthGenerateTokenInput{
+					Username: "test",
+					Password: "Qwerty1!",
+				},
+			},
+			inputBody: `{"username":"test","password":"Qwerty1!"}`,
+			mockBehaviour: func(m *servicemocks.MockAuth, th *servicemocks.MockLoginThrottle, args args) {
+				throttle := types.LoginThrottleInput{Username: "test", IP: "192.0.2.1"}
+				th.EXPECT().Reserve(args.ctx, throttle).Return(time.Duration(0), nil)
+				m.EXPECT().GenerateToken(args.ctx, args.input).Return(types.AuthTokens{
+					ChallengeToken: "challenge",
+				}, nil)
+				th.EXPECT().RecordSuccess(args.ctx, throttle).Return(nil)
+			},
+			wantStatusCode:  202,
+			wantRequestBody: `{"challenge_token":"challenge","two_factor_required":true}` + "\n",
 		},
 		{
 			name:            "Invalid username: not provided",
 			args:            args{},
 			inputBody:       `{"password":"Qwerty1!"}`,
-			mockBehaviour:   func(m *servicemocks.MockAuth, args args) {},
+			mockBehaviour:   func(m *servicemocks.MockAuth, th *servicemocks.MockLoginThrottle, args args) {},
 			wantStatusCode:  400,
 			wantRequestBody: `{"error":"Key: 'signInInput.Username' Error:Field validation for 'Username' failed on the 'required' tag"}` + "\n",
 		},
@@ -181,7 +256,7 @@ func TestAuthRoutes_SignIn(t *testing.T) {
 			name:            "Invalid password: not provided",
 			args:            args{},
 			inputBody:       `{"username":"test"}`,
-			mockBehaviour:   func(m *servicemocks.MockAuth, args args) {},
+			mockBehaviour:   func(m *servicemocks.MockAuth, th *servicemocks.MockLoginThrottle, args args) {},
 			wantStatusCode:  400,
 			wantRequestBody: `{"error":"Key: 'signInInput.Password' Error:Field validation for 'Password' failed on the 'required' tag"}` + "\n",
 		},
@@ -195,17 +270,33 @@ func TestAuthRoutes_SignIn(t *testing.T) {
 				},
 			},
 			inputBody: `{"username":"test","password":"Qwerty1!"}`,
-			mockBehaviour: func(m *servicemocks.MockAuth, args args) {
-				m.EXPECT().GenerateToken(args.ctx, args.input).Return("", serviceerrs.ErrUserNotFound)
+			mockBehaviour: func(m *servicemocks.MockAuth, th *servicemocks.MockLoginThrottle, args args) {
+				throttle := types.LoginThrottleInput{Username: "test", IP: "192.0.2.1"}
+				th.EXPECT().Reserve(args.ctx, throttle).Return(time.Duration(0), nil)
+				m.EXPECT().GenerateToken(args.ctx, args.input).Return(types.AuthTokens{}, serviceerrs.ErrUserNotFound)
 			},
 			wantStatusCode:  400,
 			wantRequestBody: `{"error":"Invalid credentials"}` + "\n",
 		},
+		{
+			name: "Too many attempts",
+			args: args{
+				ctx: context.Background(),
+			},
+			inputBody: `{"username":"test","password":"Qwerty1!"}`,
+			mockBehaviour: func(m *servicemocks.MockAuth, th *servicemocks.MockLoginThrottle, args args) {
+				th.EXPECT().Reserve(args.ctx, types.LoginThrottleInput{Username: "test", IP: "192.0.2.1"}).
+					Return(1500*time.Millisecond, serviceerrs.ErrTooManyLoginAttempts)
+			},
+			wantStatusCode:  429,
+			wantRequestBody: `{"error":"Too many login attempts"}` + "\n",
+			wantRetryAfter:  "2",
+		},
 		{
 			name:            "Invalid request body",
 			args:            args{},
 			inputBody:       `({Qwerty1!)`,
-			mockBehaviour:   func(m *servicemocks.MockAuth, args args) {},
+			mockBehaviour:   func(m *servicemocks.MockAuth, th *servicemocks.MockLoginThrottle, args args) {},
 			wantStatusCode:  400,
 			wantRequestBody: `{"error":"invalid request body"}` + "\n",
 		},
@@ -219,8 +310,9 @@ func TestAuthRoutes_SignIn(t *testing.T) {
 				},
 			},
 			inputBody: `{"username":"test","password":"Qwerty1!"}`,
-			mockBehaviour: func(m *servicemocks.MockAuth, args args) {
-				m.EXPECT().GenerateToken(args.ctx, args.input).Return("", errors.New("some error"))
+			mockBehaviour: func(m *servicemocks.MockAuth, th *servicemocks.MockLoginThrottle, args args) {
+				th.EXPECT().Reserve(args.ctx, gomock.Any()).Return(time.Duration(0), nil)
+				m.EXPECT().GenerateToken(args.ctx, args.input).Return(types.AuthTokens{}, errors.New("some error"))
 			},
 			wantStatusCode:  500,
 			wantRequestBody: `{"error":"Internal server error"}` + "\n",
@@ -235,14 +327,16 @@ func TestAuthRoutes_SignIn(t *testing.T) {
 
 			// Init service mock
 			auth := servicemocks.NewMockAuth(ctrl)
-			tc.mockBehaviour(auth, tc.args)
-			services := &service.Services{Auth: auth}
+			throttle := servicemocks.NewMockLoginThrottle(ctrl)
+			tc.mockBehaviour(auth, throttle, tc.args)
+			services := &service.Services{Auth: auth, LoginThrottle: throttle}
 
 			// Create router
 			router := gin.Default()
 			authRoutes := &authRoutes{
-				authService: services.Auth,
-				validator:   validator.New(),
+				authService:     services.Auth,
+				throttleService: services.LoginThrottle,
+				validator:       validator.New(),
 			}
 			router.POST("/auth/sign-in", authRoutes.signIn)
 
@@ -254,6 +348,183 @@ func TestAuthRoutes_SignIn(t *testing.T) {
 			// Execute request
 			router.ServeHTTP(w, req)
 
+			// Check response
+			assert.Equal(t, tc.wantStatusCode, w.Code)
+			assert.JSONEq(t, tc.wantRequestBody, w.Body.String())
+			assert.Equal(t, tc.wantRetryAfter, w.Header().Get("Retry-After"))
+		})
+	}
+}
+
+func TestAuthRoutes_Refresh(t *testing.T) {
+	type mockBehaviour func(m *servicemocks.MockAuth)
+
+	testCases := []struct {
+		name            string
+		inputBody       string
+		mockBehaviour   mockBehaviour
+		wantStatusCode  int
+		wantRequestBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"refresh_token":"sid.old"}`,
+			mockBehaviour: func(m *servicemocks.MockAuth) {
+				m.EXPECT().RefreshToken(gomock.Any(), "sid.old").Return(types.AuthTokens{
+					AccessToken:  "token",
+					RefreshToken: "sid.new",
+				}, nil)
+			},
+			wantStatusCode:  201,
+			wantRequestBody: `{"token":"token","refresh_token":"sid.new"}`,
+		},
+		{
+			name:      "Reused refresh token",
+			inputBody: `{"refresh_token":"sid.old"}`,
+			mockBehaviour: func(m *servicemocks.MockAuth) {
+				m.EXPECT().RefreshToken(gomock.Any(), "sid.old").Return(types.AuthTokens{}, serviceerrs.ErrRefreshTokenReused)
+			},
+			wantStatusCode:  401,
+			wantRequestBody: `{"error":"refresh token reuse detected"}`,
+		},
+		{
+			name:            "Refresh token not provided",
+			inputBody:       `{}`,
+			mockBehaviour:   func(m *servicemocks.MockAuth) {},
+			wantStatusCode:  400,
+			wantRequestBody: `{"error":"Key: 'refreshTokenInput.RefreshToken' Error:Field validation for 'RefreshToken' failed on the 'required' tag"}`,
+		},
+		{
+			name:      "Internal server error",
+			inputBody: `{"refresh_token":"sid.old"}`,
+			mockBehaviour: func(m *servicemocks.MockAuth) {
+				m.EXPECT().RefreshToken(gomock.Any(), "sid.old").Return(types.AuthTokens{}, errors.New("some error"))
+			},
+			wantStatusCode:  500,
+			wantRequestBody: `{"error":"internal server error"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Init service mock
+			auth := servicemocks.NewMockAuth(ctrl)
+			tc.mockBehaviour(auth)
+
+			// Create router
+			router := gin.Default()
+			authRoutes := &authRoutes{
+				authService: auth,
+				validator:   validator.New(),
+			}
+			router.POST("/auth/refresh", authRoutes.refresh)
+
+			// Create request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewBufferString(tc.inputBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			// Execute request
+			router.ServeHTTP(w, req)
+
+			// Check response
+			assert.Equal(t, tc.wantStatusCode, w.Code)
+			assert.JSONEq(t, tc.wantRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestAuthRoutes_VerifyTwoFactor(t *testing.T) {
+	type mockBehaviour func(m *servicemocks.MockAuth, th *servicemocks.MockLoginThrottle)
+
+	ctx := context.Background()
+	throttle := types.LoginThrottleInput{Scope: "2fa", Username: "test", IP: "192.0.2.1"}
+	input := types.AuthVerifyTwoFactorInput{ChallengeToken: "challenge", Code: "123456"}
+
+	testCases := []struct {
+		name            string
+		inputBody       string
+		mockBehaviour   mockBehaviour
+		wantStatusCode  int
+		wantRequestBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"challenge_token":"challenge","code":"123456"}`,
+			mockBehaviour: func(m *servicemocks.MockAuth, th *servicemocks.MockLoginThrottle) {
+				m.EXPECT().ParseChallengeToken(ctx, "challenge").Return(types.AuthChallenge{UserID: 1, Username: "test"}, nil)
+				th.EXPECT().Reserve(ctx, throttle).Return(time.Duration(0), nil)
+				m.EXPECT().VerifyTwoFactor(ctx, input).Return(types.AuthTokens{AccessToken: "token", RefreshToken: "sid.secret"}, nil)
+				th.EXPECT().RecordSuccess(ctx, throttle).Return(nil)
+			},
+			wantStatusCode:  201,
+			wantRequestBody: `{"token":"token","refresh_token":"sid.secret"}`,
+		},
+		{
+			name:      "Invalid code",
+			inputBody: `{"challenge_token":"challenge","code":"123456"}`,
+			mockBehaviour: func(m *servicemocks.MockAuth, th *servicemocks.MockLoginThrottle) {
+				m.EXPECT().ParseChallengeToken(ctx, "challenge").Return(types.AuthChallenge{UserID: 1, Username: "test"}, nil)
+				th.EXPECT().Reserve(ctx, throttle).Return(time.Duration(0), nil)
+				m.EXPECT().VerifyTwoFactor(ctx, input).Return(types.AuthTokens{}, serviceerrs.ErrInvalidTwoFactorCode)
+			},
+			wantStatusCode:  401,
+			wantRequestBody: `{"error":"invalid two-factor code"}`,
+		},
+		{
+			name:      "Invalid challenge token",
+			inputBody: `{"challenge_token":"challenge","code":"123456"}`,
+			mockBehaviour: func(m *servicemocks.MockAuth, th *servicemocks.MockLoginThrottle) {
+				m.EXPECT().ParseChallengeToken(ctx, "challenge").Return(types.AuthChallenge{}, serviceerrs.ErrInvalidChallengeToken)
+			},
+			wantStatusCode:  401,
+			wantRequestBody: `{"error":"invalid or expired challenge token"}`,
+		},
+		{
+			name:      "Too many attempts",
+			inputBody: `{"challenge_token":"challenge","code":"123456"}`,
+			mockBehaviour: func(m *servicemocks.MockAuth, th *servicemocks.MockLoginThrottle) {
+				m.EXPECT().ParseChallengeToken(ctx, "challenge").Return(types.AuthChallenge{UserID: 1, Username: "test"}, nil)
+				th.EXPECT().Reserve(ctx, throttle).Return(time.Minute, serviceerrs.ErrTooManyLoginAttempts)
+			},
+			wantStatusCode:  429,
+			wantRequestBody: `{"error":"Too many login attempts"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Init service mock
+			auth := servicemocks.NewMockAuth(ctrl)
+			throttleService := servicemocks.NewMockLoginThrottle(ctrl)
+			tc.mockBehaviour(auth, throttleService)
+
+			// Create router
+			router := gin.Default()
+			authRoutes := &authRoutes{
+				authService:     auth,
+				throttleService: throttleService,
+				validator:       validator.New(),
+			}
+			router.POST("/auth/2fa/verify", authRoutes.verifyTwoFactor)
+
+			// Create request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/auth/2fa/verify", bytes.NewBufferString(tc.inputBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.RemoteAddr = "192.0.2.1:1234"
+
+			// Execute request
+			router.ServeHTTP(w, req)
+
 			// Check response
 			assert.Equal(t, tc.wantStatusCode, w.Code)
 			assert.JSONEq(t, tc.wantRequestBody, w.Body.String())
@@ -0,0 +1,356 @@
+package v1
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/service"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+type cartRoutes struct {
+	cartService service.Cart
+	validator   *validator.Validate
+}
+
+func newCartRoutes(g *gin.RouterGroup, cartService service.Cart, validator *validator.Validate, idempotent gin.HandlerFunc) {
+	r := &cartRoutes{
+		cartService: cartService,
+		validator:   validator,
+	}
+
+	g.GET("", r.getCart)
+	g.DELETE("", r.clear)
+	g.POST("/items", r.addItem)
+	g.PUT("/items/:id", r.updateItem)
+	g.DELETE("/items/:id", r.removeItem)
+	g.POST("/checkout", idempotent, r.checkout)
+}
+
+type cartItemResponse struct {
+	ProductID   int                 `json:"product_id"`
+	ProductName string              `json:"product_name"`
+	Price       float64             `json:"price"`
+	Quantity    int                 `json:"quantity"`
+	Available   int                 `json:"available"`
+	Subtotal    float64             `json:"subtotal"`
+	Warning     entity.StockWarning `json:"warning,omitempty"`
+}
+
+type cartResponse struct {
+	Items []cartItemResponse `json:"items"`
+	Total float64            `json:"total"`
+}
+
+func newCartResponse(cart entity.Cart) cartResponse {
+	response := cartResponse{
+		Items: make([]cartItemResponse, 0, len(cart.Items)),
+		Total: cart.Total,
+	}
+	for _, item := range cart.Items {
+		response.Items = append(response.Items, cartItemResponse{
+			ProductID:   item.ProductID,
+			ProductName: item.ProductName,
+			Price:       item.Price,
+			Quantity:    item.Quantity,
+			Available:   item.Available,
+			Subtotal:    item.Subtotal,
+			Warning:     item.Warning,
+		})
+	}
+	return response
+}
+
+// getCart возвращает корзину текущего пользователя
+// @Summary Get cart
+// @Description Get the cart of the authenticated user with current prices. Items with less stock than requested carry a warning: out_of_stock or not_enough_stock
+// @Tags cart
+// @Produce json
+// @Success 200 {object} cartResponse
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Router /api/v1/cart [get]
+func (r *cartRoutes) getCart(c *gin.Context) {
+	userId, ok := getUserId(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	cart, err := r.cartService.GetCart(c.Request.Context(), userId)
+	if err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newCartResponse(cart))
+}
+
+// addCartItemInput представляет собой модель данных для добавления товара в корзину.
+type addCartItemInput struct {
+	ProductID int `json:"product_id" validate:"required"`
+	Quantity  int `json:"quantity" validate:"required,gt=0"`
+}
+
+// addItem добавляет товар в корзину
+// @Summary Add item to cart
+// @Description Put a product into the cart of the authenticated user. If the product is already in the cart, the quantity is added to it
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param input body addCartItemInput true "Product and quantity"
+// @Success 200 {object} map[string]interface{} "Success message"
+// @Failure 400 {object} ErrorResonse "Invalid request body or validation error"
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 404 {object} ErrorResonse "Product not found"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Router /api/v1/cart/items [post]
+func (r *cartRoutes) addItem(c *gin.Context) {
+	userId, ok := getUserId(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input addCartItemInput
+
+	if err := c.ShouldBindBodyWithJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := r.validator.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err := r.cartService.AddItem(c.Request.Context(), types.CartAddItemInput{
+		UserID:    userId,
+		ProductID: input.ProductID,
+		Quantity:  input.Quantity,
+	})
+	if err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "succes",
+	})
+}
+
+// updateCartItemInput представляет собой модель данных для изменения количества
+// товара в корзине. Нулевое количество убирает товар из корзины.
+type updateCartItemInput struct {
+	Quantity *int `json:"quantity" validate:"required,gte=0"`
+}
+
+// updateItem задает количество товара в корзине
+// @Summary Update cart item
+// @Description Set the quantity of a product in the cart of the authenticated user. Quantity 0 removes the product from the cart
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param input body updateCartItemInput true "New quantity"
+// @Success 200 {object} map[string]interface{} "Success message"
+// @Failure 400 {object} ErrorResonse "Invalid ID, request body or validation error"
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 404 {object} ErrorResonse "Product is not in the cart"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Router /api/v1/cart/items/{id} [put]
+func (r *cartRoutes) updateItem(c *gin.Context) {
+	userId, ok := getUserId(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	productId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var input updateCartItemInput
+
+	if err := c.ShouldBindBodyWithJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := r.validator.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = r.cartService.UpdateItem(c.Request.Context(), types.CartUpdateItemInput{
+		UserID:    userId,
+		ProductID: productId,
+		Quantity:  *input.Quantity,
+	})
+	if err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "succes",
+	})
+}
+
+// removeItem убирает товар из корзины
+// @Summary Remove cart item
+// @Description Remove a product from the cart of the authenticated user
+// @Tags cart
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} map[string]interface{} "Success message"
+// @Failure 400 {object} ErrorResonse "Invalid ID"
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 404 {object} ErrorResonse "Product is not in the cart"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Router /api/v1/cart/items/{id} [delete]
+func (r *cartRoutes) removeItem(c *gin.Context) {
+	userId, ok := getUserId(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	productId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	if err = r.cartService.RemoveItem(c.Request.Context(), userId, productId); err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "succes",
+	})
+}
+
+// clear очищает корзину
+// @Summary Clear cart
+// @Description Remove all products from the cart of the authenticated user
+// @Tags cart
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Success message"
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Router /api/v1/cart [delete]
+func (r *cartRoutes) clear(c *gin.Context) {
+	userId, ok := getUserId(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if err := r.cartService.Clear(c.Request.Context(), userId); err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "succes",
+	})
+}
+
+// checkoutInput - необязательное тело оформления заказа из корзины или брони.
+// По ShipTo выбираются ближайшие склады.
+type checkoutInput struct {
+	ShipTo *geoPointInput `json:"ship_to"`
+}
+
+type checkoutResponse struct {
+	OrderID int `json:"order_id"`
+}
+
+// checkout оформляет корзину
+// @Summary Checkout cart
+// @Description Buy everything in the cart of the authenticated user as one order at current prices. If any item cannot be bought, nothing is bought and the cart is left as is. On success the cart is emptied. The order is shipped from warehouses chosen by the configured allocation strategy; with ship_to the nearest strategy prefers warehouses closest to the buyer. A request retried with the same Idempotency-Key gets the original response instead of buying again
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Unique key of the checkout attempt, e.g. a UUID"
+// @Param input body checkoutInput false "Delivery destination"
+// @Success 201 {object} checkoutResponse
+// @Failure 400 {object} ErrorResonse "Invalid request body, validation error or cart is empty"
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 402 {object} ErrorResonse "Not enough balance"
+// @Failure 403 {object} ErrorResonse "Buyer email is not verified"
+// @Failure 404 {object} ErrorResonse "Product or buyer not found"
+// @Failure 409 {object} ErrorResonse "Not enough stock or request with this idempotency key is still in progress"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Router /api/v1/cart/checkout [post]
+func (r *cartRoutes) checkout(c *gin.Context) {
+	userId, ok := getUserId(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input checkoutInput
+	if err := c.ShouldBindBodyWithJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := r.validator.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := r.cartService.Checkout(c.Request.Context(), types.CartCheckoutInput{
+		UserID: userId,
+		ShipTo: input.ShipTo.toEntity(),
+	})
+	if err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, checkoutResponse{
+		OrderID: id,
+	})
+}
+
+func (r *cartRoutes) handleError(c *gin.Context, err error) {
+	switch err {
+	case serviceerrs.ErrInvalidQuantity, serviceerrs.ErrCartEmpty:
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+	case serviceerrs.ErrProductNotFound, serviceerrs.ErrUserNotFound, serviceerrs.ErrCartItemNotFound:
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+	case serviceerrs.ErrEmailNotVerified:
+		newErrorResponse(c, http.StatusForbidden, err.Error())
+	case serviceerrs.ErrNotEnoughStock:
+		newErrorResponse(c, http.StatusConflict, err.Error())
+	case serviceerrs.ErrNotEnoughBalance:
+		newErrorResponse(c, http.StatusPaymentRequired, err.Error())
+	default:
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+	}
+}
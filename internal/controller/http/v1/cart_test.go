@@ -0,0 +1,150 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/mocks/servicemocks"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+func TestCartRoutes_GetCart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cart := servicemocks.NewMockCart(ctrl)
+	cart.EXPECT().GetCart(context.Background(), 1).Return(entity.Cart{
+		UserID: 1,
+		Items: []entity.CartItem{
+			{ProductID: 2, ProductName: "Tea", Price: 2.5, Quantity: 2, Available: 1, Subtotal: 5, Warning: entity.StockWarningNotEnoughStock},
+		},
+		Total: 5,
+	}, nil)
+
+	router := gin.Default()
+	router.GET("/api/v1/cart", func(c *gin.Context) {
+		c.Set(userIdCtx, 1)
+	}, (&cartRoutes{cartService: cart, validator: validator.New()}).getCart)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/cart", nil))
+
+	assert.Equal(t, 200, w.Code)
+	assert.JSONEq(t, `{"items":[{"product_id":2,"product_name":"Tea","price":2.5,"quantity":2,"available":1,"subtotal":5,"warning":"not_enough_stock"}],"total":5}`, w.Body.String())
+}
+
+func TestCartRoutes_Checkout(t *testing.T) {
+	type MockBehaviour func(m *servicemocks.MockCart)
+
+	testCases := []struct {
+		name            string
+		inputBody       string
+		mockBehaviour   MockBehaviour
+		wantStatusCode  int
+		wantRequestBody string
+	}{
+		{
+			name: "OK",
+			mockBehaviour: func(m *servicemocks.MockCart) {
+				m.EXPECT().Checkout(context.Background(), types.CartCheckoutInput{UserID: 1}).Return(10, nil)
+			},
+			wantStatusCode:  201,
+			wantRequestBody: `{"order_id":10}`,
+		},
+		{
+			name:      "OK with destination",
+			inputBody: `{"ship_to":{"latitude":55.75,"longitude":37.62}}`,
+			mockBehaviour: func(m *servicemocks.MockCart) {
+				m.EXPECT().Checkout(context.Background(), types.CartCheckoutInput{
+					UserID: 1,
+					ShipTo: &entity.GeoPoint{Latitude: 55.75, Longitude: 37.62},
+				}).Return(10, nil)
+			},
+			wantStatusCode:  201,
+			wantRequestBody: `{"order_id":10}`,
+		},
+		{
+			name:            "Invalid destination",
+			inputBody:       `{"ship_to":{"latitude":95,"longitude":37.62}}`,
+			mockBehaviour:   func(m *servicemocks.MockCart) {},
+			wantStatusCode:  400,
+			wantRequestBody: `{"error":"Key: 'checkoutInput.ShipTo.Latitude' Error:Field validation for 'Latitude' failed on the 'max' tag"}`,
+		},
+		{
+			name: "Empty cart",
+			mockBehaviour: func(m *servicemocks.MockCart) {
+				m.EXPECT().Checkout(context.Background(), types.CartCheckoutInput{UserID: 1}).Return(0, serviceerrs.ErrCartEmpty)
+			},
+			wantStatusCode:  400,
+			wantRequestBody: `{"error":"cart is empty"}`,
+		},
+		{
+			name: "Not enough stock",
+			mockBehaviour: func(m *servicemocks.MockCart) {
+				m.EXPECT().Checkout(context.Background(), types.CartCheckoutInput{UserID: 1}).Return(0, serviceerrs.ErrNotEnoughStock)
+			},
+			wantStatusCode:  409,
+			wantRequestBody: `{"error":"not enough stock"}`,
+		},
+		{
+			name: "Not enough balance",
+			mockBehaviour: func(m *servicemocks.MockCart) {
+				m.EXPECT().Checkout(context.Background(), types.CartCheckoutInput{UserID: 1}).Return(0, serviceerrs.ErrNotEnoughBalance)
+			},
+			wantStatusCode:  402,
+			wantRequestBody: `{"error":"not enough balance"}`,
+		},
+		{
+			name: "Internal server error",
+			mockBehaviour: func(m *servicemocks.MockCart) {
+				m.EXPECT().Checkout(context.Background(), types.CartCheckoutInput{UserID: 1}).Return(0, errors.New("some error"))
+			},
+			wantStatusCode:  500,
+			wantRequestBody: `{"error":"internal server error"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Init service mock
+			cart := servicemocks.NewMockCart(ctrl)
+			tc.mockBehaviour(cart)
+
+			// Create router
+			router := gin.Default()
+			cartRoutes := &cartRoutes{
+				cartService: cart,
+				validator:   validator.New(),
+			}
+			router.POST("/api/v1/cart/checkout", func(c *gin.Context) {
+				c.Set(userIdCtx, 1)
+			}, cartRoutes.checkout)
+
+			// Create request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/cart/checkout", bytes.NewBufferString(tc.inputBody))
+
+			// Execute request
+			router.ServeHTTP(w, req)
+
+			// Check response
+			assert.Equal(t, tc.wantStatusCode, w.Code)
+			assert.JSONEq(t, tc.wantRequestBody, w.Body.String())
+		})
+	}
+}
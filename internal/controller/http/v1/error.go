@@ -2,8 +2,11 @@ package v1
 
 import (
 	"errors"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
 )
 
 var (
@@ -16,3 +19,38 @@ func newErrorResponse(c *gin.Context, errStatus int, message string) {
 
 	c.JSON(errStatus, gin.H{"error": err.Error()})
 }
+
+// PasswordPolicyResponse - ответ на пароль, не прошедший политику: по одному
+// сообщению на каждое нарушенное правило.
+type PasswordPolicyResponse struct {
+	Error      string              `json:"error"`
+	Violations []passwordViolation `json:"violations"`
+}
+
+type passwordViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// newPasswordPolicyResponse отвечает 422, если err - нарушение политики
+// паролей, и сообщает, был ли ответ записан.
+func newPasswordPolicyResponse(c *gin.Context, err error) bool {
+	var policyErr *serviceerrs.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return false
+	}
+
+	violations := make([]passwordViolation, 0, len(policyErr.Violations))
+	for _, v := range policyErr.Violations {
+		violations = append(violations, passwordViolation{
+			Rule:    string(v.Rule),
+			Message: v.Message,
+		})
+	}
+
+	c.JSON(http.StatusUnprocessableEntity, PasswordPolicyResponse{
+		Error:      policyErr.Error(),
+		Violations: violations,
+	})
+	return true
+}
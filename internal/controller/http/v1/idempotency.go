@@ -0,0 +1,126 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/cripplemymind9/go-market/internal/service"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+)
+
+type IdempotencyMiddleware struct {
+	idempotencyService service.Idempotency
+}
+
+// Idempotent делает маршрут безопасным для повторов: запрос с заголовком
+// Idempotency-Key выполняется один раз, а повтор с тем же ключом и телом
+// получает сохраненный ответ. Ключ, повторенный с другим телом, отвергается.
+// Ответы 5xx не сохраняются, чтобы запрос можно было повторить. Запросы без
+// заголовка проходят как обычно.
+func (h *IdempotencyMiddleware) Idempotent() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		userId, ok := getUserId(c)
+		if !ok {
+			newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		// Путь берется вместе с параметрами, а не шаблон маршрута: иначе ключ,
+		// повторенный для другого ресурса, вернул бы чужой ответ.
+		record, err := h.idempotencyService.Begin(c.Request.Context(), types.IdempotencyBeginInput{
+			UserID: userId,
+			Scope:  c.Request.Method + " " + c.Request.URL.Path,
+			Key:    key,
+			Body:   body,
+		})
+		if err != nil {
+			switch err {
+			case serviceerrs.ErrInvalidIdempotencyKey:
+				newErrorResponse(c, http.StatusBadRequest, err.Error())
+			case serviceerrs.ErrIdempotencyKeyReused:
+				newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+			case serviceerrs.ErrIdempotencyKeyInProgress:
+				newErrorResponse(c, http.StatusConflict, err.Error())
+			default:
+				newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+			}
+			c.Abort()
+			return
+		}
+
+		if record.StatusCode != 0 {
+			c.Header(idempotencyReplayedHeader, "true")
+			c.Data(record.StatusCode, gin.MIMEJSON+"; charset=utf-8", record.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		// Клиент, не дождавшийся ответа, обрывает запрос, но ответ все равно
+		// должен сохраниться для его повтора.
+		ctx := context.WithoutCancel(c.Request.Context())
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		defer func() {
+			if p := recover(); p != nil {
+				_ = h.idempotencyService.Release(ctx, record)
+				panic(p)
+			}
+		}()
+
+		c.Next()
+
+		if recorder.Status() >= http.StatusInternalServerError {
+			_ = h.idempotencyService.Release(ctx, record)
+			return
+		}
+
+		// Если ответ сохранить не удалось, ключ остается занятым: повтор
+		// получит 409, но не совершит покупку второй раз.
+		record.StatusCode = recorder.Status()
+		record.ResponseBody = recorder.body.Bytes()
+		_ = h.idempotencyService.Complete(ctx, record)
+	}
+}
+
+// responseRecorder копирует тело ответа, чтобы его можно было сохранить.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
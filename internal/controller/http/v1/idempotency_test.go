@@ -0,0 +1,187 @@
+package v1
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/mocks/servicemocks"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	type MockBehaviour func(m *servicemocks.MockIdempotency)
+
+	const body = `{"product_id":1,"quantity":2}`
+	begin := types.IdempotencyBeginInput{UserID: 1, Scope: "POST /purchase", Key: "key-1", Body: []byte(body)}
+	record := entity.IdempotencyRecord{UserID: 1, Scope: "POST /purchase", Key: "key-1", Fingerprint: "fp"}
+
+	testCases := []struct {
+		name            string
+		key             string
+		handlerStatus   int
+		mockBehaviour   MockBehaviour
+		wantCalls       int
+		wantStatusCode  int
+		wantRequestBody string
+		wantReplayed    bool
+	}{
+		{
+			name:            "No key",
+			handlerStatus:   201,
+			mockBehaviour:   func(m *servicemocks.MockIdempotency) {},
+			wantCalls:       1,
+			wantStatusCode:  201,
+			wantRequestBody: `{"id":7,"body":"{\"product_id\":1,\"quantity\":2}"}`,
+		},
+		{
+			name:          "First request stores response",
+			key:           "key-1",
+			handlerStatus: 201,
+			mockBehaviour: func(m *servicemocks.MockIdempotency) {
+				m.EXPECT().Begin(gomock.Any(), begin).Return(record, nil)
+				stored := record
+				stored.StatusCode = 201
+				stored.ResponseBody = []byte(`{"body":"{\"product_id\":1,\"quantity\":2}","id":7}`)
+				m.EXPECT().Complete(gomock.Any(), stored).Return(nil)
+			},
+			wantCalls:       1,
+			wantStatusCode:  201,
+			wantRequestBody: `{"id":7,"body":"{\"product_id\":1,\"quantity\":2}"}`,
+		},
+		{
+			name: "Retry is replayed",
+			key:  "key-1",
+			mockBehaviour: func(m *servicemocks.MockIdempotency) {
+				stored := record
+				stored.StatusCode = 201
+				stored.ResponseBody = []byte(`{"id":7}`)
+				m.EXPECT().Begin(gomock.Any(), begin).Return(stored, nil)
+			},
+			wantCalls:       0,
+			wantStatusCode:  201,
+			wantRequestBody: `{"id":7}`,
+			wantReplayed:    true,
+		},
+		{
+			name: "Key reused with another body",
+			key:  "key-1",
+			mockBehaviour: func(m *servicemocks.MockIdempotency) {
+				m.EXPECT().Begin(gomock.Any(), begin).Return(entity.IdempotencyRecord{}, serviceerrs.ErrIdempotencyKeyReused)
+			},
+			wantCalls:       0,
+			wantStatusCode:  422,
+			wantRequestBody: `{"error":"idempotency key was already used with a different request"}`,
+		},
+		{
+			name: "Request in progress",
+			key:  "key-1",
+			mockBehaviour: func(m *servicemocks.MockIdempotency) {
+				m.EXPECT().Begin(gomock.Any(), begin).Return(entity.IdempotencyRecord{}, serviceerrs.ErrIdempotencyKeyInProgress)
+			},
+			wantCalls:       0,
+			wantStatusCode:  409,
+			wantRequestBody: `{"error":"request with this idempotency key is still in progress"}`,
+		},
+		{
+			name:          "Server error releases key",
+			key:           "key-1",
+			handlerStatus: 500,
+			mockBehaviour: func(m *servicemocks.MockIdempotency) {
+				m.EXPECT().Begin(gomock.Any(), begin).Return(record, nil)
+				m.EXPECT().Release(gomock.Any(), record).Return(nil)
+			},
+			wantCalls:       1,
+			wantStatusCode:  500,
+			wantRequestBody: `{"error":"internal server error"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Init service mock
+			idempotency := servicemocks.NewMockIdempotency(ctrl)
+			tc.mockBehaviour(idempotency)
+
+			// Create router: обработчик отвечает телом запроса, чтобы было
+			// видно, что middleware его не съел.
+			calls := 0
+			router := gin.Default()
+			router.POST("/purchase", func(c *gin.Context) {
+				c.Set(userIdCtx, 1)
+			}, (&IdempotencyMiddleware{idempotency}).Idempotent(), func(c *gin.Context) {
+				calls++
+				if tc.handlerStatus >= 500 {
+					newErrorResponse(c, tc.handlerStatus, "internal server error")
+					return
+				}
+				data, _ := c.GetRawData()
+				c.JSON(tc.handlerStatus, map[string]interface{}{"id": 7, "body": string(data)})
+			})
+
+			// Create request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/purchase", bytes.NewBufferString(body))
+			if tc.key != "" {
+				req.Header.Set(idempotencyKeyHeader, tc.key)
+			}
+
+			// Execute request
+			router.ServeHTTP(w, req)
+
+			// Check response
+			assert.Equal(t, tc.wantCalls, calls)
+			assert.Equal(t, tc.wantStatusCode, w.Code)
+			assert.JSONEq(t, tc.wantRequestBody, w.Body.String())
+			assert.Equal(t, tc.wantReplayed, w.Header().Get(idempotencyReplayedHeader) == "true")
+		})
+	}
+}
+
+func TestIdempotencyMiddleware_KeyReusedForAnotherResource(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Один ключ для подтверждения двух разных броней: это два разных запроса,
+	// и ответ первого не должен вернуться на второй.
+	idempotency := servicemocks.NewMockIdempotency(ctrl)
+	for _, scope := range []string{"POST /reservations/1/confirm", "POST /reservations/2/confirm"} {
+		record := entity.IdempotencyRecord{UserID: 1, Scope: scope, Key: "key-1"}
+		idempotency.EXPECT().Begin(gomock.Any(), types.IdempotencyBeginInput{
+			UserID: 1, Scope: scope, Key: "key-1", Body: []byte{},
+		}).Return(record, nil)
+		idempotency.EXPECT().Complete(gomock.Any(), gomock.Any()).Return(nil)
+	}
+
+	var confirmed []string
+	router := gin.Default()
+	router.POST("/reservations/:id/confirm", func(c *gin.Context) {
+		c.Set(userIdCtx, 1)
+	}, (&IdempotencyMiddleware{idempotency}).Idempotent(), func(c *gin.Context) {
+		confirmed = append(confirmed, c.Param("id"))
+		c.JSON(http.StatusCreated, map[string]interface{}{"id": c.Param("id")})
+	})
+
+	for _, id := range []string{"1", "2"} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/reservations/"+id+"/confirm", nil)
+		req.Header.Set(idempotencyKeyHeader, "key-1")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.JSONEq(t, `{"id":"`+id+`"}`, w.Body.String())
+		assert.Empty(t, w.Header().Get(idempotencyReplayedHeader))
+	}
+	assert.Equal(t, []string{"1", "2"}, confirmed)
+}
@@ -0,0 +1,33 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/cripplemymind9/go-market/internal/service"
+)
+
+type jwksRoutes struct {
+	authService service.Auth
+}
+
+func newJWKSRoutes(g *gin.RouterGroup, authService service.Auth) {
+	r := &jwksRoutes{
+		authService: authService,
+	}
+
+	g.GET("/jwks.json", r.jwks)
+}
+
+// jwks отдает открытые ключи для проверки токенов другими сервисами
+// @Summary JSON Web Key Set
+// @Description Public keys for verifying access tokens, selected by the kid header. Retired keys stay published until their tokens expire
+// @Tags auth
+// @Produce json
+// @Success 200 {object} jwtkeys.JWKSet
+// @Router /.well-known/jwks.json [get]
+func (r *jwksRoutes) jwks(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, r.authService.JWKS())
+}
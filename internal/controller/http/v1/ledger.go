@@ -0,0 +1,41 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/cripplemymind9/go-market/internal/service"
+)
+
+type ledgerRoutes struct {
+	ledgerService service.Ledger
+}
+
+func newLedgerRoutes(g *gin.RouterGroup, ledgerService service.Ledger) {
+	r := &ledgerRoutes{
+		ledgerService: ledgerService,
+	}
+
+	g.GET("/reconcile", r.reconcile)
+}
+
+// reconcile пересчитывает остатки по проводкам и сообщает о расхождениях
+// @Summary Reconcile ledger
+// @Description Recompute account balances from ledger entries and report drift against stored wallet balances
+// @Tags ledger
+// @Produce json
+// @Success 200 {object} ledger.Report
+// @Failure 403 {object} ErrorResonse "Admin role required"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /api/v1/ledger/reconcile [get]
+func (r *ledgerRoutes) reconcile(c *gin.Context) {
+	report, err := r.ledgerService.Reconcile(c.Request.Context())
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
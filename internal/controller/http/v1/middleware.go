@@ -4,35 +4,147 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/cripplemymind9/go-market/internal/entity"
 	"github.com/cripplemymind9/go-market/internal/service"
+	"github.com/cripplemymind9/go-market/internal/service/types"
 	"github.com/gin-gonic/gin"
 )
 
 const (
-	userIdCtx = "userId"
+	userIdCtx       = "userId"
+	userIdentityCtx = "userIdentity"
+
+	apiKeyHeader = "X-API-Key"
 )
 
 type AuthMiddleware struct {
-	authService service.Auth
+	authService   service.Auth
+	apiKeyService service.APIKey
 }
 
+// UserIdentity принимает токен доступа в заголовке Authorization или
+// API-ключ в заголовке X-API-Key и кладет в контекст личность пользователя.
 func (h *AuthMiddleware) UserIdentity() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		token, ok := bearerToken(c.Request)
-		if !ok {
+		var (
+			identity types.AuthIdentity
+			err      error
+		)
+
+		apiKey := c.GetHeader(apiKeyHeader)
+		token, hasToken := bearerToken(c.Request)
+
+		switch {
+		case apiKey != "" && c.GetHeader("Authorization") != "":
+			newErrorResponse(c, http.StatusUnauthorized, "use either Authorization or X-API-Key header")
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		case apiKey != "":
+			identity, err = h.apiKeyService.Authenticate(c.Request.Context(), apiKey)
+			if err != nil {
+				newErrorResponse(c, http.StatusUnauthorized, "invalid API key")
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+		case hasToken:
+			identity, err = h.authService.ParseToken(c.Request.Context(), token)
+			if err != nil {
+				newErrorResponse(c, http.StatusUnauthorized, "cannot parse token")
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+		default:
 			newErrorResponse(c, http.StatusUnauthorized, "Invalid authorization header")
 			c.AbortWithStatus(http.StatusUnauthorized)
 			return
 		}
 
-		userId, err := h.authService.ParseToken(token)
-		if err != nil {
-			newErrorResponse(c, http.StatusUnauthorized, "cannot parse token")
-			c.AbortWithStatus(http.StatusUnauthorized)
+		c.Set(userIdCtx, identity.UserID)
+		c.Set(userIdentityCtx, identity)
+		c.Next()
+	}
+}
+
+// RequireRole пропускает запрос дальше, только если у текущего пользователя
+// есть хотя бы одна из перечисленных ролей.
+func RequireRole(roles ...entity.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, ok := getIdentity(c)
+		if !ok {
+			newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+			c.Abort()
+			return
+		}
+
+		for _, role := range roles {
+			if identity.HasRole(role) {
+				c.Next()
+				return
+			}
+		}
+
+		newErrorResponse(c, http.StatusForbidden, "forbidden")
+		c.Abort()
+	}
+}
+
+// RequireScope ограничивает запросы по API-ключу: для чтения (GET, HEAD)
+// нужно право read, для остальных методов - право write. Запросы с токеном
+// доступа пропускаются без проверки.
+func RequireScope(read, write entity.APIKeyScope) gin.HandlerFunc {
+	return requireScope(func(method string) entity.APIKeyScope {
+		if method == http.MethodGet || method == http.MethodHead {
+			return read
+		}
+		return write
+	})
+}
+
+// RequireReadScope закрывает группу только для чтения: любой запрос по
+// API-ключу требует права scope, отдельного права на запись у группы нет.
+func RequireReadScope(scope entity.APIKeyScope) gin.HandlerFunc {
+	return requireScope(func(string) entity.APIKeyScope {
+		return scope
+	})
+}
+
+func requireScope(scopeFor func(method string) entity.APIKeyScope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, ok := getIdentity(c)
+		if !ok {
+			newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+			c.Abort()
+			return
+		}
+
+		scope := scopeFor(c.Request.Method)
+		if !identity.HasScope(scope) {
+			newErrorResponse(c, http.StatusForbidden, "API key lacks scope "+string(scope))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireSession закрывает маршрут для API-ключей: управлять аккаунтом,
+// ключами и ролями можно только после входа по паролю.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, ok := getIdentity(c)
+		if !ok {
+			newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+			c.Abort()
+			return
+		}
+
+		if identity.APIKeyID != 0 {
+			newErrorResponse(c, http.StatusForbidden, "not available for API keys")
+			c.Abort()
 			return
 		}
 
-		c.Set(userIdCtx, userId)
 		c.Next()
 	}
 }
@@ -51,3 +163,34 @@ func bearerToken(r *http.Request) (string, bool) {
 
 	return "", false
 }
+
+func getUserId(c *gin.Context) (int, bool) {
+	value, ok := c.Get(userIdCtx)
+	if !ok {
+		return 0, false
+	}
+
+	userId, ok := value.(int)
+	return userId, ok
+}
+
+func getIdentity(c *gin.Context) (types.AuthIdentity, bool) {
+	value, ok := c.Get(userIdentityCtx)
+	if !ok {
+		return types.AuthIdentity{}, false
+	}
+
+	identity, ok := value.(types.AuthIdentity)
+	return identity, ok
+}
+
+// canActFor сообщает, может ли текущий пользователь читать или изменять
+// данные пользователя userId: себя - всегда, других - только администратор.
+func canActFor(c *gin.Context, userId int) bool {
+	identity, ok := getIdentity(c)
+	if !ok {
+		return false
+	}
+
+	return identity.UserID == userId || identity.HasRole(entity.RoleAdmin)
+}
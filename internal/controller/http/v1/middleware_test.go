@@ -0,0 +1,217 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/mocks/servicemocks"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+func TestRequireRole(t *testing.T) {
+	type MockBehaviour func(m *servicemocks.MockAuth)
+
+	testCases := []struct {
+		name           string
+		authHeader     string
+		mockBehaviour  MockBehaviour
+		wantStatusCode int
+	}{
+		{
+			name:       "Seller allowed",
+			authHeader: "Bearer token",
+			mockBehaviour: func(m *servicemocks.MockAuth) {
+				m.EXPECT().ParseToken(gomock.Any(), "token").Return(types.AuthIdentity{
+					UserID: 1,
+					Roles:  []entity.Role{entity.RoleBuyer, entity.RoleSeller},
+				}, nil)
+			},
+			wantStatusCode: 200,
+		},
+		{
+			name:       "Buyer forbidden",
+			authHeader: "Bearer token",
+			mockBehaviour: func(m *servicemocks.MockAuth) {
+				m.EXPECT().ParseToken(gomock.Any(), "token").Return(types.AuthIdentity{
+					UserID: 1,
+					Roles:  []entity.Role{entity.RoleBuyer},
+				}, nil)
+			},
+			wantStatusCode: 403,
+		},
+		{
+			name:       "Invalid token",
+			authHeader: "Bearer token",
+			mockBehaviour: func(m *servicemocks.MockAuth) {
+				m.EXPECT().ParseToken(gomock.Any(), "token").Return(types.AuthIdentity{}, serviceerrs.ErrCannotParseToken)
+			},
+			wantStatusCode: 401,
+		},
+		{
+			name:           "No token",
+			authHeader:     "",
+			mockBehaviour:  func(m *servicemocks.MockAuth) {},
+			wantStatusCode: 401,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Init service mock
+			auth := servicemocks.NewMockAuth(ctrl)
+			tc.mockBehaviour(auth)
+
+			// Create router
+			router := gin.Default()
+			authMiddleware := &AuthMiddleware{auth, nil}
+			router.GET("/protected",
+				authMiddleware.UserIdentity(),
+				RequireRole(entity.RoleSeller, entity.RoleAdmin),
+				func(c *gin.Context) { c.Status(http.StatusOK) },
+			)
+
+			// Create request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+			if tc.authHeader != "" {
+				req.Header.Set("Authorization", tc.authHeader)
+			}
+
+			// Execute request
+			router.ServeHTTP(w, req)
+
+			// Check response
+			assert.Equal(t, tc.wantStatusCode, w.Code)
+		})
+	}
+}
+
+func TestAuthMiddleware_APIKey(t *testing.T) {
+	type MockBehaviour func(m *servicemocks.MockAPIKey)
+
+	keyIdentity := types.AuthIdentity{
+		UserID:   1,
+		Roles:    []entity.Role{entity.RoleSeller},
+		APIKeyID: 7,
+		Scopes:   []entity.APIKeyScope{entity.ScopeProductsRead},
+	}
+
+	testCases := []struct {
+		name           string
+		method         string
+		path           string
+		headers        map[string]string
+		mockBehaviour  MockBehaviour
+		wantStatusCode int
+	}{
+		{
+			name:    "Scope granted",
+			method:  http.MethodGet,
+			path:    "/products",
+			headers: map[string]string{"X-API-Key": "gmk_key"},
+			mockBehaviour: func(m *servicemocks.MockAPIKey) {
+				m.EXPECT().Authenticate(gomock.Any(), "gmk_key").Return(keyIdentity, nil)
+			},
+			wantStatusCode: 200,
+		},
+		{
+			name:    "Write scope missing",
+			method:  http.MethodPost,
+			path:    "/products",
+			headers: map[string]string{"X-API-Key": "gmk_key"},
+			mockBehaviour: func(m *servicemocks.MockAPIKey) {
+				m.EXPECT().Authenticate(gomock.Any(), "gmk_key").Return(keyIdentity, nil)
+			},
+			wantStatusCode: 403,
+		},
+		{
+			name:    "Read-only scope missing",
+			method:  http.MethodGet,
+			path:    "/seller/sales",
+			headers: map[string]string{"X-API-Key": "gmk_key"},
+			mockBehaviour: func(m *servicemocks.MockAPIKey) {
+				m.EXPECT().Authenticate(gomock.Any(), "gmk_key").Return(keyIdentity, nil)
+			},
+			wantStatusCode: 403,
+		},
+		{
+			name:    "Session only route",
+			method:  http.MethodGet,
+			path:    "/users/me",
+			headers: map[string]string{"X-API-Key": "gmk_key"},
+			mockBehaviour: func(m *servicemocks.MockAPIKey) {
+				m.EXPECT().Authenticate(gomock.Any(), "gmk_key").Return(keyIdentity, nil)
+			},
+			wantStatusCode: 403,
+		},
+		{
+			name:    "Invalid key",
+			method:  http.MethodGet,
+			path:    "/products",
+			headers: map[string]string{"X-API-Key": "gmk_key"},
+			mockBehaviour: func(m *servicemocks.MockAPIKey) {
+				m.EXPECT().Authenticate(gomock.Any(), "gmk_key").Return(types.AuthIdentity{}, serviceerrs.ErrInvalidAPIKey)
+			},
+			wantStatusCode: 401,
+		},
+		{
+			name:   "Both headers",
+			method: http.MethodGet,
+			path:   "/products",
+			headers: map[string]string{
+				"X-API-Key":     "gmk_key",
+				"Authorization": "Bearer token",
+			},
+			mockBehaviour:  func(m *servicemocks.MockAPIKey) {},
+			wantStatusCode: 401,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Init service mock
+			auth := servicemocks.NewMockAuth(ctrl)
+			apiKeys := servicemocks.NewMockAPIKey(ctrl)
+			tc.mockBehaviour(apiKeys)
+
+			// Create router
+			ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+			router := gin.Default()
+			authMiddleware := &AuthMiddleware{auth, apiKeys}
+			api := router.Group("", authMiddleware.UserIdentity())
+			products := api.Group("/products", RequireScope(entity.ScopeProductsRead, entity.ScopeProductsWrite))
+			products.GET("", ok)
+			products.POST("", ok)
+			api.GET("/seller/sales", RequireReadScope(entity.ScopeSellerRead), ok)
+			api.GET("/users/me", RequireSession(), ok)
+
+			// Create request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+
+			// Execute request
+			router.ServeHTTP(w, req)
+
+			// Check response
+			assert.Equal(t, tc.wantStatusCode, w.Code)
+		})
+	}
+}
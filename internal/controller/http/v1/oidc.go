@@ -0,0 +1,122 @@
+package v1
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/cripplemymind9/go-market/internal/service"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+// oidcStateCookie привязывает начатый вход к браузеру: без него чужая ссылка
+// возврата от провайдера не будет принята (защита от login CSRF).
+const oidcStateCookie = "oidc_state"
+
+type oidcRoutes struct {
+	oidcService service.OIDC
+	authService service.Auth
+}
+
+func newOIDCRoutes(g *gin.RouterGroup, oidcService service.OIDC, authService service.Auth) {
+	r := &oidcRoutes{
+		oidcService: oidcService,
+		authService: authService,
+	}
+
+	g.GET("/login", r.login)
+	g.GET("/callback", r.callback)
+}
+
+// login перенаправляет на страницу входа внешнего провайдера
+// @Summary Sign in with the company identity provider
+// @Description Start the OpenID Connect authorization code flow with PKCE and redirect to the identity provider
+// @Tags auth
+// @Success 302 "Redirect to the identity provider"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Router /auth/oidc/login [get]
+func (r *oidcRoutes) login(c *gin.Context) {
+	start, err := r.oidcService.Begin(c.Request.Context())
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	r.setStateCookie(c, start.State, 0)
+	c.Redirect(http.StatusFound, start.AuthURL)
+}
+
+// callback завершает вход через внешнего провайдера
+// @Summary Identity provider callback
+// @Description Finish the OpenID Connect login: the provider redirects here with a code, which is exchanged for the JWT access token and refresh token. On the first login the external account is linked to the user with the same verified email or a new user is created. If the user has two-factor authentication enabled, a challenge token is returned instead and has to be exchanged via /auth/2fa/verify
+// @Tags auth
+// @Produce json
+// @Param code query string true "Authorization code"
+// @Param state query string true "State from the login redirect"
+// @Success 200 {object} tokensResponse
+// @Success 202 {object} twoFactorChallengeResponse "Second factor required"
+// @Failure 400 {object} ErrorResonse "Missing parameters or invalid login state"
+// @Failure 401 {object} ErrorResonse "Login denied by the provider or invalid ID token"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Router /auth/oidc/callback [get]
+func (r *oidcRoutes) callback(c *gin.Context) {
+	cookieState, _ := c.Cookie(oidcStateCookie)
+	r.setStateCookie(c, "", -1)
+
+	if c.Query("error") != "" {
+		newErrorResponse(c, http.StatusUnauthorized, "external login was denied: "+c.Query("error"))
+		return
+	}
+
+	state, code := c.Query("state"), c.Query("code")
+	if state == "" || code == "" {
+		newErrorResponse(c, http.StatusBadRequest, "state and code are required")
+		return
+	}
+
+	if subtle.ConstantTimeCompare([]byte(cookieState), []byte(state)) != 1 {
+		newErrorResponse(c, http.StatusBadRequest, serviceerrs.ErrInvalidOIDCState.Error())
+		return
+	}
+
+	user, err := r.oidcService.Complete(c.Request.Context(), types.OIDCCompleteInput{
+		State: state,
+		Code:  code,
+	})
+	if err != nil {
+		switch err {
+		case serviceerrs.ErrInvalidOIDCState:
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+		case serviceerrs.ErrOIDCLoginFailed, serviceerrs.ErrUserNotFound:
+			newErrorResponse(c, http.StatusUnauthorized, serviceerrs.ErrOIDCLoginFailed.Error())
+		default:
+			newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	tokens, err := r.authService.IssueTokens(c.Request.Context(), user.ID)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	if tokens.ChallengeToken != "" {
+		c.JSON(http.StatusAccepted, twoFactorChallengeResponse{
+			ChallengeToken:    tokens.ChallengeToken,
+			TwoFactorRequired: true,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, newTokensResponse(tokens))
+}
+
+// setStateCookie ставит или, при maxAge < 0, удаляет cookie со state. Режим
+// Lax нужен, чтобы cookie дошел при перенаправлении от провайдера.
+func (r *oidcRoutes) setStateCookie(c *gin.Context, state string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, state, maxAge, "/auth/oidc", "", c.Request.TLS != nil, true)
+}
@@ -0,0 +1,161 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/mocks/servicemocks"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+func TestOIDCRoutes_Login(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	oidcService := servicemocks.NewMockOIDC(ctrl)
+	oidcService.EXPECT().Begin(gomock.Any()).Return(types.OIDCLoginStart{
+		AuthURL: "https://idp.example/authorize?state=state",
+		State:   "state",
+	}, nil)
+
+	router := gin.Default()
+	newOIDCRoutes(router.Group("/auth/oidc"), oidcService, servicemocks.NewMockAuth(ctrl))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/oidc/login", nil))
+
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "https://idp.example/authorize?state=state", w.Header().Get("Location"))
+	assert.Contains(t, w.Header().Get("Set-Cookie"), "oidc_state=state")
+	assert.Contains(t, w.Header().Get("Set-Cookie"), "HttpOnly")
+	assert.Contains(t, w.Header().Get("Set-Cookie"), "SameSite=Lax")
+}
+
+func TestOIDCRoutes_Callback(t *testing.T) {
+	type mockBehaviour func(o *servicemocks.MockOIDC, a *servicemocks.MockAuth)
+
+	ctx := context.Background()
+	input := types.OIDCCompleteInput{State: "state", Code: "code"}
+
+	testCases := []struct {
+		name            string
+		query           string
+		cookie          string
+		mockBehaviour   mockBehaviour
+		wantStatusCode  int
+		wantRequestBody string
+	}{
+		{
+			name:   "OK",
+			query:  "?state=state&code=code",
+			cookie: "state",
+			mockBehaviour: func(o *servicemocks.MockOIDC, a *servicemocks.MockAuth) {
+				o.EXPECT().Complete(ctx, input).Return(entity.User{ID: 5}, nil)
+				a.EXPECT().IssueTokens(ctx, 5).Return(types.AuthTokens{AccessToken: "token", RefreshToken: "sid.secret"}, nil)
+			},
+			wantStatusCode:  200,
+			wantRequestBody: `{"token":"token","refresh_token":"sid.secret"}`,
+		},
+		{
+			name:   "Second factor required",
+			query:  "?state=state&code=code",
+			cookie: "state",
+			mockBehaviour: func(o *servicemocks.MockOIDC, a *servicemocks.MockAuth) {
+				o.EXPECT().Complete(ctx, input).Return(entity.User{ID: 5}, nil)
+				a.EXPECT().IssueTokens(ctx, 5).Return(types.AuthTokens{ChallengeToken: "challenge"}, nil)
+			},
+			wantStatusCode:  202,
+			wantRequestBody: `{"challenge_token":"challenge","two_factor_required":true}`,
+		},
+		{
+			name:            "State does not match cookie",
+			query:           "?state=state&code=code",
+			cookie:          "another",
+			mockBehaviour:   func(o *servicemocks.MockOIDC, a *servicemocks.MockAuth) {},
+			wantStatusCode:  400,
+			wantRequestBody: `{"error":"invalid or expired login state"}`,
+		},
+		{
+			name:            "No cookie",
+			query:           "?state=state&code=code",
+			mockBehaviour:   func(o *servicemocks.MockOIDC, a *servicemocks.MockAuth) {},
+			wantStatusCode:  400,
+			wantRequestBody: `{"error":"invalid or expired login state"}`,
+		},
+		{
+			name:            "Denied by provider",
+			query:           "?state=state&error=access_denied",
+			cookie:          "state",
+			mockBehaviour:   func(o *servicemocks.MockOIDC, a *servicemocks.MockAuth) {},
+			wantStatusCode:  401,
+			wantRequestBody: `{"error":"external login was denied: access_denied"}`,
+		},
+		{
+			name:            "No code",
+			query:           "?state=state",
+			cookie:          "state",
+			mockBehaviour:   func(o *servicemocks.MockOIDC, a *servicemocks.MockAuth) {},
+			wantStatusCode:  400,
+			wantRequestBody: `{"error":"state and code are required"}`,
+		},
+		{
+			name:   "Invalid ID token",
+			query:  "?state=state&code=code",
+			cookie: "state",
+			mockBehaviour: func(o *servicemocks.MockOIDC, a *servicemocks.MockAuth) {
+				o.EXPECT().Complete(ctx, input).Return(entity.User{}, serviceerrs.ErrOIDCLoginFailed)
+			},
+			wantStatusCode:  401,
+			wantRequestBody: `{"error":"external login failed"}`,
+		},
+		{
+			name:   "Expired state",
+			query:  "?state=state&code=code",
+			cookie: "state",
+			mockBehaviour: func(o *servicemocks.MockOIDC, a *servicemocks.MockAuth) {
+				o.EXPECT().Complete(ctx, input).Return(entity.User{}, serviceerrs.ErrInvalidOIDCState)
+			},
+			wantStatusCode:  400,
+			wantRequestBody: `{"error":"invalid or expired login state"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Init service mock
+			oidcService := servicemocks.NewMockOIDC(ctrl)
+			authService := servicemocks.NewMockAuth(ctrl)
+			tc.mockBehaviour(oidcService, authService)
+
+			// Create router
+			router := gin.Default()
+			newOIDCRoutes(router.Group("/auth/oidc"), oidcService, authService)
+
+			// Create request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/auth/oidc/callback"+tc.query, nil)
+			if tc.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: oidcStateCookie, Value: tc.cookie})
+			}
+
+			// Execute request
+			router.ServeHTTP(w, req)
+
+			// Check response
+			assert.Equal(t, tc.wantStatusCode, w.Code)
+			assert.JSONEq(t, tc.wantRequestBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,347 @@
+package v1
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/service"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+type orderRoutes struct {
+	orderService service.Order
+	validator    *validator.Validate
+}
+
+func newOrderRoutes(g *gin.RouterGroup, orderService service.Order, validator *validator.Validate) {
+	r := &orderRoutes{
+		orderService: orderService,
+		validator:    validator,
+	}
+
+	g.GET("/:id", r.getOrder)
+	g.POST("/:id/cancel", r.cancel)
+	g.POST("/:id/advance", r.advance)
+	g.POST("/:id/refunds", r.refund)
+}
+
+type orderStatusChangeResponse struct {
+	From      entity.OrderStatus `json:"from,omitempty"`
+	To        entity.OrderStatus `json:"to"`
+	ActorID   int                `json:"actor_id,omitempty"`
+	Reason    string             `json:"reason,omitempty"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+type refundItemResponse struct {
+	ProductID int     `json:"product_id"`
+	Quantity  int     `json:"quantity"`
+	Amount    float64 `json:"amount"`
+}
+
+type refundResponse struct {
+	ID        int                  `json:"id"`
+	OrderID   int                  `json:"order_id"`
+	ActorID   int                  `json:"actor_id,omitempty"`
+	Reason    string               `json:"reason"`
+	Items     []refundItemResponse `json:"items"`
+	Amount    float64              `json:"amount"`
+	CreatedAt time.Time            `json:"created_at"`
+}
+
+func newRefundResponse(refund entity.Refund) refundResponse {
+	response := refundResponse{
+		ID:        refund.ID,
+		OrderID:   refund.OrderID,
+		ActorID:   refund.ActorID,
+		Reason:    refund.Reason,
+		Items:     make([]refundItemResponse, 0, len(refund.Items)),
+		Amount:    refund.Amount,
+		CreatedAt: refund.CreatedAt,
+	}
+	for _, item := range refund.Items {
+		response.Items = append(response.Items, refundItemResponse{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Amount:    item.Amount,
+		})
+	}
+	return response
+}
+
+type orderDetailsResponse struct {
+	orderResponse
+	History []orderStatusChangeResponse `json:"history"`
+	Refunds []refundResponse            `json:"refunds"`
+}
+
+func newOrderDetailsResponse(details types.OrderDetails) orderDetailsResponse {
+	response := orderDetailsResponse{
+		orderResponse: newOrderResponse(details.Order),
+		History:       make([]orderStatusChangeResponse, 0, len(details.History)),
+		Refunds:       make([]refundResponse, 0, len(details.Refunds)),
+	}
+	for _, change := range details.History {
+		response.History = append(response.History, orderStatusChangeResponse{
+			From:      change.From,
+			To:        change.To,
+			ActorID:   change.ActorID,
+			Reason:    change.Reason,
+			CreatedAt: change.CreatedAt,
+		})
+	}
+	for _, refund := range details.Refunds {
+		response.Refunds = append(response.Refunds, newRefundResponse(refund))
+	}
+	return response
+}
+
+// getOrder возвращает заказ с историей статусов и возвратами
+// @Summary Get order
+// @Description Get an order with its status history and refunds. The order is visible to the buyer, sellers of its lines and admins
+// @Tags orders
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} orderDetailsResponse
+// @Failure 400 {object} ErrorResonse "Invalid order ID"
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 404 {object} ErrorResonse "Order not found"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Router /api/v1/orders/{id} [get]
+func (r *orderRoutes) getOrder(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	identity, ok := getIdentity(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	details, err := r.orderService.GetOrder(c.Request.Context(), types.OrderGetOrderInput{
+		OrderID: id,
+		Actor:   identity,
+	})
+	if err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newOrderDetailsResponse(details))
+}
+
+// orderStatusInput представляет собой необязательную причину смены статуса.
+type orderStatusInput struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+// cancel отменяет заказ
+// @Summary Cancel order
+// @Description Cancel an order that has not been shipped yet. Stock is returned to the products and a paid order is refunded to the buyer. Allowed for the buyer, admins and the seller of all order lines
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param input body orderStatusInput false "Reason"
+// @Success 200 {object} orderResponse
+// @Failure 400 {object} ErrorResonse "Invalid order ID or request body"
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 403 {object} ErrorResonse "Not allowed to change this order"
+// @Failure 404 {object} ErrorResonse "Order not found"
+// @Failure 409 {object} ErrorResonse "Order status does not allow cancellation or has changed"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Router /api/v1/orders/{id}/cancel [post]
+func (r *orderRoutes) cancel(c *gin.Context) {
+	id, input, identity, ok := r.bindStatusInput(c)
+	if !ok {
+		return
+	}
+
+	order, err := r.orderService.Cancel(c.Request.Context(), types.OrderCancelInput{
+		OrderID: id,
+		Reason:  input.Reason,
+		Actor:   identity,
+	})
+	if err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newOrderResponse(order))
+}
+
+// advance переводит заказ на следующий шаг выполнения
+// @Summary Advance order
+// @Description Move an order to the next fulfilment status: paid to shipped, shipped to delivered. Allowed for admins and the seller of all order lines
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param input body orderStatusInput false "Reason"
+// @Success 200 {object} orderResponse
+// @Failure 400 {object} ErrorResonse "Invalid order ID or request body"
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 403 {object} ErrorResonse "Not allowed to change this order"
+// @Failure 404 {object} ErrorResonse "Order not found"
+// @Failure 409 {object} ErrorResonse "Order status cannot be advanced or has changed"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Router /api/v1/orders/{id}/advance [post]
+func (r *orderRoutes) advance(c *gin.Context) {
+	id, input, identity, ok := r.bindStatusInput(c)
+	if !ok {
+		return
+	}
+
+	order, err := r.orderService.Advance(c.Request.Context(), types.OrderAdvanceInput{
+		OrderID: id,
+		Reason:  input.Reason,
+		Actor:   identity,
+	})
+	if err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newOrderResponse(order))
+}
+
+// refundItemInput представляет собой возвращаемые единицы товара заказа.
+type refundItemInput struct {
+	ProductID int `json:"product_id" validate:"required"`
+	Quantity  int `json:"quantity" validate:"required,gt=0"`
+}
+
+// refundInput представляет собой модель данных для возврата. Без items
+// возвращается все, что еще не возвращено.
+type refundInput struct {
+	Reason string            `json:"reason" validate:"required,max=500"`
+	Items  []refundItemInput `json:"items" validate:"omitempty,max=100,dive"`
+}
+
+// refund оформляет возврат по заказу
+// @Summary Refund order
+// @Description Return units of a paid or delivered order: they go back to stock and their purchase price is credited to the buyer. Without items everything not yet refunded is returned, and the order becomes refunded once nothing is left. Allowed for admins and the seller of all refunded lines
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param input body refundInput true "Reason and returned units"
+// @Success 201 {object} refundResponse
+// @Failure 400 {object} ErrorResonse "Invalid order ID, request body or product not in the order"
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 403 {object} ErrorResonse "Not allowed to change this order"
+// @Failure 404 {object} ErrorResonse "Order not found"
+// @Failure 409 {object} ErrorResonse "Refund exceeds purchased quantity or order status does not allow refunds"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Router /api/v1/orders/{id}/refunds [post]
+func (r *orderRoutes) refund(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var input refundInput
+
+	if err := c.ShouldBindBodyWithJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := r.validator.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	identity, ok := getIdentity(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	items := make([]types.OrderRefundItemInput, 0, len(input.Items))
+	for _, item := range input.Items {
+		items = append(items, types.OrderRefundItemInput{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	refund, err := r.orderService.Refund(c.Request.Context(), types.OrderRefundInput{
+		OrderID: id,
+		Items:   items,
+		Reason:  input.Reason,
+		Actor:   identity,
+	})
+	if err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newRefundResponse(refund))
+}
+
+// bindStatusInput разбирает идентификатор заказа и необязательное тело запроса
+// смены статуса. При ошибке ответ уже записан.
+func (r *orderRoutes) bindStatusInput(c *gin.Context) (int, orderStatusInput, types.AuthIdentity, bool) {
+	var input orderStatusInput
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id")
+		return 0, input, types.AuthIdentity{}, false
+	}
+
+	if err := c.ShouldBindBodyWithJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return 0, input, types.AuthIdentity{}, false
+	}
+
+	if err := r.validator.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return 0, input, types.AuthIdentity{}, false
+	}
+
+	identity, ok := getIdentity(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return 0, input, types.AuthIdentity{}, false
+	}
+
+	return id, input, identity, true
+}
+
+func (r *orderRoutes) handleError(c *gin.Context, err error) {
+	switch err {
+	case serviceerrs.ErrOrderNotFound:
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+	case serviceerrs.ErrOrderForbidden:
+		newErrorResponse(c, http.StatusForbidden, err.Error())
+	case serviceerrs.ErrOrderItemNotFound, serviceerrs.ErrInvalidQuantity:
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+	case serviceerrs.ErrInvalidOrderTransition, serviceerrs.ErrOrderStatusChanged,
+		serviceerrs.ErrRefundExceedsPurchase, serviceerrs.ErrNothingToRefund:
+		newErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+	}
+}
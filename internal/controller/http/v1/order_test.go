@@ -0,0 +1,274 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/mocks/servicemocks"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+func TestOrderRoutes_GetOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	identity := types.AuthIdentity{UserID: 1, Roles: []entity.Role{entity.RoleBuyer}}
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	orders := servicemocks.NewMockOrder(ctrl)
+	orders.EXPECT().GetOrder(context.Background(), types.OrderGetOrderInput{OrderID: 7, Actor: identity}).Return(types.OrderDetails{
+		Order: entity.Order{
+			ID:        7,
+			UserID:    1,
+			Status:    entity.OrderStatusShipped,
+			Items:     []entity.OrderItem{{ProductID: 2, ProductName: "Tea", SellerID: 3, UnitPrice: 2.5, Quantity: 2}},
+			Total:     5,
+			CreatedAt: createdAt,
+		},
+		History: []entity.OrderStatusChange{
+			{OrderID: 7, To: entity.OrderStatusPaid, ActorID: 1, CreatedAt: createdAt},
+			{OrderID: 7, From: entity.OrderStatusPaid, To: entity.OrderStatusShipped, ActorID: 3, Reason: "tracking 123", CreatedAt: createdAt},
+		},
+	}, nil)
+
+	router := gin.Default()
+	router.GET("/api/v1/orders/:id", func(c *gin.Context) {
+		c.Set(userIdentityCtx, identity)
+	}, (&orderRoutes{orderService: orders, validator: validator.New()}).getOrder)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/orders/7", nil))
+
+	assert.Equal(t, 200, w.Code)
+	assert.JSONEq(t, `{
+		"id":7,"user_id":1,"status":"shipped","total":5,"created_at":"2024-01-02T03:04:05Z",
+		"items":[{"product_id":2,"product_name":"Tea","seller_id":3,"unit_price":2.5,"quantity":2}],
+		"history":[
+			{"to":"paid","actor_id":1,"created_at":"2024-01-02T03:04:05Z"},
+			{"from":"paid","to":"shipped","actor_id":3,"reason":"tracking 123","created_at":"2024-01-02T03:04:05Z"}
+		],
+		"refunds":[]
+	}`, w.Body.String())
+}
+
+func TestOrderRoutes_Cancel(t *testing.T) {
+	type MockBehaviour func(m *servicemocks.MockOrder)
+
+	identity := types.AuthIdentity{UserID: 1, Roles: []entity.Role{entity.RoleBuyer}}
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	testCases := []struct {
+		name            string
+		path            string
+		inputBody       string
+		mockBehaviour   MockBehaviour
+		wantStatusCode  int
+		wantRequestBody string
+	}{
+		{
+			name:      "OK",
+			path:      "/api/v1/orders/7/cancel",
+			inputBody: `{"reason":"changed my mind"}`,
+			mockBehaviour: func(m *servicemocks.MockOrder) {
+				m.EXPECT().Cancel(context.Background(), types.OrderCancelInput{OrderID: 7, Reason: "changed my mind", Actor: identity}).Return(entity.Order{
+					ID:        7,
+					UserID:    1,
+					Status:    entity.OrderStatusCancelled,
+					Items:     []entity.OrderItem{},
+					Total:     5,
+					CreatedAt: createdAt,
+				}, nil)
+			},
+			wantStatusCode:  200,
+			wantRequestBody: `{"id":7,"user_id":1,"status":"cancelled","items":[],"total":5,"created_at":"2024-01-02T03:04:05Z"}`,
+		},
+		{
+			name: "Without body",
+			path: "/api/v1/orders/7/cancel",
+			mockBehaviour: func(m *servicemocks.MockOrder) {
+				m.EXPECT().Cancel(context.Background(), types.OrderCancelInput{OrderID: 7, Actor: identity}).Return(entity.Order{
+					ID:        7,
+					UserID:    1,
+					Status:    entity.OrderStatusCancelled,
+					CreatedAt: createdAt,
+				}, nil)
+			},
+			wantStatusCode:  200,
+			wantRequestBody: `{"id":7,"user_id":1,"status":"cancelled","items":[],"total":0,"created_at":"2024-01-02T03:04:05Z"}`,
+		},
+		{
+			name:            "Invalid id",
+			path:            "/api/v1/orders/abc/cancel",
+			mockBehaviour:   func(m *servicemocks.MockOrder) {},
+			wantStatusCode:  400,
+			wantRequestBody: `{"error":"invalid id"}`,
+		},
+		{
+			name:      "Shipped order",
+			path:      "/api/v1/orders/7/cancel",
+			inputBody: `{}`,
+			mockBehaviour: func(m *servicemocks.MockOrder) {
+				m.EXPECT().Cancel(context.Background(), gomock.Any()).Return(entity.Order{}, serviceerrs.ErrInvalidOrderTransition)
+			},
+			wantStatusCode:  409,
+			wantRequestBody: `{"error":"order status does not allow this action"}`,
+		},
+		{
+			name: "Forbidden",
+			path: "/api/v1/orders/7/cancel",
+			mockBehaviour: func(m *servicemocks.MockOrder) {
+				m.EXPECT().Cancel(context.Background(), gomock.Any()).Return(entity.Order{}, serviceerrs.ErrOrderForbidden)
+			},
+			wantStatusCode:  403,
+			wantRequestBody: `{"error":"not allowed to change this order"}`,
+		},
+		{
+			name: "Not found",
+			path: "/api/v1/orders/7/cancel",
+			mockBehaviour: func(m *servicemocks.MockOrder) {
+				m.EXPECT().Cancel(context.Background(), gomock.Any()).Return(entity.Order{}, serviceerrs.ErrOrderNotFound)
+			},
+			wantStatusCode:  404,
+			wantRequestBody: `{"error":"order not found"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Init service mock
+			orders := servicemocks.NewMockOrder(ctrl)
+			tc.mockBehaviour(orders)
+
+			// Create router
+			router := gin.Default()
+			router.POST("/api/v1/orders/:id/cancel", func(c *gin.Context) {
+				c.Set(userIdentityCtx, identity)
+			}, (&orderRoutes{orderService: orders, validator: validator.New()}).cancel)
+
+			// Create request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, tc.path, bytes.NewBufferString(tc.inputBody))
+
+			// Execute request
+			router.ServeHTTP(w, req)
+
+			// Check response
+			assert.Equal(t, tc.wantStatusCode, w.Code)
+			assert.JSONEq(t, tc.wantRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestOrderRoutes_Refund(t *testing.T) {
+	type MockBehaviour func(m *servicemocks.MockOrder)
+
+	identity := types.AuthIdentity{UserID: 3, Roles: []entity.Role{entity.RoleSeller}}
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	testCases := []struct {
+		name            string
+		inputBody       string
+		mockBehaviour   MockBehaviour
+		wantStatusCode  int
+		wantRequestBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"reason":"damaged","items":[{"product_id":2,"quantity":1}]}`,
+			mockBehaviour: func(m *servicemocks.MockOrder) {
+				m.EXPECT().Refund(context.Background(), types.OrderRefundInput{
+					OrderID: 7,
+					Items:   []types.OrderRefundItemInput{{ProductID: 2, Quantity: 1}},
+					Reason:  "damaged",
+					Actor:   identity,
+				}).Return(entity.Refund{
+					ID:        4,
+					OrderID:   7,
+					ActorID:   3,
+					Reason:    "damaged",
+					Items:     []entity.RefundItem{{OrderItemID: 11, ProductID: 2, Quantity: 1, Amount: 2.5}},
+					Amount:    2.5,
+					CreatedAt: createdAt,
+				}, nil)
+			},
+			wantStatusCode:  201,
+			wantRequestBody: `{"id":4,"order_id":7,"actor_id":3,"reason":"damaged","items":[{"product_id":2,"quantity":1,"amount":2.5}],"amount":2.5,"created_at":"2024-01-02T03:04:05Z"}`,
+		},
+		{
+			name:            "Reason is required",
+			inputBody:       `{"items":[{"product_id":2,"quantity":1}]}`,
+			mockBehaviour:   func(m *servicemocks.MockOrder) {},
+			wantStatusCode:  400,
+			wantRequestBody: `{"error":"Key: 'refundInput.Reason' Error:Field validation for 'Reason' failed on the 'required' tag"}`,
+		},
+		{
+			name:            "Zero quantity",
+			inputBody:       `{"reason":"damaged","items":[{"product_id":2,"quantity":0}]}`,
+			mockBehaviour:   func(m *servicemocks.MockOrder) {},
+			wantStatusCode:  400,
+			wantRequestBody: `{"error":"Key: 'refundInput.Items[0].Quantity' Error:Field validation for 'Quantity' failed on the 'required' tag"}`,
+		},
+		{
+			name:      "Over-refund",
+			inputBody: `{"reason":"damaged","items":[{"product_id":2,"quantity":5}]}`,
+			mockBehaviour: func(m *servicemocks.MockOrder) {
+				m.EXPECT().Refund(context.Background(), gomock.Any()).Return(entity.Refund{}, serviceerrs.ErrRefundExceedsPurchase)
+			},
+			wantStatusCode:  409,
+			wantRequestBody: `{"error":"refund exceeds purchased quantity"}`,
+		},
+		{
+			name:      "Product not in order",
+			inputBody: `{"reason":"damaged","items":[{"product_id":9,"quantity":1}]}`,
+			mockBehaviour: func(m *servicemocks.MockOrder) {
+				m.EXPECT().Refund(context.Background(), gomock.Any()).Return(entity.Refund{}, serviceerrs.ErrOrderItemNotFound)
+			},
+			wantStatusCode:  400,
+			wantRequestBody: `{"error":"product is not in the order"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Init service mock
+			orders := servicemocks.NewMockOrder(ctrl)
+			tc.mockBehaviour(orders)
+
+			// Create router
+			router := gin.Default()
+			router.POST("/api/v1/orders/:id/refunds", func(c *gin.Context) {
+				c.Set(userIdentityCtx, identity)
+			}, (&orderRoutes{orderService: orders, validator: validator.New()}).refund)
+
+			// Create request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/orders/7/refunds", bytes.NewBufferString(tc.inputBody))
+
+			// Execute request
+			router.ServeHTTP(w, req)
+
+			// Check response
+			assert.Equal(t, tc.wantStatusCode, w.Code)
+			assert.JSONEq(t, tc.wantRequestBody, w.Body.String())
+		})
+	}
+}
@@ -3,6 +3,7 @@ package v1
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -24,32 +25,42 @@ func newProductRoutes(g *gin.RouterGroup, productService service.Product, valida
 		validator:      validator,
 	}
 
-	g.POST("/add-product", r.addProduct)
 	g.GET("/get-products", r.getAllProducts)
 	g.GET("/get-product/:id", r.getProduct)
-	g.PUT("/update-product/:id", r.updateProduct)
-	g.DELETE("/delete-product/:id", r.deleteProduct)
+
+	manage := g.Group("", RequireRole(entity.RoleSeller, entity.RoleAdmin))
+	manage.POST("/add-product", r.addProduct)
+	manage.PUT("/update-product/:id", r.updateProduct)
+	manage.DELETE("/delete-product/:id", r.deleteProduct)
+	manage.POST("/adjust-stock/:id", r.adjustStock)
+	manage.GET("/stock-movements/:id", r.getStockMovements)
+	manage.GET("/get-stock/:id", r.getStock)
+	manage.GET("/warehouse-stock/:id", r.getWarehouseStock)
+	manage.PUT("/reorder-threshold/:id", r.setReorderThreshold)
+	manage.GET("/stock-alerts/:id", r.getStockAlerts)
 }
 
 // addProductInput представляет собой модель данных для добавления продукта.
 type addProductInput struct {
 	Name        string  `json:"name" validate:"required"`
 	Description string  `json:"description" validate:"required"`
-	Price       float64 `json:"price" validate:"required"`
+	Price       float64 `json:"price" validate:"required,gte=0.01"`
 	Quantity    int     `json:"quantity" validate:"required"`
 }
 
 // addProduct добавляет новый продукт в каталог
 // @Summary Add a new product
-// @Description Add a new product with name, description, price, and quantity
+// @Description Add a new product with name, description, price, and quantity owned by the authenticated seller
 // @Tags products
 // @Accept json
 // @Produce json
 // @Param input body addProductInput true "Product input"
 // @Success 201 {object} v1.productRoutes.addProduct.response
 // @Failure 400 {object} ErrorResonse "Invalid request body or validation error"
+// @Failure 403 {object} ErrorResonse "Seller or admin role required"
 // @Failure 500 {object} ErrorResonse "Internal server error"
 // @Security ApiKeyAuth
+// @Security APIKeyHeader
 // @Router /api/v1/products/add-product [post]
 func (r *productRoutes) addProduct(c *gin.Context) {
 	var input addProductInput
@@ -64,17 +75,28 @@ func (r *productRoutes) addProduct(c *gin.Context) {
 		return
 	}
 
+	sellerId, ok := getUserId(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
 	id, err := r.productService.AddProduct(c.Request.Context(), types.ProductAddProductInput{
 		Name:        input.Name,
 		Description: input.Description,
 		Price:       input.Price,
 		Quantity:    input.Quantity,
+		SellerID:    sellerId,
 	})
 	if err != nil {
 		if err == serviceerrs.ErrProductAlreadyExists {
 			newErrorResponse(c, http.StatusInternalServerError, err.Error())
 			return
 		}
+		if err == serviceerrs.ErrInvalidPrice {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
 		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
 		return
 	}
@@ -96,6 +118,7 @@ func (r *productRoutes) addProduct(c *gin.Context) {
 // @Success 200 {object} v1.productRoutes.getAllProducts.response
 // @Failure 500 {object} ErrorResonse "Internal server error"
 // @Security ApiKeyAuth
+// @Security APIKeyHeader
 // @Router /api/v1/products/get-products [get]
 func (r *productRoutes) getAllProducts(c *gin.Context) {
 	var products []entity.Product
@@ -125,6 +148,7 @@ func (r *productRoutes) getAllProducts(c *gin.Context) {
 // @Failure 400 {object} ErrorResonse "Invalid product ID"
 // @Failure 500 {object} ErrorResonse "Internal server error"
 // @Security ApiKeyAuth
+// @Security APIKeyHeader
 // @Router /api/v1/products/get-product/{id} [get]
 func (r *productRoutes) getProduct(c *gin.Context) {
 	param := c.Param("id")
@@ -150,16 +174,16 @@ func (r *productRoutes) getProduct(c *gin.Context) {
 }
 
 // updateProductInput представляет собой модель данных для обновления продукта.
+// Остаток здесь не меняется, для этого есть корректировки остатка.
 type updateProductInput struct {
 	Name        string  `json:"name" validate:"required"`
 	Description string  `json:"description" validate:"required"`
-	Price       float64 `json:"price" validate:"required"`
-	Quantity    int     `json:"quantity" validate:"required"`
+	Price       float64 `json:"price" validate:"required,gte=0.01"`
 }
 
 // updateProduct обновляет информацию о продукте по его идентификатору
 // @Summary Update product by ID
-// @Description Update product details by ID with new name, description, price, and quantity
+// @Description Update product details by ID with new name, description and price. Stock is changed via stock adjustments. Only the owning seller or an admin may update a product
 // @Tags products
 // @Accept json
 // @Produce json
@@ -167,8 +191,11 @@ type updateProductInput struct {
 // @Param input body updateProductInput true "Product update input"
 // @Success 200 {object} map[string]interface{} "Success message"
 // @Failure 400 {object} ErrorResonse "Invalid request body or validation error"
+// @Failure 403 {object} ErrorResonse "Seller or admin role required, or product belongs to another seller"
+// @Failure 404 {object} ErrorResonse "Product not found"
 // @Failure 500 {object} ErrorResonse "Internal server error"
 // @Security ApiKeyAuth
+// @Security APIKeyHeader
 // @Router /api/v1/products/update-product/{id} [put]
 func (r *productRoutes) updateProduct(c *gin.Context) {
 	param := c.Param("id")
@@ -190,14 +217,20 @@ func (r *productRoutes) updateProduct(c *gin.Context) {
 		return
 	}
 
+	identity, ok := getIdentity(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
 	if err := r.productService.UpdateProduct(c.Request.Context(), types.ProductUpdateProductInput{
 		ID:          id,
 		Name:        input.Name,
 		Description: input.Description,
 		Price:       input.Price,
-		Quantity:    input.Quantity,
+		Actor:       identity,
 	}); err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		r.handleOwnershipError(c, err)
 		return
 	}
 
@@ -208,14 +241,17 @@ func (r *productRoutes) updateProduct(c *gin.Context) {
 
 // deleteProduct удаляет продукт по его идентификатору
 // @Summary Delete product by ID
-// @Description Delete a product by its ID
+// @Description Delete a product by its ID. Only the owning seller or an admin may delete a product
 // @Tags products
 // @Produce json
 // @Param id path int true "Product ID"
 // @Success 200 {object} map[string]interface{} "Success message"
 // @Failure 400 {object} ErrorResonse "Invalid product ID"
+// @Failure 403 {object} ErrorResonse "Seller or admin role required, or product belongs to another seller"
+// @Failure 404 {object} ErrorResonse "Product not found"
 // @Failure 500 {object} ErrorResonse "Internal server error"
 // @Security ApiKeyAuth
+// @Security APIKeyHeader
 // @Router /api/v1/products/delete-product/{id} [delete]
 func (r *productRoutes) deleteProduct(c *gin.Context) {
 	param := c.Param("id")
@@ -225,8 +261,344 @@ func (r *productRoutes) deleteProduct(c *gin.Context) {
 		return
 	}
 
-	if err := r.productService.DeleteProduct(c.Request.Context(), id); err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+	identity, ok := getIdentity(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if err := r.productService.DeleteProduct(c.Request.Context(), types.ProductDeleteProductInput{
+		ID:    id,
+		Actor: identity,
+	}); err != nil {
+		r.handleOwnershipError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "succes",
+	})
+}
+
+type stockMovementResponse struct {
+	ID            int                          `json:"id"`
+	WarehouseID   int                          `json:"warehouse_id,omitempty"`
+	Kind          entity.InventoryMovementKind `json:"kind"`
+	Delta         int                          `json:"delta"`
+	QuantityAfter int                          `json:"quantity_after"`
+	ActorID       int                          `json:"actor_id,omitempty"`
+	Reason        string                       `json:"reason,omitempty"`
+	OrderID       int                          `json:"order_id,omitempty"`
+	CreatedAt     time.Time                    `json:"created_at"`
+}
+
+func newStockMovementResponse(movement entity.InventoryMovement) stockMovementResponse {
+	return stockMovementResponse{
+		ID:            movement.ID,
+		WarehouseID:   movement.WarehouseID,
+		Kind:          movement.Kind,
+		Delta:         movement.Delta,
+		QuantityAfter: movement.QuantityAfter,
+		ActorID:       movement.ActorID,
+		Reason:        movement.Reason,
+		OrderID:       movement.OrderID,
+		CreatedAt:     movement.CreatedAt,
+	}
+}
+
+// adjustStockInput представляет собой модель данных для ручного изменения
+// остатка на складе. restock добавляет поступившие единицы, adjustment
+// исправляет остаток в любую сторону. Без warehouse_id меняется остаток на
+// складе по умолчанию.
+type adjustStockInput struct {
+	WarehouseID int                          `json:"warehouse_id" validate:"omitempty,min=1"`
+	Kind        entity.InventoryMovementKind `json:"kind" validate:"required,oneof=restock adjustment"`
+	Delta       int                          `json:"delta" validate:"required"`
+	Reason      string                       `json:"reason" validate:"required,max=500"`
+}
+
+// adjustStock меняет остаток товара
+// @Summary Adjust product stock
+// @Description Change the stock of a product in a warehouse and record the movement with the actor and reason. Kind restock adds received units (positive delta), adjustment corrects the stock in either direction. Without warehouse_id the default (highest priority) warehouse is used. The warehouse stock cannot go below zero and reserved units cannot be written off. Only the owning seller or an admin may adjust stock
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param input body adjustStockInput true "Stock change"
+// @Success 201 {object} stockMovementResponse
+// @Failure 400 {object} ErrorResonse "Invalid ID, request body or validation error"
+// @Failure 403 {object} ErrorResonse "Seller or admin role required, or product belongs to another seller"
+// @Failure 404 {object} ErrorResonse "Product or warehouse not found"
+// @Failure 409 {object} ErrorResonse "Stock would go below zero in the warehouse or below the reserved quantity"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Router /api/v1/products/adjust-stock/{id} [post]
+func (r *productRoutes) adjustStock(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var input adjustStockInput
+
+	if err := c.ShouldBindBodyWithJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := r.validator.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	identity, ok := getIdentity(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	movement, err := r.productService.AdjustStock(c.Request.Context(), types.ProductAdjustStockInput{
+		ID:          id,
+		WarehouseID: input.WarehouseID,
+		Kind:        input.Kind,
+		Delta:       input.Delta,
+		Reason:      input.Reason,
+		Actor:       identity,
+	})
+	if err != nil {
+		r.handleStockError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newStockMovementResponse(movement))
+}
+
+// getStockMovements возвращает историю движения товара
+// @Summary Get product stock movements
+// @Description Get restocks, sales, refunds and manual adjustments of a product in the period [from, to), oldest first. Without to the period ends now. Only the owning seller or an admin may see the history
+// @Tags products
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param from query string false "Period start, RFC 3339"
+// @Param to query string false "Period end, RFC 3339"
+// @Success 200 {array} stockMovementResponse
+// @Failure 400 {object} ErrorResonse "Invalid ID or period"
+// @Failure 403 {object} ErrorResonse "Seller or admin role required, or product belongs to another seller"
+// @Failure 404 {object} ErrorResonse "Product not found"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Router /api/v1/products/stock-movements/{id} [get]
+func (r *productRoutes) getStockMovements(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	from, ok := parseTimeQuery(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := parseTimeQuery(c, "to")
+	if !ok {
+		return
+	}
+
+	identity, ok := getIdentity(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	movements, err := r.productService.GetStockMovements(c.Request.Context(), types.ProductStockMovementsInput{
+		ID:    id,
+		From:  from,
+		To:    to,
+		Actor: identity,
+	})
+	if err != nil {
+		r.handleStockError(c, err)
+		return
+	}
+
+	response := make([]stockMovementResponse, 0, len(movements))
+	for _, movement := range movements {
+		response = append(response, newStockMovementResponse(movement))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type stockResponse struct {
+	ProductID int       `json:"product_id"`
+	At        time.Time `json:"at"`
+	Quantity  int       `json:"quantity"`
+}
+
+// getStock возвращает остаток товара на момент времени
+// @Summary Get product stock at a point in time
+// @Description Reconstruct the stock of a product at the given moment from its movement history. Without at the current stock is returned. Only the owning seller or an admin may see it
+// @Tags products
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param at query string false "Moment, RFC 3339"
+// @Success 200 {object} stockResponse
+// @Failure 400 {object} ErrorResonse "Invalid ID or moment"
+// @Failure 403 {object} ErrorResonse "Seller or admin role required, or product belongs to another seller"
+// @Failure 404 {object} ErrorResonse "Product not found"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Router /api/v1/products/get-stock/{id} [get]
+func (r *productRoutes) getStock(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	at, ok := parseTimeQuery(c, "at")
+	if !ok {
+		return
+	}
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	identity, ok := getIdentity(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	quantity, err := r.productService.GetStockAt(c.Request.Context(), types.ProductStockAtInput{
+		ID:    id,
+		At:    at,
+		Actor: identity,
+	})
+	if err != nil {
+		r.handleStockError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stockResponse{
+		ProductID: id,
+		At:        at,
+		Quantity:  quantity,
+	})
+}
+
+type warehouseStockResponse struct {
+	WarehouseID   int    `json:"warehouse_id"`
+	WarehouseName string `json:"warehouse_name"`
+	Quantity      int    `json:"quantity"`
+}
+
+// getWarehouseStock возвращает остатки товара по складам
+// @Summary Get product stock by warehouse
+// @Description Get the stock of a product in every warehouse that has ever held it, in warehouse priority order. The product quantity is the sum of these stocks. Only the owning seller or an admin may see it
+// @Tags products
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {array} warehouseStockResponse
+// @Failure 400 {object} ErrorResonse "Invalid ID"
+// @Failure 403 {object} ErrorResonse "Seller or admin role required, or product belongs to another seller"
+// @Failure 404 {object} ErrorResonse "Product not found"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Router /api/v1/products/warehouse-stock/{id} [get]
+func (r *productRoutes) getWarehouseStock(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	identity, ok := getIdentity(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	stock, err := r.productService.GetWarehouseStock(c.Request.Context(), types.ProductWarehouseStockInput{
+		ID:    id,
+		Actor: identity,
+	})
+	if err != nil {
+		r.handleStockError(c, err)
+		return
+	}
+
+	response := make([]warehouseStockResponse, 0, len(stock))
+	for _, s := range stock {
+		response = append(response, warehouseStockResponse{
+			WarehouseID:   s.WarehouseID,
+			WarehouseName: s.WarehouseName,
+			Quantity:      s.Quantity,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// reorderThresholdInput представляет собой модель данных для порога дозаказа.
+// null снимает порог.
+type reorderThresholdInput struct {
+	Threshold *int `json:"threshold" validate:"omitempty,min=0"`
+}
+
+// setReorderThreshold задает порог дозаказа товара
+// @Summary Set product reorder threshold
+// @Description Set the stock level at or below which the seller is alerted that the product needs restocking. The alert fires once per crossing: it is sent when the stock drops to the threshold and re-armed when the stock rises above it again. A null threshold disables alerts. Only the owning seller or an admin may set it
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param input body reorderThresholdInput true "Reorder threshold"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResonse "Invalid ID, request body or validation error"
+// @Failure 403 {object} ErrorResonse "Seller or admin role required, or product belongs to another seller"
+// @Failure 404 {object} ErrorResonse "Product not found"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Router /api/v1/products/reorder-threshold/{id} [put]
+func (r *productRoutes) setReorderThreshold(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var input reorderThresholdInput
+
+	if err := c.ShouldBindBodyWithJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := r.validator.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	identity, ok := getIdentity(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	err = r.productService.SetReorderThreshold(c.Request.Context(), types.ProductReorderThresholdInput{
+		ID:        id,
+		Threshold: input.Threshold,
+		Actor:     identity,
+	})
+	if err != nil {
+		r.handleStockError(c, err)
 		return
 	}
 
@@ -234,3 +606,112 @@ func (r *productRoutes) deleteProduct(c *gin.Context) {
 		"message": "succes",
 	})
 }
+
+type stockAlertResponse struct {
+	ID                int        `json:"id"`
+	ProductID         int        `json:"product_id"`
+	Threshold         int        `json:"threshold"`
+	Quantity          int        `json:"quantity"`
+	Attempts          int        `json:"attempts"`
+	DeliveredChannels []string   `json:"delivered_channels"`
+	CreatedAt         time.Time  `json:"created_at"`
+	NotifiedAt        *time.Time `json:"notified_at"`
+	ResolvedAt        *time.Time `json:"resolved_at"`
+}
+
+// getStockAlerts возвращает предупреждения о низком остатке товара
+// @Summary Get product stock alerts
+// @Description Get the low stock alerts of a product, newest first. An alert without resolved_at is still open: the stock has not risen above the threshold since. An alert without notified_at has not been delivered yet; failed deliveries are retried with backoff and counted in attempts, and only through channels not yet listed in delivered_channels. Only the owning seller or an admin may see them
+// @Tags products
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {array} stockAlertResponse
+// @Failure 400 {object} ErrorResonse "Invalid ID"
+// @Failure 403 {object} ErrorResonse "Seller or admin role required, or product belongs to another seller"
+// @Failure 404 {object} ErrorResonse "Product not found"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Router /api/v1/products/stock-alerts/{id} [get]
+func (r *productRoutes) getStockAlerts(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	identity, ok := getIdentity(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	alerts, err := r.productService.GetStockAlerts(c.Request.Context(), types.ProductStockAlertsInput{
+		ID:    id,
+		Actor: identity,
+	})
+	if err != nil {
+		r.handleStockError(c, err)
+		return
+	}
+
+	response := make([]stockAlertResponse, 0, len(alerts))
+	for _, a := range alerts {
+		response = append(response, stockAlertResponse{
+			ID:                a.ID,
+			ProductID:         a.ProductID,
+			Threshold:         a.Threshold,
+			Quantity:          a.Quantity,
+			Attempts:          a.Attempts,
+			DeliveredChannels: a.DeliveredChannels,
+			CreatedAt:         a.CreatedAt,
+			NotifiedAt:        a.NotifiedAt,
+			ResolvedAt:        a.ResolvedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// parseTimeQuery разбирает необязательный параметр запроса в формате RFC 3339.
+// При ошибке ответ уже отправлен.
+func parseTimeQuery(c *gin.Context, name string) (time.Time, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return time.Time{}, true
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid "+name+", expected RFC 3339 time")
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
+func (r *productRoutes) handleStockError(c *gin.Context, err error) {
+	switch err {
+	case serviceerrs.ErrInvalidStockAdjustment, serviceerrs.ErrInvalidStockPeriod, serviceerrs.ErrInvalidReorderThreshold:
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+	case serviceerrs.ErrWarehouseNotFound:
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+	case serviceerrs.ErrStockBelowReserved:
+		newErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		r.handleOwnershipError(c, err)
+	}
+}
+
+func (r *productRoutes) handleOwnershipError(c *gin.Context, err error) {
+	switch err {
+	case serviceerrs.ErrProductNotFound:
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+	case serviceerrs.ErrProductNotOwned:
+		newErrorResponse(c, http.StatusForbidden, err.Error())
+	case serviceerrs.ErrInvalidPrice:
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+	default:
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+	}
+}
@@ -0,0 +1,270 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/mocks/servicemocks"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+func TestProductRoutes_AddProduct(t *testing.T) {
+	type MockBehaviour func(m *servicemocks.MockProduct)
+
+	testCases := []struct {
+		name            string
+		body            string
+		mockBehaviour   MockBehaviour
+		wantStatusCode  int
+		wantRequestBody string
+	}{
+		{
+			name: "OK",
+			body: `{"name":"Keyboard","description":"Mechanical","price":99.5,"quantity":3}`,
+			mockBehaviour: func(m *servicemocks.MockProduct) {
+				m.EXPECT().AddProduct(context.Background(), types.ProductAddProductInput{
+					Name: "Keyboard", Description: "Mechanical", Price: 99.5, Quantity: 3, SellerID: 5,
+				}).Return(7, nil)
+			},
+			wantStatusCode:  201,
+			wantRequestBody: `{"id":7}`,
+		},
+		{
+			name:            "Negative price",
+			body:            `{"name":"Keyboard","description":"Mechanical","price":-10,"quantity":3}`,
+			mockBehaviour:   func(m *servicemocks.MockProduct) {},
+			wantStatusCode:  400,
+			wantRequestBody: `{"error":"Key: 'addProductInput.Price' Error:Field validation for 'Price' failed on the 'gte' tag"}`,
+		},
+		{
+			name:            "Price below one kopeck",
+			body:            `{"name":"Keyboard","description":"Mechanical","price":0.001,"quantity":3}`,
+			mockBehaviour:   func(m *servicemocks.MockProduct) {},
+			wantStatusCode:  400,
+			wantRequestBody: `{"error":"Key: 'addProductInput.Price' Error:Field validation for 'Price' failed on the 'gte' tag"}`,
+		},
+		{
+			name: "Price rejected by storage",
+			body: `{"name":"Keyboard","description":"Mechanical","price":0.01,"quantity":3}`,
+			mockBehaviour: func(m *servicemocks.MockProduct) {
+				m.EXPECT().AddProduct(context.Background(), types.ProductAddProductInput{
+					Name: "Keyboard", Description: "Mechanical", Price: 0.01, Quantity: 3, SellerID: 5,
+				}).Return(0, serviceerrs.ErrInvalidPrice)
+			},
+			wantStatusCode:  400,
+			wantRequestBody: `{"error":"price must be at least 0.01"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			products := servicemocks.NewMockProduct(ctrl)
+			tc.mockBehaviour(products)
+
+			router := gin.Default()
+			router.POST("/api/v1/products/add-product", func(c *gin.Context) {
+				c.Set(userIdCtx, 5)
+			}, (&productRoutes{productService: products, validator: validator.New()}).addProduct)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/products/add-product", bytes.NewBufferString(tc.body)))
+
+			assert.Equal(t, tc.wantStatusCode, w.Code)
+			assert.JSONEq(t, tc.wantRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestProductRoutes_AdjustStock(t *testing.T) {
+	type MockBehaviour func(m *servicemocks.MockProduct)
+
+	seller := types.AuthIdentity{UserID: 5, Roles: []entity.Role{entity.RoleSeller}}
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name            string
+		body            string
+		mockBehaviour   MockBehaviour
+		wantStatusCode  int
+		wantRequestBody string
+	}{
+		{
+			name: "OK",
+			body: `{"kind":"restock","delta":5,"reason":"delivery"}`,
+			mockBehaviour: func(m *servicemocks.MockProduct) {
+				m.EXPECT().AdjustStock(context.Background(), types.ProductAdjustStockInput{
+					ID: 1, Kind: entity.InventoryMovementRestock, Delta: 5, Reason: "delivery", Actor: seller,
+				}).Return(entity.InventoryMovement{
+					ID: 3, ProductID: 1, Kind: entity.InventoryMovementRestock, Delta: 5, QuantityAfter: 15,
+					ActorID: 5, Reason: "delivery", CreatedAt: createdAt,
+				}, nil)
+			},
+			wantStatusCode:  201,
+			wantRequestBody: `{"id":3,"kind":"restock","delta":5,"quantity_after":15,"actor_id":5,"reason":"delivery","created_at":"2024-05-01T12:00:00Z"}`,
+		},
+		{
+			name:            "Sale kind",
+			body:            `{"kind":"sale","delta":-1,"reason":"sold"}`,
+			mockBehaviour:   func(m *servicemocks.MockProduct) {},
+			wantStatusCode:  400,
+			wantRequestBody: `{"error":"Key: 'adjustStockInput.Kind' Error:Field validation for 'Kind' failed on the 'oneof' tag"}`,
+		},
+		{
+			name: "Below reserved",
+			body: `{"kind":"adjustment","delta":-8,"reason":"stocktake"}`,
+			mockBehaviour: func(m *servicemocks.MockProduct) {
+				m.EXPECT().AdjustStock(context.Background(), gomock.Any()).Return(entity.InventoryMovement{}, serviceerrs.ErrStockBelowReserved)
+			},
+			wantStatusCode:  409,
+			wantRequestBody: `{"error":"stock cannot go below zero in the warehouse or below the reserved quantity"}`,
+		},
+		{
+			name: "Another seller",
+			body: `{"kind":"adjustment","delta":1,"reason":"found"}`,
+			mockBehaviour: func(m *servicemocks.MockProduct) {
+				m.EXPECT().AdjustStock(context.Background(), gomock.Any()).Return(entity.InventoryMovement{}, serviceerrs.ErrProductNotOwned)
+			},
+			wantStatusCode:  403,
+			wantRequestBody: `{"error":"product belongs to another seller"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			products := servicemocks.NewMockProduct(ctrl)
+			tc.mockBehaviour(products)
+
+			router := gin.Default()
+			router.POST("/api/v1/products/adjust-stock/:id", func(c *gin.Context) {
+				c.Set(userIdCtx, seller.UserID)
+				c.Set(userIdentityCtx, seller)
+			}, (&productRoutes{productService: products, validator: validator.New()}).adjustStock)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/products/adjust-stock/1", bytes.NewBufferString(tc.body)))
+
+			assert.Equal(t, tc.wantStatusCode, w.Code)
+			assert.JSONEq(t, tc.wantRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestProductRoutes_GetStock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	admin := types.AuthIdentity{UserID: 1, Roles: []entity.Role{entity.RoleAdmin}}
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	products := servicemocks.NewMockProduct(ctrl)
+	products.EXPECT().GetStockAt(context.Background(), types.ProductStockAtInput{ID: 1, At: at, Actor: admin}).Return(7, nil)
+
+	router := gin.Default()
+	router.GET("/api/v1/products/get-stock/:id", func(c *gin.Context) {
+		c.Set(userIdCtx, admin.UserID)
+		c.Set(userIdentityCtx, admin)
+	}, (&productRoutes{productService: products, validator: validator.New()}).getStock)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/products/get-stock/1?at=2024-05-01T12:00:00Z", nil))
+
+	assert.Equal(t, 200, w.Code)
+	assert.JSONEq(t, `{"product_id":1,"at":"2024-05-01T12:00:00Z","quantity":7}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/products/get-stock/1?at=yesterday", nil))
+
+	assert.Equal(t, 400, w.Code)
+	assert.JSONEq(t, `{"error":"invalid at, expected RFC 3339 time"}`, w.Body.String())
+}
+
+func TestProductRoutes_SetReorderThreshold(t *testing.T) {
+	type MockBehaviour func(m *servicemocks.MockProduct)
+
+	seller := types.AuthIdentity{UserID: 5, Roles: []entity.Role{entity.RoleSeller}}
+	threshold := 3
+
+	testCases := []struct {
+		name            string
+		body            string
+		mockBehaviour   MockBehaviour
+		wantStatusCode  int
+		wantRequestBody string
+	}{
+		{
+			name: "OK",
+			body: `{"threshold":3}`,
+			mockBehaviour: func(m *servicemocks.MockProduct) {
+				m.EXPECT().SetReorderThreshold(context.Background(), types.ProductReorderThresholdInput{
+					ID: 1, Threshold: &threshold, Actor: seller,
+				}).Return(nil)
+			},
+			wantStatusCode:  200,
+			wantRequestBody: `{"message":"succes"}`,
+		},
+		{
+			name: "Disable",
+			body: `{"threshold":null}`,
+			mockBehaviour: func(m *servicemocks.MockProduct) {
+				m.EXPECT().SetReorderThreshold(context.Background(), types.ProductReorderThresholdInput{ID: 1, Actor: seller}).Return(nil)
+			},
+			wantStatusCode:  200,
+			wantRequestBody: `{"message":"succes"}`,
+		},
+		{
+			name:            "Negative",
+			body:            `{"threshold":-1}`,
+			mockBehaviour:   func(m *servicemocks.MockProduct) {},
+			wantStatusCode:  400,
+			wantRequestBody: `{"error":"Key: 'reorderThresholdInput.Threshold' Error:Field validation for 'Threshold' failed on the 'min' tag"}`,
+		},
+		{
+			name: "Product not found",
+			body: `{"threshold":3}`,
+			mockBehaviour: func(m *servicemocks.MockProduct) {
+				m.EXPECT().SetReorderThreshold(context.Background(), gomock.Any()).Return(serviceerrs.ErrProductNotFound)
+			},
+			wantStatusCode:  404,
+			wantRequestBody: `{"error":"product not found"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			products := servicemocks.NewMockProduct(ctrl)
+			tc.mockBehaviour(products)
+
+			router := gin.Default()
+			router.PUT("/api/v1/products/reorder-threshold/:id", func(c *gin.Context) {
+				c.Set(userIdCtx, seller.UserID)
+				c.Set(userIdentityCtx, seller)
+			}, (&productRoutes{productService: products, validator: validator.New()}).setReorderThreshold)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/api/v1/products/reorder-threshold/1", bytes.NewBufferString(tc.body)))
+
+			assert.Equal(t, tc.wantStatusCode, w.Code)
+			assert.JSONEq(t, tc.wantRequestBody, w.Body.String())
+		})
+	}
+}
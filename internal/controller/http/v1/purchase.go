@@ -3,12 +3,14 @@ package v1
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 
 	"github.com/cripplemymind9/go-market/internal/entity"
 	"github.com/cripplemymind9/go-market/internal/service"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
 	"github.com/cripplemymind9/go-market/internal/service/types"
 )
 
@@ -17,35 +19,55 @@ type purchaseRoutes struct {
 	validator       *validator.Validate
 }
 
-func newPurchaseRoutes(g *gin.RouterGroup, purchaseService service.Purchase, validator *validator.Validate) {
+func newPurchaseRoutes(g *gin.RouterGroup, purchaseService service.Purchase, validator *validator.Validate, idempotent gin.HandlerFunc) {
 	r := &purchaseRoutes{
 		purchaseService: purchaseService,
 		validator:       validator,
 	}
 
-	g.POST("/make-purchase", r.makePurchase)
+	g.POST("/make-purchase", idempotent, r.makePurchase)
+	g.GET("/me", r.getMyPurchases)
 	g.GET("/get-user-purchase/:id", r.getUserPurchases)
 	g.GET("/get-product-purchase/:id", r.getProductPurchases)
 }
 
-// makePurcahseInput представляет собой модель данных для запроса на покупку продукта.
+// makePurchaseItemInput представляет собой строку заказа.
+type makePurchaseItemInput struct {
+	ProductID int `json:"product_id" validate:"required"`
+	Quantity  int `json:"quantity" validate:"required,gt=0"`
+}
+
+// makePurcahseInput представляет собой модель данных для запроса на покупку.
+// Покупается либо один продукт (product_id и quantity), либо несколько
+// строк из items одним заказом. Если UserID не указан, покупка совершается
+// от имени текущего пользователя. По ShipTo выбираются ближайшие склады.
 type makePurcahseInput struct {
-	UserID    int `json:"user_id"`
-	ProductID int `json:"product_id"`
-	Quantity  int `json:"quantity"`
+	UserID    int                     `json:"user_id"`
+	ProductID int                     `json:"product_id" validate:"required_without=Items,excluded_with=Items"`
+	Quantity  int                     `json:"quantity" validate:"required_without=Items,excluded_with=Items,omitempty,gt=0"`
+	Items     []makePurchaseItemInput `json:"items" validate:"omitempty,max=100,dive"`
+	ShipTo    *geoPointInput          `json:"ship_to"`
 }
 
-// makePurchase осуществляет покупку продукта
+// makePurchase оформляет заказ
 // @Summary Make a purchase
-// @Description Allows a user to purchase a product by specifying user ID, product ID, and quantity
+// @Description Allows the authenticated user to purchase a product by specifying product ID and quantity, or several products at once via items. All lines are bought as one order: if any line fails, nothing is bought. Prices are fixed at purchase time. The order is shipped from warehouses chosen by the configured allocation strategy; with ship_to the nearest strategy prefers warehouses closest to the buyer. Only admins may purchase on behalf of another user ID. A request retried with the same Idempotency-Key and body gets the original response instead of buying again
 // @Tags purchases
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Unique key of the purchase attempt, e.g. a UUID"
 // @Param input body makePurcahseInput true "Purchase input data"
 // @Success 201 {object} v1.purchaseRoutes.makePurchase.response
-// @Failure 400 {object} ErrorResonse "Invalid request body or validation error"
+// @Failure 400 {object} ErrorResonse "Invalid request body, validation error or empty order"
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 402 {object} ErrorResonse "Not enough balance"
+// @Failure 403 {object} ErrorResonse "Purchase on behalf of another user is forbidden or buyer email is not verified"
+// @Failure 404 {object} ErrorResonse "Product or buyer not found"
+// @Failure 409 {object} ErrorResonse "Not enough stock or request with this idempotency key is still in progress"
+// @Failure 422 {object} ErrorResonse "Idempotency key was used with a different request"
 // @Failure 500 {object} ErrorResonse "Internal server error"
 // @Security ApiKeyAuth
+// @Security APIKeyHeader
 // @Router /api/v1/purchase/make-purchase [post]
 func (r *purchaseRoutes) makePurchase(c *gin.Context) {
 	var input makePurcahseInput
@@ -60,13 +82,50 @@ func (r *purchaseRoutes) makePurchase(c *gin.Context) {
 		return
 	}
 
-	id, err := r.purchaseService.MakePurchase(c.Request.Context(), types.PurchaseMakePurchaseInput{
-		UserID:    input.UserID,
-		ProductID: input.ProductID,
-		Quantity:  input.Quantity,
-	})
+	userId, ok := getUserId(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if input.UserID != 0 && input.UserID != userId {
+		if !canActFor(c, input.UserID) {
+			newErrorResponse(c, http.StatusForbidden, "forbidden")
+			return
+		}
+		userId = input.UserID
+	}
+
+	purchase := types.PurchaseMakePurchaseInput{UserID: userId, ShipTo: input.ShipTo.toEntity()}
+	if input.ProductID != 0 {
+		purchase.Items = append(purchase.Items, types.PurchaseItemInput{
+			ProductID: input.ProductID,
+			Quantity:  input.Quantity,
+		})
+	}
+	for _, item := range input.Items {
+		purchase.Items = append(purchase.Items, types.PurchaseItemInput{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	id, err := r.purchaseService.MakePurchase(c.Request.Context(), purchase)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		switch err {
+		case serviceerrs.ErrEmptyOrder, serviceerrs.ErrInvalidQuantity:
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+		case serviceerrs.ErrProductNotFound, serviceerrs.ErrUserNotFound:
+			newErrorResponse(c, http.StatusNotFound, err.Error())
+		case serviceerrs.ErrEmailNotVerified:
+			newErrorResponse(c, http.StatusForbidden, err.Error())
+		case serviceerrs.ErrNotEnoughStock:
+			newErrorResponse(c, http.StatusConflict, err.Error())
+		case serviceerrs.ErrNotEnoughBalance:
+			newErrorResponse(c, http.StatusPaymentRequired, err.Error())
+		default:
+			newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		}
 		return
 	}
 
@@ -79,17 +138,96 @@ func (r *purchaseRoutes) makePurchase(c *gin.Context) {
 	})
 }
 
+type orderItemResponse struct {
+	ProductID   int     `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	SellerID    int     `json:"seller_id,omitempty"`
+	UnitPrice   float64 `json:"unit_price"`
+	Quantity    int     `json:"quantity"`
+}
+
+type orderResponse struct {
+	ID        int                 `json:"id"`
+	UserID    int                 `json:"user_id"`
+	Status    entity.OrderStatus  `json:"status"`
+	Items     []orderItemResponse `json:"items"`
+	Total     float64             `json:"total"`
+	CreatedAt time.Time           `json:"created_at"`
+}
+
+type ordersResponse struct {
+	Orders []orderResponse `json:"orders"`
+}
+
+func newOrderResponse(order entity.Order) orderResponse {
+	response := orderResponse{
+		ID:        order.ID,
+		UserID:    order.UserID,
+		Status:    order.Status,
+		Items:     make([]orderItemResponse, 0, len(order.Items)),
+		Total:     order.Total,
+		CreatedAt: order.CreatedAt,
+	}
+	for _, item := range order.Items {
+		response.Items = append(response.Items, orderItemResponse{
+			ProductID:   item.ProductID,
+			ProductName: item.ProductName,
+			SellerID:    item.SellerID,
+			UnitPrice:   item.UnitPrice,
+			Quantity:    item.Quantity,
+		})
+	}
+	return response
+}
+
+func newOrdersResponse(orders []entity.Order) ordersResponse {
+	response := ordersResponse{Orders: make([]orderResponse, 0, len(orders))}
+	for _, order := range orders {
+		response.Orders = append(response.Orders, newOrderResponse(order))
+	}
+	return response
+}
+
+// getMyPurchases возвращает список покупок текущего пользователя
+// @Summary Get my purchases
+// @Description Retrieve the orders of the authenticated user with prices fixed at purchase time
+// @Tags purchases
+// @Produce json
+// @Success 200 {object} ordersResponse
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Router /api/v1/purchase/me [get]
+func (r *purchaseRoutes) getMyPurchases(c *gin.Context) {
+	userId, ok := getUserId(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	orders, err := r.purchaseService.GetUserOrders(c.Request.Context(), userId)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, newOrdersResponse(orders))
+}
+
 // getUserPurchases возвращает список покупок пользователя
 // @Summary Get user purchases
-// @Description Retrieve a list of purchases made by a user specified by user ID
+// @Description Retrieve the orders of a user specified by user ID. Only admins may read orders of another user
 // @Tags purchases
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
-// @Success 200 {object} v1.purchaseRoutes.getUserPurchases.response
+// @Success 200 {object} ordersResponse
 // @Failure 400 {object} ErrorResonse "Invalid request body or validation error"
+// @Failure 403 {object} ErrorResonse "Reading purchases of another user is forbidden"
 // @Failure 500 {object} ErrorResonse "Internal server error"
 // @Security ApiKeyAuth
+// @Security APIKeyHeader
 // @Router /api/v1/purchase/get-user-purchase/{id} [get]
 func (r *purchaseRoutes) getUserPurchases(c *gin.Context) {
 	param := c.Param("id")
@@ -99,32 +237,32 @@ func (r *purchaseRoutes) getUserPurchases(c *gin.Context) {
 		return
 	}
 
-	purchases, err := r.purchaseService.GetUserPurchases(c.Request.Context(), id)
-	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+	if !canActFor(c, id) {
+		newErrorResponse(c, http.StatusForbidden, "forbidden")
 		return
 	}
 
-	type response struct {
-		Purchases []entity.Purchase
+	orders, err := r.purchaseService.GetUserOrders(c.Request.Context(), id)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return
 	}
 
-	c.JSON(http.StatusOK, response{
-		Purchases: purchases,
-	})
+	c.JSON(http.StatusOK, newOrdersResponse(orders))
 }
 
 // getProductPurchases возвращает список покупок по идентификатору продукта
 // @Summary Get product purchases
-// @Description Retrieve a list of purchases for a specific product by product ID
+// @Description Retrieve the orders containing a specific product by product ID. Only the lines of this product are returned
 // @Tags purchases
 // @Accept json
 // @Produce json
 // @Param id path int true "Product ID"
-// @Success 200 {object} v1.purchaseRoutes.getProductPurchases.response
+// @Success 200 {object} ordersResponse
 // @Failure 400 {object} ErrorResonse "Invalid request body or validation error"
 // @Failure 500 {object} ErrorResonse "Internal server error"
 // @Security ApiKeyAuth
+// @Security APIKeyHeader
 // @Router /api/v1/purchase/get-product-purchase/{id} [get]
 func (r *purchaseRoutes) getProductPurchases(c *gin.Context) {
 	param := c.Param("id")
@@ -134,17 +272,11 @@ func (r *purchaseRoutes) getProductPurchases(c *gin.Context) {
 		return
 	}
 
-	purchases, err := r.purchaseService.GetProductPurchases(c.Request.Context(), id)
+	orders, err := r.purchaseService.GetProductOrders(c.Request.Context(), id)
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
-	type response struct {
-		Purchases []entity.Purchase
-	}
-
-	c.JSON(http.StatusOK, response{
-		Purchases: purchases,
-	})
+	c.JSON(http.StatusOK, newOrdersResponse(orders))
 }
@@ -0,0 +1,330 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/mocks/servicemocks"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+func TestPurchaseRoutes_MakePurchase(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		input types.PurchaseMakePurchaseInput
+	}
+
+	type MockBehaviour func(m *servicemocks.MockPurchase, args args)
+
+	testCases := []struct {
+		name            string
+		args            args
+		identity        types.AuthIdentity
+		inputBody       string
+		mockBehaviour   MockBehaviour
+		wantStatusCode  int
+		wantRequestBody string
+	}{
+		{
+			name: "OK",
+			args: args{
+				ctx: context.Background(),
+				input: types.PurchaseMakePurchaseInput{
+					UserID: 1,
+					Items:  []types.PurchaseItemInput{{ProductID: 1, Quantity: 2}},
+				},
+			},
+			inputBody: `{"user_id":1,"product_id":1,"quantity":2}`,
+			mockBehaviour: func(m *servicemocks.MockPurchase, args args) {
+				m.EXPECT().MakePurchase(args.ctx, args.input).Return(1, nil)
+			},
+			wantStatusCode:  201,
+			wantRequestBody: `{"id":1}`,
+		},
+		{
+			name: "Buyer taken from token",
+			args: args{
+				ctx: context.Background(),
+				input: types.PurchaseMakePurchaseInput{
+					UserID: 7,
+					Items:  []types.PurchaseItemInput{{ProductID: 1, Quantity: 1}},
+				},
+			},
+			identity:  types.AuthIdentity{UserID: 7},
+			inputBody: `{"product_id":1,"quantity":1}`,
+			mockBehaviour: func(m *servicemocks.MockPurchase, args args) {
+				m.EXPECT().MakePurchase(args.ctx, args.input).Return(2, nil)
+			},
+			wantStatusCode:  201,
+			wantRequestBody: `{"id":2}`,
+		},
+		{
+			name:            "Purchase for another user",
+			args:            args{},
+			identity:        types.AuthIdentity{UserID: 7},
+			inputBody:       `{"user_id":1,"product_id":1,"quantity":1}`,
+			mockBehaviour:   func(m *servicemocks.MockPurchase, args args) {},
+			wantStatusCode:  403,
+			wantRequestBody: `{"error":"forbidden"}`,
+		},
+		{
+			name: "Admin purchase for another user",
+			args: args{
+				ctx: context.Background(),
+				input: types.PurchaseMakePurchaseInput{
+					UserID: 1,
+					Items:  []types.PurchaseItemInput{{ProductID: 1, Quantity: 1}},
+				},
+			},
+			identity:  types.AuthIdentity{UserID: 7, Roles: []entity.Role{entity.RoleAdmin}},
+			inputBody: `{"user_id":1,"product_id":1,"quantity":1}`,
+			mockBehaviour: func(m *servicemocks.MockPurchase, args args) {
+				m.EXPECT().MakePurchase(args.ctx, args.input).Return(3, nil)
+			},
+			wantStatusCode:  201,
+			wantRequestBody: `{"id":3}`,
+		},
+		{
+			name: "Several items",
+			args: args{
+				ctx: context.Background(),
+				input: types.PurchaseMakePurchaseInput{
+					UserID: 1,
+					Items:  []types.PurchaseItemInput{{ProductID: 1, Quantity: 2}, {ProductID: 3, Quantity: 1}},
+				},
+			},
+			inputBody: `{"items":[{"product_id":1,"quantity":2},{"product_id":3,"quantity":1}]}`,
+			mockBehaviour: func(m *servicemocks.MockPurchase, args args) {
+				m.EXPECT().MakePurchase(args.ctx, args.input).Return(4, nil)
+			},
+			wantStatusCode:  201,
+			wantRequestBody: `{"id":4}`,
+		},
+		{
+			name:            "Product and items together",
+			args:            args{},
+			inputBody:       `{"product_id":1,"quantity":1,"items":[{"product_id":3,"quantity":1}]}`,
+			mockBehaviour:   func(m *servicemocks.MockPurchase, args args) {},
+			wantStatusCode:  400,
+			wantRequestBody: `{"error":"Key: 'makePurcahseInput.ProductID' Error:Field validation for 'ProductID' failed on the 'excluded_with' tag\nKey: 'makePurcahseInput.Quantity' Error:Field validation for 'Quantity' failed on the 'excluded_with' tag"}`,
+		},
+		{
+			name: "Empty order",
+			args: args{
+				ctx:   context.Background(),
+				input: types.PurchaseMakePurchaseInput{UserID: 1},
+			},
+			inputBody: `{"items":[]}`,
+			mockBehaviour: func(m *servicemocks.MockPurchase, args args) {
+				m.EXPECT().MakePurchase(args.ctx, args.input).Return(0, serviceerrs.ErrEmptyOrder)
+			},
+			wantStatusCode:  400,
+			wantRequestBody: `{"error":"order must contain at least one item"}`,
+		},
+		{
+			name:            "Invalid quantity",
+			args:            args{},
+			inputBody:       `{"user_id":1,"product_id":1,"quantity":-1}`,
+			mockBehaviour:   func(m *servicemocks.MockPurchase, args args) {},
+			wantStatusCode:  400,
+			wantRequestBody: `{"error":"Key: 'makePurcahseInput.Quantity' Error:Field validation for 'Quantity' failed on the 'gt' tag"}`,
+		},
+		{
+			name: "Product not found",
+			args: args{
+				ctx: context.Background(),
+				input: types.PurchaseMakePurchaseInput{
+					UserID: 1,
+					Items:  []types.PurchaseItemInput{{ProductID: 42, Quantity: 1}},
+				},
+			},
+			inputBody: `{"user_id":1,"product_id":42,"quantity":1}`,
+			mockBehaviour: func(m *servicemocks.MockPurchase, args args) {
+				m.EXPECT().MakePurchase(args.ctx, args.input).Return(0, serviceerrs.ErrProductNotFound)
+			},
+			wantStatusCode:  404,
+			wantRequestBody: `{"error":"product not found"}`,
+		},
+		{
+			name: "Not enough stock",
+			args: args{
+				ctx: context.Background(),
+				input: types.PurchaseMakePurchaseInput{
+					UserID: 1,
+					Items:  []types.PurchaseItemInput{{ProductID: 1, Quantity: 100}},
+				},
+			},
+			inputBody: `{"user_id":1,"product_id":1,"quantity":100}`,
+			mockBehaviour: func(m *servicemocks.MockPurchase, args args) {
+				m.EXPECT().MakePurchase(args.ctx, args.input).Return(0, serviceerrs.ErrNotEnoughStock)
+			},
+			wantStatusCode:  409,
+			wantRequestBody: `{"error":"not enough stock"}`,
+		},
+		{
+			name: "Not enough balance",
+			args: args{
+				ctx: context.Background(),
+				input: types.PurchaseMakePurchaseInput{
+					UserID: 1,
+					Items:  []types.PurchaseItemInput{{ProductID: 1, Quantity: 1}},
+				},
+			},
+			inputBody: `{"user_id":1,"product_id":1,"quantity":1}`,
+			mockBehaviour: func(m *servicemocks.MockPurchase, args args) {
+				m.EXPECT().MakePurchase(args.ctx, args.input).Return(0, serviceerrs.ErrNotEnoughBalance)
+			},
+			wantStatusCode:  402,
+			wantRequestBody: `{"error":"not enough balance"}`,
+		},
+		{
+			name: "Internal server error",
+			args: args{
+				ctx: context.Background(),
+				input: types.PurchaseMakePurchaseInput{
+					UserID: 1,
+					Items:  []types.PurchaseItemInput{{ProductID: 1, Quantity: 1}},
+				},
+			},
+			inputBody: `{"user_id":1,"product_id":1,"quantity":1}`,
+			mockBehaviour: func(m *servicemocks.MockPurchase, args args) {
+				m.EXPECT().MakePurchase(args.ctx, args.input).Return(0, errors.New("some error"))
+			},
+			wantStatusCode:  500,
+			wantRequestBody: `{"error":"internal server error"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Init service mock
+			purchase := servicemocks.NewMockPurchase(ctrl)
+			tc.mockBehaviour(purchase, tc.args)
+
+			// Create router
+			router := gin.Default()
+			purchaseRoutes := &purchaseRoutes{
+				purchaseService: purchase,
+				validator:       validator.New(),
+			}
+			identity := tc.identity
+			if identity.UserID == 0 {
+				identity = types.AuthIdentity{UserID: 1}
+			}
+			router.POST("/api/v1/purchase/make-purchase", func(c *gin.Context) {
+				c.Set(userIdCtx, identity.UserID)
+				c.Set(userIdentityCtx, identity)
+			}, purchaseRoutes.makePurchase)
+
+			// Create request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/purchase/make-purchase", bytes.NewBufferString(tc.inputBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			// Execute request
+			router.ServeHTTP(w, req)
+
+			// Check response
+			assert.Equal(t, tc.wantStatusCode, w.Code)
+			assert.JSONEq(t, tc.wantRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestPurchaseRoutes_GetUserPurchases(t *testing.T) {
+	type MockBehaviour func(m *servicemocks.MockPurchase)
+
+	testCases := []struct {
+		name            string
+		identity        types.AuthIdentity
+		path            string
+		mockBehaviour   MockBehaviour
+		wantStatusCode  int
+		wantRequestBody string
+	}{
+		{
+			name:     "My purchases",
+			identity: types.AuthIdentity{UserID: 1},
+			path:     "/api/v1/purchase/me",
+			mockBehaviour: func(m *servicemocks.MockPurchase) {
+				m.EXPECT().GetUserOrders(gomock.Any(), 1).Return([]entity.Order{}, nil)
+			},
+			wantStatusCode:  200,
+			wantRequestBody: `{"orders":[]}`,
+		},
+		{
+			name:     "Own purchases by ID",
+			identity: types.AuthIdentity{UserID: 1},
+			path:     "/api/v1/purchase/get-user-purchase/1",
+			mockBehaviour: func(m *servicemocks.MockPurchase) {
+				m.EXPECT().GetUserOrders(gomock.Any(), 1).Return([]entity.Order{}, nil)
+			},
+			wantStatusCode:  200,
+			wantRequestBody: `{"orders":[]}`,
+		},
+		{
+			name:            "Purchases of another user",
+			identity:        types.AuthIdentity{UserID: 1},
+			path:            "/api/v1/purchase/get-user-purchase/2",
+			mockBehaviour:   func(m *servicemocks.MockPurchase) {},
+			wantStatusCode:  403,
+			wantRequestBody: `{"error":"forbidden"}`,
+		},
+		{
+			name:     "Admin reads purchases of another user",
+			identity: types.AuthIdentity{UserID: 1, Roles: []entity.Role{entity.RoleAdmin}},
+			path:     "/api/v1/purchase/get-user-purchase/2",
+			mockBehaviour: func(m *servicemocks.MockPurchase) {
+				m.EXPECT().GetUserOrders(gomock.Any(), 2).Return([]entity.Order{}, nil)
+			},
+			wantStatusCode:  200,
+			wantRequestBody: `{"orders":[]}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Init service mock
+			purchase := servicemocks.NewMockPurchase(ctrl)
+			tc.mockBehaviour(purchase)
+
+			// Create router
+			router := gin.Default()
+			g := router.Group("/api/v1/purchase", func(c *gin.Context) {
+				c.Set(userIdCtx, tc.identity.UserID)
+				c.Set(userIdentityCtx, tc.identity)
+			})
+			newPurchaseRoutes(g, purchase, validator.New(), func(c *gin.Context) {})
+
+			// Create request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+
+			// Execute request
+			router.ServeHTTP(w, req)
+
+			// Check response
+			assert.Equal(t, tc.wantStatusCode, w.Code)
+			assert.JSONEq(t, tc.wantRequestBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package v1
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/service"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+type reservationRoutes struct {
+	reservationService service.Reservation
+	validator          *validator.Validate
+}
+
+func newReservationRoutes(g *gin.RouterGroup, reservationService service.Reservation, validator *validator.Validate, idempotent gin.HandlerFunc) {
+	r := &reservationRoutes{
+		reservationService: reservationService,
+		validator:          validator,
+	}
+
+	g.POST("", r.reserve)
+	g.GET("/:id", r.getReservation)
+	g.POST("/:id/confirm", idempotent, r.confirm)
+	g.DELETE("/:id", r.release)
+}
+
+type reservationItemResponse struct {
+	ProductID int `json:"product_id"`
+	Quantity  int `json:"quantity"`
+}
+
+type reservationResponse struct {
+	ID        int                       `json:"id"`
+	Status    entity.ReservationStatus  `json:"status"`
+	Items     []reservationItemResponse `json:"items"`
+	OrderID   int                       `json:"order_id,omitempty"`
+	ExpiresAt time.Time                 `json:"expires_at"`
+	CreatedAt time.Time                 `json:"created_at"`
+}
+
+func newReservationResponse(reservation entity.Reservation) reservationResponse {
+	response := reservationResponse{
+		ID:        reservation.ID,
+		Status:    reservation.Status,
+		Items:     make([]reservationItemResponse, 0, len(reservation.Items)),
+		OrderID:   reservation.OrderID,
+		ExpiresAt: reservation.ExpiresAt,
+		CreatedAt: reservation.CreatedAt,
+	}
+	for _, item := range reservation.Items {
+		response.Items = append(response.Items, reservationItemResponse{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		})
+	}
+	return response
+}
+
+// reserveInput представляет собой модель данных для брони товаров.
+type reserveInput struct {
+	Items []makePurchaseItemInput `json:"items" validate:"required,min=1,max=100,dive"`
+}
+
+// reserve бронирует товары
+// @Summary Reserve products
+// @Description Hold products for the authenticated user while they pay. Reserved units are not available to other buyers until the reservation is confirmed, released or expires. All lines are reserved at once: if any line fails, nothing is reserved
+// @Tags reservations
+// @Accept json
+// @Produce json
+// @Param input body reserveInput true "Products and quantities"
+// @Success 201 {object} reservationResponse
+// @Failure 400 {object} ErrorResonse "Invalid request body or validation error"
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 403 {object} ErrorResonse "Buyer email is not verified"
+// @Failure 404 {object} ErrorResonse "Product or buyer not found"
+// @Failure 409 {object} ErrorResonse "Not enough stock"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Router /api/v1/reservations [post]
+func (r *reservationRoutes) reserve(c *gin.Context) {
+	userId, ok := getUserId(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input reserveInput
+
+	if err := c.ShouldBindBodyWithJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := r.validator.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reservation := types.ReservationCreateInput{UserID: userId}
+	for _, item := range input.Items {
+		reservation.Items = append(reservation.Items, types.PurchaseItemInput{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	created, err := r.reservationService.Reserve(c.Request.Context(), reservation)
+	if err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newReservationResponse(created))
+}
+
+// getReservation возвращает бронь
+// @Summary Get reservation
+// @Description Get a reservation of the authenticated user
+// @Tags reservations
+// @Produce json
+// @Param id path int true "Reservation ID"
+// @Success 200 {object} reservationResponse
+// @Failure 400 {object} ErrorResonse "Invalid ID"
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 404 {object} ErrorResonse "Reservation not found"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Router /api/v1/reservations/{id} [get]
+func (r *reservationRoutes) getReservation(c *gin.Context) {
+	input, ok := r.reservationInput(c)
+	if !ok {
+		return
+	}
+
+	reservation, err := r.reservationService.GetReservation(c.Request.Context(), input)
+	if err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newReservationResponse(reservation))
+}
+
+// confirm оплачивает бронь
+// @Summary Confirm reservation
+// @Description Pay for an active reservation at current prices. The reserved units are sold and the reservation is converted into an order. If payment fails, the reservation stays active until it expires. The order is shipped from warehouses chosen by the configured allocation strategy; with ship_to the nearest strategy prefers warehouses closest to the buyer. A request retried with the same Idempotency-Key gets the original response instead of paying again
+// @Tags reservations
+// @Accept json
+// @Produce json
+// @Param id path int true "Reservation ID"
+// @Param Idempotency-Key header string false "Unique key of the payment attempt, e.g. a UUID"
+// @Param input body checkoutInput false "Delivery destination"
+// @Success 201 {object} checkoutResponse
+// @Failure 400 {object} ErrorResonse "Invalid ID, invalid request body or validation error"
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 402 {object} ErrorResonse "Not enough balance"
+// @Failure 404 {object} ErrorResonse "Reservation or product not found"
+// @Failure 409 {object} ErrorResonse "Reservation is no longer active or has expired"
+// @Failure 422 {object} ErrorResonse "Idempotency key was used with a different request"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Router /api/v1/reservations/{id}/confirm [post]
+func (r *reservationRoutes) confirm(c *gin.Context) {
+	reservation, ok := r.reservationInput(c)
+	if !ok {
+		return
+	}
+
+	var input checkoutInput
+	if err := c.ShouldBindBodyWithJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := r.validator.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := r.reservationService.Confirm(c.Request.Context(), types.ReservationConfirmInput{
+		ReservationID: reservation.ReservationID,
+		UserID:        reservation.UserID,
+		ShipTo:        input.ShipTo.toEntity(),
+	})
+	if err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, checkoutResponse{
+		OrderID: id,
+	})
+}
+
+// release снимает бронь
+// @Summary Release reservation
+// @Description Cancel an active reservation of the authenticated user and return the products to sale
+// @Tags reservations
+// @Produce json
+// @Param id path int true "Reservation ID"
+// @Success 200 {object} map[string]interface{} "Success message"
+// @Failure 400 {object} ErrorResonse "Invalid ID"
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 404 {object} ErrorResonse "Reservation not found"
+// @Failure 409 {object} ErrorResonse "Reservation is no longer active"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Router /api/v1/reservations/{id} [delete]
+func (r *reservationRoutes) release(c *gin.Context) {
+	input, ok := r.reservationInput(c)
+	if !ok {
+		return
+	}
+
+	if err := r.reservationService.Release(c.Request.Context(), input); err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "succes",
+	})
+}
+
+// reservationInput разбирает id брони и текущего пользователя. При ошибке
+// ответ уже отправлен.
+func (r *reservationRoutes) reservationInput(c *gin.Context) (types.ReservationInput, bool) {
+	userId, ok := getUserId(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return types.ReservationInput{}, false
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id")
+		return types.ReservationInput{}, false
+	}
+
+	return types.ReservationInput{ReservationID: id, UserID: userId}, true
+}
+
+func (r *reservationRoutes) handleError(c *gin.Context, err error) {
+	switch err {
+	case serviceerrs.ErrEmptyOrder, serviceerrs.ErrInvalidQuantity:
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+	case serviceerrs.ErrReservationNotFound, serviceerrs.ErrProductNotFound, serviceerrs.ErrUserNotFound:
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+	case serviceerrs.ErrEmailNotVerified:
+		newErrorResponse(c, http.StatusForbidden, err.Error())
+	case serviceerrs.ErrNotEnoughStock, serviceerrs.ErrReservationNotActive, serviceerrs.ErrReservationExpired:
+		newErrorResponse(c, http.StatusConflict, err.Error())
+	case serviceerrs.ErrNotEnoughBalance:
+		newErrorResponse(c, http.StatusPaymentRequired, err.Error())
+	default:
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+	}
+}
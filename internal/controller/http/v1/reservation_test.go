@@ -0,0 +1,151 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/mocks/servicemocks"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+func TestReservationRoutes_Reserve(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	reservations := servicemocks.NewMockReservation(ctrl)
+	reservations.EXPECT().Reserve(context.Background(), types.ReservationCreateInput{
+		UserID: 1,
+		Items:  []types.PurchaseItemInput{{ProductID: 2, Quantity: 3}},
+	}).Return(entity.Reservation{
+		ID:        7,
+		UserID:    1,
+		Status:    entity.ReservationStatusActive,
+		Items:     []entity.ReservationItem{{ProductID: 2, Quantity: 3}},
+		ExpiresAt: createdAt.Add(15 * time.Minute),
+		CreatedAt: createdAt,
+	}, nil)
+
+	router := gin.Default()
+	router.POST("/api/v1/reservations", func(c *gin.Context) {
+		c.Set(userIdCtx, 1)
+	}, (&reservationRoutes{reservationService: reservations, validator: validator.New()}).reserve)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/reservations",
+		bytes.NewBufferString(`{"items":[{"product_id":2,"quantity":3}]}`)))
+
+	assert.Equal(t, 201, w.Code)
+	assert.JSONEq(t, `{"id":7,"status":"active","items":[{"product_id":2,"quantity":3}],"expires_at":"2024-05-01T12:15:00Z","created_at":"2024-05-01T12:00:00Z"}`, w.Body.String())
+}
+
+func TestReservationRoutes_Confirm(t *testing.T) {
+	type MockBehaviour func(m *servicemocks.MockReservation)
+
+	input := types.ReservationConfirmInput{ReservationID: 7, UserID: 1}
+
+	testCases := []struct {
+		name            string
+		id              string
+		inputBody       string
+		mockBehaviour   MockBehaviour
+		wantStatusCode  int
+		wantRequestBody string
+	}{
+		{
+			name: "OK",
+			id:   "7",
+			mockBehaviour: func(m *servicemocks.MockReservation) {
+				m.EXPECT().Confirm(context.Background(), input).Return(11, nil)
+			},
+			wantStatusCode:  201,
+			wantRequestBody: `{"order_id":11}`,
+		},
+		{
+			name:      "OK with destination",
+			id:        "7",
+			inputBody: `{"ship_to":{"latitude":55.75,"longitude":37.62}}`,
+			mockBehaviour: func(m *servicemocks.MockReservation) {
+				withShipTo := input
+				withShipTo.ShipTo = &entity.GeoPoint{Latitude: 55.75, Longitude: 37.62}
+				m.EXPECT().Confirm(context.Background(), withShipTo).Return(11, nil)
+			},
+			wantStatusCode:  201,
+			wantRequestBody: `{"order_id":11}`,
+		},
+		{
+			name:            "Invalid request body",
+			id:              "7",
+			inputBody:       `{"ship_to":`,
+			mockBehaviour:   func(m *servicemocks.MockReservation) {},
+			wantStatusCode:  400,
+			wantRequestBody: `{"error":"invalid request body"}`,
+		},
+		{
+			name: "Expired",
+			id:   "7",
+			mockBehaviour: func(m *servicemocks.MockReservation) {
+				m.EXPECT().Confirm(context.Background(), input).Return(0, serviceerrs.ErrReservationExpired)
+			},
+			wantStatusCode:  409,
+			wantRequestBody: `{"error":"reservation has expired"}`,
+		},
+		{
+			name: "Not enough balance",
+			id:   "7",
+			mockBehaviour: func(m *servicemocks.MockReservation) {
+				m.EXPECT().Confirm(context.Background(), input).Return(0, serviceerrs.ErrNotEnoughBalance)
+			},
+			wantStatusCode:  402,
+			wantRequestBody: `{"error":"not enough balance"}`,
+		},
+		{
+			name: "Not found",
+			id:   "7",
+			mockBehaviour: func(m *servicemocks.MockReservation) {
+				m.EXPECT().Confirm(context.Background(), input).Return(0, serviceerrs.ErrReservationNotFound)
+			},
+			wantStatusCode:  404,
+			wantRequestBody: `{"error":"reservation not found"}`,
+		},
+		{
+			name:            "Invalid id",
+			id:              "abc",
+			mockBehaviour:   func(m *servicemocks.MockReservation) {},
+			wantStatusCode:  400,
+			wantRequestBody: `{"error":"invalid id"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			reservations := servicemocks.NewMockReservation(ctrl)
+			tc.mockBehaviour(reservations)
+
+			router := gin.Default()
+			router.POST("/api/v1/reservations/:id/confirm", func(c *gin.Context) {
+				c.Set(userIdCtx, 1)
+			}, (&reservationRoutes{reservationService: reservations, validator: validator.New()}).confirm)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/reservations/"+tc.id+"/confirm", bytes.NewBufferString(tc.inputBody)))
+
+			assert.Equal(t, tc.wantStatusCode, w.Code)
+			assert.JSONEq(t, tc.wantRequestBody, w.Body.String())
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 
 	_ "github.com/cripplemymind9/go-market/docs"
+	"github.com/cripplemymind9/go-market/internal/entity"
 	"github.com/cripplemymind9/go-market/internal/service"
 )
 
@@ -23,15 +24,33 @@ func NewRouter(router *gin.Engine, services *service.Services, validator *valida
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	newJWKSRoutes(router.Group("/.well-known"), services.Auth)
+
 	auth := router.Group("/auth")
 	{
-		newAuthRoutes(auth, services.Auth, validator)
+		newAuthRoutes(auth, services.Auth, services.Account, services.LoginThrottle, validator)
+		newAccountRoutes(auth, services.Account, validator)
+		if services.OIDC != nil {
+			newOIDCRoutes(auth.Group("/oidc"), services.OIDC, services.Auth)
+		}
 	}
 
-	authMiddleware := &AuthMiddleware{services.Auth}
+	authMiddleware := &AuthMiddleware{services.Auth, services.APIKey}
+	idempotency := &IdempotencyMiddleware{services.Idempotency}
 	v1 := router.Group("/api/v1", authMiddleware.UserIdentity())
 	{
-		newProductRoutes(v1.Group("/products"), services.Product, validator)
-		newPurchaseRoutes(v1.Group("/purchase"), services.Purchase, validator)
+		newUserRoutes(v1.Group("/users", RequireSession()), services.User, services.Account, validator)
+		newTwoFactorRoutes(v1.Group("/users/me/2fa", RequireSession()), services.TwoFactor, validator)
+		newAPIKeyRoutes(v1.Group("/users/me/api-keys", RequireSession()), services.APIKey, validator)
+		newProductRoutes(v1.Group("/products", RequireScope(entity.ScopeProductsRead, entity.ScopeProductsWrite)), services.Product, validator)
+		newWarehouseRoutes(v1.Group("/warehouses", RequireRole(entity.RoleSeller, entity.RoleAdmin), RequireScope(entity.ScopeProductsRead, entity.ScopeProductsWrite)), services.Warehouse, validator)
+		newSellerRoutes(v1.Group("/seller", RequireRole(entity.RoleSeller, entity.RoleAdmin), RequireReadScope(entity.ScopeSellerRead)), services.Seller)
+		newPurchaseRoutes(v1.Group("/purchase", RequireScope(entity.ScopePurchasesRead, entity.ScopePurchasesWrite)), services.Purchase, validator, idempotency.Idempotent())
+		newCartRoutes(v1.Group("/cart", RequireScope(entity.ScopePurchasesRead, entity.ScopePurchasesWrite)), services.Cart, validator, idempotency.Idempotent())
+		newOrderRoutes(v1.Group("/orders", RequireScope(entity.ScopePurchasesRead, entity.ScopePurchasesWrite)), services.Order, validator)
+		newReservationRoutes(v1.Group("/reservations", RequireScope(entity.ScopePurchasesRead, entity.ScopePurchasesWrite)), services.Reservation, validator, idempotency.Idempotent())
+		newWalletRoutes(v1.Group("/wallet", RequireScope(entity.ScopeWalletRead, entity.ScopeWalletWrite)), services.Wallet, validator)
+		newLedgerRoutes(v1.Group("/ledger", RequireRole(entity.RoleAdmin), RequireSession()), services.Ledger)
+		newAdminRoutes(v1.Group("/admin", RequireRole(entity.RoleAdmin), RequireSession()), services.Role, validator)
 	}
 }
@@ -0,0 +1,85 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/service"
+)
+
+type sellerRoutes struct {
+	sellerService service.Seller
+}
+
+func newSellerRoutes(g *gin.RouterGroup, sellerService service.Seller) {
+	r := &sellerRoutes{
+		sellerService: sellerService,
+	}
+
+	g.GET("/products", r.getProducts)
+	g.GET("/sales", r.getSales)
+}
+
+// getProducts возвращает товары текущего продавца
+// @Summary Get seller products
+// @Description Retrieve the products owned by the authenticated seller
+// @Tags seller
+// @Produce json
+// @Success 200 {object} v1.sellerRoutes.getProducts.response
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 403 {object} ErrorResonse "Seller or admin role required"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Router /api/v1/seller/products [get]
+func (r *sellerRoutes) getProducts(c *gin.Context) {
+	sellerId, ok := getUserId(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	products, err := r.sellerService.GetProducts(c.Request.Context(), sellerId)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	type response struct {
+		Products []entity.Product
+	}
+
+	c.JSON(http.StatusOK, response{
+		Products: products,
+	})
+}
+
+// getSales возвращает сводку продаж текущего продавца
+// @Summary Get seller sales
+// @Description Retrieve units sold and revenue per product and in total for the authenticated seller
+// @Tags seller
+// @Produce json
+// @Success 200 {object} entity.SellerSalesReport
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 403 {object} ErrorResonse "Seller or admin role required"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Router /api/v1/seller/sales [get]
+func (r *sellerRoutes) getSales(c *gin.Context) {
+	sellerId, ok := getUserId(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	report, err := r.sellerService.GetSalesReport(c.Request.Context(), sellerId)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,179 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/cripplemymind9/go-market/internal/service"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+type twoFactorRoutes struct {
+	twoFactorService service.TwoFactor
+	validator        *validator.Validate
+}
+
+func newTwoFactorRoutes(g *gin.RouterGroup, twoFactorService service.TwoFactor, validator *validator.Validate) {
+	r := &twoFactorRoutes{
+		twoFactorService: twoFactorService,
+		validator:        validator,
+	}
+
+	g.POST("/enroll", r.enroll)
+	g.POST("/confirm", r.confirm)
+	g.POST("/disable", r.disable)
+}
+
+type twoFactorEnrollmentResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// enroll начинает подключение второго фактора
+// @Summary Enroll two-factor authentication
+// @Description Generate a TOTP secret and an otpauth URI for the authenticator app. Two-factor authentication is enabled only after /confirm
+// @Tags users
+// @Produce json
+// @Success 200 {object} twoFactorEnrollmentResponse
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 404 {object} ErrorResonse "User not found"
+// @Failure 409 {object} ErrorResonse "Two-factor authentication is already enabled"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /api/v1/users/me/2fa/enroll [post]
+func (r *twoFactorRoutes) enroll(c *gin.Context) {
+	userId, ok := getUserId(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	enrollment, err := r.twoFactorService.Enroll(c.Request.Context(), userId)
+	if err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, twoFactorEnrollmentResponse{
+		Secret: enrollment.Secret,
+		URI:    enrollment.URI,
+	})
+}
+
+// twoFactorCodeInput представляет собой модель данных с кодом второго фактора.
+type twoFactorCodeInput struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type recoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// confirm включает второй фактор
+// @Summary Confirm two-factor authentication
+// @Description Enable two-factor authentication with a code from the authenticator app. Returns recovery codes, which are shown only once
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param input body twoFactorCodeInput true "Code from the authenticator app"
+// @Success 200 {object} recoveryCodesResponse
+// @Failure 400 {object} ErrorResonse "Invalid request body, validation error, invalid code or enrollment not started"
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 409 {object} ErrorResonse "Two-factor authentication is already enabled"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /api/v1/users/me/2fa/confirm [post]
+func (r *twoFactorRoutes) confirm(c *gin.Context) {
+	userId, ok := getUserId(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input twoFactorCodeInput
+
+	if err := c.ShouldBindBodyWithJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := r.validator.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	codes, err := r.twoFactorService.Confirm(c.Request.Context(), types.TwoFactorConfirmInput{
+		UserID: userId,
+		Code:   input.Code,
+	})
+	if err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, recoveryCodesResponse{
+		RecoveryCodes: codes,
+	})
+}
+
+// disable отключает второй фактор
+// @Summary Disable two-factor authentication
+// @Description Disable two-factor authentication with a code from the authenticator app or a recovery code
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param input body twoFactorCodeInput true "Code from the authenticator app or a recovery code"
+// @Success 200 {object} map[string]interface{} "Success message"
+// @Failure 400 {object} ErrorResonse "Invalid request body, validation error, invalid code or two-factor authentication not enabled"
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /api/v1/users/me/2fa/disable [post]
+func (r *twoFactorRoutes) disable(c *gin.Context) {
+	userId, ok := getUserId(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input twoFactorCodeInput
+
+	if err := c.ShouldBindBodyWithJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := r.validator.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err := r.twoFactorService.Disable(c.Request.Context(), types.TwoFactorDisableInput{
+		UserID: userId,
+		Code:   input.Code,
+	})
+	if err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "succes",
+	})
+}
+
+func (r *twoFactorRoutes) handleError(c *gin.Context, err error) {
+	switch err {
+	case serviceerrs.ErrInvalidTwoFactorCode, serviceerrs.ErrTwoFactorNotEnrolled, serviceerrs.ErrTwoFactorNotEnabled:
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+	case serviceerrs.ErrTwoFactorAlreadyEnabled:
+		newErrorResponse(c, http.StatusConflict, err.Error())
+	case serviceerrs.ErrUserNotFound:
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+	default:
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+	}
+}
@@ -0,0 +1,262 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/service"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+type userRoutes struct {
+	userService    service.User
+	accountService service.Account
+	validator      *validator.Validate
+}
+
+func newUserRoutes(g *gin.RouterGroup, userService service.User, accountService service.Account, validator *validator.Validate) {
+	r := &userRoutes{
+		userService:    userService,
+		accountService: accountService,
+		validator:      validator,
+	}
+
+	g.GET("/me", r.getProfile)
+	g.PATCH("/me", r.updateProfile)
+	g.POST("/me/password", r.changePassword)
+	g.DELETE("/me", r.deleteAccount)
+}
+
+type userProfileResponse struct {
+	ID            int           `json:"id"`
+	Username      string        `json:"username"`
+	Email         string        `json:"email"`
+	EmailVerified bool          `json:"email_verified"`
+	Balance       float64       `json:"balance"`
+	Roles         []entity.Role `json:"roles"`
+}
+
+func newUserProfileResponse(user entity.User) userProfileResponse {
+	return userProfileResponse{
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified,
+		Balance:       user.Balance,
+		Roles:         user.Roles,
+	}
+}
+
+// getProfile возвращает профиль текущего пользователя
+// @Summary Get profile
+// @Description Retrieve the profile of the authenticated user
+// @Tags users
+// @Produce json
+// @Success 200 {object} userProfileResponse
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 404 {object} ErrorResonse "User not found"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /api/v1/users/me [get]
+func (r *userRoutes) getProfile(c *gin.Context) {
+	userId, ok := getUserId(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	user, err := r.userService.GetProfile(c.Request.Context(), userId)
+	if err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserProfileResponse(user))
+}
+
+// updateProfileInput представляет собой модель данных для изменения профиля.
+// Незаданные поля не меняются.
+type updateProfileInput struct {
+	Username *string `json:"username" validate:"omitempty,min=1"`
+	Email    *string `json:"email" validate:"omitempty,email"`
+}
+
+// updateProfile изменяет имя и адрес почты текущего пользователя
+// @Summary Update profile
+// @Description Change the username and/or email of the authenticated user. A new email must be verified again, a verification link is sent to it
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param input body updateProfileInput true "Profile fields to change"
+// @Success 200 {object} userProfileResponse
+// @Failure 400 {object} ErrorResonse "Invalid request body or validation error"
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 404 {object} ErrorResonse "User not found"
+// @Failure 409 {object} ErrorResonse "Username or email is already taken"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /api/v1/users/me [patch]
+func (r *userRoutes) updateProfile(c *gin.Context) {
+	userId, ok := getUserId(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input updateProfileInput
+
+	if err := c.ShouldBindBodyWithJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := r.validator.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := r.userService.UpdateProfile(c.Request.Context(), types.UserUpdateProfileInput{
+		UserID:   userId,
+		Username: input.Username,
+		Email:    input.Email,
+	})
+	if err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	// Как и при регистрации, профиль не откатывается, если письмо не ушло.
+	if input.Email != nil && !user.EmailVerified {
+		_ = r.accountService.SendVerificationEmail(c.Request.Context(), user.ID)
+	}
+
+	c.JSON(http.StatusOK, newUserProfileResponse(user))
+}
+
+// changePasswordInput представляет собой модель данных для смены пароля.
+type changePasswordInput struct {
+	OldPassword string `json:"old_password" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
+// changePassword меняет пароль текущего пользователя
+// @Summary Change password
+// @Description Change the password of the authenticated user. The current password is required. All sessions are revoked, so the user has to sign in again
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param input body changePasswordInput true "Current and new password"
+// @Success 200 {object} map[string]interface{} "Success message"
+// @Failure 400 {object} ErrorResonse "Invalid request body, validation error or wrong current password"
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 404 {object} ErrorResonse "User not found"
+// @Failure 422 {object} PasswordPolicyResponse "Password does not meet the policy"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /api/v1/users/me/password [post]
+func (r *userRoutes) changePassword(c *gin.Context) {
+	userId, ok := getUserId(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input changePasswordInput
+
+	if err := c.ShouldBindBodyWithJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := r.validator.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err := r.userService.ChangePassword(c.Request.Context(), types.UserChangePasswordInput{
+		UserID:      userId,
+		OldPassword: input.OldPassword,
+		NewPassword: input.NewPassword,
+	})
+	if err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "succes",
+	})
+}
+
+// deleteAccountInput представляет собой модель данных для удаления аккаунта.
+type deleteAccountInput struct {
+	Password string `json:"password" validate:"required"`
+}
+
+// deleteAccount удаляет аккаунт текущего пользователя
+// @Summary Delete account
+// @Description Delete the account of the authenticated user after confirming the password. Personal data is erased, purchase history is kept
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param input body deleteAccountInput true "Current password"
+// @Success 200 {object} map[string]interface{} "Success message"
+// @Failure 400 {object} ErrorResonse "Invalid request body, validation error or wrong password"
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 404 {object} ErrorResonse "User not found"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /api/v1/users/me [delete]
+func (r *userRoutes) deleteAccount(c *gin.Context) {
+	userId, ok := getUserId(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input deleteAccountInput
+
+	if err := c.ShouldBindBodyWithJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := r.validator.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err := r.userService.DeleteAccount(c.Request.Context(), types.UserDeleteAccountInput{
+		UserID:   userId,
+		Password: input.Password,
+	})
+	if err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "succes",
+	})
+}
+
+func (r *userRoutes) handleError(c *gin.Context, err error) {
+	if newPasswordPolicyResponse(c, err) {
+		return
+	}
+
+	switch err {
+	case serviceerrs.ErrInvalidPassword:
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+	case serviceerrs.ErrUsernameTaken, serviceerrs.ErrEmailTaken:
+		newErrorResponse(c, http.StatusConflict, err.Error())
+	case serviceerrs.ErrUserNotFound:
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+	default:
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+	}
+}
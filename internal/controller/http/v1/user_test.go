@@ -0,0 +1,135 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/mocks/servicemocks"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+func TestUserRoutes_UpdateProfile(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		input types.UserUpdateProfileInput
+	}
+
+	type MockBehaviour func(m *servicemocks.MockUser, am *servicemocks.MockAccount, args args)
+
+	newEmail := "new@example.com"
+	newUsername := "renamed"
+
+	testCases := []struct {
+		name            string
+		args            args
+		inputBody       string
+		mockBehaviour   MockBehaviour
+		wantStatusCode  int
+		wantRequestBody string
+	}{
+		{
+			name: "OK: new email is sent a verification link",
+			args: args{
+				ctx:   context.Background(),
+				input: types.UserUpdateProfileInput{UserID: 1, Email: &newEmail},
+			},
+			inputBody: `{"email":"new@example.com"}`,
+			mockBehaviour: func(m *servicemocks.MockUser, am *servicemocks.MockAccount, args args) {
+				m.EXPECT().UpdateProfile(args.ctx, args.input).Return(entity.User{
+					ID:       1,
+					Username: "test",
+					Email:    "new@example.com",
+					Roles:    []entity.Role{entity.RoleBuyer},
+				}, nil)
+				am.EXPECT().SendVerificationEmail(args.ctx, 1).Return(nil)
+			},
+			wantStatusCode:  200,
+			wantRequestBody: `{"id":1,"username":"test","email":"new@example.com","email_verified":false,"balance":0,"roles":["buyer"]}`,
+		},
+		{
+			name: "OK: username only",
+			args: args{
+				ctx:   context.Background(),
+				input: types.UserUpdateProfileInput{UserID: 1, Username: &newUsername},
+			},
+			inputBody: `{"username":"renamed"}`,
+			mockBehaviour: func(m *servicemocks.MockUser, am *servicemocks.MockAccount, args args) {
+				m.EXPECT().UpdateProfile(args.ctx, args.input).Return(entity.User{
+					ID:            1,
+					Username:      "renamed",
+					Email:         "test@example.com",
+					EmailVerified: true,
+				}, nil)
+			},
+			wantStatusCode:  200,
+			wantRequestBody: `{"id":1,"username":"renamed","email":"test@example.com","email_verified":true,"balance":0,"roles":null}`,
+		},
+		{
+			name:            "Invalid email",
+			args:            args{},
+			inputBody:       `{"email":"not-an-email"}`,
+			mockBehaviour:   func(m *servicemocks.MockUser, am *servicemocks.MockAccount, args args) {},
+			wantStatusCode:  400,
+			wantRequestBody: `{"error":"Key: 'updateProfileInput.Email' Error:Field validation for 'Email' failed on the 'email' tag"}`,
+		},
+		{
+			name: "Username taken",
+			args: args{
+				ctx:   context.Background(),
+				input: types.UserUpdateProfileInput{UserID: 1, Username: &newUsername},
+			},
+			inputBody: `{"username":"renamed"}`,
+			mockBehaviour: func(m *servicemocks.MockUser, am *servicemocks.MockAccount, args args) {
+				m.EXPECT().UpdateProfile(args.ctx, args.input).Return(entity.User{}, serviceerrs.ErrUsernameTaken)
+			},
+			wantStatusCode:  409,
+			wantRequestBody: `{"error":"username is already taken"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Init service mock
+			user := servicemocks.NewMockUser(ctrl)
+			account := servicemocks.NewMockAccount(ctrl)
+			tc.mockBehaviour(user, account, tc.args)
+
+			// Create router
+			router := gin.Default()
+			userRoutes := &userRoutes{
+				userService:    user,
+				accountService: account,
+				validator:      validator.New(),
+			}
+			router.PATCH("/api/v1/users/me", func(c *gin.Context) {
+				c.Set(userIdCtx, 1)
+			}, userRoutes.updateProfile)
+
+			// Create request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/users/me", bytes.NewBufferString(tc.inputBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			// Execute request
+			router.ServeHTTP(w, req)
+
+			// Check response
+			assert.Equal(t, tc.wantStatusCode, w.Code)
+			assert.JSONEq(t, tc.wantRequestBody, w.Body.String())
+		})
+	}
+}
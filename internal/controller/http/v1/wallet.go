@@ -0,0 +1,177 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/cripplemymind9/go-market/internal/service"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+type walletRoutes struct {
+	walletService service.Wallet
+	validator     *validator.Validate
+}
+
+func newWalletRoutes(g *gin.RouterGroup, walletService service.Wallet, validator *validator.Validate) {
+	r := &walletRoutes{
+		walletService: walletService,
+		validator:     validator,
+	}
+
+	g.GET("/balance", r.getBalance)
+	g.POST("/deposit", r.deposit)
+	g.POST("/withdraw", r.withdraw)
+}
+
+type walletBalanceResponse struct {
+	Balance float64 `json:"balance"`
+}
+
+// getBalance возвращает баланс кошелька текущего пользователя
+// @Summary Get wallet balance
+// @Description Retrieve the wallet balance of the authenticated user
+// @Tags wallet
+// @Produce json
+// @Success 200 {object} walletBalanceResponse
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 404 {object} ErrorResonse "User not found"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Router /api/v1/wallet/balance [get]
+func (r *walletRoutes) getBalance(c *gin.Context) {
+	userId, ok := getUserId(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	balance, err := r.walletService.GetBalance(c.Request.Context(), userId)
+	if err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, walletBalanceResponse{
+		Balance: balance,
+	})
+}
+
+// walletAmountInput представляет собой модель данных для пополнения и списания средств.
+type walletAmountInput struct {
+	Amount float64 `json:"amount" validate:"required,gt=0"`
+}
+
+// deposit пополняет кошелек текущего пользователя
+// @Summary Deposit to wallet
+// @Description Top up the wallet of the authenticated user
+// @Tags wallet
+// @Accept json
+// @Produce json
+// @Param input body walletAmountInput true "Deposit amount"
+// @Success 200 {object} walletBalanceResponse
+// @Failure 400 {object} ErrorResonse "Invalid request body or validation error"
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 404 {object} ErrorResonse "User not found"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Router /api/v1/wallet/deposit [post]
+func (r *walletRoutes) deposit(c *gin.Context) {
+	userId, ok := getUserId(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input walletAmountInput
+
+	if err := c.ShouldBindBodyWithJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := r.validator.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	balance, err := r.walletService.Deposit(c.Request.Context(), types.WalletDepositInput{
+		UserID: userId,
+		Amount: input.Amount,
+	})
+	if err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, walletBalanceResponse{
+		Balance: balance,
+	})
+}
+
+// withdraw списывает средства с кошелька текущего пользователя
+// @Summary Withdraw from wallet
+// @Description Withdraw funds from the wallet of the authenticated user
+// @Tags wallet
+// @Accept json
+// @Produce json
+// @Param input body walletAmountInput true "Withdraw amount"
+// @Success 200 {object} walletBalanceResponse
+// @Failure 400 {object} ErrorResonse "Invalid request body or validation error"
+// @Failure 401 {object} ErrorResonse "Unauthorized"
+// @Failure 402 {object} ErrorResonse "Not enough balance"
+// @Failure 404 {object} ErrorResonse "User not found"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Router /api/v1/wallet/withdraw [post]
+func (r *walletRoutes) withdraw(c *gin.Context) {
+	userId, ok := getUserId(c)
+	if !ok {
+		newErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input walletAmountInput
+
+	if err := c.ShouldBindBodyWithJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := r.validator.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	balance, err := r.walletService.Withdraw(c.Request.Context(), types.WalletWithdrawInput{
+		UserID: userId,
+		Amount: input.Amount,
+	})
+	if err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, walletBalanceResponse{
+		Balance: balance,
+	})
+}
+
+func (r *walletRoutes) handleError(c *gin.Context, err error) {
+	switch err {
+	case serviceerrs.ErrInvalidAmount:
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+	case serviceerrs.ErrNotEnoughBalance:
+		newErrorResponse(c, http.StatusPaymentRequired, err.Error())
+	case serviceerrs.ErrUserNotFound:
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+	default:
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+	}
+}
@@ -0,0 +1,105 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cripplemymind9/go-market/internal/mocks/servicemocks"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+func TestWalletRoutes_Withdraw(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		input types.WalletWithdrawInput
+	}
+
+	type MockBehaviour func(m *servicemocks.MockWallet, args args)
+
+	testCases := []struct {
+		name            string
+		args            args
+		inputBody       string
+		mockBehaviour   MockBehaviour
+		wantStatusCode  int
+		wantRequestBody string
+	}{
+		{
+			name: "OK",
+			args: args{
+				ctx:   context.Background(),
+				input: types.WalletWithdrawInput{UserID: 1, Amount: 25},
+			},
+			inputBody: `{"amount":25}`,
+			mockBehaviour: func(m *servicemocks.MockWallet, args args) {
+				m.EXPECT().Withdraw(args.ctx, args.input).Return(75.0, nil)
+			},
+			wantStatusCode:  200,
+			wantRequestBody: `{"balance":75}`,
+		},
+		{
+			name:            "Invalid amount",
+			args:            args{},
+			inputBody:       `{"amount":-1}`,
+			mockBehaviour:   func(m *servicemocks.MockWallet, args args) {},
+			wantStatusCode:  400,
+			wantRequestBody: `{"error":"Key: 'walletAmountInput.Amount' Error:Field validation for 'Amount' failed on the 'gt' tag"}`,
+		},
+		{
+			name: "Not enough balance",
+			args: args{
+				ctx:   context.Background(),
+				input: types.WalletWithdrawInput{UserID: 1, Amount: 500},
+			},
+			inputBody: `{"amount":500}`,
+			mockBehaviour: func(m *servicemocks.MockWallet, args args) {
+				m.EXPECT().Withdraw(args.ctx, args.input).Return(0.0, serviceerrs.ErrNotEnoughBalance)
+			},
+			wantStatusCode:  402,
+			wantRequestBody: `{"error":"not enough balance"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Init service mock
+			wallet := servicemocks.NewMockWallet(ctrl)
+			tc.mockBehaviour(wallet, tc.args)
+
+			// Create router
+			router := gin.Default()
+			walletRoutes := &walletRoutes{
+				walletService: wallet,
+				validator:     validator.New(),
+			}
+			router.POST("/api/v1/wallet/withdraw", func(c *gin.Context) {
+				c.Set(userIdCtx, 1)
+			}, walletRoutes.withdraw)
+
+			// Create request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet/withdraw", bytes.NewBufferString(tc.inputBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			// Execute request
+			router.ServeHTTP(w, req)
+
+			// Check response
+			assert.Equal(t, tc.wantStatusCode, w.Code)
+			assert.JSONEq(t, tc.wantRequestBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/service"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+type warehouseRoutes struct {
+	warehouseService service.Warehouse
+	validator        *validator.Validate
+}
+
+func newWarehouseRoutes(g *gin.RouterGroup, warehouseService service.Warehouse, validator *validator.Validate) {
+	r := &warehouseRoutes{
+		warehouseService: warehouseService,
+		validator:        validator,
+	}
+
+	g.GET("", r.getWarehouses)
+
+	manage := g.Group("", RequireRole(entity.RoleAdmin), RequireSession())
+	manage.POST("", r.createWarehouse)
+	manage.PUT("/:id", r.updateWarehouse)
+}
+
+// geoPointInput представляет собой координаты в градусах.
+type geoPointInput struct {
+	Latitude  *float64 `json:"latitude" validate:"required,min=-90,max=90"`
+	Longitude *float64 `json:"longitude" validate:"required,min=-180,max=180"`
+}
+
+func (p *geoPointInput) toEntity() *entity.GeoPoint {
+	if p == nil {
+		return nil
+	}
+	return &entity.GeoPoint{Latitude: *p.Latitude, Longitude: *p.Longitude}
+}
+
+type geoPointResponse struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type warehouseResponse struct {
+	ID        int               `json:"id"`
+	Name      string            `json:"name"`
+	Priority  int               `json:"priority"`
+	Location  *geoPointResponse `json:"location,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// warehouseInput представляет собой модель данных склада. Чем больше
+// priority, тем раньше склад выбирается при отгрузке.
+type warehouseInput struct {
+	Name     string         `json:"name" validate:"required,max=100"`
+	Priority int            `json:"priority"`
+	Location *geoPointInput `json:"location"`
+}
+
+// getWarehouses возвращает склады
+// @Summary List warehouses
+// @Description Get all warehouses, highest priority first. The first warehouse receives stock for which no warehouse is given
+// @Tags warehouses
+// @Produce json
+// @Success 200 {array} warehouseResponse
+// @Failure 403 {object} ErrorResonse "Seller or admin role required"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Security APIKeyHeader
+// @Router /api/v1/warehouses [get]
+func (r *warehouseRoutes) getWarehouses(c *gin.Context) {
+	warehouses, err := r.warehouseService.GetWarehouses(c.Request.Context())
+	if err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	response := make([]warehouseResponse, 0, len(warehouses))
+	for _, warehouse := range warehouses {
+		item := warehouseResponse{
+			ID:        warehouse.ID,
+			Name:      warehouse.Name,
+			Priority:  warehouse.Priority,
+			CreatedAt: warehouse.CreatedAt,
+		}
+		if warehouse.Location != nil {
+			item.Location = &geoPointResponse{
+				Latitude:  warehouse.Location.Latitude,
+				Longitude: warehouse.Location.Longitude,
+			}
+		}
+		response = append(response, item)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// createWarehouse добавляет склад
+// @Summary Create warehouse
+// @Description Add a warehouse with a unique name, allocation priority and optional location used by the nearest allocation strategy
+// @Tags warehouses
+// @Accept json
+// @Produce json
+// @Param input body warehouseInput true "Warehouse"
+// @Success 201 {object} v1.warehouseRoutes.createWarehouse.response
+// @Failure 400 {object} ErrorResonse "Invalid request body or validation error"
+// @Failure 403 {object} ErrorResonse "Admin role required"
+// @Failure 409 {object} ErrorResonse "Warehouse with this name already exists"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /api/v1/warehouses [post]
+func (r *warehouseRoutes) createWarehouse(c *gin.Context) {
+	var input warehouseInput
+
+	if err := c.ShouldBindBodyWithJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := r.validator.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := r.warehouseService.CreateWarehouse(c.Request.Context(), types.WarehouseCreateInput{
+		Name:     input.Name,
+		Priority: input.Priority,
+		Location: input.Location.toEntity(),
+	})
+	if err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	type response struct {
+		Id int `json:"id"`
+	}
+
+	c.JSON(http.StatusCreated, response{
+		Id: id,
+	})
+}
+
+// updateWarehouse изменяет склад
+// @Summary Update warehouse
+// @Description Replace the name, priority and location of a warehouse. Stock in the warehouse is not affected
+// @Tags warehouses
+// @Accept json
+// @Produce json
+// @Param id path int true "Warehouse ID"
+// @Param input body warehouseInput true "Warehouse"
+// @Success 200 {object} map[string]interface{} "Success message"
+// @Failure 400 {object} ErrorResonse "Invalid ID, request body or validation error"
+// @Failure 403 {object} ErrorResonse "Admin role required"
+// @Failure 404 {object} ErrorResonse "Warehouse not found"
+// @Failure 409 {object} ErrorResonse "Warehouse with this name already exists"
+// @Failure 500 {object} ErrorResonse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /api/v1/warehouses/{id} [put]
+func (r *warehouseRoutes) updateWarehouse(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var input warehouseInput
+
+	if err := c.ShouldBindBodyWithJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := r.validator.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = r.warehouseService.UpdateWarehouse(c.Request.Context(), types.WarehouseUpdateInput{
+		ID:       id,
+		Name:     input.Name,
+		Priority: input.Priority,
+		Location: input.Location.toEntity(),
+	})
+	if err != nil {
+		r.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "succes",
+	})
+}
+
+func (r *warehouseRoutes) handleError(c *gin.Context, err error) {
+	switch err {
+	case serviceerrs.ErrWarehouseNotFound:
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+	case serviceerrs.ErrWarehouseAlreadyExists:
+		newErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		newErrorResponse(c, http.StatusInternalServerError, "internal server error")
+	}
+}
@@ -0,0 +1,87 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/mocks/servicemocks"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+func TestWarehouseRoutes_CreateWarehouse(t *testing.T) {
+	type MockBehaviour func(m *servicemocks.MockWarehouse)
+
+	testCases := []struct {
+		name            string
+		body            string
+		mockBehaviour   MockBehaviour
+		wantStatusCode  int
+		wantRequestBody string
+	}{
+		{
+			name: "OK",
+			body: `{"name":"equator","priority":5,"location":{"latitude":0,"longitude":0}}`,
+			mockBehaviour: func(m *servicemocks.MockWarehouse) {
+				m.EXPECT().CreateWarehouse(context.Background(), types.WarehouseCreateInput{
+					Name: "equator", Priority: 5, Location: &entity.GeoPoint{},
+				}).Return(2, nil)
+			},
+			wantStatusCode:  201,
+			wantRequestBody: `{"id":2}`,
+		},
+		{
+			name: "Without location",
+			body: `{"name":"remote"}`,
+			mockBehaviour: func(m *servicemocks.MockWarehouse) {
+				m.EXPECT().CreateWarehouse(context.Background(), types.WarehouseCreateInput{Name: "remote"}).Return(3, nil)
+			},
+			wantStatusCode:  201,
+			wantRequestBody: `{"id":3}`,
+		},
+		{
+			name:            "Latitude out of range",
+			body:            `{"name":"north","location":{"latitude":91,"longitude":0}}`,
+			mockBehaviour:   func(m *servicemocks.MockWarehouse) {},
+			wantStatusCode:  400,
+			wantRequestBody: `{"error":"Key: 'warehouseInput.Location.Latitude' Error:Field validation for 'Latitude' failed on the 'max' tag"}`,
+		},
+		{
+			name: "Name taken",
+			body: `{"name":"main"}`,
+			mockBehaviour: func(m *servicemocks.MockWarehouse) {
+				m.EXPECT().CreateWarehouse(context.Background(), gomock.Any()).Return(0, serviceerrs.ErrWarehouseAlreadyExists)
+			},
+			wantStatusCode:  409,
+			wantRequestBody: `{"error":"warehouse with this name already exists"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			warehouses := servicemocks.NewMockWarehouse(ctrl)
+			tc.mockBehaviour(warehouses)
+
+			router := gin.Default()
+			router.POST("/api/v1/warehouses", (&warehouseRoutes{warehouseService: warehouses, validator: validator.New()}).createWarehouse)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/warehouses", bytes.NewBufferString(tc.body)))
+
+			assert.Equal(t, tc.wantStatusCode, w.Code)
+			assert.JSONEq(t, tc.wantRequestBody, w.Body.String())
+		})
+	}
+}
@@ -2,25 +2,385 @@ package entity
 
 import "time"
 
+type Role string
+
+const (
+	RoleBuyer  Role = "buyer"
+	RoleSeller Role = "seller"
+	RoleAdmin  Role = "admin"
+)
+
+func (r Role) Valid() bool {
+	switch r {
+	case RoleBuyer, RoleSeller, RoleAdmin:
+		return true
+	}
+	return false
+}
+
 type User struct {
-	ID       int
-	Username string
-	Password string
-	Email    string
+	ID            int
+	Username      string
+	Password      string
+	Email         string
+	EmailVerified bool
+	Balance       float64
+	Roles         []Role
 }
 
+// Product - товар. Quantity - общий остаток на всех складах, из него Reserved
+// единиц удерживается под неподтвержденные покупки.
 type Product struct {
 	ID          int
 	Name        string
 	Description string
 	Price       float64
 	Quantity    int
+	Reserved    int
+	SellerID    int
 }
 
-type Purchase struct {
+// Available возвращает, сколько единиц товара можно купить прямо сейчас.
+func (p Product) Available() int {
+	if p.Reserved >= p.Quantity {
+		return 0
+	}
+	return p.Quantity - p.Reserved
+}
+
+type ReservationStatus string
+
+const (
+	ReservationStatusActive    ReservationStatus = "active"
+	ReservationStatusConfirmed ReservationStatus = "confirmed"
+	ReservationStatusReleased  ReservationStatus = "released"
+	ReservationStatusExpired   ReservationStatus = "expired"
+)
+
+// Reservation - товары, удерживаемые за покупателем до ExpiresAt, пока он
+// завершает оплату. Подтвержденная бронь становится заказом OrderID.
+type Reservation struct {
 	ID        int
 	UserID    int
+	Status    ReservationStatus
+	Items     []ReservationItem
+	OrderID   int
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+type ReservationItem struct {
 	ProductID int
 	Quantity  int
-	Timestamp time.Time
+}
+
+// GeoPoint - координаты в градусах.
+type GeoPoint struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Warehouse - склад. Чем больше Priority, тем раньше склад выбирается при
+// отгрузке; склад с наибольшим приоритетом принимает товар, для которого
+// склад не указан. Location может быть не задан.
+type Warehouse struct {
+	ID        int
+	Name      string
+	Priority  int
+	Location  *GeoPoint
+	CreatedAt time.Time
+}
+
+// WarehouseStock - остаток товара на одном складе.
+type WarehouseStock struct {
+	WarehouseID   int
+	WarehouseName string
+	ProductID     int
+	Quantity      int
+}
+
+type InventoryMovementKind string
+
+const (
+	InventoryMovementRestock    InventoryMovementKind = "restock"
+	InventoryMovementSale       InventoryMovementKind = "sale"
+	InventoryMovementRefund     InventoryMovementKind = "refund"
+	InventoryMovementAdjustment InventoryMovementKind = "adjustment"
+)
+
+// InventoryMovement - одно изменение остатка товара на складе WarehouseID на
+// Delta единиц. QuantityAfter - общий остаток товара сразу после движения.
+// OrderID задан для продаж и возвратов, ActorID - если движение вызвал
+// пользователь.
+type InventoryMovement struct {
+	ID            int
+	ProductID     int
+	WarehouseID   int
+	Kind          InventoryMovementKind
+	Delta         int
+	QuantityAfter int
+	ActorID       int
+	Reason        string
+	OrderID       int
+	CreatedAt     time.Time
+}
+
+// StockAlert - предупреждение о том, что остаток товара опустился до порога
+// дозаказа. Одно пересечение порога дает одно предупреждение; ResolvedAt
+// заполняется, когда остаток снова поднимается выше порога. NotifiedAt -
+// когда предупреждение доставлено, Attempts - сколько раз его пытались
+// отправить. Имя товара и контакты продавца заполняются при отправке.
+type StockAlert struct {
+	ID          int
+	ProductID   int
+	ProductName string
+	SellerID    int
+	SellerEmail string
+	Threshold   int
+	Quantity    int
+	Attempts    int
+	// DeliveredChannels - способы доставки, которыми предупреждение уже
+	// отправлено.
+	DeliveredChannels []string
+	CreatedAt         time.Time
+	NotifiedAt        *time.Time
+	ResolvedAt        *time.Time
+}
+
+type OrderStatus string
+
+const (
+	OrderStatusPending   OrderStatus = "pending"
+	OrderStatusPaid      OrderStatus = "paid"
+	OrderStatusShipped   OrderStatus = "shipped"
+	OrderStatusDelivered OrderStatus = "delivered"
+	OrderStatusCancelled OrderStatus = "cancelled"
+	OrderStatusRefunded  OrderStatus = "refunded"
+)
+
+// Order - заказ покупателя из одной или нескольких строк. Итог и цены строк
+// зафиксированы на момент покупки и не меняются вслед за ценами товаров.
+// ShipTo - адрес доставки, переданный при покупке: по нему выбираются
+// ближайшие склады. В заказе он не сохраняется.
+type Order struct {
+	ID        int
+	UserID    int
+	Status    OrderStatus
+	Items     []OrderItem
+	Total     float64
+	ShipTo    *GeoPoint
+	CreatedAt time.Time
+}
+
+// OrderStatusChange - запись истории статусов заказа. У записи об оформлении
+// заказа From пустой.
+type OrderStatusChange struct {
+	ID        int
+	OrderID   int
+	From      OrderStatus
+	To        OrderStatus
+	ActorID   int
+	Reason    string
+	CreatedAt time.Time
+}
+
+// OrderItem - строка заказа со снимком названия, продавца и цены товара.
+type OrderItem struct {
+	ID          int
+	OrderID     int
+	ProductID   int
+	ProductName string
+	SellerID    int
+	UnitPrice   float64
+	Quantity    int
+}
+
+// Refund - возврат части заказа или всего заказа. Amount - сумма, вернувшаяся
+// покупателю.
+type Refund struct {
+	ID        int
+	OrderID   int
+	ActorID   int
+	Reason    string
+	Items     []RefundItem
+	Amount    float64
+	CreatedAt time.Time
+}
+
+// RefundItem - возвращенные единицы одной строки заказа.
+type RefundItem struct {
+	OrderItemID int
+	ProductID   int
+	Quantity    int
+	Amount      float64
+}
+
+// ProductSales - продажи одного товара продавца.
+type ProductSales struct {
+	ProductID   int
+	ProductName string
+	UnitsSold   int
+	Revenue     float64
+}
+
+// SellerSalesReport - сводка продаж продавца по всем его товарам.
+type SellerSalesReport struct {
+	Products  []ProductSales
+	UnitsSold int
+	Revenue   float64
+}
+
+// StockWarning - предупреждение о позиции корзины, которую нельзя купить
+// в нужном количестве.
+type StockWarning string
+
+const (
+	StockWarningOutOfStock     StockWarning = "out_of_stock"
+	StockWarningNotEnoughStock StockWarning = "not_enough_stock"
+)
+
+// CartItem - позиция корзины. Цена и остаток - текущие значения товара, а
+// не зафиксированные при добавлении.
+type CartItem struct {
+	ProductID   int
+	ProductName string
+	Price       float64
+	Quantity    int
+	Available   int
+	Subtotal    float64
+	AddedAt     time.Time
+	Warning     StockWarning
+}
+
+// Cart - корзина пользователя с итоговой суммой по текущим ценам.
+type Cart struct {
+	UserID int
+	Items  []CartItem
+	Total  float64
+}
+
+// Session - серверная сессия, выданная при входе. Хранит хэш текущего
+// refresh-токена: при каждом обновлении он заменяется новым, а прежний
+// остается в PreviousRefreshTokenHash. Handle - непрозрачный идентификатор
+// сессии в токенах доступа.
+type Session struct {
+	ID                       string
+	Handle                   string
+	UserID                   int
+	RefreshTokenHash         string
+	PreviousRefreshTokenHash string
+	ExpiresAt                time.Time
+	CreatedAt                time.Time
+	RevokedAt                *time.Time
+}
+
+// LoginAttempt - счетчик неудачных входов подряд по ключу: имени
+// пользователя или IP-адресу.
+type LoginAttempt struct {
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+}
+
+// IdempotencyRecord - запрос, выполненный с заголовком Idempotency-Key, и его
+// ответ. Пока запрос выполняется, StatusCode равен 0.
+type IdempotencyRecord struct {
+	UserID       int
+	Scope        string
+	Key          string
+	Fingerprint  string
+	StatusCode   int
+	ResponseBody []byte
+	CreatedAt    time.Time
+}
+
+type TokenPurpose string
+
+const (
+	TokenPurposeVerifyEmail   TokenPurpose = "verify_email"
+	TokenPurposeResetPassword TokenPurpose = "reset_password"
+)
+
+// UserToken - одноразовый токен из письма. Хранится только хэш токена.
+type UserToken struct {
+	ID        int
+	UserID    int
+	Purpose   TokenPurpose
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+// UserTOTP - секрет второго фактора пользователя. Пока подключение не
+// подтверждено кодом, второй фактор при входе не запрашивается.
+type UserTOTP struct {
+	UserID       int
+	Secret       string
+	Confirmed    bool
+	LastUsedStep int64
+}
+
+// RecoveryCode - неиспользованный код восстановления. Хранится только хэш.
+type RecoveryCode struct {
+	ID       int
+	UserID   int
+	CodeHash string
+}
+
+// APIKeyScope - право, выдаваемое API-ключу. Ключ без нужного права
+// получает 403, даже если у владельца есть подходящая роль.
+type APIKeyScope string
+
+const (
+	ScopeProductsRead   APIKeyScope = "products:read"
+	ScopeProductsWrite  APIKeyScope = "products:write"
+	ScopePurchasesRead  APIKeyScope = "purchases:read"
+	ScopePurchasesWrite APIKeyScope = "purchases:write"
+	ScopeWalletRead     APIKeyScope = "wallet:read"
+	ScopeWalletWrite    APIKeyScope = "wallet:write"
+	ScopeSellerRead     APIKeyScope = "seller:read"
+)
+
+func (s APIKeyScope) Valid() bool {
+	switch s {
+	case ScopeProductsRead, ScopeProductsWrite,
+		ScopePurchasesRead, ScopePurchasesWrite,
+		ScopeWalletRead, ScopeWalletWrite,
+		ScopeSellerRead:
+		return true
+	}
+	return false
+}
+
+// APIKey - ключ для межсервисного доступа. Хранится только хэш секрета,
+// а по открытому префиксу ключ находится без перебора.
+type APIKey struct {
+	ID         int
+	UserID     int
+	Name       string
+	Prefix     string
+	SecretHash string
+	Scopes     []APIKeyScope
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+	RevokedAt  *time.Time
+}
+
+// ExternalIdentity связывает учетную запись у внешнего провайдера входа
+// (subject в терминах OIDC) с пользователем.
+type ExternalIdentity struct {
+	Provider string
+	Subject  string
+	UserID   int
+	Email    string
+}
+
+// OIDCLoginState - начатый вход через внешнего провайдера. Хранится до
+// возврата пользователя от провайдера, state хранится в виде хэша.
+type OIDCLoginState struct {
+	StateHash    string
+	Nonce        string
+	CodeVerifier string
+	ExpiresAt    time.Time
 }
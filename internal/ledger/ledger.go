@@ -0,0 +1,126 @@
+// Package ledger описывает двойную запись денежных движений: счета, проводки
+// и сверку пересчитанных по проводкам остатков с балансами кошельков.
+package ledger
+
+import (
+	"errors"
+	"math"
+)
+
+var (
+	ErrUnbalancedEntry = errors.New("ledger entry debits and credits do not sum to zero")
+	ErrEmptyEntry      = errors.New("ledger entry must have at least two postings")
+	ErrZeroPosting     = errors.New("ledger posting amount must not be zero")
+)
+
+type AccountType string
+
+const (
+	AccountUserWallet         AccountType = "user_wallet"
+	AccountMarketplaceRevenue AccountType = "marketplace_revenue"
+	AccountSellerPayout       AccountType = "seller_payout"
+	// AccountExternal - внешний мир: источник пополнений и получатель выводов.
+	AccountExternal AccountType = "external"
+)
+
+type EntryKind string
+
+const (
+	EntryOpeningBalance EntryKind = "opening_balance"
+	EntryDeposit        EntryKind = "deposit"
+	EntryWithdraw       EntryKind = "withdraw"
+	EntryPurchase       EntryKind = "purchase"
+	EntryOrderCancel    EntryKind = "order_cancel"
+	EntryRefund         EntryKind = "refund"
+)
+
+// Account идентифицирует счет по типу и владельцу. Для системных счетов OwnerID равен 0.
+type Account struct {
+	Type    AccountType `json:"type"`
+	OwnerID int         `json:"owner_id"`
+}
+
+func UserWallet(userId int) Account {
+	return Account{Type: AccountUserWallet, OwnerID: userId}
+}
+
+func MarketplaceRevenue() Account {
+	return Account{Type: AccountMarketplaceRevenue}
+}
+
+func SellerPayout(sellerId int) Account {
+	return Account{Type: AccountSellerPayout, OwnerID: sellerId}
+}
+
+func External() Account {
+	return Account{Type: AccountExternal}
+}
+
+// Posting - одна строка проводки. Amount хранится в копейках:
+// положительное значение - дебет, отрицательное - кредит.
+type Posting struct {
+	Account Account
+	Amount  int64
+}
+
+// Entry - журнальная запись. Сумма Amount всех ее строк обязана быть нулевой.
+type Entry struct {
+	Kind        EntryKind
+	ReferenceID int
+	Postings    []Posting
+}
+
+func (e Entry) Validate() error {
+	if len(e.Postings) < 2 {
+		return ErrEmptyEntry
+	}
+
+	var sum int64
+	for _, p := range e.Postings {
+		if p.Amount == 0 {
+			return ErrZeroPosting
+		}
+		sum += p.Amount
+	}
+
+	if sum != 0 {
+		return ErrUnbalancedEntry
+	}
+
+	return nil
+}
+
+// Transfer строит запись о переносе amount копеек со счета from на счет to.
+func Transfer(kind EntryKind, referenceId int, from, to Account, amount int64) Entry {
+	return Entry{
+		Kind:        kind,
+		ReferenceID: referenceId,
+		Postings: []Posting{
+			{Account: from, Amount: amount},
+			{Account: to, Amount: -amount},
+		},
+	}
+}
+
+// Reverse строит запись kind, отменяющую e: те же счета с противоположными суммами.
+func (e Entry) Reverse(kind EntryKind, referenceId int) Entry {
+	reversed := Entry{
+		Kind:        kind,
+		ReferenceID: referenceId,
+		Postings:    make([]Posting, 0, len(e.Postings)),
+	}
+	for _, p := range e.Postings {
+		reversed.Postings = append(reversed.Postings, Posting{Account: p.Account, Amount: -p.Amount})
+	}
+	return reversed
+}
+
+// ToMinor переводит денежную сумму в копейки.
+func ToMinor(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// FromMinor переводит копейки в денежную сумму.
+func FromMinor(amount int64) float64 {
+	return float64(amount) / 100
+}
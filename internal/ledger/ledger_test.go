@@ -0,0 +1,149 @@
+package ledger
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestEntry_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		entry   Entry
+		wantErr error
+	}{
+		{
+			name:    "OK",
+			entry:   Transfer(EntryPurchase, 1, UserWallet(1), MarketplaceRevenue(), 1500),
+			wantErr: nil,
+		},
+		{
+			name: "Unbalanced",
+			entry: Entry{
+				Kind: EntryPurchase,
+				Postings: []Posting{
+					{Account: UserWallet(1), Amount: 1500},
+					{Account: MarketplaceRevenue(), Amount: -1000},
+				},
+			},
+			wantErr: ErrUnbalancedEntry,
+		},
+		{
+			name: "Single posting",
+			entry: Entry{
+				Kind:     EntryDeposit,
+				Postings: []Posting{{Account: UserWallet(1), Amount: 100}},
+			},
+			wantErr: ErrEmptyEntry,
+		},
+		{
+			name:    "Zero posting",
+			entry:   Transfer(EntryDeposit, 1, External(), UserWallet(1), 0),
+			wantErr: ErrZeroPosting,
+		},
+		{
+			name: "Split to several accounts",
+			entry: Entry{
+				Kind: EntryPurchase,
+				Postings: []Posting{
+					{Account: UserWallet(1), Amount: 1000},
+					{Account: SellerPayout(2), Amount: -900},
+					{Account: MarketplaceRevenue(), Amount: -100},
+				},
+			},
+			wantErr: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.entry.Validate(); !errors.Is(err, tc.wantErr) {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestEntry_Reverse(t *testing.T) {
+	entry := Entry{
+		Kind:        EntryPurchase,
+		ReferenceID: 7,
+		Postings: []Posting{
+			{Account: UserWallet(1), Amount: 1000},
+			{Account: SellerPayout(2), Amount: -900},
+			{Account: MarketplaceRevenue(), Amount: -100},
+		},
+	}
+
+	want := Entry{
+		Kind:        EntryOrderCancel,
+		ReferenceID: 7,
+		Postings: []Posting{
+			{Account: UserWallet(1), Amount: -1000},
+			{Account: SellerPayout(2), Amount: 900},
+			{Account: MarketplaceRevenue(), Amount: 100},
+		},
+	}
+
+	got := entry.Reverse(EntryOrderCancel, 7)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Reverse() = %+v, want %+v", got, want)
+	}
+	if err := got.Validate(); err != nil {
+		t.Errorf("Reverse().Validate() error = %v", err)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	testCases := []struct {
+		name         string
+		accounts     []AccountBalance
+		stored       map[int]int64
+		wantDrifts   []Drift
+		wantBalanced bool
+	}{
+		{
+			name: "Balanced",
+			accounts: []AccountBalance{
+				{Account: External(), Balance: -10000},
+				{Account: UserWallet(1), Balance: 7000},
+				{Account: MarketplaceRevenue(), Balance: 3000},
+			},
+			stored:       map[int]int64{1: 7000, 2: 0},
+			wantDrifts:   []Drift{},
+			wantBalanced: true,
+		},
+		{
+			name: "Wallet drift",
+			accounts: []AccountBalance{
+				{Account: External(), Balance: -10000},
+				{Account: UserWallet(1), Balance: 7000},
+				{Account: MarketplaceRevenue(), Balance: 3000},
+			},
+			stored: map[int]int64{1: 7500, 2: 100},
+			wantDrifts: []Drift{
+				{UserID: 1, Stored: 75, Computed: 70, Difference: 5},
+				{UserID: 2, Stored: 1, Computed: 0, Difference: 1},
+			},
+			wantBalanced: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			report := Reconcile(tc.accounts, tc.stored)
+
+			if report.TrialBalance != 0 {
+				t.Errorf("Reconcile() trial balance = %d, want 0", report.TrialBalance)
+			}
+
+			if !reflect.DeepEqual(report.Drifts, tc.wantDrifts) {
+				t.Errorf("Reconcile() drifts = %+v, want %+v", report.Drifts, tc.wantDrifts)
+			}
+
+			if report.Balanced != tc.wantBalanced {
+				t.Errorf("Reconcile() balanced = %v, want %v", report.Balanced, tc.wantBalanced)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package ledger
+
+import "sort"
+
+// AccountBalance - остаток счета, пересчитанный по проводкам. Balance -
+// кредитовый остаток в копейках, т.е. сумма проводок с обратным знаком:
+// для кошелька это деньги, которые маркетплейс должен пользователю.
+type AccountBalance struct {
+	Account Account `json:"account"`
+	Balance int64   `json:"balance"`
+}
+
+// Drift - расхождение сохраненного баланса кошелька с пересчитанным по проводкам.
+type Drift struct {
+	UserID     int     `json:"user_id"`
+	Stored     float64 `json:"stored"`
+	Computed   float64 `json:"computed"`
+	Difference float64 `json:"difference"`
+}
+
+type Report struct {
+	Accounts     []AccountBalance `json:"accounts"`
+	Drifts       []Drift          `json:"drifts"`
+	TrialBalance int64            `json:"trial_balance"`
+	Balanced     bool             `json:"balanced"`
+}
+
+// Reconcile сравнивает остатки счетов с сохраненными балансами кошельков
+// (в копейках, по идентификатору пользователя) и собирает отчет о расхождениях.
+func Reconcile(accounts []AccountBalance, storedWallets map[int]int64) Report {
+	report := Report{
+		Accounts: accounts,
+		Drifts:   []Drift{},
+	}
+
+	computedWallets := make(map[int]int64)
+	for _, a := range accounts {
+		report.TrialBalance += a.Balance
+		if a.Account.Type == AccountUserWallet {
+			computedWallets[a.Account.OwnerID] = a.Balance
+		}
+	}
+
+	userIds := make(map[int]struct{}, len(storedWallets)+len(computedWallets))
+	for id := range storedWallets {
+		userIds[id] = struct{}{}
+	}
+	for id := range computedWallets {
+		userIds[id] = struct{}{}
+	}
+
+	for id := range userIds {
+		stored, computed := storedWallets[id], computedWallets[id]
+		if stored == computed {
+			continue
+		}
+		report.Drifts = append(report.Drifts, Drift{
+			UserID:     id,
+			Stored:     FromMinor(stored),
+			Computed:   FromMinor(computed),
+			Difference: FromMinor(stored - computed),
+		})
+	}
+
+	sort.Slice(report.Drifts, func(i, j int) bool {
+		return report.Drifts[i].UserID < report.Drifts[j].UserID
+	})
+
+	report.Balanced = report.TrialBalance == 0 && len(report.Drifts) == 0
+
+	return report
+}
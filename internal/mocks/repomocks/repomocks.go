@@ -7,8 +7,10 @@ package repomocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	entity "github.com/cripplemymind9/go-market/internal/entity"
+	ledger "github.com/cripplemymind9/go-market/internal/ledger"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -35,6 +37,21 @@ func (m *MockUser) EXPECT() *MockUserMockRecorder {
 	return m.recorder
 }
 
+// EmailInUse mocks base method.
+func (m *MockUser) EmailInUse(ctx context.Context, email string, exceptUserId int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EmailInUse", ctx, email, exceptUserId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EmailInUse indicates an expected call of EmailInUse.
+func (mr *MockUserMockRecorder) EmailInUse(ctx, email, exceptUserId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EmailInUse", reflect.TypeOf((*MockUser)(nil).EmailInUse), ctx, email, exceptUserId)
+}
+
 // GetUserProfile mocks base method.
 func (m *MockUser) GetUserProfile(ctx context.Context, userId int) (entity.User, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +67,50 @@ func (mr *MockUserMockRecorder) GetUserProfile(ctx, userId interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserProfile", reflect.TypeOf((*MockUser)(nil).GetUserProfile), ctx, userId)
 }
 
+// GetUserRoles mocks base method.
+func (m *MockUser) GetUserRoles(ctx context.Context, userId int) ([]entity.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserRoles", ctx, userId)
+	ret0, _ := ret[0].([]entity.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserRoles indicates an expected call of GetUserRoles.
+func (mr *MockUserMockRecorder) GetUserRoles(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserRoles", reflect.TypeOf((*MockUser)(nil).GetUserRoles), ctx, userId)
+}
+
+// GetUsersByEmail mocks base method.
+func (m *MockUser) GetUsersByEmail(ctx context.Context, email string) ([]entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersByEmail", ctx, email)
+	ret0, _ := ret[0].([]entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersByEmail indicates an expected call of GetUsersByEmail.
+func (mr *MockUserMockRecorder) GetUsersByEmail(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersByEmail", reflect.TypeOf((*MockUser)(nil).GetUsersByEmail), ctx, email)
+}
+
+// GrantRole mocks base method.
+func (m *MockUser) GrantRole(ctx context.Context, userId int, role entity.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantRole", ctx, userId, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GrantRole indicates an expected call of GrantRole.
+func (mr *MockUserMockRecorder) GrantRole(ctx, userId, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantRole", reflect.TypeOf((*MockUser)(nil).GrantRole), ctx, userId, role)
+}
+
 // LoginUser mocks base method.
 func (m *MockUser) LoginUser(ctx context.Context, username string) (entity.User, error) {
 	m.ctrl.T.Helper()
@@ -65,6 +126,20 @@ func (mr *MockUserMockRecorder) LoginUser(ctx, username interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginUser", reflect.TypeOf((*MockUser)(nil).LoginUser), ctx, username)
 }
 
+// MarkEmailVerified mocks base method.
+func (m *MockUser) MarkEmailVerified(ctx context.Context, userId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkEmailVerified", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkEmailVerified indicates an expected call of MarkEmailVerified.
+func (mr *MockUserMockRecorder) MarkEmailVerified(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkEmailVerified", reflect.TypeOf((*MockUser)(nil).MarkEmailVerified), ctx, userId)
+}
+
 // RegisterUser mocks base method.
 func (m *MockUser) RegisterUser(ctx context.Context, user entity.User) (int, error) {
 	m.ctrl.T.Helper()
@@ -80,166 +155,1586 @@ func (mr *MockUserMockRecorder) RegisterUser(ctx, user interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterUser", reflect.TypeOf((*MockUser)(nil).RegisterUser), ctx, user)
 }
 
-// MockProduct is a mock of Product interface.
-type MockProduct struct {
+// RevokeRole mocks base method.
+func (m *MockUser) RevokeRole(ctx context.Context, userId int, role entity.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRole", ctx, userId, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRole indicates an expected call of RevokeRole.
+func (mr *MockUserMockRecorder) RevokeRole(ctx, userId, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRole", reflect.TypeOf((*MockUser)(nil).RevokeRole), ctx, userId, role)
+}
+
+// SoftDeleteUser mocks base method.
+func (m *MockUser) SoftDeleteUser(ctx context.Context, userId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDeleteUser", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SoftDeleteUser indicates an expected call of SoftDeleteUser.
+func (mr *MockUserMockRecorder) SoftDeleteUser(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDeleteUser", reflect.TypeOf((*MockUser)(nil).SoftDeleteUser), ctx, userId)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockUser) UpdatePassword(ctx context.Context, userId int, passwordHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, userId, passwordHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockUserMockRecorder) UpdatePassword(ctx, userId, passwordHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockUser)(nil).UpdatePassword), ctx, userId, passwordHash)
+}
+
+// UpdateUserProfile mocks base method.
+func (m *MockUser) UpdateUserProfile(ctx context.Context, userId int, username, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserProfile", ctx, userId, username, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserProfile indicates an expected call of UpdateUserProfile.
+func (mr *MockUserMockRecorder) UpdateUserProfile(ctx, userId, username, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserProfile", reflect.TypeOf((*MockUser)(nil).UpdateUserProfile), ctx, userId, username, email)
+}
+
+// MockUserToken is a mock of UserToken interface.
+type MockUserToken struct {
 	ctrl     *gomock.Controller
-	recorder *MockProductMockRecorder
+	recorder *MockUserTokenMockRecorder
 }
 
-// MockProductMockRecorder is the mock recorder for MockProduct.
-type MockProductMockRecorder struct {
-	mock *MockProduct
+// MockUserTokenMockRecorder is the mock recorder for MockUserToken.
+type MockUserTokenMockRecorder struct {
+	mock *MockUserToken
 }
 
-// NewMockProduct creates a new mock instance.
-func NewMockProduct(ctrl *gomock.Controller) *MockProduct {
-	mock := &MockProduct{ctrl: ctrl}
-	mock.recorder = &MockProductMockRecorder{mock}
+// NewMockUserToken creates a new mock instance.
+func NewMockUserToken(ctrl *gomock.Controller) *MockUserToken {
+	mock := &MockUserToken{ctrl: ctrl}
+	mock.recorder = &MockUserTokenMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockProduct) EXPECT() *MockProductMockRecorder {
+func (m *MockUserToken) EXPECT() *MockUserTokenMockRecorder {
 	return m.recorder
 }
 
-// AddProduct mocks base method.
-func (m *MockProduct) AddProduct(ctx context.Context, product entity.Product) (int, error) {
+// ConsumeUserToken mocks base method.
+func (m *MockUserToken) ConsumeUserToken(ctx context.Context, purpose entity.TokenPurpose, tokenHash string) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddProduct", ctx, product)
+	ret := m.ctrl.Call(m, "ConsumeUserToken", ctx, purpose, tokenHash)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// AddProduct indicates an expected call of AddProduct.
-func (mr *MockProductMockRecorder) AddProduct(ctx, product interface{}) *gomock.Call {
+// ConsumeUserToken indicates an expected call of ConsumeUserToken.
+func (mr *MockUserTokenMockRecorder) ConsumeUserToken(ctx, purpose, tokenHash interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProduct", reflect.TypeOf((*MockProduct)(nil).AddProduct), ctx, product)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeUserToken", reflect.TypeOf((*MockUserToken)(nil).ConsumeUserToken), ctx, purpose, tokenHash)
 }
 
-// DeleteProduct mocks base method.
-func (m *MockProduct) DeleteProduct(ctx context.Context, productId int) error {
+// CreateUserToken mocks base method.
+func (m *MockUserToken) CreateUserToken(ctx context.Context, token entity.UserToken) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteProduct", ctx, productId)
+	ret := m.ctrl.Call(m, "CreateUserToken", ctx, token)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteProduct indicates an expected call of DeleteProduct.
-func (mr *MockProductMockRecorder) DeleteProduct(ctx, productId interface{}) *gomock.Call {
+// CreateUserToken indicates an expected call of CreateUserToken.
+func (mr *MockUserTokenMockRecorder) CreateUserToken(ctx, token interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProduct", reflect.TypeOf((*MockProduct)(nil).DeleteProduct), ctx, productId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserToken", reflect.TypeOf((*MockUserToken)(nil).CreateUserToken), ctx, token)
 }
 
-// GetAllProducts mocks base method.
-func (m *MockProduct) GetAllProducts(ctx context.Context) ([]entity.Product, error) {
+// InvalidateUserTokens mocks base method.
+func (m *MockUserToken) InvalidateUserTokens(ctx context.Context, userId int, purpose entity.TokenPurpose) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllProducts", ctx)
-	ret0, _ := ret[0].([]entity.Product)
+	ret := m.ctrl.Call(m, "InvalidateUserTokens", ctx, userId, purpose)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidateUserTokens indicates an expected call of InvalidateUserTokens.
+func (mr *MockUserTokenMockRecorder) InvalidateUserTokens(ctx, userId, purpose interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateUserTokens", reflect.TypeOf((*MockUserToken)(nil).InvalidateUserTokens), ctx, userId, purpose)
+}
+
+// MockSession is a mock of Session interface.
+type MockSession struct {
+	ctrl     *gomock.Controller
+	recorder *MockSessionMockRecorder
+}
+
+// MockSessionMockRecorder is the mock recorder for MockSession.
+type MockSessionMockRecorder struct {
+	mock *MockSession
+}
+
+// NewMockSession creates a new mock instance.
+func NewMockSession(ctrl *gomock.Controller) *MockSession {
+	mock := &MockSession{ctrl: ctrl}
+	mock.recorder = &MockSessionMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSession) EXPECT() *MockSessionMockRecorder {
+	return m.recorder
+}
+
+// CreateSession mocks base method.
+func (m *MockSession) CreateSession(ctx context.Context, session entity.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSession", ctx, session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MockSessionMockRecorder) CreateSession(ctx, session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockSession)(nil).CreateSession), ctx, session)
+}
+
+// GetSession mocks base method.
+func (m *MockSession) GetSession(ctx context.Context, id string) (entity.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", ctx, id)
+	ret0, _ := ret[0].(entity.Session)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAllProducts indicates an expected call of GetAllProducts.
-func (mr *MockProductMockRecorder) GetAllProducts(ctx interface{}) *gomock.Call {
+// GetSession indicates an expected call of GetSession.
+func (mr *MockSessionMockRecorder) GetSession(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllProducts", reflect.TypeOf((*MockProduct)(nil).GetAllProducts), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockSession)(nil).GetSession), ctx, id)
 }
 
-// GetProductById mocks base method.
-func (m *MockProduct) GetProductById(ctx context.Context, productId int) (entity.Product, error) {
+// GetSessionByHandle mocks base method.
+func (m *MockSession) GetSessionByHandle(ctx context.Context, handle string) (entity.Session, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetProductById", ctx, productId)
-	ret0, _ := ret[0].(entity.Product)
+	ret := m.ctrl.Call(m, "GetSessionByHandle", ctx, handle)
+	ret0, _ := ret[0].(entity.Session)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetProductById indicates an expected call of GetProductById.
-func (mr *MockProductMockRecorder) GetProductById(ctx, productId interface{}) *gomock.Call {
+// GetSessionByHandle indicates an expected call of GetSessionByHandle.
+func (mr *MockSessionMockRecorder) GetSessionByHandle(ctx, handle interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductById", reflect.TypeOf((*MockProduct)(nil).GetProductById), ctx, productId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionByHandle", reflect.TypeOf((*MockSession)(nil).GetSessionByHandle), ctx, handle)
 }
 
-// UpdateProduct mocks base method.
-func (m *MockProduct) UpdateProduct(ctx context.Context, product entity.Product) error {
+// RevokeSession mocks base method.
+func (m *MockSession) RevokeSession(ctx context.Context, id string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateProduct", ctx, product)
+	ret := m.ctrl.Call(m, "RevokeSession", ctx, id)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateProduct indicates an expected call of UpdateProduct.
-func (mr *MockProductMockRecorder) UpdateProduct(ctx, product interface{}) *gomock.Call {
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockSessionMockRecorder) RevokeSession(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProduct", reflect.TypeOf((*MockProduct)(nil).UpdateProduct), ctx, product)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockSession)(nil).RevokeSession), ctx, id)
 }
 
-// MockPurchase is a mock of Purchase interface.
-type MockPurchase struct {
+// RevokeUserSessions mocks base method.
+func (m *MockSession) RevokeUserSessions(ctx context.Context, userId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserSessions", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserSessions indicates an expected call of RevokeUserSessions.
+func (mr *MockSessionMockRecorder) RevokeUserSessions(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserSessions", reflect.TypeOf((*MockSession)(nil).RevokeUserSessions), ctx, userId)
+}
+
+// RotateSession mocks base method.
+func (m *MockSession) RotateSession(ctx context.Context, id, oldHash, newHash string, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateSession", ctx, id, oldHash, newHash, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RotateSession indicates an expected call of RotateSession.
+func (mr *MockSessionMockRecorder) RotateSession(ctx, id, oldHash, newHash, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateSession", reflect.TypeOf((*MockSession)(nil).RotateSession), ctx, id, oldHash, newHash, expiresAt)
+}
+
+// MockTwoFactor is a mock of TwoFactor interface.
+type MockTwoFactor struct {
 	ctrl     *gomock.Controller
-	recorder *MockPurchaseMockRecorder
+	recorder *MockTwoFactorMockRecorder
 }
 
-// MockPurchaseMockRecorder is the mock recorder for MockPurchase.
-type MockPurchaseMockRecorder struct {
-	mock *MockPurchase
+// MockTwoFactorMockRecorder is the mock recorder for MockTwoFactor.
+type MockTwoFactorMockRecorder struct {
+	mock *MockTwoFactor
 }
 
-// NewMockPurchase creates a new mock instance.
-func NewMockPurchase(ctrl *gomock.Controller) *MockPurchase {
-	mock := &MockPurchase{ctrl: ctrl}
-	mock.recorder = &MockPurchaseMockRecorder{mock}
+// NewMockTwoFactor creates a new mock instance.
+func NewMockTwoFactor(ctrl *gomock.Controller) *MockTwoFactor {
+	mock := &MockTwoFactor{ctrl: ctrl}
+	mock.recorder = &MockTwoFactorMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockPurchase) EXPECT() *MockPurchaseMockRecorder {
+func (m *MockTwoFactor) EXPECT() *MockTwoFactorMockRecorder {
 	return m.recorder
 }
 
-// GetProductPurchases mocks base method.
-func (m *MockPurchase) GetProductPurchases(ctx context.Context, productId int) ([]entity.Purchase, error) {
+// ConfirmTOTP mocks base method.
+func (m *MockTwoFactor) ConfirmTOTP(ctx context.Context, userId int, step int64, recoveryCodeHashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTP", ctx, userId, step, recoveryCodeHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmTOTP indicates an expected call of ConfirmTOTP.
+func (mr *MockTwoFactorMockRecorder) ConfirmTOTP(ctx, userId, step, recoveryCodeHashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTP", reflect.TypeOf((*MockTwoFactor)(nil).ConfirmTOTP), ctx, userId, step, recoveryCodeHashes)
+}
+
+// DeleteTOTP mocks base method.
+func (m *MockTwoFactor) DeleteTOTP(ctx context.Context, userId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTOTP", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTOTP indicates an expected call of DeleteTOTP.
+func (mr *MockTwoFactorMockRecorder) DeleteTOTP(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTOTP", reflect.TypeOf((*MockTwoFactor)(nil).DeleteTOTP), ctx, userId)
+}
+
+// GetRecoveryCodes mocks base method.
+func (m *MockTwoFactor) GetRecoveryCodes(ctx context.Context, userId int) ([]entity.RecoveryCode, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetProductPurchases", ctx, productId)
-	ret0, _ := ret[0].([]entity.Purchase)
+	ret := m.ctrl.Call(m, "GetRecoveryCodes", ctx, userId)
+	ret0, _ := ret[0].([]entity.RecoveryCode)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetProductPurchases indicates an expected call of GetProductPurchases.
-func (mr *MockPurchaseMockRecorder) GetProductPurchases(ctx, productId interface{}) *gomock.Call {
+// GetRecoveryCodes indicates an expected call of GetRecoveryCodes.
+func (mr *MockTwoFactorMockRecorder) GetRecoveryCodes(ctx, userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductPurchases", reflect.TypeOf((*MockPurchase)(nil).GetProductPurchases), ctx, productId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecoveryCodes", reflect.TypeOf((*MockTwoFactor)(nil).GetRecoveryCodes), ctx, userId)
 }
 
-// GetUserPurchases mocks base method.
-func (m *MockPurchase) GetUserPurchases(ctx context.Context, userId int) ([]entity.Purchase, error) {
+// GetTOTP mocks base method.
+func (m *MockTwoFactor) GetTOTP(ctx context.Context, userId int) (entity.UserTOTP, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserPurchases", ctx, userId)
-	ret0, _ := ret[0].([]entity.Purchase)
+	ret := m.ctrl.Call(m, "GetTOTP", ctx, userId)
+	ret0, _ := ret[0].(entity.UserTOTP)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserPurchases indicates an expected call of GetUserPurchases.
-func (mr *MockPurchaseMockRecorder) GetUserPurchases(ctx, userId interface{}) *gomock.Call {
+// GetTOTP indicates an expected call of GetTOTP.
+func (mr *MockTwoFactorMockRecorder) GetTOTP(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTOTP", reflect.TypeOf((*MockTwoFactor)(nil).GetTOTP), ctx, userId)
+}
+
+// SaveTOTP mocks base method.
+func (m *MockTwoFactor) SaveTOTP(ctx context.Context, userId int, secret string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTOTP", ctx, userId, secret)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTOTP indicates an expected call of SaveTOTP.
+func (mr *MockTwoFactorMockRecorder) SaveTOTP(ctx, userId, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTOTP", reflect.TypeOf((*MockTwoFactor)(nil).SaveTOTP), ctx, userId, secret)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockTwoFactor) UseRecoveryCode(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockTwoFactorMockRecorder) UseRecoveryCode(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockTwoFactor)(nil).UseRecoveryCode), ctx, id)
+}
+
+// UseTOTPStep mocks base method.
+func (m *MockTwoFactor) UseTOTPStep(ctx context.Context, userId int, step int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPStep", ctx, userId, step)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseTOTPStep indicates an expected call of UseTOTPStep.
+func (mr *MockTwoFactorMockRecorder) UseTOTPStep(ctx, userId, step interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserPurchases", reflect.TypeOf((*MockPurchase)(nil).GetUserPurchases), ctx, userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPStep", reflect.TypeOf((*MockTwoFactor)(nil).UseTOTPStep), ctx, userId, step)
+}
+
+// MockAPIKey is a mock of APIKey interface.
+type MockAPIKey struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyMockRecorder
+}
+
+// MockAPIKeyMockRecorder is the mock recorder for MockAPIKey.
+type MockAPIKeyMockRecorder struct {
+	mock *MockAPIKey
+}
+
+// NewMockAPIKey creates a new mock instance.
+func NewMockAPIKey(ctrl *gomock.Controller) *MockAPIKey {
+	mock := &MockAPIKey{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPIKey) EXPECT() *MockAPIKeyMockRecorder {
+	return m.recorder
 }
 
-// MakePurchase mocks base method.
-func (m *MockPurchase) MakePurchase(ctx context.Context, purchase entity.Purchase) (int, error) {
+// CreateAPIKey mocks base method.
+func (m *MockAPIKey) CreateAPIKey(ctx context.Context, key entity.APIKey) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "MakePurchase", ctx, purchase)
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, key)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// MakePurchase indicates an expected call of MakePurchase.
-func (mr *MockPurchaseMockRecorder) MakePurchase(ctx, purchase interface{}) *gomock.Call {
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockAPIKeyMockRecorder) CreateAPIKey(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockAPIKey)(nil).CreateAPIKey), ctx, key)
+}
+
+// GetAPIKeyByPrefix mocks base method.
+func (m *MockAPIKey) GetAPIKeyByPrefix(ctx context.Context, prefix string) (entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyByPrefix", ctx, prefix)
+	ret0, _ := ret[0].(entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyByPrefix indicates an expected call of GetAPIKeyByPrefix.
+func (mr *MockAPIKeyMockRecorder) GetAPIKeyByPrefix(ctx, prefix interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByPrefix", reflect.TypeOf((*MockAPIKey)(nil).GetAPIKeyByPrefix), ctx, prefix)
+}
+
+// GetUserAPIKeys mocks base method.
+func (m *MockAPIKey) GetUserAPIKeys(ctx context.Context, userId int) ([]entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserAPIKeys", ctx, userId)
+	ret0, _ := ret[0].([]entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserAPIKeys indicates an expected call of GetUserAPIKeys.
+func (mr *MockAPIKeyMockRecorder) GetUserAPIKeys(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserAPIKeys", reflect.TypeOf((*MockAPIKey)(nil).GetUserAPIKeys), ctx, userId)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockAPIKey) RevokeAPIKey(ctx context.Context, userId, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, userId, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockAPIKeyMockRecorder) RevokeAPIKey(ctx, userId, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockAPIKey)(nil).RevokeAPIKey), ctx, userId, id)
+}
+
+// TouchAPIKey mocks base method.
+func (m *MockAPIKey) TouchAPIKey(ctx context.Context, id int, usedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchAPIKey", ctx, id, usedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchAPIKey indicates an expected call of TouchAPIKey.
+func (mr *MockAPIKeyMockRecorder) TouchAPIKey(ctx, id, usedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchAPIKey", reflect.TypeOf((*MockAPIKey)(nil).TouchAPIKey), ctx, id, usedAt)
+}
+
+// MockExternalIdentity is a mock of ExternalIdentity interface.
+type MockExternalIdentity struct {
+	ctrl     *gomock.Controller
+	recorder *MockExternalIdentityMockRecorder
+}
+
+// MockExternalIdentityMockRecorder is the mock recorder for MockExternalIdentity.
+type MockExternalIdentityMockRecorder struct {
+	mock *MockExternalIdentity
+}
+
+// NewMockExternalIdentity creates a new mock instance.
+func NewMockExternalIdentity(ctrl *gomock.Controller) *MockExternalIdentity {
+	mock := &MockExternalIdentity{ctrl: ctrl}
+	mock.recorder = &MockExternalIdentityMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExternalIdentity) EXPECT() *MockExternalIdentityMockRecorder {
+	return m.recorder
+}
+
+// ConsumeOIDCLoginState mocks base method.
+func (m *MockExternalIdentity) ConsumeOIDCLoginState(ctx context.Context, stateHash string) (entity.OIDCLoginState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeOIDCLoginState", ctx, stateHash)
+	ret0, _ := ret[0].(entity.OIDCLoginState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeOIDCLoginState indicates an expected call of ConsumeOIDCLoginState.
+func (mr *MockExternalIdentityMockRecorder) ConsumeOIDCLoginState(ctx, stateHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeOIDCLoginState", reflect.TypeOf((*MockExternalIdentity)(nil).ConsumeOIDCLoginState), ctx, stateHash)
+}
+
+// CreateExternalUser mocks base method.
+func (m *MockExternalIdentity) CreateExternalUser(ctx context.Context, user entity.User, identity entity.ExternalIdentity) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateExternalUser", ctx, user, identity)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateExternalUser indicates an expected call of CreateExternalUser.
+func (mr *MockExternalIdentityMockRecorder) CreateExternalUser(ctx, user, identity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateExternalUser", reflect.TypeOf((*MockExternalIdentity)(nil).CreateExternalUser), ctx, user, identity)
+}
+
+// CreateOIDCLoginState mocks base method.
+func (m *MockExternalIdentity) CreateOIDCLoginState(ctx context.Context, state entity.OIDCLoginState) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOIDCLoginState", ctx, state)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOIDCLoginState indicates an expected call of CreateOIDCLoginState.
+func (mr *MockExternalIdentityMockRecorder) CreateOIDCLoginState(ctx, state interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOIDCLoginState", reflect.TypeOf((*MockExternalIdentity)(nil).CreateOIDCLoginState), ctx, state)
+}
+
+// GetExternalIdentity mocks base method.
+func (m *MockExternalIdentity) GetExternalIdentity(ctx context.Context, provider, subject string) (entity.ExternalIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExternalIdentity", ctx, provider, subject)
+	ret0, _ := ret[0].(entity.ExternalIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExternalIdentity indicates an expected call of GetExternalIdentity.
+func (mr *MockExternalIdentityMockRecorder) GetExternalIdentity(ctx, provider, subject interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExternalIdentity", reflect.TypeOf((*MockExternalIdentity)(nil).GetExternalIdentity), ctx, provider, subject)
+}
+
+// LinkExternalIdentity mocks base method.
+func (m *MockExternalIdentity) LinkExternalIdentity(ctx context.Context, identity entity.ExternalIdentity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkExternalIdentity", ctx, identity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LinkExternalIdentity indicates an expected call of LinkExternalIdentity.
+func (mr *MockExternalIdentityMockRecorder) LinkExternalIdentity(ctx, identity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkExternalIdentity", reflect.TypeOf((*MockExternalIdentity)(nil).LinkExternalIdentity), ctx, identity)
+}
+
+// MockLoginAttempt is a mock of LoginAttempt interface.
+type MockLoginAttempt struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginAttemptMockRecorder
+}
+
+// MockLoginAttemptMockRecorder is the mock recorder for MockLoginAttempt.
+type MockLoginAttemptMockRecorder struct {
+	mock *MockLoginAttempt
+}
+
+// NewMockLoginAttempt creates a new mock instance.
+func NewMockLoginAttempt(ctrl *gomock.Controller) *MockLoginAttempt {
+	mock := &MockLoginAttempt{ctrl: ctrl}
+	mock.recorder = &MockLoginAttemptMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginAttempt) EXPECT() *MockLoginAttemptMockRecorder {
+	return m.recorder
+}
+
+// ReleaseLoginAttempt mocks base method.
+func (m *MockLoginAttempt) ReleaseLoginAttempt(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseLoginAttempt", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseLoginAttempt indicates an expected call of ReleaseLoginAttempt.
+func (mr *MockLoginAttemptMockRecorder) ReleaseLoginAttempt(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseLoginAttempt", reflect.TypeOf((*MockLoginAttempt)(nil).ReleaseLoginAttempt), ctx, key)
+}
+
+// ReserveLoginAttempt mocks base method.
+func (m *MockLoginAttempt) ReserveLoginAttempt(ctx context.Context, key string, at, resetBefore time.Time) (entity.LoginAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveLoginAttempt", ctx, key, at, resetBefore)
+	ret0, _ := ret[0].(entity.LoginAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveLoginAttempt indicates an expected call of ReserveLoginAttempt.
+func (mr *MockLoginAttemptMockRecorder) ReserveLoginAttempt(ctx, key, at, resetBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveLoginAttempt", reflect.TypeOf((*MockLoginAttempt)(nil).ReserveLoginAttempt), ctx, key, at, resetBefore)
+}
+
+// ResetLoginAttempts mocks base method.
+func (m *MockLoginAttempt) ResetLoginAttempts(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetLoginAttempts", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetLoginAttempts indicates an expected call of ResetLoginAttempts.
+func (mr *MockLoginAttemptMockRecorder) ResetLoginAttempts(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetLoginAttempts", reflect.TypeOf((*MockLoginAttempt)(nil).ResetLoginAttempts), ctx, key)
+}
+
+// MockIdempotency is a mock of Idempotency interface.
+type MockIdempotency struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyMockRecorder
+}
+
+// MockIdempotencyMockRecorder is the mock recorder for MockIdempotency.
+type MockIdempotencyMockRecorder struct {
+	mock *MockIdempotency
+}
+
+// NewMockIdempotency creates a new mock instance.
+func NewMockIdempotency(ctrl *gomock.Controller) *MockIdempotency {
+	mock := &MockIdempotency{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotency) EXPECT() *MockIdempotencyMockRecorder {
+	return m.recorder
+}
+
+// CompleteIdempotencyRecord mocks base method.
+func (m *MockIdempotency) CompleteIdempotencyRecord(ctx context.Context, record entity.IdempotencyRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteIdempotencyRecord", ctx, record)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteIdempotencyRecord indicates an expected call of CompleteIdempotencyRecord.
+func (mr *MockIdempotencyMockRecorder) CompleteIdempotencyRecord(ctx, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteIdempotencyRecord", reflect.TypeOf((*MockIdempotency)(nil).CompleteIdempotencyRecord), ctx, record)
+}
+
+// CreateIdempotencyRecord mocks base method.
+func (m *MockIdempotency) CreateIdempotencyRecord(ctx context.Context, record entity.IdempotencyRecord, expiredBefore time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIdempotencyRecord", ctx, record, expiredBefore)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateIdempotencyRecord indicates an expected call of CreateIdempotencyRecord.
+func (mr *MockIdempotencyMockRecorder) CreateIdempotencyRecord(ctx, record, expiredBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdempotencyRecord", reflect.TypeOf((*MockIdempotency)(nil).CreateIdempotencyRecord), ctx, record, expiredBefore)
+}
+
+// DeleteIdempotencyRecord mocks base method.
+func (m *MockIdempotency) DeleteIdempotencyRecord(ctx context.Context, userId int, scope, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIdempotencyRecord", ctx, userId, scope, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteIdempotencyRecord indicates an expected call of DeleteIdempotencyRecord.
+func (mr *MockIdempotencyMockRecorder) DeleteIdempotencyRecord(ctx, userId, scope, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIdempotencyRecord", reflect.TypeOf((*MockIdempotency)(nil).DeleteIdempotencyRecord), ctx, userId, scope, key)
+}
+
+// GetIdempotencyRecord mocks base method.
+func (m *MockIdempotency) GetIdempotencyRecord(ctx context.Context, userId int, scope, key string) (entity.IdempotencyRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdempotencyRecord", ctx, userId, scope, key)
+	ret0, _ := ret[0].(entity.IdempotencyRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdempotencyRecord indicates an expected call of GetIdempotencyRecord.
+func (mr *MockIdempotencyMockRecorder) GetIdempotencyRecord(ctx, userId, scope, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdempotencyRecord", reflect.TypeOf((*MockIdempotency)(nil).GetIdempotencyRecord), ctx, userId, scope, key)
+}
+
+// MockProduct is a mock of Product interface.
+type MockProduct struct {
+	ctrl     *gomock.Controller
+	recorder *MockProductMockRecorder
+}
+
+// MockProductMockRecorder is the mock recorder for MockProduct.
+type MockProductMockRecorder struct {
+	mock *MockProduct
+}
+
+// NewMockProduct creates a new mock instance.
+func NewMockProduct(ctrl *gomock.Controller) *MockProduct {
+	mock := &MockProduct{ctrl: ctrl}
+	mock.recorder = &MockProductMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProduct) EXPECT() *MockProductMockRecorder {
+	return m.recorder
+}
+
+// AddProduct mocks base method.
+func (m *MockProduct) AddProduct(ctx context.Context, product entity.Product) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddProduct", ctx, product)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddProduct indicates an expected call of AddProduct.
+func (mr *MockProductMockRecorder) AddProduct(ctx, product interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProduct", reflect.TypeOf((*MockProduct)(nil).AddProduct), ctx, product)
+}
+
+// DeleteProduct mocks base method.
+func (m *MockProduct) DeleteProduct(ctx context.Context, productId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProduct", ctx, productId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteProduct indicates an expected call of DeleteProduct.
+func (mr *MockProductMockRecorder) DeleteProduct(ctx, productId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProduct", reflect.TypeOf((*MockProduct)(nil).DeleteProduct), ctx, productId)
+}
+
+// GetAllProducts mocks base method.
+func (m *MockProduct) GetAllProducts(ctx context.Context) ([]entity.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllProducts", ctx)
+	ret0, _ := ret[0].([]entity.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllProducts indicates an expected call of GetAllProducts.
+func (mr *MockProductMockRecorder) GetAllProducts(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllProducts", reflect.TypeOf((*MockProduct)(nil).GetAllProducts), ctx)
+}
+
+// GetProductById mocks base method.
+func (m *MockProduct) GetProductById(ctx context.Context, productId int) (entity.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductById", ctx, productId)
+	ret0, _ := ret[0].(entity.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductById indicates an expected call of GetProductById.
+func (mr *MockProductMockRecorder) GetProductById(ctx, productId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductById", reflect.TypeOf((*MockProduct)(nil).GetProductById), ctx, productId)
+}
+
+// GetProductsBySeller mocks base method.
+func (m *MockProduct) GetProductsBySeller(ctx context.Context, sellerId int) ([]entity.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductsBySeller", ctx, sellerId)
+	ret0, _ := ret[0].([]entity.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductsBySeller indicates an expected call of GetProductsBySeller.
+func (mr *MockProductMockRecorder) GetProductsBySeller(ctx, sellerId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductsBySeller", reflect.TypeOf((*MockProduct)(nil).GetProductsBySeller), ctx, sellerId)
+}
+
+// SetReorderThreshold mocks base method.
+func (m *MockProduct) SetReorderThreshold(ctx context.Context, productId int, threshold *int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetReorderThreshold", ctx, productId, threshold)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetReorderThreshold indicates an expected call of SetReorderThreshold.
+func (mr *MockProductMockRecorder) SetReorderThreshold(ctx, productId, threshold interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReorderThreshold", reflect.TypeOf((*MockProduct)(nil).SetReorderThreshold), ctx, productId, threshold)
+}
+
+// UpdateProduct mocks base method.
+func (m *MockProduct) UpdateProduct(ctx context.Context, product entity.Product) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProduct", ctx, product)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProduct indicates an expected call of UpdateProduct.
+func (mr *MockProductMockRecorder) UpdateProduct(ctx, product interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProduct", reflect.TypeOf((*MockProduct)(nil).UpdateProduct), ctx, product)
+}
+
+// MockInventory is a mock of Inventory interface.
+type MockInventory struct {
+	ctrl     *gomock.Controller
+	recorder *MockInventoryMockRecorder
+}
+
+// MockInventoryMockRecorder is the mock recorder for MockInventory.
+type MockInventoryMockRecorder struct {
+	mock *MockInventory
+}
+
+// NewMockInventory creates a new mock instance.
+func NewMockInventory(ctrl *gomock.Controller) *MockInventory {
+	mock := &MockInventory{ctrl: ctrl}
+	mock.recorder = &MockInventoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInventory) EXPECT() *MockInventoryMockRecorder {
+	return m.recorder
+}
+
+// AdjustStock mocks base method.
+func (m *MockInventory) AdjustStock(ctx context.Context, movement entity.InventoryMovement) (entity.InventoryMovement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustStock", ctx, movement)
+	ret0, _ := ret[0].(entity.InventoryMovement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdjustStock indicates an expected call of AdjustStock.
+func (mr *MockInventoryMockRecorder) AdjustStock(ctx, movement interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustStock", reflect.TypeOf((*MockInventory)(nil).AdjustStock), ctx, movement)
+}
+
+// GetProductMovements mocks base method.
+func (m *MockInventory) GetProductMovements(ctx context.Context, productId int, from, to time.Time) ([]entity.InventoryMovement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductMovements", ctx, productId, from, to)
+	ret0, _ := ret[0].([]entity.InventoryMovement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductMovements indicates an expected call of GetProductMovements.
+func (mr *MockInventoryMockRecorder) GetProductMovements(ctx, productId, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductMovements", reflect.TypeOf((*MockInventory)(nil).GetProductMovements), ctx, productId, from, to)
+}
+
+// GetProductStock mocks base method.
+func (m *MockInventory) GetProductStock(ctx context.Context, productId int) ([]entity.WarehouseStock, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductStock", ctx, productId)
+	ret0, _ := ret[0].([]entity.WarehouseStock)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductStock indicates an expected call of GetProductStock.
+func (mr *MockInventoryMockRecorder) GetProductStock(ctx, productId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductStock", reflect.TypeOf((*MockInventory)(nil).GetProductStock), ctx, productId)
+}
+
+// GetStockAt mocks base method.
+func (m *MockInventory) GetStockAt(ctx context.Context, productId int, at time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStockAt", ctx, productId, at)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStockAt indicates an expected call of GetStockAt.
+func (mr *MockInventoryMockRecorder) GetStockAt(ctx, productId, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStockAt", reflect.TypeOf((*MockInventory)(nil).GetStockAt), ctx, productId, at)
+}
+
+// MockWarehouse is a mock of Warehouse interface.
+type MockWarehouse struct {
+	ctrl     *gomock.Controller
+	recorder *MockWarehouseMockRecorder
+}
+
+// MockWarehouseMockRecorder is the mock recorder for MockWarehouse.
+type MockWarehouseMockRecorder struct {
+	mock *MockWarehouse
+}
+
+// NewMockWarehouse creates a new mock instance.
+func NewMockWarehouse(ctrl *gomock.Controller) *MockWarehouse {
+	mock := &MockWarehouse{ctrl: ctrl}
+	mock.recorder = &MockWarehouseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWarehouse) EXPECT() *MockWarehouseMockRecorder {
+	return m.recorder
+}
+
+// CreateWarehouse mocks base method.
+func (m *MockWarehouse) CreateWarehouse(ctx context.Context, warehouse entity.Warehouse) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWarehouse", ctx, warehouse)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWarehouse indicates an expected call of CreateWarehouse.
+func (mr *MockWarehouseMockRecorder) CreateWarehouse(ctx, warehouse interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWarehouse", reflect.TypeOf((*MockWarehouse)(nil).CreateWarehouse), ctx, warehouse)
+}
+
+// GetWarehouse mocks base method.
+func (m *MockWarehouse) GetWarehouse(ctx context.Context, warehouseId int) (entity.Warehouse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWarehouse", ctx, warehouseId)
+	ret0, _ := ret[0].(entity.Warehouse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWarehouse indicates an expected call of GetWarehouse.
+func (mr *MockWarehouseMockRecorder) GetWarehouse(ctx, warehouseId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWarehouse", reflect.TypeOf((*MockWarehouse)(nil).GetWarehouse), ctx, warehouseId)
+}
+
+// GetWarehouses mocks base method.
+func (m *MockWarehouse) GetWarehouses(ctx context.Context) ([]entity.Warehouse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWarehouses", ctx)
+	ret0, _ := ret[0].([]entity.Warehouse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWarehouses indicates an expected call of GetWarehouses.
+func (mr *MockWarehouseMockRecorder) GetWarehouses(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWarehouses", reflect.TypeOf((*MockWarehouse)(nil).GetWarehouses), ctx)
+}
+
+// UpdateWarehouse mocks base method.
+func (m *MockWarehouse) UpdateWarehouse(ctx context.Context, warehouse entity.Warehouse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWarehouse", ctx, warehouse)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWarehouse indicates an expected call of UpdateWarehouse.
+func (mr *MockWarehouseMockRecorder) UpdateWarehouse(ctx, warehouse interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWarehouse", reflect.TypeOf((*MockWarehouse)(nil).UpdateWarehouse), ctx, warehouse)
+}
+
+// MockStockAlert is a mock of StockAlert interface.
+type MockStockAlert struct {
+	ctrl     *gomock.Controller
+	recorder *MockStockAlertMockRecorder
+}
+
+// MockStockAlertMockRecorder is the mock recorder for MockStockAlert.
+type MockStockAlertMockRecorder struct {
+	mock *MockStockAlert
+}
+
+// NewMockStockAlert creates a new mock instance.
+func NewMockStockAlert(ctrl *gomock.Controller) *MockStockAlert {
+	mock := &MockStockAlert{ctrl: ctrl}
+	mock.recorder = &MockStockAlertMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStockAlert) EXPECT() *MockStockAlertMockRecorder {
+	return m.recorder
+}
+
+// ClaimStockAlerts mocks base method.
+func (m *MockStockAlert) ClaimStockAlerts(ctx context.Context, now, claimUntil time.Time, limit int) ([]entity.StockAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimStockAlerts", ctx, now, claimUntil, limit)
+	ret0, _ := ret[0].([]entity.StockAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimStockAlerts indicates an expected call of ClaimStockAlerts.
+func (mr *MockStockAlertMockRecorder) ClaimStockAlerts(ctx, now, claimUntil, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimStockAlerts", reflect.TypeOf((*MockStockAlert)(nil).ClaimStockAlerts), ctx, now, claimUntil, limit)
+}
+
+// GetProductStockAlerts mocks base method.
+func (m *MockStockAlert) GetProductStockAlerts(ctx context.Context, productId int) ([]entity.StockAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductStockAlerts", ctx, productId)
+	ret0, _ := ret[0].([]entity.StockAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductStockAlerts indicates an expected call of GetProductStockAlerts.
+func (mr *MockStockAlertMockRecorder) GetProductStockAlerts(ctx, productId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductStockAlerts", reflect.TypeOf((*MockStockAlert)(nil).GetProductStockAlerts), ctx, productId)
+}
+
+// MarkStockAlertNotified mocks base method.
+func (m *MockStockAlert) MarkStockAlertNotified(ctx context.Context, alertId int, delivered []string, notifiedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkStockAlertNotified", ctx, alertId, delivered, notifiedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkStockAlertNotified indicates an expected call of MarkStockAlertNotified.
+func (mr *MockStockAlertMockRecorder) MarkStockAlertNotified(ctx, alertId, delivered, notifiedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkStockAlertNotified", reflect.TypeOf((*MockStockAlert)(nil).MarkStockAlertNotified), ctx, alertId, delivered, notifiedAt)
+}
+
+// RetryStockAlert mocks base method.
+func (m *MockStockAlert) RetryStockAlert(ctx context.Context, alertId int, delivered []string, nextAttemptAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryStockAlert", ctx, alertId, delivered, nextAttemptAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetryStockAlert indicates an expected call of RetryStockAlert.
+func (mr *MockStockAlertMockRecorder) RetryStockAlert(ctx, alertId, delivered, nextAttemptAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryStockAlert", reflect.TypeOf((*MockStockAlert)(nil).RetryStockAlert), ctx, alertId, delivered, nextAttemptAt)
+}
+
+// MockPurchase is a mock of Purchase interface.
+type MockPurchase struct {
+	ctrl     *gomock.Controller
+	recorder *MockPurchaseMockRecorder
+}
+
+// MockPurchaseMockRecorder is the mock recorder for MockPurchase.
+type MockPurchaseMockRecorder struct {
+	mock *MockPurchase
+}
+
+// NewMockPurchase creates a new mock instance.
+func NewMockPurchase(ctrl *gomock.Controller) *MockPurchase {
+	mock := &MockPurchase{ctrl: ctrl}
+	mock.recorder = &MockPurchaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPurchase) EXPECT() *MockPurchaseMockRecorder {
+	return m.recorder
+}
+
+// CreateOrder mocks base method.
+func (m *MockPurchase) CreateOrder(ctx context.Context, order entity.Order) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrder", ctx, order)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOrder indicates an expected call of CreateOrder.
+func (mr *MockPurchaseMockRecorder) CreateOrder(ctx, order interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrder", reflect.TypeOf((*MockPurchase)(nil).CreateOrder), ctx, order)
+}
+
+// CreateRefund mocks base method.
+func (m *MockPurchase) CreateRefund(ctx context.Context, refund entity.Refund, from entity.OrderStatus) (entity.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRefund", ctx, refund, from)
+	ret0, _ := ret[0].(entity.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRefund indicates an expected call of CreateRefund.
+func (mr *MockPurchaseMockRecorder) CreateRefund(ctx, refund, from interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefund", reflect.TypeOf((*MockPurchase)(nil).CreateRefund), ctx, refund, from)
+}
+
+// GetOrderRefunds mocks base method.
+func (m *MockPurchase) GetOrderRefunds(ctx context.Context, orderId int) ([]entity.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderRefunds", ctx, orderId)
+	ret0, _ := ret[0].([]entity.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderRefunds indicates an expected call of GetOrderRefunds.
+func (mr *MockPurchaseMockRecorder) GetOrderRefunds(ctx, orderId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderRefunds", reflect.TypeOf((*MockPurchase)(nil).GetOrderRefunds), ctx, orderId)
+}
+
+// GetProductOrders mocks base method.
+func (m *MockPurchase) GetProductOrders(ctx context.Context, productId int) ([]entity.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductOrders", ctx, productId)
+	ret0, _ := ret[0].([]entity.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductOrders indicates an expected call of GetProductOrders.
+func (mr *MockPurchaseMockRecorder) GetProductOrders(ctx, productId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductOrders", reflect.TypeOf((*MockPurchase)(nil).GetProductOrders), ctx, productId)
+}
+
+// GetSellerSales mocks base method.
+func (m *MockPurchase) GetSellerSales(ctx context.Context, sellerId int) ([]entity.ProductSales, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSellerSales", ctx, sellerId)
+	ret0, _ := ret[0].([]entity.ProductSales)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSellerSales indicates an expected call of GetSellerSales.
+func (mr *MockPurchaseMockRecorder) GetSellerSales(ctx, sellerId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSellerSales", reflect.TypeOf((*MockPurchase)(nil).GetSellerSales), ctx, sellerId)
+}
+
+// GetUserOrders mocks base method.
+func (m *MockPurchase) GetUserOrders(ctx context.Context, userId int) ([]entity.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserOrders", ctx, userId)
+	ret0, _ := ret[0].([]entity.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserOrders indicates an expected call of GetUserOrders.
+func (mr *MockPurchaseMockRecorder) GetUserOrders(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserOrders", reflect.TypeOf((*MockPurchase)(nil).GetUserOrders), ctx, userId)
+}
+
+// MockOrder is a mock of Order interface.
+type MockOrder struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderMockRecorder
+}
+
+// MockOrderMockRecorder is the mock recorder for MockOrder.
+type MockOrderMockRecorder struct {
+	mock *MockOrder
+}
+
+// NewMockOrder creates a new mock instance.
+func NewMockOrder(ctrl *gomock.Controller) *MockOrder {
+	mock := &MockOrder{ctrl: ctrl}
+	mock.recorder = &MockOrderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrder) EXPECT() *MockOrderMockRecorder {
+	return m.recorder
+}
+
+// CancelOrder mocks base method.
+func (m *MockOrder) CancelOrder(ctx context.Context, change entity.OrderStatusChange, refund bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelOrder", ctx, change, refund)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelOrder indicates an expected call of CancelOrder.
+func (mr *MockOrderMockRecorder) CancelOrder(ctx, change, refund interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelOrder", reflect.TypeOf((*MockOrder)(nil).CancelOrder), ctx, change, refund)
+}
+
+// GetOrder mocks base method.
+func (m *MockOrder) GetOrder(ctx context.Context, orderId int) (entity.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrder", ctx, orderId)
+	ret0, _ := ret[0].(entity.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrder indicates an expected call of GetOrder.
+func (mr *MockOrderMockRecorder) GetOrder(ctx, orderId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockOrder)(nil).GetOrder), ctx, orderId)
+}
+
+// GetOrderHistory mocks base method.
+func (m *MockOrder) GetOrderHistory(ctx context.Context, orderId int) ([]entity.OrderStatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderHistory", ctx, orderId)
+	ret0, _ := ret[0].([]entity.OrderStatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderHistory indicates an expected call of GetOrderHistory.
+func (mr *MockOrderMockRecorder) GetOrderHistory(ctx, orderId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderHistory", reflect.TypeOf((*MockOrder)(nil).GetOrderHistory), ctx, orderId)
+}
+
+// UpdateOrderStatus mocks base method.
+func (m *MockOrder) UpdateOrderStatus(ctx context.Context, change entity.OrderStatusChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOrderStatus", ctx, change)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOrderStatus indicates an expected call of UpdateOrderStatus.
+func (mr *MockOrderMockRecorder) UpdateOrderStatus(ctx, change interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrderStatus", reflect.TypeOf((*MockOrder)(nil).UpdateOrderStatus), ctx, change)
+}
+
+// MockReservation is a mock of Reservation interface.
+type MockReservation struct {
+	ctrl     *gomock.Controller
+	recorder *MockReservationMockRecorder
+}
+
+// MockReservationMockRecorder is the mock recorder for MockReservation.
+type MockReservationMockRecorder struct {
+	mock *MockReservation
+}
+
+// NewMockReservation creates a new mock instance.
+func NewMockReservation(ctrl *gomock.Controller) *MockReservation {
+	mock := &MockReservation{ctrl: ctrl}
+	mock.recorder = &MockReservationMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReservation) EXPECT() *MockReservationMockRecorder {
+	return m.recorder
+}
+
+// ConfirmReservation mocks base method.
+func (m *MockReservation) ConfirmReservation(ctx context.Context, reservationId int, shipTo *entity.GeoPoint, now time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmReservation", ctx, reservationId, shipTo, now)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmReservation indicates an expected call of ConfirmReservation.
+func (mr *MockReservationMockRecorder) ConfirmReservation(ctx, reservationId, shipTo, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmReservation", reflect.TypeOf((*MockReservation)(nil).ConfirmReservation), ctx, reservationId, shipTo, now)
+}
+
+// CreateReservation mocks base method.
+func (m *MockReservation) CreateReservation(ctx context.Context, reservation entity.Reservation) (entity.Reservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReservation", ctx, reservation)
+	ret0, _ := ret[0].(entity.Reservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReservation indicates an expected call of CreateReservation.
+func (mr *MockReservationMockRecorder) CreateReservation(ctx, reservation interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReservation", reflect.TypeOf((*MockReservation)(nil).CreateReservation), ctx, reservation)
+}
+
+// GetReservation mocks base method.
+func (m *MockReservation) GetReservation(ctx context.Context, reservationId int) (entity.Reservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReservation", ctx, reservationId)
+	ret0, _ := ret[0].(entity.Reservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReservation indicates an expected call of GetReservation.
+func (mr *MockReservationMockRecorder) GetReservation(ctx, reservationId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReservation", reflect.TypeOf((*MockReservation)(nil).GetReservation), ctx, reservationId)
+}
+
+// ReleaseExpiredReservations mocks base method.
+func (m *MockReservation) ReleaseExpiredReservations(ctx context.Context, now time.Time, limit int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseExpiredReservations", ctx, now, limit)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseExpiredReservations indicates an expected call of ReleaseExpiredReservations.
+func (mr *MockReservationMockRecorder) ReleaseExpiredReservations(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseExpiredReservations", reflect.TypeOf((*MockReservation)(nil).ReleaseExpiredReservations), ctx, now, limit)
+}
+
+// ReleaseReservation mocks base method.
+func (m *MockReservation) ReleaseReservation(ctx context.Context, reservationId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseReservation", ctx, reservationId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseReservation indicates an expected call of ReleaseReservation.
+func (mr *MockReservationMockRecorder) ReleaseReservation(ctx, reservationId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseReservation", reflect.TypeOf((*MockReservation)(nil).ReleaseReservation), ctx, reservationId)
+}
+
+// MockCart is a mock of Cart interface.
+type MockCart struct {
+	ctrl     *gomock.Controller
+	recorder *MockCartMockRecorder
+}
+
+// MockCartMockRecorder is the mock recorder for MockCart.
+type MockCartMockRecorder struct {
+	mock *MockCart
+}
+
+// NewMockCart creates a new mock instance.
+func NewMockCart(ctrl *gomock.Controller) *MockCart {
+	mock := &MockCart{ctrl: ctrl}
+	mock.recorder = &MockCartMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCart) EXPECT() *MockCartMockRecorder {
+	return m.recorder
+}
+
+// AddCartItem mocks base method.
+func (m *MockCart) AddCartItem(ctx context.Context, userId, productId, quantity int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCartItem", ctx, userId, productId, quantity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddCartItem indicates an expected call of AddCartItem.
+func (mr *MockCartMockRecorder) AddCartItem(ctx, userId, productId, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCartItem", reflect.TypeOf((*MockCart)(nil).AddCartItem), ctx, userId, productId, quantity)
+}
+
+// Checkout mocks base method.
+func (m *MockCart) Checkout(ctx context.Context, userId int, shipTo *entity.GeoPoint) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Checkout", ctx, userId, shipTo)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Checkout indicates an expected call of Checkout.
+func (mr *MockCartMockRecorder) Checkout(ctx, userId, shipTo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Checkout", reflect.TypeOf((*MockCart)(nil).Checkout), ctx, userId, shipTo)
+}
+
+// ClearCart mocks base method.
+func (m *MockCart) ClearCart(ctx context.Context, userId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearCart", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearCart indicates an expected call of ClearCart.
+func (mr *MockCartMockRecorder) ClearCart(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearCart", reflect.TypeOf((*MockCart)(nil).ClearCart), ctx, userId)
+}
+
+// GetCartItems mocks base method.
+func (m *MockCart) GetCartItems(ctx context.Context, userId int) ([]entity.CartItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCartItems", ctx, userId)
+	ret0, _ := ret[0].([]entity.CartItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCartItems indicates an expected call of GetCartItems.
+func (mr *MockCartMockRecorder) GetCartItems(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCartItems", reflect.TypeOf((*MockCart)(nil).GetCartItems), ctx, userId)
+}
+
+// RemoveCartItem mocks base method.
+func (m *MockCart) RemoveCartItem(ctx context.Context, userId, productId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveCartItem", ctx, userId, productId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveCartItem indicates an expected call of RemoveCartItem.
+func (mr *MockCartMockRecorder) RemoveCartItem(ctx, userId, productId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveCartItem", reflect.TypeOf((*MockCart)(nil).RemoveCartItem), ctx, userId, productId)
+}
+
+// SetCartItemQuantity mocks base method.
+func (m *MockCart) SetCartItemQuantity(ctx context.Context, userId, productId, quantity int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCartItemQuantity", ctx, userId, productId, quantity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCartItemQuantity indicates an expected call of SetCartItemQuantity.
+func (mr *MockCartMockRecorder) SetCartItemQuantity(ctx, userId, productId, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCartItemQuantity", reflect.TypeOf((*MockCart)(nil).SetCartItemQuantity), ctx, userId, productId, quantity)
+}
+
+// MockWallet is a mock of Wallet interface.
+type MockWallet struct {
+	ctrl     *gomock.Controller
+	recorder *MockWalletMockRecorder
+}
+
+// MockWalletMockRecorder is the mock recorder for MockWallet.
+type MockWalletMockRecorder struct {
+	mock *MockWallet
+}
+
+// NewMockWallet creates a new mock instance.
+func NewMockWallet(ctrl *gomock.Controller) *MockWallet {
+	mock := &MockWallet{ctrl: ctrl}
+	mock.recorder = &MockWalletMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWallet) EXPECT() *MockWalletMockRecorder {
+	return m.recorder
+}
+
+// Deposit mocks base method.
+func (m *MockWallet) Deposit(ctx context.Context, userId int, amount float64) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deposit", ctx, userId, amount)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Deposit indicates an expected call of Deposit.
+func (mr *MockWalletMockRecorder) Deposit(ctx, userId, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deposit", reflect.TypeOf((*MockWallet)(nil).Deposit), ctx, userId, amount)
+}
+
+// GetBalance mocks base method.
+func (m *MockWallet) GetBalance(ctx context.Context, userId int) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalance", ctx, userId)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalance indicates an expected call of GetBalance.
+func (mr *MockWalletMockRecorder) GetBalance(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockWallet)(nil).GetBalance), ctx, userId)
+}
+
+// Withdraw mocks base method.
+func (m *MockWallet) Withdraw(ctx context.Context, userId int, amount float64) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Withdraw", ctx, userId, amount)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Withdraw indicates an expected call of Withdraw.
+func (mr *MockWalletMockRecorder) Withdraw(ctx, userId, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Withdraw", reflect.TypeOf((*MockWallet)(nil).Withdraw), ctx, userId, amount)
+}
+
+// MockLedger is a mock of Ledger interface.
+type MockLedger struct {
+	ctrl     *gomock.Controller
+	recorder *MockLedgerMockRecorder
+}
+
+// MockLedgerMockRecorder is the mock recorder for MockLedger.
+type MockLedgerMockRecorder struct {
+	mock *MockLedger
+}
+
+// NewMockLedger creates a new mock instance.
+func NewMockLedger(ctrl *gomock.Controller) *MockLedger {
+	mock := &MockLedger{ctrl: ctrl}
+	mock.recorder = &MockLedgerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLedger) EXPECT() *MockLedgerMockRecorder {
+	return m.recorder
+}
+
+// GetAccountBalances mocks base method.
+func (m *MockLedger) GetAccountBalances(ctx context.Context) ([]ledger.AccountBalance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountBalances", ctx)
+	ret0, _ := ret[0].([]ledger.AccountBalance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountBalances indicates an expected call of GetAccountBalances.
+func (mr *MockLedgerMockRecorder) GetAccountBalances(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountBalances", reflect.TypeOf((*MockLedger)(nil).GetAccountBalances), ctx)
+}
+
+// GetWalletBalances mocks base method.
+func (m *MockLedger) GetWalletBalances(ctx context.Context) (map[int]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletBalances", ctx)
+	ret0, _ := ret[0].(map[int]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletBalances indicates an expected call of GetWalletBalances.
+func (mr *MockLedgerMockRecorder) GetWalletBalances(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MakePurchase", reflect.TypeOf((*MockPurchase)(nil).MakePurchase), ctx, purchase)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletBalances", reflect.TypeOf((*MockLedger)(nil).GetWalletBalances), ctx)
 }
@@ -7,9 +7,12 @@ package servicemocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	entity "github.com/cripplemymind9/go-market/internal/entity"
+	ledger "github.com/cripplemymind9/go-market/internal/ledger"
 	types "github.com/cripplemymind9/go-market/internal/service/types"
+	jwtkeys "github.com/cripplemymind9/go-market/pkg/jwtkeys"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -37,10 +40,10 @@ func (m *MockAuth) EXPECT() *MockAuthMockRecorder {
 }
 
 // GenerateToken mocks base method.
-func (m *MockAuth) GenerateToken(ctx context.Context, input types.AuthGenerateTokenInput) (string, error) {
+func (m *MockAuth) GenerateToken(ctx context.Context, input types.AuthGenerateTokenInput) (types.AuthTokens, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GenerateToken", ctx, input)
-	ret0, _ := ret[0].(string)
+	ret0, _ := ret[0].(types.AuthTokens)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -51,19 +54,92 @@ func (mr *MockAuthMockRecorder) GenerateToken(ctx, input interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockAuth)(nil).GenerateToken), ctx, input)
 }
 
+// IssueTokens mocks base method.
+func (m *MockAuth) IssueTokens(ctx context.Context, userId int) (types.AuthTokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueTokens", ctx, userId)
+	ret0, _ := ret[0].(types.AuthTokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueTokens indicates an expected call of IssueTokens.
+func (mr *MockAuthMockRecorder) IssueTokens(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueTokens", reflect.TypeOf((*MockAuth)(nil).IssueTokens), ctx, userId)
+}
+
+// JWKS mocks base method.
+func (m *MockAuth) JWKS() jwtkeys.JWKSet {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JWKS")
+	ret0, _ := ret[0].(jwtkeys.JWKSet)
+	return ret0
+}
+
+// JWKS indicates an expected call of JWKS.
+func (mr *MockAuthMockRecorder) JWKS() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JWKS", reflect.TypeOf((*MockAuth)(nil).JWKS))
+}
+
+// Logout mocks base method.
+func (m *MockAuth) Logout(ctx context.Context, refreshToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", ctx, refreshToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockAuthMockRecorder) Logout(ctx, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockAuth)(nil).Logout), ctx, refreshToken)
+}
+
+// ParseChallengeToken mocks base method.
+func (m *MockAuth) ParseChallengeToken(ctx context.Context, challengeToken string) (types.AuthChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ParseChallengeToken", ctx, challengeToken)
+	ret0, _ := ret[0].(types.AuthChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ParseChallengeToken indicates an expected call of ParseChallengeToken.
+func (mr *MockAuthMockRecorder) ParseChallengeToken(ctx, challengeToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseChallengeToken", reflect.TypeOf((*MockAuth)(nil).ParseChallengeToken), ctx, challengeToken)
+}
+
 // ParseToken mocks base method.
-func (m *MockAuth) ParseToken(token string) (int, error) {
+func (m *MockAuth) ParseToken(ctx context.Context, token string) (types.AuthIdentity, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ParseToken", token)
-	ret0, _ := ret[0].(int)
+	ret := m.ctrl.Call(m, "ParseToken", ctx, token)
+	ret0, _ := ret[0].(types.AuthIdentity)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ParseToken indicates an expected call of ParseToken.
-func (mr *MockAuthMockRecorder) ParseToken(token interface{}) *gomock.Call {
+func (mr *MockAuthMockRecorder) ParseToken(ctx, token interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseToken", reflect.TypeOf((*MockAuth)(nil).ParseToken), token)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseToken", reflect.TypeOf((*MockAuth)(nil).ParseToken), ctx, token)
+}
+
+// RefreshToken mocks base method.
+func (m *MockAuth) RefreshToken(ctx context.Context, refreshToken string) (types.AuthTokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshToken", ctx, refreshToken)
+	ret0, _ := ret[0].(types.AuthTokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshToken indicates an expected call of RefreshToken.
+func (mr *MockAuthMockRecorder) RefreshToken(ctx, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshToken", reflect.TypeOf((*MockAuth)(nil).RefreshToken), ctx, refreshToken)
 }
 
 // RegisterUser mocks base method.
@@ -81,166 +157,1383 @@ func (mr *MockAuthMockRecorder) RegisterUser(ctx, input interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterUser", reflect.TypeOf((*MockAuth)(nil).RegisterUser), ctx, input)
 }
 
-// MockProduct is a mock of Product interface.
-type MockProduct struct {
+// VerifyTwoFactor mocks base method.
+func (m *MockAuth) VerifyTwoFactor(ctx context.Context, input types.AuthVerifyTwoFactorInput) (types.AuthTokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyTwoFactor", ctx, input)
+	ret0, _ := ret[0].(types.AuthTokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyTwoFactor indicates an expected call of VerifyTwoFactor.
+func (mr *MockAuthMockRecorder) VerifyTwoFactor(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyTwoFactor", reflect.TypeOf((*MockAuth)(nil).VerifyTwoFactor), ctx, input)
+}
+
+// MockAccount is a mock of Account interface.
+type MockAccount struct {
 	ctrl     *gomock.Controller
-	recorder *MockProductMockRecorder
+	recorder *MockAccountMockRecorder
 }
 
-// MockProductMockRecorder is the mock recorder for MockProduct.
-type MockProductMockRecorder struct {
-	mock *MockProduct
+// MockAccountMockRecorder is the mock recorder for MockAccount.
+type MockAccountMockRecorder struct {
+	mock *MockAccount
 }
 
-// NewMockProduct creates a new mock instance.
-func NewMockProduct(ctrl *gomock.Controller) *MockProduct {
-	mock := &MockProduct{ctrl: ctrl}
-	mock.recorder = &MockProductMockRecorder{mock}
+// NewMockAccount creates a new mock instance.
+func NewMockAccount(ctrl *gomock.Controller) *MockAccount {
+	mock := &MockAccount{ctrl: ctrl}
+	mock.recorder = &MockAccountMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockProduct) EXPECT() *MockProductMockRecorder {
+func (m *MockAccount) EXPECT() *MockAccountMockRecorder {
 	return m.recorder
 }
 
-// AddProduct mocks base method.
-func (m *MockProduct) AddProduct(ctx context.Context, input types.ProductAddProductInput) (int, error) {
+// ForgotPassword mocks base method.
+func (m *MockAccount) ForgotPassword(ctx context.Context, email string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddProduct", ctx, input)
-	ret0, _ := ret[0].(int)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "ForgotPassword", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// AddProduct indicates an expected call of AddProduct.
-func (mr *MockProductMockRecorder) AddProduct(ctx, input interface{}) *gomock.Call {
+// ForgotPassword indicates an expected call of ForgotPassword.
+func (mr *MockAccountMockRecorder) ForgotPassword(ctx, email interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProduct", reflect.TypeOf((*MockProduct)(nil).AddProduct), ctx, input)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForgotPassword", reflect.TypeOf((*MockAccount)(nil).ForgotPassword), ctx, email)
 }
 
-// DeleteProduct mocks base method.
-func (m *MockProduct) DeleteProduct(ctx context.Context, productId int) error {
+// ResendVerificationEmail mocks base method.
+func (m *MockAccount) ResendVerificationEmail(ctx context.Context, email string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteProduct", ctx, productId)
+	ret := m.ctrl.Call(m, "ResendVerificationEmail", ctx, email)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteProduct indicates an expected call of DeleteProduct.
-func (mr *MockProductMockRecorder) DeleteProduct(ctx, productId interface{}) *gomock.Call {
+// ResendVerificationEmail indicates an expected call of ResendVerificationEmail.
+func (mr *MockAccountMockRecorder) ResendVerificationEmail(ctx, email interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProduct", reflect.TypeOf((*MockProduct)(nil).DeleteProduct), ctx, productId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResendVerificationEmail", reflect.TypeOf((*MockAccount)(nil).ResendVerificationEmail), ctx, email)
 }
 
-// GetAllProducts mocks base method.
-func (m *MockProduct) GetAllProducts(ctx context.Context) ([]entity.Product, error) {
+// ResetPassword mocks base method.
+func (m *MockAccount) ResetPassword(ctx context.Context, input types.AccountResetPasswordInput) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllProducts", ctx)
-	ret0, _ := ret[0].([]entity.Product)
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockAccountMockRecorder) ResetPassword(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockAccount)(nil).ResetPassword), ctx, input)
+}
+
+// SendVerificationEmail mocks base method.
+func (m *MockAccount) SendVerificationEmail(ctx context.Context, userId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendVerificationEmail", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendVerificationEmail indicates an expected call of SendVerificationEmail.
+func (mr *MockAccountMockRecorder) SendVerificationEmail(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendVerificationEmail", reflect.TypeOf((*MockAccount)(nil).SendVerificationEmail), ctx, userId)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockAccount) VerifyEmail(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockAccountMockRecorder) VerifyEmail(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockAccount)(nil).VerifyEmail), ctx, token)
+}
+
+// MockOIDC is a mock of OIDC interface.
+type MockOIDC struct {
+	ctrl     *gomock.Controller
+	recorder *MockOIDCMockRecorder
+}
+
+// MockOIDCMockRecorder is the mock recorder for MockOIDC.
+type MockOIDCMockRecorder struct {
+	mock *MockOIDC
+}
+
+// NewMockOIDC creates a new mock instance.
+func NewMockOIDC(ctrl *gomock.Controller) *MockOIDC {
+	mock := &MockOIDC{ctrl: ctrl}
+	mock.recorder = &MockOIDCMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOIDC) EXPECT() *MockOIDCMockRecorder {
+	return m.recorder
+}
+
+// Begin mocks base method.
+func (m *MockOIDC) Begin(ctx context.Context) (types.OIDCLoginStart, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Begin", ctx)
+	ret0, _ := ret[0].(types.OIDCLoginStart)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAllProducts indicates an expected call of GetAllProducts.
-func (mr *MockProductMockRecorder) GetAllProducts(ctx interface{}) *gomock.Call {
+// Begin indicates an expected call of Begin.
+func (mr *MockOIDCMockRecorder) Begin(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllProducts", reflect.TypeOf((*MockProduct)(nil).GetAllProducts), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockOIDC)(nil).Begin), ctx)
 }
 
-// GetProductById mocks base method.
-func (m *MockProduct) GetProductById(ctx context.Context, productId int) (entity.Product, error) {
+// Complete mocks base method.
+func (m *MockOIDC) Complete(ctx context.Context, input types.OIDCCompleteInput) (entity.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetProductById", ctx, productId)
-	ret0, _ := ret[0].(entity.Product)
+	ret := m.ctrl.Call(m, "Complete", ctx, input)
+	ret0, _ := ret[0].(entity.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetProductById indicates an expected call of GetProductById.
-func (mr *MockProductMockRecorder) GetProductById(ctx, productId interface{}) *gomock.Call {
+// Complete indicates an expected call of Complete.
+func (mr *MockOIDCMockRecorder) Complete(ctx, input interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductById", reflect.TypeOf((*MockProduct)(nil).GetProductById), ctx, productId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockOIDC)(nil).Complete), ctx, input)
 }
 
-// UpdateProduct mocks base method.
-func (m *MockProduct) UpdateProduct(ctx context.Context, input types.ProductUpdateProductInput) error {
+// MockTwoFactor is a mock of TwoFactor interface.
+type MockTwoFactor struct {
+	ctrl     *gomock.Controller
+	recorder *MockTwoFactorMockRecorder
+}
+
+// MockTwoFactorMockRecorder is the mock recorder for MockTwoFactor.
+type MockTwoFactorMockRecorder struct {
+	mock *MockTwoFactor
+}
+
+// NewMockTwoFactor creates a new mock instance.
+func NewMockTwoFactor(ctrl *gomock.Controller) *MockTwoFactor {
+	mock := &MockTwoFactor{ctrl: ctrl}
+	mock.recorder = &MockTwoFactorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTwoFactor) EXPECT() *MockTwoFactorMockRecorder {
+	return m.recorder
+}
+
+// Confirm mocks base method.
+func (m *MockTwoFactor) Confirm(ctx context.Context, input types.TwoFactorConfirmInput) ([]string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateProduct", ctx, input)
+	ret := m.ctrl.Call(m, "Confirm", ctx, input)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Confirm indicates an expected call of Confirm.
+func (mr *MockTwoFactorMockRecorder) Confirm(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Confirm", reflect.TypeOf((*MockTwoFactor)(nil).Confirm), ctx, input)
+}
+
+// Disable mocks base method.
+func (m *MockTwoFactor) Disable(ctx context.Context, input types.TwoFactorDisableInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Disable", ctx, input)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateProduct indicates an expected call of UpdateProduct.
-func (mr *MockProductMockRecorder) UpdateProduct(ctx, input interface{}) *gomock.Call {
+// Disable indicates an expected call of Disable.
+func (mr *MockTwoFactorMockRecorder) Disable(ctx, input interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProduct", reflect.TypeOf((*MockProduct)(nil).UpdateProduct), ctx, input)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disable", reflect.TypeOf((*MockTwoFactor)(nil).Disable), ctx, input)
 }
 
-// MockPurchase is a mock of Purchase interface.
-type MockPurchase struct {
+// Enroll mocks base method.
+func (m *MockTwoFactor) Enroll(ctx context.Context, userId int) (types.TwoFactorEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enroll", ctx, userId)
+	ret0, _ := ret[0].(types.TwoFactorEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Enroll indicates an expected call of Enroll.
+func (mr *MockTwoFactorMockRecorder) Enroll(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enroll", reflect.TypeOf((*MockTwoFactor)(nil).Enroll), ctx, userId)
+}
+
+// MockAPIKey is a mock of APIKey interface.
+type MockAPIKey struct {
 	ctrl     *gomock.Controller
-	recorder *MockPurchaseMockRecorder
+	recorder *MockAPIKeyMockRecorder
 }
 
-// MockPurchaseMockRecorder is the mock recorder for MockPurchase.
-type MockPurchaseMockRecorder struct {
-	mock *MockPurchase
+// MockAPIKeyMockRecorder is the mock recorder for MockAPIKey.
+type MockAPIKeyMockRecorder struct {
+	mock *MockAPIKey
 }
 
-// NewMockPurchase creates a new mock instance.
-func NewMockPurchase(ctrl *gomock.Controller) *MockPurchase {
-	mock := &MockPurchase{ctrl: ctrl}
-	mock.recorder = &MockPurchaseMockRecorder{mock}
+// NewMockAPIKey creates a new mock instance.
+func NewMockAPIKey(ctrl *gomock.Controller) *MockAPIKey {
+	mock := &MockAPIKey{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockPurchase) EXPECT() *MockPurchaseMockRecorder {
+func (m *MockAPIKey) EXPECT() *MockAPIKeyMockRecorder {
 	return m.recorder
 }
 
-// GetProductPurchases mocks base method.
-func (m *MockPurchase) GetProductPurchases(ctx context.Context, productId int) ([]entity.Purchase, error) {
+// Authenticate mocks base method.
+func (m *MockAPIKey) Authenticate(ctx context.Context, token string) (types.AuthIdentity, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetProductPurchases", ctx, productId)
-	ret0, _ := ret[0].([]entity.Purchase)
+	ret := m.ctrl.Call(m, "Authenticate", ctx, token)
+	ret0, _ := ret[0].(types.AuthIdentity)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetProductPurchases indicates an expected call of GetProductPurchases.
-func (mr *MockPurchaseMockRecorder) GetProductPurchases(ctx, productId interface{}) *gomock.Call {
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockAPIKeyMockRecorder) Authenticate(ctx, token interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductPurchases", reflect.TypeOf((*MockPurchase)(nil).GetProductPurchases), ctx, productId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockAPIKey)(nil).Authenticate), ctx, token)
 }
 
-// GetUserPurchases mocks base method.
-func (m *MockPurchase) GetUserPurchases(ctx context.Context, userId int) ([]entity.Purchase, error) {
+// Create mocks base method.
+func (m *MockAPIKey) Create(ctx context.Context, input types.APIKeyCreateInput) (types.APIKeyCreated, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserPurchases", ctx, userId)
-	ret0, _ := ret[0].([]entity.Purchase)
+	ret := m.ctrl.Call(m, "Create", ctx, input)
+	ret0, _ := ret[0].(types.APIKeyCreated)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserPurchases indicates an expected call of GetUserPurchases.
-func (mr *MockPurchaseMockRecorder) GetUserPurchases(ctx, userId interface{}) *gomock.Call {
+// Create indicates an expected call of Create.
+func (mr *MockAPIKeyMockRecorder) Create(ctx, input interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserPurchases", reflect.TypeOf((*MockPurchase)(nil).GetUserPurchases), ctx, userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAPIKey)(nil).Create), ctx, input)
 }
 
-// MakePurchase mocks base method.
-func (m *MockPurchase) MakePurchase(ctx context.Context, input types.PurchaseMakePurchaseInput) (int, error) {
+// List mocks base method.
+func (m *MockAPIKey) List(ctx context.Context, userId int) ([]entity.APIKey, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "MakePurchase", ctx, input)
-	ret0, _ := ret[0].(int)
+	ret := m.ctrl.Call(m, "List", ctx, userId)
+	ret0, _ := ret[0].([]entity.APIKey)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// MakePurchase indicates an expected call of MakePurchase.
-func (mr *MockPurchaseMockRecorder) MakePurchase(ctx, input interface{}) *gomock.Call {
+// List indicates an expected call of List.
+func (mr *MockAPIKeyMockRecorder) List(ctx, userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MakePurchase", reflect.TypeOf((*MockPurchase)(nil).MakePurchase), ctx, input)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAPIKey)(nil).List), ctx, userId)
+}
+
+// Revoke mocks base method.
+func (m *MockAPIKey) Revoke(ctx context.Context, userId, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, userId, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockAPIKeyMockRecorder) Revoke(ctx, userId, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockAPIKey)(nil).Revoke), ctx, userId, id)
+}
+
+// MockLoginThrottle is a mock of LoginThrottle interface.
+type MockLoginThrottle struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginThrottleMockRecorder
+}
+
+// MockLoginThrottleMockRecorder is the mock recorder for MockLoginThrottle.
+type MockLoginThrottleMockRecorder struct {
+	mock *MockLoginThrottle
+}
+
+// NewMockLoginThrottle creates a new mock instance.
+func NewMockLoginThrottle(ctrl *gomock.Controller) *MockLoginThrottle {
+	mock := &MockLoginThrottle{ctrl: ctrl}
+	mock.recorder = &MockLoginThrottleMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginThrottle) EXPECT() *MockLoginThrottleMockRecorder {
+	return m.recorder
+}
+
+// RecordSuccess mocks base method.
+func (m *MockLoginThrottle) RecordSuccess(ctx context.Context, input types.LoginThrottleInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordSuccess", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordSuccess indicates an expected call of RecordSuccess.
+func (mr *MockLoginThrottleMockRecorder) RecordSuccess(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordSuccess", reflect.TypeOf((*MockLoginThrottle)(nil).RecordSuccess), ctx, input)
+}
+
+// Reserve mocks base method.
+func (m *MockLoginThrottle) Reserve(ctx context.Context, input types.LoginThrottleInput) (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reserve", ctx, input)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reserve indicates an expected call of Reserve.
+func (mr *MockLoginThrottleMockRecorder) Reserve(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reserve", reflect.TypeOf((*MockLoginThrottle)(nil).Reserve), ctx, input)
+}
+
+// MockIdempotency is a mock of Idempotency interface.
+type MockIdempotency struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyMockRecorder
+}
+
+// MockIdempotencyMockRecorder is the mock recorder for MockIdempotency.
+type MockIdempotencyMockRecorder struct {
+	mock *MockIdempotency
+}
+
+// NewMockIdempotency creates a new mock instance.
+func NewMockIdempotency(ctrl *gomock.Controller) *MockIdempotency {
+	mock := &MockIdempotency{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotency) EXPECT() *MockIdempotencyMockRecorder {
+	return m.recorder
+}
+
+// Begin mocks base method.
+func (m *MockIdempotency) Begin(ctx context.Context, input types.IdempotencyBeginInput) (entity.IdempotencyRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Begin", ctx, input)
+	ret0, _ := ret[0].(entity.IdempotencyRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Begin indicates an expected call of Begin.
+func (mr *MockIdempotencyMockRecorder) Begin(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockIdempotency)(nil).Begin), ctx, input)
+}
+
+// Complete mocks base method.
+func (m *MockIdempotency) Complete(ctx context.Context, record entity.IdempotencyRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", ctx, record)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockIdempotencyMockRecorder) Complete(ctx, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockIdempotency)(nil).Complete), ctx, record)
+}
+
+// Release mocks base method.
+func (m *MockIdempotency) Release(ctx context.Context, record entity.IdempotencyRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", ctx, record)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockIdempotencyMockRecorder) Release(ctx, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockIdempotency)(nil).Release), ctx, record)
+}
+
+// MockUser is a mock of User interface.
+type MockUser struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserMockRecorder
+}
+
+// MockUserMockRecorder is the mock recorder for MockUser.
+type MockUserMockRecorder struct {
+	mock *MockUser
+}
+
+// NewMockUser creates a new mock instance.
+func NewMockUser(ctrl *gomock.Controller) *MockUser {
+	mock := &MockUser{ctrl: ctrl}
+	mock.recorder = &MockUserMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUser) EXPECT() *MockUserMockRecorder {
+	return m.recorder
+}
+
+// ChangePassword mocks base method.
+func (m *MockUser) ChangePassword(ctx context.Context, input types.UserChangePasswordInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockUserMockRecorder) ChangePassword(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockUser)(nil).ChangePassword), ctx, input)
+}
+
+// DeleteAccount mocks base method.
+func (m *MockUser) DeleteAccount(ctx context.Context, input types.UserDeleteAccountInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount.
+func (mr *MockUserMockRecorder) DeleteAccount(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockUser)(nil).DeleteAccount), ctx, input)
+}
+
+// GetProfile mocks base method.
+func (m *MockUser) GetProfile(ctx context.Context, userId int) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProfile", ctx, userId)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProfile indicates an expected call of GetProfile.
+func (mr *MockUserMockRecorder) GetProfile(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProfile", reflect.TypeOf((*MockUser)(nil).GetProfile), ctx, userId)
+}
+
+// UpdateProfile mocks base method.
+func (m *MockUser) UpdateProfile(ctx context.Context, input types.UserUpdateProfileInput) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfile", ctx, input)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProfile indicates an expected call of UpdateProfile.
+func (mr *MockUserMockRecorder) UpdateProfile(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockUser)(nil).UpdateProfile), ctx, input)
+}
+
+// MockRole is a mock of Role interface.
+type MockRole struct {
+	ctrl     *gomock.Controller
+	recorder *MockRoleMockRecorder
+}
+
+// MockRoleMockRecorder is the mock recorder for MockRole.
+type MockRoleMockRecorder struct {
+	mock *MockRole
+}
+
+// NewMockRole creates a new mock instance.
+func NewMockRole(ctrl *gomock.Controller) *MockRole {
+	mock := &MockRole{ctrl: ctrl}
+	mock.recorder = &MockRoleMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRole) EXPECT() *MockRoleMockRecorder {
+	return m.recorder
+}
+
+// GetUserRoles mocks base method.
+func (m *MockRole) GetUserRoles(ctx context.Context, userId int) ([]entity.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserRoles", ctx, userId)
+	ret0, _ := ret[0].([]entity.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserRoles indicates an expected call of GetUserRoles.
+func (mr *MockRoleMockRecorder) GetUserRoles(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserRoles", reflect.TypeOf((*MockRole)(nil).GetUserRoles), ctx, userId)
+}
+
+// GrantRole mocks base method.
+func (m *MockRole) GrantRole(ctx context.Context, input types.RoleGrantRoleInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantRole", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GrantRole indicates an expected call of GrantRole.
+func (mr *MockRoleMockRecorder) GrantRole(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantRole", reflect.TypeOf((*MockRole)(nil).GrantRole), ctx, input)
+}
+
+// RevokeRole mocks base method.
+func (m *MockRole) RevokeRole(ctx context.Context, input types.RoleRevokeRoleInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRole", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRole indicates an expected call of RevokeRole.
+func (mr *MockRoleMockRecorder) RevokeRole(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRole", reflect.TypeOf((*MockRole)(nil).RevokeRole), ctx, input)
+}
+
+// MockProduct is a mock of Product interface.
+type MockProduct struct {
+	ctrl     *gomock.Controller
+	recorder *MockProductMockRecorder
+}
+
+// MockProductMockRecorder is the mock recorder for MockProduct.
+type MockProductMockRecorder struct {
+	mock *MockProduct
+}
+
+// NewMockProduct creates a new mock instance.
+func NewMockProduct(ctrl *gomock.Controller) *MockProduct {
+	mock := &MockProduct{ctrl: ctrl}
+	mock.recorder = &MockProductMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProduct) EXPECT() *MockProductMockRecorder {
+	return m.recorder
+}
+
+// AddProduct mocks base method.
+func (m *MockProduct) AddProduct(ctx context.Context, input types.ProductAddProductInput) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddProduct", ctx, input)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddProduct indicates an expected call of AddProduct.
+func (mr *MockProductMockRecorder) AddProduct(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProduct", reflect.TypeOf((*MockProduct)(nil).AddProduct), ctx, input)
+}
+
+// AdjustStock mocks base method.
+func (m *MockProduct) AdjustStock(ctx context.Context, input types.ProductAdjustStockInput) (entity.InventoryMovement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustStock", ctx, input)
+	ret0, _ := ret[0].(entity.InventoryMovement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdjustStock indicates an expected call of AdjustStock.
+func (mr *MockProductMockRecorder) AdjustStock(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustStock", reflect.TypeOf((*MockProduct)(nil).AdjustStock), ctx, input)
+}
+
+// DeleteProduct mocks base method.
+func (m *MockProduct) DeleteProduct(ctx context.Context, input types.ProductDeleteProductInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProduct", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteProduct indicates an expected call of DeleteProduct.
+func (mr *MockProductMockRecorder) DeleteProduct(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProduct", reflect.TypeOf((*MockProduct)(nil).DeleteProduct), ctx, input)
+}
+
+// GetAllProducts mocks base method.
+func (m *MockProduct) GetAllProducts(ctx context.Context) ([]entity.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllProducts", ctx)
+	ret0, _ := ret[0].([]entity.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllProducts indicates an expected call of GetAllProducts.
+func (mr *MockProductMockRecorder) GetAllProducts(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllProducts", reflect.TypeOf((*MockProduct)(nil).GetAllProducts), ctx)
+}
+
+// GetProductById mocks base method.
+func (m *MockProduct) GetProductById(ctx context.Context, productId int) (entity.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductById", ctx, productId)
+	ret0, _ := ret[0].(entity.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductById indicates an expected call of GetProductById.
+func (mr *MockProductMockRecorder) GetProductById(ctx, productId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductById", reflect.TypeOf((*MockProduct)(nil).GetProductById), ctx, productId)
+}
+
+// GetStockAlerts mocks base method.
+func (m *MockProduct) GetStockAlerts(ctx context.Context, input types.ProductStockAlertsInput) ([]entity.StockAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStockAlerts", ctx, input)
+	ret0, _ := ret[0].([]entity.StockAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStockAlerts indicates an expected call of GetStockAlerts.
+func (mr *MockProductMockRecorder) GetStockAlerts(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStockAlerts", reflect.TypeOf((*MockProduct)(nil).GetStockAlerts), ctx, input)
+}
+
+// GetStockAt mocks base method.
+func (m *MockProduct) GetStockAt(ctx context.Context, input types.ProductStockAtInput) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStockAt", ctx, input)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStockAt indicates an expected call of GetStockAt.
+func (mr *MockProductMockRecorder) GetStockAt(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStockAt", reflect.TypeOf((*MockProduct)(nil).GetStockAt), ctx, input)
+}
+
+// GetStockMovements mocks base method.
+func (m *MockProduct) GetStockMovements(ctx context.Context, input types.ProductStockMovementsInput) ([]entity.InventoryMovement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStockMovements", ctx, input)
+	ret0, _ := ret[0].([]entity.InventoryMovement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStockMovements indicates an expected call of GetStockMovements.
+func (mr *MockProductMockRecorder) GetStockMovements(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStockMovements", reflect.TypeOf((*MockProduct)(nil).GetStockMovements), ctx, input)
+}
+
+// GetWarehouseStock mocks base method.
+func (m *MockProduct) GetWarehouseStock(ctx context.Context, input types.ProductWarehouseStockInput) ([]entity.WarehouseStock, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWarehouseStock", ctx, input)
+	ret0, _ := ret[0].([]entity.WarehouseStock)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWarehouseStock indicates an expected call of GetWarehouseStock.
+func (mr *MockProductMockRecorder) GetWarehouseStock(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWarehouseStock", reflect.TypeOf((*MockProduct)(nil).GetWarehouseStock), ctx, input)
+}
+
+// SetReorderThreshold mocks base method.
+func (m *MockProduct) SetReorderThreshold(ctx context.Context, input types.ProductReorderThresholdInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetReorderThreshold", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetReorderThreshold indicates an expected call of SetReorderThreshold.
+func (mr *MockProductMockRecorder) SetReorderThreshold(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReorderThreshold", reflect.TypeOf((*MockProduct)(nil).SetReorderThreshold), ctx, input)
+}
+
+// UpdateProduct mocks base method.
+func (m *MockProduct) UpdateProduct(ctx context.Context, input types.ProductUpdateProductInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProduct", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProduct indicates an expected call of UpdateProduct.
+func (mr *MockProductMockRecorder) UpdateProduct(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProduct", reflect.TypeOf((*MockProduct)(nil).UpdateProduct), ctx, input)
+}
+
+// MockStockAlert is a mock of StockAlert interface.
+type MockStockAlert struct {
+	ctrl     *gomock.Controller
+	recorder *MockStockAlertMockRecorder
+}
+
+// MockStockAlertMockRecorder is the mock recorder for MockStockAlert.
+type MockStockAlertMockRecorder struct {
+	mock *MockStockAlert
+}
+
+// NewMockStockAlert creates a new mock instance.
+func NewMockStockAlert(ctrl *gomock.Controller) *MockStockAlert {
+	mock := &MockStockAlert{ctrl: ctrl}
+	mock.recorder = &MockStockAlertMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStockAlert) EXPECT() *MockStockAlertMockRecorder {
+	return m.recorder
+}
+
+// DispatchAlerts mocks base method.
+func (m *MockStockAlert) DispatchAlerts(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DispatchAlerts", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DispatchAlerts indicates an expected call of DispatchAlerts.
+func (mr *MockStockAlertMockRecorder) DispatchAlerts(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DispatchAlerts", reflect.TypeOf((*MockStockAlert)(nil).DispatchAlerts), ctx)
+}
+
+// MockWarehouse is a mock of Warehouse interface.
+type MockWarehouse struct {
+	ctrl     *gomock.Controller
+	recorder *MockWarehouseMockRecorder
+}
+
+// MockWarehouseMockRecorder is the mock recorder for MockWarehouse.
+type MockWarehouseMockRecorder struct {
+	mock *MockWarehouse
+}
+
+// NewMockWarehouse creates a new mock instance.
+func NewMockWarehouse(ctrl *gomock.Controller) *MockWarehouse {
+	mock := &MockWarehouse{ctrl: ctrl}
+	mock.recorder = &MockWarehouseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWarehouse) EXPECT() *MockWarehouseMockRecorder {
+	return m.recorder
+}
+
+// CreateWarehouse mocks base method.
+func (m *MockWarehouse) CreateWarehouse(ctx context.Context, input types.WarehouseCreateInput) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWarehouse", ctx, input)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWarehouse indicates an expected call of CreateWarehouse.
+func (mr *MockWarehouseMockRecorder) CreateWarehouse(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWarehouse", reflect.TypeOf((*MockWarehouse)(nil).CreateWarehouse), ctx, input)
+}
+
+// GetWarehouses mocks base method.
+func (m *MockWarehouse) GetWarehouses(ctx context.Context) ([]entity.Warehouse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWarehouses", ctx)
+	ret0, _ := ret[0].([]entity.Warehouse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWarehouses indicates an expected call of GetWarehouses.
+func (mr *MockWarehouseMockRecorder) GetWarehouses(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWarehouses", reflect.TypeOf((*MockWarehouse)(nil).GetWarehouses), ctx)
+}
+
+// UpdateWarehouse mocks base method.
+func (m *MockWarehouse) UpdateWarehouse(ctx context.Context, input types.WarehouseUpdateInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWarehouse", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWarehouse indicates an expected call of UpdateWarehouse.
+func (mr *MockWarehouseMockRecorder) UpdateWarehouse(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWarehouse", reflect.TypeOf((*MockWarehouse)(nil).UpdateWarehouse), ctx, input)
+}
+
+// MockSeller is a mock of Seller interface.
+type MockSeller struct {
+	ctrl     *gomock.Controller
+	recorder *MockSellerMockRecorder
+}
+
+// MockSellerMockRecorder is the mock recorder for MockSeller.
+type MockSellerMockRecorder struct {
+	mock *MockSeller
+}
+
+// NewMockSeller creates a new mock instance.
+func NewMockSeller(ctrl *gomock.Controller) *MockSeller {
+	mock := &MockSeller{ctrl: ctrl}
+	mock.recorder = &MockSellerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSeller) EXPECT() *MockSellerMockRecorder {
+	return m.recorder
+}
+
+// GetProducts mocks base method.
+func (m *MockSeller) GetProducts(ctx context.Context, sellerId int) ([]entity.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProducts", ctx, sellerId)
+	ret0, _ := ret[0].([]entity.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProducts indicates an expected call of GetProducts.
+func (mr *MockSellerMockRecorder) GetProducts(ctx, sellerId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProducts", reflect.TypeOf((*MockSeller)(nil).GetProducts), ctx, sellerId)
+}
+
+// GetSalesReport mocks base method.
+func (m *MockSeller) GetSalesReport(ctx context.Context, sellerId int) (entity.SellerSalesReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSalesReport", ctx, sellerId)
+	ret0, _ := ret[0].(entity.SellerSalesReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSalesReport indicates an expected call of GetSalesReport.
+func (mr *MockSellerMockRecorder) GetSalesReport(ctx, sellerId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSalesReport", reflect.TypeOf((*MockSeller)(nil).GetSalesReport), ctx, sellerId)
+}
+
+// MockPurchase is a mock of Purchase interface.
+type MockPurchase struct {
+	ctrl     *gomock.Controller
+	recorder *MockPurchaseMockRecorder
+}
+
+// MockPurchaseMockRecorder is the mock recorder for MockPurchase.
+type MockPurchaseMockRecorder struct {
+	mock *MockPurchase
+}
+
+// NewMockPurchase creates a new mock instance.
+func NewMockPurchase(ctrl *gomock.Controller) *MockPurchase {
+	mock := &MockPurchase{ctrl: ctrl}
+	mock.recorder = &MockPurchaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPurchase) EXPECT() *MockPurchaseMockRecorder {
+	return m.recorder
+}
+
+// GetProductOrders mocks base method.
+func (m *MockPurchase) GetProductOrders(ctx context.Context, productId int) ([]entity.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductOrders", ctx, productId)
+	ret0, _ := ret[0].([]entity.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductOrders indicates an expected call of GetProductOrders.
+func (mr *MockPurchaseMockRecorder) GetProductOrders(ctx, productId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductOrders", reflect.TypeOf((*MockPurchase)(nil).GetProductOrders), ctx, productId)
+}
+
+// GetUserOrders mocks base method.
+func (m *MockPurchase) GetUserOrders(ctx context.Context, userId int) ([]entity.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserOrders", ctx, userId)
+	ret0, _ := ret[0].([]entity.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserOrders indicates an expected call of GetUserOrders.
+func (mr *MockPurchaseMockRecorder) GetUserOrders(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserOrders", reflect.TypeOf((*MockPurchase)(nil).GetUserOrders), ctx, userId)
+}
+
+// MakePurchase mocks base method.
+func (m *MockPurchase) MakePurchase(ctx context.Context, input types.PurchaseMakePurchaseInput) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MakePurchase", ctx, input)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MakePurchase indicates an expected call of MakePurchase.
+func (mr *MockPurchaseMockRecorder) MakePurchase(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MakePurchase", reflect.TypeOf((*MockPurchase)(nil).MakePurchase), ctx, input)
+}
+
+// MockOrder is a mock of Order interface.
+type MockOrder struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderMockRecorder
+}
+
+// MockOrderMockRecorder is the mock recorder for MockOrder.
+type MockOrderMockRecorder struct {
+	mock *MockOrder
+}
+
+// NewMockOrder creates a new mock instance.
+func NewMockOrder(ctrl *gomock.Controller) *MockOrder {
+	mock := &MockOrder{ctrl: ctrl}
+	mock.recorder = &MockOrderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrder) EXPECT() *MockOrderMockRecorder {
+	return m.recorder
+}
+
+// Advance mocks base method.
+func (m *MockOrder) Advance(ctx context.Context, input types.OrderAdvanceInput) (entity.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Advance", ctx, input)
+	ret0, _ := ret[0].(entity.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Advance indicates an expected call of Advance.
+func (mr *MockOrderMockRecorder) Advance(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Advance", reflect.TypeOf((*MockOrder)(nil).Advance), ctx, input)
+}
+
+// Cancel mocks base method.
+func (m *MockOrder) Cancel(ctx context.Context, input types.OrderCancelInput) (entity.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Cancel", ctx, input)
+	ret0, _ := ret[0].(entity.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Cancel indicates an expected call of Cancel.
+func (mr *MockOrderMockRecorder) Cancel(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cancel", reflect.TypeOf((*MockOrder)(nil).Cancel), ctx, input)
+}
+
+// GetOrder mocks base method.
+func (m *MockOrder) GetOrder(ctx context.Context, input types.OrderGetOrderInput) (types.OrderDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrder", ctx, input)
+	ret0, _ := ret[0].(types.OrderDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrder indicates an expected call of GetOrder.
+func (mr *MockOrderMockRecorder) GetOrder(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockOrder)(nil).GetOrder), ctx, input)
+}
+
+// Refund mocks base method.
+func (m *MockOrder) Refund(ctx context.Context, input types.OrderRefundInput) (entity.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refund", ctx, input)
+	ret0, _ := ret[0].(entity.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refund indicates an expected call of Refund.
+func (mr *MockOrderMockRecorder) Refund(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refund", reflect.TypeOf((*MockOrder)(nil).Refund), ctx, input)
+}
+
+// MockReservation is a mock of Reservation interface.
+type MockReservation struct {
+	ctrl     *gomock.Controller
+	recorder *MockReservationMockRecorder
+}
+
+// MockReservationMockRecorder is the mock recorder for MockReservation.
+type MockReservationMockRecorder struct {
+	mock *MockReservation
+}
+
+// NewMockReservation creates a new mock instance.
+func NewMockReservation(ctrl *gomock.Controller) *MockReservation {
+	mock := &MockReservation{ctrl: ctrl}
+	mock.recorder = &MockReservationMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReservation) EXPECT() *MockReservationMockRecorder {
+	return m.recorder
+}
+
+// Confirm mocks base method.
+func (m *MockReservation) Confirm(ctx context.Context, input types.ReservationConfirmInput) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Confirm", ctx, input)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Confirm indicates an expected call of Confirm.
+func (mr *MockReservationMockRecorder) Confirm(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Confirm", reflect.TypeOf((*MockReservation)(nil).Confirm), ctx, input)
+}
+
+// GetReservation mocks base method.
+func (m *MockReservation) GetReservation(ctx context.Context, input types.ReservationInput) (entity.Reservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReservation", ctx, input)
+	ret0, _ := ret[0].(entity.Reservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReservation indicates an expected call of GetReservation.
+func (mr *MockReservationMockRecorder) GetReservation(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReservation", reflect.TypeOf((*MockReservation)(nil).GetReservation), ctx, input)
+}
+
+// Release mocks base method.
+func (m *MockReservation) Release(ctx context.Context, input types.ReservationInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockReservationMockRecorder) Release(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockReservation)(nil).Release), ctx, input)
+}
+
+// ReleaseExpired mocks base method.
+func (m *MockReservation) ReleaseExpired(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseExpired", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseExpired indicates an expected call of ReleaseExpired.
+func (mr *MockReservationMockRecorder) ReleaseExpired(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseExpired", reflect.TypeOf((*MockReservation)(nil).ReleaseExpired), ctx)
+}
+
+// Reserve mocks base method.
+func (m *MockReservation) Reserve(ctx context.Context, input types.ReservationCreateInput) (entity.Reservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reserve", ctx, input)
+	ret0, _ := ret[0].(entity.Reservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reserve indicates an expected call of Reserve.
+func (mr *MockReservationMockRecorder) Reserve(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reserve", reflect.TypeOf((*MockReservation)(nil).Reserve), ctx, input)
+}
+
+// MockCart is a mock of Cart interface.
+type MockCart struct {
+	ctrl     *gomock.Controller
+	recorder *MockCartMockRecorder
+}
+
+// MockCartMockRecorder is the mock recorder for MockCart.
+type MockCartMockRecorder struct {
+	mock *MockCart
+}
+
+// NewMockCart creates a new mock instance.
+func NewMockCart(ctrl *gomock.Controller) *MockCart {
+	mock := &MockCart{ctrl: ctrl}
+	mock.recorder = &MockCartMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCart) EXPECT() *MockCartMockRecorder {
+	return m.recorder
+}
+
+// AddItem mocks base method.
+func (m *MockCart) AddItem(ctx context.Context, input types.CartAddItemInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddItem", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddItem indicates an expected call of AddItem.
+func (mr *MockCartMockRecorder) AddItem(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddItem", reflect.TypeOf((*MockCart)(nil).AddItem), ctx, input)
+}
+
+// Checkout mocks base method.
+func (m *MockCart) Checkout(ctx context.Context, input types.CartCheckoutInput) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Checkout", ctx, input)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Checkout indicates an expected call of Checkout.
+func (mr *MockCartMockRecorder) Checkout(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Checkout", reflect.TypeOf((*MockCart)(nil).Checkout), ctx, input)
+}
+
+// Clear mocks base method.
+func (m *MockCart) Clear(ctx context.Context, userId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Clear", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Clear indicates an expected call of Clear.
+func (mr *MockCartMockRecorder) Clear(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clear", reflect.TypeOf((*MockCart)(nil).Clear), ctx, userId)
+}
+
+// GetCart mocks base method.
+func (m *MockCart) GetCart(ctx context.Context, userId int) (entity.Cart, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCart", ctx, userId)
+	ret0, _ := ret[0].(entity.Cart)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCart indicates an expected call of GetCart.
+func (mr *MockCartMockRecorder) GetCart(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCart", reflect.TypeOf((*MockCart)(nil).GetCart), ctx, userId)
+}
+
+// RemoveItem mocks base method.
+func (m *MockCart) RemoveItem(ctx context.Context, userId, productId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveItem", ctx, userId, productId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveItem indicates an expected call of RemoveItem.
+func (mr *MockCartMockRecorder) RemoveItem(ctx, userId, productId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveItem", reflect.TypeOf((*MockCart)(nil).RemoveItem), ctx, userId, productId)
+}
+
+// UpdateItem mocks base method.
+func (m *MockCart) UpdateItem(ctx context.Context, input types.CartUpdateItemInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateItem", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateItem indicates an expected call of UpdateItem.
+func (mr *MockCartMockRecorder) UpdateItem(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateItem", reflect.TypeOf((*MockCart)(nil).UpdateItem), ctx, input)
+}
+
+// MockWallet is a mock of Wallet interface.
+type MockWallet struct {
+	ctrl     *gomock.Controller
+	recorder *MockWalletMockRecorder
+}
+
+// MockWalletMockRecorder is the mock recorder for MockWallet.
+type MockWalletMockRecorder struct {
+	mock *MockWallet
+}
+
+// NewMockWallet creates a new mock instance.
+func NewMockWallet(ctrl *gomock.Controller) *MockWallet {
+	mock := &MockWallet{ctrl: ctrl}
+	mock.recorder = &MockWalletMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWallet) EXPECT() *MockWalletMockRecorder {
+	return m.recorder
+}
+
+// Deposit mocks base method.
+func (m *MockWallet) Deposit(ctx context.Context, input types.WalletDepositInput) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deposit", ctx, input)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Deposit indicates an expected call of Deposit.
+func (mr *MockWalletMockRecorder) Deposit(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deposit", reflect.TypeOf((*MockWallet)(nil).Deposit), ctx, input)
+}
+
+// GetBalance mocks base method.
+func (m *MockWallet) GetBalance(ctx context.Context, userId int) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalance", ctx, userId)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalance indicates an expected call of GetBalance.
+func (mr *MockWalletMockRecorder) GetBalance(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockWallet)(nil).GetBalance), ctx, userId)
+}
+
+// Withdraw mocks base method.
+func (m *MockWallet) Withdraw(ctx context.Context, input types.WalletWithdrawInput) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Withdraw", ctx, input)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Withdraw indicates an expected call of Withdraw.
+func (mr *MockWalletMockRecorder) Withdraw(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Withdraw", reflect.TypeOf((*MockWallet)(nil).Withdraw), ctx, input)
+}
+
+// MockLedger is a mock of Ledger interface.
+type MockLedger struct {
+	ctrl     *gomock.Controller
+	recorder *MockLedgerMockRecorder
+}
+
+// MockLedgerMockRecorder is the mock recorder for MockLedger.
+type MockLedgerMockRecorder struct {
+	mock *MockLedger
+}
+
+// NewMockLedger creates a new mock instance.
+func NewMockLedger(ctrl *gomock.Controller) *MockLedger {
+	mock := &MockLedger{ctrl: ctrl}
+	mock.recorder = &MockLedgerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLedger) EXPECT() *MockLedgerMockRecorder {
+	return m.recorder
+}
+
+// Reconcile mocks base method.
+func (m *MockLedger) Reconcile(ctx context.Context) (ledger.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reconcile", ctx)
+	ret0, _ := ret[0].(ledger.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reconcile indicates an expected call of Reconcile.
+func (mr *MockLedgerMockRecorder) Reconcile(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconcile", reflect.TypeOf((*MockLedger)(nil).Reconcile), ctx)
 }
@@ -0,0 +1,95 @@
+// Package notifier доставляет продавцам предупреждения о том, что остаток
+// товара опустился до порога дозаказа. Способы доставки выбираются при
+// запуске: лог, вебхук, письмо продавцу или несколько сразу.
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/pkg/mailer"
+)
+
+type Notifier interface {
+	Notify(ctx context.Context, alert entity.StockAlert) error
+}
+
+// LogNotifier пишет предупреждение в лог сервиса.
+type LogNotifier struct{}
+
+func NewLogNotifier() LogNotifier {
+	return LogNotifier{}
+}
+
+func (LogNotifier) Notify(_ context.Context, alert entity.StockAlert) error {
+	log.WithFields(log.Fields{
+		"product_id": alert.ProductID,
+		"seller_id":  alert.SellerID,
+		"threshold":  alert.Threshold,
+		"quantity":   alert.Quantity,
+	}).Warnf("Product %q is running low on stock", alert.ProductName)
+	return nil
+}
+
+// EmailNotifier отправляет предупреждение письмом продавцу товара. Если у
+// продавца нет адреса, предупреждение пропускается.
+type EmailNotifier struct {
+	mailer mailer.Mailer
+}
+
+func NewEmailNotifier(m mailer.Mailer) *EmailNotifier {
+	return &EmailNotifier{mailer: m}
+}
+
+func (n *EmailNotifier) Notify(ctx context.Context, alert entity.StockAlert) error {
+	if alert.SellerEmail == "" {
+		return nil
+	}
+
+	return n.mailer.Send(ctx, mailer.Message{
+		To:      alert.SellerEmail,
+		Subject: "Товар заканчивается",
+		Body: fmt.Sprintf(
+			"Остаток товара «%s» (id %d) - %d шт., порог дозаказа - %d шт.\n\nПополните склад, чтобы покупки не начали завершаться ошибкой.",
+			alert.ProductName, alert.ProductID, alert.Quantity, alert.Threshold,
+		),
+	})
+}
+
+// Channel - способ доставки и имя, под которым запоминается, что
+// предупреждение им уже доставлено.
+type Channel struct {
+	Name     string
+	Notifier Notifier
+}
+
+// Multi отправляет предупреждение всеми способами по очереди. Ошибка одного
+// способа не мешает остальным.
+type Multi []Channel
+
+// Deliver отправляет предупреждение способами, которыми оно еще не доставлено
+// (см. StockAlert.DeliveredChannels). Возвращает имена способов, доставивших
+// его сейчас, и ошибки остальных: при повторной попытке отправлять нужно
+// только ими, иначе продавец получит одно предупреждение несколько раз.
+func (m Multi) Deliver(ctx context.Context, alert entity.StockAlert) ([]string, error) {
+	var (
+		delivered []string
+		errs      []error
+	)
+	for _, ch := range m {
+		if slices.Contains(alert.DeliveredChannels, ch.Name) {
+			continue
+		}
+		if err := ch.Notifier.Notify(ctx, alert); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ch.Name, err))
+			continue
+		}
+		delivered = append(delivered, ch.Name)
+	}
+	return delivered, errors.Join(errs...)
+}
@@ -0,0 +1,123 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/pkg/mailer"
+)
+
+var testAlert = entity.StockAlert{
+	ID:          7,
+	ProductID:   3,
+	ProductName: "Keyboard",
+	SellerID:    2,
+	SellerEmail: "seller@example.com",
+	Threshold:   5,
+	Quantity:    4,
+}
+
+func TestWebhookNotifier_Notify(t *testing.T) {
+	var gotSignature string
+	var got webhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotSignature = r.Header.Get(SignatureHeader)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("webhook body: %v", err)
+		}
+		if gotSignature != Sign("secret", body) {
+			t.Errorf("signature = %q, want %q", gotSignature, Sign("secret", body))
+		}
+	}))
+	defer server.Close()
+
+	if err := NewWebhookNotifier(server.URL, "secret", time.Second).Notify(context.Background(), testAlert); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if got.AlertID != 7 || got.ProductID != 3 || got.Quantity != 4 || got.Threshold != 5 {
+		t.Errorf("payload = %+v", got)
+	}
+	if !strings.HasPrefix(gotSignature, "sha256=") {
+		t.Errorf("signature = %q, want sha256= prefix", gotSignature)
+	}
+}
+
+func TestWebhookNotifier_NotifyFailedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	if err := NewWebhookNotifier(server.URL, "", time.Second).Notify(context.Background(), testAlert); err == nil {
+		t.Error("Notify() error = nil, want error on 502")
+	}
+}
+
+func TestEmailNotifier_Notify(t *testing.T) {
+	m := mailer.NewMemoryMailer()
+	n := NewEmailNotifier(m)
+
+	if err := n.Notify(context.Background(), testAlert); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	withoutEmail := testAlert
+	withoutEmail.SellerEmail = ""
+	if err := n.Notify(context.Background(), withoutEmail); err != nil {
+		t.Fatalf("Notify() without email error = %v", err)
+	}
+
+	messages := m.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(messages))
+	}
+	if messages[0].To != "seller@example.com" || !strings.Contains(messages[0].Body, "Keyboard") {
+		t.Errorf("message = %+v", messages[0])
+	}
+}
+
+type failingNotifier struct{ calls *int }
+
+func (n failingNotifier) Notify(context.Context, entity.StockAlert) error {
+	*n.calls++
+	return errors.New("unavailable")
+}
+
+func TestMulti_Deliver(t *testing.T) {
+	var calls int
+	m := mailer.NewMemoryMailer()
+	multi := Multi{
+		{Name: "webhook", Notifier: failingNotifier{&calls}},
+		{Name: "email", Notifier: NewEmailNotifier(m)},
+	}
+
+	delivered, err := multi.Deliver(context.Background(), testAlert)
+	if err == nil {
+		t.Error("Deliver() error = nil, want error of the failing channel")
+	}
+	if calls != 1 || len(m.Messages()) != 1 {
+		t.Errorf("calls = %d, messages = %d, want both channels to run", calls, len(m.Messages()))
+	}
+	if len(delivered) != 1 || delivered[0] != "email" {
+		t.Errorf("Deliver() delivered = %v, want [email]", delivered)
+	}
+
+	// Повторная попытка не отправляет письмо второй раз.
+	retry := testAlert
+	retry.DeliveredChannels = delivered
+	if delivered, err = multi.Deliver(context.Background(), retry); err == nil || len(delivered) != 0 {
+		t.Errorf("Deliver() retry = %v, %v, want only the failing channel", delivered, err)
+	}
+	if calls != 2 || len(m.Messages()) != 1 {
+		t.Errorf("calls = %d, messages = %d, want only the failing channel to run again", calls, len(m.Messages()))
+	}
+}
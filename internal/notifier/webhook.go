@@ -0,0 +1,91 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+)
+
+// SignatureHeader - заголовок с HMAC-SHA256 тела запроса, по которому
+// получатель вебхука проверяет, что запрос пришел от магазина.
+const SignatureHeader = "X-GoMarket-Signature"
+
+// webhookPayload - тело запроса вебхука.
+type webhookPayload struct {
+	Event       string    `json:"event"`
+	AlertID     int       `json:"alert_id"`
+	ProductID   int       `json:"product_id"`
+	ProductName string    `json:"product_name"`
+	SellerID    int       `json:"seller_id"`
+	Threshold   int       `json:"threshold"`
+	Quantity    int       `json:"quantity"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// WebhookNotifier отправляет предупреждение POST-запросом с JSON на url.
+// Если задан secret, тело подписывается в заголовке SignatureHeader.
+type WebhookNotifier struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func NewWebhookNotifier(url, secret string, timeout time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, alert entity.StockAlert) error {
+	body, err := json.Marshal(webhookPayload{
+		Event:       "stock.low",
+		AlertID:     alert.ID,
+		ProductID:   alert.ProductID,
+		ProductName: alert.ProductName,
+		SellerID:    alert.SellerID,
+		Threshold:   alert.Threshold,
+		Quantity:    alert.Quantity,
+		CreatedAt:   alert.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("notifier: encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("notifier: build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.secret != "" {
+		req.Header.Set(SignatureHeader, Sign(n.secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("notifier: send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("notifier: webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Sign возвращает значение заголовка SignatureHeader для тела body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,61 @@
+// Package memory содержит реализации репозиториев в памяти процесса. Они не
+// переживают перезапуск и не разделяются между экземплярами сервиса.
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+)
+
+type LoginAttemptRepo struct {
+	mu       sync.Mutex
+	attempts map[string]entity.LoginAttempt
+}
+
+func NewLoginAttemptRepo() *LoginAttemptRepo {
+	return &LoginAttemptRepo{attempts: make(map[string]entity.LoginAttempt)}
+}
+
+func (r *LoginAttemptRepo) ReserveLoginAttempt(_ context.Context, key string, at, resetBefore time.Time) (entity.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, ok := r.attempts[key]
+	if !ok {
+		previous = entity.LoginAttempt{Key: key, LastFailureAt: at}
+	}
+
+	attempt := previous
+	if attempt.LastFailureAt.Before(resetBefore) {
+		attempt.Failures = 0
+	}
+	attempt.Failures++
+	attempt.LastFailureAt = at
+	r.attempts[key] = attempt
+
+	return previous, nil
+}
+
+func (r *LoginAttemptRepo) ReleaseLoginAttempt(_ context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if attempt, ok := r.attempts[key]; ok && attempt.Failures > 0 {
+		attempt.Failures--
+		r.attempts[key] = attempt
+	}
+
+	return nil
+}
+
+func (r *LoginAttemptRepo) ResetLoginAttempts(_ context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attempts, key)
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/pkg/postgres"
+)
+
+var apiKeyColumns = []string{
+	"id", "user_id", "name", "prefix", "secret_hash", "scopes",
+	"expires_at", "last_used_at", "created_at", "revoked_at",
+}
+
+type APIKeyRepo struct {
+	*postgres.Postgres
+}
+
+func NewAPIKeyRepo(pg *postgres.Postgres) *APIKeyRepo {
+	return &APIKeyRepo{pg}
+}
+
+// CreateAPIKey сохраняет ключ. Совпадение префикса с уже выданным ключом
+// возвращает repoerrs.ErrAlreadyExists.
+func (r *APIKeyRepo) CreateAPIKey(ctx context.Context, key entity.APIKey) (int, error) {
+	scopes := make([]string, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	sql, args, err := r.Builder.
+		Insert("api_keys").
+		Columns("user_id", "name", "prefix", "secret_hash", "scopes", "expires_at").
+		Values(
+			key.UserID,
+			key.Name,
+			key.Prefix,
+			key.SecretHash,
+			scopes,
+			key.ExpiresAt,
+		).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("APIKeyRepo.CreateAPIKey - r.Builder.Insert: %v", err)
+	}
+
+	var id int
+	err = r.Pool.QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if ok := errors.As(err, &pgErr); ok {
+			if pgErr.Code == "23505" {
+				return 0, repoerrs.ErrAlreadyExists
+			}
+		}
+		return 0, fmt.Errorf("APIKeyRepo.CreateAPIKey - r.Pool.QueryRow: %v", err)
+	}
+
+	return id, nil
+}
+
+func (r *APIKeyRepo) GetAPIKeyByPrefix(ctx context.Context, prefix string) (entity.APIKey, error) {
+	sql, args, err := r.Builder.
+		Select(apiKeyColumns...).
+		From("api_keys").
+		Where("prefix = ?", prefix).
+		ToSql()
+	if err != nil {
+		return entity.APIKey{}, fmt.Errorf("APIKeyRepo.GetAPIKeyByPrefix - r.Builder.Select: %v", err)
+	}
+
+	key, err := scanAPIKey(r.Pool.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.APIKey{}, repoerrs.ErrNotFound
+		}
+		return entity.APIKey{}, fmt.Errorf("APIKeyRepo.GetAPIKeyByPrefix - r.Pool.QueryRow: %v", err)
+	}
+
+	return key, nil
+}
+
+// GetUserAPIKeys возвращает неотозванные ключи пользователя, начиная с новых.
+func (r *APIKeyRepo) GetUserAPIKeys(ctx context.Context, userId int) ([]entity.APIKey, error) {
+	sql, args, err := r.Builder.
+		Select(apiKeyColumns...).
+		From("api_keys").
+		Where(squirrel.Eq{"user_id": userId, "revoked_at": nil}).
+		OrderBy("id DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("APIKeyRepo.GetUserAPIKeys - r.Builder.Select: %v", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("APIKeyRepo.GetUserAPIKeys - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var keys []entity.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("APIKeyRepo.GetUserAPIKeys - rows.Next: %v", err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey отзывает ключ пользователя. Если ключ не найден, принадлежит
+// другому пользователю или уже отозван, возвращает repoerrs.ErrNotFound.
+func (r *APIKeyRepo) RevokeAPIKey(ctx context.Context, userId, id int) error {
+	sql, args, err := r.Builder.
+		Update("api_keys").
+		Set("revoked_at", squirrel.Expr("CURRENT_TIMESTAMP")).
+		Where(squirrel.Eq{"id": id, "user_id": userId, "revoked_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("APIKeyRepo.RevokeAPIKey - r.Builder.Update: %v", err)
+	}
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("APIKeyRepo.RevokeAPIKey - r.Pool.Exec: %v", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *APIKeyRepo) TouchAPIKey(ctx context.Context, id int, usedAt time.Time) error {
+	sql, args, err := r.Builder.
+		Update("api_keys").
+		Set("last_used_at", usedAt).
+		Where("id = ?", id).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("APIKeyRepo.TouchAPIKey - r.Builder.Update: %v", err)
+	}
+
+	if _, err = r.Pool.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("APIKeyRepo.TouchAPIKey - r.Pool.Exec: %v", err)
+	}
+
+	return nil
+}
+
+func scanAPIKey(row pgx.Row) (entity.APIKey, error) {
+	var (
+		key    entity.APIKey
+		scopes []string
+	)
+	err := row.Scan(
+		&key.ID,
+		&key.UserID,
+		&key.Name,
+		&key.Prefix,
+		&key.SecretHash,
+		&scopes,
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.CreatedAt,
+		&key.RevokedAt,
+	)
+	if err != nil {
+		return entity.APIKey{}, err
+	}
+
+	key.Scopes = make([]entity.APIKeyScope, len(scopes))
+	for i, scope := range scopes {
+		key.Scopes[i] = entity.APIKeyScope(scope)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,215 @@
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/cripplemymind9/go-market/internal/allocation"
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/pkg/postgres"
+)
+
+type CartRepo struct {
+	*postgres.Postgres
+	strategy allocation.Strategy
+}
+
+func NewCartRepo(pg *postgres.Postgres, strategy allocation.Strategy) *CartRepo {
+	return &CartRepo{pg, strategy}
+}
+
+// GetCartItems возвращает позиции корзины вместе с текущими ценой и остатком
+// товаров в порядке добавления.
+func (r *CartRepo) GetCartItems(ctx context.Context, userId int) ([]entity.CartItem, error) {
+	sql, args, err := r.Builder.
+		Select("c.product_id", "p.name", "p.price", "c.quantity", "GREATEST(p.quantity - p.reserved, 0)", "c.added_at").
+		From("cart_items c").
+		Join("products p ON p.id = c.product_id").
+		Where("c.user_id = ?", userId).
+		OrderBy("c.added_at", "c.product_id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("CartRepo.GetCartItems - r.Builder.Select: %v", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("CartRepo.GetCartItems - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var items []entity.CartItem
+	for rows.Next() {
+		var item entity.CartItem
+		err = rows.Scan(
+			&item.ProductID,
+			&item.ProductName,
+			&item.Price,
+			&item.Quantity,
+			&item.Available,
+			&item.AddedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("CartRepo.GetCartItems - rows.Scan: %v", err)
+		}
+		items = append(items, item)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("CartRepo.GetCartItems - rows.Err: %v", err)
+	}
+
+	return items, nil
+}
+
+// AddCartItem кладет товар в корзину. Если товар уже в корзине, количество
+// прибавляется к имеющемуся.
+func (r *CartRepo) AddCartItem(ctx context.Context, userId, productId, quantity int) error {
+	sql, args, err := r.Builder.
+		Insert("cart_items").
+		Columns("user_id", "product_id", "quantity").
+		Values(userId, productId, quantity).
+		Suffix("ON CONFLICT (user_id, product_id) DO UPDATE SET quantity = cart_items.quantity + EXCLUDED.quantity").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("CartRepo.AddCartItem - r.Builder.Insert: %v", err)
+	}
+
+	if _, err = r.Pool.Exec(ctx, sql, args...); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return repoerrs.ErrNotFound
+		}
+		return fmt.Errorf("CartRepo.AddCartItem - r.Pool.Exec: %v", err)
+	}
+
+	return nil
+}
+
+func (r *CartRepo) SetCartItemQuantity(ctx context.Context, userId, productId, quantity int) error {
+	sql, args, err := r.Builder.
+		Update("cart_items").
+		Set("quantity", quantity).
+		Where("user_id = ? AND product_id = ?", userId, productId).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("CartRepo.SetCartItemQuantity - r.Builder.Update: %v", err)
+	}
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("CartRepo.SetCartItemQuantity - r.Pool.Exec: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *CartRepo) RemoveCartItem(ctx context.Context, userId, productId int) error {
+	sql, args, err := r.Builder.
+		Delete("cart_items").
+		Where("user_id = ? AND product_id = ?", userId, productId).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("CartRepo.RemoveCartItem - r.Builder.Delete: %v", err)
+	}
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("CartRepo.RemoveCartItem - r.Pool.Exec: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *CartRepo) ClearCart(ctx context.Context, userId int) error {
+	sql, args, err := r.Builder.
+		Delete("cart_items").
+		Where("user_id = ?", userId).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("CartRepo.ClearCart - r.Builder.Delete: %v", err)
+	}
+
+	if _, err = r.Pool.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("CartRepo.ClearCart - r.Pool.Exec: %v", err)
+	}
+
+	return nil
+}
+
+// Checkout оформляет корзину одним заказом и очищает ее. Если хотя бы одну
+// позицию купить нельзя, не покупается ничего. Строки корзины блокируются,
+// поэтому повторное оформление той же корзины дождется первого и получит
+// ErrEmptyCart. Склады заказа выбираются с учетом shipTo. Возвращает id заказа.
+func (r *CartRepo) Checkout(ctx context.Context, userId int, shipTo *entity.GeoPoint) (int, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("CartRepo.Checkout - r.Pool.Begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	sql, args, err := r.Builder.
+		Select("product_id", "quantity").
+		From("cart_items").
+		Where("user_id = ?", userId).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("CartRepo.Checkout - r.Builder.Select: %v", err)
+	}
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("CartRepo.Checkout - tx.Query: %v", err)
+	}
+
+	order := entity.Order{UserID: userId, ShipTo: shipTo}
+	for rows.Next() {
+		var item entity.OrderItem
+		if err = rows.Scan(&item.ProductID, &item.Quantity); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("CartRepo.Checkout - rows.Scan: %v", err)
+		}
+		order.Items = append(order.Items, item)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("CartRepo.Checkout - rows.Err: %v", err)
+	}
+
+	if len(order.Items) == 0 {
+		return 0, repoerrs.ErrEmptyCart
+	}
+
+	id, err := createOrder(ctx, tx, r.Builder, r.strategy, order)
+	if err != nil {
+		return 0, err
+	}
+
+	sql, args, err = r.Builder.
+		Delete("cart_items").
+		Where("user_id = ?", userId).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("CartRepo.Checkout - r.Builder.Delete: %v", err)
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		return 0, fmt.Errorf("CartRepo.Checkout - tx.Exec: %v", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("CartRepo.Checkout - tx.Commit: %v", err)
+	}
+
+	return id, nil
+}
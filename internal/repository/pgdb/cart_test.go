@@ -0,0 +1,95 @@
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cripplemymind9/go-market/internal/allocation"
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+)
+
+func TestCartRepo_Checkout_AllOrNothing(t *testing.T) {
+	pg := newTestPostgres(t)
+	ctx := context.Background()
+
+	productRepo := NewProductRepo(pg)
+	cartRepo := NewCartRepo(pg, allocation.Priority{})
+
+	var productIds []int
+	for _, quantity := range []int{5, 1} {
+		productId, err := productRepo.AddProduct(ctx, entity.Product{
+			Name:        "cart test product",
+			Description: "cart test product",
+			Price:       2,
+			Quantity:    quantity,
+		})
+		if err != nil {
+			t.Fatalf("AddProduct() error = %v", err)
+		}
+		t.Cleanup(func() {
+			_ = productRepo.DeleteProduct(ctx, productId)
+		})
+		productIds = append(productIds, productId)
+	}
+
+	buyerID := newTestBuyer(t, pg, 100)
+
+	if err := cartRepo.AddCartItem(ctx, buyerID, productIds[0], 2); err != nil {
+		t.Fatalf("AddCartItem() error = %v", err)
+	}
+	if err := cartRepo.AddCartItem(ctx, buyerID, productIds[1], 2); err != nil {
+		t.Fatalf("AddCartItem() error = %v", err)
+	}
+
+	// Второго товара не хватает: не должна пройти и первая позиция.
+	if _, err := cartRepo.Checkout(ctx, buyerID, nil); !errors.Is(err, repoerrs.ErrNotEnoughStock) {
+		t.Fatalf("Checkout() error = %v, want %v", err, repoerrs.ErrNotEnoughStock)
+	}
+
+	product, err := productRepo.GetProductById(ctx, productIds[0])
+	if err != nil {
+		t.Fatalf("GetProductById() error = %v", err)
+	}
+	if product.Quantity != 5 {
+		t.Errorf("product quantity = %d, want 5", product.Quantity)
+	}
+
+	balance, err := NewWalletRepo(pg).GetBalance(ctx, buyerID)
+	if err != nil {
+		t.Fatalf("GetBalance() error = %v", err)
+	}
+	if balance != 100 {
+		t.Errorf("buyer balance = %v, want 100", balance)
+	}
+
+	if err = cartRepo.SetCartItemQuantity(ctx, buyerID, productIds[1], 1); err != nil {
+		t.Fatalf("SetCartItemQuantity() error = %v", err)
+	}
+
+	orderId, err := cartRepo.Checkout(ctx, buyerID, nil)
+	if err != nil {
+		t.Fatalf("Checkout() error = %v", err)
+	}
+
+	orders, err := NewPurchaseRepo(pg, allocation.Priority{}).GetUserOrders(ctx, buyerID)
+	if err != nil {
+		t.Fatalf("GetUserOrders() error = %v", err)
+	}
+	if len(orders) != 1 || orders[0].ID != orderId || len(orders[0].Items) != 2 {
+		t.Errorf("GetUserOrders() = %+v, want one order %d with 2 items", orders, orderId)
+	}
+
+	items, err := cartRepo.GetCartItems(ctx, buyerID)
+	if err != nil {
+		t.Fatalf("GetCartItems() error = %v", err)
+	}
+	if len(items) != 0 {
+		t.Errorf("cart items after checkout = %v, want empty", items)
+	}
+
+	if _, err = cartRepo.Checkout(ctx, buyerID, nil); !errors.Is(err, repoerrs.ErrEmptyCart) {
+		t.Errorf("Checkout() repeated error = %v, want %v", err, repoerrs.ErrEmptyCart)
+	}
+}
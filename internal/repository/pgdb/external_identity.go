@@ -0,0 +1,233 @@
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/pkg/postgres"
+)
+
+type ExternalIdentityRepo struct {
+	*postgres.Postgres
+}
+
+func NewExternalIdentityRepo(pg *postgres.Postgres) *ExternalIdentityRepo {
+	return &ExternalIdentityRepo{pg}
+}
+
+// CreateOIDCLoginState сохраняет начатый вход и заодно удаляет просроченные
+// входы, до завершения которых пользователь так и не дошел.
+func (r *ExternalIdentityRepo) CreateOIDCLoginState(ctx context.Context, state entity.OIDCLoginState) error {
+	sql, args, err := r.Builder.
+		Delete("oidc_login_states").
+		Where("expires_at < ?", time.Now()).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ExternalIdentityRepo.CreateOIDCLoginState - r.Builder.Delete: %v", err)
+	}
+
+	if _, err = r.Pool.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("ExternalIdentityRepo.CreateOIDCLoginState - r.Pool.Exec: %v", err)
+	}
+
+	sql, args, err = r.Builder.
+		Insert("oidc_login_states").
+		Columns("state_hash", "nonce", "code_verifier", "expires_at").
+		Values(
+			state.StateHash,
+			state.Nonce,
+			state.CodeVerifier,
+			state.ExpiresAt,
+		).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ExternalIdentityRepo.CreateOIDCLoginState - r.Builder.Insert: %v", err)
+	}
+
+	if _, err = r.Pool.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("ExternalIdentityRepo.CreateOIDCLoginState - r.Pool.Exec: %v", err)
+	}
+
+	return nil
+}
+
+// ConsumeOIDCLoginState удаляет начатый вход и возвращает его. Повторный
+// вызов с тем же state возвращает repoerrs.ErrNotFound. Срок действия
+// проверяет вызывающий.
+func (r *ExternalIdentityRepo) ConsumeOIDCLoginState(ctx context.Context, stateHash string) (entity.OIDCLoginState, error) {
+	sql, args, err := r.Builder.
+		Delete("oidc_login_states").
+		Where("state_hash = ?", stateHash).
+		Suffix("RETURNING state_hash, nonce, code_verifier, expires_at").
+		ToSql()
+	if err != nil {
+		return entity.OIDCLoginState{}, fmt.Errorf("ExternalIdentityRepo.ConsumeOIDCLoginState - r.Builder.Delete: %v", err)
+	}
+
+	var state entity.OIDCLoginState
+	err = r.Pool.QueryRow(ctx, sql, args...).Scan(
+		&state.StateHash,
+		&state.Nonce,
+		&state.CodeVerifier,
+		&state.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.OIDCLoginState{}, repoerrs.ErrNotFound
+		}
+		return entity.OIDCLoginState{}, fmt.Errorf("ExternalIdentityRepo.ConsumeOIDCLoginState - r.Pool.QueryRow: %v", err)
+	}
+
+	return state, nil
+}
+
+func (r *ExternalIdentityRepo) GetExternalIdentity(ctx context.Context, provider, subject string) (entity.ExternalIdentity, error) {
+	sql, args, err := r.Builder.
+		Select("provider", "subject", "user_id", "email").
+		From("user_identities").
+		Where(squirrel.Eq{"provider": provider, "subject": subject}).
+		ToSql()
+	if err != nil {
+		return entity.ExternalIdentity{}, fmt.Errorf("ExternalIdentityRepo.GetExternalIdentity - r.Builder.Select: %v", err)
+	}
+
+	var identity entity.ExternalIdentity
+	err = r.Pool.QueryRow(ctx, sql, args...).Scan(
+		&identity.Provider,
+		&identity.Subject,
+		&identity.UserID,
+		&identity.Email,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.ExternalIdentity{}, repoerrs.ErrNotFound
+		}
+		return entity.ExternalIdentity{}, fmt.Errorf("ExternalIdentityRepo.GetExternalIdentity - r.Pool.QueryRow: %v", err)
+	}
+
+	return identity, nil
+}
+
+// LinkExternalIdentity связывает внешнюю учетную запись с существующим
+// пользователем. Если она уже связана, возвращает repoerrs.ErrAlreadyExists.
+func (r *ExternalIdentityRepo) LinkExternalIdentity(ctx context.Context, identity entity.ExternalIdentity) error {
+	sql, args, err := r.Builder.
+		Insert("user_identities").
+		Columns("provider", "subject", "user_id", "email").
+		Values(
+			identity.Provider,
+			identity.Subject,
+			identity.UserID,
+			identity.Email,
+		).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ExternalIdentityRepo.LinkExternalIdentity - r.Builder.Insert: %v", err)
+	}
+
+	if _, err = r.Pool.Exec(ctx, sql, args...); err != nil {
+		var pgErr *pgconn.PgError
+		if ok := errors.As(err, &pgErr); ok {
+			if pgErr.Code == "23505" {
+				return repoerrs.ErrAlreadyExists
+			}
+		}
+		return fmt.Errorf("ExternalIdentityRepo.LinkExternalIdentity - r.Pool.Exec: %v", err)
+	}
+
+	return nil
+}
+
+// CreateExternalUser в одной транзакции создает пользователя без пароля и
+// связывает с ним внешнюю учетную запись. Занятое имя пользователя или уже
+// связанная учетная запись дают repoerrs.ErrAlreadyExists.
+func (r *ExternalIdentityRepo) CreateExternalUser(ctx context.Context, user entity.User, identity entity.ExternalIdentity) (int, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("ExternalIdentityRepo.CreateExternalUser - r.Pool.Begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var emailVerifiedAt interface{}
+	if user.EmailVerified {
+		emailVerifiedAt = squirrel.Expr("CURRENT_TIMESTAMP")
+	}
+
+	sql, args, err := r.Builder.
+		Insert("users").
+		Columns("username", "password", "email", "email_verified_at").
+		Values(
+			user.Username,
+			"",
+			user.Email,
+			emailVerifiedAt,
+		).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("ExternalIdentityRepo.CreateExternalUser - r.Builder.Insert: %v", err)
+	}
+
+	var id int
+	if err = tx.QueryRow(ctx, sql, args...).Scan(&id); err != nil {
+		return 0, uniqueViolation(err, "ExternalIdentityRepo.CreateExternalUser - tx.QueryRow")
+	}
+
+	for _, role := range user.Roles {
+		sql, args, err = r.Builder.
+			Insert("user_roles").
+			Columns("user_id", "role").
+			Values(id, role).
+			Suffix("ON CONFLICT DO NOTHING").
+			ToSql()
+		if err != nil {
+			return 0, fmt.Errorf("ExternalIdentityRepo.CreateExternalUser - r.Builder.Insert: %v", err)
+		}
+
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			return 0, fmt.Errorf("ExternalIdentityRepo.CreateExternalUser - tx.Exec: %v", err)
+		}
+	}
+
+	sql, args, err = r.Builder.
+		Insert("user_identities").
+		Columns("provider", "subject", "user_id", "email").
+		Values(
+			identity.Provider,
+			identity.Subject,
+			id,
+			identity.Email,
+		).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("ExternalIdentityRepo.CreateExternalUser - r.Builder.Insert: %v", err)
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		return 0, uniqueViolation(err, "ExternalIdentityRepo.CreateExternalUser - tx.Exec")
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("ExternalIdentityRepo.CreateExternalUser - tx.Commit: %v", err)
+	}
+
+	return id, nil
+}
+
+// uniqueViolation превращает нарушение уникальности в repoerrs.ErrAlreadyExists,
+// остальные ошибки дополняет местом возникновения.
+func uniqueViolation(err error, where string) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return repoerrs.ErrAlreadyExists
+	}
+	return fmt.Errorf("%s: %v", where, err)
+}
@@ -0,0 +1,126 @@
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/pkg/postgres"
+)
+
+type IdempotencyRepo struct {
+	*postgres.Postgres
+}
+
+func NewIdempotencyRepo(pg *postgres.Postgres) *IdempotencyRepo {
+	return &IdempotencyRepo{pg}
+}
+
+// CreateIdempotencyRecord занимает ключ под выполняемый запрос. Запись,
+// созданная раньше expiredBefore, считается истекшей и перезаписывается. Если
+// ключ уже занят, возвращает ErrAlreadyExists.
+func (r *IdempotencyRepo) CreateIdempotencyRecord(ctx context.Context, record entity.IdempotencyRecord, expiredBefore time.Time) error {
+	sql, args, err := r.Builder.
+		Insert("idempotency_keys").
+		Columns("user_id", "scope", "key", "fingerprint", "created_at").
+		Values(record.UserID, record.Scope, record.Key, record.Fingerprint, record.CreatedAt).
+		Suffix(`ON CONFLICT (user_id, scope, key) DO UPDATE SET
+			fingerprint = EXCLUDED.fingerprint,
+			status_code = NULL,
+			response_body = NULL,
+			created_at = EXCLUDED.created_at,
+			completed_at = NULL
+			WHERE idempotency_keys.created_at < ?`, expiredBefore).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("IdempotencyRepo.CreateIdempotencyRecord - r.Builder.Insert: %v", err)
+	}
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("IdempotencyRepo.CreateIdempotencyRecord - r.Pool.Exec: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrAlreadyExists
+	}
+
+	return nil
+}
+
+func (r *IdempotencyRepo) GetIdempotencyRecord(ctx context.Context, userId int, scope, key string) (entity.IdempotencyRecord, error) {
+	sql, args, err := r.Builder.
+		Select("user_id", "scope", "key", "fingerprint", "COALESCE(status_code, 0)", "response_body", "created_at").
+		From("idempotency_keys").
+		Where("user_id = ? AND scope = ? AND key = ?", userId, scope, key).
+		ToSql()
+	if err != nil {
+		return entity.IdempotencyRecord{}, fmt.Errorf("IdempotencyRepo.GetIdempotencyRecord - r.Builder.Select: %v", err)
+	}
+
+	var record entity.IdempotencyRecord
+	err = r.Pool.QueryRow(ctx, sql, args...).Scan(
+		&record.UserID,
+		&record.Scope,
+		&record.Key,
+		&record.Fingerprint,
+		&record.StatusCode,
+		&record.ResponseBody,
+		&record.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.IdempotencyRecord{}, repoerrs.ErrNotFound
+		}
+		return entity.IdempotencyRecord{}, fmt.Errorf("IdempotencyRepo.GetIdempotencyRecord - r.Pool.QueryRow: %v", err)
+	}
+
+	return record, nil
+}
+
+// CompleteIdempotencyRecord сохраняет ответ на запрос, занявший ключ.
+func (r *IdempotencyRepo) CompleteIdempotencyRecord(ctx context.Context, record entity.IdempotencyRecord) error {
+	sql, args, err := r.Builder.
+		Update("idempotency_keys").
+		Set("status_code", record.StatusCode).
+		Set("response_body", record.ResponseBody).
+		Set("completed_at", squirrel.Expr("CURRENT_TIMESTAMP")).
+		Where("user_id = ? AND scope = ? AND key = ? AND fingerprint = ?", record.UserID, record.Scope, record.Key, record.Fingerprint).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("IdempotencyRepo.CompleteIdempotencyRecord - r.Builder.Update: %v", err)
+	}
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("IdempotencyRepo.CompleteIdempotencyRecord - r.Pool.Exec: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrNotFound
+	}
+
+	return nil
+}
+
+// DeleteIdempotencyRecord освобождает ключ, ответ на который сохранять не
+// нужно, чтобы запрос можно было повторить.
+func (r *IdempotencyRepo) DeleteIdempotencyRecord(ctx context.Context, userId int, scope, key string) error {
+	sql, args, err := r.Builder.
+		Delete("idempotency_keys").
+		Where("user_id = ? AND scope = ? AND key = ? AND status_code IS NULL", userId, scope, key).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("IdempotencyRepo.DeleteIdempotencyRecord - r.Builder.Delete: %v", err)
+	}
+
+	if _, err = r.Pool.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("IdempotencyRepo.DeleteIdempotencyRecord - r.Pool.Exec: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,304 @@
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/pkg/postgres"
+)
+
+// movementColumns перечисляет колонки inventory_movements в порядке
+// сканирования в scanMovement.
+var movementColumns = []string{
+	"id", "product_id", "COALESCE(warehouse_id, 0)", "kind", "delta", "quantity_after",
+	"COALESCE(actor_id, 0)", "reason", "COALESCE(order_id, 0)", "created_at",
+}
+
+type InventoryRepo struct {
+	*postgres.Postgres
+}
+
+func NewInventoryRepo(pg *postgres.Postgres) *InventoryRepo {
+	return &InventoryRepo{pg}
+}
+
+// AdjustStock меняет остаток товара на складе movement.WarehouseID (или на
+// складе по умолчанию) на movement.Delta и записывает движение. Ни остаток на
+// складе не может стать отрицательным, ни общий остаток не может стать меньше
+// забронированного: в этих случаях возвращается ErrNotEnoughStock.
+func (r *InventoryRepo) AdjustStock(ctx context.Context, movement entity.InventoryMovement) (entity.InventoryMovement, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return entity.InventoryMovement{}, fmt.Errorf("InventoryRepo.AdjustStock - r.Pool.Begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	sql, args, err := r.Builder.
+		Select("quantity", "reserved").
+		From("products").
+		Where("id = ?", movement.ProductID).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return entity.InventoryMovement{}, fmt.Errorf("InventoryRepo.AdjustStock - r.Builder.Select: %v", err)
+	}
+
+	var quantity, reserved int
+	if err = tx.QueryRow(ctx, sql, args...).Scan(&quantity, &reserved); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.InventoryMovement{}, repoerrs.ErrNotFound
+		}
+		return entity.InventoryMovement{}, fmt.Errorf("InventoryRepo.AdjustStock - tx.QueryRow: %v", err)
+	}
+
+	if quantity+movement.Delta < reserved {
+		return entity.InventoryMovement{}, repoerrs.ErrNotEnoughStock
+	}
+
+	movement, err = moveStock(ctx, tx, r.Builder, movement)
+	if err != nil {
+		return entity.InventoryMovement{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return entity.InventoryMovement{}, fmt.Errorf("InventoryRepo.AdjustStock - tx.Commit: %v", err)
+	}
+
+	return movement, nil
+}
+
+// GetProductStock возвращает остатки товара по складам, где он когда-либо
+// был, в порядке приоритета складов.
+func (r *InventoryRepo) GetProductStock(ctx context.Context, productId int) ([]entity.WarehouseStock, error) {
+	sql, args, err := r.Builder.
+		Select("ws.warehouse_id", "w.name", "ws.product_id", "ws.quantity").
+		From("warehouse_stock ws").
+		Join("warehouses w ON w.id = ws.warehouse_id").
+		Where("ws.product_id = ?", productId).
+		OrderBy("w.priority DESC", "w.id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("InventoryRepo.GetProductStock - r.Builder.Select: %v", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("InventoryRepo.GetProductStock - r.Pool.Query: %v", err)
+	}
+
+	stock, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.WarehouseStock, error) {
+		var s entity.WarehouseStock
+		err := row.Scan(&s.WarehouseID, &s.WarehouseName, &s.ProductID, &s.Quantity)
+		return s, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("InventoryRepo.GetProductStock - pgx.CollectRows: %v", err)
+	}
+
+	return stock, nil
+}
+
+// GetProductMovements возвращает движения товара в полуинтервале [from, to)
+// от старых к новым.
+func (r *InventoryRepo) GetProductMovements(ctx context.Context, productId int, from, to time.Time) ([]entity.InventoryMovement, error) {
+	sql, args, err := r.Builder.
+		Select(movementColumns...).
+		From("inventory_movements").
+		Where("product_id = ? AND created_at >= ? AND created_at < ?", productId, from, to).
+		OrderBy("created_at", "id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("InventoryRepo.GetProductMovements - r.Builder.Select: %v", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("InventoryRepo.GetProductMovements - r.Pool.Query: %v", err)
+	}
+
+	movements, err := pgx.CollectRows(rows, scanMovement)
+	if err != nil {
+		return nil, fmt.Errorf("InventoryRepo.GetProductMovements - pgx.CollectRows: %v", err)
+	}
+
+	return movements, nil
+}
+
+// GetStockAt восстанавливает остаток товара на момент at по последнему
+// движению не позже at. Если движений до at не было, остаток нулевой.
+func (r *InventoryRepo) GetStockAt(ctx context.Context, productId int, at time.Time) (int, error) {
+	sql, args, err := r.Builder.
+		Select("quantity_after").
+		From("inventory_movements").
+		Where("product_id = ? AND created_at <= ?", productId, at).
+		OrderBy("created_at DESC", "id DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("InventoryRepo.GetStockAt - r.Builder.Select: %v", err)
+	}
+
+	var quantity int
+	if err = r.Pool.QueryRow(ctx, sql, args...).Scan(&quantity); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("InventoryRepo.GetStockAt - r.Pool.QueryRow: %v", err)
+	}
+
+	return quantity, nil
+}
+
+// moveStock в рамках переданной транзакции меняет остаток товара на складе
+// movement.WarehouseID на movement.Delta, пересчитывает общий остаток товара
+// как сумму остатков по складам и записывает движение с получившимся общим
+// остатком. Если склад не задан, используется склад по умолчанию. Если на
+// складе не хватает единиц, возвращает ErrNotEnoughStock; не забронированный
+// остаток проверяет вызывающий. Новый остаток сверяется с порогом дозаказа.
+// Удаленный товар пропускается: тогда возвращается movement без ID.
+func moveStock(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, movement entity.InventoryMovement) (entity.InventoryMovement, error) {
+	sql, args, err := builder.
+		Select("id").
+		From("products").
+		Where("id = ?", movement.ProductID).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return entity.InventoryMovement{}, fmt.Errorf("moveStock - builder.Select: %v", err)
+	}
+
+	if err = tx.QueryRow(ctx, sql, args...).Scan(&movement.ProductID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return movement, nil
+		}
+		return entity.InventoryMovement{}, fmt.Errorf("moveStock - tx.QueryRow: %v", err)
+	}
+
+	if movement.WarehouseID == 0 {
+		if movement.WarehouseID, err = defaultWarehouse(ctx, tx, builder); err != nil {
+			return entity.InventoryMovement{}, err
+		}
+	}
+
+	if err = changeWarehouseStock(ctx, tx, builder, movement.WarehouseID, movement.ProductID, movement.Delta); err != nil {
+		return entity.InventoryMovement{}, err
+	}
+
+	sql, args, err = builder.
+		Update("products").
+		Set("quantity", squirrel.Expr("(SELECT COALESCE(SUM(quantity), 0) FROM warehouse_stock WHERE product_id = ?)", movement.ProductID)).
+		Where("id = ?", movement.ProductID).
+		Suffix("RETURNING quantity, reorder_threshold").
+		ToSql()
+	if err != nil {
+		return entity.InventoryMovement{}, fmt.Errorf("moveStock - builder.Update: %v", err)
+	}
+
+	var threshold *int
+	if err = tx.QueryRow(ctx, sql, args...).Scan(&movement.QuantityAfter, &threshold); err != nil {
+		return entity.InventoryMovement{}, fmt.Errorf("moveStock - tx.QueryRow: %v", err)
+	}
+
+	if err = checkReorderThreshold(ctx, tx, builder, movement.ProductID, movement.QuantityAfter, threshold); err != nil {
+		return entity.InventoryMovement{}, err
+	}
+
+	sql, args, err = builder.
+		Insert("inventory_movements").
+		Columns("product_id", "warehouse_id", "kind", "delta", "quantity_after", "actor_id", "reason", "order_id").
+		Values(
+			movement.ProductID,
+			movement.WarehouseID,
+			movement.Kind,
+			movement.Delta,
+			movement.QuantityAfter,
+			nullableId(movement.ActorID),
+			movement.Reason,
+			nullableId(movement.OrderID),
+		).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return entity.InventoryMovement{}, fmt.Errorf("moveStock - builder.Insert: %v", err)
+	}
+
+	if err = tx.QueryRow(ctx, sql, args...).Scan(&movement.ID, &movement.CreatedAt); err != nil {
+		return entity.InventoryMovement{}, fmt.Errorf("moveStock - tx.QueryRow: %v", err)
+	}
+
+	return movement, nil
+}
+
+// changeWarehouseStock меняет остаток товара на складе на delta. Строка
+// остатка создается при первом поступлении товара на склад. Вызывающий должен
+// держать блокировку товара, иначе два первых поступления столкнутся на
+// вставке.
+func changeWarehouseStock(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, warehouseId, productId, delta int) error {
+	sql, args, err := builder.
+		Update("warehouse_stock").
+		Set("quantity", squirrel.Expr("quantity + ?", delta)).
+		Where("warehouse_id = ? AND product_id = ?", warehouseId, productId).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("changeWarehouseStock - builder.Update: %v", err)
+	}
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23514" {
+			return repoerrs.ErrNotEnoughStock
+		}
+		return fmt.Errorf("changeWarehouseStock - tx.Exec: %v", err)
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+	if delta < 0 {
+		return repoerrs.ErrNotEnoughStock
+	}
+
+	sql, args, err = builder.
+		Insert("warehouse_stock").
+		Columns("warehouse_id", "product_id", "quantity").
+		Values(warehouseId, productId, delta).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("changeWarehouseStock - builder.Insert: %v", err)
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return repoerrs.ErrNotFound
+		}
+		return fmt.Errorf("changeWarehouseStock - tx.Exec: %v", err)
+	}
+
+	return nil
+}
+
+func scanMovement(row pgx.CollectableRow) (entity.InventoryMovement, error) {
+	var movement entity.InventoryMovement
+	err := row.Scan(
+		&movement.ID,
+		&movement.ProductID,
+		&movement.WarehouseID,
+		&movement.Kind,
+		&movement.Delta,
+		&movement.QuantityAfter,
+		&movement.ActorID,
+		&movement.Reason,
+		&movement.OrderID,
+		&movement.CreatedAt,
+	)
+	return movement, err
+}
@@ -0,0 +1,107 @@
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cripplemymind9/go-market/internal/allocation"
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+)
+
+func TestInventoryRepo_MovementsAndStockAt(t *testing.T) {
+	pg := newTestPostgres(t)
+	ctx := context.Background()
+
+	productRepo := NewProductRepo(pg)
+	inventoryRepo := NewInventoryRepo(pg)
+
+	start := time.Now()
+	productId := newTestProduct(t, productRepo, 2, 10)
+	buyerID := newTestBuyer(t, pg, 100)
+
+	orderId, err := NewPurchaseRepo(pg, allocation.Priority{}).CreateOrder(ctx, entity.Order{
+		UserID: buyerID,
+		Items:  []entity.OrderItem{{ProductID: productId, Quantity: 3}},
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder() error = %v", err)
+	}
+	afterSale := time.Now()
+
+	err = NewOrderRepo(pg).CancelOrder(ctx, entity.OrderStatusChange{
+		OrderID: orderId,
+		From:    entity.OrderStatusPaid,
+		To:      entity.OrderStatusCancelled,
+		ActorID: buyerID,
+		Reason:  "changed my mind",
+	}, true)
+	if err != nil {
+		t.Fatalf("CancelOrder() error = %v", err)
+	}
+
+	movement, err := inventoryRepo.AdjustStock(ctx, entity.InventoryMovement{
+		ProductID: productId,
+		Kind:      entity.InventoryMovementAdjustment,
+		Delta:     -4,
+		ActorID:   buyerID,
+		Reason:    "stocktake",
+	})
+	if err != nil {
+		t.Fatalf("AdjustStock() error = %v", err)
+	}
+	if movement.QuantityAfter != 6 {
+		t.Errorf("AdjustStock() quantity after = %d, want 6", movement.QuantityAfter)
+	}
+
+	if _, err = inventoryRepo.AdjustStock(ctx, entity.InventoryMovement{
+		ProductID: productId,
+		Kind:      entity.InventoryMovementAdjustment,
+		Delta:     -7,
+		Reason:    "stocktake",
+	}); !errors.Is(err, repoerrs.ErrNotEnoughStock) {
+		t.Errorf("AdjustStock() below zero error = %v, want %v", err, repoerrs.ErrNotEnoughStock)
+	}
+
+	movements, err := inventoryRepo.GetProductMovements(ctx, productId, start.Add(-time.Minute), time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("GetProductMovements() error = %v", err)
+	}
+	wantKinds := []entity.InventoryMovementKind{
+		entity.InventoryMovementRestock,
+		entity.InventoryMovementSale,
+		entity.InventoryMovementRefund,
+		entity.InventoryMovementAdjustment,
+	}
+	wantAfter := []int{10, 7, 10, 6}
+	if len(movements) != len(wantKinds) {
+		t.Fatalf("GetProductMovements() = %+v, want %d movements", movements, len(wantKinds))
+	}
+	for i, movement := range movements {
+		if movement.Kind != wantKinds[i] || movement.QuantityAfter != wantAfter[i] {
+			t.Errorf("movement %d = %+v, want %s with quantity after %d", i, movement, wantKinds[i], wantAfter[i])
+		}
+	}
+	if movements[1].OrderID != orderId || movements[2].Reason != "changed my mind" {
+		t.Errorf("order movements = %+v, %+v, want order %d", movements[1], movements[2], orderId)
+	}
+
+	for _, tc := range []struct {
+		at   time.Time
+		want int
+	}{
+		{at: start.Add(-time.Minute), want: 0},
+		{at: afterSale, want: 7},
+		{at: time.Now(), want: 6},
+	} {
+		got, err := inventoryRepo.GetStockAt(ctx, productId, tc.at)
+		if err != nil {
+			t.Fatalf("GetStockAt() error = %v", err)
+		}
+		if got != tc.want {
+			t.Errorf("GetStockAt(%v) = %d, want %d", tc.at, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package pgdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/cripplemymind9/go-market/internal/ledger"
+	"github.com/cripplemymind9/go-market/pkg/postgres"
+)
+
+type LedgerRepo struct {
+	*postgres.Postgres
+}
+
+func NewLedgerRepo(pg *postgres.Postgres) *LedgerRepo {
+	return &LedgerRepo{pg}
+}
+
+func (r *LedgerRepo) GetAccountBalances(ctx context.Context) ([]ledger.AccountBalance, error) {
+	sql, args, err := r.Builder.
+		Select("a.type", "a.owner_id", "-COALESCE(SUM(p.amount), 0)").
+		From("ledger_accounts a").
+		LeftJoin("ledger_postings p ON p.account_id = a.id").
+		GroupBy("a.id", "a.type", "a.owner_id").
+		OrderBy("a.type", "a.owner_id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("LedgerRepo.GetAccountBalances - r.Builder.Select: %v", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("LedgerRepo.GetAccountBalances - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var balances []ledger.AccountBalance
+	for rows.Next() {
+		var balance ledger.AccountBalance
+		err = rows.Scan(
+			&balance.Account.Type,
+			&balance.Account.OwnerID,
+			&balance.Balance,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("LedgerRepo.GetAccountBalances - rows.Next: %v", err)
+		}
+		balances = append(balances, balance)
+	}
+
+	return balances, nil
+}
+
+func (r *LedgerRepo) GetWalletBalances(ctx context.Context) (map[int]int64, error) {
+	sql, args, err := r.Builder.
+		Select("id", "ROUND(balance * 100)::BIGINT").
+		From("users").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("LedgerRepo.GetWalletBalances - r.Builder.Select: %v", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("LedgerRepo.GetWalletBalances - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	balances := make(map[int]int64)
+	for rows.Next() {
+		var (
+			userId  int
+			balance int64
+		)
+		if err = rows.Scan(&userId, &balance); err != nil {
+			return nil, fmt.Errorf("LedgerRepo.GetWalletBalances - rows.Next: %v", err)
+		}
+		balances[userId] = balance
+	}
+
+	return balances, nil
+}
+
+// postEntry записывает журнальную запись и ее проводки в рамках переданной
+// транзакции, заводя недостающие счета.
+func postEntry(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, entry ledger.Entry) error {
+	if err := entry.Validate(); err != nil {
+		return fmt.Errorf("postEntry - entry.Validate: %w", err)
+	}
+
+	sql, args, err := builder.
+		Insert("ledger_entries").
+		Columns("kind", "reference_id").
+		Values(entry.Kind, entry.ReferenceID).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("postEntry - builder.Insert: %v", err)
+	}
+
+	var entryId int
+	if err = tx.QueryRow(ctx, sql, args...).Scan(&entryId); err != nil {
+		return fmt.Errorf("postEntry - tx.QueryRow: %v", err)
+	}
+
+	for _, posting := range entry.Postings {
+		accountId, err := ensureAccount(ctx, tx, builder, posting.Account)
+		if err != nil {
+			return err
+		}
+
+		sql, args, err = builder.
+			Insert("ledger_postings").
+			Columns("entry_id", "account_id", "amount").
+			Values(entryId, accountId, posting.Amount).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("postEntry - builder.Insert: %v", err)
+		}
+
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			return fmt.Errorf("postEntry - tx.Exec: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func ensureAccount(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, account ledger.Account) (int, error) {
+	sql, args, err := builder.
+		Insert("ledger_accounts").
+		Columns("type", "owner_id").
+		Values(account.Type, account.OwnerID).
+		Suffix("ON CONFLICT (type, owner_id) DO UPDATE SET type = EXCLUDED.type RETURNING id").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("ensureAccount - builder.Insert: %v", err)
+	}
+
+	var id int
+	if err = tx.QueryRow(ctx, sql, args...).Scan(&id); err != nil {
+		return 0, fmt.Errorf("ensureAccount - tx.QueryRow: %v", err)
+	}
+
+	return id, nil
+}
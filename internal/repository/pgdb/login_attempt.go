@@ -0,0 +1,121 @@
+package pgdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/pkg/postgres"
+)
+
+type LoginAttemptRepo struct {
+	*postgres.Postgres
+}
+
+func NewLoginAttemptRepo(pg *postgres.Postgres) *LoginAttemptRepo {
+	return &LoginAttemptRepo{pg}
+}
+
+// ReserveLoginAttempt заранее засчитывает попытку входа неудачной и
+// возвращает счетчик, каким он был до нее. Строка счетчика блокируется на
+// время обновления, поэтому параллельные попытки получают разные значения.
+// Если последняя неудача была раньше resetBefore, счет начинается заново.
+func (r *LoginAttemptRepo) ReserveLoginAttempt(ctx context.Context, key string, at, resetBefore time.Time) (entity.LoginAttempt, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return entity.LoginAttempt{}, fmt.Errorf("LoginAttemptRepo.ReserveLoginAttempt - r.Pool.Begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	sql, args, err := r.Builder.
+		Insert("login_attempts").
+		Columns("key", "failures", "last_failure_at").
+		Values(key, 0, at).
+		Suffix("ON CONFLICT (key) DO NOTHING").
+		ToSql()
+	if err != nil {
+		return entity.LoginAttempt{}, fmt.Errorf("LoginAttemptRepo.ReserveLoginAttempt - r.Builder.Insert: %v", err)
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		return entity.LoginAttempt{}, fmt.Errorf("LoginAttemptRepo.ReserveLoginAttempt - tx.Exec: %v", err)
+	}
+
+	sql, args, err = r.Builder.
+		Select("key", "failures", "last_failure_at").
+		From("login_attempts").
+		Where("key = ?", key).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return entity.LoginAttempt{}, fmt.Errorf("LoginAttemptRepo.ReserveLoginAttempt - r.Builder.Select: %v", err)
+	}
+
+	var previous entity.LoginAttempt
+	err = tx.QueryRow(ctx, sql, args...).Scan(
+		&previous.Key,
+		&previous.Failures,
+		&previous.LastFailureAt,
+	)
+	if err != nil {
+		return entity.LoginAttempt{}, fmt.Errorf("LoginAttemptRepo.ReserveLoginAttempt - tx.QueryRow: %v", err)
+	}
+
+	sql, args, err = r.Builder.
+		Update("login_attempts").
+		Set("failures", squirrel.Expr("CASE WHEN last_failure_at < ? THEN 1 ELSE failures + 1 END", resetBefore)).
+		Set("last_failure_at", at).
+		Where("key = ?", key).
+		ToSql()
+	if err != nil {
+		return entity.LoginAttempt{}, fmt.Errorf("LoginAttemptRepo.ReserveLoginAttempt - r.Builder.Update: %v", err)
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		return entity.LoginAttempt{}, fmt.Errorf("LoginAttemptRepo.ReserveLoginAttempt - tx.Exec: %v", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return entity.LoginAttempt{}, fmt.Errorf("LoginAttemptRepo.ReserveLoginAttempt - tx.Commit: %v", err)
+	}
+
+	return previous, nil
+}
+
+// ReleaseLoginAttempt снимает одну засчитанную заранее неудачу, если попытка
+// оказалась успешной.
+func (r *LoginAttemptRepo) ReleaseLoginAttempt(ctx context.Context, key string) error {
+	sql, args, err := r.Builder.
+		Update("login_attempts").
+		Set("failures", squirrel.Expr("GREATEST(failures - 1, 0)")).
+		Where("key = ?", key).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("LoginAttemptRepo.ReleaseLoginAttempt - r.Builder.Update: %v", err)
+	}
+
+	if _, err = r.Pool.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("LoginAttemptRepo.ReleaseLoginAttempt - r.Pool.Exec: %v", err)
+	}
+
+	return nil
+}
+
+func (r *LoginAttemptRepo) ResetLoginAttempts(ctx context.Context, key string) error {
+	sql, args, err := r.Builder.
+		Delete("login_attempts").
+		Where("key = ?", key).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("LoginAttemptRepo.ResetLoginAttempts - r.Builder.Delete: %v", err)
+	}
+
+	if _, err = r.Pool.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("LoginAttemptRepo.ResetLoginAttempts - r.Pool.Exec: %v", err)
+	}
+
+	return nil
+}
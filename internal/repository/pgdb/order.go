@@ -0,0 +1,265 @@
+package pgdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/ledger"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/pkg/postgres"
+)
+
+type OrderRepo struct {
+	*postgres.Postgres
+}
+
+func NewOrderRepo(pg *postgres.Postgres) *OrderRepo {
+	return &OrderRepo{pg}
+}
+
+func (r *OrderRepo) GetOrder(ctx context.Context, orderId int) (entity.Order, error) {
+	sql, args, err := r.Builder.
+		Select(orderColumns...).
+		From("orders o").
+		Join("order_items oi ON oi.order_id = o.id").
+		Where("o.id = ?", orderId).
+		OrderBy("oi.id").
+		ToSql()
+	if err != nil {
+		return entity.Order{}, fmt.Errorf("OrderRepo.GetOrder - r.Builder.Select: %v", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return entity.Order{}, fmt.Errorf("OrderRepo.GetOrder - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	orders, err := scanOrders(rows)
+	if err != nil {
+		return entity.Order{}, fmt.Errorf("OrderRepo.GetOrder - scanOrders: %v", err)
+	}
+	if len(orders) == 0 {
+		return entity.Order{}, repoerrs.ErrNotFound
+	}
+
+	return orders[0], nil
+}
+
+func (r *OrderRepo) GetOrderHistory(ctx context.Context, orderId int) ([]entity.OrderStatusChange, error) {
+	sql, args, err := r.Builder.
+		Select("id", "order_id", "COALESCE(from_status, '')", "to_status", "COALESCE(actor_id, 0)", "reason", "created_at").
+		From("order_status_history").
+		Where("order_id = ?", orderId).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("OrderRepo.GetOrderHistory - r.Builder.Select: %v", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("OrderRepo.GetOrderHistory - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var history []entity.OrderStatusChange
+	for rows.Next() {
+		var change entity.OrderStatusChange
+		err = rows.Scan(
+			&change.ID,
+			&change.OrderID,
+			&change.From,
+			&change.To,
+			&change.ActorID,
+			&change.Reason,
+			&change.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("OrderRepo.GetOrderHistory - rows.Scan: %v", err)
+		}
+		history = append(history, change)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("OrderRepo.GetOrderHistory - rows.Err: %v", err)
+	}
+
+	return history, nil
+}
+
+// UpdateOrderStatus переводит заказ из change.From в change.To и пишет
+// историю. Если статус заказа уже не change.From, возвращает ErrConflict.
+func (r *OrderRepo) UpdateOrderStatus(ctx context.Context, change entity.OrderStatusChange) error {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("OrderRepo.UpdateOrderStatus - r.Pool.Begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = lockOrder(ctx, tx, r.Builder, change); err != nil {
+		return err
+	}
+
+	if err = setOrderStatus(ctx, tx, r.Builder, change); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("OrderRepo.UpdateOrderStatus - tx.Commit: %v", err)
+	}
+
+	return nil
+}
+
+// CancelOrder отменяет заказ: возвращает товары на склады, с которых они
+// были отгружены, и, если refund, возвращает покупателю оплату обратной
+// проводкой. Единицы, уже возвращенные через CreateRefund, не учитываются
+// повторно, а товары, удаленные после покупки, пропускаются. Если статус
+// заказа уже не change.From, возвращает ErrConflict.
+func (r *OrderRepo) CancelOrder(ctx context.Context, change entity.OrderStatusChange, refund bool) error {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("OrderRepo.CancelOrder - r.Pool.Begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	order, err := lockOrder(ctx, tx, r.Builder, change)
+	if err != nil {
+		return err
+	}
+
+	if err = setOrderStatus(ctx, tx, r.Builder, change); err != nil {
+		return err
+	}
+
+	refunded, err := refundedQuantities(ctx, tx, r.Builder, order.ID)
+	if err != nil {
+		return err
+	}
+
+	var (
+		lines []entity.OrderItem
+		total int64
+	)
+	for _, item := range order.Items {
+		item.Quantity -= refunded[item.ID]
+		if item.Quantity <= 0 {
+			continue
+		}
+
+		err = returnStock(ctx, tx, r.Builder, item.ID, entity.InventoryMovement{
+			ProductID: item.ProductID,
+			Kind:      entity.InventoryMovementRefund,
+			Delta:     item.Quantity,
+			ActorID:   change.ActorID,
+			Reason:    change.Reason,
+			OrderID:   order.ID,
+		})
+		if err != nil {
+			return err
+		}
+		lines = append(lines, item)
+		total += ledger.ToMinor(item.UnitPrice) * int64(item.Quantity)
+	}
+
+	if refund && total > 0 {
+		if _, err = creditBalance(ctx, tx, r.Builder, order.UserID, ledger.FromMinor(total)); err != nil {
+			return err
+		}
+
+		entry := orderEntry(order.ID, order.UserID, lines).Reverse(ledger.EntryOrderCancel, order.ID)
+		if err = postEntry(ctx, tx, r.Builder, entry); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("OrderRepo.CancelOrder - tx.Commit: %v", err)
+	}
+
+	return nil
+}
+
+// lockOrder блокирует заказ change.OrderID и проверяет, что он все еще в
+// статусе change.From.
+func lockOrder(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, change entity.OrderStatusChange) (entity.Order, error) {
+	sql, args, err := builder.
+		Select(orderColumns...).
+		From("orders o").
+		Join("order_items oi ON oi.order_id = o.id").
+		Where("o.id = ?", change.OrderID).
+		OrderBy("oi.product_id").
+		Suffix("FOR UPDATE OF o").
+		ToSql()
+	if err != nil {
+		return entity.Order{}, fmt.Errorf("lockOrder - builder.Select: %v", err)
+	}
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return entity.Order{}, fmt.Errorf("lockOrder - tx.Query: %v", err)
+	}
+	orders, err := scanOrders(rows)
+	rows.Close()
+	if err != nil {
+		return entity.Order{}, fmt.Errorf("lockOrder - scanOrders: %v", err)
+	}
+
+	if len(orders) == 0 {
+		return entity.Order{}, repoerrs.ErrNotFound
+	}
+	if orders[0].Status != change.From {
+		return entity.Order{}, repoerrs.ErrConflict
+	}
+
+	return orders[0], nil
+}
+
+func setOrderStatus(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, change entity.OrderStatusChange) error {
+	sql, args, err := builder.
+		Update("orders").
+		Set("status", change.To).
+		Where("id = ?", change.OrderID).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("setOrderStatus - builder.Update: %v", err)
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("setOrderStatus - tx.Exec: %v", err)
+	}
+
+	return insertStatusChange(ctx, tx, builder, change)
+}
+
+func insertStatusChange(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, change entity.OrderStatusChange) error {
+	var from *entity.OrderStatus
+	if change.From != "" {
+		from = &change.From
+	}
+
+	sql, args, err := builder.
+		Insert("order_status_history").
+		Columns("order_id", "from_status", "to_status", "actor_id", "reason").
+		Values(
+			change.OrderID,
+			from,
+			change.To,
+			nullableId(change.ActorID),
+			change.Reason,
+		).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("insertStatusChange - builder.Insert: %v", err)
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("insertStatusChange - tx.Exec: %v", err)
+	}
+
+	return nil
+}
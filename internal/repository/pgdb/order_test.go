@@ -0,0 +1,90 @@
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cripplemymind9/go-market/internal/allocation"
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+)
+
+func TestOrderRepo_CancelOrder_RestoresStockAndBalance(t *testing.T) {
+	pg := newTestPostgres(t)
+	ctx := context.Background()
+
+	productRepo := NewProductRepo(pg)
+	orderRepo := NewOrderRepo(pg)
+
+	productId, err := productRepo.AddProduct(ctx, entity.Product{
+		Name:        "cancel test product",
+		Description: "cancel test product",
+		Price:       4,
+		Quantity:    5,
+	})
+	if err != nil {
+		t.Fatalf("AddProduct() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = productRepo.DeleteProduct(ctx, productId)
+	})
+
+	buyerID := newTestBuyer(t, pg, 20)
+
+	orderId, err := NewPurchaseRepo(pg, allocation.Priority{}).CreateOrder(ctx, entity.Order{
+		UserID: buyerID,
+		Items:  []entity.OrderItem{{ProductID: productId, Quantity: 3}},
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder() error = %v", err)
+	}
+
+	change := entity.OrderStatusChange{
+		OrderID: orderId,
+		From:    entity.OrderStatusPaid,
+		To:      entity.OrderStatusCancelled,
+		ActorID: buyerID,
+		Reason:  "changed my mind",
+	}
+	if err = orderRepo.CancelOrder(ctx, change, true); err != nil {
+		t.Fatalf("CancelOrder() error = %v", err)
+	}
+
+	// Повторная отмена уже не застает заказ оплаченным.
+	if err = orderRepo.CancelOrder(ctx, change, true); !errors.Is(err, repoerrs.ErrConflict) {
+		t.Errorf("CancelOrder() second call error = %v, want %v", err, repoerrs.ErrConflict)
+	}
+
+	product, err := productRepo.GetProductById(ctx, productId)
+	if err != nil {
+		t.Fatalf("GetProductById() error = %v", err)
+	}
+	if product.Quantity != 5 {
+		t.Errorf("product quantity = %d, want 5", product.Quantity)
+	}
+
+	balance, err := NewWalletRepo(pg).GetBalance(ctx, buyerID)
+	if err != nil {
+		t.Fatalf("GetBalance() error = %v", err)
+	}
+	if balance != 20 {
+		t.Errorf("buyer balance = %v, want 20", balance)
+	}
+
+	order, err := orderRepo.GetOrder(ctx, orderId)
+	if err != nil {
+		t.Fatalf("GetOrder() error = %v", err)
+	}
+	if order.Status != entity.OrderStatusCancelled {
+		t.Errorf("order status = %q, want %q", order.Status, entity.OrderStatusCancelled)
+	}
+
+	history, err := orderRepo.GetOrderHistory(ctx, orderId)
+	if err != nil {
+		t.Fatalf("GetOrderHistory() error = %v", err)
+	}
+	if len(history) != 2 || history[0].To != entity.OrderStatusPaid || history[1].From != entity.OrderStatusPaid || history[1].Reason != "changed my mind" {
+		t.Errorf("GetOrderHistory() = %+v, want paid then cancelled", history)
+	}
+}
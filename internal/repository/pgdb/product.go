@@ -13,6 +13,9 @@ import (
 	"github.com/cripplemymind9/go-market/pkg/postgres"
 )
 
+// productColumns перечисляет колонки products в порядке сканирования в entity.Product.
+var productColumns = []string{"id", "name", "description", "price", "quantity", "reserved", "COALESCE(seller_id, 0)"}
+
 type ProductRepo struct {
 	*postgres.Postgres
 }
@@ -21,15 +24,24 @@ func NewProductRepo(pg *postgres.Postgres) *ProductRepo {
 	return &ProductRepo{pg}
 }
 
+// AddProduct добавляет товар. Начальный остаток записывается движением
+// restock от имени продавца.
 func (r *ProductRepo) AddProduct(ctx context.Context, product entity.Product) (int, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("ProductRepo.AddProduct - r.Pool.Begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
 	sql, args, err := r.Builder.
 		Insert("products").
-		Columns("name", "description", "price", "quantity").
+		Columns("name", "description", "price", "quantity", "seller_id").
 		Values(
 			product.Name,
 			product.Description,
 			product.Price,
-			product.Quantity,
+			0,
+			nullableId(product.SellerID),
 		).
 		Suffix("RETURNING id").
 		ToSql()
@@ -38,15 +50,36 @@ func (r *ProductRepo) AddProduct(ctx context.Context, product entity.Product) (i
 	}
 
 	var id int
-	err = r.Pool.QueryRow(ctx, sql, args...).Scan(&id)
+	err = tx.QueryRow(ctx, sql, args...).Scan(&id)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if ok := errors.As(err, &pgErr); ok {
-			if pgErr.Code == "23505" {
+			switch pgErr.Code {
+			case "23505":
 				return 0, repoerrs.ErrAlreadyExists
+			case "23514":
+				// Цена, округленная до копеек, оказалась нулевой.
+				return 0, repoerrs.ErrInvalidPrice
 			}
 		}
-		return 0, fmt.Errorf("ProductRepo.AddProduct - r.Pool.QueryRow: %v", err)
+		return 0, fmt.Errorf("ProductRepo.AddProduct - tx.QueryRow: %v", err)
+	}
+
+	if product.Quantity != 0 {
+		_, err = moveStock(ctx, tx, r.Builder, entity.InventoryMovement{
+			ProductID: id,
+			Kind:      entity.InventoryMovementRestock,
+			Delta:     product.Quantity,
+			ActorID:   product.SellerID,
+			Reason:    "initial stock",
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("ProductRepo.AddProduct - tx.Commit: %v", err)
 	}
 
 	return id, nil
@@ -54,7 +87,7 @@ func (r *ProductRepo) AddProduct(ctx context.Context, product entity.Product) (i
 
 func (r *ProductRepo) GetAllProducts(ctx context.Context) ([]entity.Product, error) {
 	sql, args, err := r.Builder.
-		Select("*").
+		Select(productColumns...).
 		From("products").
 		ToSql()
 	if err != nil {
@@ -76,6 +109,8 @@ func (r *ProductRepo) GetAllProducts(ctx context.Context) ([]entity.Product, err
 			&product.Description,
 			&product.Price,
 			&product.Quantity,
+			&product.Reserved,
+			&product.SellerID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("ProductRepo.GetAllProducts - rows.Next: %v", err)
@@ -86,9 +121,47 @@ func (r *ProductRepo) GetAllProducts(ctx context.Context) ([]entity.Product, err
 	return products, nil
 }
 
+func (r *ProductRepo) GetProductsBySeller(ctx context.Context, sellerId int) ([]entity.Product, error) {
+	sql, args, err := r.Builder.
+		Select(productColumns...).
+		From("products").
+		Where("seller_id = ?", sellerId).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ProductRepo.GetProductsBySeller - r.Builder.Select: %v", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ProductRepo.GetProductsBySeller - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var products []entity.Product
+	for rows.Next() {
+		var product entity.Product
+		err = rows.Scan(
+			&product.ID,
+			&product.Name,
+			&product.Description,
+			&product.Price,
+			&product.Quantity,
+			&product.Reserved,
+			&product.SellerID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ProductRepo.GetProductsBySeller - rows.Next: %v", err)
+		}
+		products = append(products, product)
+	}
+
+	return products, nil
+}
+
 func (r *ProductRepo) GetProductById(ctx context.Context, productId int) (entity.Product, error) {
 	sql, args, err := r.Builder.
-		Select("*").
+		Select(productColumns...).
 		From("products").
 		Where("id = ?", productId).
 		ToSql()
@@ -103,6 +176,8 @@ func (r *ProductRepo) GetProductById(ctx context.Context, productId int) (entity
 		&product.Description,
 		&product.Price,
 		&product.Quantity,
+		&product.Reserved,
+		&product.SellerID,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -120,7 +195,6 @@ func (r *ProductRepo) UpdateProduct(ctx context.Context, product entity.Product)
 		Set("name", product.Name).
 		Set("description", product.Description).
 		Set("price", product.Price).
-		Set("quantity", product.Quantity).
 		Where("id = ?", product.ID).
 		ToSql()
 	if err != nil {
@@ -128,12 +202,54 @@ func (r *ProductRepo) UpdateProduct(ctx context.Context, product entity.Product)
 	}
 
 	if _, err = r.Pool.Exec(ctx, sql, args...); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23514" {
+			return repoerrs.ErrInvalidPrice
+		}
 		return fmt.Errorf("ProductRepo.UpdateProduct - r.Pool.Exec: %v", err)
 	}
 
 	return nil
 }
 
+// SetReorderThreshold задает порог дозаказа товара; nil снимает порог. Текущий
+// остаток сразу сверяется с новым порогом.
+func (r *ProductRepo) SetReorderThreshold(ctx context.Context, productId int, threshold *int) error {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ProductRepo.SetReorderThreshold - r.Pool.Begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	sql, args, err := r.Builder.
+		Update("products").
+		Set("reorder_threshold", threshold).
+		Where("id = ?", productId).
+		Suffix("RETURNING quantity").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ProductRepo.SetReorderThreshold - r.Builder.Update: %v", err)
+	}
+
+	var quantity int
+	if err = tx.QueryRow(ctx, sql, args...).Scan(&quantity); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repoerrs.ErrNotFound
+		}
+		return fmt.Errorf("ProductRepo.SetReorderThreshold - tx.QueryRow: %v", err)
+	}
+
+	if err = checkReorderThreshold(ctx, tx, r.Builder, productId, quantity, threshold); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ProductRepo.SetReorderThreshold - tx.Commit: %v", err)
+	}
+
+	return nil
+}
+
 func (r *ProductRepo) DeleteProduct(ctx context.Context, productId int) error {
 	sql, args, err := r.Builder.
 		Delete("products").
@@ -149,3 +265,11 @@ func (r *ProductRepo) DeleteProduct(ctx context.Context, productId int) error {
 
 	return nil
 }
+
+// nullableId превращает нулевой идентификатор в NULL.
+func nullableId(id int) *int {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
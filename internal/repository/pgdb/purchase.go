@@ -4,144 +4,335 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/Masterminds/squirrel"
-	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5"
 
+	"github.com/cripplemymind9/go-market/internal/allocation"
 	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/ledger"
 	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
 	"github.com/cripplemymind9/go-market/pkg/postgres"
 )
 
+// orderColumns перечисляет колонки заказа и его строки в порядке сканирования
+// в scanOrders.
+var orderColumns = []string{
+	"o.id", "o.user_id", "o.status", "o.total", "o.created_at",
+	"oi.id", "oi.product_id", "oi.product_name", "COALESCE(oi.seller_id, 0)", "oi.unit_price", "oi.quantity",
+}
+
 type PurchaseRepo struct {
 	*postgres.Postgres
+	strategy allocation.Strategy
 }
 
-func NewPurchaseRepo(pg *postgres.Postgres) *PurchaseRepo {
-	return &PurchaseRepo{pg}
+func NewPurchaseRepo(pg *postgres.Postgres, strategy allocation.Strategy) *PurchaseRepo {
+	return &PurchaseRepo{pg, strategy}
 }
 
-func (r *PurchaseRepo) MakePurchase(ctx context.Context, purchase entity.Purchase) (int, error) {
+// CreateOrder оформляет заказ из order.Items, где заданы только товар и
+// количество. Все строки покупаются в одной транзакции: если хотя бы одну
+// купить нельзя, не покупается ничего.
+func (r *PurchaseRepo) CreateOrder(ctx context.Context, order entity.Order) (int, error) {
 	tx, err := r.Pool.Begin(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("PurchaseRepo.MakePurchase - r.Pool.Begin: %v", err)
+		return 0, fmt.Errorf("PurchaseRepo.CreateOrder - r.Pool.Begin: %v", err)
 	}
 	defer tx.Rollback(ctx)
 
-	sql, args, err := r.Builder.
-		Update("products").
-		Set("quantity", squirrel.Expr("quantity - ?", purchase.Quantity)).
-		Where("id = ?", purchase.ProductID).
-		ToSql()
+	id, err := createOrder(ctx, tx, r.Builder, r.strategy, order)
 	if err != nil {
-		return 0, fmt.Errorf("PurchaseRepo.MakePurchase - r.Builder..Update: %v", err)
+		return 0, err
 	}
 
-	_, err = tx.Exec(ctx, sql, args...)
+	err = tx.Commit(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("PurchaseRepo.MakePurchase - tx.Exec: %v", err)
+		return 0, fmt.Errorf("PurchaseRepo.CreateOrder - tx.Commit: %v", err)
 	}
 
-	sql, args, err = r.Builder.
-		Insert("purchases").
-		Columns("user_id", "product_id", "quantity").
-		Values(
-			purchase.UserID,
-			purchase.ProductID,
-			purchase.Quantity,
-		).
-		Suffix("RETURNING id").
+	return id, nil
+}
+
+func (r *PurchaseRepo) GetUserOrders(ctx context.Context, userId int) ([]entity.Order, error) {
+	sql, args, err := r.Builder.
+		Select(orderColumns...).
+		From("orders o").
+		Join("order_items oi ON oi.order_id = o.id").
+		Where("o.user_id = ?", userId).
+		OrderBy("o.id", "oi.id").
 		ToSql()
 	if err != nil {
-		return 0, fmt.Errorf("PurchaseRepo.MakePurchase - r.Builder.Insert:%v", err)
+		return nil, fmt.Errorf("PurchaseRepo.GetUserOrders - r.Builder.Select: %v", err)
 	}
 
-	var id int
-	err = tx.QueryRow(ctx, sql, args...).Scan(&id)
+	rows, err := r.Pool.Query(ctx, sql, args...)
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if ok := errors.As(err, &pgErr); ok {
-			if pgErr.Code == "23505" {
-				return 0, repoerrs.ErrAlreadyExists
-			}
-		}
-		return 0, fmt.Errorf("PurchaseRepo.MakePurchase - tx.QueryRow:%v", err)
+		return nil, fmt.Errorf("PurchaseRepo.GetUserOrders - r.Pool.Query: %v", err)
 	}
+	defer rows.Close()
 
-	err = tx.Commit(ctx)
+	orders, err := scanOrders(rows)
 	if err != nil {
-		return 0, fmt.Errorf("PurchaseRepo.MakePurchase - tx.Commit:%v", err)
+		return nil, fmt.Errorf("PurchaseRepo.GetUserOrders - scanOrders: %v", err)
 	}
 
-	return id, nil
+	return orders, nil
 }
 
-func (r *PurchaseRepo) GetUserPurchases(ctx context.Context, userId int) ([]entity.Purchase, error) {
+// GetProductOrders возвращает заказы, в которые входит товар. Из строк заказа
+// возвращаются только строки этого товара.
+func (r *PurchaseRepo) GetProductOrders(ctx context.Context, productId int) ([]entity.Order, error) {
 	sql, args, err := r.Builder.
-		Select("*").
-		From("purchases").
-		Where("user_id = ?", userId).
+		Select(orderColumns...).
+		From("orders o").
+		Join("order_items oi ON oi.order_id = o.id").
+		Where("oi.product_id = ?", productId).
+		OrderBy("o.id", "oi.id").
 		ToSql()
 	if err != nil {
-		return nil, fmt.Errorf("PurchaseRepo.GetUserPurchases - r.Builder.Select:%v", err)
+		return nil, fmt.Errorf("PurchaseRepo.GetProductOrders - r.Builder.Select: %v", err)
 	}
 
 	rows, err := r.Pool.Query(ctx, sql, args...)
 	if err != nil {
-		return nil, fmt.Errorf("PurchaseRepo.GetUserPurchases - r.Pool.Query:%v", err)
+		return nil, fmt.Errorf("PurchaseRepo.GetProductOrders - r.Pool.Query: %v", err)
 	}
 	defer rows.Close()
 
-	var purchases []entity.Purchase
-	for rows.Next() {
-		var purchase entity.Purchase
-		err = rows.Scan(
-			&purchase.ID,
-			&purchase.UserID,
-			&purchase.ProductID,
-			&purchase.Quantity,
-			&purchase.Timestamp,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("PurchaseRepo.GetUserPurchases - rows.Next:%v", err)
-		}
-		purchases = append(purchases, purchase)
+	orders, err := scanOrders(rows)
+	if err != nil {
+		return nil, fmt.Errorf("PurchaseRepo.GetProductOrders - scanOrders: %v", err)
 	}
 
-	return purchases, nil
+	return orders, nil
 }
 
-func (r *PurchaseRepo) GetProductPurchases(ctx context.Context, productId int) ([]entity.Purchase, error) {
+func (r *PurchaseRepo) GetSellerSales(ctx context.Context, sellerId int) ([]entity.ProductSales, error) {
 	sql, args, err := r.Builder.
-		Select("*").
-		From("purchases").
-		Where("product_id = ?", productId).
+		Select(
+			"pr.id",
+			"pr.name",
+			"COALESCE(SUM(oi.quantity), 0)",
+			"COALESCE(SUM(oi.quantity * oi.unit_price), 0)",
+		).
+		From("products pr").
+		LeftJoin("order_items oi ON oi.product_id = pr.id").
+		Where("pr.seller_id = ?", sellerId).
+		GroupBy("pr.id", "pr.name").
+		OrderBy("pr.id").
 		ToSql()
 	if err != nil {
-		return nil, fmt.Errorf("PurchaseRepo.GetProductPurchases - r.Builder.Select:%v", err)
+		return nil, fmt.Errorf("PurchaseRepo.GetSellerSales - r.Builder.Select: %v", err)
 	}
 
 	rows, err := r.Pool.Query(ctx, sql, args...)
 	if err != nil {
-		return nil, fmt.Errorf("PurchaseRepo.GetProductPurchases - r.Pool.Query:%v", err)
+		return nil, fmt.Errorf("PurchaseRepo.GetSellerSales - r.Pool.Query: %v", err)
 	}
 	defer rows.Close()
 
-	var purchases []entity.Purchase
+	var sales []entity.ProductSales
 	for rows.Next() {
-		var purchase entity.Purchase
+		var s entity.ProductSales
 		err = rows.Scan(
-			&purchase.ID,
-			&purchase.UserID,
-			&purchase.ProductID,
-			&purchase.Quantity,
-			&purchase.Timestamp,
+			&s.ProductID,
+			&s.ProductName,
+			&s.UnitsSold,
+			&s.Revenue,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("PurchaseRepo.GetSellerSales - rows.Next: %v", err)
+		}
+		sales = append(sales, s)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("PurchaseRepo.GetSellerSales - rows.Err: %v", err)
+	}
+
+	return sales, nil
+}
+
+// scanOrders собирает заказы из строк orderColumns, упорядоченных по id заказа.
+func scanOrders(rows pgx.Rows) ([]entity.Order, error) {
+	var orders []entity.Order
+	for rows.Next() {
+		var (
+			order entity.Order
+			item  entity.OrderItem
+		)
+		err := rows.Scan(
+			&order.ID,
+			&order.UserID,
+			&order.Status,
+			&order.Total,
+			&order.CreatedAt,
+			&item.ID,
+			&item.ProductID,
+			&item.ProductName,
+			&item.SellerID,
+			&item.UnitPrice,
+			&item.Quantity,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("PurchaseRepo.GetProductPurchases - rows.Next:%v", err)
+			return nil, err
+		}
+		item.OrderID = order.ID
+
+		if n := len(orders); n > 0 && orders[n-1].ID == order.ID {
+			orders[n-1].Items = append(orders[n-1].Items, item)
+			continue
+		}
+		order.Items = []entity.OrderItem{item}
+		orders = append(orders, order)
+	}
+
+	return orders, rows.Err()
+}
+
+// createOrder в рамках переданной транзакции блокирует товары заказа,
+// проверяет не забронированные остатки, списывает итог с баланса покупателя и
+// записывает оплаченный заказ со снимком цен вместе с проводкой в журнале и
+// движениями товара. Склады отгрузки каждой строки выбирает strategy. Товары
+// блокируются по возрастанию id, чтобы встречные заказы не
+// взаимоблокировались. Возвращает id заказа.
+func createOrder(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, strategy allocation.Strategy, order entity.Order) (int, error) {
+	items := make([]entity.OrderItem, len(order.Items))
+	copy(items, order.Items)
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].ProductID < items[j].ProductID
+	})
+
+	var total int64
+	for i := range items {
+		sql, args, err := builder.
+			Select("name", "quantity - reserved", "price", "COALESCE(seller_id, 0)").
+			From("products").
+			Where("id = ?", items[i].ProductID).
+			Suffix("FOR UPDATE").
+			ToSql()
+		if err != nil {
+			return 0, fmt.Errorf("createOrder - builder.Select: %v", err)
 		}
-		purchases = append(purchases, purchase)
+
+		var available int
+		err = tx.QueryRow(ctx, sql, args...).Scan(&items[i].ProductName, &available, &items[i].UnitPrice, &items[i].SellerID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return 0, repoerrs.ErrNotFound
+			}
+			return 0, fmt.Errorf("createOrder - tx.QueryRow: %v", err)
+		}
+
+		if available < items[i].Quantity {
+			return 0, repoerrs.ErrNotEnoughStock
+		}
+
+		total += ledger.ToMinor(items[i].UnitPrice) * int64(items[i].Quantity)
+	}
+
+	if _, err := debitBalance(ctx, tx, builder, order.UserID, ledger.FromMinor(total)); err != nil {
+		return 0, err
+	}
+
+	sql, args, err := builder.
+		Insert("orders").
+		Columns("user_id", "status", "total").
+		Values(order.UserID, entity.OrderStatusPaid, ledger.FromMinor(total)).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("createOrder - builder.Insert: %v", err)
+	}
+
+	var id int
+	if err = tx.QueryRow(ctx, sql, args...).Scan(&id); err != nil {
+		return 0, fmt.Errorf("createOrder - tx.QueryRow: %v", err)
+	}
+
+	order.ID = id
+	for _, item := range items {
+		sql, args, err = builder.
+			Insert("order_items").
+			Columns("order_id", "product_id", "product_name", "seller_id", "unit_price", "quantity").
+			Values(
+				id,
+				item.ProductID,
+				item.ProductName,
+				nullableId(item.SellerID),
+				item.UnitPrice,
+				item.Quantity,
+			).
+			Suffix("RETURNING id").
+			ToSql()
+		if err != nil {
+			return 0, fmt.Errorf("createOrder - builder.Insert: %v", err)
+		}
+
+		if err = tx.QueryRow(ctx, sql, args...).Scan(&item.ID); err != nil {
+			return 0, fmt.Errorf("createOrder - tx.QueryRow: %v", err)
+		}
+
+		if err = shipStock(ctx, tx, builder, strategy, item, order); err != nil {
+			return 0, err
+		}
+	}
+
+	err = insertStatusChange(ctx, tx, builder, entity.OrderStatusChange{
+		OrderID: id,
+		To:      entity.OrderStatusPaid,
+		ActorID: order.UserID,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if total == 0 {
+		return id, nil
+	}
+
+	if err = postEntry(ctx, tx, builder, orderEntry(id, order.UserID, items)); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// orderEntry строит одну проводку на весь заказ: итог списывается с кошелька
+// покупателя и делится между продавцами строк, а выручка товаров без продавца
+// достается площадке.
+func orderEntry(orderId, userId int, items []entity.OrderItem) ledger.Entry {
+	var (
+		total   int64
+		payees  []ledger.Account
+		amounts = make(map[ledger.Account]int64)
+	)
+	for _, item := range items {
+		amount := ledger.ToMinor(item.UnitPrice) * int64(item.Quantity)
+		if amount == 0 {
+			continue
+		}
+
+		payee := ledger.MarketplaceRevenue()
+		if item.SellerID != 0 {
+			payee = ledger.SellerPayout(item.SellerID)
+		}
+		if _, ok := amounts[payee]; !ok {
+			payees = append(payees, payee)
+		}
+		amounts[payee] += amount
+		total += amount
+	}
+
+	entry := ledger.Entry{
+		Kind:        ledger.EntryPurchase,
+		ReferenceID: orderId,
+		Postings:    []ledger.Posting{{Account: ledger.UserWallet(userId), Amount: total}},
+	}
+	for _, payee := range payees {
+		entry.Postings = append(entry.Postings, ledger.Posting{Account: payee, Amount: -amounts[payee]})
 	}
 
-	return purchases, nil
+	return entry
 }
@@ -0,0 +1,317 @@
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cripplemymind9/go-market/internal/allocation"
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/ledger"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/pkg/postgres"
+)
+
+// newTestPostgres подключается к тестовой базе, указанной в TEST_PG_URL.
+// База должна быть предварительно мигрирована.
+func newTestPostgres(t *testing.T) *postgres.Postgres {
+	t.Helper()
+
+	url, ok := os.LookupEnv("TEST_PG_URL")
+	if !ok || url == "" {
+		t.Skip("TEST_PG_URL is not set")
+	}
+
+	pg, err := postgres.New(url, postgres.MaxPoolSize(20), postgres.ConnAttempts(1), postgres.ConnTimeout(0))
+	if err != nil {
+		t.Fatalf("postgres.New() error = %v", err)
+	}
+	t.Cleanup(pg.Close)
+
+	return pg
+}
+
+// newTestBuyer регистрирует покупателя с уникальным именем и пополняет его кошелек.
+func newTestBuyer(t *testing.T, pg *postgres.Postgres, balance float64) int {
+	t.Helper()
+	ctx := context.Background()
+
+	username := fmt.Sprintf("buyer-%d", time.Now().UnixNano())
+	userId, err := NewUserRepo(pg).RegisterUser(ctx, entity.User{
+		Username: username,
+		Password: "password",
+		Email:    username + "@example.com",
+	})
+	if err != nil {
+		t.Fatalf("RegisterUser() error = %v", err)
+	}
+
+	if balance > 0 {
+		if _, err = NewWalletRepo(pg).Deposit(ctx, userId, balance); err != nil {
+			t.Fatalf("Deposit() error = %v", err)
+		}
+	}
+
+	return userId
+}
+
+func TestPurchaseRepo_CreateOrder_Concurrent(t *testing.T) {
+	pg := newTestPostgres(t)
+	ctx := context.Background()
+
+	const (
+		stock  = 10
+		buyers = 50
+		perBuy = 1
+	)
+
+	buyerID := newTestBuyer(t, pg, buyers)
+
+	productRepo := NewProductRepo(pg)
+	purchaseRepo := NewPurchaseRepo(pg, allocation.Priority{})
+
+	productId, err := productRepo.AddProduct(ctx, entity.Product{
+		Name:        "concurrency test product",
+		Description: "concurrency test product",
+		Price:       1,
+		Quantity:    stock,
+	})
+	if err != nil {
+		t.Fatalf("AddProduct() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = productRepo.DeleteProduct(ctx, productId)
+	})
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		ok       int
+		rejected int
+	)
+
+	for range buyers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := purchaseRepo.CreateOrder(ctx, entity.Order{
+				UserID: buyerID,
+				Items:  []entity.OrderItem{{ProductID: productId, Quantity: perBuy}},
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			switch {
+			case err == nil:
+				ok++
+			case errors.Is(err, repoerrs.ErrNotEnoughStock):
+				rejected++
+			default:
+				t.Errorf("CreateOrder() unexpected error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if ok != stock/perBuy {
+		t.Errorf("successful purchases = %d, want %d", ok, stock/perBuy)
+	}
+	if rejected != buyers-stock/perBuy {
+		t.Errorf("rejected purchases = %d, want %d", rejected, buyers-stock/perBuy)
+	}
+
+	product, err := productRepo.GetProductById(ctx, productId)
+	if err != nil {
+		t.Fatalf("GetProductById() error = %v", err)
+	}
+	if product.Quantity != 0 {
+		t.Errorf("product quantity = %d, want 0", product.Quantity)
+	}
+
+	balance, err := NewWalletRepo(pg).GetBalance(ctx, buyerID)
+	if err != nil {
+		t.Fatalf("GetBalance() error = %v", err)
+	}
+	if balance != buyers-stock {
+		t.Errorf("buyer balance = %v, want %v", balance, buyers-stock)
+	}
+
+	accounts, err := NewLedgerRepo(pg).GetAccountBalances(ctx)
+	if err != nil {
+		t.Fatalf("GetAccountBalances() error = %v", err)
+	}
+	for _, a := range accounts {
+		if a.Account == ledger.UserWallet(buyerID) && a.Balance != ledger.ToMinor(balance) {
+			t.Errorf("buyer ledger balance = %d, want %d", a.Balance, ledger.ToMinor(balance))
+		}
+	}
+}
+
+func TestPurchaseRepo_CreateOrder_NotEnoughStock(t *testing.T) {
+	pg := newTestPostgres(t)
+	ctx := context.Background()
+
+	productRepo := NewProductRepo(pg)
+	purchaseRepo := NewPurchaseRepo(pg, allocation.Priority{})
+
+	productId, err := productRepo.AddProduct(ctx, entity.Product{
+		Name:        "stock test product",
+		Description: "stock test product",
+		Price:       1,
+		Quantity:    2,
+	})
+	if err != nil {
+		t.Fatalf("AddProduct() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = productRepo.DeleteProduct(ctx, productId)
+	})
+
+	buyerID := newTestBuyer(t, pg, 100)
+
+	_, err = purchaseRepo.CreateOrder(ctx, entity.Order{UserID: buyerID, Items: []entity.OrderItem{{ProductID: productId, Quantity: 3}}})
+	if !errors.Is(err, repoerrs.ErrNotEnoughStock) {
+		t.Errorf("CreateOrder() error = %v, want %v", err, repoerrs.ErrNotEnoughStock)
+	}
+
+	product, err := productRepo.GetProductById(ctx, productId)
+	if err != nil {
+		t.Fatalf("GetProductById() error = %v", err)
+	}
+	if product.Quantity != 2 {
+		t.Errorf("product quantity = %d, want 2", product.Quantity)
+	}
+}
+
+func TestPurchaseRepo_CreateOrder_NotEnoughBalance(t *testing.T) {
+	pg := newTestPostgres(t)
+	ctx := context.Background()
+
+	productRepo := NewProductRepo(pg)
+	purchaseRepo := NewPurchaseRepo(pg, allocation.Priority{})
+
+	productId, err := productRepo.AddProduct(ctx, entity.Product{
+		Name:        "balance test product",
+		Description: "balance test product",
+		Price:       10,
+		Quantity:    5,
+	})
+	if err != nil {
+		t.Fatalf("AddProduct() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = productRepo.DeleteProduct(ctx, productId)
+	})
+
+	buyerID := newTestBuyer(t, pg, 15)
+
+	_, err = purchaseRepo.CreateOrder(ctx, entity.Order{UserID: buyerID, Items: []entity.OrderItem{{ProductID: productId, Quantity: 2}}})
+	if !errors.Is(err, repoerrs.ErrNotEnoughBalance) {
+		t.Errorf("CreateOrder() error = %v, want %v", err, repoerrs.ErrNotEnoughBalance)
+	}
+
+	product, err := productRepo.GetProductById(ctx, productId)
+	if err != nil {
+		t.Fatalf("GetProductById() error = %v", err)
+	}
+	if product.Quantity != 5 {
+		t.Errorf("product quantity = %d, want 5", product.Quantity)
+	}
+
+	balance, err := NewWalletRepo(pg).GetBalance(ctx, buyerID)
+	if err != nil {
+		t.Fatalf("GetBalance() error = %v", err)
+	}
+	if balance != 15 {
+		t.Errorf("buyer balance = %v, want 15", balance)
+	}
+}
+
+func TestPurchaseRepo_CreateOrder_ProductNotFound(t *testing.T) {
+	pg := newTestPostgres(t)
+	ctx := context.Background()
+
+	purchaseRepo := NewPurchaseRepo(pg, allocation.Priority{})
+
+	_, err := purchaseRepo.CreateOrder(ctx, entity.Order{UserID: 1, Items: []entity.OrderItem{{ProductID: -1, Quantity: 1}}})
+	if !errors.Is(err, repoerrs.ErrNotFound) {
+		t.Errorf("CreateOrder() error = %v, want %v", err, repoerrs.ErrNotFound)
+	}
+}
+
+func TestPurchaseRepo_CreateOrder_PriceSnapshot(t *testing.T) {
+	pg := newTestPostgres(t)
+	ctx := context.Background()
+
+	productRepo := NewProductRepo(pg)
+	purchaseRepo := NewPurchaseRepo(pg, allocation.Priority{})
+
+	var productIds []int
+	for _, price := range []float64{3, 0.5} {
+		productId, err := productRepo.AddProduct(ctx, entity.Product{
+			Name:        "order test product",
+			Description: "order test product",
+			Price:       price,
+			Quantity:    10,
+		})
+		if err != nil {
+			t.Fatalf("AddProduct() error = %v", err)
+		}
+		t.Cleanup(func() {
+			_ = productRepo.DeleteProduct(ctx, productId)
+		})
+		productIds = append(productIds, productId)
+	}
+
+	buyerID := newTestBuyer(t, pg, 100)
+
+	orderId, err := purchaseRepo.CreateOrder(ctx, entity.Order{
+		UserID: buyerID,
+		Items: []entity.OrderItem{
+			{ProductID: productIds[1], Quantity: 3},
+			{ProductID: productIds[0], Quantity: 2},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder() error = %v", err)
+	}
+
+	// Новая цена не меняет уже оформленный заказ.
+	err = productRepo.UpdateProduct(ctx, entity.Product{
+		ID:          productIds[0],
+		Name:        "order test product",
+		Description: "order test product",
+		Price:       30,
+	})
+	if err != nil {
+		t.Fatalf("UpdateProduct() error = %v", err)
+	}
+
+	orders, err := purchaseRepo.GetUserOrders(ctx, buyerID)
+	if err != nil {
+		t.Fatalf("GetUserOrders() error = %v", err)
+	}
+	if len(orders) != 1 || orders[0].ID != orderId {
+		t.Fatalf("GetUserOrders() = %+v, want order %d", orders, orderId)
+	}
+	if orders[0].Total != 7.5 {
+		t.Errorf("order total = %v, want 7.5", orders[0].Total)
+	}
+	if len(orders[0].Items) != 2 || orders[0].Items[0].UnitPrice != 3 || orders[0].Items[1].UnitPrice != 0.5 {
+		t.Errorf("order items = %+v, want unit prices 3 and 0.5", orders[0].Items)
+	}
+
+	balance, err := NewWalletRepo(pg).GetBalance(ctx, buyerID)
+	if err != nil {
+		t.Fatalf("GetBalance() error = %v", err)
+	}
+	if balance != 92.5 {
+		t.Errorf("buyer balance = %v, want 92.5", balance)
+	}
+}
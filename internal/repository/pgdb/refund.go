@@ -0,0 +1,242 @@
+package pgdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/ledger"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+)
+
+// CreateRefund возвращает refund.Items заказа refund.OrderID, где заданы
+// только товар и количество: единицы возвращаются на склады отгрузки, их
+// стоимость по цене покупки - покупателю. Заказ блокируется, поэтому вернуть
+// больше, чем куплено за вычетом прошлых возвратов, нельзя даже параллельными
+// запросами: в этом случае возвращается ErrRefundExceeded. Если после возврата в заказе
+// ничего не осталось, он переводится в refunded. Если статус заказа уже не
+// from, возвращает ErrConflict.
+func (r *PurchaseRepo) CreateRefund(ctx context.Context, refund entity.Refund, from entity.OrderStatus) (entity.Refund, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return entity.Refund{}, fmt.Errorf("PurchaseRepo.CreateRefund - r.Pool.Begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	order, err := lockOrder(ctx, tx, r.Builder, entity.OrderStatusChange{OrderID: refund.OrderID, From: from})
+	if err != nil {
+		return entity.Refund{}, err
+	}
+
+	refunded, err := refundedQuantities(ctx, tx, r.Builder, order.ID)
+	if err != nil {
+		return entity.Refund{}, err
+	}
+
+	requested := make(map[int]int, len(refund.Items))
+	for _, item := range refund.Items {
+		if item.Quantity <= 0 {
+			return entity.Refund{}, repoerrs.ErrRefundExceeded
+		}
+		requested[item.ProductID] += item.Quantity
+	}
+
+	// Строки обходятся в порядке lockOrder, то есть по возрастанию товара.
+	var (
+		lines     []entity.OrderItem
+		total     int64
+		remaining int
+	)
+	refund.Items = make([]entity.RefundItem, 0, len(requested))
+	for _, item := range order.Items {
+		quantity, ok := requested[item.ProductID]
+		delete(requested, item.ProductID)
+		if refunded[item.ID]+quantity > item.Quantity {
+			return entity.Refund{}, repoerrs.ErrRefundExceeded
+		}
+		remaining += item.Quantity - refunded[item.ID] - quantity
+		if !ok {
+			continue
+		}
+
+		amount := ledger.ToMinor(item.UnitPrice) * int64(quantity)
+		refund.Items = append(refund.Items, entity.RefundItem{
+			OrderItemID: item.ID,
+			ProductID:   item.ProductID,
+			Quantity:    quantity,
+			Amount:      ledger.FromMinor(amount),
+		})
+		total += amount
+
+		item.Quantity = quantity
+		lines = append(lines, item)
+	}
+	if len(requested) > 0 || len(lines) == 0 {
+		return entity.Refund{}, repoerrs.ErrRefundExceeded
+	}
+	refund.Amount = ledger.FromMinor(total)
+
+	sql, args, err := r.Builder.
+		Insert("refunds").
+		Columns("order_id", "actor_id", "reason", "amount").
+		Values(order.ID, nullableId(refund.ActorID), refund.Reason, refund.Amount).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return entity.Refund{}, fmt.Errorf("PurchaseRepo.CreateRefund - r.Builder.Insert: %v", err)
+	}
+
+	if err = tx.QueryRow(ctx, sql, args...).Scan(&refund.ID, &refund.CreatedAt); err != nil {
+		return entity.Refund{}, fmt.Errorf("PurchaseRepo.CreateRefund - tx.QueryRow: %v", err)
+	}
+
+	insert := r.Builder.
+		Insert("refund_items").
+		Columns("refund_id", "order_item_id", "quantity", "amount")
+	for _, item := range refund.Items {
+		insert = insert.Values(refund.ID, item.OrderItemID, item.Quantity, item.Amount)
+	}
+
+	sql, args, err = insert.ToSql()
+	if err != nil {
+		return entity.Refund{}, fmt.Errorf("PurchaseRepo.CreateRefund - r.Builder.Insert: %v", err)
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		return entity.Refund{}, fmt.Errorf("PurchaseRepo.CreateRefund - tx.Exec: %v", err)
+	}
+
+	for _, item := range refund.Items {
+		err = returnStock(ctx, tx, r.Builder, item.OrderItemID, entity.InventoryMovement{
+			ProductID: item.ProductID,
+			Kind:      entity.InventoryMovementRefund,
+			Delta:     item.Quantity,
+			ActorID:   refund.ActorID,
+			Reason:    refund.Reason,
+			OrderID:   order.ID,
+		})
+		if err != nil {
+			return entity.Refund{}, err
+		}
+	}
+
+	if total > 0 {
+		if _, err = creditBalance(ctx, tx, r.Builder, order.UserID, refund.Amount); err != nil {
+			return entity.Refund{}, err
+		}
+
+		entry := orderEntry(order.ID, order.UserID, lines).Reverse(ledger.EntryRefund, refund.ID)
+		if err = postEntry(ctx, tx, r.Builder, entry); err != nil {
+			return entity.Refund{}, err
+		}
+	}
+
+	if remaining == 0 {
+		err = setOrderStatus(ctx, tx, r.Builder, entity.OrderStatusChange{
+			OrderID: order.ID,
+			From:    from,
+			To:      entity.OrderStatusRefunded,
+			ActorID: refund.ActorID,
+			Reason:  refund.Reason,
+		})
+		if err != nil {
+			return entity.Refund{}, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return entity.Refund{}, fmt.Errorf("PurchaseRepo.CreateRefund - tx.Commit: %v", err)
+	}
+
+	return refund, nil
+}
+
+func (r *PurchaseRepo) GetOrderRefunds(ctx context.Context, orderId int) ([]entity.Refund, error) {
+	sql, args, err := r.Builder.
+		Select(
+			"rf.id", "rf.order_id", "COALESCE(rf.actor_id, 0)", "rf.reason", "rf.amount", "rf.created_at",
+			"ri.order_item_id", "oi.product_id", "ri.quantity", "ri.amount",
+		).
+		From("refunds rf").
+		Join("refund_items ri ON ri.refund_id = rf.id").
+		Join("order_items oi ON oi.id = ri.order_item_id").
+		Where("rf.order_id = ?", orderId).
+		OrderBy("rf.id", "ri.id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("PurchaseRepo.GetOrderRefunds - r.Builder.Select: %v", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("PurchaseRepo.GetOrderRefunds - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var refunds []entity.Refund
+	for rows.Next() {
+		var (
+			refund entity.Refund
+			item   entity.RefundItem
+		)
+		err = rows.Scan(
+			&refund.ID,
+			&refund.OrderID,
+			&refund.ActorID,
+			&refund.Reason,
+			&refund.Amount,
+			&refund.CreatedAt,
+			&item.OrderItemID,
+			&item.ProductID,
+			&item.Quantity,
+			&item.Amount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("PurchaseRepo.GetOrderRefunds - rows.Scan: %v", err)
+		}
+
+		if n := len(refunds); n > 0 && refunds[n-1].ID == refund.ID {
+			refunds[n-1].Items = append(refunds[n-1].Items, item)
+			continue
+		}
+		refund.Items = []entity.RefundItem{item}
+		refunds = append(refunds, refund)
+	}
+
+	return refunds, rows.Err()
+}
+
+// refundedQuantities возвращает, сколько единиц каждой строки заказа уже
+// возвращено, по id строки.
+func refundedQuantities(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, orderId int) (map[int]int, error) {
+	sql, args, err := builder.
+		Select("ri.order_item_id", "SUM(ri.quantity)").
+		From("refund_items ri").
+		Join("refunds rf ON rf.id = ri.refund_id").
+		Where("rf.order_id = ?", orderId).
+		GroupBy("ri.order_item_id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("refundedQuantities - builder.Select: %v", err)
+	}
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("refundedQuantities - tx.Query: %v", err)
+	}
+	defer rows.Close()
+
+	refunded := make(map[int]int)
+	for rows.Next() {
+		var orderItemId, quantity int
+		if err = rows.Scan(&orderItemId, &quantity); err != nil {
+			return nil, fmt.Errorf("refundedQuantities - rows.Scan: %v", err)
+		}
+		refunded[orderItemId] = quantity
+	}
+
+	return refunded, rows.Err()
+}
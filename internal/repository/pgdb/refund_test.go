@@ -0,0 +1,100 @@
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cripplemymind9/go-market/internal/allocation"
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+)
+
+func TestPurchaseRepo_CreateRefund_Partial(t *testing.T) {
+	pg := newTestPostgres(t)
+	ctx := context.Background()
+
+	productRepo := NewProductRepo(pg)
+	purchaseRepo := NewPurchaseRepo(pg, allocation.Priority{})
+
+	productId, err := productRepo.AddProduct(ctx, entity.Product{
+		Name:        "refund test product",
+		Description: "refund test product",
+		Price:       2.5,
+		Quantity:    10,
+	})
+	if err != nil {
+		t.Fatalf("AddProduct() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = productRepo.DeleteProduct(ctx, productId)
+	})
+
+	buyerID := newTestBuyer(t, pg, 20)
+
+	orderId, err := purchaseRepo.CreateOrder(ctx, entity.Order{
+		UserID: buyerID,
+		Items:  []entity.OrderItem{{ProductID: productId, Quantity: 4}},
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder() error = %v", err)
+	}
+
+	refund := entity.Refund{
+		OrderID: orderId,
+		ActorID: buyerID,
+		Reason:  "damaged",
+		Items:   []entity.RefundItem{{ProductID: productId, Quantity: 3}},
+	}
+	created, err := purchaseRepo.CreateRefund(ctx, refund, entity.OrderStatusPaid)
+	if err != nil {
+		t.Fatalf("CreateRefund() error = %v", err)
+	}
+	if created.Amount != 7.5 {
+		t.Errorf("refund amount = %v, want 7.5", created.Amount)
+	}
+
+	// Куплено 4, возвращено 3: еще двух единиц в заказе нет.
+	refund.Items[0].Quantity = 2
+	if _, err = purchaseRepo.CreateRefund(ctx, refund, entity.OrderStatusPaid); !errors.Is(err, repoerrs.ErrRefundExceeded) {
+		t.Errorf("CreateRefund() over-refund error = %v, want %v", err, repoerrs.ErrRefundExceeded)
+	}
+
+	product, err := productRepo.GetProductById(ctx, productId)
+	if err != nil {
+		t.Fatalf("GetProductById() error = %v", err)
+	}
+	if product.Quantity != 9 {
+		t.Errorf("product quantity = %d, want 9", product.Quantity)
+	}
+
+	balance, err := NewWalletRepo(pg).GetBalance(ctx, buyerID)
+	if err != nil {
+		t.Fatalf("GetBalance() error = %v", err)
+	}
+	if balance != 17.5 {
+		t.Errorf("buyer balance = %v, want 17.5", balance)
+	}
+
+	// Последняя единица закрывает заказ.
+	refund.Items[0].Quantity = 1
+	if _, err = purchaseRepo.CreateRefund(ctx, refund, entity.OrderStatusPaid); err != nil {
+		t.Fatalf("CreateRefund() error = %v", err)
+	}
+
+	order, err := NewOrderRepo(pg).GetOrder(ctx, orderId)
+	if err != nil {
+		t.Fatalf("GetOrder() error = %v", err)
+	}
+	if order.Status != entity.OrderStatusRefunded {
+		t.Errorf("order status = %q, want %q", order.Status, entity.OrderStatusRefunded)
+	}
+
+	refunds, err := purchaseRepo.GetOrderRefunds(ctx, orderId)
+	if err != nil {
+		t.Fatalf("GetOrderRefunds() error = %v", err)
+	}
+	if len(refunds) != 2 || refunds[0].Reason != "damaged" || refunds[1].Items[0].Quantity != 1 {
+		t.Errorf("GetOrderRefunds() = %+v, want two refunds", refunds)
+	}
+}
@@ -0,0 +1,422 @@
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/cripplemymind9/go-market/internal/allocation"
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/pkg/postgres"
+)
+
+// reservationColumns перечисляет колонки брони и ее строки в порядке
+// сканирования в scanReservation.
+var reservationColumns = []string{
+	"rs.id", "rs.user_id", "rs.status", "COALESCE(rs.order_id, 0)", "rs.expires_at", "rs.created_at",
+	"ri.product_id", "ri.quantity",
+}
+
+type ReservationRepo struct {
+	*postgres.Postgres
+	strategy allocation.Strategy
+}
+
+func NewReservationRepo(pg *postgres.Postgres, strategy allocation.Strategy) *ReservationRepo {
+	return &ReservationRepo{pg, strategy}
+}
+
+// CreateReservation бронирует reservation.Items до reservation.ExpiresAt:
+// увеличивает reserved товаров, не трогая остаток. Бронь проходит целиком или
+// не проходит вовсе. Товары блокируются по возрастанию id, как в createOrder.
+func (r *ReservationRepo) CreateReservation(ctx context.Context, reservation entity.Reservation) (entity.Reservation, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return entity.Reservation{}, fmt.Errorf("ReservationRepo.CreateReservation - r.Pool.Begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	items := make([]entity.ReservationItem, len(reservation.Items))
+	copy(items, reservation.Items)
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].ProductID < items[j].ProductID
+	})
+
+	for _, item := range items {
+		sql, args, err := r.Builder.
+			Select("quantity - reserved").
+			From("products").
+			Where("id = ?", item.ProductID).
+			Suffix("FOR UPDATE").
+			ToSql()
+		if err != nil {
+			return entity.Reservation{}, fmt.Errorf("ReservationRepo.CreateReservation - r.Builder.Select: %v", err)
+		}
+
+		var available int
+		if err = tx.QueryRow(ctx, sql, args...).Scan(&available); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return entity.Reservation{}, repoerrs.ErrNotFound
+			}
+			return entity.Reservation{}, fmt.Errorf("ReservationRepo.CreateReservation - tx.QueryRow: %v", err)
+		}
+
+		if available < item.Quantity {
+			return entity.Reservation{}, repoerrs.ErrNotEnoughStock
+		}
+
+		sql, args, err = r.Builder.
+			Update("products").
+			Set("reserved", squirrel.Expr("reserved + ?", item.Quantity)).
+			Where("id = ?", item.ProductID).
+			ToSql()
+		if err != nil {
+			return entity.Reservation{}, fmt.Errorf("ReservationRepo.CreateReservation - r.Builder.Update: %v", err)
+		}
+
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			return entity.Reservation{}, fmt.Errorf("ReservationRepo.CreateReservation - tx.Exec: %v", err)
+		}
+	}
+
+	sql, args, err := r.Builder.
+		Insert("reservations").
+		Columns("user_id", "status", "expires_at").
+		Values(reservation.UserID, entity.ReservationStatusActive, reservation.ExpiresAt).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return entity.Reservation{}, fmt.Errorf("ReservationRepo.CreateReservation - r.Builder.Insert: %v", err)
+	}
+
+	if err = tx.QueryRow(ctx, sql, args...).Scan(&reservation.ID, &reservation.CreatedAt); err != nil {
+		return entity.Reservation{}, fmt.Errorf("ReservationRepo.CreateReservation - tx.QueryRow: %v", err)
+	}
+
+	insert := r.Builder.
+		Insert("reservation_items").
+		Columns("reservation_id", "product_id", "quantity")
+	for _, item := range items {
+		insert = insert.Values(reservation.ID, item.ProductID, item.Quantity)
+	}
+
+	sql, args, err = insert.ToSql()
+	if err != nil {
+		return entity.Reservation{}, fmt.Errorf("ReservationRepo.CreateReservation - r.Builder.Insert: %v", err)
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		return entity.Reservation{}, fmt.Errorf("ReservationRepo.CreateReservation - tx.Exec: %v", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return entity.Reservation{}, fmt.Errorf("ReservationRepo.CreateReservation - tx.Commit: %v", err)
+	}
+
+	reservation.Status = entity.ReservationStatusActive
+	reservation.Items = items
+	return reservation, nil
+}
+
+func (r *ReservationRepo) GetReservation(ctx context.Context, reservationId int) (entity.Reservation, error) {
+	sql, args, err := r.Builder.
+		Select(reservationColumns...).
+		From("reservations rs").
+		LeftJoin("reservation_items ri ON ri.reservation_id = rs.id").
+		Where("rs.id = ?", reservationId).
+		OrderBy("ri.product_id").
+		ToSql()
+	if err != nil {
+		return entity.Reservation{}, fmt.Errorf("ReservationRepo.GetReservation - r.Builder.Select: %v", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return entity.Reservation{}, fmt.Errorf("ReservationRepo.GetReservation - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	reservation, err := scanReservation(rows)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return entity.Reservation{}, err
+		}
+		return entity.Reservation{}, fmt.Errorf("ReservationRepo.GetReservation - scanReservation: %v", err)
+	}
+
+	return reservation, nil
+}
+
+// ConfirmReservation превращает активную бронь в оплаченный заказ: снимает
+// бронь с товаров и в той же транзакции оформляет заказ по текущим ценам. Если
+// оплатить заказ нельзя, бронь остается активной. Если бронь уже не активна
+// или истекла к now, возвращает ErrConflict. Склады заказа выбираются с
+// учетом shipTo. Возвращает id заказа.
+func (r *ReservationRepo) ConfirmReservation(ctx context.Context, reservationId int, shipTo *entity.GeoPoint, now time.Time) (int, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("ReservationRepo.ConfirmReservation - r.Pool.Begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	reservation, err := lockReservation(ctx, tx, r.Builder, reservationId)
+	if err != nil {
+		return 0, err
+	}
+	if !reservation.ExpiresAt.After(now) {
+		return 0, repoerrs.ErrConflict
+	}
+	if len(reservation.Items) == 0 {
+		// Все товары брони удалены.
+		return 0, repoerrs.ErrNotFound
+	}
+
+	if err = unreserve(ctx, tx, r.Builder, reservation.Items); err != nil {
+		return 0, err
+	}
+
+	order := entity.Order{UserID: reservation.UserID, ShipTo: shipTo}
+	for _, item := range reservation.Items {
+		order.Items = append(order.Items, entity.OrderItem{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+
+	orderId, err := createOrder(ctx, tx, r.Builder, r.strategy, order)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = setReservationStatus(ctx, tx, r.Builder, []int{reservation.ID}, entity.ReservationStatusConfirmed, orderId); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("ReservationRepo.ConfirmReservation - tx.Commit: %v", err)
+	}
+
+	return orderId, nil
+}
+
+// ReleaseReservation снимает активную бронь по просьбе покупателя. Если бронь
+// уже не активна, возвращает ErrConflict.
+func (r *ReservationRepo) ReleaseReservation(ctx context.Context, reservationId int) error {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ReservationRepo.ReleaseReservation - r.Pool.Begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	reservation, err := lockReservation(ctx, tx, r.Builder, reservationId)
+	if err != nil {
+		return err
+	}
+
+	if err = unreserve(ctx, tx, r.Builder, reservation.Items); err != nil {
+		return err
+	}
+
+	if err = setReservationStatus(ctx, tx, r.Builder, []int{reservation.ID}, entity.ReservationStatusReleased, 0); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ReservationRepo.ReleaseReservation - tx.Commit: %v", err)
+	}
+
+	return nil
+}
+
+// ReleaseExpiredReservations снимает до limit активных броней, истекших к now,
+// и возвращает их число. Брони, которые сейчас подтверждаются или снимаются,
+// пропускаются, поэтому чистку можно запускать параллельно.
+func (r *ReservationRepo) ReleaseExpiredReservations(ctx context.Context, now time.Time, limit int) (int, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("ReservationRepo.ReleaseExpiredReservations - r.Pool.Begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	sql, args, err := r.Builder.
+		Select("id").
+		From("reservations").
+		Where("status = ? AND expires_at <= ?", entity.ReservationStatusActive, now).
+		OrderBy("expires_at").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("ReservationRepo.ReleaseExpiredReservations - r.Builder.Select: %v", err)
+	}
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("ReservationRepo.ReleaseExpiredReservations - tx.Query: %v", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return 0, fmt.Errorf("ReservationRepo.ReleaseExpiredReservations - pgx.CollectRows: %v", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	// Строки всех броней сводятся по товару, чтобы блокировать товары по
+	// возрастанию id.
+	sql, args, err = r.Builder.
+		Select("product_id", "SUM(quantity)").
+		From("reservation_items").
+		Where(squirrel.Eq{"reservation_id": ids}).
+		GroupBy("product_id").
+		OrderBy("product_id").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("ReservationRepo.ReleaseExpiredReservations - r.Builder.Select: %v", err)
+	}
+
+	rows, err = tx.Query(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("ReservationRepo.ReleaseExpiredReservations - tx.Query: %v", err)
+	}
+	items, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.ReservationItem, error) {
+		var item entity.ReservationItem
+		err := row.Scan(&item.ProductID, &item.Quantity)
+		return item, err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("ReservationRepo.ReleaseExpiredReservations - pgx.CollectRows: %v", err)
+	}
+
+	if err = unreserve(ctx, tx, r.Builder, items); err != nil {
+		return 0, err
+	}
+
+	if err = setReservationStatus(ctx, tx, r.Builder, ids, entity.ReservationStatusExpired, 0); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("ReservationRepo.ReleaseExpiredReservations - tx.Commit: %v", err)
+	}
+
+	return len(ids), nil
+}
+
+// lockReservation блокирует активную бронь. Если брони нет, возвращает
+// ErrNotFound, если она уже не активна - ErrConflict.
+func lockReservation(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, reservationId int) (entity.Reservation, error) {
+	sql, args, err := builder.
+		Select(reservationColumns...).
+		From("reservations rs").
+		LeftJoin("reservation_items ri ON ri.reservation_id = rs.id").
+		Where("rs.id = ?", reservationId).
+		OrderBy("ri.product_id").
+		Suffix("FOR UPDATE OF rs").
+		ToSql()
+	if err != nil {
+		return entity.Reservation{}, fmt.Errorf("lockReservation - builder.Select: %v", err)
+	}
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return entity.Reservation{}, fmt.Errorf("lockReservation - tx.Query: %v", err)
+	}
+	reservation, err := scanReservation(rows)
+	rows.Close()
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return entity.Reservation{}, err
+		}
+		return entity.Reservation{}, fmt.Errorf("lockReservation - scanReservation: %v", err)
+	}
+
+	if reservation.Status != entity.ReservationStatusActive {
+		return entity.Reservation{}, repoerrs.ErrConflict
+	}
+
+	return reservation, nil
+}
+
+// unreserve снимает бронь с товаров items, которые должны идти по возрастанию
+// id товара.
+func unreserve(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, items []entity.ReservationItem) error {
+	for _, item := range items {
+		sql, args, err := builder.
+			Update("products").
+			Set("reserved", squirrel.Expr("GREATEST(reserved - ?, 0)", item.Quantity)).
+			Where("id = ?", item.ProductID).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("unreserve - builder.Update: %v", err)
+		}
+
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			return fmt.Errorf("unreserve - tx.Exec: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func setReservationStatus(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, ids []int, status entity.ReservationStatus, orderId int) error {
+	sql, args, err := builder.
+		Update("reservations").
+		Set("status", status).
+		Set("order_id", nullableId(orderId)).
+		Where(squirrel.Eq{"id": ids}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("setReservationStatus - builder.Update: %v", err)
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("setReservationStatus - tx.Exec: %v", err)
+	}
+
+	return nil
+}
+
+// scanReservation собирает одну бронь из строк reservationColumns. У брони,
+// все товары которой удалены, строк нет.
+func scanReservation(rows pgx.Rows) (entity.Reservation, error) {
+	var (
+		reservation entity.Reservation
+		found       bool
+	)
+	for rows.Next() {
+		var (
+			productId, quantity *int
+		)
+		err := rows.Scan(
+			&reservation.ID,
+			&reservation.UserID,
+			&reservation.Status,
+			&reservation.OrderID,
+			&reservation.ExpiresAt,
+			&reservation.CreatedAt,
+			&productId,
+			&quantity,
+		)
+		if err != nil {
+			return entity.Reservation{}, err
+		}
+		found = true
+
+		if productId != nil && quantity != nil {
+			reservation.Items = append(reservation.Items, entity.ReservationItem{ProductID: *productId, Quantity: *quantity})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return entity.Reservation{}, err
+	}
+
+	if !found {
+		return entity.Reservation{}, repoerrs.ErrNotFound
+	}
+
+	return reservation, nil
+}
@@ -0,0 +1,167 @@
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cripplemymind9/go-market/internal/allocation"
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+)
+
+func newTestProduct(t *testing.T, productRepo *ProductRepo, price float64, quantity int) int {
+	t.Helper()
+
+	ctx := context.Background()
+	productId, err := productRepo.AddProduct(ctx, entity.Product{
+		Name:        "reservation test product",
+		Description: "reservation test product",
+		Price:       price,
+		Quantity:    quantity,
+	})
+	if err != nil {
+		t.Fatalf("AddProduct() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = productRepo.DeleteProduct(ctx, productId)
+	})
+
+	return productId
+}
+
+func TestReservationRepo_HoldAndConfirm(t *testing.T) {
+	pg := newTestPostgres(t)
+	ctx := context.Background()
+
+	productRepo := NewProductRepo(pg)
+	reservationRepo := NewReservationRepo(pg, allocation.Priority{})
+
+	productId := newTestProduct(t, productRepo, 2, 3)
+	buyerID := newTestBuyer(t, pg, 100)
+	otherID := newTestBuyer(t, pg, 100)
+
+	now := time.Now()
+	reservation, err := reservationRepo.CreateReservation(ctx, entity.Reservation{
+		UserID:    buyerID,
+		Items:     []entity.ReservationItem{{ProductID: productId, Quantity: 2}},
+		ExpiresAt: now.Add(time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("CreateReservation() error = %v", err)
+	}
+
+	product, err := productRepo.GetProductById(ctx, productId)
+	if err != nil {
+		t.Fatalf("GetProductById() error = %v", err)
+	}
+	if product.Quantity != 3 || product.Reserved != 2 || product.Available() != 1 {
+		t.Errorf("product quantity/reserved = %d/%d, want 3/2", product.Quantity, product.Reserved)
+	}
+
+	// Забронированные единицы не достаются другим покупателям.
+	_, err = NewPurchaseRepo(pg, allocation.Priority{}).CreateOrder(ctx, entity.Order{
+		UserID: otherID,
+		Items:  []entity.OrderItem{{ProductID: productId, Quantity: 2}},
+	})
+	if !errors.Is(err, repoerrs.ErrNotEnoughStock) {
+		t.Errorf("CreateOrder() error = %v, want %v", err, repoerrs.ErrNotEnoughStock)
+	}
+
+	orderId, err := reservationRepo.ConfirmReservation(ctx, reservation.ID, nil, now)
+	if err != nil {
+		t.Fatalf("ConfirmReservation() error = %v", err)
+	}
+
+	product, err = productRepo.GetProductById(ctx, productId)
+	if err != nil {
+		t.Fatalf("GetProductById() error = %v", err)
+	}
+	if product.Quantity != 1 || product.Reserved != 0 {
+		t.Errorf("product quantity/reserved = %d/%d, want 1/0", product.Quantity, product.Reserved)
+	}
+
+	reservation, err = reservationRepo.GetReservation(ctx, reservation.ID)
+	if err != nil {
+		t.Fatalf("GetReservation() error = %v", err)
+	}
+	if reservation.Status != entity.ReservationStatusConfirmed || reservation.OrderID != orderId {
+		t.Errorf("reservation = %+v, want confirmed with order %d", reservation, orderId)
+	}
+
+	if _, err = reservationRepo.ConfirmReservation(ctx, reservation.ID, nil, now); !errors.Is(err, repoerrs.ErrConflict) {
+		t.Errorf("ConfirmReservation() repeat error = %v, want %v", err, repoerrs.ErrConflict)
+	}
+}
+
+func TestReservationRepo_ReleaseExpired(t *testing.T) {
+	pg := newTestPostgres(t)
+	ctx := context.Background()
+
+	productRepo := NewProductRepo(pg)
+	reservationRepo := NewReservationRepo(pg, allocation.Priority{})
+
+	productId := newTestProduct(t, productRepo, 2, 5)
+	buyerID := newTestBuyer(t, pg, 100)
+
+	now := time.Now()
+	expired, err := reservationRepo.CreateReservation(ctx, entity.Reservation{
+		UserID:    buyerID,
+		Items:     []entity.ReservationItem{{ProductID: productId, Quantity: 2}},
+		ExpiresAt: now.Add(-time.Second),
+	})
+	if err != nil {
+		t.Fatalf("CreateReservation() error = %v", err)
+	}
+	active, err := reservationRepo.CreateReservation(ctx, entity.Reservation{
+		UserID:    buyerID,
+		Items:     []entity.ReservationItem{{ProductID: productId, Quantity: 1}},
+		ExpiresAt: now.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("CreateReservation() error = %v", err)
+	}
+
+	if _, err = reservationRepo.ConfirmReservation(ctx, expired.ID, nil, now); !errors.Is(err, repoerrs.ErrConflict) {
+		t.Errorf("ConfirmReservation() expired error = %v, want %v", err, repoerrs.ErrConflict)
+	}
+
+	// Другие тесты могли оставить свои истекшие брони, поэтому снимается все.
+	for {
+		released, err := reservationRepo.ReleaseExpiredReservations(ctx, now, 100)
+		if err != nil {
+			t.Fatalf("ReleaseExpiredReservations() error = %v", err)
+		}
+		if released < 100 {
+			break
+		}
+	}
+
+	product, err := productRepo.GetProductById(ctx, productId)
+	if err != nil {
+		t.Fatalf("GetProductById() error = %v", err)
+	}
+	if product.Quantity != 5 || product.Reserved != 1 {
+		t.Errorf("product quantity/reserved = %d/%d, want 5/1", product.Quantity, product.Reserved)
+	}
+
+	got, err := reservationRepo.GetReservation(ctx, expired.ID)
+	if err != nil {
+		t.Fatalf("GetReservation() error = %v", err)
+	}
+	if got.Status != entity.ReservationStatusExpired {
+		t.Errorf("expired reservation status = %q, want %q", got.Status, entity.ReservationStatusExpired)
+	}
+
+	if err = reservationRepo.ReleaseReservation(ctx, active.ID); err != nil {
+		t.Fatalf("ReleaseReservation() error = %v", err)
+	}
+	product, err = productRepo.GetProductById(ctx, productId)
+	if err != nil {
+		t.Fatalf("GetProductById() error = %v", err)
+	}
+	if product.Reserved != 0 {
+		t.Errorf("product reserved = %d, want 0", product.Reserved)
+	}
+}
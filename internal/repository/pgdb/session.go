@@ -0,0 +1,167 @@
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/pkg/postgres"
+)
+
+type SessionRepo struct {
+	*postgres.Postgres
+}
+
+func NewSessionRepo(pg *postgres.Postgres) *SessionRepo {
+	return &SessionRepo{pg}
+}
+
+func (r *SessionRepo) CreateSession(ctx context.Context, session entity.Session) error {
+	sql, args, err := r.Builder.
+		Insert("sessions").
+		Columns("id", "handle", "user_id", "refresh_token_hash", "expires_at").
+		Values(
+			session.ID,
+			session.Handle,
+			session.UserID,
+			session.RefreshTokenHash,
+			session.ExpiresAt,
+		).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("SessionRepo.CreateSession - r.Builder.Insert: %v", err)
+	}
+
+	if _, err = r.Pool.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("SessionRepo.CreateSession - r.Pool.Exec: %v", err)
+	}
+
+	return nil
+}
+
+func (r *SessionRepo) GetSession(ctx context.Context, id string) (entity.Session, error) {
+	session, err := r.getSession(ctx, squirrel.Eq{"id": id})
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return entity.Session{}, err
+		}
+		return entity.Session{}, fmt.Errorf("SessionRepo.GetSession - r.getSession: %v", err)
+	}
+
+	return session, nil
+}
+
+// GetSessionByHandle находит сессию по идентификатору из токена доступа.
+func (r *SessionRepo) GetSessionByHandle(ctx context.Context, handle string) (entity.Session, error) {
+	session, err := r.getSession(ctx, squirrel.Eq{"handle": handle})
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return entity.Session{}, err
+		}
+		return entity.Session{}, fmt.Errorf("SessionRepo.GetSessionByHandle - r.getSession: %v", err)
+	}
+
+	return session, nil
+}
+
+func (r *SessionRepo) getSession(ctx context.Context, where squirrel.Eq) (entity.Session, error) {
+	sql, args, err := r.Builder.
+		Select(
+			"id", "handle", "user_id", "refresh_token_hash", "COALESCE(previous_refresh_token_hash, '')",
+			"expires_at", "created_at", "revoked_at",
+		).
+		From("sessions").
+		Where(where).
+		ToSql()
+	if err != nil {
+		return entity.Session{}, fmt.Errorf("r.Builder.Select: %v", err)
+	}
+
+	var session entity.Session
+	err = r.Pool.QueryRow(ctx, sql, args...).Scan(
+		&session.ID,
+		&session.Handle,
+		&session.UserID,
+		&session.RefreshTokenHash,
+		&session.PreviousRefreshTokenHash,
+		&session.ExpiresAt,
+		&session.CreatedAt,
+		&session.RevokedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.Session{}, repoerrs.ErrNotFound
+		}
+		return entity.Session{}, fmt.Errorf("r.Pool.QueryRow: %v", err)
+	}
+
+	return session, nil
+}
+
+// RotateSession заменяет хэш refresh-токена, только если текущий хэш все еще
+// равен oldHash и сессия не отозвана. Иначе возвращает repoerrs.ErrNotFound:
+// токен уже был использован параллельным запросом. oldHash запоминается как
+// хэш предыдущего токена.
+func (r *SessionRepo) RotateSession(ctx context.Context, id, oldHash, newHash string, expiresAt time.Time) error {
+	sql, args, err := r.Builder.
+		Update("sessions").
+		Set("refresh_token_hash", newHash).
+		Set("previous_refresh_token_hash", oldHash).
+		Set("expires_at", expiresAt).
+		Where(squirrel.Eq{"id": id, "refresh_token_hash": oldHash, "revoked_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("SessionRepo.RotateSession - r.Builder.Update: %v", err)
+	}
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("SessionRepo.RotateSession - r.Pool.Exec: %v", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *SessionRepo) RevokeSession(ctx context.Context, id string) error {
+	sql, args, err := r.Builder.
+		Update("sessions").
+		Set("revoked_at", squirrel.Expr("CURRENT_TIMESTAMP")).
+		Where(squirrel.Eq{"id": id, "revoked_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("SessionRepo.RevokeSession - r.Builder.Update: %v", err)
+	}
+
+	if _, err = r.Pool.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("SessionRepo.RevokeSession - r.Pool.Exec: %v", err)
+	}
+
+	return nil
+}
+
+func (r *SessionRepo) RevokeUserSessions(ctx context.Context, userId int) error {
+	sql, args, err := r.Builder.
+		Update("sessions").
+		Set("revoked_at", squirrel.Expr("CURRENT_TIMESTAMP")).
+		Where(squirrel.Eq{"user_id": userId, "revoked_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("SessionRepo.RevokeUserSessions - r.Builder.Update: %v", err)
+	}
+
+	if _, err = r.Pool.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("SessionRepo.RevokeUserSessions - r.Pool.Exec: %v", err)
+	}
+
+	return nil
+}
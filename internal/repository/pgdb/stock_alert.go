@@ -0,0 +1,219 @@
+package pgdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/pkg/postgres"
+)
+
+// stockAlertColumns перечисляет колонки предупреждения в порядке
+// сканирования в scanStockAlert.
+var stockAlertColumns = []string{
+	"sa.id", "sa.product_id", "p.name", "COALESCE(p.seller_id, 0)", "COALESCE(u.email, '')",
+	"sa.threshold", "sa.quantity", "sa.attempts", "sa.delivered_channels", "sa.created_at", "sa.notified_at", "sa.resolved_at",
+}
+
+type StockAlertRepo struct {
+	*postgres.Postgres
+}
+
+func NewStockAlertRepo(pg *postgres.Postgres) *StockAlertRepo {
+	return &StockAlertRepo{pg}
+}
+
+// ClaimStockAlerts забирает на отправку до limit недоставленных
+// предупреждений, чья очередная попытка наступила к now. Забранные
+// предупреждения до claimUntil не достаются другим отправителям; если
+// отправитель не отчитается о доставке, после claimUntil их заберут снова.
+func (r *StockAlertRepo) ClaimStockAlerts(ctx context.Context, now, claimUntil time.Time, limit int) ([]entity.StockAlert, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("StockAlertRepo.ClaimStockAlerts - r.Pool.Begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	sql, args, err := r.Builder.
+		Select(stockAlertColumns...).
+		From("stock_alerts sa").
+		Join("products p ON p.id = sa.product_id").
+		LeftJoin("users u ON u.id = p.seller_id").
+		Where("sa.notified_at IS NULL AND sa.next_attempt_at <= ?", now).
+		OrderBy("sa.id").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE OF sa SKIP LOCKED").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("StockAlertRepo.ClaimStockAlerts - r.Builder.Select: %v", err)
+	}
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("StockAlertRepo.ClaimStockAlerts - tx.Query: %v", err)
+	}
+
+	alerts, err := pgx.CollectRows(rows, scanStockAlert)
+	if err != nil {
+		return nil, fmt.Errorf("StockAlertRepo.ClaimStockAlerts - pgx.CollectRows: %v", err)
+	}
+	if len(alerts) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]int, 0, len(alerts))
+	for i := range alerts {
+		ids = append(ids, alerts[i].ID)
+		alerts[i].Attempts++
+	}
+
+	sql, args, err = r.Builder.
+		Update("stock_alerts").
+		Set("attempts", squirrel.Expr("attempts + 1")).
+		Set("next_attempt_at", claimUntil).
+		Where(squirrel.Eq{"id": ids}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("StockAlertRepo.ClaimStockAlerts - r.Builder.Update: %v", err)
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		return nil, fmt.Errorf("StockAlertRepo.ClaimStockAlerts - tx.Exec: %v", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("StockAlertRepo.ClaimStockAlerts - tx.Commit: %v", err)
+	}
+
+	return alerts, nil
+}
+
+// MarkStockAlertNotified отмечает предупреждение доставленным и добавляет
+// delivered к способам, которыми оно отправлено.
+func (r *StockAlertRepo) MarkStockAlertNotified(ctx context.Context, alertId int, delivered []string, notifiedAt time.Time) error {
+	sql, args, err := r.Builder.
+		Update("stock_alerts").
+		Set("notified_at", notifiedAt).
+		Set("delivered_channels", squirrel.Expr("delivered_channels || COALESCE(?::TEXT[], '{}')", delivered)).
+		Where("id = ? AND notified_at IS NULL", alertId).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("StockAlertRepo.MarkStockAlertNotified - r.Builder.Update: %v", err)
+	}
+
+	if _, err = r.Pool.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("StockAlertRepo.MarkStockAlertNotified - r.Pool.Exec: %v", err)
+	}
+
+	return nil
+}
+
+// RetryStockAlert откладывает следующую попытку доставить предупреждение до
+// nextAttemptAt и запоминает способы delivered, которыми оно уже доставлено.
+func (r *StockAlertRepo) RetryStockAlert(ctx context.Context, alertId int, delivered []string, nextAttemptAt time.Time) error {
+	sql, args, err := r.Builder.
+		Update("stock_alerts").
+		Set("next_attempt_at", nextAttemptAt).
+		Set("delivered_channels", squirrel.Expr("delivered_channels || COALESCE(?::TEXT[], '{}')", delivered)).
+		Where("id = ? AND notified_at IS NULL", alertId).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("StockAlertRepo.RetryStockAlert - r.Builder.Update: %v", err)
+	}
+
+	if _, err = r.Pool.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("StockAlertRepo.RetryStockAlert - r.Pool.Exec: %v", err)
+	}
+
+	return nil
+}
+
+// GetProductStockAlerts возвращает предупреждения товара от новых к старым.
+func (r *StockAlertRepo) GetProductStockAlerts(ctx context.Context, productId int) ([]entity.StockAlert, error) {
+	sql, args, err := r.Builder.
+		Select(stockAlertColumns...).
+		From("stock_alerts sa").
+		Join("products p ON p.id = sa.product_id").
+		LeftJoin("users u ON u.id = p.seller_id").
+		Where("sa.product_id = ?", productId).
+		OrderBy("sa.created_at DESC", "sa.id DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("StockAlertRepo.GetProductStockAlerts - r.Builder.Select: %v", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("StockAlertRepo.GetProductStockAlerts - r.Pool.Query: %v", err)
+	}
+
+	alerts, err := pgx.CollectRows(rows, scanStockAlert)
+	if err != nil {
+		return nil, fmt.Errorf("StockAlertRepo.GetProductStockAlerts - pgx.CollectRows: %v", err)
+	}
+
+	return alerts, nil
+}
+
+// checkReorderThreshold в рамках переданной транзакции сравнивает остаток
+// товара с его порогом дозаказа. Если остаток опустился до порога, а
+// открытого предупреждения еще нет, создает его; если остаток выше порога
+// или порог снят, закрывает открытое предупреждение. Так одно пересечение
+// порога дает ровно одно предупреждение, сколько бы продаж ни было ниже него.
+func checkReorderThreshold(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, productId, quantity int, threshold *int) error {
+	if threshold != nil && quantity <= *threshold {
+		sql, args, err := builder.
+			Insert("stock_alerts").
+			Columns("product_id", "threshold", "quantity").
+			Values(productId, *threshold, quantity).
+			Suffix("ON CONFLICT (product_id) WHERE resolved_at IS NULL DO NOTHING").
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("checkReorderThreshold - builder.Insert: %v", err)
+		}
+
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			return fmt.Errorf("checkReorderThreshold - tx.Exec: %v", err)
+		}
+
+		return nil
+	}
+
+	sql, args, err := builder.
+		Update("stock_alerts").
+		Set("resolved_at", squirrel.Expr("CURRENT_TIMESTAMP")).
+		Where("product_id = ? AND resolved_at IS NULL", productId).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("checkReorderThreshold - builder.Update: %v", err)
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("checkReorderThreshold - tx.Exec: %v", err)
+	}
+
+	return nil
+}
+
+func scanStockAlert(row pgx.CollectableRow) (entity.StockAlert, error) {
+	var alert entity.StockAlert
+	err := row.Scan(
+		&alert.ID,
+		&alert.ProductID,
+		&alert.ProductName,
+		&alert.SellerID,
+		&alert.SellerEmail,
+		&alert.Threshold,
+		&alert.Quantity,
+		&alert.Attempts,
+		&alert.DeliveredChannels,
+		&alert.CreatedAt,
+		&alert.NotifiedAt,
+		&alert.ResolvedAt,
+	)
+	return alert, err
+}
@@ -0,0 +1,120 @@
+package pgdb
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+)
+
+func TestStockAlertRepo_OneAlertPerCrossing(t *testing.T) {
+	pg := newTestPostgres(t)
+	ctx := context.Background()
+
+	productRepo := NewProductRepo(pg)
+	inventoryRepo := NewInventoryRepo(pg)
+	stockAlertRepo := NewStockAlertRepo(pg)
+
+	productId := newTestProduct(t, productRepo, 1, 10)
+
+	threshold := 5
+	if err := productRepo.SetReorderThreshold(ctx, productId, &threshold); err != nil {
+		t.Fatalf("SetReorderThreshold() error = %v", err)
+	}
+
+	adjust := func(delta int) {
+		t.Helper()
+		if _, err := inventoryRepo.AdjustStock(ctx, entity.InventoryMovement{
+			ProductID: productId,
+			Kind:      entity.InventoryMovementAdjustment,
+			Delta:     delta,
+			Reason:    "stocktake",
+		}); err != nil {
+			t.Fatalf("AdjustStock() error = %v", err)
+		}
+	}
+
+	// Остаток опускается до порога и ниже: предупреждение одно.
+	adjust(-5)
+	adjust(-2)
+	assertStockAlerts(t, stockAlertRepo, productId, 1, 1)
+
+	// Остаток поднимается выше порога и снова падает: новое пересечение.
+	adjust(4)
+	assertStockAlerts(t, stockAlertRepo, productId, 1, 0)
+	adjust(-4)
+	assertStockAlerts(t, stockAlertRepo, productId, 2, 1)
+
+	now := time.Now()
+	ours := claimProductAlerts(t, stockAlertRepo, productId, now)
+	if len(ours) != 2 {
+		t.Fatalf("claimed %d alerts of the product, want 2", len(ours))
+	}
+
+	// Забранные предупреждения не достаются другому отправителю.
+	if again := claimProductAlerts(t, stockAlertRepo, productId, now); len(again) != 0 {
+		t.Errorf("claimed %d alerts twice, want 0", len(again))
+	}
+
+	// Доставленное больше не отправляется, а недоставленное забирается снова,
+	// когда подойдет время следующей попытки.
+	if err := stockAlertRepo.MarkStockAlertNotified(ctx, ours[0].ID, []string{"log"}, now); err != nil {
+		t.Fatalf("MarkStockAlertNotified() error = %v", err)
+	}
+	if err := stockAlertRepo.RetryStockAlert(ctx, ours[1].ID, []string{"log"}, now.Add(-time.Second)); err != nil {
+		t.Fatalf("RetryStockAlert() error = %v", err)
+	}
+	retried := claimProductAlerts(t, stockAlertRepo, productId, now)
+	if len(retried) != 1 || retried[0].ID != ours[1].ID || retried[0].Attempts != 2 {
+		t.Errorf("claimed %+v, want alert %d on its second attempt", retried, ours[1].ID)
+	}
+	if len(retried) == 1 && !slices.Equal(retried[0].DeliveredChannels, []string{"log"}) {
+		t.Errorf("DeliveredChannels = %v, want [log]", retried[0].DeliveredChannels)
+	}
+
+	// Снятый порог закрывает открытое предупреждение.
+	if err := productRepo.SetReorderThreshold(ctx, productId, nil); err != nil {
+		t.Fatalf("SetReorderThreshold() error = %v", err)
+	}
+	assertStockAlerts(t, stockAlertRepo, productId, 2, 0)
+}
+
+// claimProductAlerts забирает на отправку все подошедшие предупреждения и
+// возвращает предупреждения товара productId.
+func claimProductAlerts(t *testing.T, stockAlertRepo *StockAlertRepo, productId int, now time.Time) []entity.StockAlert {
+	t.Helper()
+
+	claimed, err := stockAlertRepo.ClaimStockAlerts(context.Background(), now, now.Add(time.Hour), 1000)
+	if err != nil {
+		t.Fatalf("ClaimStockAlerts() error = %v", err)
+	}
+
+	var ours []entity.StockAlert
+	for _, alert := range claimed {
+		if alert.ProductID == productId {
+			ours = append(ours, alert)
+		}
+	}
+	return ours
+}
+
+func assertStockAlerts(t *testing.T, stockAlertRepo *StockAlertRepo, productId, wantTotal, wantOpen int) {
+	t.Helper()
+
+	alerts, err := stockAlertRepo.GetProductStockAlerts(context.Background(), productId)
+	if err != nil {
+		t.Fatalf("GetProductStockAlerts() error = %v", err)
+	}
+
+	var open int
+	for _, alert := range alerts {
+		if alert.ResolvedAt == nil {
+			open++
+		}
+	}
+	if len(alerts) != wantTotal || open != wantOpen {
+		t.Errorf("alerts = %d (%d open), want %d (%d open)", len(alerts), open, wantTotal, wantOpen)
+	}
+}
@@ -0,0 +1,265 @@
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/pkg/postgres"
+)
+
+type TwoFactorRepo struct {
+	*postgres.Postgres
+}
+
+func NewTwoFactorRepo(pg *postgres.Postgres) *TwoFactorRepo {
+	return &TwoFactorRepo{pg}
+}
+
+func (r *TwoFactorRepo) GetTOTP(ctx context.Context, userId int) (entity.UserTOTP, error) {
+	sql, args, err := r.Builder.
+		Select("user_id", "secret", "confirmed_at IS NOT NULL", "last_used_step").
+		From("user_totp").
+		Where("user_id = ?", userId).
+		ToSql()
+	if err != nil {
+		return entity.UserTOTP{}, fmt.Errorf("TwoFactorRepo.GetTOTP - r.Builder.Select: %v", err)
+	}
+
+	var totp entity.UserTOTP
+	err = r.Pool.QueryRow(ctx, sql, args...).Scan(
+		&totp.UserID,
+		&totp.Secret,
+		&totp.Confirmed,
+		&totp.LastUsedStep,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.UserTOTP{}, repoerrs.ErrNotFound
+		}
+		return entity.UserTOTP{}, fmt.Errorf("TwoFactorRepo.GetTOTP - r.Pool.QueryRow: %v", err)
+	}
+
+	return totp, nil
+}
+
+// SaveTOTP сохраняет новый неподтвержденный секрет, заменяя прежний
+// неподтвержденный. Если второй фактор уже подключен, возвращает
+// repoerrs.ErrAlreadyExists.
+func (r *TwoFactorRepo) SaveTOTP(ctx context.Context, userId int, secret string) error {
+	sql, args, err := r.Builder.
+		Insert("user_totp").
+		Columns("user_id", "secret").
+		Values(userId, secret).
+		Suffix("ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, last_used_step = 0, created_at = CURRENT_TIMESTAMP").
+		Suffix("WHERE user_totp.confirmed_at IS NULL").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("TwoFactorRepo.SaveTOTP - r.Builder.Insert: %v", err)
+	}
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("TwoFactorRepo.SaveTOTP - r.Pool.Exec: %v", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrAlreadyExists
+	}
+
+	return nil
+}
+
+// ConfirmTOTP включает второй фактор, запоминая шаг кода подтверждения, и
+// заменяет коды восстановления. Если подключение не начато или уже
+// подтверждено, возвращает repoerrs.ErrNotFound.
+func (r *TwoFactorRepo) ConfirmTOTP(ctx context.Context, userId int, step int64, recoveryCodeHashes []string) error {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("TwoFactorRepo.ConfirmTOTP - r.Pool.Begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	sql, args, err := r.Builder.
+		Update("user_totp").
+		Set("confirmed_at", squirrel.Expr("CURRENT_TIMESTAMP")).
+		Set("last_used_step", step).
+		Where("user_id = ? AND confirmed_at IS NULL", userId).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("TwoFactorRepo.ConfirmTOTP - r.Builder.Update: %v", err)
+	}
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("TwoFactorRepo.ConfirmTOTP - tx.Exec: %v", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrNotFound
+	}
+
+	if err = r.replaceRecoveryCodes(ctx, tx, userId, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("TwoFactorRepo.ConfirmTOTP - tx.Commit: %v", err)
+	}
+
+	return nil
+}
+
+// UseTOTPStep отмечает шаг как использованный. Если этот или более поздний
+// шаг уже использован, код повторный и возвращается repoerrs.ErrNotFound.
+func (r *TwoFactorRepo) UseTOTPStep(ctx context.Context, userId int, step int64) error {
+	sql, args, err := r.Builder.
+		Update("user_totp").
+		Set("last_used_step", step).
+		Where("user_id = ? AND confirmed_at IS NOT NULL AND last_used_step < ?", userId, step).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("TwoFactorRepo.UseTOTPStep - r.Builder.Update: %v", err)
+	}
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("TwoFactorRepo.UseTOTPStep - r.Pool.Exec: %v", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *TwoFactorRepo) GetRecoveryCodes(ctx context.Context, userId int) ([]entity.RecoveryCode, error) {
+	sql, args, err := r.Builder.
+		Select("id", "user_id", "code_hash").
+		From("user_recovery_codes").
+		Where("user_id = ? AND used_at IS NULL", userId).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("TwoFactorRepo.GetRecoveryCodes - r.Builder.Select: %v", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("TwoFactorRepo.GetRecoveryCodes - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var codes []entity.RecoveryCode
+	for rows.Next() {
+		var code entity.RecoveryCode
+		if err = rows.Scan(&code.ID, &code.UserID, &code.CodeHash); err != nil {
+			return nil, fmt.Errorf("TwoFactorRepo.GetRecoveryCodes - rows.Next: %v", err)
+		}
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
+
+// UseRecoveryCode гасит код восстановления. Уже использованный код дает
+// repoerrs.ErrNotFound.
+func (r *TwoFactorRepo) UseRecoveryCode(ctx context.Context, id int) error {
+	sql, args, err := r.Builder.
+		Update("user_recovery_codes").
+		Set("used_at", squirrel.Expr("CURRENT_TIMESTAMP")).
+		Where("id = ? AND used_at IS NULL", id).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("TwoFactorRepo.UseRecoveryCode - r.Builder.Update: %v", err)
+	}
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("TwoFactorRepo.UseRecoveryCode - r.Pool.Exec: %v", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrNotFound
+	}
+
+	return nil
+}
+
+// DeleteTOTP отключает второй фактор вместе с кодами восстановления.
+func (r *TwoFactorRepo) DeleteTOTP(ctx context.Context, userId int) error {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("TwoFactorRepo.DeleteTOTP - r.Pool.Begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err = r.replaceRecoveryCodes(ctx, tx, userId, nil); err != nil {
+		return err
+	}
+
+	sql, args, err := r.Builder.
+		Delete("user_totp").
+		Where("user_id = ?", userId).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("TwoFactorRepo.DeleteTOTP - r.Builder.Delete: %v", err)
+	}
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("TwoFactorRepo.DeleteTOTP - tx.Exec: %v", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrNotFound
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("TwoFactorRepo.DeleteTOTP - tx.Commit: %v", err)
+	}
+
+	return nil
+}
+
+func (r *TwoFactorRepo) replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userId int, codeHashes []string) error {
+	sql, args, err := r.Builder.
+		Delete("user_recovery_codes").
+		Where("user_id = ?", userId).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("TwoFactorRepo.replaceRecoveryCodes - r.Builder.Delete: %v", err)
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("TwoFactorRepo.replaceRecoveryCodes - tx.Exec: %v", err)
+	}
+
+	if len(codeHashes) == 0 {
+		return nil
+	}
+
+	insert := r.Builder.
+		Insert("user_recovery_codes").
+		Columns("user_id", "code_hash")
+	for _, hash := range codeHashes {
+		insert = insert.Values(userId, hash)
+	}
+
+	sql, args, err = insert.ToSql()
+	if err != nil {
+		return fmt.Errorf("TwoFactorRepo.replaceRecoveryCodes - r.Builder.Insert: %v", err)
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("TwoFactorRepo.replaceRecoveryCodes - tx.Exec: %v", err)
+	}
+
+	return nil
+}
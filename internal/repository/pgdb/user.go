@@ -14,6 +14,8 @@ import (
 	"github.com/cripplemymind9/go-market/pkg/postgres"
 )
 
+var userColumns = []string{"id", "username", "password", "email", "email_verified_at IS NOT NULL", "balance"}
+
 type UserRepo struct {
 	*postgres.Postgres
 }
@@ -23,6 +25,12 @@ func NewUserRepo(pg *postgres.Postgres) *UserRepo {
 }
 
 func (r *UserRepo) RegisterUser(ctx context.Context, user entity.User) (int, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("UserRepo.RegisterUser - r.Pool.Begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
 	sql, args, err := r.Builder.
 		Insert("users").
 		Columns("username", "password", "email").
@@ -38,7 +46,7 @@ func (r *UserRepo) RegisterUser(ctx context.Context, user entity.User) (int, err
 	}
 
 	var id int
-	err = r.Pool.QueryRow(ctx, sql, args...).Scan(&id)
+	err = tx.QueryRow(ctx, sql, args...).Scan(&id)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if ok := errors.As(err, &pgErr); ok {
@@ -46,7 +54,28 @@ func (r *UserRepo) RegisterUser(ctx context.Context, user entity.User) (int, err
 				return 0, repoerrs.ErrAlreadyExists
 			}
 		}
-		return 0, fmt.Errorf("UserRepo.CreateUser - r.Pool.QueryRow: %v", err)
+		return 0, fmt.Errorf("UserRepo.CreateUser - tx.QueryRow: %v", err)
+	}
+
+	for _, role := range user.Roles {
+		sql, args, err = r.Builder.
+			Insert("user_roles").
+			Columns("user_id", "role").
+			Values(id, role).
+			Suffix("ON CONFLICT DO NOTHING").
+			ToSql()
+		if err != nil {
+			return 0, fmt.Errorf("UserRepo.RegisterUser - r.Builder.Insert: %v", err)
+		}
+
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			return 0, fmt.Errorf("UserRepo.RegisterUser - tx.Exec: %v", err)
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("UserRepo.RegisterUser - tx.Commit: %v", err)
 	}
 
 	return id, nil
@@ -54,9 +83,9 @@ func (r *UserRepo) RegisterUser(ctx context.Context, user entity.User) (int, err
 
 func (r *UserRepo) LoginUser(ctx context.Context, username string) (entity.User, error) {
 	sql, args, err := r.Builder.
-		Select("*").From("users").
+		Select(userColumns...).From("users").
 		Where(
-			squirrel.Eq{"username": username},
+			squirrel.Eq{"username": username, "deleted_at": nil},
 		).
 		ToSql()
 	if err != nil {
@@ -69,6 +98,8 @@ func (r *UserRepo) LoginUser(ctx context.Context, username string) (entity.User,
 		&user.Username,
 		&user.Password,
 		&user.Email,
+		&user.EmailVerified,
+		&user.Balance,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -77,14 +108,19 @@ func (r *UserRepo) LoginUser(ctx context.Context, username string) (entity.User,
 		return entity.User{}, fmt.Errorf("UserRepo.LoginUser - r.Pool.QueryRow: %v", err)
 	}
 
+	user.Roles, err = r.GetUserRoles(ctx, user.ID)
+	if err != nil {
+		return entity.User{}, err
+	}
+
 	return user, nil
 }
 
 func (r *UserRepo) GetUserProfile(ctx context.Context, id int) (entity.User, error) {
 	sql, args, err := r.Builder.
-		Select("*").
+		Select(userColumns...).
 		From("users").
-		Where("id = ?", id).
+		Where("id = ? AND deleted_at IS NULL", id).
 		ToSql()
 	if err != nil {
 		return entity.User{}, fmt.Errorf("UserRepo.GetUserProfile - r.Builder.Select: %v", err)
@@ -96,6 +132,8 @@ func (r *UserRepo) GetUserProfile(ctx context.Context, id int) (entity.User, err
 		&user.Username,
 		&user.Password,
 		&user.Email,
+		&user.EmailVerified,
+		&user.Balance,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -104,5 +142,247 @@ func (r *UserRepo) GetUserProfile(ctx context.Context, id int) (entity.User, err
 		return entity.User{}, fmt.Errorf("UserRepo.GetUserProfile - r.Pool.QueryRow: %v", err)
 	}
 
+	user.Roles, err = r.GetUserRoles(ctx, user.ID)
+	if err != nil {
+		return entity.User{}, err
+	}
+
 	return user, nil
 }
+
+func (r *UserRepo) GetUsersByEmail(ctx context.Context, email string) ([]entity.User, error) {
+	sql, args, err := r.Builder.
+		Select(userColumns...).
+		From("users").
+		Where("LOWER(email) = LOWER(?) AND deleted_at IS NULL", email).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("UserRepo.GetUsersByEmail - r.Builder.Select: %v", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("UserRepo.GetUsersByEmail - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var users []entity.User
+	for rows.Next() {
+		var user entity.User
+		err = rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.Password,
+			&user.Email,
+			&user.EmailVerified,
+			&user.Balance,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("UserRepo.GetUsersByEmail - rows.Next: %v", err)
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+func (r *UserRepo) MarkEmailVerified(ctx context.Context, userId int) error {
+	sql, args, err := r.Builder.
+		Update("users").
+		Set("email_verified_at", squirrel.Expr("COALESCE(email_verified_at, CURRENT_TIMESTAMP)")).
+		Where("id = ? AND deleted_at IS NULL", userId).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("UserRepo.MarkEmailVerified - r.Builder.Update: %v", err)
+	}
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("UserRepo.MarkEmailVerified - r.Pool.Exec: %v", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *UserRepo) UpdatePassword(ctx context.Context, userId int, passwordHash string) error {
+	sql, args, err := r.Builder.
+		Update("users").
+		Set("password", passwordHash).
+		Where("id = ? AND deleted_at IS NULL", userId).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("UserRepo.UpdatePassword - r.Builder.Update: %v", err)
+	}
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("UserRepo.UpdatePassword - r.Pool.Exec: %v", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrNotFound
+	}
+
+	return nil
+}
+
+// EmailInUse сообщает, есть ли у другого активного пользователя такой адрес.
+func (r *UserRepo) EmailInUse(ctx context.Context, email string, exceptUserId int) (bool, error) {
+	sql, args, err := r.Builder.
+		Select("1").
+		From("users").
+		Where("LOWER(email) = LOWER(?) AND id <> ? AND deleted_at IS NULL", email, exceptUserId).
+		Prefix("SELECT EXISTS (").
+		Suffix(")").
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("UserRepo.EmailInUse - r.Builder.Select: %v", err)
+	}
+
+	var exists bool
+	if err = r.Pool.QueryRow(ctx, sql, args...).Scan(&exists); err != nil {
+		return false, fmt.Errorf("UserRepo.EmailInUse - r.Pool.QueryRow: %v", err)
+	}
+
+	return exists, nil
+}
+
+// UpdateUserProfile меняет имя и адрес почты. При смене адреса подтверждение
+// сбрасывается.
+func (r *UserRepo) UpdateUserProfile(ctx context.Context, userId int, username, email string) error {
+	sql, args, err := r.Builder.
+		Update("users").
+		Set("username", username).
+		Set("email_verified_at", squirrel.Expr("CASE WHEN LOWER(email) = LOWER(?) THEN email_verified_at END", email)).
+		Set("email", email).
+		Where("id = ? AND deleted_at IS NULL", userId).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("UserRepo.UpdateUserProfile - r.Builder.Update: %v", err)
+	}
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if ok := errors.As(err, &pgErr); ok {
+			if pgErr.Code == "23505" {
+				return repoerrs.ErrAlreadyExists
+			}
+		}
+		return fmt.Errorf("UserRepo.UpdateUserProfile - r.Pool.Exec: %v", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrNotFound
+	}
+
+	return nil
+}
+
+// SoftDeleteUser помечает пользователя удаленным и стирает его личные данные.
+// Строка остается, чтобы покупки и записи журнала по-прежнему ссылались на нее,
+// а имя освобождается для новых регистраций.
+func (r *UserRepo) SoftDeleteUser(ctx context.Context, userId int) error {
+	sql, args, err := r.Builder.
+		Update("users").
+		Set("username", squirrel.Expr("'deleted-user-' || id")).
+		Set("email", "").
+		Set("password", "").
+		Set("email_verified_at", nil).
+		Set("deleted_at", squirrel.Expr("CURRENT_TIMESTAMP")).
+		Where("id = ? AND deleted_at IS NULL", userId).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("UserRepo.SoftDeleteUser - r.Builder.Update: %v", err)
+	}
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("UserRepo.SoftDeleteUser - r.Pool.Exec: %v", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *UserRepo) GetUserRoles(ctx context.Context, userId int) ([]entity.Role, error) {
+	sql, args, err := r.Builder.
+		Select("role").
+		From("user_roles").
+		Where("user_id = ?", userId).
+		OrderBy("role").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("UserRepo.GetUserRoles - r.Builder.Select: %v", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("UserRepo.GetUserRoles - r.Pool.Query: %v", err)
+	}
+	defer rows.Close()
+
+	var roles []entity.Role
+	for rows.Next() {
+		var role entity.Role
+		if err = rows.Scan(&role); err != nil {
+			return nil, fmt.Errorf("UserRepo.GetUserRoles - rows.Next: %v", err)
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, nil
+}
+
+func (r *UserRepo) GrantRole(ctx context.Context, userId int, role entity.Role) error {
+	sql, args, err := r.Builder.
+		Insert("user_roles").
+		Columns("user_id", "role").
+		Values(userId, role).
+		Suffix("ON CONFLICT DO NOTHING").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("UserRepo.GrantRole - r.Builder.Insert: %v", err)
+	}
+
+	if _, err = r.Pool.Exec(ctx, sql, args...); err != nil {
+		var pgErr *pgconn.PgError
+		if ok := errors.As(err, &pgErr); ok {
+			if pgErr.Code == "23503" {
+				return repoerrs.ErrNotFound
+			}
+		}
+		return fmt.Errorf("UserRepo.GrantRole - r.Pool.Exec: %v", err)
+	}
+
+	return nil
+}
+
+func (r *UserRepo) RevokeRole(ctx context.Context, userId int, role entity.Role) error {
+	sql, args, err := r.Builder.
+		Delete("user_roles").
+		Where(squirrel.Eq{"user_id": userId, "role": role}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("UserRepo.RevokeRole - r.Builder.Delete: %v", err)
+	}
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("UserRepo.RevokeRole - r.Pool.Exec: %v", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrNotFound
+	}
+
+	return nil
+}
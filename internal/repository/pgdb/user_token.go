@@ -0,0 +1,88 @@
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/pkg/postgres"
+)
+
+type UserTokenRepo struct {
+	*postgres.Postgres
+}
+
+func NewUserTokenRepo(pg *postgres.Postgres) *UserTokenRepo {
+	return &UserTokenRepo{pg}
+}
+
+func (r *UserTokenRepo) CreateUserToken(ctx context.Context, token entity.UserToken) error {
+	sql, args, err := r.Builder.
+		Insert("user_tokens").
+		Columns("user_id", "purpose", "token_hash", "expires_at").
+		Values(
+			token.UserID,
+			token.Purpose,
+			token.TokenHash,
+			token.ExpiresAt,
+		).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("UserTokenRepo.CreateUserToken - r.Builder.Insert: %v", err)
+	}
+
+	if _, err = r.Pool.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("UserTokenRepo.CreateUserToken - r.Pool.Exec: %v", err)
+	}
+
+	return nil
+}
+
+// ConsumeUserToken помечает токен использованным и возвращает его владельца.
+// Использованный, просроченный или неизвестный токен дает repoerrs.ErrNotFound.
+func (r *UserTokenRepo) ConsumeUserToken(ctx context.Context, purpose entity.TokenPurpose, tokenHash string) (int, error) {
+	sql, args, err := r.Builder.
+		Update("user_tokens").
+		Set("used_at", squirrel.Expr("CURRENT_TIMESTAMP")).
+		Where(squirrel.Eq{"purpose": purpose, "token_hash": tokenHash, "used_at": nil}).
+		Where("expires_at > CURRENT_TIMESTAMP").
+		Suffix("RETURNING user_id").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("UserTokenRepo.ConsumeUserToken - r.Builder.Update: %v", err)
+	}
+
+	var userId int
+	if err = r.Pool.QueryRow(ctx, sql, args...).Scan(&userId); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, repoerrs.ErrNotFound
+		}
+		return 0, fmt.Errorf("UserTokenRepo.ConsumeUserToken - r.Pool.QueryRow: %v", err)
+	}
+
+	return userId, nil
+}
+
+// InvalidateUserTokens гасит все неиспользованные токены пользователя с
+// данным назначением, например остальные ссылки сброса после смены пароля.
+func (r *UserTokenRepo) InvalidateUserTokens(ctx context.Context, userId int, purpose entity.TokenPurpose) error {
+	sql, args, err := r.Builder.
+		Update("user_tokens").
+		Set("used_at", squirrel.Expr("CURRENT_TIMESTAMP")).
+		Where(squirrel.Eq{"user_id": userId, "purpose": purpose, "used_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("UserTokenRepo.InvalidateUserTokens - r.Builder.Update: %v", err)
+	}
+
+	if _, err = r.Pool.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("UserTokenRepo.InvalidateUserTokens - r.Pool.Exec: %v", err)
+	}
+
+	return nil
+}
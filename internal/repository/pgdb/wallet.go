@@ -0,0 +1,201 @@
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/cripplemymind9/go-market/internal/ledger"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/pkg/postgres"
+)
+
+type WalletRepo struct {
+	*postgres.Postgres
+}
+
+func NewWalletRepo(pg *postgres.Postgres) *WalletRepo {
+	return &WalletRepo{pg}
+}
+
+func (r *WalletRepo) GetBalance(ctx context.Context, userId int) (float64, error) {
+	sql, args, err := r.Builder.
+		Select("balance").
+		From("users").
+		Where("id = ?", userId).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("WalletRepo.GetBalance - r.Builder.Select: %v", err)
+	}
+
+	var balance float64
+	err = r.Pool.QueryRow(ctx, sql, args...).Scan(&balance)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, repoerrs.ErrNotFound
+		}
+		return 0, fmt.Errorf("WalletRepo.GetBalance - r.Pool.QueryRow: %v", err)
+	}
+
+	return balance, nil
+}
+
+// Deposit зачисляет amount, округленную до копеек, на баланс пользователя и
+// проводит ее по журналу. Баланс и журнал получают одну и ту же сумму.
+func (r *WalletRepo) Deposit(ctx context.Context, userId int, amount float64) (float64, error) {
+	minor := ledger.ToMinor(amount)
+	if minor <= 0 {
+		return 0, repoerrs.ErrInvalidAmount
+	}
+
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("WalletRepo.Deposit - r.Pool.Begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	sql, args, err := r.Builder.
+		Update("users").
+		Set("balance", squirrel.Expr("balance + ?", ledger.FromMinor(minor))).
+		Where("id = ?", userId).
+		Suffix("RETURNING balance").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("WalletRepo.Deposit - r.Builder.Update: %v", err)
+	}
+
+	var balance float64
+	err = tx.QueryRow(ctx, sql, args...).Scan(&balance)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, repoerrs.ErrNotFound
+		}
+		return 0, fmt.Errorf("WalletRepo.Deposit - tx.QueryRow: %v", err)
+	}
+
+	entry := ledger.Transfer(ledger.EntryDeposit, userId, ledger.External(), ledger.UserWallet(userId), minor)
+	if err = postEntry(ctx, tx, r.Builder, entry); err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("WalletRepo.Deposit - tx.Commit: %v", err)
+	}
+
+	return balance, nil
+}
+
+// Withdraw списывает amount, округленную до копеек, с баланса пользователя и
+// проводит ее по журналу.
+func (r *WalletRepo) Withdraw(ctx context.Context, userId int, amount float64) (float64, error) {
+	minor := ledger.ToMinor(amount)
+
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("WalletRepo.Withdraw - r.Pool.Begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	balance, err := debitBalance(ctx, tx, r.Builder, userId, ledger.FromMinor(minor))
+	if err != nil {
+		return 0, err
+	}
+
+	entry := ledger.Transfer(ledger.EntryWithdraw, userId, ledger.UserWallet(userId), ledger.External(), minor)
+	if err = postEntry(ctx, tx, r.Builder, entry); err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("WalletRepo.Withdraw - tx.Commit: %v", err)
+	}
+
+	return balance, nil
+}
+
+// debitBalance блокирует строку пользователя и списывает amount с его баланса
+// в рамках переданной транзакции. Возвращает остаток после списания.
+// Сумма округляется до копеек, как в журнале. Неположительная сумма
+// отклоняется: иначе списание пополнило бы баланс.
+func debitBalance(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, userId int, amount float64) (float64, error) {
+	amount = ledger.FromMinor(ledger.ToMinor(amount))
+	if amount <= 0 {
+		return 0, repoerrs.ErrInvalidAmount
+	}
+
+	sql, args, err := builder.
+		Select("balance").
+		From("users").
+		Where("id = ?", userId).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("debitBalance - builder.Select: %v", err)
+	}
+
+	var balance float64
+	err = tx.QueryRow(ctx, sql, args...).Scan(&balance)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, repoerrs.ErrNotFound
+		}
+		return 0, fmt.Errorf("debitBalance - tx.QueryRow: %v", err)
+	}
+
+	if balance < amount {
+		return 0, repoerrs.ErrNotEnoughBalance
+	}
+
+	sql, args, err = builder.
+		Update("users").
+		Set("balance", squirrel.Expr("balance - ?", amount)).
+		Where("id = ?", userId).
+		Suffix("RETURNING balance").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("debitBalance - builder.Update: %v", err)
+	}
+
+	err = tx.QueryRow(ctx, sql, args...).Scan(&balance)
+	if err != nil {
+		return 0, fmt.Errorf("debitBalance - tx.QueryRow: %v", err)
+	}
+
+	return balance, nil
+}
+
+// creditBalance зачисляет amount на баланс пользователя в рамках переданной
+// транзакции. Возвращает остаток после зачисления. Сумма округляется до
+// копеек, как в журнале; неположительная сумма отклоняется.
+func creditBalance(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, userId int, amount float64) (float64, error) {
+	amount = ledger.FromMinor(ledger.ToMinor(amount))
+	if amount <= 0 {
+		return 0, repoerrs.ErrInvalidAmount
+	}
+
+	sql, args, err := builder.
+		Update("users").
+		Set("balance", squirrel.Expr("balance + ?", amount)).
+		Where("id = ?", userId).
+		Suffix("RETURNING balance").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("creditBalance - builder.Update: %v", err)
+	}
+
+	var balance float64
+	err = tx.QueryRow(ctx, sql, args...).Scan(&balance)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, repoerrs.ErrNotFound
+		}
+		return 0, fmt.Errorf("creditBalance - tx.QueryRow: %v", err)
+	}
+
+	return balance, nil
+}
@@ -0,0 +1,44 @@
+package pgdb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cripplemymind9/go-market/internal/ledger"
+)
+
+func TestWalletRepo_SubKopeckAmountsMatchLedger(t *testing.T) {
+	pg := newTestPostgres(t)
+	ctx := context.Background()
+
+	walletRepo := NewWalletRepo(pg)
+	ledgerRepo := NewLedgerRepo(pg)
+	buyerID := newTestBuyer(t, pg, 0)
+
+	// Postgres округлил бы 1.005 до 1.01, а журнал - до 1.00.
+	if _, err := walletRepo.Deposit(ctx, buyerID, 1.005); err != nil {
+		t.Fatalf("Deposit() error = %v", err)
+	}
+	balance, err := walletRepo.Withdraw(ctx, buyerID, 0.125)
+	if err != nil {
+		t.Fatalf("Withdraw() error = %v", err)
+	}
+	if balance != 0.87 {
+		t.Errorf("balance = %v, want %v", balance, 0.87)
+	}
+
+	accounts, err := ledgerRepo.GetAccountBalances(ctx)
+	if err != nil {
+		t.Fatalf("GetAccountBalances() error = %v", err)
+	}
+	wallets, err := ledgerRepo.GetWalletBalances(ctx)
+	if err != nil {
+		t.Fatalf("GetWalletBalances() error = %v", err)
+	}
+
+	for _, drift := range ledger.Reconcile(accounts, wallets).Drifts {
+		if drift.UserID == buyerID {
+			t.Errorf("wallet drifted from ledger: %+v", drift)
+		}
+	}
+}
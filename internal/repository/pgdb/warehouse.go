@@ -0,0 +1,328 @@
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/cripplemymind9/go-market/internal/allocation"
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/pkg/postgres"
+)
+
+// warehouseColumns перечисляет колонки склада в порядке сканирования в
+// scanWarehouse.
+var warehouseColumns = []string{"id", "name", "priority", "latitude", "longitude", "created_at"}
+
+type WarehouseRepo struct {
+	*postgres.Postgres
+}
+
+func NewWarehouseRepo(pg *postgres.Postgres) *WarehouseRepo {
+	return &WarehouseRepo{pg}
+}
+
+func (r *WarehouseRepo) CreateWarehouse(ctx context.Context, warehouse entity.Warehouse) (int, error) {
+	latitude, longitude := geoColumns(warehouse.Location)
+
+	sql, args, err := r.Builder.
+		Insert("warehouses").
+		Columns("name", "priority", "latitude", "longitude").
+		Values(warehouse.Name, warehouse.Priority, latitude, longitude).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("WarehouseRepo.CreateWarehouse - r.Builder.Insert: %v", err)
+	}
+
+	var id int
+	if err = r.Pool.QueryRow(ctx, sql, args...).Scan(&id); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return 0, repoerrs.ErrAlreadyExists
+		}
+		return 0, fmt.Errorf("WarehouseRepo.CreateWarehouse - r.Pool.QueryRow: %v", err)
+	}
+
+	return id, nil
+}
+
+func (r *WarehouseRepo) GetWarehouses(ctx context.Context) ([]entity.Warehouse, error) {
+	sql, args, err := r.Builder.
+		Select(warehouseColumns...).
+		From("warehouses").
+		OrderBy("priority DESC", "id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("WarehouseRepo.GetWarehouses - r.Builder.Select: %v", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("WarehouseRepo.GetWarehouses - r.Pool.Query: %v", err)
+	}
+
+	warehouses, err := pgx.CollectRows(rows, scanWarehouse)
+	if err != nil {
+		return nil, fmt.Errorf("WarehouseRepo.GetWarehouses - pgx.CollectRows: %v", err)
+	}
+
+	return warehouses, nil
+}
+
+func (r *WarehouseRepo) GetWarehouse(ctx context.Context, warehouseId int) (entity.Warehouse, error) {
+	sql, args, err := r.Builder.
+		Select(warehouseColumns...).
+		From("warehouses").
+		Where("id = ?", warehouseId).
+		ToSql()
+	if err != nil {
+		return entity.Warehouse{}, fmt.Errorf("WarehouseRepo.GetWarehouse - r.Builder.Select: %v", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return entity.Warehouse{}, fmt.Errorf("WarehouseRepo.GetWarehouse - r.Pool.Query: %v", err)
+	}
+
+	warehouse, err := pgx.CollectExactlyOneRow(rows, scanWarehouse)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.Warehouse{}, repoerrs.ErrNotFound
+		}
+		return entity.Warehouse{}, fmt.Errorf("WarehouseRepo.GetWarehouse - pgx.CollectExactlyOneRow: %v", err)
+	}
+
+	return warehouse, nil
+}
+
+func (r *WarehouseRepo) UpdateWarehouse(ctx context.Context, warehouse entity.Warehouse) error {
+	latitude, longitude := geoColumns(warehouse.Location)
+
+	sql, args, err := r.Builder.
+		Update("warehouses").
+		Set("name", warehouse.Name).
+		Set("priority", warehouse.Priority).
+		Set("latitude", latitude).
+		Set("longitude", longitude).
+		Where("id = ?", warehouse.ID).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("WarehouseRepo.UpdateWarehouse - r.Builder.Update: %v", err)
+	}
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return repoerrs.ErrAlreadyExists
+		}
+		return fmt.Errorf("WarehouseRepo.UpdateWarehouse - r.Pool.Exec: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return repoerrs.ErrNotFound
+	}
+
+	return nil
+}
+
+// defaultWarehouse возвращает склад с наибольшим приоритетом. На него
+// поступает товар, для которого склад не указан.
+func defaultWarehouse(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType) (int, error) {
+	sql, args, err := builder.
+		Select("id").
+		From("warehouses").
+		OrderBy("priority DESC", "id").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("defaultWarehouse - builder.Select: %v", err)
+	}
+
+	var id int
+	if err = tx.QueryRow(ctx, sql, args...).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, repoerrs.ErrNotFound
+		}
+		return 0, fmt.Errorf("defaultWarehouse - tx.QueryRow: %v", err)
+	}
+
+	return id, nil
+}
+
+// shipStock списывает строку заказа со складов, выбранных стратегией, и
+// запоминает, с каких складов она отгружена, чтобы при возврате вернуть
+// единицы туда же. Вызывающий должен держать блокировку товара.
+func shipStock(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, strategy allocation.Strategy, item entity.OrderItem, order entity.Order) error {
+	sql, args, err := builder.
+		Select("ws.warehouse_id", "ws.quantity", "w.priority", "w.latitude", "w.longitude").
+		From("warehouse_stock ws").
+		Join("warehouses w ON w.id = ws.warehouse_id").
+		Where("ws.product_id = ? AND ws.quantity > 0", item.ProductID).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("shipStock - builder.Select: %v", err)
+	}
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("shipStock - tx.Query: %v", err)
+	}
+
+	candidates, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (allocation.Candidate, error) {
+		var (
+			candidate           allocation.Candidate
+			latitude, longitude *float64
+		)
+		err := row.Scan(&candidate.WarehouseID, &candidate.Quantity, &candidate.Priority, &latitude, &longitude)
+		candidate.Location = geoPoint(latitude, longitude)
+		return candidate, err
+	})
+	if err != nil {
+		return fmt.Errorf("shipStock - pgx.CollectRows: %v", err)
+	}
+
+	allocations, err := allocation.Allocate(strategy, candidates, order.ShipTo, item.Quantity)
+	if err != nil {
+		if errors.Is(err, allocation.ErrNotEnoughStock) {
+			return repoerrs.ErrNotEnoughStock
+		}
+		return fmt.Errorf("shipStock - allocation.Allocate: %v", err)
+	}
+
+	for position, a := range allocations {
+		_, err = moveStock(ctx, tx, builder, entity.InventoryMovement{
+			ProductID:   item.ProductID,
+			WarehouseID: a.WarehouseID,
+			Kind:        entity.InventoryMovementSale,
+			Delta:       -a.Quantity,
+			ActorID:     order.UserID,
+			OrderID:     order.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		sql, args, err = builder.
+			Insert("order_item_allocations").
+			Columns("order_item_id", "warehouse_id", "position", "quantity").
+			Values(item.ID, a.WarehouseID, position, a.Quantity).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("shipStock - builder.Insert: %v", err)
+		}
+
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			return fmt.Errorf("shipStock - tx.Exec: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// returnStock возвращает movement.Delta единиц строки заказа orderItemId на
+// склады, с которых она была отгружена, начиная с последнего. Единицы, для
+// которых склад отгрузки неизвестен (заказы, оформленные до появления
+// складов), поступают на склад по умолчанию.
+func returnStock(ctx context.Context, tx pgx.Tx, builder squirrel.StatementBuilderType, orderItemId int, movement entity.InventoryMovement) error {
+	sql, args, err := builder.
+		Select("warehouse_id", "quantity - returned").
+		From("order_item_allocations").
+		Where("order_item_id = ? AND returned < quantity", orderItemId).
+		OrderBy("position DESC").
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("returnStock - builder.Select: %v", err)
+	}
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("returnStock - tx.Query: %v", err)
+	}
+
+	allocations, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (allocation.Allocation, error) {
+		var a allocation.Allocation
+		err := row.Scan(&a.WarehouseID, &a.Quantity)
+		return a, err
+	})
+	if err != nil {
+		return fmt.Errorf("returnStock - pgx.CollectRows: %v", err)
+	}
+
+	remaining := movement.Delta
+	for _, a := range allocations {
+		if remaining == 0 {
+			break
+		}
+		take := min(a.Quantity, remaining)
+
+		sql, args, err = builder.
+			Update("order_item_allocations").
+			Set("returned", squirrel.Expr("returned + ?", take)).
+			Where("order_item_id = ? AND warehouse_id = ?", orderItemId, a.WarehouseID).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("returnStock - builder.Update: %v", err)
+		}
+
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			return fmt.Errorf("returnStock - tx.Exec: %v", err)
+		}
+
+		part := movement
+		part.WarehouseID = a.WarehouseID
+		part.Delta = take
+		if _, err = moveStock(ctx, tx, builder, part); err != nil {
+			return err
+		}
+		remaining -= take
+	}
+
+	if remaining > 0 {
+		movement.WarehouseID = 0
+		movement.Delta = remaining
+		if _, err = moveStock(ctx, tx, builder, movement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// geoColumns раскладывает координаты на значения колонок latitude и longitude.
+func geoColumns(point *entity.GeoPoint) (any, any) {
+	if point == nil {
+		return nil, nil
+	}
+	return point.Latitude, point.Longitude
+}
+
+func geoPoint(latitude, longitude *float64) *entity.GeoPoint {
+	if latitude == nil || longitude == nil {
+		return nil
+	}
+	return &entity.GeoPoint{Latitude: *latitude, Longitude: *longitude}
+}
+
+func scanWarehouse(row pgx.CollectableRow) (entity.Warehouse, error) {
+	var (
+		warehouse           entity.Warehouse
+		latitude, longitude *float64
+	)
+	err := row.Scan(
+		&warehouse.ID,
+		&warehouse.Name,
+		&warehouse.Priority,
+		&latitude,
+		&longitude,
+		&warehouse.CreatedAt,
+	)
+	warehouse.Location = geoPoint(latitude, longitude)
+	return warehouse, err
+}
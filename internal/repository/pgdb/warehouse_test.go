@@ -0,0 +1,136 @@
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cripplemymind9/go-market/internal/allocation"
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+)
+
+func TestWarehouseRepo_AllocateAndReturn(t *testing.T) {
+	pg := newTestPostgres(t)
+	ctx := context.Background()
+
+	warehouseRepo := NewWarehouseRepo(pg)
+	inventoryRepo := NewInventoryRepo(pg)
+	purchaseRepo := NewPurchaseRepo(pg, allocation.Priority{})
+
+	// Отрицательные приоритеты, чтобы тестовые склады не стали складом по
+	// умолчанию для других тестов.
+	suffix := time.Now().UnixNano()
+	var warehouseIds []int
+	for _, priority := range []int{-10, -20} {
+		id, err := warehouseRepo.CreateWarehouse(ctx, entity.Warehouse{
+			Name:     fmt.Sprintf("warehouse-%d-%d", suffix, priority),
+			Priority: priority,
+		})
+		if err != nil {
+			t.Fatalf("CreateWarehouse() error = %v", err)
+		}
+		warehouseIds = append(warehouseIds, id)
+	}
+	first, second := warehouseIds[0], warehouseIds[1]
+
+	if _, err := warehouseRepo.CreateWarehouse(ctx, entity.Warehouse{Name: fmt.Sprintf("warehouse-%d-%d", suffix, -10)}); !errors.Is(err, repoerrs.ErrAlreadyExists) {
+		t.Errorf("CreateWarehouse() duplicate error = %v, want %v", err, repoerrs.ErrAlreadyExists)
+	}
+
+	productId := newTestProduct(t, NewProductRepo(pg), 1, 0)
+	buyerID := newTestBuyer(t, pg, 100)
+
+	for warehouseId, delta := range map[int]int{first: 2, second: 5} {
+		_, err := inventoryRepo.AdjustStock(ctx, entity.InventoryMovement{
+			ProductID:   productId,
+			WarehouseID: warehouseId,
+			Kind:        entity.InventoryMovementRestock,
+			Delta:       delta,
+			Reason:      "delivery",
+		})
+		if err != nil {
+			t.Fatalf("AdjustStock() error = %v", err)
+		}
+	}
+
+	if _, err := inventoryRepo.AdjustStock(ctx, entity.InventoryMovement{
+		ProductID:   productId,
+		WarehouseID: first,
+		Kind:        entity.InventoryMovementAdjustment,
+		Delta:       -3,
+		Reason:      "stocktake",
+	}); !errors.Is(err, repoerrs.ErrNotEnoughStock) {
+		t.Errorf("AdjustStock() below zero in warehouse error = %v, want %v", err, repoerrs.ErrNotEnoughStock)
+	}
+
+	// Первый склад не может отгрузить 4 единицы сам, поэтому строка целиком
+	// уходит со второго.
+	if _, err := purchaseRepo.CreateOrder(ctx, entity.Order{
+		UserID: buyerID,
+		Items:  []entity.OrderItem{{ProductID: productId, Quantity: 4}},
+	}); err != nil {
+		t.Fatalf("CreateOrder() error = %v", err)
+	}
+	assertWarehouseStock(t, inventoryRepo, productId, map[int]int{first: 2, second: 1})
+
+	// Ни один склад не может отгрузить 3 единицы сам: строка делится.
+	orderId, err := purchaseRepo.CreateOrder(ctx, entity.Order{
+		UserID: buyerID,
+		Items:  []entity.OrderItem{{ProductID: productId, Quantity: 3}},
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder() error = %v", err)
+	}
+	assertWarehouseStock(t, inventoryRepo, productId, map[int]int{first: 0, second: 0})
+
+	product, err := NewProductRepo(pg).GetProductById(ctx, productId)
+	if err != nil {
+		t.Fatalf("GetProductById() error = %v", err)
+	}
+	if product.Quantity != 0 {
+		t.Errorf("product quantity = %d, want 0", product.Quantity)
+	}
+
+	// Возврат идет на склады отгрузки, начиная с последнего.
+	if _, err = purchaseRepo.CreateRefund(ctx, entity.Refund{
+		OrderID: orderId,
+		ActorID: buyerID,
+		Items:   []entity.RefundItem{{ProductID: productId, Quantity: 2}},
+	}, entity.OrderStatusPaid); err != nil {
+		t.Fatalf("CreateRefund() error = %v", err)
+	}
+	assertWarehouseStock(t, inventoryRepo, productId, map[int]int{first: 1, second: 1})
+
+	err = NewOrderRepo(pg).CancelOrder(ctx, entity.OrderStatusChange{
+		OrderID: orderId,
+		From:    entity.OrderStatusPaid,
+		To:      entity.OrderStatusCancelled,
+		ActorID: buyerID,
+	}, true)
+	if err != nil {
+		t.Fatalf("CancelOrder() error = %v", err)
+	}
+	assertWarehouseStock(t, inventoryRepo, productId, map[int]int{first: 2, second: 1})
+}
+
+func assertWarehouseStock(t *testing.T, inventoryRepo *InventoryRepo, productId int, want map[int]int) {
+	t.Helper()
+
+	stock, err := inventoryRepo.GetProductStock(context.Background(), productId)
+	if err != nil {
+		t.Fatalf("GetProductStock() error = %v", err)
+	}
+
+	got := make(map[int]int)
+	for _, s := range stock {
+		got[s.WarehouseID] = s.Quantity
+	}
+	for warehouseId, quantity := range want {
+		if got[warehouseId] != quantity {
+			t.Errorf("stock in warehouse %d = %d, want %d", warehouseId, got[warehouseId], quantity)
+		}
+	}
+}
@@ -2,8 +2,11 @@ package repository
 
 import (
 	"context"
+	"time"
 
+	"github.com/cripplemymind9/go-market/internal/allocation"
 	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/ledger"
 	"github.com/cripplemymind9/go-market/internal/repository/pgdb"
 	"github.com/cripplemymind9/go-market/pkg/postgres"
 )
@@ -12,32 +15,186 @@ type User interface {
 	RegisterUser(ctx context.Context, user entity.User) (int, error)
 	LoginUser(ctx context.Context, username string) (entity.User, error)
 	GetUserProfile(ctx context.Context, userId int) (entity.User, error)
+	GetUsersByEmail(ctx context.Context, email string) ([]entity.User, error)
+	MarkEmailVerified(ctx context.Context, userId int) error
+	UpdatePassword(ctx context.Context, userId int, passwordHash string) error
+	EmailInUse(ctx context.Context, email string, exceptUserId int) (bool, error)
+	UpdateUserProfile(ctx context.Context, userId int, username, email string) error
+	SoftDeleteUser(ctx context.Context, userId int) error
+	GetUserRoles(ctx context.Context, userId int) ([]entity.Role, error)
+	GrantRole(ctx context.Context, userId int, role entity.Role) error
+	RevokeRole(ctx context.Context, userId int, role entity.Role) error
+}
+
+type UserToken interface {
+	CreateUserToken(ctx context.Context, token entity.UserToken) error
+	ConsumeUserToken(ctx context.Context, purpose entity.TokenPurpose, tokenHash string) (int, error)
+	InvalidateUserTokens(ctx context.Context, userId int, purpose entity.TokenPurpose) error
+}
+
+type Session interface {
+	CreateSession(ctx context.Context, session entity.Session) error
+	GetSession(ctx context.Context, id string) (entity.Session, error)
+	GetSessionByHandle(ctx context.Context, handle string) (entity.Session, error)
+	RotateSession(ctx context.Context, id, oldHash, newHash string, expiresAt time.Time) error
+	RevokeSession(ctx context.Context, id string) error
+	RevokeUserSessions(ctx context.Context, userId int) error
+}
+
+type TwoFactor interface {
+	GetTOTP(ctx context.Context, userId int) (entity.UserTOTP, error)
+	SaveTOTP(ctx context.Context, userId int, secret string) error
+	ConfirmTOTP(ctx context.Context, userId int, step int64, recoveryCodeHashes []string) error
+	UseTOTPStep(ctx context.Context, userId int, step int64) error
+	GetRecoveryCodes(ctx context.Context, userId int) ([]entity.RecoveryCode, error)
+	UseRecoveryCode(ctx context.Context, id int) error
+	DeleteTOTP(ctx context.Context, userId int) error
+}
+
+type APIKey interface {
+	CreateAPIKey(ctx context.Context, key entity.APIKey) (int, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (entity.APIKey, error)
+	GetUserAPIKeys(ctx context.Context, userId int) ([]entity.APIKey, error)
+	RevokeAPIKey(ctx context.Context, userId, id int) error
+	TouchAPIKey(ctx context.Context, id int, usedAt time.Time) error
+}
+
+type ExternalIdentity interface {
+	CreateOIDCLoginState(ctx context.Context, state entity.OIDCLoginState) error
+	ConsumeOIDCLoginState(ctx context.Context, stateHash string) (entity.OIDCLoginState, error)
+	GetExternalIdentity(ctx context.Context, provider, subject string) (entity.ExternalIdentity, error)
+	LinkExternalIdentity(ctx context.Context, identity entity.ExternalIdentity) error
+	CreateExternalUser(ctx context.Context, user entity.User, identity entity.ExternalIdentity) (int, error)
+}
+
+type LoginAttempt interface {
+	ReserveLoginAttempt(ctx context.Context, key string, at, resetBefore time.Time) (entity.LoginAttempt, error)
+	ReleaseLoginAttempt(ctx context.Context, key string) error
+	ResetLoginAttempts(ctx context.Context, key string) error
+}
+
+type Idempotency interface {
+	CreateIdempotencyRecord(ctx context.Context, record entity.IdempotencyRecord, expiredBefore time.Time) error
+	GetIdempotencyRecord(ctx context.Context, userId int, scope, key string) (entity.IdempotencyRecord, error)
+	CompleteIdempotencyRecord(ctx context.Context, record entity.IdempotencyRecord) error
+	DeleteIdempotencyRecord(ctx context.Context, userId int, scope, key string) error
 }
 
 type Product interface {
 	AddProduct(ctx context.Context, product entity.Product) (int, error)
 	GetAllProducts(ctx context.Context) ([]entity.Product, error)
+	GetProductsBySeller(ctx context.Context, sellerId int) ([]entity.Product, error)
 	GetProductById(ctx context.Context, productId int) (entity.Product, error)
 	UpdateProduct(ctx context.Context, product entity.Product) error
 	DeleteProduct(ctx context.Context, productId int) error
+	SetReorderThreshold(ctx context.Context, productId int, threshold *int) error
+}
+
+type Inventory interface {
+	AdjustStock(ctx context.Context, movement entity.InventoryMovement) (entity.InventoryMovement, error)
+	GetProductMovements(ctx context.Context, productId int, from, to time.Time) ([]entity.InventoryMovement, error)
+	GetStockAt(ctx context.Context, productId int, at time.Time) (int, error)
+	GetProductStock(ctx context.Context, productId int) ([]entity.WarehouseStock, error)
+}
+
+type Warehouse interface {
+	CreateWarehouse(ctx context.Context, warehouse entity.Warehouse) (int, error)
+	GetWarehouses(ctx context.Context) ([]entity.Warehouse, error)
+	GetWarehouse(ctx context.Context, warehouseId int) (entity.Warehouse, error)
+	UpdateWarehouse(ctx context.Context, warehouse entity.Warehouse) error
+}
+
+type StockAlert interface {
+	ClaimStockAlerts(ctx context.Context, now, claimUntil time.Time, limit int) ([]entity.StockAlert, error)
+	MarkStockAlertNotified(ctx context.Context, alertId int, delivered []string, notifiedAt time.Time) error
+	RetryStockAlert(ctx context.Context, alertId int, delivered []string, nextAttemptAt time.Time) error
+	GetProductStockAlerts(ctx context.Context, productId int) ([]entity.StockAlert, error)
 }
 
 type Purchase interface {
-	MakePurchase(ctx context.Context, purchase entity.Purchase) (int, error)
-	GetUserPurchases(ctx context.Context, userId int) ([]entity.Purchase, error)
-	GetProductPurchases(ctx context.Context, productId int) ([]entity.Purchase, error)
+	CreateOrder(ctx context.Context, order entity.Order) (int, error)
+	GetUserOrders(ctx context.Context, userId int) ([]entity.Order, error)
+	GetProductOrders(ctx context.Context, productId int) ([]entity.Order, error)
+	GetSellerSales(ctx context.Context, sellerId int) ([]entity.ProductSales, error)
+	CreateRefund(ctx context.Context, refund entity.Refund, from entity.OrderStatus) (entity.Refund, error)
+	GetOrderRefunds(ctx context.Context, orderId int) ([]entity.Refund, error)
+}
+
+type Order interface {
+	GetOrder(ctx context.Context, orderId int) (entity.Order, error)
+	GetOrderHistory(ctx context.Context, orderId int) ([]entity.OrderStatusChange, error)
+	UpdateOrderStatus(ctx context.Context, change entity.OrderStatusChange) error
+	CancelOrder(ctx context.Context, change entity.OrderStatusChange, refund bool) error
+}
+
+type Reservation interface {
+	CreateReservation(ctx context.Context, reservation entity.Reservation) (entity.Reservation, error)
+	GetReservation(ctx context.Context, reservationId int) (entity.Reservation, error)
+	ConfirmReservation(ctx context.Context, reservationId int, shipTo *entity.GeoPoint, now time.Time) (int, error)
+	ReleaseReservation(ctx context.Context, reservationId int) error
+	ReleaseExpiredReservations(ctx context.Context, now time.Time, limit int) (int, error)
+}
+
+type Cart interface {
+	GetCartItems(ctx context.Context, userId int) ([]entity.CartItem, error)
+	AddCartItem(ctx context.Context, userId, productId, quantity int) error
+	SetCartItemQuantity(ctx context.Context, userId, productId, quantity int) error
+	RemoveCartItem(ctx context.Context, userId, productId int) error
+	ClearCart(ctx context.Context, userId int) error
+	Checkout(ctx context.Context, userId int, shipTo *entity.GeoPoint) (int, error)
+}
+
+type Wallet interface {
+	GetBalance(ctx context.Context, userId int) (float64, error)
+	Deposit(ctx context.Context, userId int, amount float64) (float64, error)
+	Withdraw(ctx context.Context, userId int, amount float64) (float64, error)
+}
+
+type Ledger interface {
+	GetAccountBalances(ctx context.Context) ([]ledger.AccountBalance, error)
+	GetWalletBalances(ctx context.Context) (map[int]int64, error)
 }
 
 type Repositories struct {
 	User
+	UserToken
+	Session
+	TwoFactor
+	APIKey
+	ExternalIdentity
+	LoginAttempt
+	Idempotency
 	Product
+	Inventory
+	Warehouse
+	StockAlert
 	Purchase
+	Order
+	Reservation
+	Cart
+	Wallet
+	Ledger
 }
 
-func NewRepositories(pg *postgres.Postgres) *Repositories {
+func NewRepositories(pg *postgres.Postgres, strategy allocation.Strategy) *Repositories {
 	return &Repositories{
-		User:     pgdb.NewUserRepo(pg),
-		Product:  pgdb.NewProductRepo(pg),
-		Purchase: pgdb.NewPurchaseRepo(pg),
+		User:             pgdb.NewUserRepo(pg),
+		UserToken:        pgdb.NewUserTokenRepo(pg),
+		Session:          pgdb.NewSessionRepo(pg),
+		TwoFactor:        pgdb.NewTwoFactorRepo(pg),
+		APIKey:           pgdb.NewAPIKeyRepo(pg),
+		ExternalIdentity: pgdb.NewExternalIdentityRepo(pg),
+		LoginAttempt:     pgdb.NewLoginAttemptRepo(pg),
+		Idempotency:      pgdb.NewIdempotencyRepo(pg),
+		Product:          pgdb.NewProductRepo(pg),
+		Inventory:        pgdb.NewInventoryRepo(pg),
+		Warehouse:        pgdb.NewWarehouseRepo(pg),
+		StockAlert:       pgdb.NewStockAlertRepo(pg),
+		Purchase:         pgdb.NewPurchaseRepo(pg, strategy),
+		Order:            pgdb.NewOrderRepo(pg),
+		Reservation:      pgdb.NewReservationRepo(pg, strategy),
+		Cart:             pgdb.NewCartRepo(pg, strategy),
+		Wallet:           pgdb.NewWalletRepo(pg),
+		Ledger:           pgdb.NewLedgerRepo(pg),
 	}
 }
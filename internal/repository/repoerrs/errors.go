@@ -6,4 +6,10 @@ var (
 	ErrNotFound         = errors.New("not found")
 	ErrAlreadyExists    = errors.New("already exists")
 	ErrNotEnoughBalance = errors.New("not enough balance")
+	ErrNotEnoughStock   = errors.New("not enough stock")
+	ErrEmptyCart        = errors.New("empty cart")
+	ErrConflict         = errors.New("conflict")
+	ErrRefundExceeded   = errors.New("refund exceeds purchased quantity")
+	ErrInvalidAmount    = errors.New("amount must be positive")
+	ErrInvalidPrice     = errors.New("price must be positive")
 )
@@ -0,0 +1,249 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+	"github.com/cripplemymind9/go-market/pkg/hasher"
+	"github.com/cripplemymind9/go-market/pkg/mailer"
+	"github.com/cripplemymind9/go-market/pkg/passwordpolicy"
+	"github.com/cripplemymind9/go-market/pkg/signedtoken"
+)
+
+// AccountConfig - адреса страниц, на которые ведут ссылки из писем, и время
+// жизни токенов в них. Токен передается в параметре token.
+type AccountConfig struct {
+	VerifyEmailURL   string
+	ResetPasswordURL string
+	VerifyEmailTTL   time.Duration
+	ResetPasswordTTL time.Duration
+}
+
+type AccountService struct {
+	userRepo       repository.User
+	userTokenRepo  repository.UserToken
+	sessionRepo    repository.Session
+	passwordHasher hasher.PasswordHasher
+	passwordPolicy passwordpolicy.Policy
+	mailer         mailer.Mailer
+	signer         *signedtoken.Signer
+	cfg            AccountConfig
+}
+
+func NewAccountService(
+	userRepo repository.User,
+	userTokenRepo repository.UserToken,
+	sessionRepo repository.Session,
+	passwordHasher hasher.PasswordHasher,
+	passwordPolicy passwordpolicy.Policy,
+	mailer mailer.Mailer,
+	signer *signedtoken.Signer,
+	cfg AccountConfig,
+) *AccountService {
+	return &AccountService{
+		userRepo:       userRepo,
+		userTokenRepo:  userTokenRepo,
+		sessionRepo:    sessionRepo,
+		passwordHasher: passwordHasher,
+		passwordPolicy: passwordPolicy,
+		mailer:         mailer,
+		signer:         signer,
+		cfg:            cfg,
+	}
+}
+
+func (s *AccountService) SendVerificationEmail(ctx context.Context, userId int) error {
+	user, err := s.userRepo.GetUserProfile(ctx, userId)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return serviceerrs.ErrUserNotFound
+		}
+		log.Errorf("AccountService.SendVerificationEmail - s.userRepo.GetUserProfile: %v", err)
+		return serviceerrs.ErrCannotGetUser
+	}
+
+	if user.EmailVerified {
+		return serviceerrs.ErrEmailAlreadyVerified
+	}
+
+	return s.sendVerificationEmail(ctx, user)
+}
+
+// ResendVerificationEmail повторно отправляет письмо всем неподтвержденным
+// аккаунтам с этим адресом. Ответ не зависит от того, есть ли такие аккаунты.
+func (s *AccountService) ResendVerificationEmail(ctx context.Context, email string) error {
+	users, err := s.userRepo.GetUsersByEmail(ctx, email)
+	if err != nil {
+		log.Errorf("AccountService.ResendVerificationEmail - s.userRepo.GetUsersByEmail: %v", err)
+		return serviceerrs.ErrCannotGetUser
+	}
+
+	for _, user := range users {
+		if user.EmailVerified {
+			continue
+		}
+		if err = s.sendVerificationEmail(ctx, user); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *AccountService) VerifyEmail(ctx context.Context, token string) error {
+	userId, err := s.consumeToken(ctx, entity.TokenPurposeVerifyEmail, token)
+	if err != nil {
+		return err
+	}
+
+	if err = s.userRepo.MarkEmailVerified(ctx, userId); err != nil {
+		log.Errorf("AccountService.VerifyEmail - s.userRepo.MarkEmailVerified: %v", err)
+		return serviceerrs.ErrCannotVerifyEmail
+	}
+
+	return nil
+}
+
+// ForgotPassword отправляет ссылку для сброса пароля на каждый аккаунт с этим
+// адресом. Ответ не зависит от того, есть ли такие аккаунты.
+func (s *AccountService) ForgotPassword(ctx context.Context, email string) error {
+	users, err := s.userRepo.GetUsersByEmail(ctx, email)
+	if err != nil {
+		log.Errorf("AccountService.ForgotPassword - s.userRepo.GetUsersByEmail: %v", err)
+		return serviceerrs.ErrCannotGetUser
+	}
+
+	for _, user := range users {
+		link, err := s.issueLink(ctx, user.ID, entity.TokenPurposeResetPassword, s.cfg.ResetPasswordURL, s.cfg.ResetPasswordTTL)
+		if err != nil {
+			return err
+		}
+
+		err = s.send(ctx, mailer.Message{
+			To:      user.Email,
+			Subject: "Сброс пароля GoMarket",
+			Body: fmt.Sprintf("Здравствуйте, %s!\n\nЧтобы задать новый пароль, перейдите по ссылке:\n%s\n\n"+
+				"Ссылка действует %s. Если вы не запрашивали сброс, просто проигнорируйте это письмо.\n",
+				user.Username, link, s.cfg.ResetPasswordTTL),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ResetPassword задает новый пароль по токену из письма. Все сессии
+// пользователя и остальные ссылки сброса при этом отзываются.
+func (s *AccountService) ResetPassword(ctx context.Context, input types.AccountResetPasswordInput) error {
+	// Слабый пароль проверяется до использования токена, чтобы по той же
+	// ссылке можно было попробовать другой.
+	if err := checkPasswordPolicy(s.passwordPolicy, input.Password); err != nil {
+		return err
+	}
+
+	userId, err := s.consumeToken(ctx, entity.TokenPurposeResetPassword, input.Token)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.passwordHasher.HashPassword(input.Password)
+	if err != nil {
+		return serviceerrs.ErrPasswordHashingFailed
+	}
+
+	if err = s.userRepo.UpdatePassword(ctx, userId, hashedPassword); err != nil {
+		log.Errorf("AccountService.ResetPassword - s.userRepo.UpdatePassword: %v", err)
+		return serviceerrs.ErrCannotResetPassword
+	}
+
+	if err = s.userTokenRepo.InvalidateUserTokens(ctx, userId, entity.TokenPurposeResetPassword); err != nil {
+		log.Errorf("AccountService.ResetPassword - s.userTokenRepo.InvalidateUserTokens: %v", err)
+	}
+
+	if err = s.sessionRepo.RevokeUserSessions(ctx, userId); err != nil {
+		log.Errorf("AccountService.ResetPassword - s.sessionRepo.RevokeUserSessions: %v", err)
+		return serviceerrs.ErrCannotRevokeSession
+	}
+
+	// Ссылка пришла на почту, значит адрес принадлежит пользователю.
+	if err = s.userRepo.MarkEmailVerified(ctx, userId); err != nil {
+		log.Errorf("AccountService.ResetPassword - s.userRepo.MarkEmailVerified: %v", err)
+	}
+
+	return nil
+}
+
+func (s *AccountService) sendVerificationEmail(ctx context.Context, user entity.User) error {
+	link, err := s.issueLink(ctx, user.ID, entity.TokenPurposeVerifyEmail, s.cfg.VerifyEmailURL, s.cfg.VerifyEmailTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Подтверждение почты GoMarket",
+		Body: fmt.Sprintf("Здравствуйте, %s!\n\nПодтвердите адрес почты, перейдя по ссылке:\n%s\n\nСсылка действует %s.\n",
+			user.Username, link, s.cfg.VerifyEmailTTL),
+	})
+}
+
+// issueLink выпускает одноразовый токен, сохраняет его хэш и возвращает
+// ссылку с токеном.
+func (s *AccountService) issueLink(ctx context.Context, userId int, purpose entity.TokenPurpose, baseURL string, ttl time.Duration) (string, error) {
+	token, hash, err := s.signer.Issue(string(purpose))
+	if err != nil {
+		log.Errorf("AccountService.issueLink - s.signer.Issue: %v", err)
+		return "", serviceerrs.ErrCannotSendEmail
+	}
+
+	err = s.userTokenRepo.CreateUserToken(ctx, entity.UserToken{
+		UserID:    userId,
+		Purpose:   purpose,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		log.Errorf("AccountService.issueLink - s.userTokenRepo.CreateUserToken: %v", err)
+		return "", serviceerrs.ErrCannotSendEmail
+	}
+
+	return baseURL + "?token=" + url.QueryEscape(token), nil
+}
+
+func (s *AccountService) send(ctx context.Context, msg mailer.Message) error {
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		log.Errorf("AccountService.send - s.mailer.Send: %v", err)
+		return serviceerrs.ErrCannotSendEmail
+	}
+	return nil
+}
+
+func (s *AccountService) consumeToken(ctx context.Context, purpose entity.TokenPurpose, token string) (int, error) {
+	hash, err := s.signer.Verify(string(purpose), token)
+	if err != nil {
+		return 0, serviceerrs.ErrInvalidEmailToken
+	}
+
+	userId, err := s.userTokenRepo.ConsumeUserToken(ctx, purpose, hash)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return 0, serviceerrs.ErrInvalidEmailToken
+		}
+		log.Errorf("AccountService.consumeToken - s.userTokenRepo.ConsumeUserToken: %v", err)
+		return 0, serviceerrs.ErrCannotVerifyToken
+	}
+
+	return userId, nil
+}
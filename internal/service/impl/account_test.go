@@ -0,0 +1,168 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/mocks/repomocks"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+	"github.com/cripplemymind9/go-market/pkg/hasher"
+	"github.com/cripplemymind9/go-market/pkg/mailer"
+	"github.com/cripplemymind9/go-market/pkg/passwordpolicy"
+	"github.com/cripplemymind9/go-market/pkg/signedtoken"
+)
+
+var testAccountConfig = AccountConfig{
+	VerifyEmailURL:   "http://localhost:8080/auth/verify-email",
+	ResetPasswordURL: "http://localhost:8080/reset-password",
+	VerifyEmailTTL:   time.Hour,
+	ResetPasswordTTL: time.Hour,
+}
+
+// tokenFromMessage достает токен из ссылки в письме.
+func tokenFromMessage(t *testing.T, msg mailer.Message) string {
+	t.Helper()
+
+	link := regexp.MustCompile(`http\S+`).FindString(msg.Body)
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("invalid link %q: %v", link, err)
+	}
+	return u.Query().Get("token")
+}
+
+func TestAccountService_VerifyEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	userRepo := repomocks.NewMockUser(ctrl)
+	userTokenRepo := repomocks.NewMockUserToken(ctrl)
+	sessionRepo := repomocks.NewMockSession(ctrl)
+	m := mailer.NewMemoryMailer()
+	signer := signedtoken.NewSigner([]byte("secret"))
+
+	s := NewAccountService(userRepo, userTokenRepo, sessionRepo, nil, passwordpolicy.DefaultPolicy(), m, signer, testAccountConfig)
+
+	var stored entity.UserToken
+	userRepo.EXPECT().GetUserProfile(ctx, 1).Return(entity.User{ID: 1, Username: "test", Email: "test@example.com"}, nil)
+	userTokenRepo.EXPECT().CreateUserToken(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, token entity.UserToken) error {
+		stored = token
+		return nil
+	})
+
+	if err := s.SendVerificationEmail(ctx, 1); err != nil {
+		t.Fatalf("SendVerificationEmail() error = %v", err)
+	}
+
+	messages := m.Messages()
+	if len(messages) != 1 || messages[0].To != "test@example.com" {
+		t.Fatalf("SendVerificationEmail() sent %+v", messages)
+	}
+	token := tokenFromMessage(t, messages[0])
+	if stored.Purpose != entity.TokenPurposeVerifyEmail || stored.TokenHash == token {
+		t.Fatalf("stored token = %+v, want hashed verify_email token", stored)
+	}
+
+	userTokenRepo.EXPECT().ConsumeUserToken(ctx, entity.TokenPurposeVerifyEmail, stored.TokenHash).Return(1, nil)
+	userRepo.EXPECT().MarkEmailVerified(ctx, 1).Return(nil)
+
+	if err := s.VerifyEmail(ctx, token); err != nil {
+		t.Fatalf("VerifyEmail() error = %v", err)
+	}
+
+	userTokenRepo.EXPECT().ConsumeUserToken(ctx, entity.TokenPurposeVerifyEmail, stored.TokenHash).Return(0, repoerrs.ErrNotFound)
+
+	if err := s.VerifyEmail(ctx, token); !errors.Is(err, serviceerrs.ErrInvalidEmailToken) {
+		t.Errorf("VerifyEmail() reused token error = %v, want %v", err, serviceerrs.ErrInvalidEmailToken)
+	}
+}
+
+func TestAccountService_VerifyEmail_InvalidToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signer := signedtoken.NewSigner([]byte("secret"))
+	resetToken, _, err := signer.Issue(string(entity.TokenPurposeResetPassword))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewAccountService(repomocks.NewMockUser(ctrl), repomocks.NewMockUserToken(ctrl), repomocks.NewMockSession(ctrl),
+		nil, passwordpolicy.DefaultPolicy(), mailer.NewMemoryMailer(), signer, testAccountConfig)
+
+	for _, token := range []string{"", "garbage", resetToken} {
+		if err := s.VerifyEmail(context.Background(), token); !errors.Is(err, serviceerrs.ErrInvalidEmailToken) {
+			t.Errorf("VerifyEmail(%q) error = %v, want %v", token, err, serviceerrs.ErrInvalidEmailToken)
+		}
+	}
+}
+
+func TestAccountService_ResetPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	userRepo := repomocks.NewMockUser(ctrl)
+	userTokenRepo := repomocks.NewMockUserToken(ctrl)
+	sessionRepo := repomocks.NewMockSession(ctrl)
+	passwordHasher := hasher.NewBcryptHasher()
+	m := mailer.NewMemoryMailer()
+	signer := signedtoken.NewSigner([]byte("secret"))
+
+	s := NewAccountService(userRepo, userTokenRepo, sessionRepo, passwordHasher, passwordpolicy.DefaultPolicy(), m, signer, testAccountConfig)
+
+	var stored entity.UserToken
+	userRepo.EXPECT().GetUsersByEmail(ctx, "test@example.com").Return([]entity.User{{ID: 1, Username: "test", Email: "test@example.com"}}, nil)
+	userTokenRepo.EXPECT().CreateUserToken(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, token entity.UserToken) error {
+		stored = token
+		return nil
+	})
+
+	if err := s.ForgotPassword(ctx, "test@example.com"); err != nil {
+		t.Fatalf("ForgotPassword() error = %v", err)
+	}
+
+	token := tokenFromMessage(t, m.Messages()[0])
+
+	userTokenRepo.EXPECT().ConsumeUserToken(ctx, entity.TokenPurposeResetPassword, stored.TokenHash).Return(1, nil)
+	userRepo.EXPECT().UpdatePassword(ctx, 1, gomock.Any()).DoAndReturn(func(_ context.Context, _ int, hash string) error {
+		if err := passwordHasher.VerifyPassword(hash, "NewPass1!"); err != nil {
+			t.Errorf("UpdatePassword() got hash not matching the new password")
+		}
+		return nil
+	})
+	userTokenRepo.EXPECT().InvalidateUserTokens(ctx, 1, entity.TokenPurposeResetPassword).Return(nil)
+	sessionRepo.EXPECT().RevokeUserSessions(ctx, 1).Return(nil)
+	userRepo.EXPECT().MarkEmailVerified(ctx, 1).Return(nil)
+
+	err := s.ResetPassword(ctx, types.AccountResetPasswordInput{Token: token, Password: "NewPass1!"})
+	if err != nil {
+		t.Fatalf("ResetPassword() error = %v", err)
+	}
+}
+
+func TestAccountService_ResetPassword_WeakPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := NewAccountService(repomocks.NewMockUser(ctrl), repomocks.NewMockUserToken(ctrl), repomocks.NewMockSession(ctrl),
+		hasher.NewBcryptHasher(), passwordpolicy.DefaultPolicy(), mailer.NewMemoryMailer(), signedtoken.NewSigner([]byte("secret")), testAccountConfig)
+
+	// Токен не должен расходоваться: вызовов репозитория не ожидается.
+	err := s.ResetPassword(context.Background(), types.AccountResetPasswordInput{Token: "token", Password: "weak"})
+
+	var policyErr *serviceerrs.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		t.Fatalf("ResetPassword() error = %v, want PasswordPolicyError", err)
+	}
+}
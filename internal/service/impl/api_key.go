@@ -0,0 +1,199 @@
+package impl
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+const (
+	// apiKeyTokenPrefix делает ключ узнаваемым в логах и сканерах секретов.
+	apiKeyTokenPrefix = "gmk_"
+	apiKeyPrefixBytes = 6
+	apiKeySecretBytes = 32
+	// apiKeyTouchInterval - как часто обновляется время последнего
+	// использования ключа, чтобы не писать в базу на каждый запрос.
+	apiKeyTouchInterval = time.Minute
+)
+
+type APIKeyService struct {
+	apiKeyRepo repository.APIKey
+	userRepo   repository.User
+	now        func() time.Time
+}
+
+func NewAPIKeyService(apiKeyRepo repository.APIKey, userRepo repository.User) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo: apiKeyRepo,
+		userRepo:   userRepo,
+		now:        time.Now,
+	}
+}
+
+// Create выпускает ключ с указанными правами. Ключ вида gmk_<префикс>_<секрет>
+// возвращается один раз, в базе хранятся только префикс и хэш секрета.
+func (s *APIKeyService) Create(ctx context.Context, input types.APIKeyCreateInput) (types.APIKeyCreated, error) {
+	scopes, err := normalizeScopes(input.Scopes)
+	if err != nil {
+		return types.APIKeyCreated{}, err
+	}
+
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(s.now()) {
+		return types.APIKeyCreated{}, serviceerrs.ErrInvalidAPIKeyExpiry
+	}
+
+	prefixBytes := make([]byte, apiKeyPrefixBytes)
+	if _, err = rand.Read(prefixBytes); err != nil {
+		log.Errorf("APIKeyService.Create - rand.Read: %v", err)
+		return types.APIKeyCreated{}, serviceerrs.ErrCannotCreateAPIKey
+	}
+	prefix := hex.EncodeToString(prefixBytes)
+
+	secret, err := randomToken(apiKeySecretBytes)
+	if err != nil {
+		log.Errorf("APIKeyService.Create - randomToken: %v", err)
+		return types.APIKeyCreated{}, serviceerrs.ErrCannotCreateAPIKey
+	}
+
+	key := entity.APIKey{
+		UserID:     input.UserID,
+		Name:       input.Name,
+		Prefix:     prefix,
+		SecretHash: hashRefreshSecret(secret),
+		Scopes:     scopes,
+		ExpiresAt:  input.ExpiresAt,
+		CreatedAt:  s.now(),
+	}
+
+	key.ID, err = s.apiKeyRepo.CreateAPIKey(ctx, key)
+	if err != nil {
+		log.Errorf("APIKeyService.Create - s.apiKeyRepo.CreateAPIKey: %v", err)
+		return types.APIKeyCreated{}, serviceerrs.ErrCannotCreateAPIKey
+	}
+
+	return types.APIKeyCreated{
+		Key:   key,
+		Token: apiKeyTokenPrefix + prefix + "_" + secret,
+	}, nil
+}
+
+func (s *APIKeyService) List(ctx context.Context, userId int) ([]entity.APIKey, error) {
+	keys, err := s.apiKeyRepo.GetUserAPIKeys(ctx, userId)
+	if err != nil {
+		log.Errorf("APIKeyService.List - s.apiKeyRepo.GetUserAPIKeys: %v", err)
+		return nil, serviceerrs.ErrCannotGetAPIKeys
+	}
+
+	return keys, nil
+}
+
+func (s *APIKeyService) Revoke(ctx context.Context, userId, id int) error {
+	if err := s.apiKeyRepo.RevokeAPIKey(ctx, userId, id); err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return serviceerrs.ErrAPIKeyNotFound
+		}
+		log.Errorf("APIKeyService.Revoke - s.apiKeyRepo.RevokeAPIKey: %v", err)
+		return serviceerrs.ErrCannotRevokeAPIKey
+	}
+
+	return nil
+}
+
+// Authenticate проверяет ключ и возвращает личность его владельца с правами
+// ключа. Роли берутся у владельца на момент запроса.
+func (s *APIKeyService) Authenticate(ctx context.Context, token string) (types.AuthIdentity, error) {
+	prefix, secret, ok := parseAPIKeyToken(token)
+	if !ok {
+		return types.AuthIdentity{}, serviceerrs.ErrInvalidAPIKey
+	}
+
+	key, err := s.apiKeyRepo.GetAPIKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return types.AuthIdentity{}, serviceerrs.ErrInvalidAPIKey
+		}
+		log.Errorf("APIKeyService.Authenticate - s.apiKeyRepo.GetAPIKeyByPrefix: %v", err)
+		return types.AuthIdentity{}, serviceerrs.ErrCannotCheckAPIKey
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashRefreshSecret(secret)), []byte(key.SecretHash)) != 1 {
+		return types.AuthIdentity{}, serviceerrs.ErrInvalidAPIKey
+	}
+
+	now := s.now()
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && !now.Before(*key.ExpiresAt)) {
+		return types.AuthIdentity{}, serviceerrs.ErrInvalidAPIKey
+	}
+
+	user, err := s.userRepo.GetUserProfile(ctx, key.UserID)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return types.AuthIdentity{}, serviceerrs.ErrInvalidAPIKey
+		}
+		log.Errorf("APIKeyService.Authenticate - s.userRepo.GetUserProfile: %v", err)
+		return types.AuthIdentity{}, serviceerrs.ErrCannotCheckAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err = s.apiKeyRepo.TouchAPIKey(ctx, key.ID, now); err != nil {
+			log.Errorf("APIKeyService.Authenticate - s.apiKeyRepo.TouchAPIKey: %v", err)
+		}
+	}
+
+	return types.AuthIdentity{
+		UserID:   user.ID,
+		Roles:    user.Roles,
+		APIKeyID: key.ID,
+		Scopes:   key.Scopes,
+	}, nil
+}
+
+// parseAPIKeyToken разбирает ключ вида gmk_<префикс>_<секрет>. Секрет в
+// base64url может содержать "_", поэтому делится только первый разделитель.
+func parseAPIKeyToken(token string) (prefix, secret string, ok bool) {
+	rest, ok := strings.CutPrefix(token, apiKeyTokenPrefix)
+	if !ok {
+		return "", "", false
+	}
+
+	prefix, secret, ok = strings.Cut(rest, "_")
+	if !ok || len(prefix) != 2*apiKeyPrefixBytes || secret == "" {
+		return "", "", false
+	}
+
+	return prefix, secret, true
+}
+
+// normalizeScopes проверяет права и убирает повторы, сохраняя порядок.
+func normalizeScopes(scopes []entity.APIKeyScope) ([]entity.APIKeyScope, error) {
+	if len(scopes) == 0 {
+		return nil, serviceerrs.ErrInvalidAPIKeyScope
+	}
+
+	seen := make(map[entity.APIKeyScope]struct{}, len(scopes))
+	result := make([]entity.APIKeyScope, 0, len(scopes))
+	for _, scope := range scopes {
+		if !scope.Valid() {
+			return nil, serviceerrs.ErrInvalidAPIKeyScope
+		}
+		if _, ok := seen[scope]; ok {
+			continue
+		}
+		seen[scope] = struct{}{}
+		result = append(result, scope)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,164 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/mocks/repomocks"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+func TestAPIKeyService_CreateAndAuthenticate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	apiKeyRepo := repomocks.NewMockAPIKey(ctrl)
+	userRepo := repomocks.NewMockUser(ctrl)
+
+	now := time.Date(2024, 9, 5, 12, 0, 0, 0, time.UTC)
+	s := NewAPIKeyService(apiKeyRepo, userRepo)
+	s.now = func() time.Time { return now }
+
+	expiresAt := now.Add(24 * time.Hour)
+
+	var stored entity.APIKey
+	apiKeyRepo.EXPECT().CreateAPIKey(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, key entity.APIKey) (int, error) {
+		stored = key
+		return 7, nil
+	})
+
+	created, err := s.Create(ctx, types.APIKeyCreateInput{
+		UserID:    1,
+		Name:      "inventory sync",
+		Scopes:    []entity.APIKeyScope{entity.ScopeProductsRead, entity.ScopeProductsWrite, entity.ScopeProductsRead},
+		ExpiresAt: &expiresAt,
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if !strings.HasPrefix(created.Token, "gmk_"+stored.Prefix+"_") {
+		t.Fatalf("Create() token %q does not carry prefix %q", created.Token, stored.Prefix)
+	}
+	if strings.Contains(stored.SecretHash, strings.TrimPrefix(created.Token, "gmk_"+stored.Prefix+"_")) {
+		t.Fatalf("Create() stored the secret in plain text")
+	}
+	if len(stored.Scopes) != 2 || created.Key.ID != 7 {
+		t.Fatalf("Create() = %+v, want 2 deduplicated scopes and ID 7", created.Key)
+	}
+
+	stored.ID = 7
+
+	// Успешная проверка обновляет время последнего использования.
+	apiKeyRepo.EXPECT().GetAPIKeyByPrefix(ctx, stored.Prefix).Return(stored, nil)
+	userRepo.EXPECT().GetUserProfile(ctx, 1).Return(entity.User{ID: 1, Roles: []entity.Role{entity.RoleSeller}}, nil)
+	apiKeyRepo.EXPECT().TouchAPIKey(ctx, 7, now).Return(nil)
+
+	identity, err := s.Authenticate(ctx, created.Token)
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if identity.UserID != 1 || identity.APIKeyID != 7 || !identity.HasRole(entity.RoleSeller) ||
+		!identity.HasScope(entity.ScopeProductsWrite) || identity.HasScope(entity.ScopeWalletWrite) {
+		t.Fatalf("Authenticate() = %+v", identity)
+	}
+
+	// Недавно использованный ключ не обновляется повторно.
+	recent := now.Add(-10 * time.Second)
+	used := stored
+	used.LastUsedAt = &recent
+	apiKeyRepo.EXPECT().GetAPIKeyByPrefix(ctx, stored.Prefix).Return(used, nil)
+	userRepo.EXPECT().GetUserProfile(ctx, 1).Return(entity.User{ID: 1}, nil)
+
+	if _, err = s.Authenticate(ctx, created.Token); err != nil {
+		t.Fatalf("Authenticate() recently used key error = %v", err)
+	}
+
+	// Неверный секрет с верным префиксом.
+	apiKeyRepo.EXPECT().GetAPIKeyByPrefix(ctx, stored.Prefix).Return(stored, nil)
+	if _, err = s.Authenticate(ctx, "gmk_"+stored.Prefix+"_wrong"); !errors.Is(err, serviceerrs.ErrInvalidAPIKey) {
+		t.Fatalf("Authenticate() wrong secret error = %v, want %v", err, serviceerrs.ErrInvalidAPIKey)
+	}
+
+	// Истекший ключ.
+	s.now = func() time.Time { return expiresAt }
+	apiKeyRepo.EXPECT().GetAPIKeyByPrefix(ctx, stored.Prefix).Return(stored, nil)
+	if _, err = s.Authenticate(ctx, created.Token); !errors.Is(err, serviceerrs.ErrInvalidAPIKey) {
+		t.Fatalf("Authenticate() expired key error = %v, want %v", err, serviceerrs.ErrInvalidAPIKey)
+	}
+
+	// Отозванный ключ.
+	s.now = func() time.Time { return now }
+	revoked := stored
+	revoked.RevokedAt = &now
+	apiKeyRepo.EXPECT().GetAPIKeyByPrefix(ctx, stored.Prefix).Return(revoked, nil)
+	if _, err = s.Authenticate(ctx, created.Token); !errors.Is(err, serviceerrs.ErrInvalidAPIKey) {
+		t.Fatalf("Authenticate() revoked key error = %v, want %v", err, serviceerrs.ErrInvalidAPIKey)
+	}
+}
+
+func TestAPIKeyService_Authenticate_Malformed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	apiKeyRepo := repomocks.NewMockAPIKey(ctrl)
+	s := NewAPIKeyService(apiKeyRepo, repomocks.NewMockUser(ctrl))
+
+	for _, token := range []string{"", "token", "gmk_", "gmk_abc_secret", "gmk_0123456789ab_"} {
+		if _, err := s.Authenticate(ctx, token); !errors.Is(err, serviceerrs.ErrInvalidAPIKey) {
+			t.Errorf("Authenticate(%q) error = %v, want %v", token, err, serviceerrs.ErrInvalidAPIKey)
+		}
+	}
+
+	apiKeyRepo.EXPECT().GetAPIKeyByPrefix(ctx, "0123456789ab").Return(entity.APIKey{}, repoerrs.ErrNotFound)
+	if _, err := s.Authenticate(ctx, "gmk_0123456789ab_secret"); !errors.Is(err, serviceerrs.ErrInvalidAPIKey) {
+		t.Errorf("Authenticate() unknown prefix error = %v, want %v", err, serviceerrs.ErrInvalidAPIKey)
+	}
+}
+
+func TestAPIKeyService_Create_Invalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := NewAPIKeyService(repomocks.NewMockAPIKey(ctrl), repomocks.NewMockUser(ctrl))
+	past := time.Now().Add(-time.Hour)
+
+	testCases := []struct {
+		name    string
+		input   types.APIKeyCreateInput
+		wantErr error
+	}{
+		{
+			name:    "No scopes",
+			input:   types.APIKeyCreateInput{UserID: 1, Name: "sync"},
+			wantErr: serviceerrs.ErrInvalidAPIKeyScope,
+		},
+		{
+			name:    "Unknown scope",
+			input:   types.APIKeyCreateInput{UserID: 1, Name: "sync", Scopes: []entity.APIKeyScope{"admin:all"}},
+			wantErr: serviceerrs.ErrInvalidAPIKeyScope,
+		},
+		{
+			name:    "Expiry in the past",
+			input:   types.APIKeyCreateInput{UserID: 1, Name: "sync", Scopes: []entity.APIKeyScope{entity.ScopeProductsRead}, ExpiresAt: &past},
+			wantErr: serviceerrs.ErrInvalidAPIKeyExpiry,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := s.Create(context.Background(), tc.input); !errors.Is(err, tc.wantErr) {
+				t.Errorf("Create() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
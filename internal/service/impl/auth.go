@@ -2,8 +2,13 @@ package impl
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
-	"fmt"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
@@ -15,31 +20,73 @@ import (
 	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
 	"github.com/cripplemymind9/go-market/internal/service/types"
 	"github.com/cripplemymind9/go-market/pkg/hasher"
+	"github.com/cripplemymind9/go-market/pkg/jwtkeys"
+	"github.com/cripplemymind9/go-market/pkg/passwordpolicy"
 )
 
+// TokenClaims - токен доступа. SessionHandle указывает на сессию, но, в
+// отличие от ее id, не входит в refresh-токен: токены доступа проверяют и
+// сторонние сервисы, и id сессии им знать незачем.
 type TokenClaims struct {
+	jwt.RegisteredClaims
+	UserID        int
+	Username      string
+	Roles         []entity.Role
+	SessionHandle string
+}
+
+// challengeAudience отличает токен второго шага входа от токена доступа.
+const challengeAudience = "2fa-challenge"
+
+// ChallengeClaims - токен, выдаваемый после пароля, если включен второй
+// фактор. Доступа он не дает: ParseToken требует сессию, которой у него нет.
+type ChallengeClaims struct {
 	jwt.RegisteredClaims
 	UserID   int
 	Username string
 }
 
 type AuthService struct {
-	userRepo       repository.User
-	passwordHasher hasher.PasswordHasher
-	signKey        string
-	tokenTTL       time.Duration
+	userRepo        repository.User
+	sessionRepo     repository.Session
+	twoFactorRepo   repository.TwoFactor
+	passwordHasher  hasher.PasswordHasher
+	passwordPolicy  passwordpolicy.Policy
+	keys            *jwtkeys.KeySet
+	tokenTTL        time.Duration
+	refreshTokenTTL time.Duration
+	challengeTTL    time.Duration
 }
 
-func NewAuthService(userRepo repository.User, passwordHasher hasher.PasswordHasher, signKey string, tokenTTL time.Duration) *AuthService {
+func NewAuthService(
+	userRepo repository.User,
+	sessionRepo repository.Session,
+	twoFactorRepo repository.TwoFactor,
+	passwordHasher hasher.PasswordHasher,
+	passwordPolicy passwordpolicy.Policy,
+	keys *jwtkeys.KeySet,
+	tokenTTL time.Duration,
+	refreshTokenTTL time.Duration,
+	challengeTTL time.Duration,
+) *AuthService {
 	return &AuthService{
-		userRepo:       userRepo,
-		passwordHasher: passwordHasher,
-		signKey:        signKey,
-		tokenTTL:       tokenTTL,
+		userRepo:        userRepo,
+		sessionRepo:     sessionRepo,
+		twoFactorRepo:   twoFactorRepo,
+		passwordHasher:  passwordHasher,
+		passwordPolicy:  passwordPolicy,
+		keys:            keys,
+		tokenTTL:        tokenTTL,
+		refreshTokenTTL: refreshTokenTTL,
+		challengeTTL:    challengeTTL,
 	}
 }
 
 func (s *AuthService) RegisterUser(ctx context.Context, input types.AuthRegisterUserInput) (int, error) {
+	if err := checkPasswordPolicy(s.passwordPolicy, input.Password); err != nil {
+		return 0, err
+	}
+
 	hashedPassword, err := s.passwordHasher.HashPassword(input.Password)
 	if err != nil {
 		return 0, serviceerrs.ErrPasswordHashingFailed
@@ -49,6 +96,7 @@ func (s *AuthService) RegisterUser(ctx context.Context, input types.AuthRegister
 		Username: input.Username,
 		Password: hashedPassword,
 		Email:    input.Email,
+		Roles:    []entity.Role{entity.RoleBuyer},
 	}
 
 	userId, err := s.userRepo.RegisterUser(ctx, user)
@@ -63,54 +111,378 @@ func (s *AuthService) RegisterUser(ctx context.Context, input types.AuthRegister
 	return userId, nil
 }
 
-func (s *AuthService) GenerateToken(ctx context.Context, input types.AuthGenerateTokenInput) (string, error) {
+func (s *AuthService) GenerateToken(ctx context.Context, input types.AuthGenerateTokenInput) (types.AuthTokens, error) {
 	user, err := s.userRepo.LoginUser(ctx, input.Username)
 	if err != nil {
 		if errors.Is(err, repoerrs.ErrNotFound) {
-			return "", serviceerrs.ErrUserNotFound
+			return types.AuthTokens{}, serviceerrs.ErrUserNotFound
 		}
 		log.Errorf("AuthService.GenerateToken - s.userRepo.LoginUser: %v", err)
-		return "", serviceerrs.ErrCannotGetUser
+		return types.AuthTokens{}, serviceerrs.ErrCannotGetUser
 	}
 
 	if err := s.passwordHasher.VerifyPassword(user.Password, input.Password); err != nil {
 		log.Errorf("AuthService.GenerateToken - password verification failed: %v", err)
-		return "", serviceerrs.ErrInvalidPassword
+		return types.AuthTokens{}, serviceerrs.ErrInvalidPassword
+	}
+
+	s.rehashPassword(ctx, user, input.Password)
+
+	return s.beginSession(ctx, user)
+}
+
+// ParseChallengeToken проверяет токен второго шага входа и возвращает, чей он.
+func (s *AuthService) ParseChallengeToken(ctx context.Context, challengeToken string) (types.AuthChallenge, error) {
+	claims := &ChallengeClaims{}
+	token, err := jwt.ParseWithClaims(challengeToken, claims, s.keys.Keyfunc)
+	if err != nil || !token.Valid || !claims.VerifyAudience(challengeAudience, true) {
+		return types.AuthChallenge{}, serviceerrs.ErrInvalidChallengeToken
+	}
+
+	return types.AuthChallenge{
+		UserID:   claims.UserID,
+		Username: claims.Username,
+	}, nil
+}
+
+// VerifyTwoFactor завершает вход: проверяет код второго фактора и выдает
+// пару токенов.
+func (s *AuthService) VerifyTwoFactor(ctx context.Context, input types.AuthVerifyTwoFactorInput) (types.AuthTokens, error) {
+	challenge, err := s.ParseChallengeToken(ctx, input.ChallengeToken)
+	if err != nil {
+		return types.AuthTokens{}, err
+	}
+
+	user, err := s.userRepo.GetUserProfile(ctx, challenge.UserID)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return types.AuthTokens{}, serviceerrs.ErrInvalidChallengeToken
+		}
+		log.Errorf("AuthService.VerifyTwoFactor - s.userRepo.GetUserProfile: %v", err)
+		return types.AuthTokens{}, serviceerrs.ErrCannotGetUser
+	}
+
+	state, err := s.twoFactorRepo.GetTOTP(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return types.AuthTokens{}, serviceerrs.ErrInvalidChallengeToken
+		}
+		log.Errorf("AuthService.VerifyTwoFactor - s.twoFactorRepo.GetTOTP: %v", err)
+		return types.AuthTokens{}, serviceerrs.ErrCannotCheckTwoFactor
+	}
+
+	if !state.Confirmed {
+		return types.AuthTokens{}, serviceerrs.ErrInvalidChallengeToken
+	}
+
+	if err = verifySecondFactor(ctx, s.twoFactorRepo, s.passwordHasher, state, input.Code, time.Now()); err != nil {
+		return types.AuthTokens{}, err
+	}
+
+	return s.createSession(ctx, user)
+}
+
+// IssueTokens открывает сессию для пользователя, уже подтвердившего личность
+// у внешнего провайдера входа. Если у пользователя включен второй фактор,
+// вместо пары токенов, как и при входе по паролю, выдается токен второго шага.
+func (s *AuthService) IssueTokens(ctx context.Context, userId int) (types.AuthTokens, error) {
+	user, err := s.userRepo.GetUserProfile(ctx, userId)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return types.AuthTokens{}, serviceerrs.ErrUserNotFound
+		}
+		log.Errorf("AuthService.IssueTokens - s.userRepo.GetUserProfile: %v", err)
+		return types.AuthTokens{}, serviceerrs.ErrCannotGetUser
+	}
+
+	return s.beginSession(ctx, user)
+}
+
+// RefreshToken обменивает refresh-токен на новую пару токенов. Предъявленный
+// токен становится недействительным. Повторное предъявление уже обмененного
+// токена считается признаком кражи: сессия отзывается целиком. Любой другой
+// неверный секрет просто отклоняется, иначе выйти из чужой сессии мог бы
+// любой, кто знает ее id.
+func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (types.AuthTokens, error) {
+	session, secret, err := s.lookupSession(ctx, refreshToken)
+	if err != nil {
+		return types.AuthTokens{}, err
+	}
+
+	if session.RevokedAt != nil {
+		return types.AuthTokens{}, serviceerrs.ErrSessionRevoked
+	}
+
+	oldHash := hashRefreshSecret(secret)
+	if subtle.ConstantTimeCompare([]byte(oldHash), []byte(session.RefreshTokenHash)) != 1 {
+		if session.PreviousRefreshTokenHash != "" &&
+			subtle.ConstantTimeCompare([]byte(oldHash), []byte(session.PreviousRefreshTokenHash)) == 1 {
+			s.revokeReusedSession(ctx, session)
+			return types.AuthTokens{}, serviceerrs.ErrRefreshTokenReused
+		}
+		return types.AuthTokens{}, serviceerrs.ErrInvalidRefreshToken
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		return types.AuthTokens{}, serviceerrs.ErrInvalidRefreshToken
+	}
+
+	user, err := s.userRepo.GetUserProfile(ctx, session.UserID)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return types.AuthTokens{}, serviceerrs.ErrUserNotFound
+		}
+		log.Errorf("AuthService.RefreshToken - s.userRepo.GetUserProfile: %v", err)
+		return types.AuthTokens{}, serviceerrs.ErrCannotGetUser
+	}
+
+	newSecret, err := randomToken(32)
+	if err != nil {
+		log.Errorf("AuthService.RefreshToken - randomToken: %v", err)
+		return types.AuthTokens{}, serviceerrs.ErrCannotRefreshToken
+	}
+
+	err = s.sessionRepo.RotateSession(ctx, session.ID, oldHash, hashRefreshSecret(newSecret), time.Now().Add(s.refreshTokenTTL))
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			s.revokeReusedSession(ctx, session)
+			return types.AuthTokens{}, serviceerrs.ErrRefreshTokenReused
+		}
+		log.Errorf("AuthService.RefreshToken - s.sessionRepo.RotateSession: %v", err)
+		return types.AuthTokens{}, serviceerrs.ErrCannotRefreshToken
+	}
+
+	accessToken, err := s.signAccessToken(user, session.Handle)
+	if err != nil {
+		return types.AuthTokens{}, err
+	}
+
+	return types.AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: session.ID + "." + newSecret,
+	}, nil
+}
+
+// Logout отзывает сессию, которой принадлежит refresh-токен. Выданные в ее
+// рамках access-токены перестают приниматься ParseToken.
+func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
+	session, secret, err := s.lookupSession(ctx, refreshToken)
+	if err != nil {
+		return err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashRefreshSecret(secret)), []byte(session.RefreshTokenHash)) != 1 {
+		return serviceerrs.ErrInvalidRefreshToken
+	}
+
+	if err = s.sessionRepo.RevokeSession(ctx, session.ID); err != nil {
+		log.Errorf("AuthService.Logout - s.sessionRepo.RevokeSession: %v", err)
+		return serviceerrs.ErrCannotRevokeSession
+	}
+
+	return nil
+}
+
+func (s *AuthService) ParseToken(ctx context.Context, accessToken string) (types.AuthIdentity, error) {
+	claims := &TokenClaims{}
+	token, err := jwt.ParseWithClaims(accessToken, claims, s.keys.Keyfunc)
+	if err != nil {
+		return types.AuthIdentity{}, serviceerrs.ErrCannotParseToken
+	}
+
+	if !token.Valid || claims.SessionHandle == "" {
+		return types.AuthIdentity{}, serviceerrs.ErrCannotParseToken
+	}
+
+	session, err := s.sessionRepo.GetSessionByHandle(ctx, claims.SessionHandle)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return types.AuthIdentity{}, serviceerrs.ErrSessionRevoked
+		}
+		log.Errorf("AuthService.ParseToken - s.sessionRepo.GetSessionByHandle: %v", err)
+		return types.AuthIdentity{}, serviceerrs.ErrCannotParseToken
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &TokenClaims{
+	if session.RevokedAt != nil {
+		return types.AuthIdentity{}, serviceerrs.ErrSessionRevoked
+	}
+
+	return types.AuthIdentity{
+		UserID: claims.UserID,
+		Roles:  claims.Roles,
+	}, nil
+}
+
+// JWKS возвращает открытые ключи, которыми другие сервисы проверяют токены.
+func (s *AuthService) JWKS() jwtkeys.JWKSet {
+	return s.keys.JWKS()
+}
+
+// rehashPassword пересчитывает хэш пароля, если он получен устаревшим
+// алгоритмом или с устаревшими параметрами. Вход при ошибке не прерывается.
+func (s *AuthService) rehashPassword(ctx context.Context, user entity.User, password string) {
+	if !s.passwordHasher.NeedsRehash(user.Password) {
+		return
+	}
+
+	hashedPassword, err := s.passwordHasher.HashPassword(password)
+	if err != nil {
+		log.Errorf("AuthService.rehashPassword - s.passwordHasher.HashPassword: %v", err)
+		return
+	}
+
+	if err = s.userRepo.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
+		log.Errorf("AuthService.rehashPassword - s.userRepo.UpdatePassword: %v", err)
+	}
+}
+
+func (s *AuthService) signAccessToken(user entity.User, sessionHandle string) (string, error) {
+	tokenString, err := s.keys.Sign(&TokenClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.tokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
-		UserID:   user.ID,
-		Username: user.Username,
+		UserID:        user.ID,
+		Username:      user.Username,
+		Roles:         user.Roles,
+		SessionHandle: sessionHandle,
 	})
-
-	tokenString, err := token.SignedString([]byte(s.signKey))
 	if err != nil {
-		log.Errorf("AuthService.GenerateToken: caanot sign token: %v", err)
+		log.Errorf("AuthService.signAccessToken: caanot sign token: %v", err)
 		return "", serviceerrs.ErrCannotSignToken
 	}
 
 	return tokenString, nil
 }
 
-func (s *AuthService) ParseToken(accessToken string) (int, error) {
-	claims := &TokenClaims{}
-	token, err := jwt.ParseWithClaims(accessToken, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+// beginSession завершает первый шаг входа: если у пользователя подключен
+// второй фактор, выдает токен второго шага, иначе сразу открывает сессию.
+func (s *AuthService) beginSession(ctx context.Context, user entity.User) (types.AuthTokens, error) {
+	state, err := s.twoFactorRepo.GetTOTP(ctx, user.ID)
+	if err != nil && !errors.Is(err, repoerrs.ErrNotFound) {
+		log.Errorf("AuthService.beginSession - s.twoFactorRepo.GetTOTP: %v", err)
+		return types.AuthTokens{}, serviceerrs.ErrCannotCheckTwoFactor
+	}
+
+	if err == nil && state.Confirmed {
+		challengeToken, err := s.signChallengeToken(user)
+		if err != nil {
+			return types.AuthTokens{}, err
 		}
-		return []byte(s.signKey), nil
+		return types.AuthTokens{ChallengeToken: challengeToken}, nil
+	}
+
+	return s.createSession(ctx, user)
+}
+
+// createSession открывает сессию и выдает пару токенов.
+func (s *AuthService) createSession(ctx context.Context, user entity.User) (types.AuthTokens, error) {
+	sessionId, err := randomToken(16)
+	if err != nil {
+		log.Errorf("AuthService.createSession - randomToken: %v", err)
+		return types.AuthTokens{}, serviceerrs.ErrCannotCreateSession
+	}
+
+	handle, err := randomToken(16)
+	if err != nil {
+		log.Errorf("AuthService.createSession - randomToken: %v", err)
+		return types.AuthTokens{}, serviceerrs.ErrCannotCreateSession
+	}
+
+	secret, err := randomToken(32)
+	if err != nil {
+		log.Errorf("AuthService.createSession - randomToken: %v", err)
+		return types.AuthTokens{}, serviceerrs.ErrCannotCreateSession
+	}
+
+	err = s.sessionRepo.CreateSession(ctx, entity.Session{
+		ID:               sessionId,
+		Handle:           handle,
+		UserID:           user.ID,
+		RefreshTokenHash: hashRefreshSecret(secret),
+		ExpiresAt:        time.Now().Add(s.refreshTokenTTL),
 	})
 	if err != nil {
-		return 0, serviceerrs.ErrCannotParseToken
+		log.Errorf("AuthService.createSession - s.sessionRepo.CreateSession: %v", err)
+		return types.AuthTokens{}, serviceerrs.ErrCannotCreateSession
+	}
+
+	accessToken, err := s.signAccessToken(user, handle)
+	if err != nil {
+		return types.AuthTokens{}, err
+	}
+
+	return types.AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: sessionId + "." + secret,
+	}, nil
+}
+
+func (s *AuthService) signChallengeToken(user entity.User) (string, error) {
+	tokenString, err := s.keys.Sign(&ChallengeClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{challengeAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.challengeTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+		UserID:   user.ID,
+		Username: user.Username,
+	})
+	if err != nil {
+		log.Errorf("AuthService.signChallengeToken: cannot sign token: %v", err)
+		return "", serviceerrs.ErrCannotSignToken
+	}
+
+	return tokenString, nil
+}
+
+// lookupSession разбирает refresh-токен вида "<id сессии>.<секрет>" и
+// загружает сессию. Секрет не проверяется.
+func (s *AuthService) lookupSession(ctx context.Context, refreshToken string) (entity.Session, string, error) {
+	sessionId, secret, ok := strings.Cut(refreshToken, ".")
+	if !ok || sessionId == "" || secret == "" {
+		return entity.Session{}, "", serviceerrs.ErrInvalidRefreshToken
+	}
+
+	session, err := s.sessionRepo.GetSession(ctx, sessionId)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return entity.Session{}, "", serviceerrs.ErrInvalidRefreshToken
+		}
+		log.Errorf("AuthService.lookupSession - s.sessionRepo.GetSession: %v", err)
+		return entity.Session{}, "", serviceerrs.ErrCannotRefreshToken
 	}
 
-	if !token.Valid {
-		return 0, serviceerrs.ErrCannotParseToken
+	return session, secret, nil
+}
+
+func (s *AuthService) revokeReusedSession(ctx context.Context, session entity.Session) {
+	log.Warnf("AuthService: refresh token reuse detected, revoking session %s of user %d", session.ID, session.UserID)
+	if err := s.sessionRepo.RevokeSession(ctx, session.ID); err != nil {
+		log.Errorf("AuthService.revokeReusedSession - s.sessionRepo.RevokeSession: %v", err)
 	}
+}
 
-	return claims.UserID, nil
+func randomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashRefreshSecret хэширует секрет refresh-токена. Секрет случайный и длинный,
+// поэтому медленный парольный хэш не нужен.
+func hashRefreshSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// checkPasswordPolicy возвращает *serviceerrs.PasswordPolicyError, если пароль
+// не соответствует политике.
+func checkPasswordPolicy(policy passwordpolicy.Policy, password string) error {
+	if violations := policy.Validate(password); len(violations) > 0 {
+		return &serviceerrs.PasswordPolicyError{Violations: violations}
+	}
+	return nil
 }
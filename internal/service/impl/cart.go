@@ -0,0 +1,157 @@
+package impl
+
+import (
+	"context"
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/ledger"
+	"github.com/cripplemymind9/go-market/internal/repository"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+type CartService struct {
+	cartRepo repository.Cart
+	userRepo repository.User
+}
+
+func NewCartService(cartRepo repository.Cart, userRepo repository.User) *CartService {
+	return &CartService{
+		cartRepo: cartRepo,
+		userRepo: userRepo,
+	}
+}
+
+// GetCart возвращает корзину по текущим ценам. Позиции, которых на складе
+// меньше, чем в корзине, помечаются предупреждением, но в сумму входят:
+// оформление такой корзины завершится ошибкой, пока их не поправят.
+func (s *CartService) GetCart(ctx context.Context, userId int) (entity.Cart, error) {
+	items, err := s.cartRepo.GetCartItems(ctx, userId)
+	if err != nil {
+		log.Errorf("CartService.GetCart - s.cartRepo.GetCartItems: %v", err)
+		return entity.Cart{}, serviceerrs.ErrCannotGetCart
+	}
+
+	cart := entity.Cart{
+		UserID: userId,
+		Items:  make([]entity.CartItem, 0, len(items)),
+	}
+
+	var total int64
+	for _, item := range items {
+		switch {
+		case item.Available == 0:
+			item.Warning = entity.StockWarningOutOfStock
+		case item.Available < item.Quantity:
+			item.Warning = entity.StockWarningNotEnoughStock
+		}
+		subtotal := ledger.ToMinor(item.Price) * int64(item.Quantity)
+		item.Subtotal = ledger.FromMinor(subtotal)
+		total += subtotal
+		cart.Items = append(cart.Items, item)
+	}
+	cart.Total = ledger.FromMinor(total)
+
+	return cart, nil
+}
+
+func (s *CartService) AddItem(ctx context.Context, input types.CartAddItemInput) error {
+	if input.Quantity <= 0 {
+		return serviceerrs.ErrInvalidQuantity
+	}
+
+	err := s.cartRepo.AddCartItem(ctx, input.UserID, input.ProductID, input.Quantity)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return serviceerrs.ErrProductNotFound
+		}
+		log.Errorf("CartService.AddItem - s.cartRepo.AddCartItem: %v", err)
+		return serviceerrs.ErrCannotUpdateCart
+	}
+
+	return nil
+}
+
+// UpdateItem задает количество товара в корзине. Нулевое количество убирает
+// товар из корзины.
+func (s *CartService) UpdateItem(ctx context.Context, input types.CartUpdateItemInput) error {
+	if input.Quantity < 0 {
+		return serviceerrs.ErrInvalidQuantity
+	}
+
+	if input.Quantity == 0 {
+		return s.RemoveItem(ctx, input.UserID, input.ProductID)
+	}
+
+	err := s.cartRepo.SetCartItemQuantity(ctx, input.UserID, input.ProductID, input.Quantity)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return serviceerrs.ErrCartItemNotFound
+		}
+		log.Errorf("CartService.UpdateItem - s.cartRepo.SetCartItemQuantity: %v", err)
+		return serviceerrs.ErrCannotUpdateCart
+	}
+
+	return nil
+}
+
+func (s *CartService) RemoveItem(ctx context.Context, userId, productId int) error {
+	err := s.cartRepo.RemoveCartItem(ctx, userId, productId)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return serviceerrs.ErrCartItemNotFound
+		}
+		log.Errorf("CartService.RemoveItem - s.cartRepo.RemoveCartItem: %v", err)
+		return serviceerrs.ErrCannotUpdateCart
+	}
+
+	return nil
+}
+
+func (s *CartService) Clear(ctx context.Context, userId int) error {
+	if err := s.cartRepo.ClearCart(ctx, userId); err != nil {
+		log.Errorf("CartService.Clear - s.cartRepo.ClearCart: %v", err)
+		return serviceerrs.ErrCannotUpdateCart
+	}
+
+	return nil
+}
+
+// Checkout оформляет всю корзину одним заказом: либо покупаются все
+// позиции, либо ни одной, и корзина остается как была. Возвращает id заказа.
+func (s *CartService) Checkout(ctx context.Context, input types.CartCheckoutInput) (int, error) {
+	buyer, err := s.userRepo.GetUserProfile(ctx, input.UserID)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return 0, serviceerrs.ErrUserNotFound
+		}
+		log.Errorf("CartService.Checkout - s.userRepo.GetUserProfile: %v", err)
+		return 0, serviceerrs.ErrCannotGetUser
+	}
+
+	if !buyer.EmailVerified {
+		return 0, serviceerrs.ErrEmailNotVerified
+	}
+
+	id, err := s.cartRepo.Checkout(ctx, input.UserID, input.ShipTo)
+	if err != nil {
+		switch {
+		case errors.Is(err, repoerrs.ErrEmptyCart):
+			return 0, serviceerrs.ErrCartEmpty
+		case errors.Is(err, repoerrs.ErrNotFound):
+			return 0, serviceerrs.ErrProductNotFound
+		case errors.Is(err, repoerrs.ErrNotEnoughStock):
+			return 0, serviceerrs.ErrNotEnoughStock
+		case errors.Is(err, repoerrs.ErrNotEnoughBalance):
+			return 0, serviceerrs.ErrNotEnoughBalance
+		}
+		log.Errorf("CartService.Checkout - s.cartRepo.Checkout: %v", err)
+		return 0, serviceerrs.ErrCannotCheckout
+	}
+
+	return id, nil
+}
@@ -0,0 +1,229 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/mocks/repomocks"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+func TestCartService_GetCart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	cartRepo := repomocks.NewMockCart(ctrl)
+	cartRepo.EXPECT().GetCartItems(ctx, 1).Return([]entity.CartItem{
+		{ProductID: 1, Price: 0.1, Quantity: 3, Available: 10},
+		{ProductID: 2, Price: 5, Quantity: 2, Available: 1},
+		{ProductID: 3, Price: 7.5, Quantity: 1, Available: 0},
+	}, nil)
+
+	s := NewCartService(cartRepo, repomocks.NewMockUser(ctrl))
+	got, err := s.GetCart(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetCart() error = %v", err)
+	}
+
+	want := entity.Cart{
+		UserID: 1,
+		Items: []entity.CartItem{
+			{ProductID: 1, Price: 0.1, Quantity: 3, Available: 10, Subtotal: 0.3},
+			{ProductID: 2, Price: 5, Quantity: 2, Available: 1, Subtotal: 10, Warning: entity.StockWarningNotEnoughStock},
+			{ProductID: 3, Price: 7.5, Quantity: 1, Available: 0, Subtotal: 7.5, Warning: entity.StockWarningOutOfStock},
+		},
+		Total: 17.8,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetCart() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCartService_UpdateItem(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		input types.CartUpdateItemInput
+	}
+
+	type MockBehaviour func(m *repomocks.MockCart, args args)
+
+	testCases := []struct {
+		name          string
+		args          args
+		mockBehaviour MockBehaviour
+		wantErr       error
+	}{
+		{
+			name: "OK",
+			args: args{
+				ctx:   context.Background(),
+				input: types.CartUpdateItemInput{UserID: 1, ProductID: 2, Quantity: 3},
+			},
+			mockBehaviour: func(m *repomocks.MockCart, args args) {
+				m.EXPECT().SetCartItemQuantity(args.ctx, 1, 2, 3).Return(nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Zero quantity removes item",
+			args: args{
+				ctx:   context.Background(),
+				input: types.CartUpdateItemInput{UserID: 1, ProductID: 2, Quantity: 0},
+			},
+			mockBehaviour: func(m *repomocks.MockCart, args args) {
+				m.EXPECT().RemoveCartItem(args.ctx, 1, 2).Return(nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Negative quantity",
+			args: args{
+				ctx:   context.Background(),
+				input: types.CartUpdateItemInput{UserID: 1, ProductID: 2, Quantity: -1},
+			},
+			mockBehaviour: func(m *repomocks.MockCart, args args) {},
+			wantErr:       serviceerrs.ErrInvalidQuantity,
+		},
+		{
+			name: "Not in cart",
+			args: args{
+				ctx:   context.Background(),
+				input: types.CartUpdateItemInput{UserID: 1, ProductID: 2, Quantity: 3},
+			},
+			mockBehaviour: func(m *repomocks.MockCart, args args) {
+				m.EXPECT().SetCartItemQuantity(args.ctx, 1, 2, 3).Return(repoerrs.ErrNotFound)
+			},
+			wantErr: serviceerrs.ErrCartItemNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			cartRepo := repomocks.NewMockCart(ctrl)
+			tc.mockBehaviour(cartRepo, tc.args)
+
+			s := NewCartService(cartRepo, repomocks.NewMockUser(ctrl))
+			err := s.UpdateItem(tc.args.ctx, tc.args.input)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("UpdateItem() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCartService_Checkout(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		input types.CartCheckoutInput
+	}
+
+	type MockBehaviour func(m *repomocks.MockCart, um *repomocks.MockUser, args args)
+
+	testCases := []struct {
+		name          string
+		args          args
+		mockBehaviour MockBehaviour
+		want          int
+		wantErr       error
+	}{
+		{
+			name: "OK",
+			args: args{ctx: context.Background(), input: types.CartCheckoutInput{UserID: 1}},
+			mockBehaviour: func(m *repomocks.MockCart, um *repomocks.MockUser, args args) {
+				um.EXPECT().GetUserProfile(args.ctx, 1).Return(entity.User{ID: 1, EmailVerified: true}, nil)
+				m.EXPECT().Checkout(args.ctx, 1, nil).Return(10, nil)
+			},
+			want:    10,
+			wantErr: nil,
+		},
+		{
+			name: "OK with destination",
+			args: args{ctx: context.Background(), input: types.CartCheckoutInput{UserID: 1, ShipTo: &entity.GeoPoint{Latitude: 55.75, Longitude: 37.62}}},
+			mockBehaviour: func(m *repomocks.MockCart, um *repomocks.MockUser, args args) {
+				um.EXPECT().GetUserProfile(args.ctx, 1).Return(entity.User{ID: 1, EmailVerified: true}, nil)
+				m.EXPECT().Checkout(args.ctx, 1, &entity.GeoPoint{Latitude: 55.75, Longitude: 37.62}).Return(10, nil)
+			},
+			want:    10,
+			wantErr: nil,
+		},
+		{
+			name: "Email not verified",
+			args: args{ctx: context.Background(), input: types.CartCheckoutInput{UserID: 1}},
+			mockBehaviour: func(m *repomocks.MockCart, um *repomocks.MockUser, args args) {
+				um.EXPECT().GetUserProfile(args.ctx, 1).Return(entity.User{ID: 1}, nil)
+			},
+			wantErr: serviceerrs.ErrEmailNotVerified,
+		},
+		{
+			name: "Empty cart",
+			args: args{ctx: context.Background(), input: types.CartCheckoutInput{UserID: 1}},
+			mockBehaviour: func(m *repomocks.MockCart, um *repomocks.MockUser, args args) {
+				um.EXPECT().GetUserProfile(args.ctx, 1).Return(entity.User{ID: 1, EmailVerified: true}, nil)
+				m.EXPECT().Checkout(args.ctx, 1, nil).Return(0, repoerrs.ErrEmptyCart)
+			},
+			wantErr: serviceerrs.ErrCartEmpty,
+		},
+		{
+			name: "Not enough stock",
+			args: args{ctx: context.Background(), input: types.CartCheckoutInput{UserID: 1}},
+			mockBehaviour: func(m *repomocks.MockCart, um *repomocks.MockUser, args args) {
+				um.EXPECT().GetUserProfile(args.ctx, 1).Return(entity.User{ID: 1, EmailVerified: true}, nil)
+				m.EXPECT().Checkout(args.ctx, 1, nil).Return(0, repoerrs.ErrNotEnoughStock)
+			},
+			wantErr: serviceerrs.ErrNotEnoughStock,
+		},
+		{
+			name: "Not enough balance",
+			args: args{ctx: context.Background(), input: types.CartCheckoutInput{UserID: 1}},
+			mockBehaviour: func(m *repomocks.MockCart, um *repomocks.MockUser, args args) {
+				um.EXPECT().GetUserProfile(args.ctx, 1).Return(entity.User{ID: 1, EmailVerified: true}, nil)
+				m.EXPECT().Checkout(args.ctx, 1, nil).Return(0, repoerrs.ErrNotEnoughBalance)
+			},
+			wantErr: serviceerrs.ErrNotEnoughBalance,
+		},
+		{
+			name: "Cannot checkout",
+			args: args{ctx: context.Background(), input: types.CartCheckoutInput{UserID: 1}},
+			mockBehaviour: func(m *repomocks.MockCart, um *repomocks.MockUser, args args) {
+				um.EXPECT().GetUserProfile(args.ctx, 1).Return(entity.User{ID: 1, EmailVerified: true}, nil)
+				m.EXPECT().Checkout(args.ctx, 1, nil).Return(0, errors.New("unexpected error"))
+			},
+			wantErr: serviceerrs.ErrCannotCheckout,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			cartRepo := repomocks.NewMockCart(ctrl)
+			userRepo := repomocks.NewMockUser(ctrl)
+			tc.mockBehaviour(cartRepo, userRepo, tc.args)
+
+			s := NewCartService(cartRepo, userRepo)
+			got, err := s.Checkout(tc.args.ctx, tc.args.input)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Checkout() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+
+			if got != tc.want {
+				t.Errorf("Checkout() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package impl
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+const maxIdempotencyKeyLength = 255
+
+type IdempotencyService struct {
+	idempotencyRepo repository.Idempotency
+	ttl             time.Duration
+	now             func() time.Time
+}
+
+func NewIdempotencyService(idempotencyRepo repository.Idempotency, ttl time.Duration) *IdempotencyService {
+	return &IdempotencyService{
+		idempotencyRepo: idempotencyRepo,
+		ttl:             ttl,
+		now:             time.Now,
+	}
+}
+
+// Begin занимает ключ под запрос. Если ключ уже использован с тем же телом,
+// возвращает запись с сохраненным ответом, а пока тот запрос выполняется -
+// serviceerrs.ErrIdempotencyKeyInProgress. Повтор ключа с другим телом
+// отвергается с serviceerrs.ErrIdempotencyKeyReused.
+func (s *IdempotencyService) Begin(ctx context.Context, input types.IdempotencyBeginInput) (entity.IdempotencyRecord, error) {
+	if !validIdempotencyKey(input.Key) {
+		return entity.IdempotencyRecord{}, serviceerrs.ErrInvalidIdempotencyKey
+	}
+
+	now := s.now()
+	record := entity.IdempotencyRecord{
+		UserID:      input.UserID,
+		Scope:       input.Scope,
+		Key:         input.Key,
+		Fingerprint: requestFingerprint(input.Body),
+		CreatedAt:   now,
+	}
+
+	err := s.idempotencyRepo.CreateIdempotencyRecord(ctx, record, now.Add(-s.ttl))
+	if err == nil {
+		return record, nil
+	}
+	if !errors.Is(err, repoerrs.ErrAlreadyExists) {
+		log.Errorf("IdempotencyService.Begin - s.idempotencyRepo.CreateIdempotencyRecord: %v", err)
+		return entity.IdempotencyRecord{}, serviceerrs.ErrCannotCheckIdempotencyKey
+	}
+
+	stored, err := s.idempotencyRepo.GetIdempotencyRecord(ctx, input.UserID, input.Scope, input.Key)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			// Ключ освободили между двумя запросами.
+			return entity.IdempotencyRecord{}, serviceerrs.ErrIdempotencyKeyInProgress
+		}
+		log.Errorf("IdempotencyService.Begin - s.idempotencyRepo.GetIdempotencyRecord: %v", err)
+		return entity.IdempotencyRecord{}, serviceerrs.ErrCannotCheckIdempotencyKey
+	}
+
+	if stored.Fingerprint != record.Fingerprint {
+		return entity.IdempotencyRecord{}, serviceerrs.ErrIdempotencyKeyReused
+	}
+	if stored.StatusCode == 0 {
+		return entity.IdempotencyRecord{}, serviceerrs.ErrIdempotencyKeyInProgress
+	}
+
+	return stored, nil
+}
+
+// Complete сохраняет ответ на запрос, занявший ключ в Begin.
+func (s *IdempotencyService) Complete(ctx context.Context, record entity.IdempotencyRecord) error {
+	if err := s.idempotencyRepo.CompleteIdempotencyRecord(ctx, record); err != nil {
+		log.Errorf("IdempotencyService.Complete - s.idempotencyRepo.CompleteIdempotencyRecord: %v", err)
+		return serviceerrs.ErrCannotStoreIdempotencyKey
+	}
+
+	return nil
+}
+
+// Release освобождает ключ, чтобы запрос можно было повторить, например после
+// внутренней ошибки.
+func (s *IdempotencyService) Release(ctx context.Context, record entity.IdempotencyRecord) error {
+	if err := s.idempotencyRepo.DeleteIdempotencyRecord(ctx, record.UserID, record.Scope, record.Key); err != nil {
+		log.Errorf("IdempotencyService.Release - s.idempotencyRepo.DeleteIdempotencyRecord: %v", err)
+		return serviceerrs.ErrCannotStoreIdempotencyKey
+	}
+
+	return nil
+}
+
+// validIdempotencyKey допускает непустые ключи из печатных ASCII-символов,
+// например UUID.
+func validIdempotencyKey(key string) bool {
+	if key == "" || len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// requestFingerprint возвращает отпечаток тела запроса. JSON приводится к
+// каноническому виду, поэтому порядок полей и пробелы на отпечаток не влияют.
+func requestFingerprint(body []byte) string {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err == nil && !decoder.More() {
+		if canonical, err := json.Marshal(value); err == nil {
+			body = canonical
+		}
+	}
+
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
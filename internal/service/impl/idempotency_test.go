@@ -0,0 +1,150 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/mocks/repomocks"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+func TestRequestFingerprint(t *testing.T) {
+	a := requestFingerprint([]byte(`{"product_id": 1, "quantity": 2}`))
+	b := requestFingerprint([]byte(`{"quantity":2,"product_id":1}`))
+	if a != b {
+		t.Errorf("requestFingerprint() differs for the same JSON: %s != %s", a, b)
+	}
+
+	if c := requestFingerprint([]byte(`{"product_id":1,"quantity":3}`)); c == a {
+		t.Errorf("requestFingerprint() is equal for different bodies")
+	}
+}
+
+func TestIdempotencyService_Begin(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		input types.IdempotencyBeginInput
+	}
+
+	type MockBehaviour func(m *repomocks.MockIdempotency, args args)
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	body := []byte(`{"product_id":1,"quantity":2}`)
+	fingerprint := requestFingerprint(body)
+	scope := "POST /api/v1/purchase/make-purchase"
+
+	testCases := []struct {
+		name          string
+		args          args
+		mockBehaviour MockBehaviour
+		want          entity.IdempotencyRecord
+		wantErr       error
+	}{
+		{
+			name: "New key",
+			args: args{
+				ctx:   context.Background(),
+				input: types.IdempotencyBeginInput{UserID: 1, Scope: scope, Key: "key-1", Body: body},
+			},
+			mockBehaviour: func(m *repomocks.MockIdempotency, args args) {
+				m.EXPECT().CreateIdempotencyRecord(args.ctx, entity.IdempotencyRecord{
+					UserID:      1,
+					Scope:       scope,
+					Key:         "key-1",
+					Fingerprint: fingerprint,
+					CreatedAt:   now,
+				}, now.Add(-24*time.Hour)).Return(nil)
+			},
+			want: entity.IdempotencyRecord{UserID: 1, Scope: scope, Key: "key-1", Fingerprint: fingerprint, CreatedAt: now},
+		},
+		{
+			name: "Completed request is replayed",
+			args: args{
+				ctx:   context.Background(),
+				input: types.IdempotencyBeginInput{UserID: 1, Scope: scope, Key: "key-1", Body: []byte(`{"quantity":2,"product_id":1}`)},
+			},
+			mockBehaviour: func(m *repomocks.MockIdempotency, args args) {
+				m.EXPECT().CreateIdempotencyRecord(args.ctx, gomock.Any(), gomock.Any()).Return(repoerrs.ErrAlreadyExists)
+				m.EXPECT().GetIdempotencyRecord(args.ctx, 1, scope, "key-1").Return(entity.IdempotencyRecord{
+					Fingerprint:  fingerprint,
+					StatusCode:   201,
+					ResponseBody: []byte(`{"id":7}`),
+				}, nil)
+			},
+			want: entity.IdempotencyRecord{Fingerprint: fingerprint, StatusCode: 201, ResponseBody: []byte(`{"id":7}`)},
+		},
+		{
+			name: "Key reused with another body",
+			args: args{
+				ctx:   context.Background(),
+				input: types.IdempotencyBeginInput{UserID: 1, Scope: scope, Key: "key-1", Body: []byte(`{"product_id":1,"quantity":5}`)},
+			},
+			mockBehaviour: func(m *repomocks.MockIdempotency, args args) {
+				m.EXPECT().CreateIdempotencyRecord(args.ctx, gomock.Any(), gomock.Any()).Return(repoerrs.ErrAlreadyExists)
+				m.EXPECT().GetIdempotencyRecord(args.ctx, 1, scope, "key-1").Return(entity.IdempotencyRecord{Fingerprint: fingerprint, StatusCode: 201}, nil)
+			},
+			wantErr: serviceerrs.ErrIdempotencyKeyReused,
+		},
+		{
+			name: "Request in progress",
+			args: args{
+				ctx:   context.Background(),
+				input: types.IdempotencyBeginInput{UserID: 1, Scope: scope, Key: "key-1", Body: body},
+			},
+			mockBehaviour: func(m *repomocks.MockIdempotency, args args) {
+				m.EXPECT().CreateIdempotencyRecord(args.ctx, gomock.Any(), gomock.Any()).Return(repoerrs.ErrAlreadyExists)
+				m.EXPECT().GetIdempotencyRecord(args.ctx, 1, scope, "key-1").Return(entity.IdempotencyRecord{Fingerprint: fingerprint}, nil)
+			},
+			wantErr: serviceerrs.ErrIdempotencyKeyInProgress,
+		},
+		{
+			name: "Invalid key",
+			args: args{
+				ctx:   context.Background(),
+				input: types.IdempotencyBeginInput{UserID: 1, Scope: scope, Key: "key with spaces", Body: body},
+			},
+			mockBehaviour: func(m *repomocks.MockIdempotency, args args) {},
+			wantErr:       serviceerrs.ErrInvalidIdempotencyKey,
+		},
+		{
+			name: "Repo error",
+			args: args{
+				ctx:   context.Background(),
+				input: types.IdempotencyBeginInput{UserID: 1, Scope: scope, Key: "key-1", Body: body},
+			},
+			mockBehaviour: func(m *repomocks.MockIdempotency, args args) {
+				m.EXPECT().CreateIdempotencyRecord(args.ctx, gomock.Any(), gomock.Any()).Return(errors.New("some error"))
+			},
+			wantErr: serviceerrs.ErrCannotCheckIdempotencyKey,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			idempotencyRepo := repomocks.NewMockIdempotency(ctrl)
+			tc.mockBehaviour(idempotencyRepo, tc.args)
+
+			s := NewIdempotencyService(idempotencyRepo, 24*time.Hour)
+			s.now = func() time.Time { return now }
+
+			got, err := s.Begin(tc.args.ctx, tc.args.input)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Begin() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Begin() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
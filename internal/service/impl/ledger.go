@@ -0,0 +1,41 @@
+package impl
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cripplemymind9/go-market/internal/ledger"
+	"github.com/cripplemymind9/go-market/internal/repository"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+)
+
+type LedgerService struct {
+	ledgerRepo repository.Ledger
+}
+
+func NewLedgerService(ledgerRepo repository.Ledger) *LedgerService {
+	return &LedgerService{ledgerRepo: ledgerRepo}
+}
+
+func (s *LedgerService) Reconcile(ctx context.Context) (ledger.Report, error) {
+	accounts, err := s.ledgerRepo.GetAccountBalances(ctx)
+	if err != nil {
+		log.Errorf("LedgerService.Reconcile - s.ledgerRepo.GetAccountBalances: %v", err)
+		return ledger.Report{}, serviceerrs.ErrCannotReconcileLedger
+	}
+
+	wallets, err := s.ledgerRepo.GetWalletBalances(ctx)
+	if err != nil {
+		log.Errorf("LedgerService.Reconcile - s.ledgerRepo.GetWalletBalances: %v", err)
+		return ledger.Report{}, serviceerrs.ErrCannotReconcileLedger
+	}
+
+	report := ledger.Reconcile(accounts, wallets)
+	if !report.Balanced {
+		log.Warnf("LedgerService.Reconcile - ledger drift detected: trial balance %d, %d wallet(s) drifted",
+			report.TrialBalance, len(report.Drifts))
+	}
+
+	return report, nil
+}
@@ -0,0 +1,85 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/cripplemymind9/go-market/internal/ledger"
+	"github.com/cripplemymind9/go-market/internal/mocks/repomocks"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+)
+
+func TestLedgerService_Reconcile(t *testing.T) {
+	type MockBehaviour func(m *repomocks.MockLedger)
+
+	testCases := []struct {
+		name          string
+		mockBehaviour MockBehaviour
+		wantBalanced  bool
+		wantDrifts    int
+		wantErr       error
+	}{
+		{
+			name: "Balanced",
+			mockBehaviour: func(m *repomocks.MockLedger) {
+				m.EXPECT().GetAccountBalances(gomock.Any()).Return([]ledger.AccountBalance{
+					{Account: ledger.External(), Balance: -5000},
+					{Account: ledger.UserWallet(1), Balance: 5000},
+				}, nil)
+				m.EXPECT().GetWalletBalances(gomock.Any()).Return(map[int]int64{1: 5000}, nil)
+			},
+			wantBalanced: true,
+			wantDrifts:   0,
+			wantErr:      nil,
+		},
+		{
+			name: "Drift",
+			mockBehaviour: func(m *repomocks.MockLedger) {
+				m.EXPECT().GetAccountBalances(gomock.Any()).Return([]ledger.AccountBalance{
+					{Account: ledger.External(), Balance: -5000},
+					{Account: ledger.UserWallet(1), Balance: 5000},
+				}, nil)
+				m.EXPECT().GetWalletBalances(gomock.Any()).Return(map[int]int64{1: 4000}, nil)
+			},
+			wantBalanced: false,
+			wantDrifts:   1,
+			wantErr:      nil,
+		},
+		{
+			name: "Repository error",
+			mockBehaviour: func(m *repomocks.MockLedger) {
+				m.EXPECT().GetAccountBalances(gomock.Any()).Return(nil, errors.New("unexpected error"))
+			},
+			wantErr: serviceerrs.ErrCannotReconcileLedger,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ledgerRepo := repomocks.NewMockLedger(ctrl)
+			tc.mockBehaviour(ledgerRepo)
+
+			s := NewLedgerService(ledgerRepo)
+			got, err := s.Reconcile(context.Background())
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Reconcile() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+
+			if got.Balanced != tc.wantBalanced {
+				t.Errorf("Reconcile() balanced = %v, want %v", got.Balanced, tc.wantBalanced)
+			}
+
+			if len(got.Drifts) != tc.wantDrifts {
+				t.Errorf("Reconcile() drifts = %d, want %d", len(got.Drifts), tc.wantDrifts)
+			}
+		})
+	}
+}
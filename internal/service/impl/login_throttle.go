@@ -0,0 +1,181 @@
+package impl
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+// LoginThrottlePolicy задает, как быстро растет задержка между попытками входа.
+// Первые FreeAttempts неудач не замедляют вход, каждая следующая удваивает
+// задержку начиная с BaseDelay (но не больше MaxDelay). После
+// LockoutThreshold неудач ключ блокируется на LockoutDuration. Счетчик
+// обнуляется, если неудач не было дольше ResetAfter.
+type LoginThrottlePolicy struct {
+	FreeAttempts     int
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+	ResetAfter       time.Duration
+}
+
+var (
+	DefaultUsernameThrottlePolicy = LoginThrottlePolicy{
+		FreeAttempts:     3,
+		BaseDelay:        time.Second,
+		MaxDelay:         5 * time.Minute,
+		LockoutThreshold: 10,
+		LockoutDuration:  15 * time.Minute,
+		ResetAfter:       time.Hour,
+	}
+	DefaultIPThrottlePolicy = LoginThrottlePolicy{
+		FreeAttempts:     20,
+		BaseDelay:        time.Second,
+		MaxDelay:         5 * time.Minute,
+		LockoutThreshold: 100,
+		LockoutDuration:  time.Hour,
+		ResetAfter:       time.Hour,
+	}
+)
+
+// delay возвращает, сколько ждать после failures неудач подряд.
+func (p LoginThrottlePolicy) delay(failures int) time.Duration {
+	if failures >= p.LockoutThreshold {
+		return p.LockoutDuration
+	}
+
+	if failures <= p.FreeAttempts {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := p.FreeAttempts + 1; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+type LoginThrottleService struct {
+	loginAttemptRepo repository.LoginAttempt
+	usernamePolicy   LoginThrottlePolicy
+	ipPolicy         LoginThrottlePolicy
+	now              func() time.Time
+}
+
+func NewLoginThrottleService(loginAttemptRepo repository.LoginAttempt, usernamePolicy, ipPolicy LoginThrottlePolicy) *LoginThrottleService {
+	return &LoginThrottleService{
+		loginAttemptRepo: loginAttemptRepo,
+		usernamePolicy:   usernamePolicy,
+		ipPolicy:         ipPolicy,
+		now:              time.Now,
+	}
+}
+
+type throttleKey struct {
+	key    string
+	policy LoginThrottlePolicy
+}
+
+// keys возвращает ключи счетчиков: первым - по имени пользователя. Ключи
+// проверок с непустым Scope получают его префиксом, например "2fa:user:bob".
+func (s *LoginThrottleService) keys(input types.LoginThrottleInput) []throttleKey {
+	prefix := ""
+	if input.Scope != "" {
+		prefix = input.Scope + ":"
+	}
+
+	keys := []throttleKey{{key: prefix + "user:" + strings.ToLower(input.Username), policy: s.usernamePolicy}}
+	if input.IP != "" {
+		keys = append(keys, throttleKey{key: prefix + "ip:" + input.IP, policy: s.ipPolicy})
+	}
+	return keys
+}
+
+// Reserve засчитывает попытку входа неудачной еще до проверки учетных данных
+// и возвращает serviceerrs.ErrTooManyLoginAttempts и время до следующей
+// разрешенной попытки, если имя пользователя или IP-адрес заблокированы.
+// Решение принимается по счетчику до этой попытки, а сам счетчик
+// увеличивается атомарно, поэтому параллельные запросы не проходят проверку
+// все разом. Отклоненная попытка тоже засчитывается, и ждать нужно от нее.
+// Если вход удался, попытку снимает RecordSuccess.
+func (s *LoginThrottleService) Reserve(ctx context.Context, input types.LoginThrottleInput) (time.Duration, error) {
+	now := s.now()
+
+	var retryAfter time.Duration
+	for _, k := range s.keys(input) {
+		resetBefore := now.Add(-k.policy.ResetAfter)
+
+		previous, err := s.loginAttemptRepo.ReserveLoginAttempt(ctx, k.key, now, resetBefore)
+		if err != nil {
+			log.Errorf("LoginThrottleService.Reserve - s.loginAttemptRepo.ReserveLoginAttempt: %v", err)
+			return 0, serviceerrs.ErrCannotCheckLoginAttempts
+		}
+
+		failures := previous.Failures + 1
+		if previous.LastFailureAt.Before(resetBefore) {
+			failures = 1
+		}
+		if failures == k.policy.LockoutThreshold {
+			log.Warnf("LoginThrottleService: %s locked out for %s after %d failed logins", k.key, k.policy.LockoutDuration, failures)
+		}
+
+		if s.wait(previous, k.policy, now) == 0 {
+			continue
+		}
+		if wait := k.policy.delay(failures); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+
+	if retryAfter > 0 {
+		return retryAfter, serviceerrs.ErrTooManyLoginAttempts
+	}
+
+	return 0, nil
+}
+
+// RecordSuccess сбрасывает счетчик имени пользователя. Со счетчика IP-адреса
+// снимается только попытка, засчитанная в Reserve: иначе перебор чужих
+// паролей можно было бы перемежать входами в собственный аккаунт.
+func (s *LoginThrottleService) RecordSuccess(ctx context.Context, input types.LoginThrottleInput) error {
+	keys := s.keys(input)
+
+	err := s.loginAttemptRepo.ResetLoginAttempts(ctx, keys[0].key)
+	if err != nil {
+		log.Errorf("LoginThrottleService.RecordSuccess - s.loginAttemptRepo.ResetLoginAttempts: %v", err)
+		return serviceerrs.ErrCannotRecordLoginAttempt
+	}
+
+	for _, k := range keys[1:] {
+		if err = s.loginAttemptRepo.ReleaseLoginAttempt(ctx, k.key); err != nil {
+			log.Errorf("LoginThrottleService.RecordSuccess - s.loginAttemptRepo.ReleaseLoginAttempt: %v", err)
+			return serviceerrs.ErrCannotRecordLoginAttempt
+		}
+	}
+
+	return nil
+}
+
+func (s *LoginThrottleService) wait(attempt entity.LoginAttempt, policy LoginThrottlePolicy, now time.Time) time.Duration {
+	if attempt.LastFailureAt.Before(now.Add(-policy.ResetAfter)) {
+		return 0
+	}
+
+	wait := attempt.LastFailureAt.Add(policy.delay(attempt.Failures)).Sub(now)
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
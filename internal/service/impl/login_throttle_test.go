@@ -0,0 +1,176 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/mocks/repomocks"
+	"github.com/cripplemymind9/go-market/internal/repository/memory"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+var testThrottlePolicy = LoginThrottlePolicy{
+	FreeAttempts:     2,
+	BaseDelay:        time.Second,
+	MaxDelay:         4 * time.Second,
+	LockoutThreshold: 6,
+	LockoutDuration:  time.Minute,
+	ResetAfter:       time.Hour,
+}
+
+func TestLoginThrottlePolicy_Delay(t *testing.T) {
+	testCases := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 0, want: 0},
+		{failures: 2, want: 0},
+		{failures: 3, want: time.Second},
+		{failures: 4, want: 2 * time.Second},
+		{failures: 5, want: 4 * time.Second},
+		{failures: 6, want: time.Minute},
+		{failures: 50, want: time.Minute},
+	}
+
+	for _, tc := range testCases {
+		if got := testThrottlePolicy.delay(tc.failures); got != tc.want {
+			t.Errorf("delay(%d) = %v, want %v", tc.failures, got, tc.want)
+		}
+	}
+}
+
+func TestLoginThrottleService(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	ipPolicy := testThrottlePolicy
+	ipPolicy.FreeAttempts = 4
+	ipPolicy.LockoutThreshold = 10
+
+	s := NewLoginThrottleService(memory.NewLoginAttemptRepo(), testThrottlePolicy, ipPolicy)
+	s.now = func() time.Time { return now }
+
+	alice := types.LoginThrottleInput{Username: "Alice", IP: "10.0.0.1"}
+
+	// Пока до попытки было не больше двух неудач, задержки нет.
+	for i := 0; i < 3; i++ {
+		if _, err := s.Reserve(ctx, alice); err != nil {
+			t.Fatalf("Reserve() after %d failures: error = %v", i, err)
+		}
+	}
+
+	// Четвертая попытка отклоняется, но засчитывается, и ждать нужно уже
+	// после четырех неудач. Имя сравнивается без учета регистра.
+	retryAfter, err := s.Reserve(ctx, types.LoginThrottleInput{Username: "alice", IP: "10.0.0.1"})
+	if !errors.Is(err, serviceerrs.ErrTooManyLoginAttempts) || retryAfter != 2*time.Second {
+		t.Errorf("Reserve() = %v, %v, want %v, %v", retryAfter, err, 2*time.Second, serviceerrs.ErrTooManyLoginAttempts)
+	}
+
+	// Тот же IP с другим именем ограничен только счетчиком IP (4 неудачи из 4 бесплатных).
+	bob := types.LoginThrottleInput{Username: "bob", IP: "10.0.0.1"}
+	if _, err = s.Reserve(ctx, bob); err != nil {
+		t.Errorf("Reserve() for another user: error = %v", err)
+	}
+
+	now = now.Add(2 * time.Second)
+	if _, err = s.Reserve(ctx, alice); err != nil {
+		t.Errorf("Reserve() after backoff elapsed: error = %v", err)
+	}
+
+	// Успешный вход сбрасывает счетчик имени, а со счетчика IP снимает
+	// только свою попытку: на нем остаются 5 неудач.
+	if err = s.RecordSuccess(ctx, alice); err != nil {
+		t.Fatalf("RecordSuccess() error = %v", err)
+	}
+
+	if _, err = s.Reserve(ctx, types.LoginThrottleInput{Username: "alice", IP: "10.0.0.2"}); err != nil {
+		t.Errorf("Reserve() from another IP after success: error = %v", err)
+	}
+
+	retryAfter, err = s.Reserve(ctx, types.LoginThrottleInput{Username: "carol", IP: "10.0.0.1"})
+	if !errors.Is(err, serviceerrs.ErrTooManyLoginAttempts) || retryAfter != 2*time.Second {
+		t.Errorf("Reserve() after IP exceeded free attempts = %v, %v, want %v, %v", retryAfter, err, 2*time.Second, serviceerrs.ErrTooManyLoginAttempts)
+	}
+
+	// Счетчики с другим Scope не пересекаются со счетчиками входа.
+	if _, err = s.Reserve(ctx, types.LoginThrottleInput{Scope: "2fa", Username: "carol", IP: "10.0.0.1"}); err != nil {
+		t.Errorf("Reserve() in another scope: error = %v", err)
+	}
+}
+
+func TestLoginThrottleService_ParallelAttempts(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	s := NewLoginThrottleService(memory.NewLoginAttemptRepo(), testThrottlePolicy, testThrottlePolicy)
+	s.now = func() time.Time { return now }
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		allowed int
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.Reserve(context.Background(), types.LoginThrottleInput{Username: "alice"}); err == nil {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Параллельные попытки получают разные значения счетчика, поэтому
+	// пропускаются только бесплатные.
+	if want := testThrottlePolicy.FreeAttempts + 1; allowed != want {
+		t.Errorf("allowed %d parallel attempts, want %d", allowed, want)
+	}
+}
+
+func TestLoginThrottleService_Lockout(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	s := NewLoginThrottleService(memory.NewLoginAttemptRepo(), testThrottlePolicy, testThrottlePolicy)
+	s.now = func() time.Time { return now }
+
+	input := types.LoginThrottleInput{Username: "alice"}
+	for i := 0; i < testThrottlePolicy.LockoutThreshold; i++ {
+		if _, err := s.Reserve(ctx, input); err != nil && !errors.Is(err, serviceerrs.ErrTooManyLoginAttempts) {
+			t.Fatalf("Reserve() error = %v", err)
+		}
+	}
+
+	retryAfter, err := s.Reserve(ctx, input)
+	if !errors.Is(err, serviceerrs.ErrTooManyLoginAttempts) || retryAfter != time.Minute {
+		t.Errorf("Reserve() = %v, %v, want %v, %v", retryAfter, err, time.Minute, serviceerrs.ErrTooManyLoginAttempts)
+	}
+
+	// После ResetAfter без неудач счет начинается заново.
+	now = now.Add(testThrottlePolicy.ResetAfter + time.Second)
+	if _, err = s.Reserve(ctx, input); err != nil {
+		t.Errorf("Reserve() after reset: error = %v", err)
+	}
+}
+
+func TestLoginThrottleService_StoreError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := repomocks.NewMockLoginAttempt(ctrl)
+	repo.EXPECT().ReserveLoginAttempt(gomock.Any(), "user:alice", gomock.Any(), gomock.Any()).Return(entity.LoginAttempt{}, errors.New("unexpected error"))
+
+	s := NewLoginThrottleService(repo, testThrottlePolicy, testThrottlePolicy)
+	if _, err := s.Reserve(context.Background(), types.LoginThrottleInput{Username: "alice"}); !errors.Is(err, serviceerrs.ErrCannotCheckLoginAttempts) {
+		t.Errorf("Reserve() error = %v, want %v", err, serviceerrs.ErrCannotCheckLoginAttempts)
+	}
+}
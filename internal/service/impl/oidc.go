@@ -0,0 +1,281 @@
+package impl
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+	"github.com/cripplemymind9/go-market/pkg/oidc"
+)
+
+const (
+	// oidcUsernameAttempts - сколько раз подбирать свободное имя для нового
+	// пользователя, прежде чем сдаться.
+	oidcUsernameAttempts = 5
+	oidcUsernameMaxLen   = 32
+)
+
+// OIDCConfig - настройки входа через внешнего провайдера.
+type OIDCConfig struct {
+	// Provider - имя провайдера, под которым хранятся связанные учетные записи.
+	Provider string
+	// LinkByEmail разрешает связывать учетную запись провайдера с
+	// существующим пользователем по подтвержденной почте.
+	LinkByEmail bool
+	StateTTL    time.Duration
+}
+
+type OIDCService struct {
+	userRepo     repository.User
+	identityRepo repository.ExternalIdentity
+	client       *oidc.Client
+	cfg          OIDCConfig
+	now          func() time.Time
+}
+
+func NewOIDCService(
+	userRepo repository.User,
+	identityRepo repository.ExternalIdentity,
+	client *oidc.Client,
+	cfg OIDCConfig,
+) *OIDCService {
+	return &OIDCService{
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		client:       client,
+		cfg:          cfg,
+		now:          time.Now,
+	}
+}
+
+// Begin начинает вход: запоминает nonce и code_verifier под хэшем state и
+// возвращает ссылку на страницу входа провайдера.
+func (s *OIDCService) Begin(ctx context.Context) (types.OIDCLoginStart, error) {
+	state, err := randomToken(32)
+	if err != nil {
+		log.Errorf("OIDCService.Begin - randomToken: %v", err)
+		return types.OIDCLoginStart{}, serviceerrs.ErrCannotStartOIDCLogin
+	}
+
+	nonce, err := randomToken(32)
+	if err != nil {
+		log.Errorf("OIDCService.Begin - randomToken: %v", err)
+		return types.OIDCLoginStart{}, serviceerrs.ErrCannotStartOIDCLogin
+	}
+
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		log.Errorf("OIDCService.Begin - oidc.NewCodeVerifier: %v", err)
+		return types.OIDCLoginStart{}, serviceerrs.ErrCannotStartOIDCLogin
+	}
+
+	authURL, err := s.client.AuthCodeURL(ctx, state, nonce, oidc.CodeChallengeS256(verifier))
+	if err != nil {
+		log.Errorf("OIDCService.Begin - s.client.AuthCodeURL: %v", err)
+		return types.OIDCLoginStart{}, serviceerrs.ErrCannotStartOIDCLogin
+	}
+
+	err = s.identityRepo.CreateOIDCLoginState(ctx, entity.OIDCLoginState{
+		StateHash:    hashRefreshSecret(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    s.now().Add(s.cfg.StateTTL),
+	})
+	if err != nil {
+		log.Errorf("OIDCService.Begin - s.identityRepo.CreateOIDCLoginState: %v", err)
+		return types.OIDCLoginStart{}, serviceerrs.ErrCannotStartOIDCLogin
+	}
+
+	return types.OIDCLoginStart{
+		AuthURL: authURL,
+		State:   state,
+	}, nil
+}
+
+// Complete завершает вход: обменивает код на ID-токен, проверяет его и
+// возвращает связанного пользователя. При первом входе учетная запись
+// провайдера связывается с пользователем по почте или для нее создается
+// новый пользователь.
+func (s *OIDCService) Complete(ctx context.Context, input types.OIDCCompleteInput) (entity.User, error) {
+	state, err := s.identityRepo.ConsumeOIDCLoginState(ctx, hashRefreshSecret(input.State))
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return entity.User{}, serviceerrs.ErrInvalidOIDCState
+		}
+		log.Errorf("OIDCService.Complete - s.identityRepo.ConsumeOIDCLoginState: %v", err)
+		return entity.User{}, serviceerrs.ErrCannotCompleteOIDCLogin
+	}
+
+	if !s.now().Before(state.ExpiresAt) {
+		return entity.User{}, serviceerrs.ErrInvalidOIDCState
+	}
+
+	tokens, err := s.client.Exchange(ctx, input.Code, state.CodeVerifier)
+	if err != nil {
+		log.Warnf("OIDCService.Complete - s.client.Exchange: %v", err)
+		return entity.User{}, serviceerrs.ErrOIDCLoginFailed
+	}
+
+	claims, err := s.client.VerifyIDToken(ctx, tokens.IDToken, state.Nonce)
+	if err != nil {
+		log.Warnf("OIDCService.Complete - s.client.VerifyIDToken: %v", err)
+		return entity.User{}, serviceerrs.ErrOIDCLoginFailed
+	}
+
+	identity, err := s.identityRepo.GetExternalIdentity(ctx, s.cfg.Provider, claims.Subject)
+	if err == nil {
+		return s.getUser(ctx, identity.UserID)
+	}
+	if !errors.Is(err, repoerrs.ErrNotFound) {
+		log.Errorf("OIDCService.Complete - s.identityRepo.GetExternalIdentity: %v", err)
+		return entity.User{}, serviceerrs.ErrCannotCompleteOIDCLogin
+	}
+
+	identity = entity.ExternalIdentity{
+		Provider: s.cfg.Provider,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}
+
+	if s.cfg.LinkByEmail && claims.EmailVerified && claims.Email != "" {
+		userId, ok, err := s.linkByEmail(ctx, identity)
+		if err != nil {
+			return entity.User{}, err
+		}
+		if ok {
+			return s.getUser(ctx, userId)
+		}
+	}
+
+	userId, err := s.createUser(ctx, identity, claims)
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	return s.getUser(ctx, userId)
+}
+
+// linkByEmail связывает учетную запись провайдера с единственным
+// пользователем, подтвердившим ту же почту. Если таких нет или их несколько,
+// ok ложно.
+func (s *OIDCService) linkByEmail(ctx context.Context, identity entity.ExternalIdentity) (userId int, ok bool, err error) {
+	users, err := s.userRepo.GetUsersByEmail(ctx, identity.Email)
+	if err != nil {
+		log.Errorf("OIDCService.linkByEmail - s.userRepo.GetUsersByEmail: %v", err)
+		return 0, false, serviceerrs.ErrCannotCompleteOIDCLogin
+	}
+
+	var verified []entity.User
+	for _, user := range users {
+		if user.EmailVerified {
+			verified = append(verified, user)
+		}
+	}
+	if len(verified) != 1 {
+		return 0, false, nil
+	}
+
+	identity.UserID = verified[0].ID
+	if err = s.identityRepo.LinkExternalIdentity(ctx, identity); err != nil {
+		if errors.Is(err, repoerrs.ErrAlreadyExists) {
+			// Параллельный вход уже связал эту учетную запись.
+			return s.linkedUserId(ctx, identity)
+		}
+		log.Errorf("OIDCService.linkByEmail - s.identityRepo.LinkExternalIdentity: %v", err)
+		return 0, false, serviceerrs.ErrCannotCompleteOIDCLogin
+	}
+
+	return identity.UserID, true, nil
+}
+
+// createUser создает пользователя для учетной записи провайдера. Если имя
+// занято, к нему добавляется случайный суффикс.
+func (s *OIDCService) createUser(ctx context.Context, identity entity.ExternalIdentity, claims oidc.Claims) (int, error) {
+	base := oidcUsername(claims)
+
+	for attempt := 0; attempt < oidcUsernameAttempts; attempt++ {
+		username := base
+		if attempt > 0 {
+			suffix := make([]byte, 3)
+			if _, err := rand.Read(suffix); err != nil {
+				log.Errorf("OIDCService.createUser - rand.Read: %v", err)
+				return 0, serviceerrs.ErrCannotCreateUser
+			}
+			username = base + "-" + hex.EncodeToString(suffix)
+		}
+
+		userId, err := s.identityRepo.CreateExternalUser(ctx, entity.User{
+			Username:      username,
+			Email:         claims.Email,
+			EmailVerified: claims.EmailVerified && claims.Email != "",
+			Roles:         []entity.Role{entity.RoleBuyer},
+		}, identity)
+		if err == nil {
+			return userId, nil
+		}
+		if !errors.Is(err, repoerrs.ErrAlreadyExists) {
+			log.Errorf("OIDCService.createUser - s.identityRepo.CreateExternalUser: %v", err)
+			return 0, serviceerrs.ErrCannotCreateUser
+		}
+
+		// Конфликт дает и занятое имя, и параллельный первый вход.
+		if userId, ok, err := s.linkedUserId(ctx, identity); err != nil || ok {
+			return userId, err
+		}
+	}
+
+	log.Errorf("OIDCService.createUser: no free username for %q", base)
+	return 0, serviceerrs.ErrCannotCreateUser
+}
+
+func (s *OIDCService) linkedUserId(ctx context.Context, identity entity.ExternalIdentity) (int, bool, error) {
+	linked, err := s.identityRepo.GetExternalIdentity(ctx, identity.Provider, identity.Subject)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return 0, false, nil
+		}
+		log.Errorf("OIDCService.linkedUserId - s.identityRepo.GetExternalIdentity: %v", err)
+		return 0, false, serviceerrs.ErrCannotCompleteOIDCLogin
+	}
+
+	return linked.UserID, true, nil
+}
+
+func (s *OIDCService) getUser(ctx context.Context, userId int) (entity.User, error) {
+	user, err := s.userRepo.GetUserProfile(ctx, userId)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return entity.User{}, serviceerrs.ErrUserNotFound
+		}
+		log.Errorf("OIDCService.getUser - s.userRepo.GetUserProfile: %v", err)
+		return entity.User{}, serviceerrs.ErrCannotGetUser
+	}
+
+	return user, nil
+}
+
+// oidcUsername выбирает имя нового пользователя: preferred_username, затем
+// начало адреса почты.
+func oidcUsername(claims oidc.Claims) string {
+	username := strings.TrimSpace(claims.PreferredUsername)
+	if username == "" {
+		username, _, _ = strings.Cut(claims.Email, "@")
+	}
+	if username == "" {
+		username = "user"
+	}
+	if runes := []rune(username); len(runes) > oidcUsernameMaxLen {
+		username = string(runes[:oidcUsernameMaxLen])
+	}
+	return username
+}
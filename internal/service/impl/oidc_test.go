@@ -0,0 +1,234 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/mocks/repomocks"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+	"github.com/cripplemymind9/go-market/pkg/oidc"
+	"github.com/cripplemymind9/go-market/pkg/oidc/oidctest"
+)
+
+// oidcTestEnv - сервис входа, подключенный к поддельному провайдеру, и
+// хранилище начатых входов в памяти поверх мока репозитория.
+type oidcTestEnv struct {
+	service      *OIDCService
+	provider     *oidctest.Provider
+	userRepo     *repomocks.MockUser
+	identityRepo *repomocks.MockExternalIdentity
+}
+
+func newOIDCTestEnv(t *testing.T, ctrl *gomock.Controller, linkByEmail bool) *oidcTestEnv {
+	t.Helper()
+
+	provider, err := oidctest.NewProvider("go-market", "client-secret")
+	if err != nil {
+		t.Fatalf("oidctest.NewProvider() error = %v", err)
+	}
+	t.Cleanup(provider.Close)
+
+	client := oidc.NewClient(oidc.Config{
+		Issuer:       provider.Issuer(),
+		ClientID:     provider.ClientID,
+		ClientSecret: provider.ClientSecret,
+		RedirectURL:  "http://localhost:8080/auth/oidc/callback",
+		Scopes:       []string{"email", "profile"},
+	})
+
+	env := &oidcTestEnv{
+		provider:     provider,
+		userRepo:     repomocks.NewMockUser(ctrl),
+		identityRepo: repomocks.NewMockExternalIdentity(ctrl),
+	}
+	env.service = NewOIDCService(env.userRepo, env.identityRepo, client, OIDCConfig{
+		Provider:    "company",
+		LinkByEmail: linkByEmail,
+		StateTTL:    10 * time.Minute,
+	})
+
+	states := make(map[string]entity.OIDCLoginState)
+	env.identityRepo.EXPECT().CreateOIDCLoginState(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, state entity.OIDCLoginState) error {
+			states[state.StateHash] = state
+			return nil
+		}).AnyTimes()
+	env.identityRepo.EXPECT().ConsumeOIDCLoginState(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, hash string) (entity.OIDCLoginState, error) {
+			state, ok := states[hash]
+			if !ok {
+				return entity.OIDCLoginState{}, repoerrs.ErrNotFound
+			}
+			delete(states, hash)
+			return state, nil
+		}).AnyTimes()
+
+	return env
+}
+
+// login начинает вход и проходит страницу провайдера, возвращая данные,
+// с которыми провайдер перенаправил бы браузер обратно.
+func (env *oidcTestEnv) login(t *testing.T, ctx context.Context) types.OIDCCompleteInput {
+	t.Helper()
+
+	start, err := env.service.Begin(ctx)
+	if err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(start.AuthURL)
+	if err != nil {
+		t.Fatalf("GET authorization endpoint error = %v", err)
+	}
+	resp.Body.Close()
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || location.Query().Get("code") == "" {
+		t.Fatalf("provider redirect = %q, error = %v", resp.Header.Get("Location"), err)
+	}
+	if location.Query().Get("state") != start.State {
+		t.Fatalf("provider returned state %q, want %q", location.Query().Get("state"), start.State)
+	}
+
+	return types.OIDCCompleteInput{
+		State: start.State,
+		Code:  location.Query().Get("code"),
+	}
+}
+
+func TestOIDCService_LinkedIdentity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	env := newOIDCTestEnv(t, ctrl, true)
+	env.provider.SetUser(oidctest.User{Subject: "employee-42", Email: "alice@company.example", EmailVerified: true})
+
+	env.identityRepo.EXPECT().GetExternalIdentity(ctx, "company", "employee-42").Return(entity.ExternalIdentity{
+		Provider: "company",
+		Subject:  "employee-42",
+		UserID:   5,
+	}, nil)
+	env.userRepo.EXPECT().GetUserProfile(ctx, 5).Return(entity.User{ID: 5, Username: "alice"}, nil)
+
+	input := env.login(t, ctx)
+	user, err := env.service.Complete(ctx, input)
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if user.ID != 5 {
+		t.Errorf("Complete() user = %+v, want ID 5", user)
+	}
+
+	// Повтор того же возврата от провайдера отвергается.
+	if _, err = env.service.Complete(ctx, input); !errors.Is(err, serviceerrs.ErrInvalidOIDCState) {
+		t.Errorf("Complete() replay error = %v, want %v", err, serviceerrs.ErrInvalidOIDCState)
+	}
+}
+
+func TestOIDCService_LinkByEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	env := newOIDCTestEnv(t, ctrl, true)
+	env.provider.SetUser(oidctest.User{Subject: "employee-42", Email: "alice@company.example", EmailVerified: true})
+
+	env.identityRepo.EXPECT().GetExternalIdentity(ctx, "company", "employee-42").Return(entity.ExternalIdentity{}, repoerrs.ErrNotFound)
+	env.userRepo.EXPECT().GetUsersByEmail(ctx, "alice@company.example").Return([]entity.User{
+		{ID: 3, Email: "alice@company.example"},
+		{ID: 5, Email: "alice@company.example", EmailVerified: true},
+	}, nil)
+	env.identityRepo.EXPECT().LinkExternalIdentity(ctx, entity.ExternalIdentity{
+		Provider: "company",
+		Subject:  "employee-42",
+		UserID:   5,
+		Email:    "alice@company.example",
+	}).Return(nil)
+	env.userRepo.EXPECT().GetUserProfile(ctx, 5).Return(entity.User{ID: 5, Username: "alice"}, nil)
+
+	user, err := env.service.Complete(ctx, env.login(t, ctx))
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if user.ID != 5 {
+		t.Errorf("Complete() user = %+v, want ID 5", user)
+	}
+}
+
+func TestOIDCService_CreateUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	// Почта провайдером не подтверждена, поэтому связывать по ней нельзя.
+	env := newOIDCTestEnv(t, ctrl, true)
+	env.provider.SetUser(oidctest.User{Subject: "employee-7", Email: "bob@company.example", PreferredUsername: "bob"})
+
+	identity := entity.ExternalIdentity{Provider: "company", Subject: "employee-7", Email: "bob@company.example"}
+
+	gomock.InOrder(
+		env.identityRepo.EXPECT().GetExternalIdentity(ctx, "company", "employee-7").Return(entity.ExternalIdentity{}, repoerrs.ErrNotFound),
+		// Имя bob занято локальным пользователем.
+		env.identityRepo.EXPECT().CreateExternalUser(ctx, entity.User{
+			Username: "bob",
+			Email:    "bob@company.example",
+			Roles:    []entity.Role{entity.RoleBuyer},
+		}, identity).Return(0, repoerrs.ErrAlreadyExists),
+		env.identityRepo.EXPECT().GetExternalIdentity(ctx, "company", "employee-7").Return(entity.ExternalIdentity{}, repoerrs.ErrNotFound),
+		env.identityRepo.EXPECT().CreateExternalUser(ctx, gomock.Any(), identity).DoAndReturn(
+			func(_ context.Context, user entity.User, _ entity.ExternalIdentity) (int, error) {
+				if len(user.Username) != len("bob-")+6 || user.Username[:4] != "bob-" {
+					t.Errorf("CreateExternalUser() username = %q, want bob-<suffix>", user.Username)
+				}
+				return 9, nil
+			}),
+		env.userRepo.EXPECT().GetUserProfile(ctx, 9).Return(entity.User{ID: 9}, nil),
+	)
+
+	user, err := env.service.Complete(ctx, env.login(t, ctx))
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if user.ID != 9 {
+		t.Errorf("Complete() user = %+v, want ID 9", user)
+	}
+}
+
+func TestOIDCService_Complete_Errors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	env := newOIDCTestEnv(t, ctrl, false)
+	env.provider.SetUser(oidctest.User{Subject: "employee-42"})
+
+	if _, err := env.service.Complete(ctx, types.OIDCCompleteInput{State: "unknown", Code: "code"}); !errors.Is(err, serviceerrs.ErrInvalidOIDCState) {
+		t.Errorf("Complete() unknown state error = %v, want %v", err, serviceerrs.ErrInvalidOIDCState)
+	}
+
+	input := env.login(t, ctx)
+	input.Code = "forged"
+	if _, err := env.service.Complete(ctx, input); !errors.Is(err, serviceerrs.ErrOIDCLoginFailed) {
+		t.Errorf("Complete() forged code error = %v, want %v", err, serviceerrs.ErrOIDCLoginFailed)
+	}
+
+	// Просроченный вход.
+	input = env.login(t, ctx)
+	env.service.now = func() time.Time { return time.Now().Add(time.Hour) }
+	if _, err := env.service.Complete(ctx, input); !errors.Is(err, serviceerrs.ErrInvalidOIDCState) {
+		t.Errorf("Complete() expired state error = %v, want %v", err, serviceerrs.ErrInvalidOIDCState)
+	}
+}
@@ -0,0 +1,300 @@
+package impl
+
+import (
+	"context"
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+// orderTransitions - разрешенные переходы между статусами заказа. Из
+// cancelled и refunded переходов нет.
+var orderTransitions = map[entity.OrderStatus][]entity.OrderStatus{
+	entity.OrderStatusPending:   {entity.OrderStatusPaid, entity.OrderStatusCancelled},
+	entity.OrderStatusPaid:      {entity.OrderStatusShipped, entity.OrderStatusCancelled, entity.OrderStatusRefunded},
+	entity.OrderStatusShipped:   {entity.OrderStatusDelivered},
+	entity.OrderStatusDelivered: {entity.OrderStatusRefunded},
+}
+
+// orderFulfilment - следующий шаг выполнения заказа. Оплата проводится при
+// оформлении, поэтому вручную в paid заказ не переводится.
+var orderFulfilment = map[entity.OrderStatus]entity.OrderStatus{
+	entity.OrderStatusPaid:    entity.OrderStatusShipped,
+	entity.OrderStatusShipped: entity.OrderStatusDelivered,
+}
+
+func canTransition(from, to entity.OrderStatus) bool {
+	for _, allowed := range orderTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+type OrderService struct {
+	orderRepo    repository.Order
+	purchaseRepo repository.Purchase
+}
+
+func NewOrderService(orderRepo repository.Order, purchaseRepo repository.Purchase) *OrderService {
+	return &OrderService{
+		orderRepo:    orderRepo,
+		purchaseRepo: purchaseRepo,
+	}
+}
+
+// GetOrder возвращает заказ с историей статусов и возвратами. Заказ видят
+// покупатель, продавцы его строк и администратор, остальным он не найден.
+func (s *OrderService) GetOrder(ctx context.Context, input types.OrderGetOrderInput) (types.OrderDetails, error) {
+	order, err := s.getVisibleOrder(ctx, input.OrderID, input.Actor)
+	if err != nil {
+		return types.OrderDetails{}, err
+	}
+
+	history, err := s.orderRepo.GetOrderHistory(ctx, order.ID)
+	if err != nil {
+		log.Errorf("OrderService.GetOrder - s.orderRepo.GetOrderHistory: %v", err)
+		return types.OrderDetails{}, serviceerrs.ErrCannotGetOrder
+	}
+
+	refunds, err := s.purchaseRepo.GetOrderRefunds(ctx, order.ID)
+	if err != nil {
+		log.Errorf("OrderService.GetOrder - s.purchaseRepo.GetOrderRefunds: %v", err)
+		return types.OrderDetails{}, serviceerrs.ErrCannotGetOrder
+	}
+
+	return types.OrderDetails{
+		Order:   order,
+		History: history,
+		Refunds: refunds,
+	}, nil
+}
+
+// Cancel отменяет заказ, пока он не отправлен. Товары возвращаются на склад,
+// а оплаченный заказ - еще и деньгами покупателю. Отменить заказ могут
+// покупатель, администратор и продавец, которому принадлежат все строки.
+func (s *OrderService) Cancel(ctx context.Context, input types.OrderCancelInput) (entity.Order, error) {
+	order, err := s.getVisibleOrder(ctx, input.OrderID, input.Actor)
+	if err != nil {
+		return entity.Order{}, err
+	}
+
+	if order.UserID != input.Actor.UserID && !canFulfil(order, input.Actor) {
+		return entity.Order{}, serviceerrs.ErrOrderForbidden
+	}
+
+	if !canTransition(order.Status, entity.OrderStatusCancelled) {
+		return entity.Order{}, serviceerrs.ErrInvalidOrderTransition
+	}
+
+	change := entity.OrderStatusChange{
+		OrderID: order.ID,
+		From:    order.Status,
+		To:      entity.OrderStatusCancelled,
+		ActorID: input.Actor.UserID,
+		Reason:  input.Reason,
+	}
+
+	err = s.orderRepo.CancelOrder(ctx, change, order.Status != entity.OrderStatusPending)
+	if err != nil {
+		return entity.Order{}, s.changeError("OrderService.Cancel - s.orderRepo.CancelOrder", err)
+	}
+
+	order.Status = entity.OrderStatusCancelled
+	return order, nil
+}
+
+// Advance переводит заказ на следующий шаг выполнения: paid -> shipped ->
+// delivered. Это может делать администратор или продавец, которому
+// принадлежат все строки заказа.
+func (s *OrderService) Advance(ctx context.Context, input types.OrderAdvanceInput) (entity.Order, error) {
+	order, err := s.getVisibleOrder(ctx, input.OrderID, input.Actor)
+	if err != nil {
+		return entity.Order{}, err
+	}
+
+	if !canFulfil(order, input.Actor) {
+		return entity.Order{}, serviceerrs.ErrOrderForbidden
+	}
+
+	next, ok := orderFulfilment[order.Status]
+	if !ok || !canTransition(order.Status, next) {
+		return entity.Order{}, serviceerrs.ErrInvalidOrderTransition
+	}
+
+	change := entity.OrderStatusChange{
+		OrderID: order.ID,
+		From:    order.Status,
+		To:      next,
+		ActorID: input.Actor.UserID,
+		Reason:  input.Reason,
+	}
+
+	if err = s.orderRepo.UpdateOrderStatus(ctx, change); err != nil {
+		return entity.Order{}, s.changeError("OrderService.Advance - s.orderRepo.UpdateOrderStatus", err)
+	}
+
+	order.Status = next
+	return order, nil
+}
+
+// Refund возвращает часть единиц заказа или, если input.Items пуст, все еще
+// не возвращенные. Вернуть можно оплаченный или доставленный заказ; когда в
+// нем ничего не остается, он переходит в refunded. Возврат оформляет
+// администратор или продавец, которому принадлежат все возвращаемые строки.
+func (s *OrderService) Refund(ctx context.Context, input types.OrderRefundInput) (entity.Refund, error) {
+	order, err := s.getVisibleOrder(ctx, input.OrderID, input.Actor)
+	if err != nil {
+		return entity.Refund{}, err
+	}
+
+	refunds, err := s.purchaseRepo.GetOrderRefunds(ctx, order.ID)
+	if err != nil {
+		log.Errorf("OrderService.Refund - s.purchaseRepo.GetOrderRefunds: %v", err)
+		return entity.Refund{}, serviceerrs.ErrCannotRefund
+	}
+
+	items, err := refundItems(order, refunds, input.Items)
+	if err != nil {
+		return entity.Refund{}, err
+	}
+
+	if !input.Actor.HasRole(entity.RoleAdmin) {
+		for _, item := range order.Items {
+			if _, ok := items[item.ProductID]; ok && item.SellerID != input.Actor.UserID {
+				return entity.Refund{}, serviceerrs.ErrOrderForbidden
+			}
+		}
+	}
+
+	if !canTransition(order.Status, entity.OrderStatusRefunded) {
+		return entity.Refund{}, serviceerrs.ErrInvalidOrderTransition
+	}
+
+	refund := entity.Refund{
+		OrderID: order.ID,
+		ActorID: input.Actor.UserID,
+		Reason:  input.Reason,
+	}
+	for _, item := range order.Items {
+		if quantity, ok := items[item.ProductID]; ok {
+			refund.Items = append(refund.Items, entity.RefundItem{ProductID: item.ProductID, Quantity: quantity})
+		}
+	}
+
+	refund, err = s.purchaseRepo.CreateRefund(ctx, refund, order.Status)
+	if err != nil {
+		switch {
+		case errors.Is(err, repoerrs.ErrRefundExceeded):
+			return entity.Refund{}, serviceerrs.ErrRefundExceedsPurchase
+		case errors.Is(err, repoerrs.ErrConflict):
+			return entity.Refund{}, serviceerrs.ErrOrderStatusChanged
+		case errors.Is(err, repoerrs.ErrNotFound):
+			return entity.Refund{}, serviceerrs.ErrOrderNotFound
+		}
+		log.Errorf("OrderService.Refund - s.purchaseRepo.CreateRefund: %v", err)
+		return entity.Refund{}, serviceerrs.ErrCannotRefund
+	}
+
+	return refund, nil
+}
+
+// refundItems сводит запрошенный возврат к количеству по товару и проверяет,
+// что он не превышает купленное за вычетом прошлых возвратов. Пустой запрос
+// означает возврат всего оставшегося.
+func refundItems(order entity.Order, refunds []entity.Refund, requested []types.OrderRefundItemInput) (map[int]int, error) {
+	remaining := make(map[int]int, len(order.Items))
+	for _, item := range order.Items {
+		remaining[item.ProductID] += item.Quantity
+	}
+	for _, refund := range refunds {
+		for _, item := range refund.Items {
+			remaining[item.ProductID] -= item.Quantity
+		}
+	}
+
+	items := make(map[int]int)
+	if len(requested) == 0 {
+		for productId, quantity := range remaining {
+			if quantity > 0 {
+				items[productId] = quantity
+			}
+		}
+		if len(items) == 0 {
+			return nil, serviceerrs.ErrNothingToRefund
+		}
+		return items, nil
+	}
+
+	for _, item := range requested {
+		if item.Quantity <= 0 {
+			return nil, serviceerrs.ErrInvalidQuantity
+		}
+		if _, ok := remaining[item.ProductID]; !ok {
+			return nil, serviceerrs.ErrOrderItemNotFound
+		}
+		items[item.ProductID] += item.Quantity
+	}
+	for productId, quantity := range items {
+		if quantity > remaining[productId] {
+			return nil, serviceerrs.ErrRefundExceedsPurchase
+		}
+	}
+
+	return items, nil
+}
+
+func (s *OrderService) getVisibleOrder(ctx context.Context, orderId int, actor types.AuthIdentity) (entity.Order, error) {
+	order, err := s.orderRepo.GetOrder(ctx, orderId)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return entity.Order{}, serviceerrs.ErrOrderNotFound
+		}
+		log.Errorf("OrderService.getVisibleOrder - s.orderRepo.GetOrder: %v", err)
+		return entity.Order{}, serviceerrs.ErrCannotGetOrder
+	}
+
+	if order.UserID == actor.UserID || actor.HasRole(entity.RoleAdmin) {
+		return order, nil
+	}
+	for _, item := range order.Items {
+		if item.SellerID == actor.UserID {
+			return order, nil
+		}
+	}
+
+	return entity.Order{}, serviceerrs.ErrOrderNotFound
+}
+
+// changeError переводит ошибку изменения статуса в ошибку сервиса.
+func (s *OrderService) changeError(where string, err error) error {
+	switch {
+	case errors.Is(err, repoerrs.ErrConflict):
+		return serviceerrs.ErrOrderStatusChanged
+	case errors.Is(err, repoerrs.ErrNotFound):
+		return serviceerrs.ErrOrderNotFound
+	}
+	log.Errorf("%s: %v", where, err)
+	return serviceerrs.ErrCannotUpdateOrder
+}
+
+// canFulfil сообщает, может ли actor выполнять заказ: администратор - любой,
+// продавец - только если все строки заказа его.
+func canFulfil(order entity.Order, actor types.AuthIdentity) bool {
+	if actor.HasRole(entity.RoleAdmin) {
+		return true
+	}
+	for _, item := range order.Items {
+		if item.SellerID != actor.UserID {
+			return false
+		}
+	}
+	return len(order.Items) > 0
+}
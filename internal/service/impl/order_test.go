@@ -0,0 +1,488 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/mocks/repomocks"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+func testOrder(status entity.OrderStatus, sellers ...int) entity.Order {
+	order := entity.Order{ID: 7, UserID: 1, Status: status, Total: 10}
+	for i, sellerId := range sellers {
+		order.Items = append(order.Items, entity.OrderItem{ProductID: i + 1, SellerID: sellerId, UnitPrice: 5, Quantity: 1})
+	}
+	return order
+}
+
+func TestOrderTransitions(t *testing.T) {
+	statuses := []entity.OrderStatus{
+		entity.OrderStatusPending,
+		entity.OrderStatusPaid,
+		entity.OrderStatusShipped,
+		entity.OrderStatusDelivered,
+		entity.OrderStatusCancelled,
+		entity.OrderStatusRefunded,
+	}
+
+	allowed := map[[2]entity.OrderStatus]bool{
+		{entity.OrderStatusPending, entity.OrderStatusPaid}:       true,
+		{entity.OrderStatusPending, entity.OrderStatusCancelled}:  true,
+		{entity.OrderStatusPaid, entity.OrderStatusShipped}:       true,
+		{entity.OrderStatusPaid, entity.OrderStatusCancelled}:     true,
+		{entity.OrderStatusPaid, entity.OrderStatusRefunded}:      true,
+		{entity.OrderStatusShipped, entity.OrderStatusDelivered}:  true,
+		{entity.OrderStatusDelivered, entity.OrderStatusRefunded}: true,
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			if got := canTransition(from, to); got != allowed[[2]entity.OrderStatus{from, to}] {
+				t.Errorf("canTransition(%s, %s) = %v", from, to, got)
+			}
+		}
+	}
+}
+
+func TestOrderService_GetOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	order := testOrder(entity.OrderStatusPaid, 2, 3)
+	history := []entity.OrderStatusChange{{OrderID: 7, To: entity.OrderStatusPaid, ActorID: 1}}
+
+	orderRepo := repomocks.NewMockOrder(ctrl)
+	orderRepo.EXPECT().GetOrder(ctx, 7).Return(order, nil).Times(2)
+	orderRepo.EXPECT().GetOrderHistory(ctx, 7).Return(history, nil)
+	purchaseRepo := repomocks.NewMockPurchase(ctrl)
+	purchaseRepo.EXPECT().GetOrderRefunds(ctx, 7).Return(nil, nil)
+
+	s := NewOrderService(orderRepo, purchaseRepo)
+
+	// Продавец одной из строк видит заказ.
+	got, err := s.GetOrder(ctx, types.OrderGetOrderInput{OrderID: 7, Actor: types.AuthIdentity{UserID: 3, Roles: []entity.Role{entity.RoleSeller}}})
+	if err != nil {
+		t.Fatalf("GetOrder() error = %v", err)
+	}
+	if want := (types.OrderDetails{Order: order, History: history}); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetOrder() = %+v, want %+v", got, want)
+	}
+
+	// Чужой заказ не раскрывается.
+	if _, err = s.GetOrder(ctx, types.OrderGetOrderInput{OrderID: 7, Actor: types.AuthIdentity{UserID: 4}}); !errors.Is(err, serviceerrs.ErrOrderNotFound) {
+		t.Errorf("GetOrder() error = %v, want %v", err, serviceerrs.ErrOrderNotFound)
+	}
+}
+
+func TestOrderService_Cancel(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		input types.OrderCancelInput
+	}
+
+	type MockBehaviour func(m *repomocks.MockOrder, args args)
+
+	buyer := types.AuthIdentity{UserID: 1, Roles: []entity.Role{entity.RoleBuyer}}
+	seller := types.AuthIdentity{UserID: 2, Roles: []entity.Role{entity.RoleSeller}}
+	admin := types.AuthIdentity{UserID: 9, Roles: []entity.Role{entity.RoleAdmin}}
+
+	testCases := []struct {
+		name          string
+		args          args
+		mockBehaviour MockBehaviour
+		wantStatus    entity.OrderStatus
+		wantErr       error
+	}{
+		{
+			name: "Buyer cancels paid order with refund",
+			args: args{
+				ctx:   context.Background(),
+				input: types.OrderCancelInput{OrderID: 7, Reason: "changed my mind", Actor: buyer},
+			},
+			mockBehaviour: func(m *repomocks.MockOrder, args args) {
+				m.EXPECT().GetOrder(args.ctx, 7).Return(testOrder(entity.OrderStatusPaid, 2), nil)
+				m.EXPECT().CancelOrder(args.ctx, entity.OrderStatusChange{
+					OrderID: 7,
+					From:    entity.OrderStatusPaid,
+					To:      entity.OrderStatusCancelled,
+					ActorID: 1,
+					Reason:  "changed my mind",
+				}, true).Return(nil)
+			},
+			wantStatus: entity.OrderStatusCancelled,
+		},
+		{
+			name: "Pending order is cancelled without refund",
+			args: args{
+				ctx:   context.Background(),
+				input: types.OrderCancelInput{OrderID: 7, Actor: admin},
+			},
+			mockBehaviour: func(m *repomocks.MockOrder, args args) {
+				m.EXPECT().GetOrder(args.ctx, 7).Return(testOrder(entity.OrderStatusPending, 2), nil)
+				m.EXPECT().CancelOrder(args.ctx, entity.OrderStatusChange{
+					OrderID: 7,
+					From:    entity.OrderStatusPending,
+					To:      entity.OrderStatusCancelled,
+					ActorID: 9,
+				}, false).Return(nil)
+			},
+			wantStatus: entity.OrderStatusCancelled,
+		},
+		{
+			name: "Seller of all lines",
+			args: args{
+				ctx:   context.Background(),
+				input: types.OrderCancelInput{OrderID: 7, Actor: seller},
+			},
+			mockBehaviour: func(m *repomocks.MockOrder, args args) {
+				m.EXPECT().GetOrder(args.ctx, 7).Return(testOrder(entity.OrderStatusPaid, 2, 2), nil)
+				m.EXPECT().CancelOrder(args.ctx, gomock.Any(), true).Return(nil)
+			},
+			wantStatus: entity.OrderStatusCancelled,
+		},
+		{
+			name: "Seller of some lines",
+			args: args{
+				ctx:   context.Background(),
+				input: types.OrderCancelInput{OrderID: 7, Actor: seller},
+			},
+			mockBehaviour: func(m *repomocks.MockOrder, args args) {
+				m.EXPECT().GetOrder(args.ctx, 7).Return(testOrder(entity.OrderStatusPaid, 2, 3), nil)
+			},
+			wantErr: serviceerrs.ErrOrderForbidden,
+		},
+		{
+			name: "Shipped order",
+			args: args{
+				ctx:   context.Background(),
+				input: types.OrderCancelInput{OrderID: 7, Actor: buyer},
+			},
+			mockBehaviour: func(m *repomocks.MockOrder, args args) {
+				m.EXPECT().GetOrder(args.ctx, 7).Return(testOrder(entity.OrderStatusShipped, 2), nil)
+			},
+			wantErr: serviceerrs.ErrInvalidOrderTransition,
+		},
+		{
+			name: "Status changed concurrently",
+			args: args{
+				ctx:   context.Background(),
+				input: types.OrderCancelInput{OrderID: 7, Actor: buyer},
+			},
+			mockBehaviour: func(m *repomocks.MockOrder, args args) {
+				m.EXPECT().GetOrder(args.ctx, 7).Return(testOrder(entity.OrderStatusPaid, 2), nil)
+				m.EXPECT().CancelOrder(args.ctx, gomock.Any(), true).Return(repoerrs.ErrConflict)
+			},
+			wantErr: serviceerrs.ErrOrderStatusChanged,
+		},
+		{
+			name: "Order not found",
+			args: args{
+				ctx:   context.Background(),
+				input: types.OrderCancelInput{OrderID: 7, Actor: buyer},
+			},
+			mockBehaviour: func(m *repomocks.MockOrder, args args) {
+				m.EXPECT().GetOrder(args.ctx, 7).Return(entity.Order{}, repoerrs.ErrNotFound)
+			},
+			wantErr: serviceerrs.ErrOrderNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			orderRepo := repomocks.NewMockOrder(ctrl)
+			tc.mockBehaviour(orderRepo, tc.args)
+
+			s := NewOrderService(orderRepo, repomocks.NewMockPurchase(ctrl))
+			got, err := s.Cancel(tc.args.ctx, tc.args.input)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Cancel() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got.Status != tc.wantStatus {
+				t.Errorf("Cancel() status = %q, want %q", got.Status, tc.wantStatus)
+			}
+		})
+	}
+}
+
+func TestOrderService_Advance(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		input types.OrderAdvanceInput
+	}
+
+	type MockBehaviour func(m *repomocks.MockOrder, args args)
+
+	buyer := types.AuthIdentity{UserID: 1, Roles: []entity.Role{entity.RoleBuyer}}
+	seller := types.AuthIdentity{UserID: 2, Roles: []entity.Role{entity.RoleSeller}}
+
+	testCases := []struct {
+		name          string
+		args          args
+		mockBehaviour MockBehaviour
+		wantStatus    entity.OrderStatus
+		wantErr       error
+	}{
+		{
+			name: "Paid to shipped",
+			args: args{
+				ctx:   context.Background(),
+				input: types.OrderAdvanceInput{OrderID: 7, Reason: "tracking 123", Actor: seller},
+			},
+			mockBehaviour: func(m *repomocks.MockOrder, args args) {
+				m.EXPECT().GetOrder(args.ctx, 7).Return(testOrder(entity.OrderStatusPaid, 2), nil)
+				m.EXPECT().UpdateOrderStatus(args.ctx, entity.OrderStatusChange{
+					OrderID: 7,
+					From:    entity.OrderStatusPaid,
+					To:      entity.OrderStatusShipped,
+					ActorID: 2,
+					Reason:  "tracking 123",
+				}).Return(nil)
+			},
+			wantStatus: entity.OrderStatusShipped,
+		},
+		{
+			name: "Shipped to delivered",
+			args: args{
+				ctx:   context.Background(),
+				input: types.OrderAdvanceInput{OrderID: 7, Actor: seller},
+			},
+			mockBehaviour: func(m *repomocks.MockOrder, args args) {
+				m.EXPECT().GetOrder(args.ctx, 7).Return(testOrder(entity.OrderStatusShipped, 2), nil)
+				m.EXPECT().UpdateOrderStatus(args.ctx, gomock.Any()).Return(nil)
+			},
+			wantStatus: entity.OrderStatusDelivered,
+		},
+		{
+			name: "Delivered is final for fulfilment",
+			args: args{
+				ctx:   context.Background(),
+				input: types.OrderAdvanceInput{OrderID: 7, Actor: seller},
+			},
+			mockBehaviour: func(m *repomocks.MockOrder, args args) {
+				m.EXPECT().GetOrder(args.ctx, 7).Return(testOrder(entity.OrderStatusDelivered, 2), nil)
+			},
+			wantErr: serviceerrs.ErrInvalidOrderTransition,
+		},
+		{
+			name: "Pending cannot be advanced",
+			args: args{
+				ctx:   context.Background(),
+				input: types.OrderAdvanceInput{OrderID: 7, Actor: seller},
+			},
+			mockBehaviour: func(m *repomocks.MockOrder, args args) {
+				m.EXPECT().GetOrder(args.ctx, 7).Return(testOrder(entity.OrderStatusPending, 2), nil)
+			},
+			wantErr: serviceerrs.ErrInvalidOrderTransition,
+		},
+		{
+			name: "Buyer cannot advance",
+			args: args{
+				ctx:   context.Background(),
+				input: types.OrderAdvanceInput{OrderID: 7, Actor: buyer},
+			},
+			mockBehaviour: func(m *repomocks.MockOrder, args args) {
+				m.EXPECT().GetOrder(args.ctx, 7).Return(testOrder(entity.OrderStatusPaid, 2), nil)
+			},
+			wantErr: serviceerrs.ErrOrderForbidden,
+		},
+		{
+			name: "Repo error",
+			args: args{
+				ctx:   context.Background(),
+				input: types.OrderAdvanceInput{OrderID: 7, Actor: seller},
+			},
+			mockBehaviour: func(m *repomocks.MockOrder, args args) {
+				m.EXPECT().GetOrder(args.ctx, 7).Return(testOrder(entity.OrderStatusPaid, 2), nil)
+				m.EXPECT().UpdateOrderStatus(args.ctx, gomock.Any()).Return(errors.New("some error"))
+			},
+			wantErr: serviceerrs.ErrCannotUpdateOrder,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			orderRepo := repomocks.NewMockOrder(ctrl)
+			tc.mockBehaviour(orderRepo, tc.args)
+
+			s := NewOrderService(orderRepo, repomocks.NewMockPurchase(ctrl))
+			got, err := s.Advance(tc.args.ctx, tc.args.input)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Advance() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got.Status != tc.wantStatus {
+				t.Errorf("Advance() status = %q, want %q", got.Status, tc.wantStatus)
+			}
+		})
+	}
+}
+
+func TestOrderService_Refund(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		input types.OrderRefundInput
+	}
+
+	type MockBehaviour func(o *repomocks.MockOrder, p *repomocks.MockPurchase, args args)
+
+	seller := types.AuthIdentity{UserID: 2, Roles: []entity.Role{entity.RoleSeller}}
+	admin := types.AuthIdentity{UserID: 9, Roles: []entity.Role{entity.RoleAdmin}}
+
+	// Заказ 7: две единицы товара 1 от продавца 2 и одна товара 2 от продавца 3.
+	order := func(status entity.OrderStatus) entity.Order {
+		return entity.Order{ID: 7, UserID: 1, Status: status, Total: 15, Items: []entity.OrderItem{
+			{ID: 11, ProductID: 1, SellerID: 2, UnitPrice: 5, Quantity: 2},
+			{ID: 12, ProductID: 2, SellerID: 3, UnitPrice: 5, Quantity: 1},
+		}}
+	}
+	previous := []entity.Refund{{ID: 1, OrderID: 7, Items: []entity.RefundItem{{OrderItemID: 11, ProductID: 1, Quantity: 1, Amount: 5}}}}
+
+	testCases := []struct {
+		name          string
+		args          args
+		mockBehaviour MockBehaviour
+		want          entity.Refund
+		wantErr       error
+	}{
+		{
+			name: "Partial refund by seller",
+			args: args{
+				ctx: context.Background(),
+				input: types.OrderRefundInput{OrderID: 7, Reason: "damaged", Actor: seller, Items: []types.OrderRefundItemInput{
+					{ProductID: 1, Quantity: 1},
+				}},
+			},
+			mockBehaviour: func(o *repomocks.MockOrder, p *repomocks.MockPurchase, args args) {
+				o.EXPECT().GetOrder(args.ctx, 7).Return(order(entity.OrderStatusDelivered), nil)
+				p.EXPECT().GetOrderRefunds(args.ctx, 7).Return(nil, nil)
+				p.EXPECT().CreateRefund(args.ctx, entity.Refund{
+					OrderID: 7,
+					ActorID: 2,
+					Reason:  "damaged",
+					Items:   []entity.RefundItem{{ProductID: 1, Quantity: 1}},
+				}, entity.OrderStatusDelivered).Return(entity.Refund{ID: 3, OrderID: 7, Amount: 5}, nil)
+			},
+			want: entity.Refund{ID: 3, OrderID: 7, Amount: 5},
+		},
+		{
+			name: "Admin refunds the rest",
+			args: args{
+				ctx:   context.Background(),
+				input: types.OrderRefundInput{OrderID: 7, Reason: "lost parcel", Actor: admin},
+			},
+			mockBehaviour: func(o *repomocks.MockOrder, p *repomocks.MockPurchase, args args) {
+				o.EXPECT().GetOrder(args.ctx, 7).Return(order(entity.OrderStatusPaid), nil)
+				p.EXPECT().GetOrderRefunds(args.ctx, 7).Return(previous, nil)
+				p.EXPECT().CreateRefund(args.ctx, entity.Refund{
+					OrderID: 7,
+					ActorID: 9,
+					Reason:  "lost parcel",
+					Items:   []entity.RefundItem{{ProductID: 1, Quantity: 1}, {ProductID: 2, Quantity: 1}},
+				}, entity.OrderStatusPaid).Return(entity.Refund{ID: 4, OrderID: 7, Amount: 10}, nil)
+			},
+			want: entity.Refund{ID: 4, OrderID: 7, Amount: 10},
+		},
+		{
+			name: "More than bought minus refunded",
+			args: args{
+				ctx: context.Background(),
+				input: types.OrderRefundInput{OrderID: 7, Reason: "damaged", Actor: seller, Items: []types.OrderRefundItemInput{
+					{ProductID: 1, Quantity: 1},
+					{ProductID: 1, Quantity: 1},
+				}},
+			},
+			mockBehaviour: func(o *repomocks.MockOrder, p *repomocks.MockPurchase, args args) {
+				o.EXPECT().GetOrder(args.ctx, 7).Return(order(entity.OrderStatusDelivered), nil)
+				p.EXPECT().GetOrderRefunds(args.ctx, 7).Return(previous, nil)
+			},
+			wantErr: serviceerrs.ErrRefundExceedsPurchase,
+		},
+		{
+			name: "Product not in order",
+			args: args{
+				ctx: context.Background(),
+				input: types.OrderRefundInput{OrderID: 7, Reason: "damaged", Actor: seller, Items: []types.OrderRefundItemInput{
+					{ProductID: 5, Quantity: 1},
+				}},
+			},
+			mockBehaviour: func(o *repomocks.MockOrder, p *repomocks.MockPurchase, args args) {
+				o.EXPECT().GetOrder(args.ctx, 7).Return(order(entity.OrderStatusDelivered), nil)
+				p.EXPECT().GetOrderRefunds(args.ctx, 7).Return(nil, nil)
+			},
+			wantErr: serviceerrs.ErrOrderItemNotFound,
+		},
+		{
+			name: "Seller refunds another seller's line",
+			args: args{
+				ctx: context.Background(),
+				input: types.OrderRefundInput{OrderID: 7, Reason: "damaged", Actor: seller, Items: []types.OrderRefundItemInput{
+					{ProductID: 2, Quantity: 1},
+				}},
+			},
+			mockBehaviour: func(o *repomocks.MockOrder, p *repomocks.MockPurchase, args args) {
+				o.EXPECT().GetOrder(args.ctx, 7).Return(order(entity.OrderStatusDelivered), nil)
+				p.EXPECT().GetOrderRefunds(args.ctx, 7).Return(nil, nil)
+			},
+			wantErr: serviceerrs.ErrOrderForbidden,
+		},
+		{
+			name: "Shipped order",
+			args: args{
+				ctx:   context.Background(),
+				input: types.OrderRefundInput{OrderID: 7, Reason: "damaged", Actor: admin},
+			},
+			mockBehaviour: func(o *repomocks.MockOrder, p *repomocks.MockPurchase, args args) {
+				o.EXPECT().GetOrder(args.ctx, 7).Return(order(entity.OrderStatusShipped), nil)
+				p.EXPECT().GetOrderRefunds(args.ctx, 7).Return(nil, nil)
+			},
+			wantErr: serviceerrs.ErrInvalidOrderTransition,
+		},
+		{
+			name: "Concurrent refund took the units",
+			args: args{
+				ctx:   context.Background(),
+				input: types.OrderRefundInput{OrderID: 7, Reason: "damaged", Actor: admin},
+			},
+			mockBehaviour: func(o *repomocks.MockOrder, p *repomocks.MockPurchase, args args) {
+				o.EXPECT().GetOrder(args.ctx, 7).Return(order(entity.OrderStatusPaid), nil)
+				p.EXPECT().GetOrderRefunds(args.ctx, 7).Return(nil, nil)
+				p.EXPECT().CreateRefund(args.ctx, gomock.Any(), entity.OrderStatusPaid).Return(entity.Refund{}, repoerrs.ErrRefundExceeded)
+			},
+			wantErr: serviceerrs.ErrRefundExceedsPurchase,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			orderRepo := repomocks.NewMockOrder(ctrl)
+			purchaseRepo := repomocks.NewMockPurchase(ctrl)
+			tc.mockBehaviour(orderRepo, purchaseRepo, tc.args)
+
+			s := NewOrderService(orderRepo, purchaseRepo)
+			got, err := s.Refund(tc.args.ctx, tc.args.input)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Refund() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Refund() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
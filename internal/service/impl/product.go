@@ -3,6 +3,7 @@ package impl
 import (
 	"context"
 	"errors"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -14,11 +15,21 @@ import (
 )
 
 type ProductService struct {
-	productRepo repository.Product
+	productRepo    repository.Product
+	inventoryRepo  repository.Inventory
+	warehouseRepo  repository.Warehouse
+	stockAlertRepo repository.StockAlert
+	now            func() time.Time
 }
 
-func NewProductService(productRepo repository.Product) *ProductService {
-	return &ProductService{productRepo: productRepo}
+func NewProductService(productRepo repository.Product, inventoryRepo repository.Inventory, warehouseRepo repository.Warehouse, stockAlertRepo repository.StockAlert) *ProductService {
+	return &ProductService{
+		productRepo:    productRepo,
+		inventoryRepo:  inventoryRepo,
+		warehouseRepo:  warehouseRepo,
+		stockAlertRepo: stockAlertRepo,
+		now:            time.Now,
+	}
 }
 
 func (s *ProductService) AddProduct(ctx context.Context, input types.ProductAddProductInput) (int, error) {
@@ -27,12 +38,16 @@ func (s *ProductService) AddProduct(ctx context.Context, input types.ProductAddP
 		Description: input.Description,
 		Price:       input.Price,
 		Quantity:    input.Quantity,
+		SellerID:    input.SellerID,
 	}
 
 	id, err := s.productRepo.AddProduct(ctx, product)
 	if err != nil {
-		if errors.Is(err, repoerrs.ErrAlreadyExists) {
+		switch {
+		case errors.Is(err, repoerrs.ErrAlreadyExists):
 			return 0, serviceerrs.ErrProductAlreadyExists
+		case errors.Is(err, repoerrs.ErrInvalidPrice):
+			return 0, serviceerrs.ErrInvalidPrice
 		}
 		log.Errorf("ProductService.AddProduct - s.productRepo.AddProduct: %v", err)
 		return 0, serviceerrs.ErrCannotCreateProduct
@@ -59,17 +74,201 @@ func (s *ProductService) GetProductById(ctx context.Context, productId int) (ent
 }
 
 func (s *ProductService) UpdateProduct(ctx context.Context, input types.ProductUpdateProductInput) error {
+	current, err := s.getOwnedProduct(ctx, input.ID, input.Actor)
+	if err != nil {
+		return err
+	}
+
 	product := entity.Product{
 		ID:          input.ID,
 		Name:        input.Name,
 		Description: input.Description,
 		Price:       input.Price,
-		Quantity:    input.Quantity,
+		SellerID:    current.SellerID,
+	}
+
+	if err := s.productRepo.UpdateProduct(ctx, product); err != nil {
+		if errors.Is(err, repoerrs.ErrInvalidPrice) {
+			return serviceerrs.ErrInvalidPrice
+		}
+		log.Errorf("ProductService.UpdateProduct - s.productRepo.UpdateProduct: %v", err)
+		return serviceerrs.ErrCannotUpdateProduct
+	}
+
+	return nil
+}
+
+func (s *ProductService) DeleteProduct(ctx context.Context, input types.ProductDeleteProductInput) error {
+	if _, err := s.getOwnedProduct(ctx, input.ID, input.Actor); err != nil {
+		return err
 	}
 
-	return s.productRepo.UpdateProduct(ctx, product)
+	if err := s.productRepo.DeleteProduct(ctx, input.ID); err != nil {
+		log.Errorf("ProductService.DeleteProduct - s.productRepo.DeleteProduct: %v", err)
+		return serviceerrs.ErrCannotDeleteProduct
+	}
+
+	return nil
 }
 
-func (s *ProductService) DeleteProduct(ctx context.Context, productId int) error {
-	return s.productRepo.DeleteProduct(ctx, productId)
+// AdjustStock меняет остаток товара на складе вручную: restock добавляет
+// поступившие единицы, adjustment исправляет остаток в любую сторону. Списать
+// больше, чем есть на складе, или забронированные единицы нельзя.
+func (s *ProductService) AdjustStock(ctx context.Context, input types.ProductAdjustStockInput) (entity.InventoryMovement, error) {
+	switch {
+	case input.Kind == entity.InventoryMovementRestock && input.Delta > 0:
+	case input.Kind == entity.InventoryMovementAdjustment && input.Delta != 0:
+	default:
+		return entity.InventoryMovement{}, serviceerrs.ErrInvalidStockAdjustment
+	}
+
+	if _, err := s.getOwnedProduct(ctx, input.ID, input.Actor); err != nil {
+		return entity.InventoryMovement{}, err
+	}
+
+	if input.WarehouseID != 0 {
+		if _, err := s.warehouseRepo.GetWarehouse(ctx, input.WarehouseID); err != nil {
+			if errors.Is(err, repoerrs.ErrNotFound) {
+				return entity.InventoryMovement{}, serviceerrs.ErrWarehouseNotFound
+			}
+			log.Errorf("ProductService.AdjustStock - s.warehouseRepo.GetWarehouse: %v", err)
+			return entity.InventoryMovement{}, serviceerrs.ErrCannotAdjustStock
+		}
+	}
+
+	movement, err := s.inventoryRepo.AdjustStock(ctx, entity.InventoryMovement{
+		ProductID:   input.ID,
+		WarehouseID: input.WarehouseID,
+		Kind:        input.Kind,
+		Delta:       input.Delta,
+		ActorID:     input.Actor.UserID,
+		Reason:      input.Reason,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, repoerrs.ErrNotFound):
+			return entity.InventoryMovement{}, serviceerrs.ErrProductNotFound
+		case errors.Is(err, repoerrs.ErrNotEnoughStock):
+			return entity.InventoryMovement{}, serviceerrs.ErrStockBelowReserved
+		}
+		log.Errorf("ProductService.AdjustStock - s.inventoryRepo.AdjustStock: %v", err)
+		return entity.InventoryMovement{}, serviceerrs.ErrCannotAdjustStock
+	}
+
+	return movement, nil
+}
+
+// GetStockMovements возвращает движения товара за период его продавцу или
+// администратору.
+func (s *ProductService) GetStockMovements(ctx context.Context, input types.ProductStockMovementsInput) ([]entity.InventoryMovement, error) {
+	if input.To.IsZero() {
+		input.To = s.now()
+	}
+	if !input.From.Before(input.To) {
+		return nil, serviceerrs.ErrInvalidStockPeriod
+	}
+
+	if _, err := s.getOwnedProduct(ctx, input.ID, input.Actor); err != nil {
+		return nil, err
+	}
+
+	movements, err := s.inventoryRepo.GetProductMovements(ctx, input.ID, input.From, input.To)
+	if err != nil {
+		log.Errorf("ProductService.GetStockMovements - s.inventoryRepo.GetProductMovements: %v", err)
+		return nil, serviceerrs.ErrCannotGetStock
+	}
+
+	return movements, nil
+}
+
+// GetStockAt восстанавливает остаток товара на момент input.At по журналу
+// движений.
+func (s *ProductService) GetStockAt(ctx context.Context, input types.ProductStockAtInput) (int, error) {
+	if input.At.IsZero() {
+		input.At = s.now()
+	}
+
+	if _, err := s.getOwnedProduct(ctx, input.ID, input.Actor); err != nil {
+		return 0, err
+	}
+
+	quantity, err := s.inventoryRepo.GetStockAt(ctx, input.ID, input.At)
+	if err != nil {
+		log.Errorf("ProductService.GetStockAt - s.inventoryRepo.GetStockAt: %v", err)
+		return 0, serviceerrs.ErrCannotGetStock
+	}
+
+	return quantity, nil
+}
+
+// GetWarehouseStock возвращает остатки товара по складам его продавцу или
+// администратору. Их сумма - общий остаток товара.
+func (s *ProductService) GetWarehouseStock(ctx context.Context, input types.ProductWarehouseStockInput) ([]entity.WarehouseStock, error) {
+	if _, err := s.getOwnedProduct(ctx, input.ID, input.Actor); err != nil {
+		return nil, err
+	}
+
+	stock, err := s.inventoryRepo.GetProductStock(ctx, input.ID)
+	if err != nil {
+		log.Errorf("ProductService.GetWarehouseStock - s.inventoryRepo.GetProductStock: %v", err)
+		return nil, serviceerrs.ErrCannotGetStock
+	}
+
+	return stock, nil
+}
+
+// SetReorderThreshold задает порог дозаказа товара: когда остаток опускается
+// до порога, продавцу уходит предупреждение. Nil снимает порог.
+func (s *ProductService) SetReorderThreshold(ctx context.Context, input types.ProductReorderThresholdInput) error {
+	if input.Threshold != nil && *input.Threshold < 0 {
+		return serviceerrs.ErrInvalidReorderThreshold
+	}
+
+	if _, err := s.getOwnedProduct(ctx, input.ID, input.Actor); err != nil {
+		return err
+	}
+
+	if err := s.productRepo.SetReorderThreshold(ctx, input.ID, input.Threshold); err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return serviceerrs.ErrProductNotFound
+		}
+		log.Errorf("ProductService.SetReorderThreshold - s.productRepo.SetReorderThreshold: %v", err)
+		return serviceerrs.ErrCannotSetReorderThreshold
+	}
+
+	return nil
+}
+
+// GetStockAlerts возвращает предупреждения о низком остатке товара его
+// продавцу или администратору.
+func (s *ProductService) GetStockAlerts(ctx context.Context, input types.ProductStockAlertsInput) ([]entity.StockAlert, error) {
+	if _, err := s.getOwnedProduct(ctx, input.ID, input.Actor); err != nil {
+		return nil, err
+	}
+
+	alerts, err := s.stockAlertRepo.GetProductStockAlerts(ctx, input.ID)
+	if err != nil {
+		log.Errorf("ProductService.GetStockAlerts - s.stockAlertRepo.GetProductStockAlerts: %v", err)
+		return nil, serviceerrs.ErrCannotGetStockAlerts
+	}
+
+	return alerts, nil
+}
+
+// getOwnedProduct возвращает товар, если actor - его продавец или администратор.
+func (s *ProductService) getOwnedProduct(ctx context.Context, productId int, actor types.AuthIdentity) (entity.Product, error) {
+	product, err := s.productRepo.GetProductById(ctx, productId)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return entity.Product{}, serviceerrs.ErrProductNotFound
+		}
+		log.Errorf("ProductService.getOwnedProduct - s.productRepo.GetProductById: %v", err)
+		return entity.Product{}, serviceerrs.ErrCannotGetProduct
+	}
+
+	if product.SellerID != actor.UserID && !actor.HasRole(entity.RoleAdmin) {
+		return entity.Product{}, serviceerrs.ErrProductNotOwned
+	}
+
+	return product, nil
 }
@@ -0,0 +1,395 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/mocks/repomocks"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+func TestProductService_UpdateProduct(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		input types.ProductUpdateProductInput
+	}
+
+	type MockBehaviour func(m *repomocks.MockProduct, args args)
+
+	owned := entity.Product{ID: 1, Name: "old", Description: "old", Price: 10, Quantity: 1, SellerID: 5}
+
+	testCases := []struct {
+		name          string
+		args          args
+		mockBehaviour MockBehaviour
+		wantErr       error
+	}{
+		{
+			name: "OK",
+			args: args{
+				ctx: context.Background(),
+				input: types.ProductUpdateProductInput{
+					ID: 1, Name: "new", Description: "new", Price: 20,
+					Actor: types.AuthIdentity{UserID: 5, Roles: []entity.Role{entity.RoleSeller}},
+				},
+			},
+			mockBehaviour: func(m *repomocks.MockProduct, args args) {
+				m.EXPECT().GetProductById(args.ctx, 1).Return(owned, nil)
+				m.EXPECT().UpdateProduct(args.ctx, entity.Product{
+					ID: 1, Name: "new", Description: "new", Price: 20, SellerID: 5,
+				}).Return(nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Another seller",
+			args: args{
+				ctx: context.Background(),
+				input: types.ProductUpdateProductInput{
+					ID: 1, Name: "new", Description: "new", Price: 20,
+					Actor: types.AuthIdentity{UserID: 6, Roles: []entity.Role{entity.RoleSeller}},
+				},
+			},
+			mockBehaviour: func(m *repomocks.MockProduct, args args) {
+				m.EXPECT().GetProductById(args.ctx, 1).Return(owned, nil)
+			},
+			wantErr: serviceerrs.ErrProductNotOwned,
+		},
+		{
+			name: "Admin",
+			args: args{
+				ctx: context.Background(),
+				input: types.ProductUpdateProductInput{
+					ID: 1, Name: "new", Description: "new", Price: 20,
+					Actor: types.AuthIdentity{UserID: 1, Roles: []entity.Role{entity.RoleAdmin}},
+				},
+			},
+			mockBehaviour: func(m *repomocks.MockProduct, args args) {
+				m.EXPECT().GetProductById(args.ctx, 1).Return(owned, nil)
+				m.EXPECT().UpdateProduct(args.ctx, gomock.Any()).Return(nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Product not found",
+			args: args{
+				ctx: context.Background(),
+				input: types.ProductUpdateProductInput{
+					ID:    42,
+					Actor: types.AuthIdentity{UserID: 5, Roles: []entity.Role{entity.RoleSeller}},
+				},
+			},
+			mockBehaviour: func(m *repomocks.MockProduct, args args) {
+				m.EXPECT().GetProductById(args.ctx, 42).Return(entity.Product{}, repoerrs.ErrNotFound)
+			},
+			wantErr: serviceerrs.ErrProductNotFound,
+		},
+		{
+			name: "Price rounds to zero",
+			args: args{
+				ctx: context.Background(),
+				input: types.ProductUpdateProductInput{
+					ID: 1, Name: "new", Description: "new", Price: 0.001,
+					Actor: types.AuthIdentity{UserID: 5, Roles: []entity.Role{entity.RoleSeller}},
+				},
+			},
+			mockBehaviour: func(m *repomocks.MockProduct, args args) {
+				m.EXPECT().GetProductById(args.ctx, 1).Return(owned, nil)
+				m.EXPECT().UpdateProduct(args.ctx, gomock.Any()).Return(repoerrs.ErrInvalidPrice)
+			},
+			wantErr: serviceerrs.ErrInvalidPrice,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			productRepo := repomocks.NewMockProduct(ctrl)
+			tc.mockBehaviour(productRepo, tc.args)
+
+			s := NewProductService(productRepo, repomocks.NewMockInventory(ctrl), repomocks.NewMockWarehouse(ctrl), repomocks.NewMockStockAlert(ctrl))
+			err := s.UpdateProduct(tc.args.ctx, tc.args.input)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("UpdateProduct() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestProductService_DeleteProduct(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		input types.ProductDeleteProductInput
+	}
+
+	type MockBehaviour func(m *repomocks.MockProduct, args args)
+
+	testCases := []struct {
+		name          string
+		args          args
+		mockBehaviour MockBehaviour
+		wantErr       error
+	}{
+		{
+			name: "OK",
+			args: args{
+				ctx: context.Background(),
+				input: types.ProductDeleteProductInput{
+					ID:    1,
+					Actor: types.AuthIdentity{UserID: 5, Roles: []entity.Role{entity.RoleSeller}},
+				},
+			},
+			mockBehaviour: func(m *repomocks.MockProduct, args args) {
+				m.EXPECT().GetProductById(args.ctx, 1).Return(entity.Product{ID: 1, SellerID: 5}, nil)
+				m.EXPECT().DeleteProduct(args.ctx, 1).Return(nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Product without seller",
+			args: args{
+				ctx: context.Background(),
+				input: types.ProductDeleteProductInput{
+					ID:    1,
+					Actor: types.AuthIdentity{UserID: 5, Roles: []entity.Role{entity.RoleSeller}},
+				},
+			},
+			mockBehaviour: func(m *repomocks.MockProduct, args args) {
+				m.EXPECT().GetProductById(args.ctx, 1).Return(entity.Product{ID: 1}, nil)
+			},
+			wantErr: serviceerrs.ErrProductNotOwned,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			productRepo := repomocks.NewMockProduct(ctrl)
+			tc.mockBehaviour(productRepo, tc.args)
+
+			s := NewProductService(productRepo, repomocks.NewMockInventory(ctrl), repomocks.NewMockWarehouse(ctrl), repomocks.NewMockStockAlert(ctrl))
+			err := s.DeleteProduct(tc.args.ctx, tc.args.input)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("DeleteProduct() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestProductService_AdjustStock(t *testing.T) {
+	type MockBehaviour func(p *repomocks.MockProduct, i *repomocks.MockInventory, w *repomocks.MockWarehouse)
+
+	seller := types.AuthIdentity{UserID: 5, Roles: []entity.Role{entity.RoleSeller}}
+	owned := entity.Product{ID: 1, Quantity: 10, Reserved: 4, SellerID: 5}
+
+	testCases := []struct {
+		name          string
+		input         types.ProductAdjustStockInput
+		mockBehaviour MockBehaviour
+		want          entity.InventoryMovement
+		wantErr       error
+	}{
+		{
+			name:  "Restock",
+			input: types.ProductAdjustStockInput{ID: 1, Kind: entity.InventoryMovementRestock, Delta: 5, Reason: "delivery", Actor: seller},
+			mockBehaviour: func(p *repomocks.MockProduct, i *repomocks.MockInventory, w *repomocks.MockWarehouse) {
+				p.EXPECT().GetProductById(gomock.Any(), 1).Return(owned, nil)
+				i.EXPECT().AdjustStock(gomock.Any(), entity.InventoryMovement{
+					ProductID: 1, Kind: entity.InventoryMovementRestock, Delta: 5, ActorID: 5, Reason: "delivery",
+				}).Return(entity.InventoryMovement{ID: 3, ProductID: 1, Delta: 5, QuantityAfter: 15}, nil)
+			},
+			want: entity.InventoryMovement{ID: 3, ProductID: 1, Delta: 5, QuantityAfter: 15},
+		},
+		{
+			name:  "Restock into warehouse",
+			input: types.ProductAdjustStockInput{ID: 1, WarehouseID: 2, Kind: entity.InventoryMovementRestock, Delta: 5, Reason: "delivery", Actor: seller},
+			mockBehaviour: func(p *repomocks.MockProduct, i *repomocks.MockInventory, w *repomocks.MockWarehouse) {
+				p.EXPECT().GetProductById(gomock.Any(), 1).Return(owned, nil)
+				w.EXPECT().GetWarehouse(gomock.Any(), 2).Return(entity.Warehouse{ID: 2, Name: "north"}, nil)
+				i.EXPECT().AdjustStock(gomock.Any(), entity.InventoryMovement{
+					ProductID: 1, WarehouseID: 2, Kind: entity.InventoryMovementRestock, Delta: 5, ActorID: 5, Reason: "delivery",
+				}).Return(entity.InventoryMovement{ID: 4, ProductID: 1, WarehouseID: 2, Delta: 5, QuantityAfter: 15}, nil)
+			},
+			want: entity.InventoryMovement{ID: 4, ProductID: 1, WarehouseID: 2, Delta: 5, QuantityAfter: 15},
+		},
+		{
+			name:  "Unknown warehouse",
+			input: types.ProductAdjustStockInput{ID: 1, WarehouseID: 9, Kind: entity.InventoryMovementRestock, Delta: 5, Reason: "delivery", Actor: seller},
+			mockBehaviour: func(p *repomocks.MockProduct, i *repomocks.MockInventory, w *repomocks.MockWarehouse) {
+				p.EXPECT().GetProductById(gomock.Any(), 1).Return(owned, nil)
+				w.EXPECT().GetWarehouse(gomock.Any(), 9).Return(entity.Warehouse{}, repoerrs.ErrNotFound)
+			},
+			wantErr: serviceerrs.ErrWarehouseNotFound,
+		},
+		{
+			name:          "Negative restock",
+			input:         types.ProductAdjustStockInput{ID: 1, Kind: entity.InventoryMovementRestock, Delta: -5, Reason: "delivery", Actor: seller},
+			mockBehaviour: func(p *repomocks.MockProduct, i *repomocks.MockInventory, w *repomocks.MockWarehouse) {},
+			wantErr:       serviceerrs.ErrInvalidStockAdjustment,
+		},
+		{
+			name:          "Sale is not a manual movement",
+			input:         types.ProductAdjustStockInput{ID: 1, Kind: entity.InventoryMovementSale, Delta: -1, Reason: "sold", Actor: seller},
+			mockBehaviour: func(p *repomocks.MockProduct, i *repomocks.MockInventory, w *repomocks.MockWarehouse) {},
+			wantErr:       serviceerrs.ErrInvalidStockAdjustment,
+		},
+		{
+			name:  "Below reserved",
+			input: types.ProductAdjustStockInput{ID: 1, Kind: entity.InventoryMovementAdjustment, Delta: -8, Reason: "stocktake", Actor: seller},
+			mockBehaviour: func(p *repomocks.MockProduct, i *repomocks.MockInventory, w *repomocks.MockWarehouse) {
+				p.EXPECT().GetProductById(gomock.Any(), 1).Return(owned, nil)
+				i.EXPECT().AdjustStock(gomock.Any(), gomock.Any()).Return(entity.InventoryMovement{}, repoerrs.ErrNotEnoughStock)
+			},
+			wantErr: serviceerrs.ErrStockBelowReserved,
+		},
+		{
+			name:  "Another seller",
+			input: types.ProductAdjustStockInput{ID: 1, Kind: entity.InventoryMovementAdjustment, Delta: 1, Reason: "found", Actor: types.AuthIdentity{UserID: 6}},
+			mockBehaviour: func(p *repomocks.MockProduct, i *repomocks.MockInventory, w *repomocks.MockWarehouse) {
+				p.EXPECT().GetProductById(gomock.Any(), 1).Return(owned, nil)
+			},
+			wantErr: serviceerrs.ErrProductNotOwned,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			productRepo := repomocks.NewMockProduct(ctrl)
+			inventoryRepo := repomocks.NewMockInventory(ctrl)
+			warehouseRepo := repomocks.NewMockWarehouse(ctrl)
+			tc.mockBehaviour(productRepo, inventoryRepo, warehouseRepo)
+
+			s := NewProductService(productRepo, inventoryRepo, warehouseRepo, repomocks.NewMockStockAlert(ctrl))
+			got, err := s.AdjustStock(context.Background(), tc.input)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("AdjustStock() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("AdjustStock() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestProductService_GetStockAt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	admin := types.AuthIdentity{UserID: 1, Roles: []entity.Role{entity.RoleAdmin}}
+
+	productRepo := repomocks.NewMockProduct(ctrl)
+	inventoryRepo := repomocks.NewMockInventory(ctrl)
+	s := NewProductService(productRepo, inventoryRepo, repomocks.NewMockWarehouse(ctrl), repomocks.NewMockStockAlert(ctrl))
+	s.now = func() time.Time { return now }
+
+	productRepo.EXPECT().GetProductById(ctx, 1).Return(entity.Product{ID: 1, SellerID: 5}, nil).Times(2)
+	inventoryRepo.EXPECT().GetStockAt(ctx, 1, now.Add(-time.Hour)).Return(7, nil)
+	inventoryRepo.EXPECT().GetStockAt(ctx, 1, now).Return(4, nil)
+
+	got, err := s.GetStockAt(ctx, types.ProductStockAtInput{ID: 1, At: now.Add(-time.Hour), Actor: admin})
+	if err != nil || got != 7 {
+		t.Errorf("GetStockAt() = %d, %v, want 7", got, err)
+	}
+
+	// Без момента возвращается текущий остаток.
+	got, err = s.GetStockAt(ctx, types.ProductStockAtInput{ID: 1, Actor: admin})
+	if err != nil || got != 4 {
+		t.Errorf("GetStockAt() now = %d, %v, want 4", got, err)
+	}
+
+	_, err = s.GetStockMovements(ctx, types.ProductStockMovementsInput{ID: 1, From: now, To: now.Add(-time.Hour), Actor: admin})
+	if !errors.Is(err, serviceerrs.ErrInvalidStockPeriod) {
+		t.Errorf("GetStockMovements() error = %v, want %v", err, serviceerrs.ErrInvalidStockPeriod)
+	}
+}
+
+func TestProductService_SetReorderThreshold(t *testing.T) {
+	type MockBehaviour func(m *repomocks.MockProduct)
+
+	seller := types.AuthIdentity{UserID: 5, Roles: []entity.Role{entity.RoleSeller}}
+	owned := entity.Product{ID: 1, Quantity: 10, SellerID: 5}
+	threshold := func(n int) *int { return &n }
+
+	testCases := []struct {
+		name          string
+		input         types.ProductReorderThresholdInput
+		mockBehaviour MockBehaviour
+		wantErr       error
+	}{
+		{
+			name:  "OK",
+			input: types.ProductReorderThresholdInput{ID: 1, Threshold: threshold(3), Actor: seller},
+			mockBehaviour: func(m *repomocks.MockProduct) {
+				m.EXPECT().GetProductById(gomock.Any(), 1).Return(owned, nil)
+				m.EXPECT().SetReorderThreshold(gomock.Any(), 1, threshold(3)).Return(nil)
+			},
+		},
+		{
+			name:  "Disable",
+			input: types.ProductReorderThresholdInput{ID: 1, Actor: seller},
+			mockBehaviour: func(m *repomocks.MockProduct) {
+				m.EXPECT().GetProductById(gomock.Any(), 1).Return(owned, nil)
+				m.EXPECT().SetReorderThreshold(gomock.Any(), 1, nil).Return(nil)
+			},
+		},
+		{
+			name:          "Negative",
+			input:         types.ProductReorderThresholdInput{ID: 1, Threshold: threshold(-1), Actor: seller},
+			mockBehaviour: func(m *repomocks.MockProduct) {},
+			wantErr:       serviceerrs.ErrInvalidReorderThreshold,
+		},
+		{
+			name:  "Another seller",
+			input: types.ProductReorderThresholdInput{ID: 1, Threshold: threshold(3), Actor: types.AuthIdentity{UserID: 6}},
+			mockBehaviour: func(m *repomocks.MockProduct) {
+				m.EXPECT().GetProductById(gomock.Any(), 1).Return(owned, nil)
+			},
+			wantErr: serviceerrs.ErrProductNotOwned,
+		},
+		{
+			name:  "Cannot set",
+			input: types.ProductReorderThresholdInput{ID: 1, Threshold: threshold(3), Actor: seller},
+			mockBehaviour: func(m *repomocks.MockProduct) {
+				m.EXPECT().GetProductById(gomock.Any(), 1).Return(owned, nil)
+				m.EXPECT().SetReorderThreshold(gomock.Any(), 1, gomock.Any()).Return(errors.New("unexpected error"))
+			},
+			wantErr: serviceerrs.ErrCannotSetReorderThreshold,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			productRepo := repomocks.NewMockProduct(ctrl)
+			tc.mockBehaviour(productRepo)
+
+			s := NewProductService(productRepo, repomocks.NewMockInventory(ctrl), repomocks.NewMockWarehouse(ctrl), repomocks.NewMockStockAlert(ctrl))
+			err := s.SetReorderThreshold(context.Background(), tc.input)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("SetReorderThreshold() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
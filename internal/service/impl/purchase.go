@@ -6,59 +6,103 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository"
 	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
 	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
 	"github.com/cripplemymind9/go-market/internal/service/types"
-	"github.com/cripplemymind9/go-market/internal/repository"
-	"github.com/cripplemymind9/go-market/internal/entity"
 )
 
 type PurchaseService struct {
 	purchaseRepo repository.Purchase
+	userRepo     repository.User
 }
 
-func NewPurchaseService(purchaseRepo repository.Purchase) *PurchaseService {
-	return &PurchaseService{purchaseRepo: purchaseRepo}
+func NewPurchaseService(purchaseRepo repository.Purchase, userRepo repository.User) *PurchaseService {
+	return &PurchaseService{
+		purchaseRepo: purchaseRepo,
+		userRepo:     userRepo,
+	}
 }
 
+// MakePurchase оформляет заказ из одной или нескольких строк. Строки с одним
+// и тем же товаром объединяются. Заказ либо проходит целиком, либо не
+// оформляется вовсе. Возвращает id заказа.
 func (s *PurchaseService) MakePurchase(ctx context.Context, input types.PurchaseMakePurchaseInput) (int, error) {
-	purchase := entity.Purchase{
-		UserID: input.UserID,
-		ProductID: input.ProductID,
-		Quantity: input.Quantity,
+	if len(input.Items) == 0 {
+		return 0, serviceerrs.ErrEmptyOrder
 	}
 
-	id, err := s.purchaseRepo.MakePurchase(ctx, purchase)
+	order := entity.Order{UserID: input.UserID, ShipTo: input.ShipTo}
+	lines := make(map[int]int)
+	for _, item := range input.Items {
+		if item.Quantity <= 0 {
+			return 0, serviceerrs.ErrInvalidQuantity
+		}
+		if i, ok := lines[item.ProductID]; ok {
+			order.Items[i].Quantity += item.Quantity
+			continue
+		}
+		lines[item.ProductID] = len(order.Items)
+		order.Items = append(order.Items, entity.OrderItem{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	buyer, err := s.userRepo.GetUserProfile(ctx, input.UserID)
 	if err != nil {
-		log.Errorf("PurchaseService.MakePurchase - s.purchaseRepo.MakePurchase: %v", err)
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return 0, serviceerrs.ErrUserNotFound
+		}
+		log.Errorf("PurchaseService.MakePurchase - s.userRepo.GetUserProfile: %v", err)
+		return 0, serviceerrs.ErrCannotGetUser
+	}
+
+	if !buyer.EmailVerified {
+		return 0, serviceerrs.ErrEmailNotVerified
+	}
+
+	id, err := s.purchaseRepo.CreateOrder(ctx, order)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return 0, serviceerrs.ErrProductNotFound
+		}
+		if errors.Is(err, repoerrs.ErrNotEnoughStock) {
+			return 0, serviceerrs.ErrNotEnoughStock
+		}
+		if errors.Is(err, repoerrs.ErrNotEnoughBalance) {
+			return 0, serviceerrs.ErrNotEnoughBalance
+		}
+		log.Errorf("PurchaseService.MakePurchase - s.purchaseRepo.CreateOrder: %v", err)
 		return 0, serviceerrs.ErrCannotCreatePurchase
 	}
 
 	return id, nil
 }
 
-func (s *PurchaseService) GetUserPurchases(ctx context.Context, userId int) ([]entity.Purchase, error) {
-	purchases, err := s.purchaseRepo.GetUserPurchases(ctx, userId)
+func (s *PurchaseService) GetUserOrders(ctx context.Context, userId int) ([]entity.Order, error) {
+	orders, err := s.purchaseRepo.GetUserOrders(ctx, userId)
 	if err != nil {
 		if errors.Is(err, repoerrs.ErrNotFound) {
 			return nil, serviceerrs.ErrNoUserPurchasesFound
 		}
-		log.Errorf("PurchaseService.GetUserPurchases - s.purchaseRepo.GetUserPurchases: %v", err)
+		log.Errorf("PurchaseService.GetUserOrders - s.purchaseRepo.GetUserOrders: %v", err)
 		return nil, serviceerrs.ErrCannotGetUserPurchases
 	}
 
-	return purchases, nil
+	return orders, nil
 }
 
-func (s *PurchaseService) GetProductPurchases(ctx context.Context, productId int) ([]entity.Purchase, error) {
-	purchases, err := s.purchaseRepo.GetProductPurchases(ctx, productId)
+func (s *PurchaseService) GetProductOrders(ctx context.Context, productId int) ([]entity.Order, error) {
+	orders, err := s.purchaseRepo.GetProductOrders(ctx, productId)
 	if err != nil {
 		if errors.Is(err, repoerrs.ErrNotFound) {
 			return nil, serviceerrs.ErrNoProductPurchasesFound
 		}
-		log.Errorf("PurchaseService.GetProductPurchases - s.purchaseRepo.GetProductPurchases: %v", err)
+		log.Errorf("PurchaseService.GetProductOrders - s.purchaseRepo.GetProductOrders: %v", err)
 		return nil, serviceerrs.ErrCannotGetProductPurchases
 	}
 
-	return purchases, nil
-}
\ No newline at end of file
+	return orders, nil
+}
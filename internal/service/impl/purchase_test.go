@@ -0,0 +1,218 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/mocks/repomocks"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+func TestPurchaseService_MakePurchase(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		input types.PurchaseMakePurchaseInput
+	}
+
+	type MockBehaviour func(m *repomocks.MockPurchase, um *repomocks.MockUser, args args)
+
+	testCases := []struct {
+		name          string
+		args          args
+		mockBehaviour MockBehaviour
+		want          int
+		wantErr       error
+	}{
+		{
+			name: "OK",
+			args: args{
+				ctx: context.Background(),
+				input: types.PurchaseMakePurchaseInput{
+					UserID: 1,
+					Items:  []types.PurchaseItemInput{{ProductID: 1, Quantity: 2}},
+				},
+			},
+			mockBehaviour: func(m *repomocks.MockPurchase, um *repomocks.MockUser, args args) {
+				um.EXPECT().GetUserProfile(args.ctx, 1).Return(entity.User{ID: 1, EmailVerified: true}, nil)
+				m.EXPECT().CreateOrder(args.ctx, entity.Order{
+					UserID: 1,
+					Items:  []entity.OrderItem{{ProductID: 1, Quantity: 2}},
+				}).Return(1, nil)
+			},
+			want:    1,
+			wantErr: nil,
+		},
+		{
+			name: "Lines of the same product are merged",
+			args: args{
+				ctx: context.Background(),
+				input: types.PurchaseMakePurchaseInput{
+					UserID: 1,
+					Items: []types.PurchaseItemInput{
+						{ProductID: 2, Quantity: 1},
+						{ProductID: 1, Quantity: 1},
+						{ProductID: 2, Quantity: 3},
+					},
+				},
+			},
+			mockBehaviour: func(m *repomocks.MockPurchase, um *repomocks.MockUser, args args) {
+				um.EXPECT().GetUserProfile(args.ctx, 1).Return(entity.User{ID: 1, EmailVerified: true}, nil)
+				m.EXPECT().CreateOrder(args.ctx, entity.Order{
+					UserID: 1,
+					Items: []entity.OrderItem{
+						{ProductID: 2, Quantity: 4},
+						{ProductID: 1, Quantity: 1},
+					},
+				}).Return(5, nil)
+			},
+			want:    5,
+			wantErr: nil,
+		},
+		{
+			name: "Empty order",
+			args: args{
+				ctx:   context.Background(),
+				input: types.PurchaseMakePurchaseInput{UserID: 1},
+			},
+			mockBehaviour: func(m *repomocks.MockPurchase, um *repomocks.MockUser, args args) {},
+			want:          0,
+			wantErr:       serviceerrs.ErrEmptyOrder,
+		},
+		{
+			name: "Invalid quantity",
+			args: args{
+				ctx: context.Background(),
+				input: types.PurchaseMakePurchaseInput{
+					UserID: 1,
+					Items:  []types.PurchaseItemInput{{ProductID: 1, Quantity: 1}, {ProductID: 2, Quantity: 0}},
+				},
+			},
+			mockBehaviour: func(m *repomocks.MockPurchase, um *repomocks.MockUser, args args) {},
+			want:          0,
+			wantErr:       serviceerrs.ErrInvalidQuantity,
+		},
+		{
+			name: "Product not found",
+			args: args{
+				ctx: context.Background(),
+				input: types.PurchaseMakePurchaseInput{
+					UserID: 1,
+					Items:  []types.PurchaseItemInput{{ProductID: 42, Quantity: 1}},
+				},
+			},
+			mockBehaviour: func(m *repomocks.MockPurchase, um *repomocks.MockUser, args args) {
+				um.EXPECT().GetUserProfile(args.ctx, 1).Return(entity.User{ID: 1, EmailVerified: true}, nil)
+				m.EXPECT().CreateOrder(args.ctx, gomock.Any()).Return(0, repoerrs.ErrNotFound)
+			},
+			want:    0,
+			wantErr: serviceerrs.ErrProductNotFound,
+		},
+		{
+			name: "Not enough stock",
+			args: args{
+				ctx: context.Background(),
+				input: types.PurchaseMakePurchaseInput{
+					UserID: 1,
+					Items:  []types.PurchaseItemInput{{ProductID: 1, Quantity: 100}},
+				},
+			},
+			mockBehaviour: func(m *repomocks.MockPurchase, um *repomocks.MockUser, args args) {
+				um.EXPECT().GetUserProfile(args.ctx, 1).Return(entity.User{ID: 1, EmailVerified: true}, nil)
+				m.EXPECT().CreateOrder(args.ctx, gomock.Any()).Return(0, repoerrs.ErrNotEnoughStock)
+			},
+			want:    0,
+			wantErr: serviceerrs.ErrNotEnoughStock,
+		},
+		{
+			name: "Not enough balance",
+			args: args{
+				ctx: context.Background(),
+				input: types.PurchaseMakePurchaseInput{
+					UserID: 1,
+					Items:  []types.PurchaseItemInput{{ProductID: 1, Quantity: 1}},
+				},
+			},
+			mockBehaviour: func(m *repomocks.MockPurchase, um *repomocks.MockUser, args args) {
+				um.EXPECT().GetUserProfile(args.ctx, 1).Return(entity.User{ID: 1, EmailVerified: true}, nil)
+				m.EXPECT().CreateOrder(args.ctx, gomock.Any()).Return(0, repoerrs.ErrNotEnoughBalance)
+			},
+			want:    0,
+			wantErr: serviceerrs.ErrNotEnoughBalance,
+		},
+		{
+			name: "Email not verified",
+			args: args{
+				ctx: context.Background(),
+				input: types.PurchaseMakePurchaseInput{
+					UserID: 1,
+					Items:  []types.PurchaseItemInput{{ProductID: 1, Quantity: 1}},
+				},
+			},
+			mockBehaviour: func(m *repomocks.MockPurchase, um *repomocks.MockUser, args args) {
+				um.EXPECT().GetUserProfile(args.ctx, 1).Return(entity.User{ID: 1}, nil)
+			},
+			want:    0,
+			wantErr: serviceerrs.ErrEmailNotVerified,
+		},
+		{
+			name: "Buyer not found",
+			args: args{
+				ctx: context.Background(),
+				input: types.PurchaseMakePurchaseInput{
+					UserID: 42,
+					Items:  []types.PurchaseItemInput{{ProductID: 1, Quantity: 1}},
+				},
+			},
+			mockBehaviour: func(m *repomocks.MockPurchase, um *repomocks.MockUser, args args) {
+				um.EXPECT().GetUserProfile(args.ctx, 42).Return(entity.User{}, repoerrs.ErrNotFound)
+			},
+			want:    0,
+			wantErr: serviceerrs.ErrUserNotFound,
+		},
+		{
+			name: "Cannot create purchase",
+			args: args{
+				ctx: context.Background(),
+				input: types.PurchaseMakePurchaseInput{
+					UserID: 1,
+					Items:  []types.PurchaseItemInput{{ProductID: 1, Quantity: 1}},
+				},
+			},
+			mockBehaviour: func(m *repomocks.MockPurchase, um *repomocks.MockUser, args args) {
+				um.EXPECT().GetUserProfile(args.ctx, 1).Return(entity.User{ID: 1, EmailVerified: true}, nil)
+				m.EXPECT().CreateOrder(args.ctx, gomock.Any()).Return(0, errors.New("unexpected error"))
+			},
+			want:    0,
+			wantErr: serviceerrs.ErrCannotCreatePurchase,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			purchaseRepo := repomocks.NewMockPurchase(ctrl)
+			userRepo := repomocks.NewMockUser(ctrl)
+			tc.mockBehaviour(purchaseRepo, userRepo, tc.args)
+
+			s := NewPurchaseService(purchaseRepo, userRepo)
+			got, err := s.MakePurchase(tc.args.ctx, tc.args.input)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("MakePurchase() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+
+			if got != tc.want {
+				t.Errorf("MakePurchase() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
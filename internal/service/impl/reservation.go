@@ -0,0 +1,201 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+// reservationSweepBatch - сколько истекших броней снимается за одну
+// транзакцию.
+const reservationSweepBatch = 100
+
+type ReservationService struct {
+	reservationRepo repository.Reservation
+	userRepo        repository.User
+	ttl             time.Duration
+	now             func() time.Time
+}
+
+func NewReservationService(reservationRepo repository.Reservation, userRepo repository.User, ttl time.Duration) *ReservationService {
+	return &ReservationService{
+		reservationRepo: reservationRepo,
+		userRepo:        userRepo,
+		ttl:             ttl,
+		now:             time.Now,
+	}
+}
+
+// Reserve бронирует товары на время ttl. Строки с одним и тем же товаром
+// объединяются. Бронь проходит целиком или не проходит вовсе.
+func (s *ReservationService) Reserve(ctx context.Context, input types.ReservationCreateInput) (entity.Reservation, error) {
+	if len(input.Items) == 0 {
+		return entity.Reservation{}, serviceerrs.ErrEmptyOrder
+	}
+
+	reservation := entity.Reservation{
+		UserID:    input.UserID,
+		ExpiresAt: s.now().Add(s.ttl),
+	}
+	lines := make(map[int]int)
+	for _, item := range input.Items {
+		if item.Quantity <= 0 {
+			return entity.Reservation{}, serviceerrs.ErrInvalidQuantity
+		}
+		if i, ok := lines[item.ProductID]; ok {
+			reservation.Items[i].Quantity += item.Quantity
+			continue
+		}
+		lines[item.ProductID] = len(reservation.Items)
+		reservation.Items = append(reservation.Items, entity.ReservationItem{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	buyer, err := s.userRepo.GetUserProfile(ctx, input.UserID)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return entity.Reservation{}, serviceerrs.ErrUserNotFound
+		}
+		log.Errorf("ReservationService.Reserve - s.userRepo.GetUserProfile: %v", err)
+		return entity.Reservation{}, serviceerrs.ErrCannotGetUser
+	}
+
+	if !buyer.EmailVerified {
+		return entity.Reservation{}, serviceerrs.ErrEmailNotVerified
+	}
+
+	reservation, err = s.reservationRepo.CreateReservation(ctx, reservation)
+	if err != nil {
+		switch {
+		case errors.Is(err, repoerrs.ErrNotFound):
+			return entity.Reservation{}, serviceerrs.ErrProductNotFound
+		case errors.Is(err, repoerrs.ErrNotEnoughStock):
+			return entity.Reservation{}, serviceerrs.ErrNotEnoughStock
+		}
+		log.Errorf("ReservationService.Reserve - s.reservationRepo.CreateReservation: %v", err)
+		return entity.Reservation{}, serviceerrs.ErrCannotReserve
+	}
+
+	return reservation, nil
+}
+
+// GetReservation возвращает бронь ее владельцу. Чужая бронь не видна.
+func (s *ReservationService) GetReservation(ctx context.Context, input types.ReservationInput) (entity.Reservation, error) {
+	return s.getOwnReservation(ctx, input)
+}
+
+// Confirm оплачивает бронь по текущим ценам и возвращает id заказа. Если
+// оплатить не удалось, бронь остается активной до истечения.
+func (s *ReservationService) Confirm(ctx context.Context, input types.ReservationConfirmInput) (int, error) {
+	reservation, err := s.getOwnReservation(ctx, types.ReservationInput{
+		ReservationID: input.ReservationID,
+		UserID:        input.UserID,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err = s.checkActive(reservation); err != nil {
+		return 0, err
+	}
+
+	orderId, err := s.reservationRepo.ConfirmReservation(ctx, reservation.ID, input.ShipTo, s.now())
+	if err != nil {
+		switch {
+		case errors.Is(err, repoerrs.ErrConflict):
+			// Бронь успели снять или она истекла между проверкой и оплатой.
+			return 0, serviceerrs.ErrReservationNotActive
+		case errors.Is(err, repoerrs.ErrNotFound):
+			return 0, serviceerrs.ErrProductNotFound
+		case errors.Is(err, repoerrs.ErrNotEnoughStock):
+			return 0, serviceerrs.ErrNotEnoughStock
+		case errors.Is(err, repoerrs.ErrNotEnoughBalance):
+			return 0, serviceerrs.ErrNotEnoughBalance
+		}
+		log.Errorf("ReservationService.Confirm - s.reservationRepo.ConfirmReservation: %v", err)
+		return 0, serviceerrs.ErrCannotConfirmReservation
+	}
+
+	return orderId, nil
+}
+
+// Release снимает бронь по просьбе покупателя.
+func (s *ReservationService) Release(ctx context.Context, input types.ReservationInput) error {
+	reservation, err := s.getOwnReservation(ctx, input)
+	if err != nil {
+		return err
+	}
+	if reservation.Status != entity.ReservationStatusActive {
+		return serviceerrs.ErrReservationNotActive
+	}
+
+	if err = s.reservationRepo.ReleaseReservation(ctx, reservation.ID); err != nil {
+		if errors.Is(err, repoerrs.ErrConflict) {
+			return serviceerrs.ErrReservationNotActive
+		}
+		log.Errorf("ReservationService.Release - s.reservationRepo.ReleaseReservation: %v", err)
+		return serviceerrs.ErrCannotReleaseReservation
+	}
+
+	return nil
+}
+
+// ReleaseExpired снимает все истекшие брони порциями по
+// reservationSweepBatch и возвращает их число.
+func (s *ReservationService) ReleaseExpired(ctx context.Context) (int, error) {
+	now := s.now()
+
+	var total int
+	for {
+		released, err := s.reservationRepo.ReleaseExpiredReservations(ctx, now, reservationSweepBatch)
+		if err != nil {
+			log.Errorf("ReservationService.ReleaseExpired - s.reservationRepo.ReleaseExpiredReservations: %v", err)
+			return total, serviceerrs.ErrCannotReleaseReservation
+		}
+		total += released
+
+		if released < reservationSweepBatch || ctx.Err() != nil {
+			return total, nil
+		}
+	}
+}
+
+func (s *ReservationService) getOwnReservation(ctx context.Context, input types.ReservationInput) (entity.Reservation, error) {
+	reservation, err := s.reservationRepo.GetReservation(ctx, input.ReservationID)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return entity.Reservation{}, serviceerrs.ErrReservationNotFound
+		}
+		log.Errorf("ReservationService.getOwnReservation - s.reservationRepo.GetReservation: %v", err)
+		return entity.Reservation{}, serviceerrs.ErrCannotGetReservation
+	}
+
+	if reservation.UserID != input.UserID {
+		return entity.Reservation{}, serviceerrs.ErrReservationNotFound
+	}
+
+	return reservation, nil
+}
+
+// checkActive проверяет, что бронь еще можно оплатить. Истекшая, но еще не
+// снятая чисткой бронь считается истекшей.
+func (s *ReservationService) checkActive(reservation entity.Reservation) error {
+	switch {
+	case reservation.Status == entity.ReservationStatusExpired:
+		return serviceerrs.ErrReservationExpired
+	case reservation.Status != entity.ReservationStatusActive:
+		return serviceerrs.ErrReservationNotActive
+	case !reservation.ExpiresAt.After(s.now()):
+		return serviceerrs.ErrReservationExpired
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/mocks/repomocks"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+func TestReservationService_Reserve(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	reservationRepo := repomocks.NewMockReservation(ctrl)
+	userRepo := repomocks.NewMockUser(ctrl)
+
+	s := NewReservationService(reservationRepo, userRepo, 15*time.Minute)
+	s.now = func() time.Time { return now }
+
+	want := entity.Reservation{
+		UserID:    1,
+		Items:     []entity.ReservationItem{{ProductID: 2, Quantity: 3}, {ProductID: 5, Quantity: 1}},
+		ExpiresAt: now.Add(15 * time.Minute),
+	}
+	userRepo.EXPECT().GetUserProfile(ctx, 1).Return(entity.User{ID: 1, EmailVerified: true}, nil)
+	reservationRepo.EXPECT().CreateReservation(ctx, want).Return(entity.Reservation{ID: 7}, nil)
+
+	got, err := s.Reserve(ctx, types.ReservationCreateInput{
+		UserID: 1,
+		Items:  []types.PurchaseItemInput{{ProductID: 2, Quantity: 1}, {ProductID: 5, Quantity: 1}, {ProductID: 2, Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if got.ID != 7 {
+		t.Errorf("Reserve() = %+v, want ID 7", got)
+	}
+
+	if _, err = s.Reserve(ctx, types.ReservationCreateInput{UserID: 1}); !errors.Is(err, serviceerrs.ErrEmptyOrder) {
+		t.Errorf("Reserve() empty error = %v, want %v", err, serviceerrs.ErrEmptyOrder)
+	}
+
+	userRepo.EXPECT().GetUserProfile(ctx, 1).Return(entity.User{ID: 1, EmailVerified: true}, nil)
+	reservationRepo.EXPECT().CreateReservation(ctx, gomock.Any()).Return(entity.Reservation{}, repoerrs.ErrNotEnoughStock)
+	_, err = s.Reserve(ctx, types.ReservationCreateInput{
+		UserID: 1,
+		Items:  []types.PurchaseItemInput{{ProductID: 2, Quantity: 100}},
+	})
+	if !errors.Is(err, serviceerrs.ErrNotEnoughStock) {
+		t.Errorf("Reserve() error = %v, want %v", err, serviceerrs.ErrNotEnoughStock)
+	}
+}
+
+func TestReservationService_Confirm(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	active := entity.Reservation{ID: 7, UserID: 1, Status: entity.ReservationStatusActive, ExpiresAt: now.Add(time.Minute)}
+
+	type MockBehaviour func(m *repomocks.MockReservation)
+
+	testCases := []struct {
+		name          string
+		input         types.ReservationConfirmInput
+		mockBehaviour MockBehaviour
+		want          int
+		wantErr       error
+	}{
+		{
+			name:  "OK",
+			input: types.ReservationConfirmInput{ReservationID: 7, UserID: 1},
+			mockBehaviour: func(m *repomocks.MockReservation) {
+				m.EXPECT().GetReservation(gomock.Any(), 7).Return(active, nil)
+				m.EXPECT().ConfirmReservation(gomock.Any(), 7, nil, now).Return(11, nil)
+			},
+			want:    11,
+			wantErr: nil,
+		},
+		{
+			name:  "OK with destination",
+			input: types.ReservationConfirmInput{ReservationID: 7, UserID: 1, ShipTo: &entity.GeoPoint{Latitude: 55.75, Longitude: 37.62}},
+			mockBehaviour: func(m *repomocks.MockReservation) {
+				m.EXPECT().GetReservation(gomock.Any(), 7).Return(active, nil)
+				m.EXPECT().ConfirmReservation(gomock.Any(), 7, &entity.GeoPoint{Latitude: 55.75, Longitude: 37.62}, now).Return(11, nil)
+			},
+			want:    11,
+			wantErr: nil,
+		},
+		{
+			name:  "Another user",
+			input: types.ReservationConfirmInput{ReservationID: 7, UserID: 2},
+			mockBehaviour: func(m *repomocks.MockReservation) {
+				m.EXPECT().GetReservation(gomock.Any(), 7).Return(active, nil)
+			},
+			wantErr: serviceerrs.ErrReservationNotFound,
+		},
+		{
+			name:  "Expired but not swept yet",
+			input: types.ReservationConfirmInput{ReservationID: 7, UserID: 1},
+			mockBehaviour: func(m *repomocks.MockReservation) {
+				expired := active
+				expired.ExpiresAt = now
+				m.EXPECT().GetReservation(gomock.Any(), 7).Return(expired, nil)
+			},
+			wantErr: serviceerrs.ErrReservationExpired,
+		},
+		{
+			name:  "Already confirmed",
+			input: types.ReservationConfirmInput{ReservationID: 7, UserID: 1},
+			mockBehaviour: func(m *repomocks.MockReservation) {
+				confirmed := active
+				confirmed.Status = entity.ReservationStatusConfirmed
+				m.EXPECT().GetReservation(gomock.Any(), 7).Return(confirmed, nil)
+			},
+			wantErr: serviceerrs.ErrReservationNotActive,
+		},
+		{
+			name:  "Released concurrently",
+			input: types.ReservationConfirmInput{ReservationID: 7, UserID: 1},
+			mockBehaviour: func(m *repomocks.MockReservation) {
+				m.EXPECT().GetReservation(gomock.Any(), 7).Return(active, nil)
+				m.EXPECT().ConfirmReservation(gomock.Any(), 7, nil, now).Return(0, repoerrs.ErrConflict)
+			},
+			wantErr: serviceerrs.ErrReservationNotActive,
+		},
+		{
+			name:  "Not enough balance",
+			input: types.ReservationConfirmInput{ReservationID: 7, UserID: 1},
+			mockBehaviour: func(m *repomocks.MockReservation) {
+				m.EXPECT().GetReservation(gomock.Any(), 7).Return(active, nil)
+				m.EXPECT().ConfirmReservation(gomock.Any(), 7, nil, now).Return(0, repoerrs.ErrNotEnoughBalance)
+			},
+			wantErr: serviceerrs.ErrNotEnoughBalance,
+		},
+		{
+			name:  "Not found",
+			input: types.ReservationConfirmInput{ReservationID: 42, UserID: 1},
+			mockBehaviour: func(m *repomocks.MockReservation) {
+				m.EXPECT().GetReservation(gomock.Any(), 42).Return(entity.Reservation{}, repoerrs.ErrNotFound)
+			},
+			wantErr: serviceerrs.ErrReservationNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			reservationRepo := repomocks.NewMockReservation(ctrl)
+			tc.mockBehaviour(reservationRepo)
+
+			s := NewReservationService(reservationRepo, repomocks.NewMockUser(ctrl), time.Minute)
+			s.now = func() time.Time { return now }
+
+			got, err := s.Confirm(context.Background(), tc.input)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Confirm() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Confirm() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestReservationService_ReleaseExpired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	reservationRepo := repomocks.NewMockReservation(ctrl)
+
+	s := NewReservationService(reservationRepo, repomocks.NewMockUser(ctrl), time.Minute)
+	s.now = func() time.Time { return now }
+
+	// Полная порция означает, что истекших броней может быть больше.
+	gomock.InOrder(
+		reservationRepo.EXPECT().ReleaseExpiredReservations(ctx, now, reservationSweepBatch).Return(reservationSweepBatch, nil),
+		reservationRepo.EXPECT().ReleaseExpiredReservations(ctx, now, reservationSweepBatch).Return(3, nil),
+	)
+
+	released, err := s.ReleaseExpired(ctx)
+	if err != nil {
+		t.Fatalf("ReleaseExpired() error = %v", err)
+	}
+	if released != reservationSweepBatch+3 {
+		t.Errorf("ReleaseExpired() = %d, want %d", released, reservationSweepBatch+3)
+	}
+
+	reservationRepo.EXPECT().ReleaseExpiredReservations(ctx, now, reservationSweepBatch).Return(0, errors.New("unexpected error"))
+	if _, err = s.ReleaseExpired(ctx); !errors.Is(err, serviceerrs.ErrCannotReleaseReservation) {
+		t.Errorf("ReleaseExpired() error = %v, want %v", err, serviceerrs.ErrCannotReleaseReservation)
+	}
+}
@@ -0,0 +1,89 @@
+package impl
+
+import (
+	"context"
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+// RoleService управляет ролями пользователей. Роли зашиты в токены доступа,
+// поэтому после изменения ролей сессии пользователя отзываются: со старыми
+// правами нельзя работать до истечения токена.
+type RoleService struct {
+	userRepo    repository.User
+	sessionRepo repository.Session
+}
+
+func NewRoleService(userRepo repository.User, sessionRepo repository.Session) *RoleService {
+	return &RoleService{
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+	}
+}
+
+func (s *RoleService) GetUserRoles(ctx context.Context, userId int) ([]entity.Role, error) {
+	if _, err := s.userRepo.GetUserProfile(ctx, userId); err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return nil, serviceerrs.ErrUserNotFound
+		}
+		log.Errorf("RoleService.GetUserRoles - s.userRepo.GetUserProfile: %v", err)
+		return nil, serviceerrs.ErrCannotGetRoles
+	}
+
+	roles, err := s.userRepo.GetUserRoles(ctx, userId)
+	if err != nil {
+		log.Errorf("RoleService.GetUserRoles - s.userRepo.GetUserRoles: %v", err)
+		return nil, serviceerrs.ErrCannotGetRoles
+	}
+
+	return roles, nil
+}
+
+func (s *RoleService) GrantRole(ctx context.Context, input types.RoleGrantRoleInput) error {
+	if !input.Role.Valid() {
+		return serviceerrs.ErrInvalidRole
+	}
+
+	if err := s.userRepo.GrantRole(ctx, input.UserID, input.Role); err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return serviceerrs.ErrUserNotFound
+		}
+		log.Errorf("RoleService.GrantRole - s.userRepo.GrantRole: %v", err)
+		return serviceerrs.ErrCannotGrantRole
+	}
+
+	if err := s.sessionRepo.RevokeUserSessions(ctx, input.UserID); err != nil {
+		log.Errorf("RoleService.GrantRole - s.sessionRepo.RevokeUserSessions: %v", err)
+		return serviceerrs.ErrCannotRevokeSession
+	}
+
+	return nil
+}
+
+func (s *RoleService) RevokeRole(ctx context.Context, input types.RoleRevokeRoleInput) error {
+	if !input.Role.Valid() {
+		return serviceerrs.ErrInvalidRole
+	}
+
+	if err := s.userRepo.RevokeRole(ctx, input.UserID, input.Role); err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return serviceerrs.ErrRoleNotAssigned
+		}
+		log.Errorf("RoleService.RevokeRole - s.userRepo.RevokeRole: %v", err)
+		return serviceerrs.ErrCannotRevokeRole
+	}
+
+	if err := s.sessionRepo.RevokeUserSessions(ctx, input.UserID); err != nil {
+		log.Errorf("RoleService.RevokeRole - s.sessionRepo.RevokeUserSessions: %v", err)
+		return serviceerrs.ErrCannotRevokeSession
+	}
+
+	return nil
+}
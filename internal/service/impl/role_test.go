@@ -0,0 +1,165 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/mocks/repomocks"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+func TestRoleService_GrantRole(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		input types.RoleGrantRoleInput
+	}
+
+	type MockBehaviour func(m *repomocks.MockUser, sm *repomocks.MockSession, args args)
+
+	testCases := []struct {
+		name          string
+		args          args
+		mockBehaviour MockBehaviour
+		wantErr       error
+	}{
+		{
+			name: "OK",
+			args: args{
+				ctx:   context.Background(),
+				input: types.RoleGrantRoleInput{UserID: 1, Role: entity.RoleSeller},
+			},
+			mockBehaviour: func(m *repomocks.MockUser, sm *repomocks.MockSession, args args) {
+				m.EXPECT().GrantRole(args.ctx, 1, entity.RoleSeller).Return(nil)
+				// Токены со старыми ролями перестают приниматься.
+				sm.EXPECT().RevokeUserSessions(args.ctx, 1).Return(nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Invalid role",
+			args: args{
+				ctx:   context.Background(),
+				input: types.RoleGrantRoleInput{UserID: 1, Role: "superuser"},
+			},
+			mockBehaviour: func(m *repomocks.MockUser, sm *repomocks.MockSession, args args) {},
+			wantErr:       serviceerrs.ErrInvalidRole,
+		},
+		{
+			name: "User not found",
+			args: args{
+				ctx:   context.Background(),
+				input: types.RoleGrantRoleInput{UserID: 42, Role: entity.RoleAdmin},
+			},
+			mockBehaviour: func(m *repomocks.MockUser, sm *repomocks.MockSession, args args) {
+				m.EXPECT().GrantRole(args.ctx, 42, entity.RoleAdmin).Return(repoerrs.ErrNotFound)
+			},
+			wantErr: serviceerrs.ErrUserNotFound,
+		},
+		{
+			name: "Cannot grant role",
+			args: args{
+				ctx:   context.Background(),
+				input: types.RoleGrantRoleInput{UserID: 1, Role: entity.RoleAdmin},
+			},
+			mockBehaviour: func(m *repomocks.MockUser, sm *repomocks.MockSession, args args) {
+				m.EXPECT().GrantRole(args.ctx, 1, entity.RoleAdmin).Return(errors.New("unexpected error"))
+			},
+			wantErr: serviceerrs.ErrCannotGrantRole,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepo := repomocks.NewMockUser(ctrl)
+			sessionRepo := repomocks.NewMockSession(ctrl)
+			tc.mockBehaviour(userRepo, sessionRepo, tc.args)
+
+			s := NewRoleService(userRepo, sessionRepo)
+			err := s.GrantRole(tc.args.ctx, tc.args.input)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("GrantRole() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestRoleService_RevokeRole(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		input types.RoleRevokeRoleInput
+	}
+
+	type MockBehaviour func(m *repomocks.MockUser, sm *repomocks.MockSession, args args)
+
+	testCases := []struct {
+		name          string
+		args          args
+		mockBehaviour MockBehaviour
+		wantErr       error
+	}{
+		{
+			name: "OK",
+			args: args{
+				ctx:   context.Background(),
+				input: types.RoleRevokeRoleInput{UserID: 1, Role: entity.RoleSeller},
+			},
+			mockBehaviour: func(m *repomocks.MockUser, sm *repomocks.MockSession, args args) {
+				m.EXPECT().RevokeRole(args.ctx, 1, entity.RoleSeller).Return(nil)
+				// Токены со старыми ролями перестают приниматься.
+				sm.EXPECT().RevokeUserSessions(args.ctx, 1).Return(nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Role not assigned",
+			args: args{
+				ctx:   context.Background(),
+				input: types.RoleRevokeRoleInput{UserID: 1, Role: entity.RoleAdmin},
+			},
+			mockBehaviour: func(m *repomocks.MockUser, sm *repomocks.MockSession, args args) {
+				m.EXPECT().RevokeRole(args.ctx, 1, entity.RoleAdmin).Return(repoerrs.ErrNotFound)
+			},
+			wantErr: serviceerrs.ErrRoleNotAssigned,
+		},
+		{
+			name: "Cannot revoke sessions",
+			args: args{
+				ctx:   context.Background(),
+				input: types.RoleRevokeRoleInput{UserID: 1, Role: entity.RoleAdmin},
+			},
+			mockBehaviour: func(m *repomocks.MockUser, sm *repomocks.MockSession, args args) {
+				m.EXPECT().RevokeRole(args.ctx, 1, entity.RoleAdmin).Return(nil)
+				sm.EXPECT().RevokeUserSessions(args.ctx, 1).Return(errors.New("unexpected error"))
+			},
+			wantErr: serviceerrs.ErrCannotRevokeSession,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepo := repomocks.NewMockUser(ctrl)
+			sessionRepo := repomocks.NewMockSession(ctrl)
+			tc.mockBehaviour(userRepo, sessionRepo, tc.args)
+
+			s := NewRoleService(userRepo, sessionRepo)
+			err := s.RevokeRole(tc.args.ctx, tc.args.input)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("RevokeRole() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package impl
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+)
+
+type SellerService struct {
+	productRepo  repository.Product
+	purchaseRepo repository.Purchase
+}
+
+func NewSellerService(productRepo repository.Product, purchaseRepo repository.Purchase) *SellerService {
+	return &SellerService{
+		productRepo:  productRepo,
+		purchaseRepo: purchaseRepo,
+	}
+}
+
+func (s *SellerService) GetProducts(ctx context.Context, sellerId int) ([]entity.Product, error) {
+	products, err := s.productRepo.GetProductsBySeller(ctx, sellerId)
+	if err != nil {
+		log.Errorf("SellerService.GetProducts - s.productRepo.GetProductsBySeller: %v", err)
+		return nil, serviceerrs.ErrCannotGetProducts
+	}
+
+	return products, nil
+}
+
+func (s *SellerService) GetSalesReport(ctx context.Context, sellerId int) (entity.SellerSalesReport, error) {
+	sales, err := s.purchaseRepo.GetSellerSales(ctx, sellerId)
+	if err != nil {
+		log.Errorf("SellerService.GetSalesReport - s.purchaseRepo.GetSellerSales: %v", err)
+		return entity.SellerSalesReport{}, serviceerrs.ErrCannotGetSales
+	}
+
+	report := entity.SellerSalesReport{
+		Products: sales,
+	}
+	for _, ps := range sales {
+		report.UnitsSold += ps.UnitsSold
+		report.Revenue += ps.Revenue
+	}
+
+	return report, nil
+}
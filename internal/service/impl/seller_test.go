@@ -0,0 +1,79 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/mocks/repomocks"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+)
+
+func TestSellerService_GetSalesReport(t *testing.T) {
+	type MockBehaviour func(m *repomocks.MockPurchase)
+
+	testCases := []struct {
+		name          string
+		sellerId      int
+		mockBehaviour MockBehaviour
+		want          entity.SellerSalesReport
+		wantErr       error
+	}{
+		{
+			name:     "OK",
+			sellerId: 5,
+			mockBehaviour: func(m *repomocks.MockPurchase) {
+				m.EXPECT().GetSellerSales(gomock.Any(), 5).Return([]entity.ProductSales{
+					{ProductID: 1, ProductName: "a", UnitsSold: 3, Revenue: 30},
+					{ProductID: 2, ProductName: "b", UnitsSold: 0, Revenue: 0},
+					{ProductID: 3, ProductName: "c", UnitsSold: 2, Revenue: 15.5},
+				}, nil)
+			},
+			want: entity.SellerSalesReport{
+				Products: []entity.ProductSales{
+					{ProductID: 1, ProductName: "a", UnitsSold: 3, Revenue: 30},
+					{ProductID: 2, ProductName: "b", UnitsSold: 0, Revenue: 0},
+					{ProductID: 3, ProductName: "c", UnitsSold: 2, Revenue: 15.5},
+				},
+				UnitsSold: 5,
+				Revenue:   45.5,
+			},
+			wantErr: nil,
+		},
+		{
+			name:     "Cannot get sales",
+			sellerId: 5,
+			mockBehaviour: func(m *repomocks.MockPurchase) {
+				m.EXPECT().GetSellerSales(gomock.Any(), 5).Return(nil, errors.New("unexpected error"))
+			},
+			want:    entity.SellerSalesReport{},
+			wantErr: serviceerrs.ErrCannotGetSales,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			purchaseRepo := repomocks.NewMockPurchase(ctrl)
+			tc.mockBehaviour(purchaseRepo)
+
+			s := NewSellerService(repomocks.NewMockProduct(ctrl), purchaseRepo)
+			got, err := s.GetSalesReport(context.Background(), tc.sellerId)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("GetSalesReport() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("GetSalesReport() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
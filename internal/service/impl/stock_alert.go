@@ -0,0 +1,95 @@
+package impl
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cripplemymind9/go-market/internal/notifier"
+	"github.com/cripplemymind9/go-market/internal/repository"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+)
+
+const (
+	// stockAlertDispatchBatch - сколько предупреждений забирается на отправку
+	// за один раз.
+	stockAlertDispatchBatch = 50
+	// stockAlertClaimTimeout - на сколько забранные предупреждения скрываются
+	// от других отправителей. Если отправитель не отчитался за это время,
+	// например упал, предупреждения отправляются снова.
+	stockAlertClaimTimeout = 10 * time.Minute
+	// stockAlertMaxRetryDelay ограничивает паузу между попытками доставки.
+	stockAlertMaxRetryDelay = time.Hour
+)
+
+type StockAlertService struct {
+	stockAlertRepo repository.StockAlert
+	notifiers      notifier.Multi
+	now            func() time.Time
+}
+
+func NewStockAlertService(stockAlertRepo repository.StockAlert, notifiers notifier.Multi) *StockAlertService {
+	return &StockAlertService{
+		stockAlertRepo: stockAlertRepo,
+		notifiers:      notifiers,
+		now:            time.Now,
+	}
+}
+
+// DispatchAlerts отправляет предупреждения о низком остатке, чья очередь
+// подошла, и возвращает число доставленных. Предупреждение отмечается
+// доставленным только после успешной отправки всеми способами; при ошибке
+// следующая попытка откладывается тем дольше, чем больше было неудачных, так
+// что предупреждение не теряется из-за сбоя получателя или остановки сервиса.
+// Повторная попытка идет только теми способами, которыми доставить не удалось.
+func (s *StockAlertService) DispatchAlerts(ctx context.Context) (int, error) {
+	var total int
+	for {
+		now := s.now()
+		alerts, err := s.stockAlertRepo.ClaimStockAlerts(ctx, now, now.Add(stockAlertClaimTimeout), stockAlertDispatchBatch)
+		if err != nil {
+			log.Errorf("StockAlertService.DispatchAlerts - s.stockAlertRepo.ClaimStockAlerts: %v", err)
+			return total, serviceerrs.ErrCannotDispatchStockAlerts
+		}
+
+		// Итог отправки записывается и при остановке сервиса: иначе
+		// предупреждение ждало бы окончания stockAlertClaimTimeout.
+		saveCtx := context.WithoutCancel(ctx)
+		for _, alert := range alerts {
+			delivered, err := s.notifiers.Deliver(ctx, alert)
+			if err != nil {
+				retryAt := s.now().Add(stockAlertRetryDelay(alert.Attempts))
+				log.Errorf("StockAlertService.DispatchAlerts - s.notifiers.Deliver: alert %d, attempt %d, retry at %s: %v", alert.ID, alert.Attempts, retryAt.Format(time.RFC3339), err)
+				if err := s.stockAlertRepo.RetryStockAlert(saveCtx, alert.ID, delivered, retryAt); err != nil {
+					log.Errorf("StockAlertService.DispatchAlerts - s.stockAlertRepo.RetryStockAlert: %v", err)
+				}
+				continue
+			}
+
+			if err := s.stockAlertRepo.MarkStockAlertNotified(saveCtx, alert.ID, delivered, s.now()); err != nil {
+				log.Errorf("StockAlertService.DispatchAlerts - s.stockAlertRepo.MarkStockAlertNotified: %v", err)
+				continue
+			}
+			total++
+		}
+
+		if len(alerts) < stockAlertDispatchBatch || ctx.Err() != nil {
+			return total, nil
+		}
+	}
+}
+
+// stockAlertRetryDelay возвращает паузу перед следующей попыткой после
+// attempts неудачных: минута, две, четыре и так далее до
+// stockAlertMaxRetryDelay.
+func stockAlertRetryDelay(attempts int) time.Duration {
+	delay := time.Minute
+	for i := 1; i < attempts && delay < stockAlertMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > stockAlertMaxRetryDelay {
+		return stockAlertMaxRetryDelay
+	}
+	return delay
+}
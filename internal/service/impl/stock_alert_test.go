@@ -0,0 +1,133 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/mocks/repomocks"
+	"github.com/cripplemymind9/go-market/internal/notifier"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+)
+
+type recordingNotifier struct {
+	alerts []entity.StockAlert
+	err    error
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, alert entity.StockAlert) error {
+	n.alerts = append(n.alerts, alert)
+	return n.err
+}
+
+func TestStockAlertService_DispatchAlerts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	full := make([]entity.StockAlert, stockAlertDispatchBatch)
+	for i := range full {
+		full[i] = entity.StockAlert{ID: i + 1, ProductID: 1, Attempts: 1}
+	}
+
+	stockAlertRepo := repomocks.NewMockStockAlert(ctrl)
+	gomock.InOrder(
+		stockAlertRepo.EXPECT().ClaimStockAlerts(ctx, now, now.Add(stockAlertClaimTimeout), stockAlertDispatchBatch).Return(full, nil),
+		stockAlertRepo.EXPECT().ClaimStockAlerts(ctx, now, now.Add(stockAlertClaimTimeout), stockAlertDispatchBatch).Return([]entity.StockAlert{{ID: 51, ProductID: 2, Attempts: 1}}, nil),
+	)
+	stockAlertRepo.EXPECT().MarkStockAlertNotified(gomock.Any(), gomock.Any(), []string{"test"}, now).Return(nil).Times(stockAlertDispatchBatch + 1)
+
+	n := &recordingNotifier{}
+	s := NewStockAlertService(stockAlertRepo, notifier.Multi{{Name: "test", Notifier: n}})
+	s.now = func() time.Time { return now }
+
+	sent, err := s.DispatchAlerts(ctx)
+	if err != nil {
+		t.Fatalf("DispatchAlerts() error = %v", err)
+	}
+	if sent != stockAlertDispatchBatch+1 || len(n.alerts) != stockAlertDispatchBatch+1 {
+		t.Errorf("DispatchAlerts() = %d, notified %d, want %d", sent, len(n.alerts), stockAlertDispatchBatch+1)
+	}
+}
+
+func TestStockAlertService_DispatchAlertsDeliveryFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// Неудачная доставка не отмечает предупреждение отправленным, а
+	// откладывает следующую попытку: третья неудача подряд - на 4 минуты.
+	// Способ, который уже доставил предупреждение, запоминается и при
+	// повторной попытке пропускается.
+	stockAlertRepo := repomocks.NewMockStockAlert(ctrl)
+	gomock.InOrder(
+		stockAlertRepo.EXPECT().ClaimStockAlerts(ctx, now, gomock.Any(), stockAlertDispatchBatch).
+			Return([]entity.StockAlert{{ID: 7, ProductID: 1, Attempts: 3}}, nil),
+		stockAlertRepo.EXPECT().RetryStockAlert(gomock.Any(), 7, []string{"log"}, now.Add(4*time.Minute)).Return(nil),
+		stockAlertRepo.EXPECT().ClaimStockAlerts(ctx, now, gomock.Any(), stockAlertDispatchBatch).
+			Return([]entity.StockAlert{{ID: 7, ProductID: 1, Attempts: 4, DeliveredChannels: []string{"log"}}}, nil),
+		stockAlertRepo.EXPECT().MarkStockAlertNotified(gomock.Any(), 7, []string{"webhook"}, now).Return(nil),
+	)
+
+	logged := &recordingNotifier{}
+	webhook := &recordingNotifier{err: errors.New("webhook is down")}
+	s := NewStockAlertService(stockAlertRepo, notifier.Multi{
+		{Name: "log", Notifier: logged},
+		{Name: "webhook", Notifier: webhook},
+	})
+	s.now = func() time.Time { return now }
+
+	sent, err := s.DispatchAlerts(ctx)
+	if err != nil {
+		t.Fatalf("DispatchAlerts() error = %v", err)
+	}
+	if sent != 0 || len(logged.alerts) != 1 || len(webhook.alerts) != 1 {
+		t.Errorf("DispatchAlerts() = %d, log %d, webhook %d, want 0 delivered of 1", sent, len(logged.alerts), len(webhook.alerts))
+	}
+
+	webhook.err = nil
+	if sent, err = s.DispatchAlerts(ctx); err != nil || sent != 1 {
+		t.Fatalf("DispatchAlerts() retry = %d, %v, want 1 delivered", sent, err)
+	}
+	if len(logged.alerts) != 1 || len(webhook.alerts) != 2 {
+		t.Errorf("log %d, webhook %d, want only the webhook retried", len(logged.alerts), len(webhook.alerts))
+	}
+}
+
+func TestStockAlertService_DispatchAlertsClaimFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	stockAlertRepo := repomocks.NewMockStockAlert(ctrl)
+	stockAlertRepo.EXPECT().ClaimStockAlerts(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("unexpected error"))
+
+	n := &recordingNotifier{}
+	_, err := NewStockAlertService(stockAlertRepo, notifier.Multi{{Name: "test", Notifier: n}}).DispatchAlerts(context.Background())
+	if !errors.Is(err, serviceerrs.ErrCannotDispatchStockAlerts) {
+		t.Errorf("DispatchAlerts() error = %v, wantErr %v", err, serviceerrs.ErrCannotDispatchStockAlerts)
+	}
+	if len(n.alerts) != 0 {
+		t.Errorf("notified %d alerts, want 0", len(n.alerts))
+	}
+}
+
+func TestStockAlertRetryDelay(t *testing.T) {
+	for attempts, want := range map[int]time.Duration{
+		1:  time.Minute,
+		2:  2 * time.Minute,
+		4:  8 * time.Minute,
+		20: stockAlertMaxRetryDelay,
+	} {
+		if got := stockAlertRetryDelay(attempts); got != want {
+			t.Errorf("stockAlertRetryDelay(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
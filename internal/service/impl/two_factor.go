@@ -0,0 +1,225 @@
+package impl
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+	"github.com/cripplemymind9/go-market/pkg/hasher"
+	"github.com/cripplemymind9/go-market/pkg/totp"
+)
+
+const (
+	recoveryCodeCount = 10
+	// totpSkew - на сколько шагов могут расходиться часы сервера и телефона.
+	totpSkew = 1
+)
+
+type TwoFactorService struct {
+	userRepo       repository.User
+	twoFactorRepo  repository.TwoFactor
+	passwordHasher hasher.PasswordHasher
+	issuer         string
+	now            func() time.Time
+}
+
+func NewTwoFactorService(
+	userRepo repository.User,
+	twoFactorRepo repository.TwoFactor,
+	passwordHasher hasher.PasswordHasher,
+	issuer string,
+) *TwoFactorService {
+	return &TwoFactorService{
+		userRepo:       userRepo,
+		twoFactorRepo:  twoFactorRepo,
+		passwordHasher: passwordHasher,
+		issuer:         issuer,
+		now:            time.Now,
+	}
+}
+
+// Enroll начинает подключение второго фактора: выпускает секрет, который
+// вступит в силу после подтверждения кодом. Повторный вызов до
+// подтверждения заменяет секрет.
+func (s *TwoFactorService) Enroll(ctx context.Context, userId int) (types.TwoFactorEnrollment, error) {
+	user, err := s.userRepo.GetUserProfile(ctx, userId)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return types.TwoFactorEnrollment{}, serviceerrs.ErrUserNotFound
+		}
+		log.Errorf("TwoFactorService.Enroll - s.userRepo.GetUserProfile: %v", err)
+		return types.TwoFactorEnrollment{}, serviceerrs.ErrCannotGetUser
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		log.Errorf("TwoFactorService.Enroll - totp.GenerateSecret: %v", err)
+		return types.TwoFactorEnrollment{}, serviceerrs.ErrCannotEnrollTwoFactor
+	}
+
+	if err = s.twoFactorRepo.SaveTOTP(ctx, user.ID, secret); err != nil {
+		if errors.Is(err, repoerrs.ErrAlreadyExists) {
+			return types.TwoFactorEnrollment{}, serviceerrs.ErrTwoFactorAlreadyEnabled
+		}
+		log.Errorf("TwoFactorService.Enroll - s.twoFactorRepo.SaveTOTP: %v", err)
+		return types.TwoFactorEnrollment{}, serviceerrs.ErrCannotEnrollTwoFactor
+	}
+
+	return types.TwoFactorEnrollment{
+		Secret: secret,
+		URI:    totp.URI(s.issuer, user.Username, secret),
+	}, nil
+}
+
+// Confirm включает второй фактор по коду из приложения и возвращает коды
+// восстановления. Они показываются один раз, хранятся только их хэши.
+func (s *TwoFactorService) Confirm(ctx context.Context, input types.TwoFactorConfirmInput) ([]string, error) {
+	state, err := s.twoFactorRepo.GetTOTP(ctx, input.UserID)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return nil, serviceerrs.ErrTwoFactorNotEnrolled
+		}
+		log.Errorf("TwoFactorService.Confirm - s.twoFactorRepo.GetTOTP: %v", err)
+		return nil, serviceerrs.ErrCannotCheckTwoFactor
+	}
+
+	if state.Confirmed {
+		return nil, serviceerrs.ErrTwoFactorAlreadyEnabled
+	}
+
+	step, ok := totp.Validate(state.Secret, strings.TrimSpace(input.Code), s.now(), totpSkew)
+	if !ok {
+		return nil, serviceerrs.ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := s.generateRecoveryCodes()
+	if err != nil {
+		log.Errorf("TwoFactorService.Confirm - s.generateRecoveryCodes: %v", err)
+		return nil, serviceerrs.ErrCannotEnableTwoFactor
+	}
+
+	if err = s.twoFactorRepo.ConfirmTOTP(ctx, input.UserID, step, hashes); err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return nil, serviceerrs.ErrTwoFactorAlreadyEnabled
+		}
+		log.Errorf("TwoFactorService.Confirm - s.twoFactorRepo.ConfirmTOTP: %v", err)
+		return nil, serviceerrs.ErrCannotEnableTwoFactor
+	}
+
+	return codes, nil
+}
+
+// Disable отключает второй фактор. Нужен действующий код из приложения или
+// код восстановления.
+func (s *TwoFactorService) Disable(ctx context.Context, input types.TwoFactorDisableInput) error {
+	state, err := s.twoFactorRepo.GetTOTP(ctx, input.UserID)
+	if err != nil && !errors.Is(err, repoerrs.ErrNotFound) {
+		log.Errorf("TwoFactorService.Disable - s.twoFactorRepo.GetTOTP: %v", err)
+		return serviceerrs.ErrCannotCheckTwoFactor
+	}
+
+	if err != nil || !state.Confirmed {
+		return serviceerrs.ErrTwoFactorNotEnabled
+	}
+
+	if err = verifySecondFactor(ctx, s.twoFactorRepo, s.passwordHasher, state, input.Code, s.now()); err != nil {
+		return err
+	}
+
+	if err = s.twoFactorRepo.DeleteTOTP(ctx, input.UserID); err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return serviceerrs.ErrTwoFactorNotEnabled
+		}
+		log.Errorf("TwoFactorService.Disable - s.twoFactorRepo.DeleteTOTP: %v", err)
+		return serviceerrs.ErrCannotDisableTwoFactor
+	}
+
+	return nil
+}
+
+// generateRecoveryCodes возвращает коды вида "abcde-fghij" и их хэши.
+func (s *TwoFactorService) generateRecoveryCodes() ([]string, []string, error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+
+		code := strings.ToLower(encoding.EncodeToString(b))[:10]
+		hash, err := s.passwordHasher.HashPassword(code)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashes = append(hashes, hash)
+	}
+
+	return codes, hashes, nil
+}
+
+// verifySecondFactor принимает код из приложения или код восстановления.
+// Каждый код срабатывает один раз: шаг TOTP и код восстановления гасятся.
+func verifySecondFactor(
+	ctx context.Context,
+	twoFactorRepo repository.TwoFactor,
+	passwordHasher hasher.PasswordHasher,
+	state entity.UserTOTP,
+	code string,
+	now time.Time,
+) error {
+	code = strings.TrimSpace(code)
+
+	if step, ok := totp.Validate(state.Secret, code, now, totpSkew); ok {
+		if err := twoFactorRepo.UseTOTPStep(ctx, state.UserID, step); err != nil {
+			if errors.Is(err, repoerrs.ErrNotFound) {
+				return serviceerrs.ErrInvalidTwoFactorCode
+			}
+			log.Errorf("verifySecondFactor - twoFactorRepo.UseTOTPStep: %v", err)
+			return serviceerrs.ErrCannotCheckTwoFactor
+		}
+		return nil
+	}
+
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	if len(normalized) != 10 {
+		return serviceerrs.ErrInvalidTwoFactorCode
+	}
+
+	recoveryCodes, err := twoFactorRepo.GetRecoveryCodes(ctx, state.UserID)
+	if err != nil {
+		log.Errorf("verifySecondFactor - twoFactorRepo.GetRecoveryCodes: %v", err)
+		return serviceerrs.ErrCannotCheckTwoFactor
+	}
+
+	for _, recoveryCode := range recoveryCodes {
+		if passwordHasher.VerifyPassword(recoveryCode.CodeHash, normalized) != nil {
+			continue
+		}
+
+		if err = twoFactorRepo.UseRecoveryCode(ctx, recoveryCode.ID); err != nil {
+			if errors.Is(err, repoerrs.ErrNotFound) {
+				return serviceerrs.ErrInvalidTwoFactorCode
+			}
+			log.Errorf("verifySecondFactor - twoFactorRepo.UseRecoveryCode: %v", err)
+			return serviceerrs.ErrCannotCheckTwoFactor
+		}
+		return nil
+	}
+
+	return serviceerrs.ErrInvalidTwoFactorCode
+}
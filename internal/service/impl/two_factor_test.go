@@ -0,0 +1,170 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/mocks/repomocks"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+	"github.com/cripplemymind9/go-market/pkg/hasher"
+	"github.com/cripplemymind9/go-market/pkg/passwordpolicy"
+	"github.com/cripplemymind9/go-market/pkg/totp"
+)
+
+func TestTwoFactorService_EnrollAndConfirm(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	userRepo := repomocks.NewMockUser(ctrl)
+	twoFactorRepo := repomocks.NewMockTwoFactor(ctrl)
+	passwordHasher := hasher.NewBcryptHasher()
+
+	s := NewTwoFactorService(userRepo, twoFactorRepo, passwordHasher, "GoMarket")
+
+	var secret string
+	userRepo.EXPECT().GetUserProfile(ctx, 1).Return(entity.User{ID: 1, Username: "seller"}, nil)
+	twoFactorRepo.EXPECT().SaveTOTP(ctx, 1, gomock.Any()).DoAndReturn(func(_ context.Context, _ int, s string) error {
+		secret = s
+		return nil
+	})
+
+	enrollment, err := s.Enroll(ctx, 1)
+	if err != nil {
+		t.Fatalf("Enroll() error = %v", err)
+	}
+	if enrollment.Secret != secret || !strings.HasPrefix(enrollment.URI, "otpauth://totp/GoMarket:seller?") {
+		t.Fatalf("Enroll() = %+v, saved secret %s", enrollment, secret)
+	}
+
+	state := entity.UserTOTP{UserID: 1, Secret: secret}
+
+	twoFactorRepo.EXPECT().GetTOTP(ctx, 1).Return(state, nil)
+	if _, err = s.Confirm(ctx, types.TwoFactorConfirmInput{UserID: 1, Code: "000000"}); !errors.Is(err, serviceerrs.ErrInvalidTwoFactorCode) {
+		t.Fatalf("Confirm() with wrong code error = %v, want %v", err, serviceerrs.ErrInvalidTwoFactorCode)
+	}
+
+	code, err := totp.Code(secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var hashes []string
+	twoFactorRepo.EXPECT().GetTOTP(ctx, 1).Return(state, nil)
+	twoFactorRepo.EXPECT().ConfirmTOTP(ctx, 1, gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ int, _ int64, h []string) error {
+		hashes = h
+		return nil
+	})
+
+	codes, err := s.Confirm(ctx, types.TwoFactorConfirmInput{UserID: 1, Code: code})
+	if err != nil {
+		t.Fatalf("Confirm() error = %v", err)
+	}
+	if len(codes) != recoveryCodeCount || len(hashes) != recoveryCodeCount {
+		t.Fatalf("Confirm() returned %d codes and stored %d hashes, want %d", len(codes), len(hashes), recoveryCodeCount)
+	}
+	for i, recoveryCode := range codes {
+		if err = passwordHasher.VerifyPassword(hashes[i], strings.ReplaceAll(recoveryCode, "-", "")); err != nil {
+			t.Errorf("recovery code %s does not match its hash: %v", recoveryCode, err)
+		}
+	}
+
+	twoFactorRepo.EXPECT().GetTOTP(ctx, 1).Return(entity.UserTOTP{UserID: 1, Secret: secret, Confirmed: true}, nil)
+	if _, err = s.Confirm(ctx, types.TwoFactorConfirmInput{UserID: 1, Code: code}); !errors.Is(err, serviceerrs.ErrTwoFactorAlreadyEnabled) {
+		t.Errorf("Confirm() twice error = %v, want %v", err, serviceerrs.ErrTwoFactorAlreadyEnabled)
+	}
+}
+
+func TestAuthService_TwoFactorLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	userRepo := repomocks.NewMockUser(ctrl)
+	sessionRepo := repomocks.NewMockSession(ctrl)
+	twoFactorRepo := repomocks.NewMockTwoFactor(ctrl)
+	passwordHasher := hasher.NewBcryptHasher()
+
+	s := NewAuthService(userRepo, sessionRepo, twoFactorRepo, passwordHasher, passwordpolicy.DefaultPolicy(),
+		newTestKeySet(t), time.Hour, time.Hour, time.Minute)
+
+	hashedPassword, err := passwordHasher.HashPassword("Qwerty1!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	recoveryHash, err := passwordHasher.HashPassword("abcdefghij")
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := entity.User{ID: 1, Username: "admin", Password: hashedPassword, Roles: []entity.Role{entity.RoleAdmin}}
+	state := entity.UserTOTP{UserID: 1, Secret: secret, Confirmed: true}
+
+	userRepo.EXPECT().LoginUser(ctx, "admin").Return(user, nil)
+	twoFactorRepo.EXPECT().GetTOTP(ctx, 1).Return(state, nil)
+
+	tokens, err := s.GenerateToken(ctx, types.AuthGenerateTokenInput{Username: "admin", Password: "Qwerty1!"})
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	if tokens.ChallengeToken == "" || tokens.AccessToken != "" || tokens.RefreshToken != "" {
+		t.Fatalf("GenerateToken() = %+v, want only a challenge token", tokens)
+	}
+
+	if _, err = s.ParseToken(ctx, tokens.ChallengeToken); !errors.Is(err, serviceerrs.ErrCannotParseToken) {
+		t.Errorf("ParseToken(challenge) error = %v, want %v", err, serviceerrs.ErrCannotParseToken)
+	}
+
+	code, err := totp.Code(secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userRepo.EXPECT().GetUserProfile(ctx, 1).Return(user, nil).Times(3)
+	twoFactorRepo.EXPECT().GetTOTP(ctx, 1).Return(state, nil).Times(3)
+	twoFactorRepo.EXPECT().UseTOTPStep(ctx, 1, gomock.Any()).Return(nil)
+	sessionRepo.EXPECT().CreateSession(ctx, gomock.Any()).Return(nil).Times(2)
+
+	tokens, err = s.VerifyTwoFactor(ctx, types.AuthVerifyTwoFactorInput{ChallengeToken: tokens.ChallengeToken, Code: code})
+	if err != nil {
+		t.Fatalf("VerifyTwoFactor() error = %v", err)
+	}
+	if tokens.AccessToken == "" || tokens.RefreshToken == "" {
+		t.Fatalf("VerifyTwoFactor() = %+v, want a token pair", tokens)
+	}
+
+	challenge, err := s.signChallengeToken(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Тот же код повторно не принимается.
+	twoFactorRepo.EXPECT().UseTOTPStep(ctx, 1, gomock.Any()).Return(repoerrs.ErrNotFound)
+	_, err = s.VerifyTwoFactor(ctx, types.AuthVerifyTwoFactorInput{ChallengeToken: challenge, Code: code})
+	if !errors.Is(err, serviceerrs.ErrInvalidTwoFactorCode) {
+		t.Errorf("VerifyTwoFactor() replay error = %v, want %v", err, serviceerrs.ErrInvalidTwoFactorCode)
+	}
+
+	twoFactorRepo.EXPECT().GetRecoveryCodes(ctx, 1).Return([]entity.RecoveryCode{{ID: 7, UserID: 1, CodeHash: recoveryHash}}, nil)
+	twoFactorRepo.EXPECT().UseRecoveryCode(ctx, 7).Return(nil)
+
+	if _, err = s.VerifyTwoFactor(ctx, types.AuthVerifyTwoFactorInput{ChallengeToken: challenge, Code: "ABCDE-FGHIJ"}); err != nil {
+		t.Errorf("VerifyTwoFactor() with recovery code error = %v", err)
+	}
+
+	if _, err = s.VerifyTwoFactor(ctx, types.AuthVerifyTwoFactorInput{ChallengeToken: "garbage", Code: code}); !errors.Is(err, serviceerrs.ErrInvalidChallengeToken) {
+		t.Errorf("VerifyTwoFactor() with invalid challenge error = %v, want %v", err, serviceerrs.ErrInvalidChallengeToken)
+	}
+}
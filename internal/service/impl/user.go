@@ -0,0 +1,153 @@
+package impl
+
+import (
+	"context"
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+	"github.com/cripplemymind9/go-market/pkg/hasher"
+	"github.com/cripplemymind9/go-market/pkg/passwordpolicy"
+)
+
+type UserService struct {
+	userRepo       repository.User
+	sessionRepo    repository.Session
+	passwordHasher hasher.PasswordHasher
+	passwordPolicy passwordpolicy.Policy
+}
+
+func NewUserService(
+	userRepo repository.User,
+	sessionRepo repository.Session,
+	passwordHasher hasher.PasswordHasher,
+	passwordPolicy passwordpolicy.Policy,
+) *UserService {
+	return &UserService{
+		userRepo:       userRepo,
+		sessionRepo:    sessionRepo,
+		passwordHasher: passwordHasher,
+		passwordPolicy: passwordPolicy,
+	}
+}
+
+func (s *UserService) GetProfile(ctx context.Context, userId int) (entity.User, error) {
+	user, err := s.userRepo.GetUserProfile(ctx, userId)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return entity.User{}, serviceerrs.ErrUserNotFound
+		}
+		log.Errorf("UserService.GetProfile - s.userRepo.GetUserProfile: %v", err)
+		return entity.User{}, serviceerrs.ErrCannotGetUser
+	}
+
+	return user, nil
+}
+
+// UpdateProfile меняет переданные поля профиля. Новый адрес почты требует
+// повторного подтверждения.
+func (s *UserService) UpdateProfile(ctx context.Context, input types.UserUpdateProfileInput) (entity.User, error) {
+	user, err := s.GetProfile(ctx, input.UserID)
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	username, email := user.Username, user.Email
+	if input.Username != nil {
+		username = *input.Username
+	}
+	if input.Email != nil {
+		email = *input.Email
+	}
+
+	if input.Email != nil {
+		inUse, err := s.userRepo.EmailInUse(ctx, email, user.ID)
+		if err != nil {
+			log.Errorf("UserService.UpdateProfile - s.userRepo.EmailInUse: %v", err)
+			return entity.User{}, serviceerrs.ErrCannotUpdateUser
+		}
+		if inUse {
+			return entity.User{}, serviceerrs.ErrEmailTaken
+		}
+	}
+
+	if err = s.userRepo.UpdateUserProfile(ctx, user.ID, username, email); err != nil {
+		if errors.Is(err, repoerrs.ErrAlreadyExists) {
+			return entity.User{}, serviceerrs.ErrUsernameTaken
+		}
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return entity.User{}, serviceerrs.ErrUserNotFound
+		}
+		log.Errorf("UserService.UpdateProfile - s.userRepo.UpdateUserProfile: %v", err)
+		return entity.User{}, serviceerrs.ErrCannotUpdateUser
+	}
+
+	return s.GetProfile(ctx, user.ID)
+}
+
+// ChangePassword меняет пароль после проверки текущего. Все сессии
+// пользователя отзываются, поэтому войти нужно заново.
+func (s *UserService) ChangePassword(ctx context.Context, input types.UserChangePasswordInput) error {
+	user, err := s.GetProfile(ctx, input.UserID)
+	if err != nil {
+		return err
+	}
+
+	if err = s.passwordHasher.VerifyPassword(user.Password, input.OldPassword); err != nil {
+		return serviceerrs.ErrInvalidPassword
+	}
+
+	if err = checkPasswordPolicy(s.passwordPolicy, input.NewPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.passwordHasher.HashPassword(input.NewPassword)
+	if err != nil {
+		return serviceerrs.ErrPasswordHashingFailed
+	}
+
+	if err = s.userRepo.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
+		log.Errorf("UserService.ChangePassword - s.userRepo.UpdatePassword: %v", err)
+		return serviceerrs.ErrCannotUpdateUser
+	}
+
+	if err = s.sessionRepo.RevokeUserSessions(ctx, user.ID); err != nil {
+		log.Errorf("UserService.ChangePassword - s.sessionRepo.RevokeUserSessions: %v", err)
+		return serviceerrs.ErrCannotRevokeSession
+	}
+
+	return nil
+}
+
+// DeleteAccount удаляет аккаунт после проверки пароля. Удаление мягкое:
+// история покупок сохраняется, а личные данные стираются.
+func (s *UserService) DeleteAccount(ctx context.Context, input types.UserDeleteAccountInput) error {
+	user, err := s.GetProfile(ctx, input.UserID)
+	if err != nil {
+		return err
+	}
+
+	if err = s.passwordHasher.VerifyPassword(user.Password, input.Password); err != nil {
+		return serviceerrs.ErrInvalidPassword
+	}
+
+	if err = s.userRepo.SoftDeleteUser(ctx, user.ID); err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return serviceerrs.ErrUserNotFound
+		}
+		log.Errorf("UserService.DeleteAccount - s.userRepo.SoftDeleteUser: %v", err)
+		return serviceerrs.ErrCannotDeleteUser
+	}
+
+	if err = s.sessionRepo.RevokeUserSessions(ctx, user.ID); err != nil {
+		log.Errorf("UserService.DeleteAccount - s.sessionRepo.RevokeUserSessions: %v", err)
+		return serviceerrs.ErrCannotRevokeSession
+	}
+
+	return nil
+}
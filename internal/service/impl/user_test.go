@@ -0,0 +1,221 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/mocks/repomocks"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+	"github.com/cripplemymind9/go-market/pkg/hasher"
+	"github.com/cripplemymind9/go-market/pkg/passwordpolicy"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestUserService_UpdateProfile(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		input types.UserUpdateProfileInput
+	}
+
+	type MockBehaviour func(m *repomocks.MockUser, args args)
+
+	current := entity.User{ID: 1, Username: "test", Email: "test@example.com", EmailVerified: true}
+
+	testCases := []struct {
+		name          string
+		args          args
+		mockBehaviour MockBehaviour
+		wantErr       error
+	}{
+		{
+			name: "OK: username only",
+			args: args{
+				ctx:   context.Background(),
+				input: types.UserUpdateProfileInput{UserID: 1, Username: strPtr("renamed")},
+			},
+			mockBehaviour: func(m *repomocks.MockUser, args args) {
+				m.EXPECT().GetUserProfile(args.ctx, 1).Return(current, nil)
+				m.EXPECT().UpdateUserProfile(args.ctx, 1, "renamed", "test@example.com").Return(nil)
+				m.EXPECT().GetUserProfile(args.ctx, 1).Return(entity.User{ID: 1, Username: "renamed"}, nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name: "OK: email",
+			args: args{
+				ctx:   context.Background(),
+				input: types.UserUpdateProfileInput{UserID: 1, Email: strPtr("new@example.com")},
+			},
+			mockBehaviour: func(m *repomocks.MockUser, args args) {
+				m.EXPECT().GetUserProfile(args.ctx, 1).Return(current, nil)
+				m.EXPECT().EmailInUse(args.ctx, "new@example.com", 1).Return(false, nil)
+				m.EXPECT().UpdateUserProfile(args.ctx, 1, "test", "new@example.com").Return(nil)
+				m.EXPECT().GetUserProfile(args.ctx, 1).Return(entity.User{ID: 1, Email: "new@example.com"}, nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Email taken",
+			args: args{
+				ctx:   context.Background(),
+				input: types.UserUpdateProfileInput{UserID: 1, Email: strPtr("other@example.com")},
+			},
+			mockBehaviour: func(m *repomocks.MockUser, args args) {
+				m.EXPECT().GetUserProfile(args.ctx, 1).Return(current, nil)
+				m.EXPECT().EmailInUse(args.ctx, "other@example.com", 1).Return(true, nil)
+			},
+			wantErr: serviceerrs.ErrEmailTaken,
+		},
+		{
+			name: "Username taken",
+			args: args{
+				ctx:   context.Background(),
+				input: types.UserUpdateProfileInput{UserID: 1, Username: strPtr("other")},
+			},
+			mockBehaviour: func(m *repomocks.MockUser, args args) {
+				m.EXPECT().GetUserProfile(args.ctx, 1).Return(current, nil)
+				m.EXPECT().UpdateUserProfile(args.ctx, 1, "other", "test@example.com").Return(repoerrs.ErrAlreadyExists)
+			},
+			wantErr: serviceerrs.ErrUsernameTaken,
+		},
+		{
+			name: "User not found",
+			args: args{
+				ctx:   context.Background(),
+				input: types.UserUpdateProfileInput{UserID: 42, Username: strPtr("other")},
+			},
+			mockBehaviour: func(m *repomocks.MockUser, args args) {
+				m.EXPECT().GetUserProfile(args.ctx, 42).Return(entity.User{}, repoerrs.ErrNotFound)
+			},
+			wantErr: serviceerrs.ErrUserNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepo := repomocks.NewMockUser(ctrl)
+			tc.mockBehaviour(userRepo, tc.args)
+
+			s := NewUserService(userRepo, repomocks.NewMockSession(ctrl), hasher.NewBcryptHasher(), passwordpolicy.DefaultPolicy())
+			_, err := s.UpdateProfile(tc.args.ctx, tc.args.input)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("UpdateProfile() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserService_ChangePassword(t *testing.T) {
+	passwordHasher := hasher.NewBcryptHasher()
+	hashedPassword, err := passwordHasher.HashPassword("Qwerty1!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := entity.User{ID: 1, Username: "test", Password: hashedPassword}
+
+	type MockBehaviour func(m *repomocks.MockUser, sm *repomocks.MockSession)
+
+	testCases := []struct {
+		name          string
+		input         types.UserChangePasswordInput
+		mockBehaviour MockBehaviour
+		wantErr       error
+	}{
+		{
+			name:  "OK",
+			input: types.UserChangePasswordInput{UserID: 1, OldPassword: "Qwerty1!", NewPassword: "NewPass1!"},
+			mockBehaviour: func(m *repomocks.MockUser, sm *repomocks.MockSession) {
+				m.EXPECT().GetUserProfile(gomock.Any(), 1).Return(user, nil)
+				m.EXPECT().UpdatePassword(gomock.Any(), 1, gomock.Any()).Return(nil)
+				sm.EXPECT().RevokeUserSessions(gomock.Any(), 1).Return(nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name:  "Wrong old password",
+			input: types.UserChangePasswordInput{UserID: 1, OldPassword: "Wrong1!!", NewPassword: "NewPass1!"},
+			mockBehaviour: func(m *repomocks.MockUser, sm *repomocks.MockSession) {
+				m.EXPECT().GetUserProfile(gomock.Any(), 1).Return(user, nil)
+			},
+			wantErr: serviceerrs.ErrInvalidPassword,
+		},
+		{
+			name:  "Weak new password",
+			input: types.UserChangePasswordInput{UserID: 1, OldPassword: "Qwerty1!", NewPassword: "short"},
+			mockBehaviour: func(m *repomocks.MockUser, sm *repomocks.MockSession) {
+				m.EXPECT().GetUserProfile(gomock.Any(), 1).Return(user, nil)
+			},
+			wantErr: serviceerrs.ErrWeakPassword,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepo := repomocks.NewMockUser(ctrl)
+			sessionRepo := repomocks.NewMockSession(ctrl)
+			tc.mockBehaviour(userRepo, sessionRepo)
+
+			s := NewUserService(userRepo, sessionRepo, passwordHasher, passwordpolicy.DefaultPolicy())
+			err := s.ChangePassword(context.Background(), tc.input)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("ChangePassword() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserService_DeleteAccount(t *testing.T) {
+	passwordHasher := hasher.NewBcryptHasher()
+	hashedPassword, err := passwordHasher.HashPassword("Qwerty1!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := entity.User{ID: 1, Username: "test", Password: hashedPassword}
+
+	t.Run("OK", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		userRepo := repomocks.NewMockUser(ctrl)
+		sessionRepo := repomocks.NewMockSession(ctrl)
+		userRepo.EXPECT().GetUserProfile(gomock.Any(), 1).Return(user, nil)
+		userRepo.EXPECT().SoftDeleteUser(gomock.Any(), 1).Return(nil)
+		sessionRepo.EXPECT().RevokeUserSessions(gomock.Any(), 1).Return(nil)
+
+		s := NewUserService(userRepo, sessionRepo, passwordHasher, passwordpolicy.DefaultPolicy())
+		if err := s.DeleteAccount(context.Background(), types.UserDeleteAccountInput{UserID: 1, Password: "Qwerty1!"}); err != nil {
+			t.Errorf("DeleteAccount() error = %v", err)
+		}
+	})
+
+	t.Run("Wrong password", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		userRepo := repomocks.NewMockUser(ctrl)
+		userRepo.EXPECT().GetUserProfile(gomock.Any(), 1).Return(user, nil)
+
+		s := NewUserService(userRepo, repomocks.NewMockSession(ctrl), passwordHasher, passwordpolicy.DefaultPolicy())
+		err := s.DeleteAccount(context.Background(), types.UserDeleteAccountInput{UserID: 1, Password: "Wrong1!!"})
+		if !errors.Is(err, serviceerrs.ErrInvalidPassword) {
+			t.Errorf("DeleteAccount() error = %v, want %v", err, serviceerrs.ErrInvalidPassword)
+		}
+	})
+}
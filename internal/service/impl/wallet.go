@@ -0,0 +1,72 @@
+package impl
+
+import (
+	"context"
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cripplemymind9/go-market/internal/ledger"
+	"github.com/cripplemymind9/go-market/internal/repository"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+type WalletService struct {
+	walletRepo repository.Wallet
+}
+
+func NewWalletService(walletRepo repository.Wallet) *WalletService {
+	return &WalletService{walletRepo: walletRepo}
+}
+
+func (s *WalletService) GetBalance(ctx context.Context, userId int) (float64, error) {
+	balance, err := s.walletRepo.GetBalance(ctx, userId)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return 0, serviceerrs.ErrUserNotFound
+		}
+		log.Errorf("WalletService.GetBalance - s.walletRepo.GetBalance: %v", err)
+		return 0, serviceerrs.ErrCannotGetBalance
+	}
+
+	return balance, nil
+}
+
+func (s *WalletService) Deposit(ctx context.Context, input types.WalletDepositInput) (float64, error) {
+	if ledger.ToMinor(input.Amount) <= 0 {
+		return 0, serviceerrs.ErrInvalidAmount
+	}
+
+	balance, err := s.walletRepo.Deposit(ctx, input.UserID, input.Amount)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return 0, serviceerrs.ErrUserNotFound
+		}
+		log.Errorf("WalletService.Deposit - s.walletRepo.Deposit: %v", err)
+		return 0, serviceerrs.ErrCannotDeposit
+	}
+
+	return balance, nil
+}
+
+func (s *WalletService) Withdraw(ctx context.Context, input types.WalletWithdrawInput) (float64, error) {
+	if ledger.ToMinor(input.Amount) <= 0 {
+		return 0, serviceerrs.ErrInvalidAmount
+	}
+
+	balance, err := s.walletRepo.Withdraw(ctx, input.UserID, input.Amount)
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrNotFound) {
+			return 0, serviceerrs.ErrUserNotFound
+		}
+		if errors.Is(err, repoerrs.ErrNotEnoughBalance) {
+			return 0, serviceerrs.ErrNotEnoughBalance
+		}
+		log.Errorf("WalletService.Withdraw - s.walletRepo.Withdraw: %v", err)
+		return 0, serviceerrs.ErrCannotWithdraw
+	}
+
+	return balance, nil
+}
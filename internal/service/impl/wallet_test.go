@@ -0,0 +1,186 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/cripplemymind9/go-market/internal/mocks/repomocks"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+func TestWalletService_Deposit(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		input types.WalletDepositInput
+	}
+
+	type MockBehaviour func(m *repomocks.MockWallet, args args)
+
+	testCases := []struct {
+		name          string
+		args          args
+		mockBehaviour MockBehaviour
+		want          float64
+		wantErr       error
+	}{
+		{
+			name: "OK",
+			args: args{
+				ctx:   context.Background(),
+				input: types.WalletDepositInput{UserID: 1, Amount: 100},
+			},
+			mockBehaviour: func(m *repomocks.MockWallet, args args) {
+				m.EXPECT().Deposit(args.ctx, 1, 100.0).Return(150.0, nil)
+			},
+			want:    150,
+			wantErr: nil,
+		},
+		{
+			name: "Invalid amount",
+			args: args{
+				ctx:   context.Background(),
+				input: types.WalletDepositInput{UserID: 1, Amount: -5},
+			},
+			mockBehaviour: func(m *repomocks.MockWallet, args args) {},
+			want:          0,
+			wantErr:       serviceerrs.ErrInvalidAmount,
+		},
+		{
+			name: "User not found",
+			args: args{
+				ctx:   context.Background(),
+				input: types.WalletDepositInput{UserID: 42, Amount: 10},
+			},
+			mockBehaviour: func(m *repomocks.MockWallet, args args) {
+				m.EXPECT().Deposit(args.ctx, 42, 10.0).Return(0.0, repoerrs.ErrNotFound)
+			},
+			want:    0,
+			wantErr: serviceerrs.ErrUserNotFound,
+		},
+		{
+			name: "Cannot deposit",
+			args: args{
+				ctx:   context.Background(),
+				input: types.WalletDepositInput{UserID: 1, Amount: 10},
+			},
+			mockBehaviour: func(m *repomocks.MockWallet, args args) {
+				m.EXPECT().Deposit(args.ctx, 1, 10.0).Return(0.0, errors.New("unexpected error"))
+			},
+			want:    0,
+			wantErr: serviceerrs.ErrCannotDeposit,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			walletRepo := repomocks.NewMockWallet(ctrl)
+			tc.mockBehaviour(walletRepo, tc.args)
+
+			s := NewWalletService(walletRepo)
+			got, err := s.Deposit(tc.args.ctx, tc.args.input)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Deposit() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+
+			if got != tc.want {
+				t.Errorf("Deposit() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWalletService_Withdraw(t *testing.T) {
+	type args struct {
+		ctx   context.Context
+		input types.WalletWithdrawInput
+	}
+
+	type MockBehaviour func(m *repomocks.MockWallet, args args)
+
+	testCases := []struct {
+		name          string
+		args          args
+		mockBehaviour MockBehaviour
+		want          float64
+		wantErr       error
+	}{
+		{
+			name: "OK",
+			args: args{
+				ctx:   context.Background(),
+				input: types.WalletWithdrawInput{UserID: 1, Amount: 30},
+			},
+			mockBehaviour: func(m *repomocks.MockWallet, args args) {
+				m.EXPECT().Withdraw(args.ctx, 1, 30.0).Return(70.0, nil)
+			},
+			want:    70,
+			wantErr: nil,
+		},
+		{
+			name: "Invalid amount",
+			args: args{
+				ctx:   context.Background(),
+				input: types.WalletWithdrawInput{UserID: 1, Amount: 0},
+			},
+			mockBehaviour: func(m *repomocks.MockWallet, args args) {},
+			want:          0,
+			wantErr:       serviceerrs.ErrInvalidAmount,
+		},
+		{
+			name: "Not enough balance",
+			args: args{
+				ctx:   context.Background(),
+				input: types.WalletWithdrawInput{UserID: 1, Amount: 1000},
+			},
+			mockBehaviour: func(m *repomocks.MockWallet, args args) {
+				m.EXPECT().Withdraw(args.ctx, 1, 1000.0).Return(0.0, repoerrs.ErrNotEnoughBalance)
+			},
+			want:    0,
+			wantErr: serviceerrs.ErrNotEnoughBalance,
+		},
+		{
+			name: "Cannot withdraw",
+			args: args{
+				ctx:   context.Background(),
+				input: types.WalletWithdrawInput{UserID: 1, Amount: 10},
+			},
+			mockBehaviour: func(m *repomocks.MockWallet, args args) {
+				m.EXPECT().Withdraw(args.ctx, 1, 10.0).Return(0.0, errors.New("unexpected error"))
+			},
+			want:    0,
+			wantErr: serviceerrs.ErrCannotWithdraw,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			walletRepo := repomocks.NewMockWallet(ctrl)
+			tc.mockBehaviour(walletRepo, tc.args)
+
+			s := NewWalletService(walletRepo)
+			got, err := s.Withdraw(tc.args.ctx, tc.args.input)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Withdraw() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+
+			if got != tc.want {
+				t.Errorf("Withdraw() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
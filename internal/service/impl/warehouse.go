@@ -0,0 +1,74 @@
+package impl
+
+import (
+	"context"
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/repository"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+type WarehouseService struct {
+	warehouseRepo repository.Warehouse
+}
+
+func NewWarehouseService(warehouseRepo repository.Warehouse) *WarehouseService {
+	return &WarehouseService{warehouseRepo: warehouseRepo}
+}
+
+func (s *WarehouseService) CreateWarehouse(ctx context.Context, input types.WarehouseCreateInput) (int, error) {
+	id, err := s.warehouseRepo.CreateWarehouse(ctx, entity.Warehouse{
+		Name:     input.Name,
+		Priority: input.Priority,
+		Location: input.Location,
+	})
+	if err != nil {
+		if errors.Is(err, repoerrs.ErrAlreadyExists) {
+			return 0, serviceerrs.ErrWarehouseAlreadyExists
+		}
+		log.Errorf("WarehouseService.CreateWarehouse - s.warehouseRepo.CreateWarehouse: %v", err)
+		return 0, serviceerrs.ErrCannotCreateWarehouse
+	}
+
+	return id, nil
+}
+
+// GetWarehouses возвращает склады в порядке, в котором их выбирает стратегия
+// priority.
+func (s *WarehouseService) GetWarehouses(ctx context.Context) ([]entity.Warehouse, error) {
+	warehouses, err := s.warehouseRepo.GetWarehouses(ctx)
+	if err != nil {
+		log.Errorf("WarehouseService.GetWarehouses - s.warehouseRepo.GetWarehouses: %v", err)
+		return nil, serviceerrs.ErrCannotGetWarehouses
+	}
+
+	return warehouses, nil
+}
+
+// UpdateWarehouse заменяет название, приоритет и координаты склада. Остатки
+// на складе не меняются.
+func (s *WarehouseService) UpdateWarehouse(ctx context.Context, input types.WarehouseUpdateInput) error {
+	err := s.warehouseRepo.UpdateWarehouse(ctx, entity.Warehouse{
+		ID:       input.ID,
+		Name:     input.Name,
+		Priority: input.Priority,
+		Location: input.Location,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, repoerrs.ErrNotFound):
+			return serviceerrs.ErrWarehouseNotFound
+		case errors.Is(err, repoerrs.ErrAlreadyExists):
+			return serviceerrs.ErrWarehouseAlreadyExists
+		}
+		log.Errorf("WarehouseService.UpdateWarehouse - s.warehouseRepo.UpdateWarehouse: %v", err)
+		return serviceerrs.ErrCannotUpdateWarehouse
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/mocks/repomocks"
+	"github.com/cripplemymind9/go-market/internal/repository/repoerrs"
+	"github.com/cripplemymind9/go-market/internal/service/serviceerrs"
+	"github.com/cripplemymind9/go-market/internal/service/types"
+)
+
+func TestWarehouseService_UpdateWarehouse(t *testing.T) {
+	type MockBehaviour func(m *repomocks.MockWarehouse)
+
+	location := &entity.GeoPoint{Latitude: 55.75, Longitude: 37.61}
+
+	testCases := []struct {
+		name          string
+		input         types.WarehouseUpdateInput
+		mockBehaviour MockBehaviour
+		wantErr       error
+	}{
+		{
+			name:  "OK",
+			input: types.WarehouseUpdateInput{ID: 2, Name: "moscow", Priority: 10, Location: location},
+			mockBehaviour: func(m *repomocks.MockWarehouse) {
+				m.EXPECT().UpdateWarehouse(gomock.Any(), entity.Warehouse{
+					ID: 2, Name: "moscow", Priority: 10, Location: location,
+				}).Return(nil)
+			},
+		},
+		{
+			name:  "Not found",
+			input: types.WarehouseUpdateInput{ID: 42, Name: "moscow"},
+			mockBehaviour: func(m *repomocks.MockWarehouse) {
+				m.EXPECT().UpdateWarehouse(gomock.Any(), gomock.Any()).Return(repoerrs.ErrNotFound)
+			},
+			wantErr: serviceerrs.ErrWarehouseNotFound,
+		},
+		{
+			name:  "Name taken",
+			input: types.WarehouseUpdateInput{ID: 2, Name: "main"},
+			mockBehaviour: func(m *repomocks.MockWarehouse) {
+				m.EXPECT().UpdateWarehouse(gomock.Any(), gomock.Any()).Return(repoerrs.ErrAlreadyExists)
+			},
+			wantErr: serviceerrs.ErrWarehouseAlreadyExists,
+		},
+		{
+			name:  "Cannot update",
+			input: types.WarehouseUpdateInput{ID: 2, Name: "moscow"},
+			mockBehaviour: func(m *repomocks.MockWarehouse) {
+				m.EXPECT().UpdateWarehouse(gomock.Any(), gomock.Any()).Return(errors.New("unexpected error"))
+			},
+			wantErr: serviceerrs.ErrCannotUpdateWarehouse,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			warehouseRepo := repomocks.NewMockWarehouse(ctrl)
+			tc.mockBehaviour(warehouseRepo)
+
+			s := NewWarehouseService(warehouseRepo)
+			err := s.UpdateWarehouse(context.Background(), tc.input)
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("UpdateWarehouse() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
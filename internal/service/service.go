@@ -5,16 +5,83 @@ import (
 	"time"
 
 	"github.com/cripplemymind9/go-market/internal/entity"
+	"github.com/cripplemymind9/go-market/internal/ledger"
+	"github.com/cripplemymind9/go-market/internal/notifier"
 	"github.com/cripplemymind9/go-market/internal/repository"
 	"github.com/cripplemymind9/go-market/internal/service/impl"
 	"github.com/cripplemymind9/go-market/internal/service/types"
 	"github.com/cripplemymind9/go-market/pkg/hasher"
+	"github.com/cripplemymind9/go-market/pkg/jwtkeys"
+	"github.com/cripplemymind9/go-market/pkg/mailer"
+	"github.com/cripplemymind9/go-market/pkg/oidc"
+	"github.com/cripplemymind9/go-market/pkg/passwordpolicy"
+	"github.com/cripplemymind9/go-market/pkg/signedtoken"
 )
 
 type Auth interface {
 	RegisterUser(ctx context.Context, input types.AuthRegisterUserInput) (int, error)
-	GenerateToken(ctx context.Context, input types.AuthGenerateTokenInput) (string, error)
-	ParseToken(token string) (int, error)
+	GenerateToken(ctx context.Context, input types.AuthGenerateTokenInput) (types.AuthTokens, error)
+	ParseChallengeToken(ctx context.Context, challengeToken string) (types.AuthChallenge, error)
+	VerifyTwoFactor(ctx context.Context, input types.AuthVerifyTwoFactorInput) (types.AuthTokens, error)
+	IssueTokens(ctx context.Context, userId int) (types.AuthTokens, error)
+	RefreshToken(ctx context.Context, refreshToken string) (types.AuthTokens, error)
+	Logout(ctx context.Context, refreshToken string) error
+	ParseToken(ctx context.Context, token string) (types.AuthIdentity, error)
+	JWKS() jwtkeys.JWKSet
+}
+
+type Account interface {
+	SendVerificationEmail(ctx context.Context, userId int) error
+	ResendVerificationEmail(ctx context.Context, email string) error
+	VerifyEmail(ctx context.Context, token string) error
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, input types.AccountResetPasswordInput) error
+}
+
+type OIDC interface {
+	Begin(ctx context.Context) (types.OIDCLoginStart, error)
+	Complete(ctx context.Context, input types.OIDCCompleteInput) (entity.User, error)
+}
+
+type TwoFactor interface {
+	Enroll(ctx context.Context, userId int) (types.TwoFactorEnrollment, error)
+	Confirm(ctx context.Context, input types.TwoFactorConfirmInput) ([]string, error)
+	Disable(ctx context.Context, input types.TwoFactorDisableInput) error
+}
+
+type APIKey interface {
+	Create(ctx context.Context, input types.APIKeyCreateInput) (types.APIKeyCreated, error)
+	List(ctx context.Context, userId int) ([]entity.APIKey, error)
+	Revoke(ctx context.Context, userId, id int) error
+	Authenticate(ctx context.Context, token string) (types.AuthIdentity, error)
+}
+
+type LoginThrottle interface {
+	Reserve(ctx context.Context, input types.LoginThrottleInput) (time.Duration, error)
+	RecordSuccess(ctx context.Context, input types.LoginThrottleInput) error
+}
+
+// Idempotency хранит ответы на запросы с заголовком Idempotency-Key. Begin
+// занимает ключ или, если запрос уже выполнен, возвращает запись с сохраненным
+// ответом (StatusCode не 0). Занятый ключ завершается Complete или, если ответ
+// сохранять не нужно, освобождается Release.
+type Idempotency interface {
+	Begin(ctx context.Context, input types.IdempotencyBeginInput) (entity.IdempotencyRecord, error)
+	Complete(ctx context.Context, record entity.IdempotencyRecord) error
+	Release(ctx context.Context, record entity.IdempotencyRecord) error
+}
+
+type User interface {
+	GetProfile(ctx context.Context, userId int) (entity.User, error)
+	UpdateProfile(ctx context.Context, input types.UserUpdateProfileInput) (entity.User, error)
+	ChangePassword(ctx context.Context, input types.UserChangePasswordInput) error
+	DeleteAccount(ctx context.Context, input types.UserDeleteAccountInput) error
+}
+
+type Role interface {
+	GetUserRoles(ctx context.Context, userId int) ([]entity.Role, error)
+	GrantRole(ctx context.Context, input types.RoleGrantRoleInput) error
+	RevokeRole(ctx context.Context, input types.RoleRevokeRoleInput) error
 }
 
 type Product interface {
@@ -22,33 +89,170 @@ type Product interface {
 	GetAllProducts(ctx context.Context) ([]entity.Product, error)
 	GetProductById(ctx context.Context, productId int) (entity.Product, error)
 	UpdateProduct(ctx context.Context, input types.ProductUpdateProductInput) error
-	DeleteProduct(ctx context.Context, productId int) error
+	DeleteProduct(ctx context.Context, input types.ProductDeleteProductInput) error
+	AdjustStock(ctx context.Context, input types.ProductAdjustStockInput) (entity.InventoryMovement, error)
+	GetStockMovements(ctx context.Context, input types.ProductStockMovementsInput) ([]entity.InventoryMovement, error)
+	GetStockAt(ctx context.Context, input types.ProductStockAtInput) (int, error)
+	GetWarehouseStock(ctx context.Context, input types.ProductWarehouseStockInput) ([]entity.WarehouseStock, error)
+	SetReorderThreshold(ctx context.Context, input types.ProductReorderThresholdInput) error
+	GetStockAlerts(ctx context.Context, input types.ProductStockAlertsInput) ([]entity.StockAlert, error)
+}
+
+type StockAlert interface {
+	DispatchAlerts(ctx context.Context) (int, error)
+}
+
+type Warehouse interface {
+	CreateWarehouse(ctx context.Context, input types.WarehouseCreateInput) (int, error)
+	GetWarehouses(ctx context.Context) ([]entity.Warehouse, error)
+	UpdateWarehouse(ctx context.Context, input types.WarehouseUpdateInput) error
+}
+
+type Seller interface {
+	GetProducts(ctx context.Context, sellerId int) ([]entity.Product, error)
+	GetSalesReport(ctx context.Context, sellerId int) (entity.SellerSalesReport, error)
 }
 
 type Purchase interface {
 	MakePurchase(ctx context.Context, input types.PurchaseMakePurchaseInput) (int, error)
-	GetUserPurchases(ctx context.Context, userId int) ([]entity.Purchase, error)
-	GetProductPurchases(ctx context.Context, productId int) ([]entity.Purchase, error)
+	GetUserOrders(ctx context.Context, userId int) ([]entity.Order, error)
+	GetProductOrders(ctx context.Context, productId int) ([]entity.Order, error)
+}
+
+type Order interface {
+	GetOrder(ctx context.Context, input types.OrderGetOrderInput) (types.OrderDetails, error)
+	Cancel(ctx context.Context, input types.OrderCancelInput) (entity.Order, error)
+	Advance(ctx context.Context, input types.OrderAdvanceInput) (entity.Order, error)
+	Refund(ctx context.Context, input types.OrderRefundInput) (entity.Refund, error)
+}
+
+// Reservation держит товары за покупателем, пока он оплачивает заказ. Бронь
+// живет ограниченное время: Confirm превращает ее в заказ, Release и
+// ReleaseExpired возвращают товары в продажу.
+type Reservation interface {
+	Reserve(ctx context.Context, input types.ReservationCreateInput) (entity.Reservation, error)
+	GetReservation(ctx context.Context, input types.ReservationInput) (entity.Reservation, error)
+	Confirm(ctx context.Context, input types.ReservationConfirmInput) (int, error)
+	Release(ctx context.Context, input types.ReservationInput) error
+	ReleaseExpired(ctx context.Context) (int, error)
+}
+
+type Cart interface {
+	GetCart(ctx context.Context, userId int) (entity.Cart, error)
+	AddItem(ctx context.Context, input types.CartAddItemInput) error
+	UpdateItem(ctx context.Context, input types.CartUpdateItemInput) error
+	RemoveItem(ctx context.Context, userId, productId int) error
+	Clear(ctx context.Context, userId int) error
+	Checkout(ctx context.Context, input types.CartCheckoutInput) (int, error)
+}
+
+type Wallet interface {
+	GetBalance(ctx context.Context, userId int) (float64, error)
+	Deposit(ctx context.Context, input types.WalletDepositInput) (float64, error)
+	Withdraw(ctx context.Context, input types.WalletWithdrawInput) (float64, error)
+}
+
+type Ledger interface {
+	Reconcile(ctx context.Context) (ledger.Report, error)
 }
 
 type Services struct {
-	Auth     Auth
-	Product  Product
-	Purchase Purchase
+	Auth          Auth
+	Account       Account
+	OIDC          OIDC
+	TwoFactor     TwoFactor
+	APIKey        APIKey
+	LoginThrottle LoginThrottle
+	Idempotency   Idempotency
+	User          User
+	Role          Role
+	Product       Product
+	Warehouse     Warehouse
+	StockAlert    StockAlert
+	Seller        Seller
+	Purchase      Purchase
+	Order         Order
+	Reservation   Reservation
+	Cart          Cart
+	Wallet        Wallet
+	Ledger        Ledger
 }
 
 type ServiceDependencies struct {
-	Repos  repository.Repositories
-	Hasher hasher.PasswordHasher
+	Repos          repository.Repositories
+	Hasher         hasher.PasswordHasher
+	PasswordPolicy passwordpolicy.Policy
+	Mailer         mailer.Mailer
+
+	EmailTokenSigner *signedtoken.Signer
+	Account          impl.AccountConfig
+
+	JWTKeys         *jwtkeys.KeySet
+	TokenTTL        time.Duration
+	RefreshTokenTTL time.Duration
+
+	TwoFactorIssuer       string
+	TwoFactorChallengeTTL time.Duration
 
-	SignKey  string
-	TokenTTL time.Duration
+	// OIDCClient - клиент внешнего провайдера входа. Если он не задан, вход
+	// через провайдера отключен.
+	OIDCClient *oidc.Client
+	OIDC       impl.OIDCConfig
+
+	// IdempotencyKeyTTL - сколько хранится ответ на запрос с Idempotency-Key.
+	IdempotencyKeyTTL time.Duration
+
+	// ReservationTTL - сколько держится бронь товаров до оплаты.
+	ReservationTTL time.Duration
+
+	// StockAlertNotifier доставляет продавцам предупреждения о низком остатке.
+	StockAlertNotifier notifier.Multi
 }
 
 func NewServices(deps ServiceDependencies) *Services {
+	var oidcService OIDC
+	if deps.OIDCClient != nil {
+		oidcService = impl.NewOIDCService(deps.Repos.User, deps.Repos.ExternalIdentity, deps.OIDCClient, deps.OIDC)
+	}
+
 	return &Services{
-		Auth:     impl.NewAuthService(deps.Repos.User, deps.Hasher, deps.SignKey, deps.TokenTTL),
-		Product:  impl.NewProductService(deps.Repos.Product),
-		Purchase: impl.NewPurchaseService(deps.Repos.Purchase),
+		Auth: impl.NewAuthService(
+			deps.Repos.User,
+			deps.Repos.Session,
+			deps.Repos.TwoFactor,
+			deps.Hasher,
+			deps.PasswordPolicy,
+			deps.JWTKeys,
+			deps.TokenTTL,
+			deps.RefreshTokenTTL,
+			deps.TwoFactorChallengeTTL,
+		),
+		Account: impl.NewAccountService(
+			deps.Repos.User,
+			deps.Repos.UserToken,
+			deps.Repos.Session,
+			deps.Hasher,
+			deps.PasswordPolicy,
+			deps.Mailer,
+			deps.EmailTokenSigner,
+			deps.Account,
+		),
+		OIDC:          oidcService,
+		TwoFactor:     impl.NewTwoFactorService(deps.Repos.User, deps.Repos.TwoFactor, deps.Hasher, deps.TwoFactorIssuer),
+		APIKey:        impl.NewAPIKeyService(deps.Repos.APIKey, deps.Repos.User),
+		LoginThrottle: impl.NewLoginThrottleService(deps.Repos.LoginAttempt, impl.DefaultUsernameThrottlePolicy, impl.DefaultIPThrottlePolicy),
+		Idempotency:   impl.NewIdempotencyService(deps.Repos.Idempotency, deps.IdempotencyKeyTTL),
+		User:          impl.NewUserService(deps.Repos.User, deps.Repos.Session, deps.Hasher, deps.PasswordPolicy),
+		Role:          impl.NewRoleService(deps.Repos.User, deps.Repos.Session),
+		Product:       impl.NewProductService(deps.Repos.Product, deps.Repos.Inventory, deps.Repos.Warehouse, deps.Repos.StockAlert),
+		Warehouse:     impl.NewWarehouseService(deps.Repos.Warehouse),
+		StockAlert:    impl.NewStockAlertService(deps.Repos.StockAlert, deps.StockAlertNotifier),
+		Seller:        impl.NewSellerService(deps.Repos.Product, deps.Repos.Purchase),
+		Purchase:      impl.NewPurchaseService(deps.Repos.Purchase, deps.Repos.User),
+		Order:         impl.NewOrderService(deps.Repos.Order, deps.Repos.Purchase),
+		Reservation:   impl.NewReservationService(deps.Repos.Reservation, deps.Repos.User, deps.ReservationTTL),
+		Cart:          impl.NewCartService(deps.Repos.Cart, deps.Repos.User),
+		Wallet:        impl.NewWalletService(deps.Repos.Wallet),
+		Ledger:        impl.NewLedgerService(deps.Repos.Ledger),
 	}
 }
@@ -1,27 +1,166 @@
 package serviceerrs
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/cripplemymind9/go-market/pkg/passwordpolicy"
+)
 
 var (
 	ErrPasswordHashingFailed = fmt.Errorf("password hashing failed")
 	ErrInvalidPassword       = fmt.Errorf("invalid password")
+	ErrWeakPassword          = fmt.Errorf("password does not meet the policy")
 
 	ErrCannotSignToken  = fmt.Errorf("cannot sign token")
 	ErrCannotParseToken = fmt.Errorf("cannot parse token")
 
+	ErrInvalidRefreshToken = fmt.Errorf("invalid refresh token")
+	ErrRefreshTokenReused  = fmt.Errorf("refresh token reuse detected")
+	ErrSessionRevoked      = fmt.Errorf("session revoked")
+	ErrCannotCreateSession = fmt.Errorf("cannot create session")
+	ErrCannotRefreshToken  = fmt.Errorf("cannot refresh token")
+	ErrCannotRevokeSession = fmt.Errorf("cannot revoke session")
+
+	ErrEmailNotVerified     = fmt.Errorf("email is not verified")
+	ErrEmailAlreadyVerified = fmt.Errorf("email is already verified")
+	ErrInvalidEmailToken    = fmt.Errorf("invalid or expired token")
+	ErrCannotVerifyToken    = fmt.Errorf("cannot verify token")
+	ErrCannotVerifyEmail    = fmt.Errorf("cannot verify email")
+	ErrCannotSendEmail      = fmt.Errorf("cannot send email")
+	ErrCannotResetPassword  = fmt.Errorf("cannot reset password")
+
+	ErrTwoFactorAlreadyEnabled = fmt.Errorf("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnrolled    = fmt.Errorf("two-factor authentication enrollment is not started")
+	ErrTwoFactorNotEnabled     = fmt.Errorf("two-factor authentication is not enabled")
+	ErrInvalidTwoFactorCode    = fmt.Errorf("invalid two-factor code")
+	ErrInvalidChallengeToken   = fmt.Errorf("invalid or expired challenge token")
+	ErrCannotCheckTwoFactor    = fmt.Errorf("cannot check two-factor authentication")
+	ErrCannotEnrollTwoFactor   = fmt.Errorf("cannot enroll two-factor authentication")
+	ErrCannotEnableTwoFactor   = fmt.Errorf("cannot enable two-factor authentication")
+	ErrCannotDisableTwoFactor  = fmt.Errorf("cannot disable two-factor authentication")
+
+	ErrInvalidOIDCState        = fmt.Errorf("invalid or expired login state")
+	ErrOIDCLoginFailed         = fmt.Errorf("external login failed")
+	ErrCannotStartOIDCLogin    = fmt.Errorf("cannot start external login")
+	ErrCannotCompleteOIDCLogin = fmt.Errorf("cannot complete external login")
+
+	ErrInvalidAPIKey       = fmt.Errorf("invalid or expired API key")
+	ErrInvalidAPIKeyScope  = fmt.Errorf("invalid API key scope")
+	ErrInvalidAPIKeyExpiry = fmt.Errorf("API key expiry must be in the future")
+	ErrAPIKeyNotFound      = fmt.Errorf("API key not found")
+	ErrCannotCheckAPIKey   = fmt.Errorf("cannot check API key")
+	ErrCannotCreateAPIKey  = fmt.Errorf("cannot create API key")
+	ErrCannotGetAPIKeys    = fmt.Errorf("cannot get API keys")
+	ErrCannotRevokeAPIKey  = fmt.Errorf("cannot revoke API key")
+
+	ErrTooManyLoginAttempts     = fmt.Errorf("too many login attempts")
+	ErrCannotCheckLoginAttempts = fmt.Errorf("cannot check login attempts")
+
+	ErrInvalidIdempotencyKey     = fmt.Errorf("invalid idempotency key")
+	ErrIdempotencyKeyReused      = fmt.Errorf("idempotency key was already used with a different request")
+	ErrIdempotencyKeyInProgress  = fmt.Errorf("request with this idempotency key is still in progress")
+	ErrCannotCheckIdempotencyKey = fmt.Errorf("cannot check idempotency key")
+	ErrCannotStoreIdempotencyKey = fmt.Errorf("cannot store idempotent response")
+	ErrCannotRecordLoginAttempt  = fmt.Errorf("cannot record login attempt")
+
 	ErrCannotCreateUser  = fmt.Errorf("cannot create user")
 	ErrUserAlreadyExists = fmt.Errorf("user already exists")
 	ErrUserNotFound      = fmt.Errorf("user not found")
 	ErrCannotGetUser     = fmt.Errorf("cannot get user")
+	ErrUsernameTaken     = fmt.Errorf("username is already taken")
+	ErrEmailTaken        = fmt.Errorf("email is already taken")
+	ErrCannotUpdateUser  = fmt.Errorf("cannot update user")
+	ErrCannotDeleteUser  = fmt.Errorf("cannot delete user")
+
+	ErrInvalidRole      = fmt.Errorf("invalid role")
+	ErrRoleNotAssigned  = fmt.Errorf("role is not assigned")
+	ErrCannotGetRoles   = fmt.Errorf("cannot get roles")
+	ErrCannotGrantRole  = fmt.Errorf("cannot grant role")
+	ErrCannotRevokeRole = fmt.Errorf("cannot revoke role")
 
 	ErrProductAlreadyExists = fmt.Errorf("product already exists")
 	ErrCannotCreateProduct  = fmt.Errorf("cannot create product")
 	ErrCannotGetProducts    = fmt.Errorf("cannot get products")
 	ErrNoProductsAvailable  = fmt.Errorf("no products available")
+	ErrProductNotFound      = fmt.Errorf("product not found")
+	ErrProductNotOwned      = fmt.Errorf("product belongs to another seller")
+	ErrCannotGetProduct     = fmt.Errorf("cannot get product")
+	ErrCannotUpdateProduct  = fmt.Errorf("cannot update product")
+	ErrCannotDeleteProduct  = fmt.Errorf("cannot delete product")
+	ErrInvalidPrice         = fmt.Errorf("price must be at least 0.01")
+	ErrCannotGetSales       = fmt.Errorf("cannot get sales")
+
+	ErrInvalidStockAdjustment = fmt.Errorf("restock must add stock and adjustment must change it")
+	ErrStockBelowReserved     = fmt.Errorf("stock cannot go below zero in the warehouse or below the reserved quantity")
+	ErrInvalidStockPeriod     = fmt.Errorf("period start must be before its end")
+	ErrCannotAdjustStock      = fmt.Errorf("cannot adjust stock")
+	ErrCannotGetStock         = fmt.Errorf("cannot get stock history")
 
+	ErrInvalidReorderThreshold   = fmt.Errorf("reorder threshold cannot be negative")
+	ErrCannotSetReorderThreshold = fmt.Errorf("cannot set reorder threshold")
+	ErrCannotGetStockAlerts      = fmt.Errorf("cannot get stock alerts")
+	ErrCannotDispatchStockAlerts = fmt.Errorf("cannot dispatch stock alerts")
+
+	ErrWarehouseNotFound      = fmt.Errorf("warehouse not found")
+	ErrWarehouseAlreadyExists = fmt.Errorf("warehouse with this name already exists")
+	ErrCannotCreateWarehouse  = fmt.Errorf("cannot create warehouse")
+	ErrCannotGetWarehouses    = fmt.Errorf("cannot get warehouses")
+	ErrCannotUpdateWarehouse  = fmt.Errorf("cannot update warehouse")
+
+	ErrEmptyOrder                = fmt.Errorf("order must contain at least one item")
 	ErrCannotCreatePurchase      = fmt.Errorf("cannot create purchase")
+	ErrNotEnoughStock            = fmt.Errorf("not enough stock")
 	ErrNoUserPurchasesFound      = fmt.Errorf("user purchases not found")
 	ErrCannotGetUserPurchases    = fmt.Errorf("cannot get user purchases")
 	ErrNoProductPurchasesFound   = fmt.Errorf("product purchases not found")
 	ErrCannotGetProductPurchases = fmt.Errorf("cannot get product purchases")
+
+	ErrOrderNotFound          = fmt.Errorf("order not found")
+	ErrOrderForbidden         = fmt.Errorf("not allowed to change this order")
+	ErrInvalidOrderTransition = fmt.Errorf("order status does not allow this action")
+	ErrOrderStatusChanged     = fmt.Errorf("order status has changed, reload the order")
+	ErrCannotGetOrder         = fmt.Errorf("cannot get order")
+	ErrCannotUpdateOrder      = fmt.Errorf("cannot update order")
+
+	ErrOrderItemNotFound     = fmt.Errorf("product is not in the order")
+	ErrRefundExceedsPurchase = fmt.Errorf("refund exceeds purchased quantity")
+	ErrNothingToRefund       = fmt.Errorf("nothing left to refund")
+	ErrCannotRefund          = fmt.Errorf("cannot refund order")
+
+	ErrReservationNotFound      = fmt.Errorf("reservation not found")
+	ErrReservationNotActive     = fmt.Errorf("reservation is no longer active")
+	ErrReservationExpired       = fmt.Errorf("reservation has expired")
+	ErrCannotReserve            = fmt.Errorf("cannot reserve products")
+	ErrCannotGetReservation     = fmt.Errorf("cannot get reservation")
+	ErrCannotConfirmReservation = fmt.Errorf("cannot confirm reservation")
+	ErrCannotReleaseReservation = fmt.Errorf("cannot release reservation")
+
+	ErrInvalidQuantity  = fmt.Errorf("quantity must be positive")
+	ErrCartEmpty        = fmt.Errorf("cart is empty")
+	ErrCartItemNotFound = fmt.Errorf("product is not in the cart")
+	ErrCannotGetCart    = fmt.Errorf("cannot get cart")
+	ErrCannotUpdateCart = fmt.Errorf("cannot update cart")
+	ErrCannotCheckout   = fmt.Errorf("cannot checkout cart")
+
+	ErrInvalidAmount    = fmt.Errorf("amount must be positive")
+	ErrNotEnoughBalance = fmt.Errorf("not enough balance")
+	ErrCannotGetBalance = fmt.Errorf("cannot get balance")
+	ErrCannotDeposit    = fmt.Errorf("cannot deposit")
+	ErrCannotWithdraw   = fmt.Errorf("cannot withdraw")
+
+	ErrCannotReconcileLedger = fmt.Errorf("cannot reconcile ledger")
 )
+
+// PasswordPolicyError перечисляет правила политики паролей, которым не
+// соответствует пароль. errors.Is(err, ErrWeakPassword) для него истинно.
+type PasswordPolicyError struct {
+	Violations []passwordpolicy.Violation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return ErrWeakPassword.Error()
+}
+
+func (e *PasswordPolicyError) Unwrap() error {
+	return ErrWeakPassword
+}
@@ -1,5 +1,11 @@
 package types
 
+import (
+	"time"
+
+	"github.com/cripplemymind9/go-market/internal/entity"
+)
+
 type AuthRegisterUserInput struct {
 	Username 	string
 	Password 	string
@@ -11,11 +17,141 @@ type AuthGenerateTokenInput struct {
 	Password 	string
 }
 
+type AccountResetPasswordInput struct {
+	Token		string
+	Password	string
+}
+
+// IdempotencyBeginInput - запрос с заголовком Idempotency-Key. Scope - метод
+// и путь запроса; он отделяет ключи разных маршрутов и разных ресурсов.
+type IdempotencyBeginInput struct {
+	UserID		int
+	Scope		string
+	Key			string
+	Body		[]byte
+}
+
+type LoginThrottleInput struct {
+	// Scope отделяет счетчики разных проверок: пустой - вход по паролю.
+	Scope		string
+	Username	string
+	IP			string
+}
+
+// AuthTokens - результат входа. Если у пользователя включен второй фактор,
+// заполнен только ChallengeToken, который обменивается на пару токенов
+// после проверки кода.
+type AuthTokens struct {
+	AccessToken		string
+	RefreshToken	string
+	ChallengeToken	string
+}
+
+type AuthVerifyTwoFactorInput struct {
+	ChallengeToken	string
+	Code			string
+}
+
+type AuthChallenge struct {
+	UserID		int
+	Username	string
+}
+
+// OIDCLoginStart - начатый вход через внешнего провайдера. State нужно
+// сохранить у клиента и сверить при возврате от провайдера.
+type OIDCLoginStart struct {
+	AuthURL	string
+	State	string
+}
+
+type OIDCCompleteInput struct {
+	State	string
+	Code	string
+}
+
+// AuthIdentity - пользователь, от имени которого выполняется запрос. При входе
+// по API-ключу заполнены APIKeyID и Scopes, и доступ ограничен этими правами.
+type AuthIdentity struct {
+	UserID		int
+	Roles		[]entity.Role
+	APIKeyID	int
+	Scopes		[]entity.APIKeyScope
+}
+
+func (i AuthIdentity) HasRole(role entity.Role) bool {
+	for _, r := range i.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScope сообщает, разрешено ли запросу действие. Вход по токену доступа
+// не ограничен правами ключа.
+func (i AuthIdentity) HasScope(scope entity.APIKeyScope) bool {
+	if i.APIKeyID == 0 {
+		return true
+	}
+	for _, s := range i.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type APIKeyCreateInput struct {
+	UserID		int
+	Name		string
+	Scopes		[]entity.APIKeyScope
+	ExpiresAt	*time.Time
+}
+
+// APIKeyCreated - выпущенный ключ. Token показывается только один раз.
+type APIKeyCreated struct {
+	Key		entity.APIKey
+	Token	string
+}
+
+type TwoFactorEnrollment struct {
+	Secret	string
+	URI		string
+}
+
+type TwoFactorConfirmInput struct {
+	UserID	int
+	Code	string
+}
+
+type TwoFactorDisableInput struct {
+	UserID	int
+	Code	string
+}
+
+type UserUpdateProfileInput struct {
+	UserID		int
+	Username	*string
+	Email		*string
+}
+
+type UserChangePasswordInput struct {
+	UserID		int
+	OldPassword	string
+	NewPassword	string
+}
+
+type UserDeleteAccountInput struct {
+	UserID		int
+	Password	string
+}
+
 type ProductAddProductInput struct {
 	Name 		string
 	Description string
 	Price 		float64
 	Quantity 	int
+	SellerID	int
 }
 
 type ProductUpdateProductInput struct {
@@ -23,11 +159,177 @@ type ProductUpdateProductInput struct {
 	Name 		string
 	Description string
 	Price 		float64
+	Actor		AuthIdentity
+}
+
+type ProductDeleteProductInput struct {
+	ID 			int
+	Actor		AuthIdentity
+}
+
+// ProductAdjustStockInput - ручное изменение остатка на складе WarehouseID на
+// Delta единиц. Kind - restock (поступление) или adjustment (корректировка).
+// Нулевой WarehouseID означает склад по умолчанию.
+type ProductAdjustStockInput struct {
+	ID			int
+	WarehouseID	int
+	Kind		entity.InventoryMovementKind
+	Delta		int
+	Reason		string
+	Actor		AuthIdentity
+}
+
+// ProductStockMovementsInput - движения товара в полуинтервале [From, To).
+// Нулевой To означает текущий момент.
+type ProductStockMovementsInput struct {
+	ID			int
+	From		time.Time
+	To			time.Time
+	Actor		AuthIdentity
+}
+
+// ProductStockAtInput - остаток товара на момент At. Нулевой At означает
+// текущий момент.
+type ProductStockAtInput struct {
+	ID			int
+	At			time.Time
+	Actor		AuthIdentity
+}
+
+type ProductWarehouseStockInput struct {
+	ID			int
+	Actor		AuthIdentity
+}
+
+// ProductReorderThresholdInput - порог дозаказа товара. Nil снимает порог.
+type ProductReorderThresholdInput struct {
+	ID			int
+	Threshold	*int
+	Actor		AuthIdentity
+}
+
+type ProductStockAlertsInput struct {
+	ID			int
+	Actor		AuthIdentity
+}
+
+type PurchaseItemInput struct {
+	ProductID 	int
 	Quantity 	int
 }
 
+// PurchaseMakePurchaseInput - покупка. ShipTo может быть не задан, тогда
+// склады выбираются без учета адреса доставки.
 type PurchaseMakePurchaseInput struct {
 	UserID		int
-	ProductID 	int
-	Quantity 	int
+	Items		[]PurchaseItemInput
+	ShipTo		*entity.GeoPoint
+}
+
+type OrderGetOrderInput struct {
+	OrderID		int
+	Actor		AuthIdentity
+}
+
+type OrderCancelInput struct {
+	OrderID		int
+	Reason		string
+	Actor		AuthIdentity
+}
+
+type OrderAdvanceInput struct {
+	OrderID		int
+	Reason		string
+	Actor		AuthIdentity
+}
+
+type OrderRefundItemInput struct {
+	ProductID	int
+	Quantity	int
+}
+
+// OrderRefundInput - возврат товаров заказа. Пустой Items означает возврат
+// всего, что еще не возвращено.
+type OrderRefundInput struct {
+	OrderID		int
+	Items		[]OrderRefundItemInput
+	Reason		string
+	Actor		AuthIdentity
+}
+
+// OrderDetails - заказ вместе с историей его статусов и возвратами.
+type OrderDetails struct {
+	Order		entity.Order
+	History		[]entity.OrderStatusChange
+	Refunds		[]entity.Refund
+}
+
+type ReservationCreateInput struct {
+	UserID		int
+	Items		[]PurchaseItemInput
+}
+
+type ReservationInput struct {
+	ReservationID	int
+	UserID		int
+}
+
+// ReservationConfirmInput - оплата брони. ShipTo может быть не задан, тогда
+// склады выбираются без учета адреса доставки.
+type ReservationConfirmInput struct {
+	ReservationID	int
+	UserID		int
+	ShipTo		*entity.GeoPoint
+}
+
+type WarehouseCreateInput struct {
+	Name		string
+	Priority	int
+	Location	*entity.GeoPoint
+}
+
+type WarehouseUpdateInput struct {
+	ID			int
+	Name		string
+	Priority	int
+	Location	*entity.GeoPoint
+}
+
+type CartAddItemInput struct {
+	UserID		int
+	ProductID	int
+	Quantity	int
+}
+
+type CartUpdateItemInput struct {
+	UserID		int
+	ProductID	int
+	Quantity	int
+}
+
+// CartCheckoutInput - оформление корзины. ShipTo может быть не задан, тогда
+// склады выбираются без учета адреса доставки.
+type CartCheckoutInput struct {
+	UserID		int
+	ShipTo		*entity.GeoPoint
+}
+
+type WalletDepositInput struct {
+	UserID	int
+	Amount	float64
+}
+
+type WalletWithdrawInput struct {
+	UserID	int
+	Amount	float64
+}
+
+type RoleGrantRoleInput struct {
+	UserID	int
+	Role	entity.Role
+}
+
+type RoleRevokeRoleInput struct {
+	UserID	int
+	Role	entity.Role
 }
\ No newline at end of file
@@ -0,0 +1,22 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS purchases (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL,
+    timestamp TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    price DECIMAL(20, 2) NOT NULL
+);
+
+-- Заказы из нескольких строк распадаются на отдельные покупки.
+INSERT INTO purchases (user_id, product_id, quantity, timestamp, price)
+    SELECT o.user_id, oi.product_id, oi.quantity, o.created_at, oi.unit_price
+    FROM order_items oi
+    JOIN orders o ON o.id = oi.order_id
+    ORDER BY oi.id;
+
+DROP TABLE IF EXISTS order_items;
+DROP TABLE IF EXISTS orders;
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,42 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS orders (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    total DECIMAL(20, 2) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS orders_user_id_idx ON orders (user_id);
+
+CREATE TABLE IF NOT EXISTS order_items (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL,
+    product_name TEXT NOT NULL,
+    seller_id INTEGER,
+    unit_price DECIMAL(20, 2) NOT NULL,
+    quantity INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS order_items_order_id_idx ON order_items (order_id);
+CREATE INDEX IF NOT EXISTS order_items_product_id_idx ON order_items (product_id);
+
+-- Каждая покупка становится заказом из одной строки с тем же id: на него
+-- ссылаются проводки журнала. Название и продавец берутся у товара, если он
+-- еще существует.
+INSERT INTO orders (id, user_id, total, created_at)
+    SELECT id, user_id, price * quantity, COALESCE(timestamp, CURRENT_TIMESTAMP)
+    FROM purchases;
+
+INSERT INTO order_items (order_id, product_id, product_name, seller_id, unit_price, quantity)
+    SELECT pu.id, pu.product_id, COALESCE(pr.name, ''), pr.seller_id, pu.price, pu.quantity
+    FROM purchases pu
+    LEFT JOIN products pr ON pr.id = pu.product_id
+    ORDER BY pu.id;
+
+SELECT setval(pg_get_serial_sequence('orders', 'id'), COALESCE((SELECT MAX(id) FROM orders), 0) + 1, false);
+
+DROP TABLE purchases;
+
+COMMIT;
\ No newline at end of file
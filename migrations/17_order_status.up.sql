@@ -0,0 +1,25 @@
+-- Заказы до этой миграции оплачивались сразу при оформлении.
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'paid';
+
+ALTER TABLE orders
+    ALTER COLUMN status DROP DEFAULT;
+
+ALTER TABLE orders
+    ADD CONSTRAINT orders_status_valid
+    CHECK (status IN ('pending', 'paid', 'shipped', 'delivered', 'cancelled', 'refunded'));
+
+CREATE TABLE IF NOT EXISTS order_status_history (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    from_status TEXT,
+    to_status TEXT NOT NULL,
+    actor_id INTEGER,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS order_status_history_order_id_idx ON order_status_history (order_id);
+
+INSERT INTO order_status_history (order_id, to_status, actor_id, created_at)
+    SELECT id, status, user_id, created_at FROM orders;
\ No newline at end of file
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS refunds (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    actor_id INTEGER,
+    reason TEXT NOT NULL,
+    amount DECIMAL NOT NULL CHECK (amount >= 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS refunds_order_id_idx ON refunds (order_id);
+
+CREATE TABLE IF NOT EXISTS refund_items (
+    id SERIAL PRIMARY KEY,
+    refund_id INTEGER NOT NULL REFERENCES refunds (id) ON DELETE CASCADE,
+    order_item_id INTEGER NOT NULL REFERENCES order_items (id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    amount DECIMAL NOT NULL CHECK (amount >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS refund_items_refund_id_idx ON refund_items (refund_id);
+CREATE INDEX IF NOT EXISTS refund_items_order_item_id_idx ON refund_items (order_item_id);
\ No newline at end of file
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS reservation_items;
+DROP TABLE IF EXISTS reservations;
+
+ALTER TABLE products
+    DROP CONSTRAINT IF EXISTS products_reserved_non_negative;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS reserved;
\ No newline at end of file
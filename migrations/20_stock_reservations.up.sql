@@ -0,0 +1,26 @@
+-- quantity - товар на складе, reserved - его часть, удерживаемая под
+-- неподтвержденные покупки. Доступно для покупки quantity - reserved.
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS reserved INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE products
+    ADD CONSTRAINT products_reserved_non_negative CHECK (reserved >= 0);
+
+CREATE TABLE IF NOT EXISTS reservations (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    status TEXT NOT NULL CHECK (status IN ('active', 'confirmed', 'released', 'expired')),
+    order_id INTEGER REFERENCES orders (id) ON DELETE SET NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS reservations_user_id_idx ON reservations (user_id);
+CREATE INDEX IF NOT EXISTS reservations_active_expires_at_idx ON reservations (expires_at) WHERE status = 'active';
+
+CREATE TABLE IF NOT EXISTS reservation_items (
+    reservation_id INTEGER NOT NULL REFERENCES reservations (id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (reservation_id, product_id)
+);
\ No newline at end of file
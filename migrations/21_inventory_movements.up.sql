@@ -0,0 +1,21 @@
+-- Журнал движения товара. delta - изменение остатка, quantity_after - остаток
+-- после движения, по нему восстанавливается остаток на любой момент.
+CREATE TABLE IF NOT EXISTS inventory_movements (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('restock', 'sale', 'refund', 'adjustment')),
+    delta INTEGER NOT NULL CHECK (delta <> 0),
+    quantity_after INTEGER NOT NULL CHECK (quantity_after >= 0),
+    actor_id INTEGER,
+    reason TEXT NOT NULL DEFAULT '',
+    order_id INTEGER REFERENCES orders (id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS inventory_movements_product_id_created_at_idx ON inventory_movements (product_id, created_at, id);
+
+-- Остатки, накопленные до появления журнала, записываются одним начальным движением.
+INSERT INTO inventory_movements (product_id, kind, delta, quantity_after, reason)
+SELECT id, 'adjustment', quantity, quantity, 'opening balance'
+FROM products
+WHERE quantity > 0;
\ No newline at end of file
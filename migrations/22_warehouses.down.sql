@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS order_item_allocations;
+
+ALTER TABLE inventory_movements
+    DROP COLUMN IF EXISTS warehouse_id;
+
+DROP TABLE IF EXISTS warehouse_stock;
+DROP TABLE IF EXISTS warehouses;
\ No newline at end of file
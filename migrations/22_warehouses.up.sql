@@ -0,0 +1,46 @@
+-- Склады. Чем больше priority, тем раньше склад выбирается при отгрузке и
+-- при поступлении товара без указания склада.
+CREATE TABLE IF NOT EXISTS warehouses (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    priority INTEGER NOT NULL DEFAULT 0,
+    latitude DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90),
+    longitude DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((latitude IS NULL) = (longitude IS NULL))
+);
+
+INSERT INTO warehouses (name) VALUES ('main') ON CONFLICT (name) DO NOTHING;
+
+-- Остатки по складам. products.quantity - их сумма.
+CREATE TABLE IF NOT EXISTS warehouse_stock (
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses (id),
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK (quantity >= 0),
+    PRIMARY KEY (warehouse_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS warehouse_stock_product_id_idx ON warehouse_stock (product_id);
+
+INSERT INTO warehouse_stock (warehouse_id, product_id, quantity)
+SELECT w.id, p.id, p.quantity
+FROM products p
+CROSS JOIN warehouses w
+WHERE w.name = 'main' AND p.quantity > 0;
+
+ALTER TABLE inventory_movements
+    ADD COLUMN IF NOT EXISTS warehouse_id INTEGER REFERENCES warehouses (id);
+
+UPDATE inventory_movements
+SET warehouse_id = (SELECT id FROM warehouses WHERE name = 'main');
+
+-- С каких складов отгружена строка заказа. returned - сколько единиц уже
+-- вернулось на этот склад при отменах и возвратах.
+CREATE TABLE IF NOT EXISTS order_item_allocations (
+    order_item_id INTEGER NOT NULL REFERENCES order_items (id) ON DELETE CASCADE,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses (id),
+    position INTEGER NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    returned INTEGER NOT NULL DEFAULT 0 CHECK (returned >= 0 AND returned <= quantity),
+    PRIMARY KEY (order_item_id, warehouse_id)
+);
\ No newline at end of file
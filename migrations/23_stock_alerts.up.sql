@@ -0,0 +1,22 @@
+-- Порог дозаказа: когда остаток товара опускается до него, продавец получает
+-- предупреждение. NULL - порог не задан.
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS reorder_threshold INTEGER CHECK (reorder_threshold >= 0);
+
+-- Предупреждения о низком остатке. Открытое (resolved_at IS NULL)
+-- предупреждение у товара одно: новое появляется, только когда остаток
+-- поднялся выше порога и снова опустился. notified_at - когда предупреждение
+-- забрали на отправку.
+CREATE TABLE IF NOT EXISTS stock_alerts (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    threshold INTEGER NOT NULL,
+    quantity INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    notified_at TIMESTAMP WITH TIME ZONE,
+    resolved_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS stock_alerts_open_product_id_idx ON stock_alerts (product_id) WHERE resolved_at IS NULL;
+CREATE INDEX IF NOT EXISTS stock_alerts_pending_idx ON stock_alerts (id) WHERE notified_at IS NULL;
+CREATE INDEX IF NOT EXISTS stock_alerts_product_id_idx ON stock_alerts (product_id, created_at);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_price_positive;
\ No newline at end of file
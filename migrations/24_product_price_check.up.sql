@@ -0,0 +1,8 @@
+-- Цена товара должна быть положительной: с отрицательной ценой покупка
+-- пополняла бы баланс покупателя. Раньше отрицательную цену можно было
+-- задать, поэтому такие цены сначала исправляются: знак минус почти наверняка
+-- опечатка, а нулевая цена поднимается до минимальной.
+UPDATE products SET price = GREATEST(ABS(price), 0.01) WHERE price <= 0;
+
+ALTER TABLE products
+    ADD CONSTRAINT products_price_positive CHECK (price > 0);
\ No newline at end of file
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS sessions_handle_idx;
+
+ALTER TABLE sessions
+    DROP COLUMN IF EXISTS handle,
+    DROP COLUMN IF EXISTS previous_refresh_token_hash;
\ No newline at end of file
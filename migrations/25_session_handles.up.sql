@@ -0,0 +1,13 @@
+-- previous_refresh_token_hash - хэш refresh-токена, замененного при последнем
+-- обновлении: только его повторное предъявление считается кражей токена.
+-- handle - непрозрачный идентификатор сессии для токенов доступа, чтобы id
+-- сессии, из которого состоит refresh-токен, не попадал в JWT.
+ALTER TABLE sessions
+    ADD COLUMN IF NOT EXISTS previous_refresh_token_hash TEXT,
+    ADD COLUMN IF NOT EXISTS handle TEXT;
+
+UPDATE sessions SET handle = md5(random()::text || id) WHERE handle IS NULL;
+
+ALTER TABLE sessions ALTER COLUMN handle SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS sessions_handle_idx ON sessions (handle);
\ No newline at end of file
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS stock_alerts_pending_idx;
+CREATE INDEX IF NOT EXISTS stock_alerts_pending_idx ON stock_alerts (id) WHERE notified_at IS NULL;
+
+ALTER TABLE stock_alerts
+    DROP COLUMN IF EXISTS next_attempt_at,
+    DROP COLUMN IF EXISTS attempts;
\ No newline at end of file
@@ -0,0 +1,10 @@
+-- Предупреждение отмечается отправленным (notified_at) только после успешной
+-- доставки. attempts - число попыток, next_attempt_at - когда предупреждение
+-- можно забрать на отправку снова: после неудачи или если отправитель
+-- забрал его и не отчитался.
+ALTER TABLE stock_alerts
+    ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+DROP INDEX IF EXISTS stock_alerts_pending_idx;
+CREATE INDEX IF NOT EXISTS stock_alerts_pending_idx ON stock_alerts (next_attempt_at) WHERE notified_at IS NULL;
\ No newline at end of file
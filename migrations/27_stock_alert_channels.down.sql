@@ -0,0 +1 @@
+ALTER TABLE stock_alerts DROP COLUMN IF EXISTS delivered_channels;
\ No newline at end of file
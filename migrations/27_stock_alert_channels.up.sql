@@ -0,0 +1,5 @@
+-- delivered_channels - способы, которыми предупреждение уже доставлено. При
+-- повторной попытке отправка идет только теми способами, которыми доставить
+-- не удалось, чтобы продавец не получал одно предупреждение несколько раз.
+ALTER TABLE stock_alerts
+    ADD COLUMN IF NOT EXISTS delivered_channels TEXT[] NOT NULL DEFAULT '{}';
\ No newline at end of file
@@ -0,0 +1,4 @@
+UPDATE products SET quantity = 0 WHERE quantity < 0;
+
+ALTER TABLE products
+    ADD CONSTRAINT products_quantity_non_negative CHECK (quantity >= 0);
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_balance_non_negative;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS balance;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS balance DECIMAL(20, 2) NOT NULL DEFAULT 0;
+
+ALTER TABLE users
+    ADD CONSTRAINT users_balance_non_negative CHECK (balance >= 0);
\ No newline at end of file
@@ -0,0 +1,61 @@
+CREATE TABLE IF NOT EXISTS ledger_accounts (
+    id SERIAL PRIMARY KEY,
+    type TEXT NOT NULL,
+    owner_id INTEGER NOT NULL DEFAULT 0,
+    UNIQUE (type, owner_id)
+);
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id SERIAL PRIMARY KEY,
+    kind TEXT NOT NULL,
+    reference_id INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS ledger_postings (
+    id SERIAL PRIMARY KEY,
+    entry_id INTEGER NOT NULL REFERENCES ledger_entries (id),
+    account_id INTEGER NOT NULL REFERENCES ledger_accounts (id),
+    amount BIGINT NOT NULL CHECK (amount <> 0)
+);
+
+CREATE INDEX IF NOT EXISTS ledger_postings_account_id_idx ON ledger_postings (account_id);
+
+CREATE OR REPLACE FUNCTION ledger_forbid_modification() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'ledger is append-only: % on % is not allowed', TG_OP, TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER ledger_entries_append_only
+    BEFORE UPDATE OR DELETE ON ledger_entries
+    FOR EACH ROW EXECUTE FUNCTION ledger_forbid_modification();
+
+CREATE TRIGGER ledger_postings_append_only
+    BEFORE UPDATE OR DELETE ON ledger_postings
+    FOR EACH ROW EXECUTE FUNCTION ledger_forbid_modification();
+
+-- Входящие остатки: переносим уже накопленные балансы кошельков в журнал.
+INSERT INTO ledger_accounts (type, owner_id) VALUES ('external', 0)
+    ON CONFLICT (type, owner_id) DO NOTHING;
+
+INSERT INTO ledger_accounts (type, owner_id)
+    SELECT 'user_wallet', id FROM users WHERE balance > 0
+    ON CONFLICT (type, owner_id) DO NOTHING;
+
+DO $$
+DECLARE
+    u RECORD;
+    entry INTEGER;
+BEGIN
+    FOR u IN SELECT id, balance FROM users WHERE balance > 0 LOOP
+        INSERT INTO ledger_entries (kind, reference_id) VALUES ('opening_balance', u.id) RETURNING id INTO entry;
+
+        INSERT INTO ledger_postings (entry_id, account_id, amount)
+            SELECT entry, id, ROUND(u.balance * 100)::BIGINT FROM ledger_accounts WHERE type = 'external' AND owner_id = 0;
+
+        INSERT INTO ledger_postings (entry_id, account_id, amount)
+            SELECT entry, id, -ROUND(u.balance * 100)::BIGINT FROM ledger_accounts WHERE type = 'user_wallet' AND owner_id = u.id;
+    END LOOP;
+END;
+$$;
\ No newline at end of file
@@ -0,0 +1 @@
+DELETE FROM user_roles WHERE role = 'buyer';
\ No newline at end of file
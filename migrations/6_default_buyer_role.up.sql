@@ -0,0 +1,3 @@
+INSERT INTO user_roles (user_id, role)
+    SELECT id, 'buyer' FROM users
+    ON CONFLICT DO NOTHING;
\ No newline at end of file
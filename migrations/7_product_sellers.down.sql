@@ -0,0 +1,7 @@
+ALTER TABLE purchases
+    DROP COLUMN IF EXISTS price;
+
+DROP INDEX IF EXISTS products_seller_id_idx;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS seller_id;
\ No newline at end of file
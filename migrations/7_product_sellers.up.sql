@@ -0,0 +1,17 @@
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS seller_id INTEGER REFERENCES users (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS products_seller_id_idx ON products (seller_id);
+
+ALTER TABLE purchases
+    ADD COLUMN IF NOT EXISTS price DECIMAL(20, 2);
+
+UPDATE purchases pu
+    SET price = pr.price
+    FROM products pr
+    WHERE pr.id = pu.product_id AND pu.price IS NULL;
+
+UPDATE purchases SET price = 0 WHERE price IS NULL;
+
+ALTER TABLE purchases
+    ALTER COLUMN price SET NOT NULL;
\ No newline at end of file
@@ -1,20 +1,42 @@
 package hasher
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrUnknownHashFormat  = errors.New("unknown password hash format")
+	ErrMismatchedPassword = errors.New("password does not match hash")
+)
 
 type PasswordHasher interface {
 	HashPassword(password string) (string, error)
 	VerifyPassword(savedHash, inputPassword string) error
+	// NeedsRehash сообщает, что хэш получен не предпочтительным алгоритмом
+	// или с устаревшими параметрами и его стоит пересчитать при входе.
+	NeedsRehash(savedHash string) bool
+}
+
+// Algorithm - отдельный алгоритм хэширования. Хэши самоописываемы: алгоритм
+// узнает свои по префиксу, поэтому в базе могут соседствовать разные форматы.
+type Algorithm interface {
+	PasswordHasher
+	Recognizes(savedHash string) bool
 }
 
-type BcryptHasher struct{}
+type BcryptHasher struct {
+	cost int
+}
 
 func NewBcryptHasher() *BcryptHasher {
-	return &BcryptHasher{}
+	return &BcryptHasher{cost: bcrypt.DefaultCost}
 }
 
 func (h *BcryptHasher) HashPassword(password string) (string, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
 	if err != nil {
 		return "", err
 	}
@@ -24,3 +46,45 @@ func (h *BcryptHasher) HashPassword(password string) (string, error) {
 func (h *BcryptHasher) VerifyPassword(hashedPassword, password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 }
+
+func (h *BcryptHasher) NeedsRehash(savedHash string) bool {
+	cost, err := bcrypt.Cost([]byte(savedHash))
+	return err != nil || cost < h.cost
+}
+
+func (h *BcryptHasher) Recognizes(savedHash string) bool {
+	return strings.HasPrefix(savedHash, "$2a$") ||
+		strings.HasPrefix(savedHash, "$2b$") ||
+		strings.HasPrefix(savedHash, "$2y$")
+}
+
+// MultiHasher хэширует новые пароли предпочтительным алгоритмом, а проверяет
+// хэши любым из известных ему алгоритмов.
+type MultiHasher struct {
+	preferred  Algorithm
+	algorithms []Algorithm
+}
+
+func NewMultiHasher(preferred Algorithm, legacy ...Algorithm) *MultiHasher {
+	return &MultiHasher{
+		preferred:  preferred,
+		algorithms: append([]Algorithm{preferred}, legacy...),
+	}
+}
+
+func (h *MultiHasher) HashPassword(password string) (string, error) {
+	return h.preferred.HashPassword(password)
+}
+
+func (h *MultiHasher) VerifyPassword(savedHash, inputPassword string) error {
+	for _, algorithm := range h.algorithms {
+		if algorithm.Recognizes(savedHash) {
+			return algorithm.VerifyPassword(savedHash, inputPassword)
+		}
+	}
+	return ErrUnknownHashFormat
+}
+
+func (h *MultiHasher) NeedsRehash(savedHash string) bool {
+	return !h.preferred.Recognizes(savedHash) || h.preferred.NeedsRehash(savedHash)
+}
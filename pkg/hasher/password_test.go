@@ -0,0 +1,87 @@
+package hasher
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var testArgon2Params = Argon2Params{
+	Memory:      1024,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+func TestArgon2Hasher(t *testing.T) {
+	h := NewArgon2Hasher(testArgon2Params)
+
+	hash, err := h.HashPassword("Qwerty1!")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Errorf("HashPassword() = %q, want PHC argon2id format", hash)
+	}
+
+	if err = h.VerifyPassword(hash, "Qwerty1!"); err != nil {
+		t.Errorf("VerifyPassword() with correct password: error = %v", err)
+	}
+
+	if err = h.VerifyPassword(hash, "wrong"); !errors.Is(err, ErrMismatchedPassword) {
+		t.Errorf("VerifyPassword() with wrong password: error = %v, want %v", err, ErrMismatchedPassword)
+	}
+
+	if h.NeedsRehash(hash) {
+		t.Errorf("NeedsRehash() = true for hash with current params")
+	}
+
+	stronger := testArgon2Params
+	stronger.Iterations = 2
+	if !NewArgon2Hasher(stronger).NeedsRehash(hash) {
+		t.Errorf("NeedsRehash() = false for hash with outdated params")
+	}
+
+	if err = h.VerifyPassword("$argon2id$v=19$garbage", "Qwerty1!"); !errors.Is(err, ErrUnknownHashFormat) {
+		t.Errorf("VerifyPassword() with malformed hash: error = %v, want %v", err, ErrUnknownHashFormat)
+	}
+}
+
+func TestMultiHasher(t *testing.T) {
+	argon := NewArgon2Hasher(testArgon2Params)
+	h := NewMultiHasher(argon, NewBcryptHasher())
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte("Qwerty1!"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("bcrypt.GenerateFromPassword() error = %v", err)
+	}
+
+	if err = h.VerifyPassword(string(legacy), "Qwerty1!"); err != nil {
+		t.Errorf("VerifyPassword() with bcrypt hash: error = %v", err)
+	}
+
+	if !h.NeedsRehash(string(legacy)) {
+		t.Errorf("NeedsRehash() = false for bcrypt hash when argon2id is preferred")
+	}
+
+	hash, err := h.HashPassword("Qwerty1!")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+
+	if !argon.Recognizes(hash) {
+		t.Errorf("HashPassword() = %q, want argon2id hash", hash)
+	}
+
+	if h.NeedsRehash(hash) {
+		t.Errorf("NeedsRehash() = true for hash from preferred algorithm")
+	}
+
+	if err = h.VerifyPassword("plaintext", "plaintext"); !errors.Is(err, ErrUnknownHashFormat) {
+		t.Errorf("VerifyPassword() with unknown format: error = %v, want %v", err, ErrUnknownHashFormat)
+	}
+}
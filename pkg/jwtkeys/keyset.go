@@ -0,0 +1,124 @@
+// Package jwtkeys хранит набор ключей подписи JWT. Один ключ активен и
+// подписывает новые токены, остальные только проверяют ранее выданные, что
+// позволяет менять ключи, не инвалидируя токены в обороте. Ключ выбирается по
+// заголовку kid.
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var (
+	ErrUnsupportedKey    = errors.New("unsupported key type")
+	ErrKeyCannotSign     = errors.New("active key has no private part")
+	ErrDuplicateKeyID    = errors.New("duplicate key id")
+	ErrUnknownKey        = errors.New("unknown key id")
+	ErrAlgorithmMismatch = errors.New("token algorithm does not match key")
+)
+
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// NewHMACKey создает симметричный ключ HS256. Такой ключ не публикуется в JWKS.
+func NewHMACKey(id string, secret []byte) Key {
+	return Key{
+		ID:        id,
+		Method:    jwt.SigningMethodHS256,
+		signKey:   secret,
+		verifyKey: secret,
+	}
+}
+
+// NewKey создает ключ подписи по закрытому ключу RSA (RS256) или Ed25519 (EdDSA).
+func NewKey(id string, private crypto.Signer) (Key, error) {
+	key, err := NewVerificationKey(id, private.Public())
+	if err != nil {
+		return Key{}, err
+	}
+
+	key.signKey = private
+	return key, nil
+}
+
+// NewVerificationKey создает ключ, который только проверяет подпись. Так
+// подключаются выведенные из оборота ключи, закрытая часть которых уже удалена.
+func NewVerificationKey(id string, public crypto.PublicKey) (Key, error) {
+	switch public.(type) {
+	case *rsa.PublicKey:
+		return Key{ID: id, Method: jwt.SigningMethodRS256, verifyKey: public}, nil
+	case ed25519.PublicKey:
+		return Key{ID: id, Method: jwt.SigningMethodEdDSA, verifyKey: public}, nil
+	}
+
+	return Key{}, fmt.Errorf("%w: %T", ErrUnsupportedKey, public)
+}
+
+func (k Key) CanSign() bool {
+	return k.signKey != nil
+}
+
+type KeySet struct {
+	active Key
+	keys   map[string]Key
+}
+
+// NewKeySet собирает набор из активного ключа и ключей, которые только
+// проверяют подпись.
+func NewKeySet(active Key, others ...Key) (*KeySet, error) {
+	if !active.CanSign() {
+		return nil, fmt.Errorf("%w: %q", ErrKeyCannotSign, active.ID)
+	}
+
+	s := &KeySet{
+		active: active,
+		keys:   map[string]Key{active.ID: active},
+	}
+
+	for _, key := range others {
+		if _, ok := s.keys[key.ID]; ok {
+			return nil, fmt.Errorf("%w: %q", ErrDuplicateKeyID, key.ID)
+		}
+		s.keys[key.ID] = key
+	}
+
+	return s, nil
+}
+
+// Sign подписывает claims активным ключом и проставляет его kid.
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.active.Method, claims)
+	if s.active.ID != "" {
+		token.Header["kid"] = s.active.ID
+	}
+
+	return token.SignedString(s.active.signKey)
+}
+
+// Keyfunc подбирает ключ проверки по kid токена для jwt.Parse. Токены без kid
+// проверяются ключом с пустым ID, если он есть в наборе. Алгоритм токена
+// обязан совпадать с алгоритмом ключа.
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("%w: %s", ErrAlgorithmMismatch, token.Method.Alg())
+	}
+
+	return key.verifyKey, nil
+}
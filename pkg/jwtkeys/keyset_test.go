@@ -0,0 +1,240 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func newEd25519Key(t *testing.T, id string) Key {
+	t.Helper()
+
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("ed25519.GenerateKey() error = %v", err)
+	}
+
+	key, err := NewKey(id, private)
+	if err != nil {
+		t.Fatalf("NewKey() error = %v", err)
+	}
+	return key
+}
+
+func newRSAKey(t *testing.T, id string) (Key, *rsa.PrivateKey) {
+	t.Helper()
+
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey() error = %v", err)
+	}
+
+	key, err := NewKey(id, private)
+	if err != nil {
+		t.Fatalf("NewKey() error = %v", err)
+	}
+	return key, private
+}
+
+func parse(s *KeySet, token string) error {
+	_, err := jwt.Parse(token, s.Keyfunc)
+	return err
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	oldKey := newEd25519Key(t, "2024-01")
+	newKey, _ := newRSAKey(t, "2024-02")
+
+	before, err := NewKeySet(oldKey)
+	if err != nil {
+		t.Fatalf("NewKeySet() error = %v", err)
+	}
+
+	oldToken, err := before.Sign(jwt.MapClaims{"sub": "1"})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	// Новый ключ становится активным, старый остается только для проверки.
+	oldPublic, err := NewVerificationKey(oldKey.ID, oldKey.verifyKey)
+	if err != nil {
+		t.Fatalf("NewVerificationKey() error = %v", err)
+	}
+
+	after, err := NewKeySet(newKey, oldPublic)
+	if err != nil {
+		t.Fatalf("NewKeySet() error = %v", err)
+	}
+
+	if err = parse(after, oldToken); err != nil {
+		t.Errorf("token signed by retired key: error = %v", err)
+	}
+
+	newToken, err := after.Sign(jwt.MapClaims{"sub": "1"})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	token, err := jwt.Parse(newToken, after.Keyfunc)
+	if err != nil {
+		t.Fatalf("token signed by active key: error = %v", err)
+	}
+	if token.Header["kid"] != "2024-02" || token.Method.Alg() != "RS256" {
+		t.Errorf("header = %v, want kid 2024-02 and RS256", token.Header)
+	}
+
+	if err = parse(before, newToken); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("token with unknown kid: error = %v, want %v", err, ErrUnknownKey)
+	}
+}
+
+func TestKeySet_AlgorithmMismatch(t *testing.T) {
+	key, private := newRSAKey(t, "rsa")
+
+	s, err := NewKeySet(key)
+	if err != nil {
+		t.Fatalf("NewKeySet() error = %v", err)
+	}
+
+	// Подпись HS256 открытым ключом RSA в качестве секрета не должна проходить.
+	publicDER, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	if err != nil {
+		t.Fatalf("x509.MarshalPKIXPublicKey() error = %v", err)
+	}
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "1"})
+	forged.Header["kid"] = "rsa"
+	forgedToken, err := forged.SignedString(publicDER)
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+
+	if err = parse(s, forgedToken); !errors.Is(err, ErrAlgorithmMismatch) {
+		t.Errorf("error = %v, want %v", err, ErrAlgorithmMismatch)
+	}
+}
+
+func TestNewKeySet_Errors(t *testing.T) {
+	key := newEd25519Key(t, "a")
+
+	public, err := NewVerificationKey("b", key.verifyKey)
+	if err != nil {
+		t.Fatalf("NewVerificationKey() error = %v", err)
+	}
+
+	if _, err = NewKeySet(public); !errors.Is(err, ErrKeyCannotSign) {
+		t.Errorf("verification key as active: error = %v, want %v", err, ErrKeyCannotSign)
+	}
+
+	if _, err = NewKeySet(key, newEd25519Key(t, "a")); !errors.Is(err, ErrDuplicateKeyID) {
+		t.Errorf("duplicate kid: error = %v, want %v", err, ErrDuplicateKeyID)
+	}
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	rsaKey, _ := newRSAKey(t, "rsa")
+
+	s, err := NewKeySet(rsaKey, newEd25519Key(t, "ed"), NewHMACKey("", []byte("secret")))
+	if err != nil {
+		t.Fatalf("NewKeySet() error = %v", err)
+	}
+
+	set := s.JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("JWKS() has %d keys, want 2 (HMAC key must not be published)", len(set.Keys))
+	}
+
+	ed, rs := set.Keys[0], set.Keys[1]
+	if ed.Kid != "ed" || ed.Kty != "OKP" || ed.Crv != "Ed25519" || ed.Alg != "EdDSA" || ed.X == "" {
+		t.Errorf("Ed25519 JWK = %+v", ed)
+	}
+	if rs.Kid != "rsa" || rs.Kty != "RSA" || rs.Alg != "RS256" || rs.N == "" || rs.E != "AQAB" {
+		t.Errorf("RSA JWK = %+v", rs)
+	}
+
+	// Опубликованные ключи восстанавливаются и проверяют подпись набора.
+	for _, jwk := range set.Keys {
+		public, err := jwk.PublicKey()
+		if err != nil {
+			t.Fatalf("PublicKey(%s) error = %v", jwk.Kid, err)
+		}
+		if _, err = NewVerificationKey(jwk.Kid, public); err != nil {
+			t.Errorf("NewVerificationKey(%s) error = %v", jwk.Kid, err)
+		}
+	}
+	if !rsaKey.verifyKey.(*rsa.PublicKey).Equal(mustPublicKey(t, rs)) {
+		t.Errorf("PublicKey() of RSA JWK does not match the original key")
+	}
+
+	if _, err = (JWK{Kty: "EC"}).PublicKey(); !errors.Is(err, ErrUnsupportedKey) {
+		t.Errorf("PublicKey() for EC error = %v, want %v", err, ErrUnsupportedKey)
+	}
+}
+
+func mustPublicKey(t *testing.T, jwk JWK) crypto.PublicKey {
+	t.Helper()
+
+	public, err := jwk.PublicKey()
+	if err != nil {
+		t.Fatalf("PublicKey() error = %v", err)
+	}
+	return public
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("ed25519.GenerateKey() error = %v", err)
+	}
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatalf("x509.MarshalPKCS8PrivateKey() error = %v", err)
+	}
+	writePEM(t, filepath.Join(dir, "active.pem"), "PRIVATE KEY", privateDER)
+
+	rsaPrivate, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey() error = %v", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&rsaPrivate.PublicKey)
+	if err != nil {
+		t.Fatalf("x509.MarshalPKIXPublicKey() error = %v", err)
+	}
+	writePEM(t, filepath.Join(dir, "retired.pem"), "PUBLIC KEY", publicDER)
+
+	s, err := LoadDir(dir, "active")
+	if err != nil {
+		t.Fatalf("LoadDir() error = %v", err)
+	}
+
+	if len(s.JWKS().Keys) != 2 {
+		t.Errorf("JWKS() has %d keys, want 2", len(s.JWKS().Keys))
+	}
+
+	if _, err = LoadDir(dir, "retired"); !errors.Is(err, ErrKeyCannotSign) {
+		t.Errorf("public key as active: error = %v, want %v", err, ErrKeyCannotSign)
+	}
+
+	if _, err = LoadDir(dir, "missing"); err == nil {
+		t.Errorf("missing active key: error = nil")
+	}
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("os.WriteFile() error = %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoadDir читает ключи из PEM-файлов каталога dir. Идентификатором ключа
+// служит имя файла без расширения .pem. Файл может содержать закрытый ключ
+// (PKCS#8 или PKCS#1) или только открытый (PKIX) - тогда ключ лишь проверяет
+// подпись. Активным становится ключ activeID, extra добавляются к набору как есть.
+func LoadDir(dir, activeID string, extra ...Key) (*KeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("jwtkeys.LoadDir - filepath.Glob: %w", err)
+	}
+
+	var (
+		active Key
+		found  bool
+		others = extra
+	)
+	for _, path := range paths {
+		id := strings.TrimSuffix(filepath.Base(path), ".pem")
+
+		key, err := loadKey(id, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if id == activeID {
+			active, found = key, true
+			continue
+		}
+		others = append(others, key)
+	}
+
+	if !found {
+		return nil, fmt.Errorf("jwtkeys.LoadDir: active key %q not found in %s", activeID, dir)
+	}
+
+	return NewKeySet(active, others...)
+}
+
+func loadKey(id, path string) (Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Key{}, fmt.Errorf("jwtkeys.loadKey - os.ReadFile: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Key{}, fmt.Errorf("jwtkeys.loadKey: %s is not a PEM file", path)
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return Key{}, fmt.Errorf("jwtkeys.loadKey: %s: unexpected PEM block %q", path, block.Type)
+	}
+	if err != nil {
+		return Key{}, fmt.Errorf("jwtkeys.loadKey: %s: %w", path, err)
+	}
+
+	if signer, ok := parsed.(crypto.Signer); ok {
+		return NewKey(id, signer)
+	}
+	return NewVerificationKey(id, parsed)
+}
@@ -0,0 +1,54 @@
+// Package mailer отправляет письма пользователям. Реализация выбирается при
+// запуске: SMTP для боевого окружения, файлы или память для разработки и тестов.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// format собирает письмо в формате RFC 5322 с текстовым телом в UTF-8.
+func format(from string, msg Message) []byte {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	return []byte(b.String())
+}
+
+// validateHeader отклоняет значения заголовков с переводами строк, через
+// которые можно внедрить собственные заголовки.
+func validateHeader(name, value string) error {
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("mailer: invalid %s header", name)
+	}
+	return nil
+}
+
+func validate(msg Message) error {
+	if err := validateHeader("To", msg.To); err != nil {
+		return err
+	}
+	return validateHeader("Subject", msg.Subject)
+}
@@ -0,0 +1,315 @@
+// Package oidc реализует вход через внешнего провайдера OpenID Connect по
+// схеме authorization code с PKCE (RFC 7636): обнаружение провайдера, ссылку
+// на авторизацию, обмен кода на токены и проверку ID-токена по JWKS
+// провайдера. Метаданные провайдера запрашиваются при первом обращении, чтобы
+// недоступность провайдера не мешала запуску сервиса.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/cripplemymind9/go-market/pkg/jwtkeys"
+)
+
+var (
+	ErrDiscovery      = errors.New("oidc: provider discovery failed")
+	ErrExchange       = errors.New("oidc: code exchange failed")
+	ErrInvalidIDToken = errors.New("oidc: invalid id token")
+)
+
+// keysRefreshInterval ограничивает повторную загрузку JWKS при неизвестном
+// kid, чтобы поддельные токены не превращались в запросы к провайдеру.
+const keysRefreshInterval = time.Minute
+
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	// Scopes запрашиваются вместе с обязательным openid.
+	Scopes     []string
+	HTTPClient *http.Client
+}
+
+// Metadata - нужная клиенту часть документа обнаружения провайдера.
+type Metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Claims - утверждения ID-токена о пользователе.
+type Claims struct {
+	jwt.RegisteredClaims
+	Nonce             string `json:"nonce"`
+	AuthorizedParty   string `json:"azp"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+}
+
+type Tokens struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+}
+
+type Client struct {
+	cfg        Config
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu            sync.Mutex
+	metadata      *Metadata
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+}
+
+func NewClient(cfg Config) *Client {
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &Client{
+		cfg:        cfg,
+		httpClient: httpClient,
+		now:        time.Now,
+	}
+}
+
+// NewCodeVerifier выпускает случайный code_verifier для PKCE.
+func NewCodeVerifier() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CodeChallengeS256 вычисляет code_challenge по методу S256.
+func CodeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL возвращает ссылку на страницу входа провайдера.
+func (c *Client) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	metadata, err := c.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	scopes := []string{"openid"}
+	for _, scope := range c.cfg.Scopes {
+		if scope != "openid" {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {c.cfg.ClientID},
+		"redirect_uri":          {c.cfg.RedirectURL},
+		"scope":                 {strings.Join(scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return metadata.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange обменивает код авторизации на токены провайдера.
+func (c *Client) Exchange(ctx context.Context, code, codeVerifier string) (Tokens, error) {
+	metadata, err := c.discover(ctx)
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {c.cfg.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Tokens{}, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(c.cfg.ClientID), url.QueryEscape(c.cfg.ClientSecret))
+
+	var tokens Tokens
+	if err = c.doJSON(req, &tokens); err != nil {
+		return Tokens{}, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+
+	if tokens.IDToken == "" {
+		return Tokens{}, fmt.Errorf("%w: response has no id_token", ErrExchange)
+	}
+
+	return tokens, nil
+}
+
+// VerifyIDToken проверяет подпись, издателя, получателя, срок действия и
+// nonce ID-токена.
+func (c *Client) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (Claims, error) {
+	metadata, err := c.discover(ctx)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	claims := &Claims{}
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{
+		jwt.SigningMethodRS256.Alg(),
+		jwt.SigningMethodEdDSA.Alg(),
+	}))
+	_, err = parser.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return c.publicKey(ctx, kid)
+	})
+	if err != nil {
+		return Claims{}, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	switch {
+	case !claims.VerifyIssuer(metadata.Issuer, true):
+		return Claims{}, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidIDToken, claims.Issuer)
+	case !claims.VerifyAudience(c.cfg.ClientID, true):
+		return Claims{}, fmt.Errorf("%w: token is not issued for this client", ErrInvalidIDToken)
+	case len(claims.Audience) > 1 && claims.AuthorizedParty != c.cfg.ClientID:
+		return Claims{}, fmt.Errorf("%w: unexpected authorized party %q", ErrInvalidIDToken, claims.AuthorizedParty)
+	case claims.ExpiresAt == nil:
+		return Claims{}, fmt.Errorf("%w: token has no expiry", ErrInvalidIDToken)
+	case claims.Subject == "":
+		return Claims{}, fmt.Errorf("%w: token has no subject", ErrInvalidIDToken)
+	case subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1:
+		return Claims{}, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+
+	return *claims, nil
+}
+
+func (c *Client) discover(ctx context.Context) (*Metadata, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.metadata != nil {
+		return c.metadata, nil
+	}
+
+	endpoint := strings.TrimSuffix(c.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDiscovery, err)
+	}
+
+	var metadata Metadata
+	if err = c.doJSON(req, &metadata); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDiscovery, err)
+	}
+
+	if metadata.Issuer != c.cfg.Issuer {
+		return nil, fmt.Errorf("%w: issuer %q does not match configured %q", ErrDiscovery, metadata.Issuer, c.cfg.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: incomplete provider metadata", ErrDiscovery)
+	}
+
+	c.metadata = &metadata
+	return c.metadata, nil
+}
+
+// publicKey возвращает ключ провайдера по kid. Неизвестный kid означает, что
+// провайдер мог сменить ключи, поэтому JWKS перечитывается, но не чаще
+// keysRefreshInterval.
+func (c *Client) publicKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if key, ok := c.keys[kid]; ok {
+		return key, nil
+	}
+
+	if c.keys != nil && c.now().Sub(c.keysFetchedAt) < keysRefreshInterval {
+		return nil, fmt.Errorf("%w: %q", jwtkeys.ErrUnknownKey, kid)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.metadata.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var set jwtkeys.JWKSet
+	if err = c.doJSON(req, &set); err != nil {
+		return nil, fmt.Errorf("fetch jwks: %v", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		public, err := jwk.PublicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = public
+	}
+
+	c.keys = keys
+	c.keysFetchedAt = c.now()
+
+	key, ok := c.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", jwtkeys.ErrUnknownKey, kid)
+	}
+
+	return key, nil
+}
+
+func (c *Client) doJSON(req *http.Request, v interface{}) error {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: status %d: %s", req.Method, req.URL.Redacted(), resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return json.Unmarshal(body, v)
+}
@@ -0,0 +1,218 @@
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/cripplemymind9/go-market/pkg/oidc"
+	"github.com/cripplemymind9/go-market/pkg/oidc/oidctest"
+)
+
+const redirectURL = "http://localhost:8080/auth/oidc/callback"
+
+func newProvider(t *testing.T) *oidctest.Provider {
+	t.Helper()
+
+	provider, err := oidctest.NewProvider("go-market", "client-secret")
+	if err != nil {
+		t.Fatalf("oidctest.NewProvider() error = %v", err)
+	}
+	t.Cleanup(provider.Close)
+
+	provider.SetUser(oidctest.User{
+		Subject:           "employee-42",
+		Email:             "alice@company.example",
+		EmailVerified:     true,
+		PreferredUsername: "alice",
+	})
+
+	return provider
+}
+
+func newClient(provider *oidctest.Provider) *oidc.Client {
+	return oidc.NewClient(oidc.Config{
+		Issuer:       provider.Issuer(),
+		ClientID:     provider.ClientID,
+		ClientSecret: provider.ClientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"openid", "email", "profile"},
+	})
+}
+
+// authorize проходит по ссылке входа и возвращает код и state из
+// перенаправления обратно на клиент.
+func authorize(t *testing.T, authURL string) (code, state string) {
+	t.Helper()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("GET %s error = %v", authURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize status = %d, want %d", resp.StatusCode, http.StatusFound)
+	}
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("invalid redirect: %v", err)
+	}
+
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+func TestClient_AuthorizationCodeFlow(t *testing.T) {
+	ctx := context.Background()
+	provider := newProvider(t)
+	client := newClient(provider)
+
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authURL, err := client.AuthCodeURL(ctx, "state-1", "nonce-1", oidc.CodeChallengeS256(verifier))
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+
+	code, state := authorize(t, authURL)
+	if state != "state-1" || code == "" {
+		t.Fatalf("authorize returned code %q, state %q", code, state)
+	}
+
+	tokens, err := client.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+
+	claims, err := client.VerifyIDToken(ctx, tokens.IDToken, "nonce-1")
+	if err != nil {
+		t.Fatalf("VerifyIDToken() error = %v", err)
+	}
+	if claims.Subject != "employee-42" || claims.Email != "alice@company.example" || !claims.EmailVerified || claims.PreferredUsername != "alice" {
+		t.Errorf("VerifyIDToken() = %+v", claims)
+	}
+
+	// Код одноразовый.
+	if _, err = client.Exchange(ctx, code, verifier); !errors.Is(err, oidc.ErrExchange) {
+		t.Errorf("Exchange() of a used code error = %v, want %v", err, oidc.ErrExchange)
+	}
+
+	// Чужой nonce - признак подмены ответа.
+	if _, err = client.VerifyIDToken(ctx, tokens.IDToken, "nonce-2"); !errors.Is(err, oidc.ErrInvalidIDToken) {
+		t.Errorf("VerifyIDToken() with another nonce error = %v, want %v", err, oidc.ErrInvalidIDToken)
+	}
+}
+
+func TestClient_Exchange_WrongVerifier(t *testing.T) {
+	ctx := context.Background()
+	provider := newProvider(t)
+	client := newClient(provider)
+
+	verifier, _ := oidc.NewCodeVerifier()
+	authURL, err := client.AuthCodeURL(ctx, "state", "nonce", oidc.CodeChallengeS256(verifier))
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+
+	code, _ := authorize(t, authURL)
+
+	other, _ := oidc.NewCodeVerifier()
+	if _, err = client.Exchange(ctx, code, other); !errors.Is(err, oidc.ErrExchange) {
+		t.Errorf("Exchange() with wrong verifier error = %v, want %v", err, oidc.ErrExchange)
+	}
+}
+
+func TestClient_VerifyIDToken_Claims(t *testing.T) {
+	testCases := []struct {
+		name  string
+		hook  func(jwt.MapClaims)
+		valid bool
+	}{
+		{
+			name:  "Valid",
+			hook:  func(jwt.MapClaims) {},
+			valid: true,
+		},
+		{
+			name:  "Expired",
+			hook:  func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() },
+			valid: false,
+		},
+		{
+			name:  "Another audience",
+			hook:  func(c jwt.MapClaims) { c["aud"] = "another-client" },
+			valid: false,
+		},
+		{
+			name:  "Another issuer",
+			hook:  func(c jwt.MapClaims) { c["iss"] = "https://evil.example" },
+			valid: false,
+		},
+		{
+			name:  "Several audiences without azp",
+			hook:  func(c jwt.MapClaims) { c["aud"] = []string{"go-market", "another-client"} },
+			valid: false,
+		},
+		{
+			name:  "No subject",
+			hook:  func(c jwt.MapClaims) { delete(c, "sub") },
+			valid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			provider := newProvider(t)
+			provider.SetClaimsHook(tc.hook)
+			client := newClient(provider)
+
+			verifier, _ := oidc.NewCodeVerifier()
+			authURL, err := client.AuthCodeURL(ctx, "state", "nonce", oidc.CodeChallengeS256(verifier))
+			if err != nil {
+				t.Fatalf("AuthCodeURL() error = %v", err)
+			}
+
+			code, _ := authorize(t, authURL)
+			tokens, err := client.Exchange(ctx, code, verifier)
+			if err != nil {
+				t.Fatalf("Exchange() error = %v", err)
+			}
+
+			_, err = client.VerifyIDToken(ctx, tokens.IDToken, "nonce")
+			if tc.valid && err != nil {
+				t.Errorf("VerifyIDToken() error = %v", err)
+			}
+			if !tc.valid && !errors.Is(err, oidc.ErrInvalidIDToken) {
+				t.Errorf("VerifyIDToken() error = %v, want %v", err, oidc.ErrInvalidIDToken)
+			}
+		})
+	}
+}
+
+func TestClient_Discovery_IssuerMismatch(t *testing.T) {
+	provider := newProvider(t)
+
+	client := oidc.NewClient(oidc.Config{
+		Issuer:      provider.Issuer() + "/",
+		ClientID:    provider.ClientID,
+		RedirectURL: redirectURL,
+	})
+
+	if _, err := client.AuthCodeURL(context.Background(), "state", "nonce", "challenge"); !errors.Is(err, oidc.ErrDiscovery) {
+		t.Errorf("AuthCodeURL() error = %v, want %v", err, oidc.ErrDiscovery)
+	}
+}
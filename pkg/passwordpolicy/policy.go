@@ -0,0 +1,130 @@
+// Package passwordpolicy проверяет пароли на соответствие политике: длина,
+// классы символов и отсутствие в списке распространенных паролей.
+package passwordpolicy
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// BcryptMaxBytes - bcrypt учитывает только первые 72 байта пароля, поэтому
+// более длинные пароли отвергаются, а не обрезаются молча.
+const BcryptMaxBytes = 72
+
+// Rule - идентификатор правила политики.
+type Rule string
+
+const (
+	RuleMinLength Rule = "min_length"
+	RuleMaxLength Rule = "max_length"
+	RuleUppercase Rule = "uppercase"
+	RuleLowercase Rule = "lowercase"
+	RuleDigit     Rule = "digit"
+	RuleSymbol    Rule = "symbol"
+	RuleCommon    Rule = "common"
+)
+
+// Violation - нарушенное правило и сообщение для пользователя.
+type Violation struct {
+	Rule    Rule
+	Message string
+}
+
+//go:embed common_passwords.txt
+var commonPasswords string
+
+type Policy struct {
+	MinLength     int
+	MaxBytes      int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// DenyList - запрещенные пароли в нижнем регистре.
+	DenyList map[string]struct{}
+}
+
+// DefaultPolicy - политика по умолчанию со встроенным списком
+// распространенных паролей.
+func DefaultPolicy() Policy {
+	return Policy{
+		MinLength:    8,
+		MaxBytes:     BcryptMaxBytes,
+		RequireUpper: true,
+		RequireLower: true,
+		RequireDigit: true,
+		DenyList:     CommonPasswords(),
+	}
+}
+
+// CommonPasswords возвращает встроенный список распространенных паролей.
+func CommonPasswords() map[string]struct{} {
+	list := make(map[string]struct{})
+
+	scanner := bufio.NewScanner(strings.NewReader(commonPasswords))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		list[strings.ToLower(line)] = struct{}{}
+	}
+
+	return list
+}
+
+// Validate возвращает все нарушенные правила. Пустой результат означает, что
+// пароль соответствует политике.
+func (p Policy) Validate(password string) []Violation {
+	var violations []Violation
+
+	if n := len([]rune(password)); n < p.MinLength {
+		violations = append(violations, Violation{
+			Rule:    RuleMinLength,
+			Message: fmt.Sprintf("password must be at least %d characters long", p.MinLength),
+		})
+	}
+
+	if p.MaxBytes > 0 && len(password) > p.MaxBytes {
+		violations = append(violations, Violation{
+			Rule:    RuleMaxLength,
+			Message: fmt.Sprintf("password must be at most %d bytes long", p.MaxBytes),
+		})
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.RequireUpper && !hasUpper {
+		violations = append(violations, Violation{Rule: RuleUppercase, Message: "password must contain an uppercase letter"})
+	}
+	if p.RequireLower && !hasLower {
+		violations = append(violations, Violation{Rule: RuleLowercase, Message: "password must contain a lowercase letter"})
+	}
+	if p.RequireDigit && !hasDigit {
+		violations = append(violations, Violation{Rule: RuleDigit, Message: "password must contain a digit"})
+	}
+	if p.RequireSymbol && !hasSymbol {
+		violations = append(violations, Violation{Rule: RuleSymbol, Message: "password must contain a special character"})
+	}
+
+	if _, ok := p.DenyList[strings.ToLower(password)]; ok {
+		violations = append(violations, Violation{Rule: RuleCommon, Message: "password is too common"})
+	}
+
+	return violations
+}
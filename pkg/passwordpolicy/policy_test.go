@@ -0,0 +1,89 @@
+package passwordpolicy
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func rules(violations []Violation) []Rule {
+	var got []Rule
+	for _, v := range violations {
+		got = append(got, v.Rule)
+	}
+	return got
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	strict := DefaultPolicy()
+	strict.RequireSymbol = true
+
+	testCases := []struct {
+		name     string
+		policy   Policy
+		password string
+		want     []Rule
+	}{
+		{
+			name:     "OK",
+			policy:   DefaultPolicy(),
+			password: "Qwerty1!",
+		},
+		{
+			name:     "Too short",
+			policy:   DefaultPolicy(),
+			password: "Ab1",
+			want:     []Rule{RuleMinLength},
+		},
+		{
+			name:     "Length counts characters, not bytes",
+			policy:   DefaultPolicy(),
+			password: "Пароль12",
+		},
+		{
+			name:     "Too long for bcrypt",
+			policy:   DefaultPolicy(),
+			password: "Aa1" + strings.Repeat("я", 35),
+			want:     []Rule{RuleMaxLength},
+		},
+		{
+			name:     "Missing classes",
+			policy:   strict,
+			password: "abcdefghij",
+			want:     []Rule{RuleUppercase, RuleDigit, RuleSymbol},
+		},
+		{
+			name:     "Common password, any case",
+			policy:   DefaultPolicy(),
+			password: "PassWord123",
+			want:     []Rule{RuleCommon},
+		},
+		{
+			name:     "Empty deny list",
+			policy:   Policy{MinLength: 8},
+			password: "password",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := rules(tc.policy.Validate(tc.password))
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Validate(%q) = %v, want %v", tc.password, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCommonPasswords(t *testing.T) {
+	list := CommonPasswords()
+
+	if _, ok := list["123456"]; !ok {
+		t.Errorf("CommonPasswords() does not contain 123456")
+	}
+	for password := range list {
+		if strings.HasPrefix(password, "#") || password != strings.ToLower(password) {
+			t.Errorf("CommonPasswords() contains unnormalized entry %q", password)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Package signedtoken выпускает одноразовые токены для ссылок из писем.
+// Токен состоит из случайного секрета и HMAC-подписи, привязывающей его к
+// назначению, поэтому поддельный или чужой по назначению токен отвергается
+// без обращения к базе. В базе хранится только хэш секрета.
+package signedtoken
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+type Signer struct {
+	key []byte
+}
+
+func NewSigner(key []byte) *Signer {
+	return &Signer{key: key}
+}
+
+// Issue выпускает токен для назначения purpose. Возвращает сам токен для
+// отправки пользователю и хэш для хранения.
+func (s *Signer) Issue(purpose string) (token string, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err = rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	secret := base64.RawURLEncoding.EncodeToString(b)
+
+	return secret + "." + s.sign(purpose, secret), Hash(secret), nil
+}
+
+// Verify проверяет подпись токена и возвращает хэш, по которому его искать.
+func (s *Signer) Verify(purpose, token string) (string, error) {
+	secret, signature, ok := strings.Cut(token, ".")
+	if !ok || secret == "" {
+		return "", ErrInvalidToken
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(s.sign(purpose, secret))) {
+		return "", ErrInvalidToken
+	}
+
+	return Hash(secret), nil
+}
+
+func (s *Signer) sign(purpose, secret string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(purpose + "." + secret))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func Hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,74 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Секрет "12345678901234567890" из приложения B RFC 6238.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	// Ожидаемые значения - последние 6 цифр 8-значных кодов из RFC 6238.
+	testCases := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+
+	for _, tc := range testCases {
+		got, err := Code(rfcSecret, time.Unix(tc.unix, 0))
+		if err != nil {
+			t.Fatalf("Code() error = %v", err)
+		}
+		if got != tc.want {
+			t.Errorf("Code(%d) = %s, want %s", tc.unix, got, tc.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+
+	step, ok := Validate(rfcSecret, "005924", now, 1)
+	if !ok || step != Step(now) {
+		t.Errorf("Validate() = %d, %v, want %d, true", step, ok, Step(now))
+	}
+
+	previous, _ := Code(rfcSecret, now.Add(-Period))
+	if step, ok = Validate(rfcSecret, previous, now, 1); !ok || step != Step(now)-1 {
+		t.Errorf("Validate() previous step = %d, %v, want %d, true", step, ok, Step(now)-1)
+	}
+
+	stale, _ := Code(rfcSecret, now.Add(-2*Period))
+	if _, ok = Validate(rfcSecret, stale, now, 1); ok {
+		t.Errorf("Validate() accepted a code outside the skew window")
+	}
+
+	for _, input := range []string{"", "12345", "abcdef", "0059240"} {
+		if _, ok = Validate(rfcSecret, input, now, 1); ok {
+			t.Errorf("Validate(%q) = true, want false", input)
+		}
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+	if _, err = Code(secret, time.Now()); err != nil {
+		t.Errorf("Code() with generated secret error = %v", err)
+	}
+
+	uri := URI("GoMarket", "test user", secret)
+	if !strings.HasPrefix(uri, "otpauth://totp/GoMarket:test%20user?") || !strings.Contains(uri, "secret="+secret) {
+		t.Errorf("URI() = %s", uri)
+	}
+}